@@ -6,14 +6,20 @@ import (
 	"encoding/json"
 	"io"
 	"io/ioutil"
+	"sort"
+	"strings"
 
 	"github.com/go-kivik/kivik/driver"
+	"github.com/go-kivik/kivik/errors"
 )
 
 // Attachments is a collection of one or more file attachments.
 type Attachments map[string]*Attachment
 
 // MD5sum is a 128-bit MD5 checksum.
+//
+// Deprecated: MD5sum is unused. Attachment digests, which may use algorithms
+// other than MD5, are decoded with Attachment.DigestSum.
 type MD5sum [16]byte
 
 // Attachment represents a file attachment on a CouchDB document.
@@ -53,10 +59,27 @@ type Attachment struct {
 	// RevPos is the revision number when attachment was added.
 	RevPos int64 `json:"revpos"`
 
-	// Digest is the content hash digest.
+	// Digest is the content hash digest, in the form "<algorithm>-<base64
+	// sum>", such as "md5-1B2M2Y8AsgTpgAmY7PhCfg==". Use DigestSum to decode
+	// it.
 	Digest string `json:"digest"`
 }
 
+// DigestSum decodes the attachment's Digest, returning the hash algorithm,
+// such as "md5", and the raw checksum. Algorithms other than MD5 are returned
+// as reported by the server.
+func (a *Attachment) DigestSum() (algorithm string, sum []byte, err error) {
+	parts := strings.SplitN(a.Digest, "-", 2)
+	if len(parts) != 2 || parts[0] == "" {
+		return "", nil, errors.Statusf(StatusBadResponse, "kivik: invalid digest %q", a.Digest)
+	}
+	sum, err = base64.StdEncoding.DecodeString(parts[1])
+	if err != nil {
+		return "", nil, errors.WrapStatus(StatusBadResponse, err)
+	}
+	return parts[0], sum, nil
+}
+
 // bufCloser wraps a *bytes.Buffer to create an io.ReadCloser
 type bufCloser struct {
 	*bytes.Buffer
@@ -90,8 +113,16 @@ func readEncoder(in io.ReadCloser) io.ReadCloser {
 	return r
 }
 
-// MarshalJSON satisfies the json.Marshaler interface.
+// MarshalJSON satisfies the json.Marshaler interface. A stub is marshaled as
+// such, so that a document read without attachment content may be written
+// back without modifying its attachments.
 func (a *Attachment) MarshalJSON() ([]byte, error) {
+	if a.Stub {
+		return json.Marshal(map[string]interface{}{
+			"content_type": a.ContentType,
+			"stub":         true,
+		})
+	}
 	r := readEncoder(a.Content)
 	data, err := ioutil.ReadAll(r)
 	if err != nil {
@@ -138,6 +169,34 @@ func (a *Attachments) UnmarshalJSON(data []byte) error {
 	return nil
 }
 
+// inlineAttachments adds atts to the _attachments field of doc, to be
+// marshaled as base64-encoded inline data. doc is converted to a
+// map[string]interface{} if necessary.
+func inlineAttachments(doc interface{}, atts []*Attachment) (map[string]interface{}, error) {
+	m, ok := doc.(map[string]interface{})
+	if !ok {
+		body, err := json.Marshal(doc)
+		if err != nil {
+			return nil, errors.WrapStatus(StatusBadRequest, err)
+		}
+		if e := json.Unmarshal(body, &m); e != nil {
+			return nil, errors.WrapStatus(StatusBadRequest, e)
+		}
+	}
+	if m == nil {
+		return nil, errors.Status(StatusBadRequest, "kivik: document must be a JSON object")
+	}
+	stubs, ok := m["_attachments"].(map[string]interface{})
+	if !ok {
+		stubs = make(map[string]interface{}, len(atts))
+	}
+	for _, att := range atts {
+		stubs[att.Filename] = att
+	}
+	m["_attachments"] = stubs
+	return m, nil
+}
+
 // AttachmentsIterator is an experimental way to read streamed attachments from
 // a multi-part Get request.
 type AttachmentsIterator struct {
@@ -151,10 +210,56 @@ func (i *AttachmentsIterator) Next() (*Attachment, error) {
 	if err := i.atti.Next(att); err != nil {
 		return nil, err
 	}
-	return &Attachment{
-		Filename:    att.Filename,
-		ContentType: att.ContentType,
-		Content:     att.Content,
-		Size:        att.Size,
-	}, nil
+	a := Attachment(*att)
+	return &a, nil
+}
+
+// bufferedAttachments is a driver.Attachments iterator over attachments which
+// have already been read into memory.
+type bufferedAttachments struct {
+	atts []*driver.Attachment
+}
+
+var _ driver.Attachments = &bufferedAttachments{}
+
+func (a *bufferedAttachments) Next(att *driver.Attachment) error {
+	if len(a.atts) == 0 {
+		return io.EOF
+	}
+	*att = *a.atts[0]
+	a.atts = a.atts[1:]
+	return nil
+}
+
+func (a *bufferedAttachments) Close() error {
+	a.atts = nil
+	return nil
+}
+
+// readInlineAttachments reads a JSON document from body, and decodes any
+// attachments in its _attachments stanza, in filename order. body is closed,
+// and a replacement reader over the buffered document is returned.
+func readInlineAttachments(body io.ReadCloser) (*bufferedAttachments, io.ReadCloser, error) {
+	defer body.Close() // nolint: errcheck
+	data, err := ioutil.ReadAll(body)
+	if err != nil {
+		return nil, nil, errors.WrapStatus(StatusNetworkError, err)
+	}
+	var doc struct {
+		Attachments Attachments `json:"_attachments"`
+	}
+	if e := json.Unmarshal(data, &doc); e != nil {
+		return nil, nil, errors.WrapStatus(StatusBadResponse, e)
+	}
+	filenames := make([]string, 0, len(doc.Attachments))
+	for filename := range doc.Attachments {
+		filenames = append(filenames, filename)
+	}
+	sort.Strings(filenames)
+	atts := make([]*driver.Attachment, len(filenames))
+	for i, filename := range filenames {
+		att := driver.Attachment(*doc.Attachments[filename])
+		atts[i] = &att
+	}
+	return &bufferedAttachments{atts: atts}, ioutil.NopCloser(bytes.NewReader(data)), nil
 }
@@ -198,3 +198,57 @@ func TestAttachmentsIteratorNext(t *testing.T) {
 		})
 	}
 }
+
+func TestAttachmentDigestSum(t *testing.T) {
+	tests := []struct {
+		name      string
+		digest    string
+		algorithm string
+		sum       []byte
+		status    int
+		err       string
+	}{
+		{
+			name:   "empty",
+			status: StatusBadResponse,
+			err:    `kivik: invalid digest ""`,
+		},
+		{
+			name:   "no algorithm",
+			digest: "-1B2M2Y8AsgTpgAmY7PhCfg==",
+			status: StatusBadResponse,
+			err:    `kivik: invalid digest "-1B2M2Y8AsgTpgAmY7PhCfg=="`,
+		},
+		{
+			name:   "invalid base64",
+			digest: "md5-!!!",
+			status: StatusBadResponse,
+			err:    "illegal base64 data at input byte 0",
+		},
+		{
+			name:      "md5",
+			digest:    "md5-1B2M2Y8AsgTpgAmY7PhCfg==",
+			algorithm: "md5",
+			sum:       []byte{0xd4, 0x1d, 0x8c, 0xd9, 0x8f, 0x00, 0xb2, 0x04, 0xe9, 0x80, 0x09, 0x98, 0xec, 0xf8, 0x42, 0x7e},
+		},
+		{
+			name:      "sha256",
+			digest:    "sha256-AAEC",
+			algorithm: "sha256",
+			sum:       []byte{0, 1, 2},
+		},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			att := &Attachment{Digest: test.digest}
+			algorithm, sum, err := att.DigestSum()
+			testy.StatusError(t, test.err, test.status, err)
+			if algorithm != test.algorithm {
+				t.Errorf("Unexpected algorithm: %s", algorithm)
+			}
+			if d := diff.Interface(test.sum, sum); d != nil {
+				t.Error(d)
+			}
+		})
+	}
+}
@@ -0,0 +1,239 @@
+package kivik
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"github.com/go-kivik/kivik/errors"
+)
+
+// Default limits for a BatchWriter.
+const (
+	DefaultBatchMaxDocs  = 100
+	DefaultBatchMaxDelay = time.Second
+)
+
+// BatchConfig configures a BatchWriter. The zero value, or a nil
+// *BatchConfig, uses the defaults.
+type BatchConfig struct {
+	// MaxDocs is the number of queued documents which triggers a flush.
+	MaxDocs int
+	// MaxDelay is the maximum time a document is queued before it is flushed.
+	MaxDelay time.Duration
+}
+
+// BatchWriter queues individual document writes, and sends them to the
+// database in batches with BulkDocs. A batch is flushed when it reaches
+// MaxDocs documents, or MaxDelay after the first document was queued,
+// whichever comes first. A BatchWriter is safe for concurrent use.
+type BatchWriter struct {
+	db      *DB
+	ctx     context.Context
+	options []Options
+	max     int
+	delay   time.Duration
+
+	mu      sync.Mutex
+	docs    []interface{}
+	results []*BatchResult
+	timer   *time.Timer
+	closed  bool
+	// wg tracks flushes started in the background, by a full batch or by
+	// the timer.
+	wg sync.WaitGroup
+}
+
+// BatchResult is the pending result of a document queued with a BatchWriter.
+type BatchResult struct {
+	done chan struct{}
+	id   string
+	rev  string
+	err  error
+}
+
+func newBatchResult() *BatchResult {
+	return &BatchResult{done: make(chan struct{})}
+}
+
+func (r *BatchResult) resolve(id, rev string, err error) {
+	r.id, r.rev, r.err = id, rev, err
+	close(r.done)
+}
+
+// Done returns a channel which is closed once the result is available.
+func (r *BatchResult) Done() <-chan struct{} {
+	return r.done
+}
+
+// Wait blocks until the document has been written, or ctx is cancelled, and
+// returns the document's ID and new revision, or the error for this
+// document.
+func (r *BatchResult) Wait(ctx context.Context) (docID, rev string, err error) {
+	select {
+	case <-r.done:
+		return r.id, r.rev, r.err
+	case <-ctx.Done():
+		return "", "", ctx.Err()
+	}
+}
+
+var errBatchClosed = errors.Status(StatusBadRequest, "kivik: batch writer closed")
+
+// NewBatchWriter returns a new BatchWriter for db. ctx is used for flushes
+// triggered by the queue, and options are passed to BulkDocs for each batch.
+func (db *DB) NewBatchWriter(ctx context.Context, config *BatchConfig, options ...Options) *BatchWriter {
+	w := &BatchWriter{
+		db:      db,
+		ctx:     ctx,
+		options: options,
+		max:     DefaultBatchMaxDocs,
+		delay:   DefaultBatchMaxDelay,
+	}
+	if config != nil {
+		if config.MaxDocs > 0 {
+			w.max = config.MaxDocs
+		}
+		if config.MaxDelay > 0 {
+			w.delay = config.MaxDelay
+		}
+	}
+	return w
+}
+
+// Put queues doc to be stored with the specified docID. As with DB.Put, doc
+// must include the current revision when updating an existing document.
+func (w *BatchWriter) Put(docID string, doc interface{}) *BatchResult {
+	if docID == "" {
+		return w.failed(missingArg("docID"))
+	}
+	m, err := toMap(doc)
+	if err != nil {
+		return w.failed(err)
+	}
+	m["_id"] = docID
+	return w.enqueue(m)
+}
+
+// CreateDoc queues doc to be created. If doc has no _id field, an ID is
+// generated by the server.
+func (w *BatchWriter) CreateDoc(doc interface{}) *BatchResult {
+	x, err := normalizeFromJSON(doc)
+	if err != nil {
+		return w.failed(err)
+	}
+	return w.enqueue(x)
+}
+
+func (w *BatchWriter) failed(err error) *BatchResult {
+	r := newBatchResult()
+	r.resolve("", "", err)
+	return r
+}
+
+func (w *BatchWriter) enqueue(doc interface{}) *BatchResult {
+	r := newBatchResult()
+	w.mu.Lock()
+	if w.closed {
+		w.mu.Unlock()
+		r.resolve("", "", errBatchClosed)
+		return r
+	}
+	w.docs = append(w.docs, doc)
+	w.results = append(w.results, r)
+	full := len(w.docs) >= w.max
+	if full {
+		w.wg.Add(1)
+		go func() {
+			defer w.wg.Done()
+			_ = w.Flush(w.ctx)
+		}()
+	} else if w.timer == nil {
+		// A stale callback, whose batch was already flushed, must not flush
+		// the next batch early, so it checks its timer is still current. It
+		// does so with w.mu held, so cannot see timer unset.
+		var timer *time.Timer
+		w.wg.Add(1)
+		timer = time.AfterFunc(w.delay, func() {
+			defer w.wg.Done()
+			w.mu.Lock()
+			if w.timer != timer {
+				w.mu.Unlock()
+				return
+			}
+			docs, results := w.take()
+			w.mu.Unlock()
+			_ = w.send(w.ctx, docs, results)
+		})
+		w.timer = timer
+	}
+	w.mu.Unlock()
+	return r
+}
+
+// take removes and returns the queued documents, and stops the timer. w.mu
+// must be held.
+func (w *BatchWriter) take() ([]interface{}, []*BatchResult) {
+	docs, results := w.docs, w.results
+	w.docs, w.results = nil, nil
+	if w.timer != nil {
+		if w.timer.Stop() {
+			// The callback will not run to release its count.
+			w.wg.Done()
+		}
+		w.timer = nil
+	}
+	return docs, results
+}
+
+// Flush sends any queued documents immediately. The returned error is that of
+// the BulkDocs request, if any; errors for individual documents are
+// reported only through their BatchResults.
+func (w *BatchWriter) Flush(ctx context.Context) error {
+	w.mu.Lock()
+	docs, results := w.take()
+	w.mu.Unlock()
+	return w.send(ctx, docs, results)
+}
+
+func (w *BatchWriter) send(ctx context.Context, docs []interface{}, results []*BatchResult) error {
+	if len(docs) == 0 {
+		return nil
+	}
+	bulk, err := w.db.BulkDocs(ctx, docs, w.options...)
+	if err != nil {
+		for _, r := range results {
+			r.resolve("", "", err)
+		}
+		return err
+	}
+	defer bulk.Close() // nolint: errcheck
+	for bulk.Next() {
+		if i := bulk.Index(); i < len(results) && results[i] != nil {
+			results[i].resolve(bulk.ID(), bulk.Rev(), bulk.UpdateErr())
+			results[i] = nil
+		}
+	}
+	err = bulk.Err()
+	missing := err
+	if missing == nil {
+		missing = errors.Status(StatusBadResponse, "kivik: no result returned for document")
+	}
+	for _, r := range results {
+		if r != nil {
+			r.resolve("", "", missing)
+		}
+	}
+	return err
+}
+
+// Close flushes any queued documents, prevents further documents from being
+// queued, and waits for any flushes already in progress to complete.
+func (w *BatchWriter) Close(ctx context.Context) error {
+	w.mu.Lock()
+	w.closed = true
+	w.mu.Unlock()
+	err := w.Flush(ctx)
+	w.wg.Wait()
+	return err
+}
@@ -0,0 +1,155 @@
+package kivik
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/flimzy/diff"
+	"github.com/flimzy/testy"
+	"github.com/go-kivik/kivik/driver"
+	kerrors "github.com/go-kivik/kivik/errors"
+	"github.com/go-kivik/kivik/mock"
+)
+
+type batchResult struct {
+	ID, Rev, Err string
+}
+
+func waitAll(t *testing.T, results ...*BatchResult) []batchResult {
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	out := make([]batchResult, len(results))
+	for i, r := range results {
+		id, rev, err := r.Wait(ctx)
+		out[i] = batchResult{ID: id, Rev: rev}
+		if err != nil {
+			out[i].Err = err.Error()
+		}
+	}
+	return out
+}
+
+func bulkDocerDB(batches *[][]interface{}, mu *sync.Mutex, err error) *DB {
+	return &DB{
+		driverDB: &mock.BulkDocer{
+			BulkDocsFunc: func(_ context.Context, docs []interface{}, _ map[string]interface{}) (driver.BulkResults, error) {
+				mu.Lock()
+				*batches = append(*batches, docs)
+				mu.Unlock()
+				if err != nil {
+					return nil, err
+				}
+				var results []driver.BulkResult
+				for i, doc := range docs {
+					id, _ := doc.(map[string]interface{})["_id"].(string)
+					if id == "" {
+						id = fmt.Sprintf("auto%d", i)
+					}
+					result := driver.BulkResult{ID: id, Rev: "1-xxx"}
+					if id == "conflict" {
+						result = driver.BulkResult{ID: id, Error: kerrors.Status(StatusConflict, "conflict")}
+					}
+					results = append(results, result)
+				}
+				return &emulatedBulkResults{results}, nil
+			},
+		},
+	}
+}
+
+func TestBatchWriterMaxDocs(t *testing.T) {
+	var batches [][]interface{}
+	var mu sync.Mutex
+	db := bulkDocerDB(&batches, &mu, nil)
+	w := db.NewBatchWriter(context.Background(), &BatchConfig{MaxDocs: 3, MaxDelay: time.Hour})
+	results := waitAll(t,
+		w.Put("a", map[string]string{"foo": "bar"}),
+		w.CreateDoc([]byte(`{"foo":"baz"}`)),
+		w.Put("conflict", map[string]string{}),
+		w.Put("", nil),
+		w.CreateDoc([]byte("invalid json")),
+	)
+	expected := []batchResult{
+		{ID: "a", Rev: "1-xxx"},
+		{ID: "auto1", Rev: "1-xxx"},
+		{ID: "conflict", Err: "conflict"},
+		{Err: "kivik: docID required"},
+		{Err: "invalid character 'i' looking for beginning of value"},
+	}
+	if d := diff.Interface(expected, results); d != nil {
+		t.Error(d)
+	}
+	mu.Lock()
+	defer mu.Unlock()
+	if len(batches) != 1 || len(batches[0]) != 3 {
+		t.Errorf("Unexpected batches: %v", batches)
+	}
+}
+
+func TestBatchWriterMaxDelay(t *testing.T) {
+	var batches [][]interface{}
+	var mu sync.Mutex
+	db := bulkDocerDB(&batches, &mu, nil)
+	w := db.NewBatchWriter(context.Background(), &BatchConfig{MaxDelay: time.Millisecond})
+	results := waitAll(t, w.Put("a", map[string]string{}), w.Put("b", map[string]string{}))
+	expected := []batchResult{{ID: "a", Rev: "1-xxx"}, {ID: "b", Rev: "1-xxx"}}
+	if d := diff.Interface(expected, results); d != nil {
+		t.Error(d)
+	}
+}
+
+func TestBatchWriterClose(t *testing.T) {
+	var batches [][]interface{}
+	var mu sync.Mutex
+	db := bulkDocerDB(&batches, &mu, errors.New("bulk failed"))
+	w := db.NewBatchWriter(context.Background(), &BatchConfig{MaxDelay: time.Hour})
+	r := w.Put("a", map[string]string{})
+	err := w.Close(context.Background())
+	testy.Error(t, "bulk failed", err)
+	results := waitAll(t, r, w.Put("b", map[string]string{}))
+	expected := []batchResult{{Err: "bulk failed"}, {Err: "kivik: batch writer closed"}}
+	if d := diff.Interface(expected, results); d != nil {
+		t.Error(d)
+	}
+	if err := w.Flush(context.Background()); err != nil {
+		t.Errorf("Unexpected error flushing empty queue: %s", err)
+	}
+}
+
+func TestBatchWriterCloseWaits(t *testing.T) {
+	started, release := make(chan struct{}), make(chan struct{})
+	db := &DB{
+		driverDB: &mock.BulkDocer{
+			BulkDocsFunc: func(_ context.Context, docs []interface{}, _ map[string]interface{}) (driver.BulkResults, error) {
+				close(started)
+				<-release
+				return &emulatedBulkResults{[]driver.BulkResult{{ID: "a", Rev: "1-xxx"}}}, nil
+			},
+		},
+	}
+	w := db.NewBatchWriter(context.Background(), &BatchConfig{MaxDocs: 1})
+	r := w.Put("a", map[string]string{})
+	<-started
+	closed := make(chan error)
+	go func() {
+		closed <- w.Close(context.Background())
+	}()
+	select {
+	case <-closed:
+		t.Fatal("Close returned before the flush completed")
+	case <-time.After(10 * time.Millisecond):
+	}
+	close(release)
+	if err := <-closed; err != nil {
+		t.Fatal(err)
+	}
+	select {
+	case <-r.Done():
+	default:
+		t.Error("Result not resolved when Close returned")
+	}
+}
@@ -2,6 +2,7 @@ package kivik
 
 import (
 	"context"
+	"encoding/json"
 	"fmt"
 	"io"
 	"reflect"
@@ -14,13 +15,31 @@ import (
 type BulkResults struct {
 	*iter
 	bulki driver.BulkResults
+	read  int // number of results read
 }
 
 // Next returns the next BulkResult from the feed. If an error occurs, it will
 // be returned and the feed closed. io.EOF will be returned when there are no
 // more results.
 func (r *BulkResults) Next() bool {
-	return r.iter.Next()
+	if !r.iter.Next() {
+		return false
+	}
+	r.read++
+	return true
+}
+
+// Index returns the zero-based index of the current result. Results are
+// returned in the order of the documents in the request, so this is also the
+// index of the corresponding input document. Index returns -1 if there is no
+// current result.
+func (r *BulkResults) Index() int {
+	runlock, err := r.rlock()
+	if err != nil {
+		return -1
+	}
+	defer runlock()
+	return r.read - 1
 }
 
 // Err returns the error, if any, that was encountered during iteration. Err
@@ -106,7 +125,18 @@ func (db *DB) BulkDocs(ctx context.Context, docs interface{}, options ...Options
 		return nil, errors.Status(StatusBadRequest, "kivik: no documents provided")
 	}
 	if bulkDocer, ok := db.driverDB.(driver.BulkDocer); ok {
-		bulki, err := bulkDocer.BulkDocs(ctx, docsi, opts)
+		encoded := make([]interface{}, len(docsi))
+		for i, doc := range docsi {
+			if encoded[i], err = encodeDoc(db.client.codec(), doc); err != nil {
+				return nil, errors.WrapStatus(StatusBadRequest, err)
+			}
+		}
+		var bulki driver.BulkResults
+		err := db.do(ctx, &Operation{Name: "BulkDocs", Options: opts}, true, func(ctx context.Context) error {
+			var e error
+			bulki, e = bulkDocer.BulkDocs(ctx, encoded, opts)
+			return e
+		})
 		if err != nil {
 			return nil, err
 		}
@@ -189,3 +219,122 @@ func docsInterfaceSlice(docs interface{}) ([]interface{}, error) {
 	}
 	return docsi, nil
 }
+
+// Bulk builds a BulkDocs request, with per-document control over creation,
+// update and deletion. The zero value is an empty request, ready to use. Its
+// methods return the receiver, so that calls may be chained:
+//
+//	bulk := new(kivik.Bulk).
+//	    Put(newDoc).
+//	    Update("foo", "1-xxx", fooDoc).
+//	    Delete("bar", "2-xxx")
+//	results, err := db.BulkWrite(ctx, bulk)
+type Bulk struct {
+	docs     []interface{}
+	noEdits  bool
+	errIndex int
+	err      error
+}
+
+// Len returns the number of documents in the request.
+func (b *Bulk) Len() int {
+	return len(b.docs)
+}
+
+// Put adds doc to the request, as is. As with Put, doc may be a
+// JSON-marshalable object, or a raw JSON document. A doc without an _id field
+// is created with an auto-generated ID.
+func (b *Bulk) Put(doc interface{}) *Bulk {
+	x, err := normalizeFromJSON(doc)
+	b.add(x, err)
+	return b
+}
+
+// Update adds doc to the request, with its _id and _rev fields set to docID
+// and rev. rev may be empty, to create a new document with a specific ID.
+func (b *Bulk) Update(docID, rev string, doc interface{}) *Bulk {
+	if docID == "" {
+		b.add(nil, missingArg("docID"))
+		return b
+	}
+	m, err := toMap(doc)
+	if err == nil {
+		m["_id"] = docID
+		if rev != "" {
+			m["_rev"] = rev
+		}
+	}
+	b.add(m, err)
+	return b
+}
+
+// Delete adds the deletion of revision rev of document docID to the request.
+func (b *Bulk) Delete(docID, rev string) *Bulk {
+	if docID == "" {
+		b.add(nil, missingArg("docID"))
+		return b
+	}
+	b.add(map[string]interface{}{
+		"_id":      docID,
+		"_rev":     rev,
+		"_deleted": true,
+	}, nil)
+	return b
+}
+
+// NewEdits sets the new_edits option for the whole request. When false, the
+// revisions supplied are stored as is, rather than new revisions being
+// assigned, as is done during replication. The default is true.
+func (b *Bulk) NewEdits(newEdits bool) *Bulk {
+	b.noEdits = !newEdits
+	return b
+}
+
+func (b *Bulk) add(doc interface{}, err error) {
+	if err != nil && b.err == nil {
+		b.errIndex, b.err = len(b.docs), err
+	}
+	b.docs = append(b.docs, doc)
+}
+
+// toMap converts doc to a map[string]interface{}, by way of JSON if
+// necessary.
+func toMap(doc interface{}) (map[string]interface{}, error) {
+	x, err := normalizeFromJSON(doc)
+	if err != nil {
+		return nil, err
+	}
+	if m, ok := x.(map[string]interface{}); ok {
+		copied := make(map[string]interface{}, len(m))
+		for k, v := range m {
+			copied[k] = v
+		}
+		return copied, nil
+	}
+	data, err := json.Marshal(x)
+	if err != nil {
+		return nil, errors.WrapStatus(StatusBadRequest, err)
+	}
+	var m map[string]interface{}
+	if err := json.Unmarshal(data, &m); err != nil {
+		return nil, errors.WrapStatus(StatusBadRequest, err)
+	}
+	return m, nil
+}
+
+// BulkWrite executes the request built by bulk, as with BulkDocs. The
+// BulkResults Index method gives the index of each result's document within
+// bulk. If any document could not be added to bulk, the first such error is
+// returned, and no request is made.
+func (db *DB) BulkWrite(ctx context.Context, bulk *Bulk, options ...Options) (*BulkResults, error) {
+	if bulk == nil {
+		return nil, missingArg("bulk")
+	}
+	if bulk.err != nil {
+		return nil, errors.WrapStatus(StatusCode(bulk.err), fmt.Errorf("kivik: document %d: %s", bulk.errIndex, bulk.err))
+	}
+	if bulk.noEdits {
+		options = append(options[:len(options):len(options)], Param("new_edits", false))
+	}
+	return db.BulkDocs(ctx, bulk.docs, options...)
+}
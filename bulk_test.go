@@ -497,3 +497,90 @@ func TestBulkResultsGetters(t *testing.T) {
 
 	})
 }
+
+func TestBulkWrite(t *testing.T) {
+	tests := []struct {
+		name     string
+		bulk     *Bulk
+		options  Options
+		expected []map[string]interface{}
+		status   int
+		err      string
+	}{
+		{
+			name:   "nil bulk",
+			status: StatusBadRequest,
+			err:    "kivik: bulk required",
+		},
+		{
+			name:   "empty",
+			bulk:   new(Bulk),
+			status: StatusBadRequest,
+			err:    "kivik: no documents provided",
+		},
+		{
+			name:   "invalid doc",
+			bulk:   new(Bulk).Put(map[string]string{"_id": "foo"}).Put([]byte("invalid")),
+			status: StatusBadRequest,
+			err:    "kivik: document 1: invalid character 'i' looking for beginning of value",
+		},
+		{
+			name:   "missing ID",
+			bulk:   new(Bulk).Delete("", "1-xxx"),
+			status: StatusBadRequest,
+			err:    "kivik: document 0: kivik: docID required",
+		},
+		{
+			name: "mixed",
+			bulk: new(Bulk).
+				Put(map[string]string{"foo": "bar"}).
+				Update("a", "1-xxx", struct {
+					Color string `json:"color"`
+				}{Color: "red"}).
+				Update("b", "", []byte(`{"_id":"x","_rev":"2-yyy"}`)).
+				Delete("c", "3-zzz").
+				NewEdits(false),
+			expected: []map[string]interface{}{
+				{"foo": "bar"},
+				{"_id": "a", "_rev": "1-xxx", "color": "red"},
+				{"_id": "b", "_rev": "2-yyy"},
+				{"_id": "c", "_rev": "3-zzz", "_deleted": true},
+			},
+			options: Options{"new_edits": false},
+		},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			db := &DB{
+				driverDB: &mock.BulkDocer{
+					BulkDocsFunc: func(_ context.Context, docs []interface{}, options map[string]interface{}) (driver.BulkResults, error) {
+						if d := diff.AsJSON(test.expected, docs); d != nil {
+							return nil, fmt.Errorf("Unexpected docs:\n%s", d)
+						}
+						if d := diff.Interface(map[string]interface{}(test.options), options); d != nil {
+							return nil, fmt.Errorf("Unexpected options:\n%s", d)
+						}
+						return &mock.BulkResults{}, nil
+					},
+				},
+			}
+			_, err := db.BulkWrite(context.Background(), test.bulk)
+			testy.StatusError(t, test.err, test.status, err)
+		})
+	}
+}
+
+func TestBulkResultsIndex(t *testing.T) {
+	results := []driver.BulkResult{{ID: "a"}, {ID: "b"}}
+	r := newBulkResults(context.Background(), &emulatedBulkResults{results})
+	if i := r.Index(); i != -1 {
+		t.Errorf("Expected -1 before Next, got %d", i)
+	}
+	var indexes []int
+	for r.Next() {
+		indexes = append(indexes, r.Index())
+	}
+	if d := diff.Interface([]int{0, 1}, indexes); d != nil {
+		t.Error(d)
+	}
+}
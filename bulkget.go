@@ -0,0 +1,118 @@
+package kivik
+
+import (
+	"context"
+	"io"
+	"io/ioutil"
+
+	"github.com/go-kivik/kivik/driver"
+	"github.com/go-kivik/kivik/errors"
+)
+
+// BulkGetReference is a reference to a document given in a BulkGet query.
+type BulkGetReference struct {
+	// ID is the document ID to fetch.
+	ID string `json:"id"`
+	// Rev is the revision to fetch. If empty, the current revision is
+	// fetched.
+	Rev string `json:"rev,omitempty"`
+	// AttsSince is a list of revisions. Only attachments added since the
+	// most recent of these revisions will be included in the result.
+	AttsSince []string `json:"atts_since,omitempty"`
+}
+
+// BulkGet can be called to query several documents in bulk. It is well suited
+// for fetching a specific revision of documents, as replicators do for
+// example, or for getting revision history. The results are returned as a
+// Rows iterator, with the document available from ScanDoc. If a document
+// could not be fetched, ScanDoc will return the relevant error.
+//
+// If the driver does not support bulk fetching natively, BulkGet is emulated
+// by calling Get for each requested document, with options passed through
+// unaltered, except for "rev" and "atts_since", which are set from each
+// document reference.
+//
+// See http://docs.couchdb.org/en/2.0.0/api/database/bulk-api.html#db-bulk-get
+func (db *DB) BulkGet(ctx context.Context, docs []BulkGetReference, options ...Options) (*Rows, error) {
+	if len(docs) == 0 {
+		return nil, errors.Status(StatusBadRequest, "kivik: no documents provided")
+	}
+	opts, err := mergeOptions(options...)
+	if err != nil {
+		return nil, err
+	}
+	refs := make([]driver.BulkGetReference, len(docs))
+	for i, doc := range docs {
+		if doc.ID == "" {
+			return nil, missingArg("docID")
+		}
+		refs[i] = driver.BulkGetReference(doc)
+	}
+	if bulkGetter, ok := db.driverDB.(driver.BulkGetter); ok {
+		return db.doRows(ctx, &Operation{Name: "BulkGet", Options: opts}, func(ctx context.Context) (driver.Rows, error) {
+			return bulkGetter.BulkGet(ctx, refs, opts)
+		})
+	}
+	return newRows(ctx, db.client.codec(), &emulatedBulkGet{
+		ctx:     ctx,
+		db:      db,
+		refs:    refs,
+		options: opts,
+	}), nil
+}
+
+// emulatedBulkGet emulates a BulkGet request by calling Get for each
+// requested document, as Next is called.
+type emulatedBulkGet struct {
+	ctx     context.Context
+	db      *DB
+	refs    []driver.BulkGetReference
+	options Options
+}
+
+var _ driver.Rows = &emulatedBulkGet{}
+
+func (r *emulatedBulkGet) Next(row *driver.Row) error {
+	if len(r.refs) == 0 {
+		return io.EOF
+	}
+	ref := r.refs[0]
+	r.refs = r.refs[1:]
+	opts := make(Options, len(r.options)+2)
+	for k, v := range r.options {
+		opts[k] = v
+	}
+	if ref.Rev != "" {
+		opts["rev"] = ref.Rev
+	}
+	if len(ref.AttsSince) > 0 {
+		opts["atts_since"] = ref.AttsSince
+	}
+	*row = driver.Row{ID: ref.ID}
+	var doc *driver.Document
+	err := r.db.do(r.ctx, &Operation{Name: "Get", DocID: ref.ID, Options: opts}, false, func(ctx context.Context) error {
+		var e error
+		doc, e = r.db.driverDB.Get(ctx, ref.ID, opts)
+		return e
+	})
+	if err != nil {
+		row.Error = err
+		return nil
+	}
+	defer doc.Body.Close() // nolint: errcheck
+	row.Doc, err = ioutil.ReadAll(doc.Body)
+	if err != nil {
+		row.Doc = nil
+		row.Error = errors.WrapStatus(StatusNetworkError, err)
+	}
+	return nil
+}
+
+func (r *emulatedBulkGet) Close() error {
+	r.refs = nil
+	return nil
+}
+
+func (r *emulatedBulkGet) UpdateSeq() string { return "" }
+func (r *emulatedBulkGet) Offset() int64     { return 0 }
+func (r *emulatedBulkGet) TotalRows() int64  { return 0 }
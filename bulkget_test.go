@@ -0,0 +1,150 @@
+package kivik
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"testing"
+
+	"github.com/flimzy/diff"
+	"github.com/flimzy/testy"
+	"github.com/go-kivik/kivik/driver"
+	"github.com/go-kivik/kivik/mock"
+)
+
+func TestBulkGet(t *testing.T) {
+	tests := []struct {
+		name     string
+		db       *DB
+		docs     []BulkGetReference
+		options  Options
+		expected *Rows
+		status   int
+		err      string
+	}{
+		{
+			name:   "no docs",
+			db:     &DB{driverDB: &mock.BulkGetter{}},
+			status: StatusBadRequest,
+			err:    "kivik: no documents provided",
+		},
+		{
+			name:   "missing doc ID",
+			db:     &DB{driverDB: &mock.BulkGetter{}},
+			docs:   []BulkGetReference{{Rev: "1-xxx"}},
+			status: StatusBadRequest,
+			err:    "kivik: docID required",
+		},
+		{
+			name: "driver error",
+			db: &DB{
+				driverDB: &mock.BulkGetter{
+					BulkGetFunc: func(_ context.Context, _ []driver.BulkGetReference, _ map[string]interface{}) (driver.Rows, error) {
+						return nil, errors.New("bulkget error")
+					},
+				},
+			},
+			docs:   []BulkGetReference{{ID: "foo"}},
+			status: StatusInternalServerError,
+			err:    "bulkget error",
+		},
+		{
+			name: "success",
+			db: &DB{
+				driverDB: &mock.BulkGetter{
+					BulkGetFunc: func(_ context.Context, docs []driver.BulkGetReference, opts map[string]interface{}) (driver.Rows, error) {
+						expectedDocs := []driver.BulkGetReference{{ID: "foo", Rev: "1-xxx", AttsSince: []string{"1-xxx"}}}
+						if d := diff.Interface(expectedDocs, docs); d != nil {
+							return nil, fmt.Errorf("Unexpected docs:\n%s", d)
+						}
+						if d := diff.Interface(testOptions, opts); d != nil {
+							return nil, fmt.Errorf("Unexpected options:\n%s", d)
+						}
+						return &mock.Rows{ID: "a"}, nil
+					},
+				},
+			},
+			docs:    []BulkGetReference{{ID: "foo", Rev: "1-xxx", AttsSince: []string{"1-xxx"}}},
+			options: testOptions,
+			expected: &Rows{
+				iter: &iter{
+					feed: &rowsIterator{
+						Rows: &mock.Rows{ID: "a"},
+					},
+					curVal: &driver.Row{},
+				},
+				rowsi: &mock.Rows{ID: "a"},
+			},
+		},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			result, err := test.db.BulkGet(context.Background(), test.docs, test.options)
+			testy.StatusError(t, test.err, test.status, err)
+			result.cancel = nil // Determinism
+			if d := diff.Interface(test.expected, result); d != nil {
+				t.Error(d)
+			}
+		})
+	}
+}
+
+func TestEmulatedBulkGet(t *testing.T) {
+	db := &DB{
+		driverDB: &mock.DB{
+			GetFunc: func(_ context.Context, docID string, opts map[string]interface{}) (*driver.Document, error) {
+				switch docID {
+				case "foo":
+					expected := map[string]interface{}{"foo": 123, "rev": "1-xxx", "atts_since": []string{"1-abc"}}
+					if d := diff.Interface(expected, opts); d != nil {
+						return nil, fmt.Errorf("Unexpected options:\n%s", d)
+					}
+					return &driver.Document{Body: body(`{"_id":"foo","_rev":"1-xxx"}`)}, nil
+				case "bar":
+					return &driver.Document{Body: errReader("read error")}, nil
+				}
+				return nil, errors.New("not found")
+			},
+		},
+	}
+	rows, err := db.BulkGet(context.Background(), []BulkGetReference{
+		{ID: "foo", Rev: "1-xxx", AttsSince: []string{"1-abc"}},
+		{ID: "bar"},
+		{ID: "baz"},
+	}, testOptions)
+	if err != nil {
+		t.Fatal(err)
+	}
+	type result struct {
+		ID  string
+		Doc map[string]interface{}
+		Err string
+	}
+	var results []result
+	for rows.Next() {
+		r := result{ID: rows.ID()}
+		if e := rows.ScanDoc(&r.Doc); e != nil {
+			r.Err = e.Error()
+		}
+		results = append(results, r)
+	}
+	if err := rows.Err(); err != nil {
+		t.Fatal(err)
+	}
+	expected := []result{
+		{ID: "foo", Doc: map[string]interface{}{"_id": "foo", "_rev": "1-xxx"}},
+		{ID: "bar", Err: "read error"},
+		{ID: "baz", Err: "not found"},
+	}
+	if d := diff.Interface(expected, results); d != nil {
+		t.Error(d)
+	}
+	eb := &emulatedBulkGet{refs: []driver.BulkGetReference{{ID: "foo"}}}
+	if err := eb.Close(); err != nil {
+		t.Fatal(err)
+	}
+	if err := eb.Next(&driver.Row{}); err != io.EOF {
+		t.Errorf("Expected EOF, got %v", err)
+	}
+}
@@ -0,0 +1,125 @@
+package kivik
+
+import (
+	"context"
+
+	"github.com/go-kivik/kivik/driver"
+)
+
+// ClientCapabilities reports which optional, server-level features are
+// supported by a client's driver and server.
+type ClientCapabilities struct {
+	// Replication is true if the driver supports Replicate and
+	// GetReplications.
+	Replication bool
+	// DBUpdates is true if the driver supports the DBUpdates feed.
+	DBUpdates bool
+	// Sessions is true if the driver supports Session.
+	Sessions bool
+	// Cluster is true if the driver supports the cluster management
+	// methods, such as Membership.
+	Cluster bool
+	// Scheduler is true if the driver supports SchedulerJobs and
+	// SchedulerDocs.
+	Scheduler bool
+	// Stats is true if the driver supports node statistics.
+	Stats bool
+	// ActiveTasks is true if the driver supports ActiveTasks.
+	ActiveTasks bool
+	// Features is the list of optional features reported by the server, such
+	// as "scheduler" or "partitioned". It is empty for servers which do not
+	// report features, such as CouchDB versions prior to 2.1.
+	Features []string
+}
+
+// HasFeature returns true if the server reported the named feature.
+func (c *ClientCapabilities) HasFeature(feature string) bool {
+	for _, f := range c.Features {
+		if f == feature {
+			return true
+		}
+	}
+	return false
+}
+
+// Capabilities reports which optional features are supported by the driver,
+// as determined by the driver interfaces it implements, and the feature list
+// reported by the server. This allows applications to degrade gracefully,
+// rather than relying on StatusNotImplemented errors.
+//
+// Note that a driver may support a feature that the server does not. For
+// instance, Cluster will be true for the CouchDB driver even when connected
+// to CouchDB 1.6.
+func (c *Client) Capabilities(ctx context.Context) (*ClientCapabilities, error) {
+	var ver *driver.Version
+	err := c.do(ctx, &Operation{Name: "Version"}, false, func(ctx context.Context) error {
+		var e error
+		ver, e = c.driverClient.Version(ctx)
+		return e
+	})
+	if err != nil {
+		return nil, err
+	}
+	caps := &ClientCapabilities{
+		Features: ver.Features,
+	}
+	_, caps.Replication = c.driverClient.(driver.ClientReplicator)
+	_, caps.DBUpdates = c.driverClient.(driver.DBUpdater)
+	if _, ok := c.driverClient.(driver.ContextDBUpdater); ok {
+		caps.DBUpdates = true
+	}
+	_, caps.Sessions = c.driverClient.(driver.Sessioner)
+	_, caps.Cluster = c.driverClient.(driver.Cluster)
+	_, caps.Scheduler = c.driverClient.(driver.Scheduler)
+	_, caps.Stats = c.driverClient.(driver.Stater)
+	_, caps.ActiveTasks = c.driverClient.(driver.ActiveTasker)
+	return caps, nil
+}
+
+// DBCapabilities reports which optional, database-level features are
+// supported by a DB's driver. Features which Kivik emulates when the driver
+// does not support them natively, such as BulkGet and Copy, are reported as
+// false when emulation is in use.
+type DBCapabilities struct {
+	// Find is true if the driver supports Mango queries and indexes.
+	Find bool
+	// Purge is true if the driver supports Purge.
+	Purge bool
+	// Partitions is true if the driver supports the partitioned database
+	// methods, such as PartitionAllDocs.
+	Partitions bool
+	// BulkDocs is true if the driver supports bulk updates natively.
+	BulkDocs bool
+	// BulkGet is true if the driver supports bulk fetches natively.
+	BulkGet bool
+	// Copy is true if the driver supports Copy natively.
+	Copy bool
+	// RevsDiff is true if the driver supports RevsDiff.
+	RevsDiff bool
+	// OpenRevs is true if the driver supports GetOpenRevs.
+	OpenRevs bool
+	// DesignDocs is true if the driver supports DesignDocs.
+	DesignDocs bool
+	// LocalDocs is true if the driver supports LocalDocs.
+	LocalDocs bool
+	// Flush is true if the driver supports Flush.
+	Flush bool
+}
+
+// Capabilities reports which optional features are supported by the DB's
+// driver, as determined by the driver interfaces it implements.
+func (db *DB) Capabilities() *DBCapabilities {
+	caps := &DBCapabilities{}
+	_, caps.Find = db.driverDB.(driver.Finder)
+	_, caps.Purge = db.driverDB.(driver.Purger)
+	_, caps.Partitions = db.driverDB.(driver.Partitioner)
+	_, caps.BulkDocs = db.driverDB.(driver.BulkDocer)
+	_, caps.BulkGet = db.driverDB.(driver.BulkGetter)
+	_, caps.Copy = db.driverDB.(driver.Copier)
+	_, caps.RevsDiff = db.driverDB.(driver.RevsDiffer)
+	_, caps.OpenRevs = db.driverDB.(driver.OpenRever)
+	_, caps.DesignDocs = db.driverDB.(driver.DesignDocer)
+	_, caps.LocalDocs = db.driverDB.(driver.LocalDocer)
+	_, caps.Flush = db.driverDB.(driver.Flusher)
+	return caps
+}
@@ -0,0 +1,101 @@
+package kivik
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/flimzy/diff"
+	"github.com/flimzy/testy"
+	"github.com/go-kivik/kivik/driver"
+	"github.com/go-kivik/kivik/mock"
+)
+
+func TestClientCapabilities(t *testing.T) {
+	version := func(_ context.Context) (*driver.Version, error) {
+		return &driver.Version{Version: "2.2.0", Features: []string{"scheduler"}}, nil
+	}
+	tests := []struct {
+		name     string
+		client   driver.Client
+		expected *ClientCapabilities
+		status   int
+		err      string
+	}{
+		{
+			name: "version error",
+			client: &mock.Client{
+				VersionFunc: func(_ context.Context) (*driver.Version, error) {
+					return nil, errors.New("version error")
+				},
+			},
+			status: StatusInternalServerError,
+			err:    "version error",
+		},
+		{
+			name:     "no optional features",
+			client:   &mock.Client{VersionFunc: version},
+			expected: &ClientCapabilities{Features: []string{"scheduler"}},
+		},
+		{
+			name: "scheduler",
+			client: &mock.Scheduler{
+				Client: &mock.Client{VersionFunc: version},
+			},
+			expected: &ClientCapabilities{Scheduler: true, Features: []string{"scheduler"}},
+		},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			c := &Client{driverClient: test.client}
+			result, err := c.Capabilities(context.Background())
+			testy.StatusError(t, test.err, test.status, err)
+			if d := diff.Interface(test.expected, result); d != nil {
+				t.Error(d)
+			}
+		})
+	}
+}
+
+func TestClientCapabilitiesHasFeature(t *testing.T) {
+	caps := &ClientCapabilities{Features: []string{"scheduler", "partitioned"}}
+	if !caps.HasFeature("partitioned") {
+		t.Error("Expected partitioned feature")
+	}
+	if caps.HasFeature("foo") {
+		t.Error("Unexpected foo feature")
+	}
+}
+
+func TestDBCapabilities(t *testing.T) {
+	tests := []struct {
+		name     string
+		db       driver.DB
+		expected *DBCapabilities
+	}{
+		{
+			name:     "no optional features",
+			db:       &mock.DB{},
+			expected: &DBCapabilities{},
+		},
+		{
+			name:     "finder",
+			db:       &mock.Finder{},
+			expected: &DBCapabilities{Find: true},
+		},
+		{
+			name:     "partitioner",
+			db:       &mock.Partitioner{},
+			expected: &DBCapabilities{Partitions: true},
+		},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			db := &DB{driverDB: test.db}
+			result := db.Capabilities()
+			if d := diff.Interface(test.expected, result); d != nil {
+				t.Error(d)
+			}
+		})
+	}
+}
@@ -10,6 +10,7 @@ import (
 type Changes struct {
 	*iter
 	changesi driver.Changes
+	codec    JSONCodec
 }
 
 // Next prepares the next result value for reading. It returns true on success
@@ -40,10 +41,11 @@ var _ iterator = &changesIterator{}
 
 func (c *changesIterator) Next(i interface{}) error { return c.Changes.Next(i.(*driver.Change)) }
 
-func newChanges(ctx context.Context, changesi driver.Changes) *Changes {
+func newChanges(ctx context.Context, codec JSONCodec, changesi driver.Changes) *Changes {
 	return &Changes{
 		iter:     newIterator(ctx, &changesIterator{changesi}, &driver.Change{}),
 		changesi: changesi,
+		codec:    codec,
 	}
 }
 
@@ -62,6 +64,28 @@ func (c *Changes) ID() string {
 	return c.curVal.(*driver.Change).ID
 }
 
+// Seq returns the update sequence of the current result.
+func (c *Changes) Seq() SequenceID {
+	return SequenceID(c.curVal.(*driver.Change).Seq)
+}
+
+// LastSeq returns the last update sequence id present in the change set, if
+// returned by the server. This value is only guaranteed to be set after all
+// changes have been enumerated through by Next, and thus should only be read
+// after processing all changes. Calling Close before enumerating will render
+// this value unreliable.
+func (c *Changes) LastSeq() SequenceID {
+	return SequenceID(c.changesi.LastSeq())
+}
+
+// Pending returns the count of remaining items in the change feed. This value
+// is only guaranteed to be set after all changes have been enumerated through
+// by Next, and thus should only be read after processing all changes. Calling
+// Close before enumerating will render this value unreliable.
+func (c *Changes) Pending() int64 {
+	return c.changesi.Pending()
+}
+
 // ScanDoc works the same as ScanValue, but on the doc field of the result. It
 // is only valid for results that include documents.
 func (c *Changes) ScanDoc(dest interface{}) error {
@@ -70,20 +94,30 @@ func (c *Changes) ScanDoc(dest interface{}) error {
 		return err
 	}
 	defer runlock()
-	return scan(dest, c.curVal.(*driver.Change).Doc)
+	return scan(c.codec, dest, c.curVal.(*driver.Change).Doc)
 }
 
 // Changes returns an iterator over the real-time changes feed. The feed remains
 // open until explicitly closed, or an error is encountered.
 // See http://couchdb.readthedocs.io/en/latest/api/database/changes.html#get--db-_changes
 func (db *DB) Changes(ctx context.Context, options ...Options) (*Changes, error) {
-	opts, err := mergeOptions(options...)
+	changesi, err := db.changes(ctx, options)
 	if err != nil {
 		return nil, err
 	}
-	changesi, err := db.driverDB.Changes(ctx, opts)
+	return newChanges(ctx, db.client.codec(), changesi), nil
+}
+
+func (db *DB) changes(ctx context.Context, options []Options) (driver.Changes, error) {
+	opts, err := mergeOptions(options...)
 	if err != nil {
 		return nil, err
 	}
-	return newChanges(ctx, changesi), nil
+	var changesi driver.Changes
+	err = db.do(ctx, &Operation{Name: "Changes", Options: opts}, false, func(ctx context.Context) error {
+		var e error
+		changesi, e = db.driverDB.Changes(ctx, opts)
+		return e
+	})
+	return changesi, err
 }
@@ -80,7 +80,7 @@ func TestChangesIteratorNext(t *testing.T) {
 }
 
 func TestChangesIteratorNew(t *testing.T) {
-	ch := newChanges(context.Background(), &mock.Changes{})
+	ch := newChanges(context.Background(), nil, &mock.Changes{})
 	expected := &Changes{
 		iter: &iter{
 			feed: &changesIterator{
@@ -101,10 +101,15 @@ func TestChangesGetters(t *testing.T) {
 		iter: &iter{
 			curVal: &driver.Change{
 				ID:      "foo",
+				Seq:     "2-abc",
 				Deleted: true,
 				Changes: []string{"1", "2", "3"},
 			},
 		},
+		changesi: &mock.Changes{
+			LastSeqFunc: func() string { return "3-xyz" },
+			PendingFunc: func() int64 { return 5 },
+		},
 	}
 
 	t.Run("Changes", func(t *testing.T) {
@@ -130,6 +135,30 @@ func TestChangesGetters(t *testing.T) {
 			t.Errorf("Unexpected result: %v", result)
 		}
 	})
+
+	t.Run("Seq", func(t *testing.T) {
+		expected := SequenceID("2-abc")
+		result := c.Seq()
+		if expected != result {
+			t.Errorf("Unexpected result: %v", result)
+		}
+	})
+
+	t.Run("LastSeq", func(t *testing.T) {
+		expected := SequenceID("3-xyz")
+		result := c.LastSeq()
+		if expected != result {
+			t.Errorf("Unexpected result: %v", result)
+		}
+	})
+
+	t.Run("Pending", func(t *testing.T) {
+		expected := int64(5)
+		result := c.Pending()
+		if expected != result {
+			t.Errorf("Unexpected result: %v", result)
+		}
+	})
 }
 
 func TestChangesScanDoc(t *testing.T) {
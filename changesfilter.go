@@ -0,0 +1,82 @@
+package kivik
+
+import (
+	"context"
+	"encoding/json"
+
+	"github.com/go-kivik/kivik/driver"
+)
+
+// ChangesFilter filters the changes feed on the client, as an alternative to
+// a filter function in a design document. See FilterChanges.
+type ChangesFilter struct {
+	// Match reports whether a change should be delivered. The change's Doc is
+	// only set if the include_docs option is set. If Match is nil, all
+	// changes are delivered.
+	Match func(change ChangeEvent) bool
+	// Transform, if set, replaces the document of each delivered change which
+	// includes a document.
+	Transform func(doc json.RawMessage) (json.RawMessage, error)
+}
+
+// FilterChanges works like Changes, but only delivers the changes accepted by
+// filter. To filter on document content, set the include_docs option.
+//
+// As filtering happens on the client, every change is still transferred from
+// the server. LastSeq reports the sequence of the last change read, whether
+// or not it was delivered, so that a consumer may resume from it.
+func (db *DB) FilterChanges(ctx context.Context, filter *ChangesFilter, options ...Options) (*Changes, error) {
+	changesi, err := db.changes(ctx, options)
+	if err != nil {
+		return nil, err
+	}
+	if filter != nil {
+		changesi = &filteredChanges{Changes: changesi, filter: filter}
+	}
+	return newChanges(ctx, db.client.codec(), changesi), nil
+}
+
+// filteredChanges is a driver.Changes which skips changes rejected by a
+// ChangesFilter.
+type filteredChanges struct {
+	driver.Changes
+	filter  *ChangesFilter
+	lastSeq driver.SequenceID
+}
+
+var _ driver.Changes = &filteredChanges{}
+
+func (c *filteredChanges) Next(change *driver.Change) error {
+	for {
+		if err := c.Changes.Next(change); err != nil {
+			return err
+		}
+		if change.Seq != "" {
+			c.lastSeq = change.Seq
+		}
+		if c.filter.Match != nil && !c.filter.Match(ChangeEvent{
+			ID:      change.ID,
+			Seq:     SequenceID(change.Seq),
+			Deleted: change.Deleted,
+			Changes: change.Changes,
+			Doc:     change.Doc,
+		}) {
+			continue
+		}
+		if c.filter.Transform != nil && change.Doc != nil {
+			doc, err := c.filter.Transform(change.Doc)
+			if err != nil {
+				return err
+			}
+			change.Doc = doc
+		}
+		return nil
+	}
+}
+
+func (c *filteredChanges) LastSeq() string {
+	if seq := c.Changes.LastSeq(); seq != "" {
+		return seq
+	}
+	return string(c.lastSeq)
+}
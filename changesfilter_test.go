@@ -0,0 +1,115 @@
+package kivik
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"strings"
+	"testing"
+
+	"github.com/flimzy/diff"
+	"github.com/flimzy/testy"
+	"github.com/go-kivik/kivik/driver"
+	"github.com/go-kivik/kivik/errors"
+	"github.com/go-kivik/kivik/mock"
+)
+
+func TestFilterChanges(t *testing.T) {
+	feed := []driver.Change{
+		{ID: "a", Seq: "1", Doc: json.RawMessage(`{"type":"widget"}`)},
+		{ID: "b", Seq: "2", Doc: json.RawMessage(`{"type":"gadget"}`)},
+		{ID: "c", Seq: "3", Deleted: true},
+		{ID: "d", Seq: "4", Doc: json.RawMessage(`{"type":"gadget"}`)},
+	}
+	isWidget := func(change ChangeEvent) bool {
+		return change.Deleted || strings.Contains(string(change.Doc), "widget")
+	}
+	tests := []struct {
+		name     string
+		filter   *ChangesFilter
+		err      error
+		expected []string
+		lastSeq  SequenceID
+		status   int
+		errMsg   string
+	}{
+		{
+			name:     "no filter",
+			expected: []string{"a", "b", "c", "d"},
+			lastSeq:  "",
+		},
+		{
+			name:     "match",
+			filter:   &ChangesFilter{Match: isWidget},
+			expected: []string{"a", "c"},
+			lastSeq:  "4",
+		},
+		{
+			name: "transform",
+			filter: &ChangesFilter{
+				Match: isWidget,
+				Transform: func(_ json.RawMessage) (json.RawMessage, error) {
+					return json.RawMessage(`{}`), nil
+				},
+			},
+			expected: []string{"a:{}", "c"},
+			lastSeq:  "4",
+		},
+		{
+			name: "transform error",
+			filter: &ChangesFilter{
+				Transform: func(_ json.RawMessage) (json.RawMessage, error) {
+					return nil, errors.Status(StatusBadRequest, "transform failed")
+				},
+			},
+			status: StatusBadRequest,
+			errMsg: "transform failed",
+		},
+		{
+			name:   "changes error",
+			filter: &ChangesFilter{Match: isWidget},
+			err:    errors.Status(StatusUnauthorized, "unauthorized"),
+			status: StatusUnauthorized,
+			errMsg: "unauthorized",
+		},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			db := &DB{
+				driverDB: &mock.DB{
+					ChangesFunc: func(_ context.Context, _ map[string]interface{}) (driver.Changes, error) {
+						if test.err != nil {
+							return nil, test.err
+						}
+						changes := changesFeed(append([]driver.Change{}, feed...), io.EOF)
+						changes.LastSeqFunc = func() string { return "" }
+						return changes, nil
+					},
+				},
+			}
+			changes, err := db.FilterChanges(context.Background(), test.filter, IncludeDocs())
+			if err == nil {
+				var ids []string
+				for changes.Next() {
+					id := changes.ID()
+					if test.filter != nil && test.filter.Transform != nil && !changes.Deleted() {
+						var doc json.RawMessage
+						_ = changes.ScanDoc(&doc)
+						id += ":" + string(doc)
+					}
+					ids = append(ids, id)
+				}
+				err = changes.Err()
+				if err == nil {
+					if d := diff.Interface(test.expected, ids); d != nil {
+						t.Error(d)
+					}
+					if seq := changes.LastSeq(); seq != test.lastSeq {
+						t.Errorf("Unexpected LastSeq: %s", seq)
+					}
+				}
+			}
+			testy.StatusError(t, test.errMsg, test.status, err)
+		})
+	}
+}
@@ -0,0 +1,82 @@
+package kivik
+
+import (
+	"context"
+
+	"github.com/go-kivik/kivik/driver"
+	"github.com/go-kivik/kivik/errors"
+)
+
+var clusterNotImplemented = errors.Status(StatusNotImplemented, "kivik: driver does not support cluster operations")
+
+// ClusterMembership contains the list of known nodes, and cluster nodes, as
+// returned by the /_membership endpoint.
+type ClusterMembership struct {
+	// AllNodes is the list of all nodes this node knows about, including
+	// those not part of the cluster.
+	AllNodes []string `json:"all_nodes"`
+	// ClusterNodes is the list of nodes which are part of the cluster.
+	ClusterNodes []string `json:"cluster_nodes"`
+}
+
+// ClusterStatus returns the current cluster status.
+//
+// See http://docs.couchdb.org/en/2.2.0/api/server/common.html#cluster-setup
+func (c *Client) ClusterStatus(ctx context.Context, options ...Options) (string, error) {
+	cluster, ok := c.driverClient.(driver.Cluster)
+	if !ok {
+		return "", clusterNotImplemented
+	}
+	opts, err := mergeOptions(options...)
+	if err != nil {
+		return "", err
+	}
+	var status string
+	err = c.do(ctx, &Operation{Name: "ClusterStatus", Options: opts}, false, func(ctx context.Context) error {
+		var e error
+		status, e = cluster.ClusterStatus(ctx, opts)
+		return e
+	})
+	return status, err
+}
+
+// ClusterSetup performs the requested cluster action. action should be
+// an object understood by the driver. For the CouchDB driver, this means an
+// object which is marshalable to a JSON object of the expected format, such as
+// {"action": "enable_single_node", "username": "admin", "password": "abc123"}.
+//
+// See http://docs.couchdb.org/en/2.2.0/api/server/common.html#post--_cluster_setup
+func (c *Client) ClusterSetup(ctx context.Context, action interface{}) error {
+	cluster, ok := c.driverClient.(driver.Cluster)
+	if !ok {
+		return clusterNotImplemented
+	}
+	if action == nil {
+		return missingArg("action")
+	}
+	return c.do(ctx, &Operation{Name: "ClusterSetup"}, true, func(ctx context.Context) error {
+		return cluster.ClusterSetup(ctx, action)
+	})
+}
+
+// Membership returns a list of all known nodes, and all nodes configured as
+// part of the cluster.
+//
+// See http://docs.couchdb.org/en/2.2.0/api/server/common.html#membership
+func (c *Client) Membership(ctx context.Context) (*ClusterMembership, error) {
+	cluster, ok := c.driverClient.(driver.Cluster)
+	if !ok {
+		return nil, clusterNotImplemented
+	}
+	var nodes *driver.ClusterMembership
+	err := c.do(ctx, &Operation{Name: "Membership"}, false, func(ctx context.Context) error {
+		var e error
+		nodes, e = cluster.Membership(ctx)
+		return e
+	})
+	if err != nil {
+		return nil, err
+	}
+	m := ClusterMembership(*nodes)
+	return &m, nil
+}
@@ -0,0 +1,170 @@
+package kivik
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"testing"
+
+	"github.com/flimzy/diff"
+	"github.com/flimzy/testy"
+	"github.com/go-kivik/kivik/driver"
+	"github.com/go-kivik/kivik/mock"
+)
+
+func TestClusterStatus(t *testing.T) {
+	tests := []struct {
+		name     string
+		client   driver.Client
+		options  Options
+		expected string
+		status   int
+		err      string
+	}{
+		{
+			name:   "driver doesn't implement Cluster interface",
+			client: &mock.Client{},
+			status: StatusNotImplemented,
+			err:    "kivik: driver does not support cluster operations",
+		},
+		{
+			name: "client error",
+			client: &mock.Cluster{
+				ClusterStatusFunc: func(_ context.Context, _ map[string]interface{}) (string, error) {
+					return "", errors.New("client error")
+				},
+			},
+			status: StatusInternalServerError,
+			err:    "client error",
+		},
+		{
+			name: "success",
+			client: &mock.Cluster{
+				ClusterStatusFunc: func(_ context.Context, opts map[string]interface{}) (string, error) {
+					if d := diff.Interface(testOptions, opts); d != nil {
+						return "", fmt.Errorf("Unexpected options:\n%s", d)
+					}
+					return "cluster_finished", nil
+				},
+			},
+			options:  testOptions,
+			expected: "cluster_finished",
+		},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			c := &Client{driverClient: test.client}
+			result, err := c.ClusterStatus(context.Background(), test.options)
+			testy.StatusError(t, test.err, test.status, err)
+			if result != test.expected {
+				t.Errorf("Unexpected result: %s", result)
+			}
+		})
+	}
+}
+
+func TestClusterSetup(t *testing.T) {
+	tests := []struct {
+		name   string
+		client driver.Client
+		action interface{}
+		status int
+		err    string
+	}{
+		{
+			name:   "driver doesn't implement Cluster interface",
+			client: &mock.Client{},
+			status: StatusNotImplemented,
+			err:    "kivik: driver does not support cluster operations",
+		},
+		{
+			name:   "missing action",
+			client: &mock.Cluster{},
+			status: StatusBadRequest,
+			err:    "kivik: action required",
+		},
+		{
+			name: "client error",
+			client: &mock.Cluster{
+				ClusterSetupFunc: func(_ context.Context, _ interface{}) error {
+					return errors.New("client error")
+				},
+			},
+			action: "foo",
+			status: StatusInternalServerError,
+			err:    "client error",
+		},
+		{
+			name: "success",
+			client: &mock.Cluster{
+				ClusterSetupFunc: func(_ context.Context, action interface{}) error {
+					expected := map[string]string{"action": "finish_cluster"}
+					if d := diff.Interface(expected, action); d != nil {
+						return fmt.Errorf("Unexpected action:\n%s", d)
+					}
+					return nil
+				},
+			},
+			action: map[string]string{"action": "finish_cluster"},
+		},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			c := &Client{driverClient: test.client}
+			err := c.ClusterSetup(context.Background(), test.action)
+			testy.StatusError(t, test.err, test.status, err)
+		})
+	}
+}
+
+func TestMembership(t *testing.T) {
+	tests := []struct {
+		name     string
+		client   driver.Client
+		expected *ClusterMembership
+		status   int
+		err      string
+	}{
+		{
+			name:   "driver doesn't implement Cluster interface",
+			client: &mock.Client{},
+			status: StatusNotImplemented,
+			err:    "kivik: driver does not support cluster operations",
+		},
+		{
+			name: "client error",
+			client: &mock.Cluster{
+				MembershipFunc: func(_ context.Context) (*driver.ClusterMembership, error) {
+					return nil, errors.New("client error")
+				},
+			},
+			status: StatusInternalServerError,
+			err:    "client error",
+		},
+		{
+			name: "success",
+			client: &mock.Cluster{
+				MembershipFunc: func(_ context.Context) (*driver.ClusterMembership, error) {
+					return &driver.ClusterMembership{
+						AllNodes:     []string{"one", "two", "three"},
+						ClusterNodes: []string{"one", "two"},
+					}, nil
+				},
+			},
+			expected: &ClusterMembership{
+				AllNodes:     []string{"one", "two", "three"},
+				ClusterNodes: []string{"one", "two"},
+			},
+		},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			c := &Client{driverClient: test.client}
+			result, err := c.Membership(context.Background())
+			testy.StatusError(t, test.err, test.status, err)
+			if d := diff.Interface(test.expected, result); d != nil {
+				t.Error(d)
+			}
+		})
+	}
+}
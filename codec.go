@@ -0,0 +1,75 @@
+package kivik
+
+import (
+	"encoding/json"
+	"io"
+	"io/ioutil"
+)
+
+// JSONCodec encodes and decodes JSON. It may be set with Client.SetJSONCodec
+// to replace encoding/json with an alternative implementation, such as
+// jsoniter. The codec must be safe for concurrent use.
+type JSONCodec interface {
+	Marshal(v interface{}) ([]byte, error)
+	Unmarshal(data []byte, v interface{}) error
+}
+
+// stdJSON is the default JSONCodec, which uses encoding/json.
+type stdJSON struct{}
+
+var _ JSONCodec = stdJSON{}
+
+func (stdJSON) Marshal(v interface{}) ([]byte, error) { return json.Marshal(v) }
+
+func (stdJSON) Unmarshal(data []byte, v interface{}) error { return json.Unmarshal(data, v) }
+
+// SetJSONCodec sets the codec used to decode results read with the Scan
+// methods of Rows, Changes and Row, and to encode documents passed to Put and
+// CreateDoc. When a codec is set, documents are passed to the driver
+// pre-encoded, as json.RawMessage. A nil codec restores the default, which
+// uses encoding/json and leaves document encoding to the driver.
+//
+// SetJSONCodec should be called before the client is used.
+func (c *Client) SetJSONCodec(codec JSONCodec) {
+	c.jsonCodec = codec
+}
+
+// codec returns the client's JSONCodec, or nil if the default is in use. It is
+// safe to call on a nil client.
+func (c *Client) codec() JSONCodec {
+	if c == nil {
+		return nil
+	}
+	return c.jsonCodec
+}
+
+// decode reads and decodes a JSON value from r. The default codec decodes
+// from the stream directly; others require the value to be read into memory
+// first.
+func decode(codec JSONCodec, r io.Reader, dest interface{}) error {
+	if _, ok := codec.(stdJSON); ok || codec == nil {
+		return json.NewDecoder(r).Decode(dest)
+	}
+	data, err := ioutil.ReadAll(r)
+	if err != nil {
+		return err
+	}
+	return codec.Unmarshal(data, dest)
+}
+
+// encodeDoc encodes doc with codec, unless codec is the default, or doc is
+// already encoded.
+func encodeDoc(codec JSONCodec, doc interface{}) (interface{}, error) {
+	if _, ok := codec.(stdJSON); ok || codec == nil {
+		return doc, nil
+	}
+	switch doc.(type) {
+	case json.RawMessage, []byte, io.Reader:
+		return doc, nil
+	}
+	data, err := codec.Marshal(doc)
+	if err != nil {
+		return nil, err
+	}
+	return json.RawMessage(data), nil
+}
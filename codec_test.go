@@ -0,0 +1,156 @@
+package kivik
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"io/ioutil"
+	"strings"
+	"testing"
+
+	"github.com/flimzy/diff"
+	"github.com/flimzy/testy"
+	"github.com/go-kivik/kivik/driver"
+	"github.com/go-kivik/kivik/errors"
+	"github.com/go-kivik/kivik/mock"
+)
+
+// countingCodec wraps encoding/json, recording its use.
+type countingCodec struct {
+	marshaled   int
+	unmarshaled int
+	err         error
+}
+
+func (c *countingCodec) Marshal(v interface{}) ([]byte, error) {
+	c.marshaled++
+	if c.err != nil {
+		return nil, c.err
+	}
+	return json.Marshal(v)
+}
+
+func (c *countingCodec) Unmarshal(data []byte, v interface{}) error {
+	c.unmarshaled++
+	return json.Unmarshal(data, v)
+}
+
+func TestJSONCodec(t *testing.T) {
+	codec := &countingCodec{}
+	client := &Client{}
+	client.SetJSONCodec(codec)
+	var putDoc interface{}
+	db := &DB{
+		client: client,
+		driverDB: &mock.DB{
+			GetFunc: func(_ context.Context, _ string, _ map[string]interface{}) (*driver.Document, error) {
+				return &driver.Document{Rev: "1-xxx", Body: ioutil.NopCloser(strings.NewReader(`{"foo":"bar"}`))}, nil
+			},
+			PutFunc: func(_ context.Context, _ string, doc interface{}, _ map[string]interface{}) (string, error) {
+				putDoc = doc
+				return "1-xxx", nil
+			},
+			QueryFunc: func(_ context.Context, _, _ string, _ map[string]interface{}) (driver.Rows, error) {
+				var done bool
+				return &mock.Rows{
+					NextFunc: func(row *driver.Row) error {
+						if done {
+							return io.EOF
+						}
+						done = true
+						row.ID = "a"
+						row.Value = json.RawMessage(`1`)
+						return nil
+					},
+					CloseFunc: func() error { return nil },
+				}, nil
+			},
+		},
+	}
+
+	t.Run("Get", func(t *testing.T) {
+		var doc map[string]string
+		if err := db.Get(context.Background(), "foo").ScanDoc(&doc); err != nil {
+			t.Fatal(err)
+		}
+		if d := diff.Interface(map[string]string{"foo": "bar"}, doc); d != nil {
+			t.Error(d)
+		}
+		if codec.unmarshaled != 1 {
+			t.Errorf("Expected 1 call to Unmarshal, got %d", codec.unmarshaled)
+		}
+	})
+	t.Run("Query", func(t *testing.T) {
+		codec.unmarshaled = 0
+		rows, err := db.Query(context.Background(), "_design/foo", "bar")
+		if err != nil {
+			t.Fatal(err)
+		}
+		for rows.Next() {
+			var value int
+			if err := rows.ScanValue(&value); err != nil {
+				t.Fatal(err)
+			}
+		}
+		if codec.unmarshaled != 1 {
+			t.Errorf("Expected 1 call to Unmarshal, got %d", codec.unmarshaled)
+		}
+	})
+	t.Run("Put", func(t *testing.T) {
+		if _, err := db.Put(context.Background(), "foo", map[string]string{"foo": "bar"}); err != nil {
+			t.Fatal(err)
+		}
+		if codec.marshaled != 1 {
+			t.Errorf("Expected 1 call to Marshal, got %d", codec.marshaled)
+		}
+		if d := diff.Interface(json.RawMessage(`{"foo":"bar"}`), putDoc); d != nil {
+			t.Error(d)
+		}
+	})
+	t.Run("Put error", func(t *testing.T) {
+		codec.err = errors.New("encode failed")
+		defer func() { codec.err = nil }()
+		_, err := db.Put(context.Background(), "foo", map[string]string{"foo": "bar"})
+		testy.StatusError(t, "encode failed", StatusBadRequest, err)
+	})
+	t.Run("default", func(t *testing.T) {
+		client.SetJSONCodec(nil)
+		defer client.SetJSONCodec(codec)
+		doc := map[string]string{"foo": "bar"}
+		if _, err := db.Put(context.Background(), "foo", doc); err != nil {
+			t.Fatal(err)
+		}
+		if d := diff.Interface(doc, putDoc); d != nil {
+			t.Error(d)
+		}
+	})
+}
+
+func TestJSONCodecBulkDocs(t *testing.T) {
+	codec := &countingCodec{}
+	client := &Client{}
+	client.SetJSONCodec(codec)
+	var bulkDocs []interface{}
+	db := &DB{
+		client: client,
+		driverDB: &mock.BulkDocer{
+			BulkDocsFunc: func(_ context.Context, docs []interface{}, _ map[string]interface{}) (driver.BulkResults, error) {
+				bulkDocs = docs
+				return &emulatedBulkResults{}, nil
+			},
+		},
+	}
+	if _, err := db.BulkDocs(context.Background(), []interface{}{map[string]string{"foo": "bar"}, []byte(`{"_id":"baz"}`)}); err != nil {
+		t.Fatal(err)
+	}
+	expected := []interface{}{
+		json.RawMessage(`{"foo":"bar"}`),
+		json.RawMessage(`{"_id":"baz"}`),
+	}
+	if d := diff.Interface(expected, bulkDocs); d != nil {
+		t.Error(d)
+	}
+	codec.err = errors.New("encode failed")
+	_, err := db.BulkDocs(context.Background(), []interface{}{map[string]string{"foo": "bar"}})
+	testy.StatusError(t, "encode failed", StatusBadRequest, err)
+}
@@ -0,0 +1,75 @@
+package kivik
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/go-kivik/kivik/errors"
+)
+
+// Conflicts returns the revisions of docID which conflict with its current,
+// winning, revision, as reported with the conflicts option. The result is
+// empty if the document has no conflicts. The content of each revision may
+// be read with GetOpenRevs.
+func (db *DB) Conflicts(ctx context.Context, docID string, options ...Options) ([]string, error) {
+	_, conflicts, err := db.conflicts(ctx, docID, options)
+	return conflicts, err
+}
+
+// conflicts returns the winning revision of docID, and its conflicts.
+func (db *DB) conflicts(ctx context.Context, docID string, options []Options) (rev string, conflicts []string, err error) {
+	if docID == "" {
+		return "", nil, missingArg("docID")
+	}
+	options = append(options[:len(options):len(options)], Param("conflicts", true))
+	var doc struct {
+		Rev       string   `json:"_rev"`
+		Conflicts []string `json:"_conflicts"`
+	}
+	if err := db.Get(ctx, docID, options...).ScanDoc(&doc); err != nil {
+		return "", nil, err
+	}
+	return doc.Rev, doc.Conflicts, nil
+}
+
+// ResolveConflict resolves any conflicts on docID in a single BulkDocs
+// request, by storing doc as a new revision of the winning revision, and
+// deleting every conflicting revision. doc is typically the result of merging
+// the conflicting revisions; any _id or _rev fields it contains are replaced.
+// The revision of the stored document is returned.
+//
+// Should the document be updated between reading its conflicts and writing
+// the resolution, the request fails with StatusConflict, and may be retried.
+// As BulkDocs is not atomic, some conflicting revisions may have been deleted
+// when an error is returned.
+func (db *DB) ResolveConflict(ctx context.Context, docID string, doc interface{}) (newRev string, err error) {
+	rev, conflicts, err := db.conflicts(ctx, docID, nil)
+	if err != nil {
+		return "", err
+	}
+	bulk := new(Bulk).Update(docID, rev, doc)
+	for _, conflict := range conflicts {
+		bulk.Delete(docID, conflict)
+	}
+	results, err := db.BulkWrite(ctx, bulk)
+	if err != nil {
+		return "", err
+	}
+	defer results.Close() // nolint: errcheck
+	for results.Next() {
+		if updateErr := results.UpdateErr(); updateErr != nil {
+			if results.Index() == 0 {
+				return "", updateErr
+			}
+			return "", errors.WrapStatus(StatusCode(updateErr), fmt.Errorf("kivik: delete revision %s: %s", conflicts[results.Index()-1], updateErr))
+		}
+		if results.Index() == 0 {
+			newRev = results.Rev()
+		}
+	}
+	if err := results.Err(); err != nil {
+		return "", err
+	}
+	updateDocument(doc, docID, newRev)
+	return newRev, nil
+}
@@ -0,0 +1,189 @@
+package kivik
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"strings"
+	"testing"
+
+	"github.com/flimzy/diff"
+	"github.com/flimzy/testy"
+	"github.com/go-kivik/kivik/driver"
+	"github.com/go-kivik/kivik/errors"
+	"github.com/go-kivik/kivik/mock"
+)
+
+func conflictsDB(body string, bulkDocs func([]interface{}) ([]driver.BulkResult, error)) *DB {
+	return &DB{
+		driverDB: &mock.BulkDocer{
+			DB: &mock.DB{
+				GetFunc: func(_ context.Context, docID string, opts map[string]interface{}) (*driver.Document, error) {
+					if opts["conflicts"] != true {
+						return nil, fmt.Errorf("Unexpected options: %v", opts)
+					}
+					if body == "" {
+						return nil, errors.Status(StatusNotFound, "missing")
+					}
+					return &driver.Document{Body: ioutil.NopCloser(strings.NewReader(body))}, nil
+				},
+			},
+			BulkDocsFunc: func(_ context.Context, docs []interface{}, _ map[string]interface{}) (driver.BulkResults, error) {
+				results, err := bulkDocs(docs)
+				if err != nil {
+					return nil, err
+				}
+				return &mock.BulkResults{
+					NextFunc: func(result *driver.BulkResult) error {
+						if len(results) == 0 {
+							return io.EOF
+						}
+						*result, results = results[0], results[1:]
+						return nil
+					},
+					CloseFunc: func() error { return nil },
+				}, nil
+			},
+		},
+	}
+}
+
+func TestConflicts(t *testing.T) {
+	tests := []struct {
+		name     string
+		docID    string
+		body     string
+		expected []string
+		status   int
+		err      string
+	}{
+		{
+			name:   "no docID",
+			status: StatusBadRequest,
+			err:    "kivik: docID required",
+		},
+		{
+			name:   "not found",
+			docID:  "foo",
+			status: StatusNotFound,
+			err:    "missing",
+		},
+		{
+			name:  "no conflicts",
+			docID: "foo",
+			body:  `{"_id":"foo","_rev":"2-a"}`,
+		},
+		{
+			name:     "conflicts",
+			docID:    "foo",
+			body:     `{"_id":"foo","_rev":"2-a","_conflicts":["2-b","1-c"]}`,
+			expected: []string{"2-b", "1-c"},
+		},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			db := conflictsDB(test.body, nil)
+			result, err := db.Conflicts(context.Background(), test.docID)
+			testy.StatusError(t, test.err, test.status, err)
+			if d := diff.Interface(test.expected, result); d != nil {
+				t.Error(d)
+			}
+		})
+	}
+}
+
+func TestResolveConflict(t *testing.T) {
+	type doc struct {
+		Document
+		Name string `json:"name"`
+	}
+	tests := []struct {
+		name     string
+		body     string
+		results  []driver.BulkResult
+		bulkErr  error
+		docs     []map[string]interface{}
+		expected string
+		status   int
+		err      string
+	}{
+		{
+			name:   "not found",
+			status: StatusNotFound,
+			err:    "missing",
+		},
+		{
+			name: "resolved",
+			body: `{"_id":"foo","_rev":"2-a","_conflicts":["2-b","1-c"]}`,
+			results: []driver.BulkResult{
+				{ID: "foo", Rev: "3-a"},
+				{ID: "foo", Rev: "3-b"},
+				{ID: "foo", Rev: "2-c"},
+			},
+			docs: []map[string]interface{}{
+				{"_id": "foo", "_rev": "2-a", "name": "merged"},
+				{"_id": "foo", "_rev": "2-b", "_deleted": true},
+				{"_id": "foo", "_rev": "1-c", "_deleted": true},
+			},
+			expected: "3-a",
+		},
+		{
+			name:    "request failed",
+			body:    `{"_id":"foo","_rev":"2-a"}`,
+			bulkErr: errors.Status(StatusServiceUnavailable, "unavailable"),
+			docs: []map[string]interface{}{
+				{"_id": "foo", "_rev": "2-a", "name": "merged"},
+			},
+			status: StatusServiceUnavailable,
+			err:    "unavailable",
+		},
+		{
+			name: "update conflict",
+			body: `{"_id":"foo","_rev":"2-a","_conflicts":["2-b"]}`,
+			results: []driver.BulkResult{
+				{ID: "foo", Error: errors.Status(StatusConflict, "conflict")},
+				{ID: "foo", Rev: "3-b"},
+			},
+			docs: []map[string]interface{}{
+				{"_id": "foo", "_rev": "2-a", "name": "merged"},
+				{"_id": "foo", "_rev": "2-b", "_deleted": true},
+			},
+			status: StatusConflict,
+			err:    "conflict",
+		},
+		{
+			name: "delete failed",
+			body: `{"_id":"foo","_rev":"2-a","_conflicts":["2-b"]}`,
+			results: []driver.BulkResult{
+				{ID: "foo", Rev: "3-a"},
+				{ID: "foo", Error: errors.Status(StatusConflict, "conflict")},
+			},
+			docs: []map[string]interface{}{
+				{"_id": "foo", "_rev": "2-a", "name": "merged"},
+				{"_id": "foo", "_rev": "2-b", "_deleted": true},
+			},
+			status: StatusConflict,
+			err:    "kivik: delete revision 2-b: conflict",
+		},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			db := conflictsDB(test.body, func(docs []interface{}) ([]driver.BulkResult, error) {
+				if d := diff.AsJSON(test.docs, docs); d != nil {
+					return nil, fmt.Errorf("Unexpected docs:\n%s", d)
+				}
+				return test.results, test.bulkErr
+			})
+			merged := &doc{Name: "merged"}
+			rev, err := db.ResolveConflict(context.Background(), "foo", merged)
+			testy.StatusError(t, test.err, test.status, err)
+			if rev != test.expected {
+				t.Errorf("Unexpected rev: %s", rev)
+			}
+			if err == nil && merged.Rev != test.expected {
+				t.Errorf("Document not updated: %+v", merged.Document)
+			}
+		})
+	}
+}
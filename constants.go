@@ -14,6 +14,10 @@ const SessionCookieName = "AuthSession"
 // See http://docs.couchdb.org/en/2.0.0/intro/security.html#org-couchdb-user
 const UserPrefix = "org.couchdb.user:"
 
+// OptionHTTPClient is the NewWithOptions option key used to pass a custom
+// *http.Client to an HTTP-based driver. See HTTPClient.
+const OptionHTTPClient = "kivik:http_client"
+
 // EndKeySuffix is a high Unicode character (0xfff0) useful for appending to an
 // endkey argument, when doing a ranged search, as described here:
 // http://couchdb.readthedocs.io/en/latest/ddocs/views/collation.html#string-ranges
@@ -58,12 +62,17 @@ const (
 	StatusUnsupportedMediaType         = 415
 	StatusRequestedRangeNotSatisfiable = 416
 	StatusExpectationFailed            = 417
+	StatusTooManyRequests              = 429
 	StatusInternalServerError          = 500
 
 	// StatusNotImplemented is not returned by CouchDB proper. It is used by
 	// Kivik for optional features which are not implemented by some drivers.
 	StatusNotImplemented = 501
 
+	// StatusServiceUnavailable is returned by CouchDB, or a proxy in front of
+	// it, when the server is temporarily unable to handle the request.
+	StatusServiceUnavailable = 503
+
 	// Error status over 600 are obviously not proper HTTP errors at all. They
 	// are used for kivik-generated errors of various types.
 
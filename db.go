@@ -29,17 +29,60 @@ func (db *DB) Name() string {
 	return db.name
 }
 
+// Close cleans up any resources used by the DB, if supported by the driver.
+// The DB should not be used after Close is called. For drivers which hold no
+// such resources, Close is a no-op.
+func (db *DB) Close(ctx context.Context) error {
+	if closer, ok := db.driverDB.(driver.DBCloser); ok {
+		return closer.Close(ctx)
+	}
+	return nil
+}
+
 // AllDocs returns a list of all documents in the database.
 func (db *DB) AllDocs(ctx context.Context, options ...Options) (*Rows, error) {
 	opts, err := mergeOptions(options...)
 	if err != nil {
 		return nil, err
 	}
-	rowsi, err := db.driverDB.AllDocs(ctx, opts)
+	return db.doRows(ctx, &Operation{Name: "AllDocs", Options: opts}, func(ctx context.Context) (driver.Rows, error) {
+		return db.driverDB.AllDocs(ctx, opts)
+	})
+}
+
+// DesignDocs returns a list of all design documents in the database.
+//
+// See http://docs.couchdb.org/en/2.2.0/api/database/bulk-api.html#db-design-docs
+func (db *DB) DesignDocs(ctx context.Context, options ...Options) (*Rows, error) {
+	ddocer, ok := db.driverDB.(driver.DesignDocer)
+	if !ok {
+		return nil, errors.Status(StatusNotImplemented, "kivik: design doc view not supported by driver")
+	}
+	opts, err := mergeOptions(options...)
+	if err != nil {
+		return nil, err
+	}
+	return db.doRows(ctx, &Operation{Name: "DesignDocs", Options: opts}, func(ctx context.Context) (driver.Rows, error) {
+		return ddocer.DesignDocs(ctx, opts)
+	})
+}
+
+// LocalDocs returns a list of all local documents in the database, such as
+// replication checkpoints, which are otherwise not returned by AllDocs.
+//
+// See http://docs.couchdb.org/en/2.2.0/api/local.html#db-local-docs
+func (db *DB) LocalDocs(ctx context.Context, options ...Options) (*Rows, error) {
+	ldocer, ok := db.driverDB.(driver.LocalDocer)
+	if !ok {
+		return nil, errors.Status(StatusNotImplemented, "kivik: local doc view not supported by driver")
+	}
+	opts, err := mergeOptions(options...)
 	if err != nil {
 		return nil, err
 	}
-	return newRows(ctx, rowsi), nil
+	return db.doRows(ctx, &Operation{Name: "LocalDocs", Options: opts}, func(ctx context.Context) (driver.Rows, error) {
+		return ldocer.LocalDocs(ctx, opts)
+	})
 }
 
 // Query executes the specified view function from the specified design
@@ -52,11 +95,9 @@ func (db *DB) Query(ctx context.Context, ddoc, view string, options ...Options)
 	}
 	ddoc = strings.TrimPrefix(ddoc, "_design/")
 	view = strings.TrimPrefix(view, "_view/")
-	rowsi, err := db.driverDB.Query(ctx, ddoc, view, opts)
-	if err != nil {
-		return nil, err
-	}
-	return newRows(ctx, rowsi), nil
+	return db.doRows(ctx, &Operation{Name: "Query", Options: opts}, func(ctx context.Context) (driver.Rows, error) {
+		return db.driverDB.Query(ctx, ddoc, view, opts)
+	})
 }
 
 // Row contains the result of calling Get for a single document. For most uses,
@@ -82,13 +123,18 @@ type Row struct {
 	// typically returned by ScanDoc.
 	Err error
 
-	// Attachments is experimental
+	// Attachments iterates over the document's attachments, when the
+	// "attachments" option is passed to Get. It is nil otherwise.
 	Attachments *AttachmentsIterator
+
+	codec JSONCodec
 }
 
 // ScanDoc unmarshals the data from the fetched row into dest. It is an
 // intelligent wrapper around json.Unmarshal which also handles
 // multipart/related responses. When done, the underlying reader is closed.
+// If dest embeds Document, and the body does not include a _rev field, the
+// revision is set from r.Rev.
 func (r *Row) ScanDoc(dest interface{}) error {
 	if r.Err != nil {
 		return r.Err
@@ -97,17 +143,33 @@ func (r *Row) ScanDoc(dest interface{}) error {
 		return errNonPtr
 	}
 	defer r.Body.Close() // nolint: errcheck
-	return errors.WrapStatus(StatusBadResponse, json.NewDecoder(r.Body).Decode(dest))
+	if err := decode(r.codec, r.Body, dest); err != nil {
+		return errors.WrapStatus(StatusBadResponse, err)
+	}
+	if d, ok := dest.(documenter); ok && d.document().Rev == "" {
+		d.document().Rev = r.Rev
+	}
+	return nil
 }
 
 // Get fetches the requested document. Any errors are deferred until the
 // row.ScanDoc call.
+//
+// When the "attachments" option is set to true, attachment content is
+// included in the response, and made available, decoded, through
+// row.Attachments. If the driver does not stream attachments separately, the
+// response body is buffered in memory to read the inline attachments.
 func (db *DB) Get(ctx context.Context, docID string, options ...Options) *Row {
 	opts, err := mergeOptions(options...)
 	if err != nil {
 		return &Row{Err: err}
 	}
-	doc, err := db.driverDB.Get(ctx, docID, opts)
+	var doc *driver.Document
+	err = db.do(ctx, &Operation{Name: "Get", DocID: docID, Options: opts}, false, func(ctx context.Context) error {
+		var e error
+		doc, e = db.driverDB.Get(ctx, docID, opts)
+		return e
+	})
 	if err != nil {
 		return &Row{Err: err}
 	}
@@ -115,48 +177,136 @@ func (db *DB) Get(ctx context.Context, docID string, options ...Options) *Row {
 		ContentLength: doc.ContentLength,
 		Rev:           doc.Rev,
 		Body:          doc.Body,
+		codec:         db.client.codec(),
 	}
 	if doc.Attachments != nil {
 		row.Attachments = &AttachmentsIterator{atti: doc.Attachments}
+		return row
+	}
+	if boolOption(opts, "attachments") {
+		atti, body, err := readInlineAttachments(doc.Body)
+		if err != nil {
+			return &Row{Err: err}
+		}
+		row.Body = body
+		row.Attachments = &AttachmentsIterator{atti: atti}
 	}
 	return row
 }
 
-// GetMeta returns the size and rev of the specified document. GetMeta accepts
-// the same options as the Get method.
-func (db *DB) GetMeta(ctx context.Context, docID string, options ...Options) (size int64, rev string, err error) {
+// DocumentMeta is the metadata of a document, as returned by GetMeta.
+type DocumentMeta struct {
+	// Size is the size of the document, in bytes.
+	Size int64
+	// Rev is the revision of the document.
+	Rev string
+	// Deleted is true if the revision is a deletion, which may be the case
+	// when a specific revision is requested with the rev option.
+	Deleted bool
+}
+
+// GetMeta returns the size, rev and deleted status of the specified document.
+// GetMeta accepts the same options as the Get method. If the driver supports
+// it, this is done with a HEAD request, so that the document body need not be
+// transferred. Otherwise, the document is fetched with Get, and the body
+// discarded.
+//
+// A non-existent or deleted document results in an error with status
+// StatusNotFound, so GetMeta may also be used to check for the existence of a
+// document. A deleted revision may be fetched with the rev option, in which
+// case Deleted is true.
+func (db *DB) GetMeta(ctx context.Context, docID string, options ...Options) (*DocumentMeta, error) {
+	if docID == "" {
+		return nil, missingArg("docID")
+	}
 	opts, err := mergeOptions(options...)
 	if err != nil {
-		return 0, "", err
+		return nil, err
+	}
+	op := &Operation{Name: "GetMeta", DocID: docID, Options: opts}
+	if r, ok := db.driverDB.(driver.DocumentMetaGetter); ok {
+		var meta *driver.DocumentMeta
+		err = db.do(ctx, op, false, func(ctx context.Context) error {
+			var e error
+			meta, e = r.GetDocumentMeta(ctx, docID, opts)
+			return e
+		})
+		if err != nil {
+			return nil, err
+		}
+		return (*DocumentMeta)(meta), nil
 	}
 	if r, ok := db.driverDB.(driver.MetaGetter); ok {
-		return r.GetMeta(ctx, docID, opts)
+		meta := &DocumentMeta{}
+		err = db.do(ctx, op, false, func(ctx context.Context) error {
+			var e error
+			meta.Size, meta.Rev, e = r.GetMeta(ctx, docID, opts)
+			return e
+		})
+		if err != nil {
+			return nil, err
+		}
+		return meta, nil
 	}
-	row := db.Get(ctx, docID, nil)
+	row := db.Get(ctx, docID, opts)
 	if row.Err != nil {
-		return 0, "", row.Err
-	}
-	if row.Rev != "" {
-		_ = row.Body.Close()
-		return row.ContentLength, row.Rev, nil
+		return nil, row.Err
 	}
 	var doc struct {
-		Rev string `json:"_rev"`
+		Rev     string `json:"_rev"`
+		Deleted bool   `json:"_deleted"`
+	}
+	if err := row.ScanDoc(&doc); err != nil {
+		return nil, err
 	}
-	// These last two lines cannot be combined for GopherJS due to a bug.
-	// See https://github.com/gopherjs/gopherjs/issues/608
-	err = row.ScanDoc(&doc)
-	return row.ContentLength, doc.Rev, err
+	meta := &DocumentMeta{Size: row.ContentLength, Rev: row.Rev, Deleted: doc.Deleted}
+	if meta.Rev == "" {
+		meta.Rev = doc.Rev
+	}
+	return meta, nil
+}
+
+// Rev returns the current revision of the specified document. It is a
+// convenience wrapper around GetMeta, so uses a HEAD request where the driver
+// supports it, and otherwise fetches and discards the document.
+func (db *DB) Rev(ctx context.Context, docID string, options ...Options) (rev string, err error) {
+	meta, err := db.GetMeta(ctx, docID, options...)
+	if err != nil {
+		return "", err
+	}
+	return meta.Rev, nil
 }
 
 // CreateDoc creates a new doc with an auto-generated unique ID. The generated
-// docID and new rev are returned.
+// docID and new rev are returned. The ID is generated by the client's
+// IDGenerator, if set, or else by the driver.
 func (db *DB) CreateDoc(ctx context.Context, doc interface{}, options ...Options) (docID, rev string, err error) {
 	opts, err := mergeOptions(options...)
 	if err != nil {
 		return "", "", err
 	}
-	return db.driverDB.CreateDoc(ctx, doc, opts)
+	encoded, err := encodeDoc(db.client.codec(), doc)
+	if err != nil {
+		return "", "", errors.WrapStatus(StatusBadRequest, err)
+	}
+	if gen := db.client.idGen(); gen != nil {
+		if docID, err = gen(); err != nil {
+			return "", "", errors.Wrap(err, "kivik: generate document ID")
+		}
+	}
+	err = db.do(ctx, &Operation{Name: "CreateDoc", DocID: docID, Options: opts}, true, func(ctx context.Context) error {
+		var e error
+		if docID != "" {
+			rev, e = db.driverDB.Put(ctx, docID, encoded, opts)
+			return e
+		}
+		docID, rev, e = db.driverDB.CreateDoc(ctx, encoded, opts)
+		return e
+	})
+	if err == nil {
+		updateDocument(doc, docID, rev)
+	}
+	return docID, rev, err
 }
 
 // normalizeFromJSON unmarshals a []byte, json.RawMessage or io.Reader to a
@@ -237,11 +387,22 @@ func (db *DB) Put(ctx context.Context, docID string, doc interface{}, options ..
 	if err != nil {
 		return "", err
 	}
+	if i, err = encodeDoc(db.client.codec(), i); err != nil {
+		return "", errors.WrapStatus(StatusBadRequest, err)
+	}
 	opts, err := mergeOptions(options...)
 	if err != nil {
 		return "", err
 	}
-	return db.driverDB.Put(ctx, docID, i, opts)
+	err = db.do(ctx, &Operation{Name: "Put", DocID: docID, Options: opts}, true, func(ctx context.Context) error {
+		var e error
+		rev, e = db.driverDB.Put(ctx, docID, i, opts)
+		return e
+	})
+	if err == nil {
+		updateDocument(doc, docID, rev)
+	}
+	return rev, err
 }
 
 // Delete marks the specified document as deleted.
@@ -253,7 +414,12 @@ func (db *DB) Delete(ctx context.Context, docID, rev string, options ...Options)
 	if err != nil {
 		return "", err
 	}
-	return db.driverDB.Delete(ctx, docID, rev, opts)
+	err = db.do(ctx, &Operation{Name: "Delete", DocID: docID, Options: opts}, true, func(ctx context.Context) error {
+		var e error
+		newRev, e = db.driverDB.Delete(ctx, docID, rev, opts)
+		return e
+	})
+	return newRev, err
 }
 
 // Flush requests a flush of disk cache to disk or other permanent storage.
@@ -261,11 +427,53 @@ func (db *DB) Delete(ctx context.Context, docID, rev string, options ...Options)
 // See http://docs.couchdb.org/en/2.0.0/api/database/compact.html#db-ensure-full-commit
 func (db *DB) Flush(ctx context.Context) error {
 	if flusher, ok := db.driverDB.(driver.Flusher); ok {
-		return flusher.Flush(ctx)
+		return db.do(ctx, &Operation{Name: "Flush"}, true, func(ctx context.Context) error {
+			return flusher.Flush(ctx)
+		})
 	}
 	return errors.Status(StatusNotImplemented, "kivik: flush not supported by driver")
 }
 
+// PurgeResult is the result of a purge request.
+type PurgeResult struct {
+	// Seq is the purge sequence number.
+	Seq int64 `json:"purge_seq"`
+	// Purged is a map of document ids to revisions, indicated the
+	// document/revision pairs that were successfully purged.
+	Purged map[string][]string `json:"purged"`
+}
+
+// Purge permanently removes the reference to deleted documents from the
+// database. Normal deletion only marks the document with the key/value pair
+// `_deleted=true`, to ensure proper replication of deleted documents. By
+// using Purge, the document can be completely removed. But note that this
+// operation is not replication safe, so great care must be taken when using
+// Purge, and this should only be used as a last resort.
+//
+// Purge expects as input a map with document ID as key, and slice of
+// revisions as value.
+//
+// See http://docs.couchdb.org/en/2.0.0/api/database/misc.html#db-purge
+func (db *DB) Purge(ctx context.Context, docRevMap map[string][]string) (*PurgeResult, error) {
+	if len(docRevMap) == 0 {
+		return nil, missingArg("docRevMap")
+	}
+	if purger, ok := db.driverDB.(driver.Purger); ok {
+		var res *driver.PurgeResult
+		err := db.do(ctx, &Operation{Name: "Purge"}, true, func(ctx context.Context) error {
+			var e error
+			res, e = purger.Purge(ctx, docRevMap)
+			return e
+		})
+		if err != nil {
+			return nil, err
+		}
+		r := PurgeResult(*res)
+		return &r, nil
+	}
+	return nil, errors.Status(StatusNotImplemented, "kivik: purge not supported by driver")
+}
+
 // DBStats contains database statistics..
 type DBStats struct {
 	// Name is the name of the database.
@@ -278,7 +486,9 @@ type DBStats struct {
 	// database.
 	DeletedCount int64 `json:"doc_del_count"`
 	// UpdateSeq is the current update sequence for the database.
-	UpdateSeq string `json:"update_seq"`
+	UpdateSeq SequenceID `json:"update_seq"`
+	// PurgeSeq is the current purge sequence for the database.
+	PurgeSeq SequenceID `json:"purge_seq"`
 	// DiskSize is the number of bytes used on-disk to store the database.
 	DiskSize int64 `json:"disk_size"`
 	// ActiveSize is the number of bytes used on-disk to store active documents.
@@ -288,43 +498,97 @@ type DBStats struct {
 	// ExternalSize is the size of the documents in the database, as represented
 	// as JSON, before compression.
 	ExternalSize int64 `json:"-"`
+	// Cluster is the sharding and quorum configuration of the database, if
+	// reported by the server, as by CouchDB 2.0 and later.
+	Cluster *ClusterConfig `json:"cluster,omitempty"`
+	// Partitioned is true if the database is partitioned.
+	Partitioned bool `json:"-"`
+}
+
+// ClusterConfig contains the sharding and quorum configuration of a
+// clustered database.
+type ClusterConfig struct {
+	// Replicas is the number of copies of each document (n).
+	Replicas int `json:"n"`
+	// Shards is the number of shards (q).
+	Shards int `json:"q"`
+	// ReadQuorum is the number of copies which must agree on a read (r).
+	ReadQuorum int `json:"r"`
+	// WriteQuorum is the number of copies which must acknowledge a write (w).
+	WriteQuorum int `json:"w"`
+}
+
+func newDBStats(i *driver.DBStats) *DBStats {
+	var cluster *ClusterConfig
+	if i.Cluster != nil {
+		c := ClusterConfig(*i.Cluster)
+		cluster = &c
+	}
+	return &DBStats{
+		Name:           i.Name,
+		CompactRunning: i.CompactRunning,
+		DocCount:       i.DocCount,
+		DeletedCount:   i.DeletedCount,
+		UpdateSeq:      SequenceID(i.UpdateSeq),
+		PurgeSeq:       SequenceID(i.PurgeSeq),
+		DiskSize:       i.DiskSize,
+		ActiveSize:     i.ActiveSize,
+		ExternalSize:   i.ExternalSize,
+		Cluster:        cluster,
+		Partitioned:    i.Partitioned,
+	}
 }
 
 // Stats returns database statistics.
 func (db *DB) Stats(ctx context.Context) (*DBStats, error) {
-	i, err := db.driverDB.Stats(ctx)
+	var i *driver.DBStats
+	err := db.do(ctx, &Operation{Name: "Stats"}, false, func(ctx context.Context) error {
+		var e error
+		i, e = db.driverDB.Stats(ctx)
+		return e
+	})
 	if err != nil {
 		return nil, err
 	}
-	stats := DBStats(*i)
-	return &stats, nil
+	return newDBStats(i), nil
 }
 
 // Compact begins compaction of the database. Check the CompactRunning field
 // returned by Info() to see if the compaction has completed.
 // See http://docs.couchdb.org/en/2.0.0/api/database/compact.html#db-compact
 func (db *DB) Compact(ctx context.Context) error {
-	return db.driverDB.Compact(ctx)
+	return db.do(ctx, &Operation{Name: "Compact"}, true, func(ctx context.Context) error {
+		return db.driverDB.Compact(ctx)
+	})
 }
 
 // CompactView compats the view indexes associated with the specified design
 // document.
 // See http://docs.couchdb.org/en/2.0.0/api/database/compact.html#db-compact-design-doc
 func (db *DB) CompactView(ctx context.Context, ddocID string) error {
-	return db.driverDB.CompactView(ctx, ddocID)
+	return db.do(ctx, &Operation{Name: "CompactView"}, true, func(ctx context.Context) error {
+		return db.driverDB.CompactView(ctx, ddocID)
+	})
 }
 
 // ViewCleanup removes view index files that are no longer required as a result
 // of changed views within design documents.
 // See http://docs.couchdb.org/en/2.0.0/api/database/compact.html#db-view-cleanup
 func (db *DB) ViewCleanup(ctx context.Context) error {
-	return db.driverDB.ViewCleanup(ctx)
+	return db.do(ctx, &Operation{Name: "ViewCleanup"}, true, func(ctx context.Context) error {
+		return db.driverDB.ViewCleanup(ctx)
+	})
 }
 
 // Security returns the database's security document.
 // See http://couchdb.readthedocs.io/en/latest/api/database/security.html#get--db-_security
 func (db *DB) Security(ctx context.Context) (*Security, error) {
-	s, err := db.driverDB.Security(ctx)
+	var s *driver.Security
+	err := db.do(ctx, &Operation{Name: "Security"}, false, func(ctx context.Context) error {
+		var e error
+		s, e = db.driverDB.Security(ctx)
+		return e
+	})
 	if err != nil {
 		return nil, err
 	}
@@ -344,13 +608,17 @@ func (db *DB) SetSecurity(ctx context.Context, security *Security) error {
 		Admins:  driver.Members(security.Admins),
 		Members: driver.Members(security.Members),
 	}
-	return db.driverDB.SetSecurity(ctx, sec)
+	return db.do(ctx, &Operation{Name: "SetSecurity"}, true, func(ctx context.Context) error {
+		return db.driverDB.SetSecurity(ctx, sec)
+	})
 }
 
 // Copy copies the source document to a new document with an ID of targetID. If
 // the database backend does not support COPY directly, the operation will be
 // emulated with a Get followed by Put. The target will be an exact copy of the
-// source, with only the ID and revision changed.
+// source, with only the ID and revision changed. When emulated, attachments
+// are fetched inline and re-uploaded with the target, as attachment stubs
+// cannot refer to another document.
 //
 // See http://docs.couchdb.org/en/2.0.0/api/document/common.html#copy--db-docid
 func (db *DB) Copy(ctx context.Context, targetID, sourceID string, options ...Options) (targetRev string, err error) {
@@ -365,14 +633,33 @@ func (db *DB) Copy(ctx context.Context, targetID, sourceID string, options ...Op
 		return "", err
 	}
 	if copier, ok := db.driverDB.(driver.Copier); ok {
-		return copier.Copy(ctx, targetID, sourceID, opts)
-	}
+		err = db.do(ctx, &Operation{Name: "Copy", DocID: targetID, Options: opts}, true, func(ctx context.Context) error {
+			var e error
+			targetRev, e = copier.Copy(ctx, targetID, sourceID, opts)
+			return e
+		})
+		return targetRev, err
+	}
+	getOpts := make(Options, len(opts)+1)
+	for k, v := range opts {
+		getOpts[k] = v
+	}
+	getOpts["attachments"] = true
 	var doc map[string]interface{}
-	if err = db.Get(ctx, sourceID, opts).ScanDoc(&doc); err != nil {
+	if err = db.Get(ctx, sourceID, getOpts).ScanDoc(&doc); err != nil {
 		return "", err
 	}
 	delete(doc, "_rev")
 	doc["_id"] = targetID
+	if atts, ok := doc["_attachments"].(map[string]interface{}); ok {
+		for filename, att := range atts {
+			a, _ := att.(map[string]interface{})
+			atts[filename] = map[string]interface{}{
+				"content_type": a["content_type"],
+				"data":         a["data"],
+			}
+		}
+	}
 	delete(opts, "rev") // rev has a completely different meaning for Copy and Put
 	return db.Put(ctx, targetID, doc, opts)
 }
@@ -391,7 +678,62 @@ func (db *DB) PutAttachment(ctx context.Context, docID, rev string, att *Attachm
 		return "", err
 	}
 	a := driver.Attachment(*att)
-	return db.driverDB.PutAttachment(ctx, docID, rev, &a, opts)
+	// The attachment content cannot be replayed, so this is never retried.
+	op := &Operation{Name: "PutAttachment", DB: db.name, DocID: docID, Options: opts}
+	err = callHooks(ctx, db.hooks(), op, func(ctx context.Context) error {
+		var e error
+		newRev, e = db.driverDB.PutAttachment(ctx, docID, rev, &a, opts)
+		return e
+	})
+	return newRev, err
+}
+
+// PutWithAttachments stores doc, along with the provided attachments, as a
+// single revision. doc may be any of the types accepted by Put. Any
+// attachment stubs already present in doc are preserved.
+//
+// If the driver supports it, the document and attachments are streamed in a
+// single multipart/related request. Otherwise, the attachments are inlined in
+// the document as base64-encoded data, which requires that they be read fully
+// into memory, and the result is stored with Put.
+func (db *DB) PutWithAttachments(ctx context.Context, docID string, doc interface{}, atts ...*Attachment) (newRev string, err error) {
+	if docID == "" {
+		return "", missingArg("docID")
+	}
+	for _, att := range atts {
+		if e := att.validate(); e != nil {
+			return "", e
+		}
+	}
+	i, err := normalizeFromJSON(doc)
+	if err != nil {
+		return "", err
+	}
+	if putter, ok := db.driverDB.(driver.MultipartPutter); ok {
+		datts := make([]*driver.Attachment, len(atts))
+		for j, att := range atts {
+			a := driver.Attachment(*att)
+			datts[j] = &a
+		}
+		// The attachment content cannot be replayed, so this is never retried.
+		op := &Operation{Name: "PutWithAttachments", DB: db.name, DocID: docID}
+		err = callHooks(ctx, db.hooks(), op, func(ctx context.Context) error {
+			var e error
+			newRev, e = putter.PutWithAttachments(ctx, docID, i, datts)
+			return e
+		})
+		return newRev, err
+	}
+	i, err = inlineAttachments(i, atts)
+	if err != nil {
+		return "", err
+	}
+	err = db.do(ctx, &Operation{Name: "Put", DocID: docID}, true, func(ctx context.Context) error {
+		var e error
+		newRev, e = db.driverDB.Put(ctx, docID, i, nil)
+		return e
+	})
+	return newRev, err
 }
 
 // GetAttachment returns a file attachment associated with the document.
@@ -406,7 +748,12 @@ func (db *DB) GetAttachment(ctx context.Context, docID, rev, filename string, op
 	if e != nil {
 		return nil, e
 	}
-	att, err := db.driverDB.GetAttachment(ctx, docID, rev, filename, opts)
+	var att *driver.Attachment
+	err := db.do(ctx, &Operation{Name: "GetAttachment", DocID: docID, Options: opts}, false, func(ctx context.Context) error {
+		var e error
+		att, e = db.driverDB.GetAttachment(ctx, docID, rev, filename, opts)
+		return e
+	})
 	if err != nil {
 		return nil, err
 	}
@@ -438,7 +785,12 @@ func (db *DB) GetAttachmentMeta(ctx context.Context, docID, rev, filename string
 		if err != nil {
 			return nil, err
 		}
-		a, err := metaer.GetAttachmentMeta(ctx, docID, rev, filename, opts)
+		var a *driver.Attachment
+		err = db.do(ctx, &Operation{Name: "GetAttachmentMeta", DocID: docID, Options: opts}, false, func(ctx context.Context) error {
+			var e error
+			a, e = metaer.GetAttachmentMeta(ctx, docID, rev, filename, opts)
+			return e
+		})
 		if err != nil {
 			return nil, err
 		}
@@ -471,5 +823,10 @@ func (db *DB) DeleteAttachment(ctx context.Context, docID, rev, filename string,
 	if err != nil {
 		return "", err
 	}
-	return db.driverDB.DeleteAttachment(ctx, docID, rev, filename, opts)
+	err = db.do(ctx, &Operation{Name: "DeleteAttachment", DocID: docID, Options: opts}, true, func(ctx context.Context) error {
+		var e error
+		newRev, e = db.driverDB.DeleteAttachment(ctx, docID, rev, filename, opts)
+		return e
+	})
+	return newRev, err
 }
@@ -35,6 +35,45 @@ func TestName(t *testing.T) {
 	}
 }
 
+func TestDBClose(t *testing.T) {
+	tests := []struct {
+		name   string
+		db     driver.DB
+		status int
+		err    string
+	}{
+		{
+			name: "non-closer",
+			db:   &mock.DB{},
+		},
+		{
+			name: "close error",
+			db: &mock.DBCloser{
+				CloseFunc: func(_ context.Context) error {
+					return errors.Status(StatusInternalServerError, "close error")
+				},
+			},
+			status: StatusInternalServerError,
+			err:    "close error",
+		},
+		{
+			name: "success",
+			db: &mock.DBCloser{
+				CloseFunc: func(_ context.Context) error {
+					return nil
+				},
+			},
+		},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			db := &DB{driverDB: test.db}
+			err := db.Close(context.Background())
+			testy.StatusError(t, test.err, test.status, err)
+		})
+	}
+}
+
 func TestAllDocs(t *testing.T) {
 	tests := []struct {
 		name     string
@@ -92,6 +131,136 @@ func TestAllDocs(t *testing.T) {
 	}
 }
 
+func TestDesignDocs(t *testing.T) {
+	tests := []struct {
+		name     string
+		db       *DB
+		options  Options
+		expected *Rows
+		status   int
+		err      string
+	}{
+		{
+			name: "non-DesignDocer",
+			db: &DB{
+				driverDB: &mock.DB{},
+			},
+			status: StatusNotImplemented,
+			err:    "kivik: design doc view not supported by driver",
+		},
+		{
+			name: "db error",
+			db: &DB{
+				driverDB: &mock.DesignDocer{
+					DesignDocsFunc: func(_ context.Context, _ map[string]interface{}) (driver.Rows, error) {
+						return nil, errors.New("db error")
+					},
+				},
+			},
+			status: StatusInternalServerError,
+			err:    "db error",
+		},
+		{
+			name: "success",
+			db: &DB{
+				driverDB: &mock.DesignDocer{
+					DesignDocsFunc: func(_ context.Context, opts map[string]interface{}) (driver.Rows, error) {
+						if d := diff.Interface(testOptions, opts); d != nil {
+							return nil, fmt.Errorf("Unexpected options: %s", d)
+						}
+						return &mock.Rows{ID: "a"}, nil
+					},
+				},
+			},
+			options: testOptions,
+			expected: &Rows{
+				iter: &iter{
+					feed: &rowsIterator{
+						Rows: &mock.Rows{ID: "a"},
+					},
+					curVal: &driver.Row{},
+				},
+				rowsi: &mock.Rows{ID: "a"},
+			},
+		},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			result, err := test.db.DesignDocs(context.Background(), test.options)
+			testy.StatusError(t, test.err, test.status, err)
+			result.cancel = nil // Determinism
+			if d := diff.Interface(test.expected, result); d != nil {
+				t.Error(d)
+			}
+		})
+	}
+}
+
+func TestLocalDocs(t *testing.T) {
+	tests := []struct {
+		name     string
+		db       *DB
+		options  Options
+		expected *Rows
+		status   int
+		err      string
+	}{
+		{
+			name: "non-LocalDocer",
+			db: &DB{
+				driverDB: &mock.DB{},
+			},
+			status: StatusNotImplemented,
+			err:    "kivik: local doc view not supported by driver",
+		},
+		{
+			name: "db error",
+			db: &DB{
+				driverDB: &mock.LocalDocer{
+					LocalDocsFunc: func(_ context.Context, _ map[string]interface{}) (driver.Rows, error) {
+						return nil, errors.New("db error")
+					},
+				},
+			},
+			status: StatusInternalServerError,
+			err:    "db error",
+		},
+		{
+			name: "success",
+			db: &DB{
+				driverDB: &mock.LocalDocer{
+					LocalDocsFunc: func(_ context.Context, opts map[string]interface{}) (driver.Rows, error) {
+						if d := diff.Interface(testOptions, opts); d != nil {
+							return nil, fmt.Errorf("Unexpected options: %s", d)
+						}
+						return &mock.Rows{ID: "a"}, nil
+					},
+				},
+			},
+			options: testOptions,
+			expected: &Rows{
+				iter: &iter{
+					feed: &rowsIterator{
+						Rows: &mock.Rows{ID: "a"},
+					},
+					curVal: &driver.Row{},
+				},
+				rowsi: &mock.Rows{ID: "a"},
+			},
+		},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			result, err := test.db.LocalDocs(context.Background(), test.options)
+			testy.StatusError(t, test.err, test.status, err)
+			result.cancel = nil // Determinism
+			if d := diff.Interface(test.expected, result); d != nil {
+				t.Error(d)
+			}
+		})
+	}
+}
+
 func TestQuery(t *testing.T) {
 	tests := []struct {
 		name       string
@@ -253,6 +422,82 @@ func TestGet(t *testing.T) {
 	}
 }
 
+func TestGetInlineAttachments(t *testing.T) {
+	db := &DB{
+		driverDB: &mock.DB{
+			GetFunc: func(_ context.Context, _ string, _ map[string]interface{}) (*driver.Document, error) {
+				return &driver.Document{
+					Rev:  "1-xxx",
+					Body: body(`{"_id":"foo","_attachments":{"b.txt":{"content_type":"text/plain","data":"VGVzdCBmaWxl"},"a.txt":{"content_type":"text/plain","stub":true,"length":10}}}`),
+				}, nil
+			},
+		},
+	}
+	t.Run("invalid json", func(t *testing.T) {
+		db := &DB{
+			driverDB: &mock.DB{
+				GetFunc: func(_ context.Context, _ string, _ map[string]interface{}) (*driver.Document, error) {
+					return &driver.Document{Body: body(`{"_attachments":"foo"}`)}, nil
+				},
+			},
+		}
+		row := db.Get(context.Background(), "foo", Options{"attachments": true})
+		if status := StatusCode(row.Err); status != StatusBadResponse {
+			t.Errorf("Unexpected status: %d (%v)", status, row.Err)
+		}
+	})
+	t.Run("success", func(t *testing.T) {
+		row := db.Get(context.Background(), "foo", Options{"attachments": true})
+		if row.Err != nil {
+			t.Fatal(row.Err)
+		}
+		var filenames, contents []string
+		for {
+			att, err := row.Attachments.Next()
+			if err == io.EOF {
+				break
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+			content, err := ioutil.ReadAll(att.Content)
+			if err != nil {
+				t.Fatal(err)
+			}
+			filenames = append(filenames, att.Filename)
+			contents = append(contents, string(content))
+		}
+		if d := diff.Interface([]string{"a.txt", "b.txt"}, filenames); d != nil {
+			t.Error(d)
+		}
+		if d := diff.Interface([]string{"", "Test file"}, contents); d != nil {
+			t.Error(d)
+		}
+		var doc map[string]interface{}
+		if err := row.ScanDoc(&doc); err != nil {
+			t.Fatal(err)
+		}
+		if doc["_id"] != "foo" {
+			t.Errorf("Unexpected doc: %v", doc)
+		}
+	})
+	t.Run("string option", func(t *testing.T) {
+		row := db.Get(context.Background(), "foo", Options{"attachments": "true"})
+		if row.Err != nil {
+			t.Fatal(row.Err)
+		}
+		if row.Attachments == nil {
+			t.Errorf("Expected attachments iterator")
+		}
+	})
+	t.Run("not requested", func(t *testing.T) {
+		row := db.Get(context.Background(), "foo")
+		if row.Attachments != nil {
+			t.Errorf("Unexpected attachments iterator")
+		}
+	})
+}
+
 func TestFlush(t *testing.T) {
 	tests := []struct {
 		name   string
@@ -299,6 +544,71 @@ func TestFlush(t *testing.T) {
 	}
 }
 
+func TestPurge(t *testing.T) {
+	docRevMap := map[string][]string{"foo": {"1-xxx"}}
+	tests := []struct {
+		name      string
+		db        *DB
+		docRevMap map[string][]string
+		expected  *PurgeResult
+		status    int
+		err       string
+	}{
+		{
+			name:   "no docs",
+			db:     &DB{driverDB: &mock.Purger{}},
+			status: StatusBadRequest,
+			err:    "kivik: docRevMap required",
+		},
+		{
+			name: "non-Purger",
+			db: &DB{
+				driverDB: &mock.DB{},
+			},
+			docRevMap: docRevMap,
+			status:    StatusNotImplemented,
+			err:       "kivik: purge not supported by driver",
+		},
+		{
+			name: "db error",
+			db: &DB{
+				driverDB: &mock.Purger{
+					PurgeFunc: func(_ context.Context, _ map[string][]string) (*driver.PurgeResult, error) {
+						return nil, errors.Status(StatusBadResponse, "purge error")
+					},
+				},
+			},
+			docRevMap: docRevMap,
+			status:    StatusBadResponse,
+			err:       "purge error",
+		},
+		{
+			name: "success",
+			db: &DB{
+				driverDB: &mock.Purger{
+					PurgeFunc: func(_ context.Context, dr map[string][]string) (*driver.PurgeResult, error) {
+						if d := diff.Interface(docRevMap, dr); d != nil {
+							return nil, fmt.Errorf("Unexpected docRevMap:\n%s", d)
+						}
+						return &driver.PurgeResult{Seq: 2, Purged: dr}, nil
+					},
+				},
+			},
+			docRevMap: docRevMap,
+			expected:  &PurgeResult{Seq: 2, Purged: docRevMap},
+		},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			result, err := test.db.Purge(context.Background(), test.docRevMap)
+			testy.StatusError(t, test.err, test.status, err)
+			if d := diff.Interface(test.expected, result); d != nil {
+				t.Error(d)
+			}
+		})
+	}
+}
+
 func TestStats(t *testing.T) {
 	tests := []struct {
 		name     string
@@ -342,6 +652,79 @@ func TestStats(t *testing.T) {
 	}
 }
 
+func TestDBsStats(t *testing.T) {
+	emulated := &mock.Client{
+		DBFunc: func(_ context.Context, dbname string, _ map[string]interface{}) (driver.DB, error) {
+			return &mock.DB{
+				StatsFunc: func(_ context.Context) (*driver.DBStats, error) {
+					switch dbname {
+					case "missing":
+						return nil, errors.Status(StatusNotFound, "not found")
+					case "broken":
+						return nil, errors.Status(StatusInternalServerError, "broken")
+					}
+					return &driver.DBStats{Name: dbname, UpdateSeq: "1"}, nil
+				},
+			}, nil
+		},
+	}
+	tests := []struct {
+		name     string
+		client   driver.Client
+		dbnames  []string
+		expected []*DBStats
+		status   int
+		err      string
+	}{
+		{
+			name: "native",
+			client: &mock.DBsStatser{
+				DBsStatsFunc: func(_ context.Context, dbnames []string) ([]*driver.DBStats, error) {
+					if d := diff.Interface([]string{"a", "missing"}, dbnames); d != nil {
+						return nil, fmt.Errorf("Unexpected names:\n%s", d)
+					}
+					return []*driver.DBStats{{Name: "a", DocCount: 2}, nil}, nil
+				},
+			},
+			dbnames:  []string{"a", "missing"},
+			expected: []*DBStats{{Name: "a", DocCount: 2}, nil},
+		},
+		{
+			name: "native error",
+			client: &mock.DBsStatser{
+				DBsStatsFunc: func(_ context.Context, _ []string) ([]*driver.DBStats, error) {
+					return nil, errors.Status(StatusBadRequest, "bad request")
+				},
+			},
+			status: StatusBadRequest,
+			err:    "bad request",
+		},
+		{
+			name:     "emulated",
+			client:   emulated,
+			dbnames:  []string{"a", "missing", "b"},
+			expected: []*DBStats{{Name: "a", UpdateSeq: "1"}, nil, {Name: "b", UpdateSeq: "1"}},
+		},
+		{
+			name:    "emulated error",
+			client:  emulated,
+			dbnames: []string{"a", "broken"},
+			status:  StatusInternalServerError,
+			err:     "broken",
+		},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			c := &Client{driverClient: test.client}
+			result, err := c.DBsStats(context.Background(), test.dbnames)
+			testy.StatusError(t, test.err, test.status, err)
+			if d := diff.Interface(test.expected, result); d != nil {
+				t.Error(d)
+			}
+		})
+	}
+}
+
 func TestCompact(t *testing.T) {
 	expected := "compact error"
 	db := &DB{
@@ -519,12 +902,17 @@ func TestGetMeta(t *testing.T) { // nolint: gocyclo
 		name    string
 		db      *DB
 		docID   string
-		size    int64
-		rev     string
+		meta    *DocumentMeta
 		options Options
 		status  int
 		err     string
 	}{
+		{
+			name:   "missing doc ID",
+			db:     &DB{driverDB: &mock.MetaGetter{}},
+			status: StatusBadRequest,
+			err:    "kivik: docID required",
+		},
 		{
 			name: "meta getter error",
 			db: &DB{
@@ -534,6 +922,7 @@ func TestGetMeta(t *testing.T) { // nolint: gocyclo
 					},
 				},
 			},
+			docID:  "foo",
 			status: StatusBadResponse,
 			err:    "get meta error",
 		},
@@ -555,8 +944,7 @@ func TestGetMeta(t *testing.T) { // nolint: gocyclo
 			},
 			docID:   "foo",
 			options: testOptions,
-			size:    123,
-			rev:     "1-xxx",
+			meta:    &DocumentMeta{Size: 123, Rev: "1-xxx"},
 		},
 		{
 			name: "non-meta getter error",
@@ -567,9 +955,30 @@ func TestGetMeta(t *testing.T) { // nolint: gocyclo
 					},
 				},
 			},
+			docID:  "foo",
 			status: StatusBadResponse,
 			err:    "get error",
 		},
+		{
+			name: "non-meta getter with options",
+			db: &DB{
+				driverDB: &mock.DB{
+					GetFunc: func(_ context.Context, _ string, opts map[string]interface{}) (*driver.Document, error) {
+						if d := diff.Interface(testOptions, opts); d != nil {
+							return nil, fmt.Errorf("Unexpected options:\n%s", d)
+						}
+						return &driver.Document{
+							ContentLength: 16,
+							Rev:           "2-xxx",
+							Body:          body(`{"_rev":"2-xxx"}`),
+						}, nil
+					},
+				},
+			},
+			docID:   "foo",
+			options: testOptions,
+			meta:    &DocumentMeta{Size: 16, Rev: "2-xxx"},
+		},
 		{
 			name: "non-meta getter success with rev",
 			db: &DB{
@@ -591,8 +1000,7 @@ func TestGetMeta(t *testing.T) { // nolint: gocyclo
 				},
 			},
 			docID: "foo",
-			size:  16,
-			rev:   "1-xxx",
+			meta:  &DocumentMeta{Size: 16, Rev: "1-xxx"},
 		},
 		{
 			name: "non-meta getter success without rev",
@@ -614,8 +1022,7 @@ func TestGetMeta(t *testing.T) { // nolint: gocyclo
 				},
 			},
 			docID: "foo",
-			size:  16,
-			rev:   "1-xxx",
+			meta:  &DocumentMeta{Size: 16, Rev: "1-xxx"},
 		},
 		{
 			name: "non-meta getter success without rev, invalid json",
@@ -640,16 +1047,113 @@ func TestGetMeta(t *testing.T) { // nolint: gocyclo
 			status: StatusBadResponse,
 			err:    "invalid character 'i' looking for beginning of value",
 		},
+		{
+			name: "document meta getter error",
+			db: &DB{
+				driverDB: &mock.DocumentMetaGetter{
+					GetDocumentMetaFunc: func(_ context.Context, _ string, _ map[string]interface{}) (*driver.DocumentMeta, error) {
+						return nil, errors.Status(StatusBadResponse, "get document meta error")
+					},
+				},
+			},
+			docID:  "foo",
+			status: StatusBadResponse,
+			err:    "get document meta error",
+		},
+		{
+			name: "document meta getter deleted",
+			db: &DB{
+				driverDB: &mock.DocumentMetaGetter{
+					GetDocumentMetaFunc: func(_ context.Context, _ string, _ map[string]interface{}) (*driver.DocumentMeta, error) {
+						return &driver.DocumentMeta{Size: 40, Rev: "2-xxx", Deleted: true}, nil
+					},
+				},
+			},
+			docID:   "foo",
+			options: Options{"rev": "2-xxx"},
+			meta:    &DocumentMeta{Size: 40, Rev: "2-xxx", Deleted: true},
+		},
+		{
+			name: "non-meta getter deleted",
+			db: &DB{
+				driverDB: &mock.DB{
+					GetFunc: func(_ context.Context, _ string, _ map[string]interface{}) (*driver.Document, error) {
+						return &driver.Document{
+							ContentLength: 40,
+							Rev:           "2-xxx",
+							Body:          body(`{"_rev":"2-xxx","_deleted":true}`),
+						}, nil
+					},
+				},
+			},
+			docID:   "foo",
+			options: Options{"rev": "2-xxx"},
+			meta:    &DocumentMeta{Size: 40, Rev: "2-xxx", Deleted: true},
+		},
 	}
 	for _, test := range tests {
 		t.Run(test.name, func(t *testing.T) {
-			size, rev, err := test.db.GetMeta(context.Background(), test.docID, test.options)
+			meta, err := test.db.GetMeta(context.Background(), test.docID, test.options)
 			testy.StatusError(t, test.err, test.status, err)
-			if size != test.size {
-				t.Errorf("Unexpected size: %v", size)
+			if d := diff.Interface(test.meta, meta); d != nil {
+				t.Error(d)
 			}
+		})
+	}
+}
+
+func TestRev(t *testing.T) {
+	tests := []struct {
+		name   string
+		db     *DB
+		rev    string
+		status int
+		err    string
+	}{
+		{
+			name: "meta getter",
+			db: &DB{
+				driverDB: &mock.MetaGetter{
+					GetMetaFunc: func(_ context.Context, docID string, _ map[string]interface{}) (int64, string, error) {
+						if docID != "foo" {
+							return 0, "", fmt.Errorf("Unexpected docID: %s", docID)
+						}
+						return 123, "1-xxx", nil
+					},
+				},
+			},
+			rev: "1-xxx",
+		},
+		{
+			name: "emulated",
+			db: &DB{
+				driverDB: &mock.DB{
+					GetFunc: func(_ context.Context, _ string, _ map[string]interface{}) (*driver.Document, error) {
+						return &driver.Document{Body: body(`{"_rev":"2-xxx","foo":"bar"}`)}, nil
+					},
+				},
+			},
+			rev: "2-xxx",
+		},
+		{
+			name: "not found",
+			db: &DB{
+				driverDB: &mock.DB{
+					GetFunc: func(_ context.Context, _ string, _ map[string]interface{}) (*driver.Document, error) {
+						return nil, errors.Status(StatusNotFound, "missing")
+					},
+				},
+			},
+			status: StatusNotFound,
+			err:    "missing",
+		},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			rev, err := test.db.Rev(context.Background(), "foo")
+			testy.StatusError(t, test.err, test.status, err)
 			if rev != test.rev {
-				t.Errorf("Unexpected rev: %v", rev)
+				t.Errorf("Unexpected rev: %s", rev)
 			}
 		})
 	}
@@ -802,6 +1306,36 @@ func TestCopy(t *testing.T) {
 			options:  Options{"rev": "1-xxx", "batch": true},
 			expected: "1-xxx",
 		},
+		{
+			name: "attachments",
+			db: &DB{
+				driverDB: &mock.DB{
+					GetFunc: func(_ context.Context, _ string, options map[string]interface{}) (*driver.Document, error) {
+						if d := diff.Interface(map[string]interface{}{"attachments": true}, options); d != nil {
+							return nil, fmt.Errorf("Unexpected get options:\n%s", d)
+						}
+						return &driver.Document{
+							Body: body(`{"_id":"bar","_rev":"2-xxx","_attachments":{"foo.txt":{"content_type":"text/plain","revpos":1,"digest":"md5-xxx","data":"VGVzdCBmaWxl"}}}`),
+						}, nil
+					},
+					PutFunc: func(_ context.Context, _ string, doc interface{}, _ map[string]interface{}) (string, error) {
+						expectedDoc := map[string]interface{}{
+							"_id": "foo",
+							"_attachments": map[string]interface{}{
+								"foo.txt": map[string]interface{}{"content_type": "text/plain", "data": "VGVzdCBmaWxl"},
+							},
+						}
+						if d := diff.Interface(expectedDoc, doc); d != nil {
+							return "", fmt.Errorf("Unexpected doc:\n%s", d)
+						}
+						return "1-xxx", nil
+					},
+				},
+			},
+			target:   "foo",
+			source:   "bar",
+			expected: "1-xxx",
+		},
 	}
 	for _, test := range tests {
 		t.Run(test.name, func(t *testing.T) {
@@ -1325,6 +1859,111 @@ func TestPutAttachment(t *testing.T) {
 	}
 }
 
+func TestPutWithAttachments(t *testing.T) {
+	newAtt := func() *Attachment {
+		return &Attachment{
+			Filename:    "foo.txt",
+			ContentType: "text/plain",
+			Content:     ioutil.NopCloser(strings.NewReader("Test file")),
+		}
+	}
+	tests := []struct {
+		name   string
+		db     *DB
+		docID  string
+		doc    interface{}
+		atts   []*Attachment
+		newRev string
+		status int
+		err    string
+	}{
+		{
+			name:   "no doc id",
+			status: StatusBadRequest,
+			err:    "kivik: docID required",
+		},
+		{
+			name:   "no filename",
+			docID:  "foo",
+			atts:   []*Attachment{{}},
+			status: StatusBadRequest,
+			err:    "kivik: filename required",
+		},
+		{
+			name: "multipart putter",
+			db: &DB{
+				driverDB: &mock.MultipartPutter{
+					PutWithAttachmentsFunc: func(_ context.Context, docID string, doc interface{}, atts []*driver.Attachment) (string, error) {
+						if docID != "foo" {
+							return "", fmt.Errorf("Unexpected docID: %s", docID)
+						}
+						if d := diff.Interface(map[string]interface{}{"_id": "foo"}, doc); d != nil {
+							return "", fmt.Errorf("Unexpected doc:\n%s", d)
+						}
+						if len(atts) != 1 || atts[0].Filename != "foo.txt" {
+							return "", fmt.Errorf("Unexpected attachments: %v", atts)
+						}
+						return "1-xxx", nil
+					},
+				},
+			},
+			docID:  "foo",
+			doc:    json.RawMessage(`{"_id":"foo"}`),
+			atts:   []*Attachment{newAtt()},
+			newRev: "1-xxx",
+		},
+		{
+			name:   "non-object doc",
+			db:     &DB{driverDB: &mock.DB{}},
+			docID:  "foo",
+			doc:    []string{"foo"},
+			status: StatusBadRequest,
+			err:    "json: cannot unmarshal array into Go value of type map[string]interface {}",
+		},
+		{
+			name: "emulated",
+			db: &DB{
+				driverDB: &mock.DB{
+					PutFunc: func(_ context.Context, docID string, doc interface{}, _ map[string]interface{}) (string, error) {
+						if docID != "foo" {
+							return "", fmt.Errorf("Unexpected docID: %s", docID)
+						}
+						expected := map[string]interface{}{
+							"_id": "foo",
+							"_attachments": map[string]interface{}{
+								"bar.txt": map[string]interface{}{"content_type": "text/plain", "stub": true},
+								"foo.txt": map[string]interface{}{"content_type": "text/plain", "data": "VGVzdCBmaWxl"},
+							},
+						}
+						if d := diff.AsJSON(expected, doc); d != nil {
+							return "", fmt.Errorf("Unexpected doc:\n%s", d)
+						}
+						return "2-xxx", nil
+					},
+				},
+			},
+			docID: "foo",
+			doc: map[string]interface{}{
+				"_id": "foo",
+				"_attachments": map[string]interface{}{
+					"bar.txt": map[string]interface{}{"content_type": "text/plain", "stub": true},
+				},
+			},
+			atts:   []*Attachment{newAtt()},
+			newRev: "2-xxx",
+		},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			newRev, err := test.db.PutWithAttachments(context.Background(), test.docID, test.doc, test.atts...)
+			testy.StatusError(t, test.err, test.status, err)
+			if newRev != test.newRev {
+				t.Errorf("Unexpected newRev: %s", newRev)
+			}
+		})
+	}
+}
+
 func TestDeleteAttachment(t *testing.T) {
 	tests := []struct {
 		name                 string
@@ -0,0 +1,221 @@
+package kivik
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"io/ioutil"
+	"sync"
+
+	"github.com/go-kivik/kivik/errors"
+)
+
+// DocCache is an opt-in, revision-aware cache of documents read with Get.
+//
+// Documents are cached by ID, along with their revision. By default, each Get
+// first fetches the document's current revision with Rev, which uses a HEAD
+// request where supported, and the cached copy is returned only if the
+// revision matches. Writes made through the cache, and changes observed by
+// Watch, invalidate the affected documents.
+//
+// Only plain reads are cached: a Get with any options bypasses the cache.
+type DocCache struct {
+	// MaxEntries is the maximum number of documents to cache. When the cache
+	// is full, an arbitrary entry is evicted to make room. Zero means no
+	// limit.
+	MaxEntries int
+	// SkipValidation, if true, causes cached documents to be returned without
+	// checking the current revision. This is only appropriate while Watch is
+	// running, and where slightly stale reads are acceptable.
+	SkipValidation bool
+
+	db      *DB
+	mu      sync.RWMutex
+	docs    map[string]*cachedDoc
+	pending map[string]*pendingGet
+}
+
+type cachedDoc struct {
+	rev  string
+	body []byte
+}
+
+// pendingGet tracks the Gets in progress for a document. gen is incremented
+// each time the document is invalidated, so that a Get which read the
+// document before it was invalidated does not store it.
+type pendingGet struct {
+	refs int
+	gen  uint64
+}
+
+// NewDocCache returns a new, empty, document cache for db.
+func NewDocCache(db *DB) *DocCache {
+	return &DocCache{
+		db:      db,
+		docs:    make(map[string]*cachedDoc),
+		pending: make(map[string]*pendingGet),
+	}
+}
+
+func (c *DocCache) lookup(docID string) *cachedDoc {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	return c.docs[docID]
+}
+
+func (c *DocCache) store(docID string, doc *cachedDoc) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.add(docID, doc)
+}
+
+// add adds doc to the cache, evicting another entry if the cache is full.
+// c.mu must be held.
+func (c *DocCache) add(docID string, doc *cachedDoc) {
+	if _, ok := c.docs[docID]; !ok && c.MaxEntries > 0 && len(c.docs) >= c.MaxEntries {
+		for id := range c.docs {
+			delete(c.docs, id)
+			break
+		}
+	}
+	c.docs[docID] = doc
+}
+
+// begin records the start of a Get of docID, and returns the document's
+// current generation, to be passed to finish.
+func (c *DocCache) begin(docID string) uint64 {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	p, ok := c.pending[docID]
+	if !ok {
+		p = &pendingGet{}
+		c.pending[docID] = p
+	}
+	p.refs++
+	return p.gen
+}
+
+// finish records the end of a Get of docID begun in generation gen, and
+// stores doc, if it is not nil, unless docID has since been invalidated.
+func (c *DocCache) finish(docID string, gen uint64, doc *cachedDoc) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	p := c.pending[docID]
+	if doc != nil && p.gen == gen {
+		c.add(docID, doc)
+	}
+	if p.refs--; p.refs == 0 {
+		delete(c.pending, docID)
+	}
+}
+
+// Invalidate removes docID from the cache.
+func (c *DocCache) Invalidate(docID string) {
+	c.mu.Lock()
+	delete(c.docs, docID)
+	if p, ok := c.pending[docID]; ok {
+		p.gen++
+	}
+	c.mu.Unlock()
+}
+
+// Len returns the number of documents currently cached.
+func (c *DocCache) Len() int {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	return len(c.docs)
+}
+
+func (d *cachedDoc) row(codec JSONCodec) *Row {
+	return &Row{
+		ContentLength: int64(len(d.body)),
+		Rev:           d.rev,
+		Body:          ioutil.NopCloser(bytes.NewReader(d.body)),
+		codec:         codec,
+	}
+}
+
+// Get works like DB.Get, but returns a cached copy of the document when it is
+// current. If any options are provided, the cache is bypassed.
+func (c *DocCache) Get(ctx context.Context, docID string, options ...Options) *Row {
+	if len(options) > 0 {
+		return c.db.Get(ctx, docID, options...)
+	}
+	cached := c.lookup(docID)
+	if cached != nil && c.SkipValidation {
+		return cached.row(c.db.client.codec())
+	}
+	var rev string
+	if cached != nil {
+		var err error
+		rev, err = c.db.Rev(ctx, docID)
+		if err != nil {
+			if StatusCode(err) == StatusNotFound {
+				c.Invalidate(docID)
+			}
+			return &Row{Err: err}
+		}
+		if rev == cached.rev {
+			return cached.row(c.db.client.codec())
+		}
+	}
+	gen := c.begin(docID)
+	var fresh *cachedDoc
+	defer func() { c.finish(docID, gen, fresh) }()
+	row := c.db.Get(ctx, docID)
+	if row.Err != nil {
+		if StatusCode(row.Err) == StatusNotFound {
+			c.Invalidate(docID)
+		}
+		return row
+	}
+	defer row.Body.Close() // nolint: errcheck
+	body, err := ioutil.ReadAll(row.Body)
+	if err != nil {
+		return &Row{Err: errors.WrapStatus(StatusNetworkError, err)}
+	}
+	rev = row.Rev
+	if rev == "" {
+		// Take the revision from the body read, rather than fetching it
+		// separately, as the document may have changed in between.
+		var meta struct {
+			Rev string `json:"_rev"`
+		}
+		_ = json.Unmarshal(body, &meta)
+		rev = meta.Rev
+	}
+	doc := &cachedDoc{rev: rev, body: body}
+	if rev != "" {
+		fresh = doc
+	}
+	return doc.row(c.db.client.codec())
+}
+
+// Put works like DB.Put, and invalidates the cached copy of docID.
+func (c *DocCache) Put(ctx context.Context, docID string, doc interface{}, options ...Options) (rev string, err error) {
+	defer c.Invalidate(docID)
+	return c.db.Put(ctx, docID, doc, options...)
+}
+
+// Delete works like DB.Delete, and invalidates the cached copy of docID.
+func (c *DocCache) Delete(ctx context.Context, docID, rev string, options ...Options) (newRev string, err error) {
+	defer c.Invalidate(docID)
+	return c.db.Delete(ctx, docID, rev, options...)
+}
+
+// Watch follows the database's changes feed with WatchChanges, invalidating
+// each changed document, until ctx is cancelled or the feed fails. It blocks,
+// so is typically run in its own goroutine. options are passed to
+// WatchChanges; by default, only changes made after Watch is called are
+// observed.
+func (c *DocCache) Watch(ctx context.Context, config *WatchConfig, options ...Options) error {
+	options = append([]Options{Since(SequenceNow)}, options...)
+	w, err := c.db.WatchChanges(ctx, config, options...)
+	if err != nil {
+		return err
+	}
+	for event := range w.Events() {
+		c.Invalidate(event.ID)
+	}
+	return w.Err()
+}
@@ -0,0 +1,199 @@
+package kivik
+
+import (
+	"context"
+	"fmt"
+	"io/ioutil"
+	"testing"
+	"time"
+
+	"github.com/flimzy/diff"
+	"github.com/flimzy/testy"
+	"github.com/go-kivik/kivik/driver"
+	"github.com/go-kivik/kivik/errors"
+	"github.com/go-kivik/kivik/mock"
+)
+
+// cacheBackend is a single-document backend for DocCache tests.
+type cacheBackend struct {
+	rev   string
+	calls []string
+}
+
+func (b *cacheBackend) db() *DB {
+	return &DB{
+		driverDB: &mock.MetaGetter{
+			DB: &mock.DB{
+				GetFunc: func(_ context.Context, docID string, _ map[string]interface{}) (*driver.Document, error) {
+					b.calls = append(b.calls, "get "+docID)
+					if b.rev == "" {
+						return nil, errors.Status(StatusNotFound, "missing")
+					}
+					return &driver.Document{
+						Rev:  b.rev,
+						Body: body(fmt.Sprintf(`{"_id":%q,"_rev":%q}`, docID, b.rev)),
+					}, nil
+				},
+				PutFunc: func(_ context.Context, docID string, _ interface{}, _ map[string]interface{}) (string, error) {
+					b.calls = append(b.calls, "put "+docID)
+					b.rev = "3-xxx"
+					return b.rev, nil
+				},
+			},
+			GetMetaFunc: func(_ context.Context, docID string, _ map[string]interface{}) (int64, string, error) {
+				b.calls = append(b.calls, "head "+docID)
+				if b.rev == "" {
+					return 0, "", errors.Status(StatusNotFound, "missing")
+				}
+				return 0, b.rev, nil
+			},
+		},
+	}
+}
+
+func readRow(t *testing.T, row *Row) string {
+	if row.Err != nil {
+		return row.Err.Error()
+	}
+	body, err := ioutil.ReadAll(row.Body)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return string(body)
+}
+
+func TestDocCacheGet(t *testing.T) {
+	backend := &cacheBackend{rev: "1-xxx"}
+	cache := NewDocCache(backend.db())
+	ctx := context.Background()
+	var results []string
+	results = append(results, readRow(t, cache.Get(ctx, "foo"))) // miss
+	results = append(results, readRow(t, cache.Get(ctx, "foo"))) // hit
+	backend.rev = "2-xxx"
+	results = append(results, readRow(t, cache.Get(ctx, "foo"))) // stale
+	if _, err := cache.Put(ctx, "foo", map[string]string{}); err != nil {
+		t.Fatal(err)
+	}
+	results = append(results, readRow(t, cache.Get(ctx, "foo"))) // invalidated
+	backend.rev = ""
+	results = append(results, readRow(t, cache.Get(ctx, "foo"))) // deleted
+	results = append(results, readRow(t, cache.Get(ctx, "foo", Options{"revs": true})))
+
+	expectedResults := []string{
+		`{"_id":"foo","_rev":"1-xxx"}`,
+		`{"_id":"foo","_rev":"1-xxx"}`,
+		`{"_id":"foo","_rev":"2-xxx"}`,
+		`{"_id":"foo","_rev":"3-xxx"}`,
+		"missing",
+		"missing",
+	}
+	if d := diff.Interface(expectedResults, results); d != nil {
+		t.Error(d)
+	}
+	expectedCalls := []string{
+		"get foo",
+		"head foo",
+		"head foo", "get foo",
+		"put foo",
+		"get foo",
+		"head foo",
+		"get foo",
+	}
+	if d := diff.Interface(expectedCalls, backend.calls); d != nil {
+		t.Error(d)
+	}
+	if n := cache.Len(); n != 0 {
+		t.Errorf("Expected empty cache, found %d entries", n)
+	}
+}
+
+func TestDocCacheSkipValidation(t *testing.T) {
+	backend := &cacheBackend{rev: "1-xxx"}
+	cache := NewDocCache(backend.db())
+	cache.SkipValidation = true
+	cache.MaxEntries = 1
+	ctx := context.Background()
+	_ = readRow(t, cache.Get(ctx, "foo"))
+	_ = readRow(t, cache.Get(ctx, "foo"))
+	_ = readRow(t, cache.Get(ctx, "bar"))
+	expectedCalls := []string{"get foo", "get bar"}
+	if d := diff.Interface(expectedCalls, backend.calls); d != nil {
+		t.Error(d)
+	}
+	if n := cache.Len(); n != 1 {
+		t.Errorf("Expected 1 entry, found %d", n)
+	}
+}
+
+func TestDocCacheBodyRev(t *testing.T) {
+	var calls []string
+	cache := NewDocCache(&DB{
+		driverDB: &mock.MetaGetter{
+			DB: &mock.DB{
+				GetFunc: func(_ context.Context, docID string, _ map[string]interface{}) (*driver.Document, error) {
+					calls = append(calls, "get "+docID)
+					return &driver.Document{Body: body(`{"_id":"foo","_rev":"1-xxx"}`)}, nil
+				},
+			},
+			GetMetaFunc: func(_ context.Context, docID string, _ map[string]interface{}) (int64, string, error) {
+				calls = append(calls, "head "+docID)
+				return 0, "1-xxx", nil
+			},
+		},
+	})
+	ctx := context.Background()
+	row := cache.Get(ctx, "foo")
+	_ = readRow(t, row)
+	if row.Rev != "1-xxx" {
+		t.Errorf("Unexpected rev: %s", row.Rev)
+	}
+	_ = readRow(t, cache.Get(ctx, "foo"))
+	expectedCalls := []string{"get foo", "head foo"}
+	if d := diff.Interface(expectedCalls, calls); d != nil {
+		t.Error(d)
+	}
+}
+
+func TestDocCacheInvalidatedGet(t *testing.T) {
+	var cache *DocCache
+	cache = NewDocCache(&DB{
+		driverDB: &mock.DB{
+			GetFunc: func(_ context.Context, docID string, _ map[string]interface{}) (*driver.Document, error) {
+				// The document changes after it is read, but before the Get
+				// returns.
+				cache.Invalidate(docID)
+				return &driver.Document{Rev: "1-xxx", Body: body(`{"_id":"foo","_rev":"1-xxx"}`)}, nil
+			},
+		},
+	})
+	_ = readRow(t, cache.Get(context.Background(), "foo"))
+	if cache.lookup("foo") != nil {
+		t.Error("Expected foo not to be cached")
+	}
+	if n := len(cache.pending); n != 0 {
+		t.Errorf("Expected no pending gets, found %d", n)
+	}
+}
+
+func TestDocCacheWatch(t *testing.T) {
+	cache := NewDocCache(&DB{
+		driverDB: &mock.DB{
+			ChangesFunc: func(_ context.Context, opts map[string]interface{}) (driver.Changes, error) {
+				if opts["since"] != "now" {
+					return nil, fmt.Errorf("Unexpected since: %v", opts["since"])
+				}
+				return changesFeed([]driver.Change{{ID: "foo", Seq: "2-x"}}, errors.Status(StatusUnauthorized, "unauthorized")), nil
+			},
+		},
+	})
+	cache.store("foo", &cachedDoc{rev: "1-xxx"})
+	cache.store("bar", &cachedDoc{rev: "1-xxx"})
+	err := cache.Watch(context.Background(), &WatchConfig{MinBackoff: time.Millisecond})
+	testy.StatusError(t, "unauthorized", StatusUnauthorized, err)
+	if cache.lookup("foo") != nil {
+		t.Error("Expected foo to be invalidated")
+	}
+	if cache.lookup("bar") == nil {
+		t.Error("Expected bar to remain cached")
+	}
+}
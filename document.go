@@ -0,0 +1,68 @@
+package kivik
+
+import (
+	"context"
+
+	"github.com/go-kivik/kivik/errors"
+)
+
+var errNotDocument = errors.Status(StatusBadRequest, "kivik: doc must be a pointer to a struct embedding kivik.Document")
+
+// Document may be embedded in a struct, to have Kivik manage the standard
+// CouchDB document fields. For example:
+//
+//	type Widget struct {
+//	    kivik.Document
+//	    Color string `json:"color"`
+//	}
+//
+// When a pointer to such a struct is passed to Put or CreateDoc, the ID and
+// Rev fields are updated after a successful write, so the same value may be
+// modified and written again without conflict. The _rev field is omitted
+// while Rev is empty, as required when creating a new document. When the
+// struct is read with Row.ScanDoc, Rev is populated from the response, if the
+// document body does not include it. DeleteDoc deletes a document using its
+// ID and Rev.
+type Document struct {
+	ID          string      `json:"_id,omitempty"`
+	Rev         string      `json:"_rev,omitempty"`
+	Deleted     bool        `json:"_deleted,omitempty"`
+	Attachments Attachments `json:"_attachments,omitempty"`
+}
+
+// documenter is satisfied by any pointer to a struct which embeds Document.
+type documenter interface {
+	document() *Document
+}
+
+func (d *Document) document() *Document {
+	return d
+}
+
+// updateDocument sets the ID and Rev of doc after a successful write, if doc
+// embeds Document.
+func updateDocument(doc interface{}, docID, rev string) {
+	if d, ok := doc.(documenter); ok {
+		d := d.document()
+		d.ID = docID
+		d.Rev = rev
+	}
+}
+
+// DeleteDoc marks doc, which must be a pointer to a struct embedding
+// Document, as deleted, using its ID and Rev. On success, Rev is updated to
+// the new revision, and Deleted is set.
+func (db *DB) DeleteDoc(ctx context.Context, doc interface{}, options ...Options) (newRev string, err error) {
+	d, ok := doc.(documenter)
+	if !ok {
+		return "", errNotDocument
+	}
+	document := d.document()
+	newRev, err = db.Delete(ctx, document.ID, document.Rev, options...)
+	if err != nil {
+		return "", err
+	}
+	document.Rev = newRev
+	document.Deleted = true
+	return newRev, nil
+}
@@ -0,0 +1,132 @@
+package kivik
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"testing"
+
+	"github.com/flimzy/diff"
+	"github.com/flimzy/testy"
+	"github.com/go-kivik/kivik/mock"
+)
+
+type testDocument struct {
+	Document
+	Color string `json:"color"`
+}
+
+func TestDocumentJSON(t *testing.T) {
+	doc := &testDocument{Color: "red"}
+	doc.Attachments = Attachments{
+		"foo.txt": {Filename: "foo.txt", ContentType: "text/plain", Stub: true},
+	}
+	result, err := json.Marshal(doc)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if d := diff.JSON([]byte(`{"_attachments":{"foo.txt":{"content_type":"text/plain","stub":true}},"color":"red"}`), result); d != nil {
+		t.Error(d)
+	}
+}
+
+func TestDocumentPut(t *testing.T) {
+	db := &DB{
+		driverDB: &mock.DB{
+			PutFunc: func(_ context.Context, _ string, doc interface{}, _ map[string]interface{}) (string, error) {
+				if d := diff.AsJSON(map[string]string{"color": "red"}, doc); d != nil {
+					return "", errors.New(d.String())
+				}
+				return "1-xxx", nil
+			},
+		},
+	}
+	doc := &testDocument{Color: "red"}
+	if _, err := db.Put(context.Background(), "foo", doc); err != nil {
+		t.Fatal(err)
+	}
+	expected := &testDocument{Document: Document{ID: "foo", Rev: "1-xxx"}, Color: "red"}
+	if d := diff.Interface(expected, doc); d != nil {
+		t.Error(d)
+	}
+}
+
+func TestDocumentCreateDoc(t *testing.T) {
+	db := &DB{
+		driverDB: &mock.DB{
+			CreateDocFunc: func(_ context.Context, _ interface{}, _ map[string]interface{}) (string, string, error) {
+				return "foo", "1-xxx", nil
+			},
+		},
+	}
+	doc := &testDocument{}
+	if _, _, err := db.CreateDoc(context.Background(), doc); err != nil {
+		t.Fatal(err)
+	}
+	expected := &testDocument{Document: Document{ID: "foo", Rev: "1-xxx"}}
+	if d := diff.Interface(expected, doc); d != nil {
+		t.Error(d)
+	}
+}
+
+func TestDocumentScanDoc(t *testing.T) {
+	row := &Row{Rev: "2-xxx", Body: body(`{"_id":"foo","color":"blue"}`)}
+	doc := &testDocument{}
+	if err := row.ScanDoc(doc); err != nil {
+		t.Fatal(err)
+	}
+	expected := &testDocument{Document: Document{ID: "foo", Rev: "2-xxx"}, Color: "blue"}
+	if d := diff.Interface(expected, doc); d != nil {
+		t.Error(d)
+	}
+}
+
+func TestDeleteDoc(t *testing.T) {
+	tests := []struct {
+		name     string
+		doc      interface{}
+		expected interface{}
+		status   int
+		err      string
+	}{
+		{
+			name:   "not a document",
+			doc:    map[string]string{"_id": "foo"},
+			status: StatusBadRequest,
+			err:    "kivik: doc must be a pointer to a struct embedding kivik.Document",
+		},
+		{
+			name:   "delete error",
+			doc:    &testDocument{Document: Document{ID: "bar", Rev: "1-xxx"}},
+			status: StatusInternalServerError,
+			err:    "delete failed",
+		},
+		{
+			name:     "success",
+			doc:      &testDocument{Document: Document{ID: "foo", Rev: "1-xxx"}},
+			expected: &testDocument{Document: Document{ID: "foo", Rev: "2-xxx", Deleted: true}},
+		},
+	}
+	db := &DB{
+		driverDB: &mock.DB{
+			DeleteFunc: func(_ context.Context, docID, rev string, _ map[string]interface{}) (string, error) {
+				if docID != "foo" || rev != "1-xxx" {
+					return "", errors.New("delete failed")
+				}
+				return "2-xxx", nil
+			},
+		},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			_, err := db.DeleteDoc(context.Background(), test.doc)
+			testy.StatusError(t, test.err, test.status, err)
+			if test.expected == nil {
+				return
+			}
+			if d := diff.Interface(test.expected, test.doc); d != nil {
+				t.Error(d)
+			}
+		})
+	}
+}
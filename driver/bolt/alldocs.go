@@ -0,0 +1,168 @@
+package bolt
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"strconv"
+
+	bbolt "go.etcd.io/bbolt"
+
+	"github.com/go-kivik/kivik"
+	"github.com/go-kivik/kivik/driver"
+	"github.com/go-kivik/kivik/driver/internal/docquery"
+	"github.com/go-kivik/kivik/errors"
+)
+
+type rowsIter struct {
+	rows      []*driver.Row
+	offset    int64
+	totalRows int64
+	updateSeq string
+	warning   string
+	bookmark  string
+}
+
+var (
+	_ driver.Rows       = &rowsIter{}
+	_ driver.RowsWarner = &rowsIter{}
+	_ driver.Bookmarker = &rowsIter{}
+)
+
+func (r *rowsIter) Next(row *driver.Row) error {
+	if len(r.rows) == 0 {
+		return io.EOF
+	}
+	*row, r.rows = *r.rows[0], r.rows[1:]
+	return nil
+}
+
+func (r *rowsIter) Close() error {
+	r.rows = nil
+	return nil
+}
+
+func (r *rowsIter) UpdateSeq() string { return r.updateSeq }
+func (r *rowsIter) Offset() int64     { return r.offset }
+func (r *rowsIter) TotalRows() int64  { return r.totalRows }
+func (r *rowsIter) Warning() string   { return r.warning }
+func (r *rowsIter) Bookmark() string  { return r.bookmark }
+
+// AllDocs returns the documents in the database, in byte order of their IDs.
+func (d *db) AllDocs(_ context.Context, opts map[string]interface{}) (driver.Rows, error) {
+	q, err := docquery.ParseAllDocs(opts)
+	if err != nil {
+		return nil, err
+	}
+	result := &rowsIter{}
+	err = d.view(func(b *bbolt.Bucket) error {
+		result.totalRows = readCount(b, keyDocCount)
+		if q.UpdateSeq {
+			result.updateSeq = strconv.FormatUint(b.Bucket(bucketSeqs).Sequence(), 10)
+		}
+		var err error
+		if q.HasKeys {
+			result.rows, err = keyRows(b, q)
+		} else {
+			result.rows, result.offset, err = rangeRows(b, q)
+		}
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+	if q.HasKeys {
+		if q.Skip > int64(len(result.rows)) {
+			q.Skip = int64(len(result.rows))
+		}
+		result.rows = result.rows[q.Skip:]
+		if q.Limit > 0 && q.Limit < int64(len(result.rows)) {
+			result.rows = result.rows[:q.Limit]
+		}
+	}
+	return result, nil
+}
+
+// rangeRows iterates the documents between the start and end keys, applying
+// skip and limit as it goes.
+func rangeRows(b *bbolt.Bucket, q *docquery.AllDocs) ([]*driver.Row, int64, error) {
+	c := b.Bucket(bucketDocs).Cursor()
+	first, next := c.First, c.Next
+	if q.Descending {
+		first, next = c.Last, c.Prev
+	}
+	var rows []*driver.Row
+	var offset, skipped int64
+	for k, v := first(); k != nil; k, v = next() {
+		if q.PastEnd(string(k)) || (q.Limit > 0 && int64(len(rows)) >= q.Limit) {
+			break
+		}
+		rec := &docRecord{}
+		if err := json.Unmarshal(v, rec); err != nil {
+			return nil, 0, errors.Wrapf(err, "bolt: corrupt document %s", k)
+		}
+		if rec.winner().Deleted {
+			continue
+		}
+		if q.BeforeStart(string(k)) || skipped < q.Skip {
+			if !q.BeforeStart(string(k)) {
+				skipped++
+			}
+			offset++
+			continue
+		}
+		row, err := docRow(b, string(k), rec, q)
+		if err != nil {
+			return nil, 0, err
+		}
+		rows = append(rows, row)
+	}
+	return rows, offset, nil
+}
+
+func keyRows(b *bbolt.Bucket, q *docquery.AllDocs) ([]*driver.Row, error) {
+	rows := make([]*driver.Row, 0, len(q.Keys))
+	for _, id := range q.Keys {
+		rec, err := readRecord(b, id)
+		if err != nil {
+			return nil, err
+		}
+		if rec == nil {
+			key, _ := json.Marshal(id)
+			rows = append(rows, &driver.Row{
+				Key:   key,
+				Error: errors.Status(kivik.StatusNotFound, "not_found"),
+			})
+			continue
+		}
+		row, err := docRow(b, id, rec, q)
+		if err != nil {
+			return nil, err
+		}
+		rows = append(rows, row)
+	}
+	return rows, nil
+}
+
+func docRow(b *bbolt.Bucket, id string, rec *docRecord, q *docquery.AllDocs) (*driver.Row, error) {
+	key, err := json.Marshal(id)
+	if err != nil {
+		return nil, err
+	}
+	winner := rec.winner()
+	value := map[string]interface{}{"rev": winner.Rev}
+	if winner.Deleted {
+		value["deleted"] = true
+	}
+	row := &driver.Row{ID: id, Key: key}
+	if row.Value, err = json.Marshal(value); err != nil {
+		return nil, err
+	}
+	if q.IncludeDocs && !winner.Deleted {
+		opts := map[string]interface{}{"conflicts": q.Conflicts}
+		if row.Doc, err = renderDoc(b, id, rec, winner, opts); err != nil {
+			return nil, err
+		}
+	}
+	return row, nil
+}
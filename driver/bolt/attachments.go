@@ -0,0 +1,141 @@
+package bolt
+
+import (
+	"bytes"
+	"context"
+	"encoding/base64"
+	"io/ioutil"
+
+	bbolt "go.etcd.io/bbolt"
+
+	"github.com/go-kivik/kivik/driver"
+)
+
+// editableDoc returns the body of leaf revision rev, with its attachments as
+// stubs, for editing. rev may be empty for a new or deleted document.
+func editableDoc(rec *docRecord, rev string) (map[string]interface{}, error) {
+	doc := map[string]interface{}{}
+	if rev != "" {
+		if rec == nil || !rec.isLeaf(rev) {
+			return nil, errConflict
+		}
+		if node := rec.find(rev); !node.Deleted {
+			doc = decodeBody(node.Body)
+		}
+	}
+	doc["_rev"] = rev
+	atts, _ := doc["_attachments"].(map[string]interface{})
+	stubs := make(map[string]interface{}, len(atts))
+	for filename := range atts {
+		stubs[filename] = map[string]interface{}{"stub": true}
+	}
+	doc["_attachments"] = stubs
+	return doc, nil
+}
+
+func (d *db) PutAttachment(_ context.Context, docID, rev string, att *driver.Attachment, _ map[string]interface{}) (string, error) {
+	if err := validateID(docID); err != nil {
+		return "", err
+	}
+	content, err := ioutil.ReadAll(att.Content)
+	if err != nil {
+		return "", err
+	}
+	var newRev string
+	err = d.update(func(b *bbolt.Bucket) error {
+		rec, err := readRecord(b, docID)
+		if err != nil {
+			return err
+		}
+		doc, err := editableDoc(rec, rev)
+		if err != nil {
+			return err
+		}
+		doc["_attachments"].(map[string]interface{})[att.Filename] = map[string]interface{}{
+			"content_type": att.ContentType,
+			"data":         base64.StdEncoding.EncodeToString(content),
+		}
+		newRev, err = newEdit(b, docID, doc)
+		return err
+	})
+	return newRev, err
+}
+
+func (d *db) DeleteAttachment(_ context.Context, docID, rev, filename string, _ map[string]interface{}) (string, error) {
+	var newRev string
+	err := d.update(func(b *bbolt.Bucket) error {
+		rec, err := readRecord(b, docID)
+		if err != nil {
+			return err
+		}
+		if rec == nil {
+			return errMissing
+		}
+		doc, err := editableDoc(rec, rev)
+		if err != nil {
+			return err
+		}
+		atts := doc["_attachments"].(map[string]interface{})
+		if _, ok := atts[filename]; !ok {
+			return errMissing
+		}
+		delete(atts, filename)
+		newRev, err = newEdit(b, docID, doc)
+		return err
+	})
+	return newRev, err
+}
+
+func (d *db) GetAttachment(_ context.Context, docID, rev, filename string, _ map[string]interface{}) (*driver.Attachment, error) {
+	var att *driver.Attachment
+	err := d.view(func(b *bbolt.Bucket) error {
+		var err error
+		if att, err = attachmentMeta(b, docID, rev, filename); err != nil {
+			return err
+		}
+		content := append([]byte(nil), b.Bucket(bucketAtts).Get([]byte(att.Digest))...)
+		att.Content = ioutil.NopCloser(bytes.NewReader(content))
+		return nil
+	})
+	return att, err
+}
+
+func (d *db) GetAttachmentMeta(_ context.Context, docID, rev, filename string, _ map[string]interface{}) (*driver.Attachment, error) {
+	var att *driver.Attachment
+	err := d.view(func(b *bbolt.Bucket) error {
+		var err error
+		att, err = attachmentMeta(b, docID, rev, filename)
+		return err
+	})
+	return att, err
+}
+
+// attachmentMeta returns the stored metadata of an attachment of revision
+// rev, or of the winning revision if rev is empty.
+func attachmentMeta(b *bbolt.Bucket, docID, rev, filename string) (*driver.Attachment, error) {
+	rec, err := readRecord(b, docID)
+	if err != nil {
+		return nil, err
+	}
+	node, err := selectRev(rec, rev)
+	if err != nil {
+		return nil, err
+	}
+	if node.Deleted {
+		return nil, errDeleted
+	}
+	meta, ok := attachmentsOf(node.Body)[filename].(map[string]interface{})
+	if !ok {
+		return nil, errMissing
+	}
+	att := &driver.Attachment{Filename: filename}
+	att.ContentType, _ = meta["content_type"].(string)
+	att.Digest, _ = meta["digest"].(string)
+	if length, ok := meta["length"].(float64); ok {
+		att.Size = int64(length)
+	}
+	if revPos, ok := meta["revpos"].(float64); ok {
+		att.RevPos = int64(revPos)
+	}
+	return att, nil
+}
@@ -0,0 +1,169 @@
+// Package bolt provides a persistent, embedded kivik driver, which stores
+// databases in a single bbolt file. It allows applications to use CouchDB
+// semantics without running a server.
+//
+// The data source name is the path of the database file, which is created if
+// it does not exist:
+//
+//	client, err := kivik.New(ctx, "bolt", "/path/to/data.db")
+//
+// Each database is a bucket within the file. Every update is made in a
+// single transaction. Documents are stored with their full revision trees,
+// so conflicts are kept, and replication with new_edits=false is supported.
+// Only leaf revisions retain their bodies. Each document is indexed by the
+// sequence of its most recent update, from which the changes feed is read,
+// and AllDocs iterates documents in byte order of their IDs.
+//
+// Attachments are stored once per database for each distinct content, and
+// are removed by Compact when no longer referenced. Find evaluates selectors
+// against every document, with the mango package; indexes may be created,
+// but are not used. Views are not supported.
+//
+// The file is locked while the client is open, so it may be used by only one
+// process at a time. Close the client, with Client.Close, to release it.
+package bolt
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	bbolt "go.etcd.io/bbolt"
+
+	"github.com/go-kivik/kivik"
+	"github.com/go-kivik/kivik/driver"
+	"github.com/go-kivik/kivik/errors"
+)
+
+// Version is the version reported by the driver.
+const Version = "0.0.1"
+
+// Vendor is the vendor string reported by the driver.
+const Vendor = "Kivik Bolt"
+
+// OpenTimeout is how long NewClient waits to obtain the lock on the database
+// file, should it be held by another process.
+var OpenTimeout = time.Second
+
+func init() {
+	kivik.Register("bolt", &boltDriver{})
+}
+
+type boltDriver struct{}
+
+var _ driver.Driver = &boltDriver{}
+
+func (d *boltDriver) NewClient(_ context.Context, path string) (driver.Client, error) {
+	bdb, err := bbolt.Open(path, 0666, &bbolt.Options{Timeout: OpenTimeout})
+	if err != nil {
+		return nil, errors.WrapStatus(kivik.StatusBadRequest, err)
+	}
+	return &client{
+		db:      bdb,
+		updated: make(chan struct{}),
+	}, nil
+}
+
+type client struct {
+	db *bbolt.DB
+
+	mu sync.Mutex
+	// updated is closed, and replaced, after each committed update, to wake
+	// any waiting changes feeds.
+	updated chan struct{}
+}
+
+var (
+	_ driver.Client       = &client{}
+	_ driver.ClientCloser = &client{}
+)
+
+// update runs fn in a read-write transaction, and notifies any waiting
+// changes feeds once it is committed.
+func (c *client) update(fn func(*bbolt.Tx) error) error {
+	if err := c.db.Update(fn); err != nil {
+		return err
+	}
+	c.mu.Lock()
+	close(c.updated)
+	c.updated = make(chan struct{})
+	c.mu.Unlock()
+	return nil
+}
+
+// wait returns a channel which is closed after the next committed update.
+func (c *client) wait() <-chan struct{} {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.updated
+}
+
+func (c *client) Version(_ context.Context) (*driver.Version, error) {
+	return &driver.Version{
+		Version: Version,
+		Vendor:  Vendor,
+	}, nil
+}
+
+func (c *client) AllDBs(_ context.Context, _ map[string]interface{}) ([]string, error) {
+	var dbs []string
+	err := c.db.View(func(tx *bbolt.Tx) error {
+		return tx.ForEach(func(name []byte, _ *bbolt.Bucket) error {
+			dbs = append(dbs, string(name))
+			return nil
+		})
+	})
+	return dbs, err
+}
+
+func (c *client) DBExists(_ context.Context, dbName string, _ map[string]interface{}) (bool, error) {
+	var exists bool
+	err := c.db.View(func(tx *bbolt.Tx) error {
+		exists = tx.Bucket([]byte(dbName)) != nil
+		return nil
+	})
+	return exists, err
+}
+
+func (c *client) CreateDB(_ context.Context, dbName string, _ map[string]interface{}) error {
+	if dbName == "" {
+		return errors.Status(kivik.StatusBadRequest, "bolt: database name must not be empty")
+	}
+	return c.update(func(tx *bbolt.Tx) error {
+		b, err := tx.CreateBucket([]byte(dbName))
+		if err == bbolt.ErrBucketExists {
+			return errors.Status(kivik.StatusPreconditionFailed, "The database could not be created, the file already exists.")
+		}
+		if err != nil {
+			return err
+		}
+		for _, name := range buckets {
+			if _, err := b.CreateBucket(name); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+}
+
+func (c *client) DestroyDB(_ context.Context, dbName string, _ map[string]interface{}) error {
+	return c.update(func(tx *bbolt.Tx) error {
+		err := tx.DeleteBucket([]byte(dbName))
+		if err == bbolt.ErrBucketNotFound {
+			return errDBNotFound
+		}
+		return err
+	})
+}
+
+func (c *client) DB(_ context.Context, dbName string, _ map[string]interface{}) (driver.DB, error) {
+	return &db{
+		client: c,
+		name:   dbName,
+	}, nil
+}
+
+// Close closes the database file.
+func (c *client) Close(_ context.Context) error {
+	return c.db.Close()
+}
@@ -0,0 +1,474 @@
+package bolt
+
+import (
+	"context"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/flimzy/diff"
+	"github.com/go-kivik/kivik"
+)
+
+// newTestDB returns a client and database in a new temporary file, and a
+// function to close the client and remove the file.
+func newTestDB(t *testing.T) (*kivik.Client, *kivik.DB, func()) {
+	dir, err := ioutil.TempDir("", "kivik-bolt-")
+	if err != nil {
+		t.Fatal(err)
+	}
+	client, err := kivik.New(context.Background(), "bolt", filepath.Join(dir, "test.db"))
+	if err != nil {
+		_ = os.RemoveAll(dir)
+		t.Fatal(err)
+	}
+	cleanup := func() {
+		_ = client.Close(context.Background())
+		_ = os.RemoveAll(dir)
+	}
+	db, err := client.CreateDB(context.Background(), "test")
+	if err != nil {
+		cleanup()
+		t.Fatal(err)
+	}
+	return client, db, cleanup
+}
+
+func TestDatabases(t *testing.T) {
+	client, _, cleanup := newTestDB(t)
+	defer cleanup()
+	ctx := context.Background()
+	if _, err := client.CreateDB(ctx, "test"); kivik.StatusCode(err) != kivik.StatusPreconditionFailed {
+		t.Errorf("Unexpected error creating duplicate database: %v", err)
+	}
+	if _, err := client.CreateDB(ctx, "another"); err != nil {
+		t.Fatal(err)
+	}
+	dbs, err := client.AllDBs(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if d := diff.Interface([]string{"another", "test"}, dbs); d != nil {
+		t.Error(d)
+	}
+	if err := client.DestroyDB(ctx, "another"); err != nil {
+		t.Fatal(err)
+	}
+	if err := client.DestroyDB(ctx, "another"); kivik.StatusCode(err) != kivik.StatusNotFound {
+		t.Errorf("Unexpected error destroying missing database: %v", err)
+	}
+	db, err := client.DB(ctx, "another")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := db.Put(ctx, "foo", map[string]interface{}{}); kivik.StatusCode(err) != kivik.StatusNotFound {
+		t.Errorf("Unexpected error writing to missing database: %v", err)
+	}
+}
+
+func TestCRUD(t *testing.T) {
+	_, db, cleanup := newTestDB(t)
+	defer cleanup()
+	ctx := context.Background()
+	rev, err := db.Put(ctx, "foo", map[string]interface{}{"value": 1})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !strings.HasPrefix(rev, "1-") {
+		t.Errorf("Unexpected rev: %s", rev)
+	}
+	if _, err := db.Put(ctx, "foo", map[string]interface{}{"value": 2}); kivik.StatusCode(err) != kivik.StatusConflict {
+		t.Errorf("Expected conflict, got %v", err)
+	}
+	rev2, err := db.Put(ctx, "foo", map[string]interface{}{"_rev": rev, "value": 2})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := db.Put(ctx, "foo", map[string]interface{}{"_rev": rev, "value": 3}); kivik.StatusCode(err) != kivik.StatusConflict {
+		t.Errorf("Expected conflict updating superseded rev, got %v", err)
+	}
+	var doc map[string]interface{}
+	if err := db.Get(ctx, "foo", kivik.Options{"revs": true}).ScanDoc(&doc); err != nil {
+		t.Fatal(err)
+	}
+	expected := map[string]interface{}{
+		"_id":   "foo",
+		"_rev":  rev2,
+		"value": 2.0,
+		"_revisions": map[string]interface{}{
+			"start": 2.0,
+			"ids":   []interface{}{strings.TrimPrefix(rev2, "2-"), strings.TrimPrefix(rev, "1-")},
+		},
+	}
+	if d := diff.Interface(expected, doc); d != nil {
+		t.Error(d)
+	}
+	if err := db.Get(ctx, "foo", kivik.Options{"rev": rev}).Err; kivik.StatusCode(err) != kivik.StatusNotFound {
+		t.Errorf("Expected superseded revision to be missing, got %v", err)
+	}
+	rev3, err := db.Delete(ctx, "foo", rev2)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := db.Get(ctx, "foo").Err; kivik.StatusCode(err) != kivik.StatusNotFound {
+		t.Errorf("Expected deleted document, got %v", err)
+	}
+	rev4, err := db.Put(ctx, "foo", map[string]interface{}{"value": 4})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !strings.HasPrefix(rev4, "4-") {
+		t.Errorf("Expected recreated document to follow %s, got %s", rev3, rev4)
+	}
+
+	t.Run("local", func(t *testing.T) {
+		rev, err := db.Put(ctx, "_local/foo", map[string]interface{}{"value": 1})
+		if err != nil {
+			t.Fatal(err)
+		}
+		if rev != "0-1" {
+			t.Errorf("Unexpected rev: %s", rev)
+		}
+		if _, err := db.Delete(ctx, "_local/foo", rev); err != nil {
+			t.Fatal(err)
+		}
+		if err := db.Get(ctx, "_local/foo").Err; kivik.StatusCode(err) != kivik.StatusNotFound {
+			t.Errorf("Expected missing local document, got %v", err)
+		}
+	})
+}
+
+func TestConflicts(t *testing.T) {
+	_, db, cleanup := newTestDB(t)
+	defer cleanup()
+	ctx := context.Background()
+	rev, err := db.Put(ctx, "foo", map[string]interface{}{"value": 1})
+	if err != nil {
+		t.Fatal(err)
+	}
+	_, hash, _ := parseRev(rev)
+	for _, branch := range []string{"aaa", "zzz"} {
+		_, err := db.Put(ctx, "foo", map[string]interface{}{
+			"_rev":       "2-" + branch,
+			"_revisions": map[string]interface{}{"start": 2, "ids": []string{branch, hash}},
+			"value":      branch,
+		}, kivik.Options{"new_edits": false})
+		if err != nil {
+			t.Fatal(err)
+		}
+	}
+	conflicts, err := db.Conflicts(ctx, "foo")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if d := diff.Interface([]string{"2-aaa"}, conflicts); d != nil {
+		t.Error(d)
+	}
+	tree, err := db.RevisionTree(ctx, "foo")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if d := diff.Interface([]string{"2-aaa", "2-zzz"}, tree.Leaves()); d != nil {
+		t.Error(d)
+	}
+	rows, err := db.RevsDiff(ctx, map[string][]string{"foo": {"2-aaa", "3-bbb"}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	var diffs []kivik.RevDiff
+	for rows.Next() {
+		var rd kivik.RevDiff
+		if err := rows.ScanValue(&rd); err != nil {
+			t.Fatal(err)
+		}
+		diffs = append(diffs, rd)
+	}
+	expected := []kivik.RevDiff{{Missing: []string{"3-bbb"}, PossibleAncestors: []string{"2-aaa", "2-zzz"}}}
+	if d := diff.Interface(expected, diffs); d != nil {
+		t.Error(d)
+	}
+	if _, err := db.ResolveConflict(ctx, "foo", map[string]interface{}{"value": "merged"}); err != nil {
+		t.Fatal(err)
+	}
+	if conflicts, _ := db.Conflicts(ctx, "foo"); len(conflicts) != 0 {
+		t.Errorf("Unexpected conflicts after resolution: %v", conflicts)
+	}
+}
+
+func TestAllDocs(t *testing.T) {
+	_, db, cleanup := newTestDB(t)
+	defer cleanup()
+	ctx := context.Background()
+	for _, id := range []string{"c", "a", "e", "d", "b"} {
+		rev, err := db.Put(ctx, id, map[string]interface{}{})
+		if err != nil {
+			t.Fatal(err)
+		}
+		if id == "e" {
+			if _, err := db.Delete(ctx, id, rev); err != nil {
+				t.Fatal(err)
+			}
+		}
+	}
+	tests := []struct {
+		name     string
+		options  kivik.Options
+		expected []string
+		offset   int64
+	}{
+		{
+			name:     "all",
+			expected: []string{"a", "b", "c", "d"},
+		},
+		{
+			name:     "descending",
+			options:  kivik.Options{"descending": true},
+			expected: []string{"d", "c", "b", "a"},
+		},
+		{
+			name:     "range",
+			options:  kivik.Options{"startkey": `"b"`, "endkey": `"c"`},
+			expected: []string{"b", "c"},
+			offset:   1,
+		},
+		{
+			name:     "descending range",
+			options:  kivik.Options{"startkey": `"c"`, "endkey": `"b"`, "descending": true},
+			expected: []string{"c", "b"},
+			offset:   1,
+		},
+		{
+			name:     "exclusive end",
+			options:  kivik.Options{"startkey": `"b"`, "endkey": `"d"`, "inclusive_end": false},
+			expected: []string{"b", "c"},
+			offset:   1,
+		},
+		{
+			name:     "skip and limit",
+			options:  kivik.Options{"skip": 1, "limit": 2},
+			expected: []string{"b", "c"},
+			offset:   1,
+		},
+		{
+			name:     "keys",
+			options:  kivik.Options{"keys": []string{"d", "a"}},
+			expected: []string{"d", "a"},
+		},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			rows, err := db.AllDocs(ctx, test.options)
+			if err != nil {
+				t.Fatal(err)
+			}
+			var ids []string
+			for rows.Next() {
+				ids = append(ids, rows.ID())
+			}
+			if err := rows.Err(); err != nil {
+				t.Fatal(err)
+			}
+			if d := diff.Interface(test.expected, ids); d != nil {
+				t.Error(d)
+			}
+			if rows.Offset() != test.offset {
+				t.Errorf("Unexpected offset: %d", rows.Offset())
+			}
+			if rows.TotalRows() != 4 {
+				t.Errorf("Unexpected total rows: %d", rows.TotalRows())
+			}
+		})
+	}
+}
+
+func TestChanges(t *testing.T) {
+	_, db, cleanup := newTestDB(t)
+	defer cleanup()
+	ctx := context.Background()
+	rev, err := db.Put(ctx, "a", map[string]interface{}{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := db.Put(ctx, "b", map[string]interface{}{}); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := db.Delete(ctx, "a", rev); err != nil {
+		t.Fatal(err)
+	}
+	changes, err := db.Changes(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var ids []string
+	for changes.Next() {
+		ids = append(ids, changes.ID())
+		if changes.ID() == "a" && !changes.Deleted() {
+			t.Error("Expected a to be deleted")
+		}
+	}
+	if err := changes.Err(); err != nil {
+		t.Fatal(err)
+	}
+	if d := diff.Interface([]string{"b", "a"}, ids); d != nil {
+		t.Error(d)
+	}
+	if changes.LastSeq() != "3" {
+		t.Errorf("Unexpected last seq: %s", changes.LastSeq())
+	}
+
+	t.Run("limit", func(t *testing.T) {
+		changes, err := db.Changes(ctx, kivik.Options{"limit": 1})
+		if err != nil {
+			t.Fatal(err)
+		}
+		var ids []string
+		for changes.Next() {
+			ids = append(ids, changes.ID())
+		}
+		if d := diff.Interface([]string{"b"}, ids); d != nil {
+			t.Error(d)
+		}
+		if changes.Pending() != 1 {
+			t.Errorf("Unexpected pending: %d", changes.Pending())
+		}
+	})
+
+	t.Run("longpoll", func(t *testing.T) {
+		changes, err := db.Changes(ctx, kivik.Options{"feed": "longpoll", "since": "now"})
+		if err != nil {
+			t.Fatal(err)
+		}
+		go func() {
+			time.Sleep(50 * time.Millisecond)
+			_, _ = db.Put(ctx, "c", map[string]interface{}{})
+		}()
+		var ids []string
+		for changes.Next() {
+			ids = append(ids, changes.ID())
+		}
+		if err := changes.Err(); err != nil {
+			t.Fatal(err)
+		}
+		if d := diff.Interface([]string{"c"}, ids); d != nil {
+			t.Error(d)
+		}
+	})
+}
+
+func TestAttachments(t *testing.T) {
+	_, db, cleanup := newTestDB(t)
+	defer cleanup()
+	ctx := context.Background()
+	rev, err := db.PutAttachment(ctx, "foo", "", &kivik.Attachment{
+		Filename:    "foo.txt",
+		ContentType: "text/plain",
+		Content:     ioutil.NopCloser(strings.NewReader("Hello")),
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	rev, err = db.Put(ctx, "foo", map[string]interface{}{
+		"_rev":         rev,
+		"value":        1,
+		"_attachments": map[string]interface{}{"foo.txt": map[string]interface{}{"stub": true}},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	att, err := db.GetAttachment(ctx, "foo", rev, "foo.txt")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer att.Content.Close() // nolint: errcheck
+	content, err := ioutil.ReadAll(att.Content)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(content) != "Hello" {
+		t.Errorf("Unexpected content: %s", content)
+	}
+	if att.RevPos != 1 {
+		t.Errorf("Unexpected revpos: %d", att.RevPos)
+	}
+	if _, err := db.DeleteAttachment(ctx, "foo", rev, "foo.txt"); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := db.GetAttachment(ctx, "foo", "", "foo.txt"); kivik.StatusCode(err) != kivik.StatusNotFound {
+		t.Errorf("Expected missing attachment, got %v", err)
+	}
+	if err := db.Compact(ctx); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestFind(t *testing.T) {
+	_, db, cleanup := newTestDB(t)
+	defer cleanup()
+	ctx := context.Background()
+	for id, age := range map[string]int{"alice": 30, "bob": 25, "carol": 35, "dave": 20} {
+		if _, err := db.Put(ctx, id, map[string]interface{}{"age": age}); err != nil {
+			t.Fatal(err)
+		}
+	}
+	rows, err := db.Find(ctx, `{"selector":{"age":{"$gt":22}},"sort":[{"age":"desc"}],"limit":2}`)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var ids []string
+	for rows.Next() {
+		ids = append(ids, rows.ID())
+	}
+	if err := rows.Err(); err != nil {
+		t.Fatal(err)
+	}
+	if d := diff.Interface([]string{"carol", "alice"}, ids); d != nil {
+		t.Error(d)
+	}
+}
+
+func TestPersistence(t *testing.T) {
+	dir, err := ioutil.TempDir("", "kivik-bolt-")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir) // nolint: errcheck
+	path := filepath.Join(dir, "test.db")
+	ctx := context.Background()
+	client, err := kivik.New(ctx, "bolt", path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	db, err := client.CreateDB(ctx, "test")
+	if err != nil {
+		t.Fatal(err)
+	}
+	rev, err := db.Put(ctx, "foo", map[string]interface{}{"value": 1})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := client.Close(ctx); err != nil {
+		t.Fatal(err)
+	}
+	client, err = kivik.New(ctx, "bolt", path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer client.Close(ctx) // nolint: errcheck
+	db, err = client.DB(ctx, "test")
+	if err != nil {
+		t.Fatal(err)
+	}
+	stats, err := db.Stats(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if stats.DocCount != 1 || stats.UpdateSeq != "1" {
+		t.Errorf("Unexpected stats: %+v", stats)
+	}
+	if got, _ := db.Rev(ctx, "foo"); got != rev {
+		t.Errorf("Unexpected rev: %s", got)
+	}
+}
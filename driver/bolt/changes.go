@@ -0,0 +1,202 @@
+package bolt
+
+import (
+	"context"
+	"encoding/binary"
+	"fmt"
+	"io"
+	"strconv"
+	"sync"
+
+	bbolt "go.etcd.io/bbolt"
+
+	"github.com/go-kivik/kivik"
+	"github.com/go-kivik/kivik/driver"
+	"github.com/go-kivik/kivik/driver/internal/docquery"
+	"github.com/go-kivik/kivik/errors"
+)
+
+func parseSince(since interface{}, updateSeq uint64) (uint64, error) {
+	switch t := since.(type) {
+	case nil:
+		return 0, nil
+	case int:
+		return uint64(t), nil
+	case int64:
+		return uint64(t), nil
+	case float64:
+		return uint64(t), nil
+	case string:
+		switch t {
+		case "":
+			return 0, nil
+		case "now":
+			return updateSeq, nil
+		}
+		seq, err := strconv.ParseUint(t, 10, 64)
+		if err != nil {
+			return 0, errors.Statusf(kivik.StatusBadRequest, "bolt: invalid since value: %s", t)
+		}
+		return seq, nil
+	}
+	return parseSince(fmt.Sprint(since), updateSeq)
+}
+
+// Changes returns the changes feed, read from the sequence index. The feed
+// option may be "normal", "longpoll" or "continuous"; the latter two wait for
+// further updates. With style=all_docs, every leaf revision is reported.
+func (d *db) Changes(ctx context.Context, opts map[string]interface{}) (driver.Changes, error) {
+	limit, err := docquery.IntOpt(opts, "limit")
+	if err != nil {
+		return nil, err
+	}
+	feed, _ := opts["feed"].(string)
+	style, _ := opts["style"].(string)
+	c := &changes{
+		ctx:         ctx,
+		db:          d,
+		feed:        feed,
+		limit:       limit,
+		includeDocs: docquery.BoolOpt(opts, "include_docs"),
+		allDocs:     style == "all_docs",
+		descending:  docquery.BoolOpt(opts, "descending") && (feed == "" || feed == "normal"),
+		closed:      make(chan struct{}),
+	}
+	err = d.view(func(b *bbolt.Bucket) error {
+		var err error
+		c.since, err = parseSince(opts["since"], b.Bucket(bucketSeqs).Sequence())
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+	c.lastSeq = c.since
+	if err := c.load(); err != nil {
+		return nil, err
+	}
+	return c, nil
+}
+
+type changes struct {
+	ctx         context.Context
+	db          *db
+	feed        string
+	since       uint64
+	limit       int64
+	includeDocs bool
+	allDocs     bool
+	descending  bool
+
+	pending []*driver.Change
+	// updated is closed on the first update after pending was loaded.
+	updated <-chan struct{}
+	sent    int64
+	// lastSeq is the sequence of the last change returned.
+	lastSeq uint64
+	remain  int64
+
+	closeOnce sync.Once
+	closed    chan struct{}
+}
+
+var _ driver.Changes = &changes{}
+
+// load reads the changes after c.since.
+func (c *changes) load() error {
+	c.updated = c.db.client.wait()
+	var pending []*driver.Change
+	since := c.since
+	err := c.db.view(func(b *bbolt.Bucket) error {
+		cur := b.Bucket(bucketSeqs).Cursor()
+		for k, v := cur.Seek(seqKey(c.since + 1)); k != nil; k, v = cur.Next() {
+			since = binary.BigEndian.Uint64(k)
+			change, err := c.change(b, since, string(v))
+			if err != nil {
+				return err
+			}
+			pending = append(pending, change)
+		}
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+	if c.descending {
+		for i, j := 0, len(pending)-1; i < j; i, j = i+1, j-1 {
+			pending[i], pending[j] = pending[j], pending[i]
+		}
+	}
+	c.since, c.pending = since, pending
+	return nil
+}
+
+func (c *changes) change(b *bbolt.Bucket, seq uint64, docID string) (*driver.Change, error) {
+	rec, err := readRecord(b, docID)
+	if err != nil {
+		return nil, err
+	}
+	winner := rec.winner()
+	change := &driver.Change{
+		ID:      docID,
+		Seq:     driver.SequenceID(strconv.FormatUint(seq, 10)),
+		Deleted: winner.Deleted,
+		Changes: driver.ChangedRevs{winner.Rev},
+	}
+	if c.allDocs {
+		for _, leaf := range rec.leaves() {
+			if leaf != winner {
+				change.Changes = append(change.Changes, leaf.Rev)
+			}
+		}
+	}
+	if c.includeDocs {
+		if change.Doc, err = renderDoc(b, docID, rec, winner, nil); err != nil {
+			return nil, err
+		}
+	}
+	return change, nil
+}
+
+func (c *changes) polling() bool {
+	return c.feed == "longpoll" || c.feed == "continuous"
+}
+
+func (c *changes) Next(change *driver.Change) error {
+	if c.limit > 0 && c.sent >= c.limit {
+		c.remain = int64(len(c.pending))
+		c.pending = nil
+		return io.EOF
+	}
+	for len(c.pending) == 0 {
+		if !c.polling() || (c.feed == "longpoll" && c.sent > 0) {
+			return io.EOF
+		}
+		select {
+		case <-c.ctx.Done():
+			return c.ctx.Err()
+		case <-c.closed:
+			return io.EOF
+		case <-c.updated:
+		}
+		if err := c.load(); err != nil {
+			return err
+		}
+	}
+	*change, c.pending = *c.pending[0], c.pending[1:]
+	c.sent++
+	c.lastSeq, _ = strconv.ParseUint(string(change.Seq), 10, 64)
+	return nil
+}
+
+func (c *changes) Close() error {
+	c.closeOnce.Do(func() { close(c.closed) })
+	return nil
+}
+
+func (c *changes) LastSeq() string {
+	return strconv.FormatUint(c.lastSeq, 10)
+}
+
+func (c *changes) Pending() int64 {
+	return c.remain
+}
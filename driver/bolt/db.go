@@ -0,0 +1,769 @@
+package bolt
+
+import (
+	"bytes"
+	"context"
+	"crypto/md5"
+	"encoding/base64"
+	"encoding/binary"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"sort"
+	"strconv"
+	"strings"
+
+	bbolt "go.etcd.io/bbolt"
+
+	"github.com/go-kivik/kivik"
+	"github.com/go-kivik/kivik/driver"
+	"github.com/go-kivik/kivik/driver/internal/docquery"
+	"github.com/go-kivik/kivik/errors"
+)
+
+var (
+	errDBNotFound = errors.Status(kivik.StatusNotFound, "Database does not exist.")
+	errMissing    = errors.Status(kivik.StatusNotFound, "missing")
+	errDeleted    = errors.Status(kivik.StatusNotFound, "deleted")
+	errConflict   = errors.Status(kivik.StatusConflict, "Document update conflict.")
+)
+
+// The buckets within each database's bucket.
+var (
+	// bucketDocs maps document IDs to their docRecords.
+	bucketDocs = []byte("docs")
+	// bucketSeqs maps update sequences to the IDs of the documents last
+	// updated at that sequence.
+	bucketSeqs = []byte("seqs")
+	// bucketLocal maps local document IDs to their bodies.
+	bucketLocal = []byte("local")
+	// bucketAtts maps attachment digests to their content.
+	bucketAtts = []byte("atts")
+	// bucketMeta holds the database's security object, indexes and counts.
+	bucketMeta = []byte("meta")
+
+	buckets = [][]byte{bucketDocs, bucketSeqs, bucketLocal, bucketAtts, bucketMeta}
+)
+
+var (
+	keySecurity = []byte("security")
+	keyIndexes  = []byte("indexes")
+	keyDocCount = []byte("doc_count")
+	keyDelCount = []byte("doc_del_count")
+)
+
+type db struct {
+	client *client
+	name   string
+}
+
+var (
+	_ driver.DB                   = &db{}
+	_ driver.RevsDiffer           = &db{}
+	_ driver.OpenRever            = &db{}
+	_ driver.AttachmentMetaGetter = &db{}
+)
+
+// view calls fn with the database's bucket, in a read-only transaction.
+func (d *db) view(fn func(*bbolt.Bucket) error) error {
+	return d.client.db.View(func(tx *bbolt.Tx) error {
+		b := tx.Bucket([]byte(d.name))
+		if b == nil {
+			return errDBNotFound
+		}
+		return fn(b)
+	})
+}
+
+// update calls fn with the database's bucket, in a read-write transaction.
+func (d *db) update(fn func(*bbolt.Bucket) error) error {
+	return d.client.update(func(tx *bbolt.Tx) error {
+		b := tx.Bucket([]byte(d.name))
+		if b == nil {
+			return errDBNotFound
+		}
+		return fn(b)
+	})
+}
+
+func seqKey(seq uint64) []byte {
+	key := make([]byte, 8)
+	binary.BigEndian.PutUint64(key, seq)
+	return key
+}
+
+func readRecord(b *bbolt.Bucket, docID string) (*docRecord, error) {
+	data := b.Bucket(bucketDocs).Get([]byte(docID))
+	if data == nil {
+		return nil, nil
+	}
+	rec := &docRecord{}
+	if err := json.Unmarshal(data, rec); err != nil {
+		return nil, errors.Wrapf(err, "bolt: corrupt document %s", docID)
+	}
+	return rec, nil
+}
+
+// docState is the contribution of a document to the document counts.
+type docState int
+
+const (
+	stateNone docState = iota
+	stateLive
+	stateDeleted
+)
+
+func (r *docRecord) state() docState {
+	if len(r.Revs) == 0 {
+		return stateNone
+	}
+	if r.winner().Deleted {
+		return stateDeleted
+	}
+	return stateLive
+}
+
+// putRecord stores rec at the next update sequence, replacing its previous
+// entry in the sequence index, and adjusts the document counts according to
+// prev, the state of the document before the update.
+func putRecord(b *bbolt.Bucket, docID string, rec *docRecord, prev docState) error {
+	seqs := b.Bucket(bucketSeqs)
+	if rec.Seq > 0 {
+		if err := seqs.Delete(seqKey(rec.Seq)); err != nil {
+			return err
+		}
+	}
+	seq, err := seqs.NextSequence()
+	if err != nil {
+		return err
+	}
+	rec.Seq = seq
+	if err := seqs.Put(seqKey(seq), []byte(docID)); err != nil {
+		return err
+	}
+	data, err := json.Marshal(rec)
+	if err != nil {
+		return err
+	}
+	if err := b.Bucket(bucketDocs).Put([]byte(docID), data); err != nil {
+		return err
+	}
+	if next := rec.state(); next != prev {
+		if err := addCount(b, prev, -1); err != nil {
+			return err
+		}
+		return addCount(b, next, 1)
+	}
+	return nil
+}
+
+func readCount(b *bbolt.Bucket, key []byte) int64 {
+	data := b.Bucket(bucketMeta).Get(key)
+	if len(data) != 8 {
+		return 0
+	}
+	return int64(binary.BigEndian.Uint64(data))
+}
+
+func addCount(b *bbolt.Bucket, state docState, delta int64) error {
+	var key []byte
+	switch state {
+	case stateLive:
+		key = keyDocCount
+	case stateDeleted:
+		key = keyDelCount
+	default:
+		return nil
+	}
+	return b.Bucket(bucketMeta).Put(key, seqKey(uint64(readCount(b, key)+delta)))
+}
+
+func isLocal(docID string) bool {
+	return strings.HasPrefix(docID, "_local/")
+}
+
+func validateID(docID string) error {
+	if docID == "" {
+		return errors.Status(kivik.StatusBadRequest, "Document id must not be empty")
+	}
+	if strings.HasPrefix(docID, "_") && !strings.HasPrefix(docID, "_design/") && !isLocal(docID) {
+		return errors.Status(kivik.StatusBadRequest, "Only reserved document ids may start with underscore.")
+	}
+	return nil
+}
+
+// normalize converts doc to a map by way of JSON.
+func normalize(doc interface{}) (map[string]interface{}, error) {
+	var data []byte
+	switch t := doc.(type) {
+	case json.RawMessage:
+		data = t
+	case []byte:
+		data = t
+	default:
+		var err error
+		if data, err = json.Marshal(doc); err != nil {
+			return nil, errors.WrapStatus(kivik.StatusBadRequest, err)
+		}
+	}
+	var result map[string]interface{}
+	if err := json.Unmarshal(data, &result); err != nil {
+		return nil, errors.WrapStatus(kivik.StatusBadRequest, err)
+	}
+	if result == nil {
+		return nil, errors.Status(kivik.StatusBadRequest, "Document must be a JSON object")
+	}
+	return result, nil
+}
+
+// decodeBody decodes a stored revision body.
+func decodeBody(body []byte) map[string]interface{} {
+	doc := make(map[string]interface{})
+	_ = json.Unmarshal(body, &doc)
+	return doc
+}
+
+func attachmentsOf(body []byte) map[string]interface{} {
+	atts, _ := decodeBody(body)["_attachments"].(map[string]interface{})
+	return atts
+}
+
+func digest(content []byte) string {
+	sum := md5.Sum(content)
+	return "md5-" + base64.StdEncoding.EncodeToString(sum[:])
+}
+
+// prepareBody returns the body to store for doc: its fields, other than
+// special fields, and its attachments. The content of attachments with inline
+// data is stored, and stubs are resolved from stubSource. revPos is recorded
+// for new attachments.
+func prepareBody(b *bbolt.Bucket, doc, stubSource map[string]interface{}, revPos int64) ([]byte, error) {
+	body := make(map[string]interface{}, len(doc))
+	for key, value := range doc {
+		if !strings.HasPrefix(key, "_") {
+			body[key] = value
+		}
+	}
+	atts, _ := doc["_attachments"].(map[string]interface{})
+	stubs := make(map[string]interface{}, len(atts))
+	for filename, a := range atts {
+		att, _ := a.(map[string]interface{})
+		if stub, _ := att["stub"].(bool); stub {
+			existing, ok := stubSource[filename]
+			if !ok {
+				return nil, errors.Statusf(kivik.StatusPreconditionFailed, "Missing attachment stub: %s", filename)
+			}
+			stubs[filename] = existing
+			continue
+		}
+		encoded, _ := att["data"].(string)
+		content, err := base64.StdEncoding.DecodeString(encoded)
+		if err != nil {
+			return nil, errors.Statusf(kivik.StatusBadRequest, "Invalid attachment data for %s", filename)
+		}
+		sum := digest(content)
+		if err := b.Bucket(bucketAtts).Put([]byte(sum), content); err != nil {
+			return nil, err
+		}
+		contentType, _ := att["content_type"].(string)
+		if contentType == "" {
+			contentType = "application/octet-stream"
+		}
+		pos := revPos
+		if p, ok := att["revpos"].(float64); ok {
+			pos = int64(p)
+		}
+		stubs[filename] = map[string]interface{}{
+			"content_type": contentType,
+			"digest":       sum,
+			"length":       len(content),
+			"revpos":       pos,
+			"stub":         true,
+		}
+	}
+	if len(stubs) > 0 {
+		body["_attachments"] = stubs
+	}
+	return json.Marshal(body)
+}
+
+// newEdit stores doc as a new revision, following its _rev, which must be a
+// leaf revision of the document, or empty for a new or deleted document.
+func newEdit(b *bbolt.Bucket, docID string, doc map[string]interface{}) (string, error) {
+	rec, err := readRecord(b, docID)
+	if err != nil {
+		return "", err
+	}
+	parentRev, _ := doc["_rev"].(string)
+	var parent *revNode
+	switch {
+	case rec == nil:
+		if parentRev != "" {
+			return "", errConflict
+		}
+		rec = &docRecord{}
+	case parentRev == "":
+		if winner := rec.winner(); winner.Deleted {
+			parent = winner
+		} else {
+			return "", errConflict
+		}
+	default:
+		if !rec.isLeaf(parentRev) {
+			return "", errConflict
+		}
+		parent = rec.find(parentRev)
+	}
+	prev := rec.state()
+	var stubSource map[string]interface{}
+	var gen int64
+	if parent != nil {
+		parentRev = parent.Rev
+		stubSource = attachmentsOf(parent.Body)
+		gen, _, _ = parseRev(parentRev)
+	}
+	deleted, _ := doc["_deleted"].(bool)
+	body, err := prepareBody(b, doc, stubSource, gen+1)
+	if err != nil {
+		return "", err
+	}
+	rev := newRev(parentRev, deleted, body)
+	rec.add(&revNode{Rev: rev, Parent: parentRev, Deleted: deleted, Body: body})
+	return rev, putRecord(b, docID, rec, prev)
+}
+
+// replicatedEdit stores doc with its existing revision and history, as for
+// new_edits=false.
+func replicatedEdit(b *bbolt.Bucket, docID string, doc map[string]interface{}) (string, error) {
+	rev, _ := doc["_rev"].(string)
+	if rev == "" {
+		return "", errors.Status(kivik.StatusBadRequest, "bolt: _rev is required when new_edits is false")
+	}
+	path, err := revPath(rev, doc["_revisions"])
+	if err != nil {
+		return "", err
+	}
+	rec, err := readRecord(b, docID)
+	if err != nil {
+		return "", err
+	}
+	if rec == nil {
+		rec = &docRecord{}
+	}
+	if rec.find(rev) != nil {
+		return rev, nil
+	}
+	prev := rec.state()
+	var stubSource map[string]interface{}
+	for _, ancestor := range path[1:] {
+		if node := rec.find(ancestor); node != nil && node.Body != nil {
+			stubSource = attachmentsOf(node.Body)
+			break
+		}
+	}
+	gen, _, _ := parseRev(rev)
+	body, err := prepareBody(b, doc, stubSource, gen)
+	if err != nil {
+		return "", err
+	}
+	deleted, _ := doc["_deleted"].(bool)
+	rec.merge(path, deleted, body)
+	return rev, putRecord(b, docID, rec, prev)
+}
+
+// putLocal stores a local document, which has no revision history.
+func putLocal(b *bbolt.Bucket, docID string, doc map[string]interface{}) (string, error) {
+	local := b.Bucket(bucketLocal)
+	var currentRev string
+	if data := local.Get([]byte(docID)); data != nil {
+		currentRev, _ = decodeBody(data)["_rev"].(string)
+	}
+	if rev, _ := doc["_rev"].(string); rev != currentRev {
+		return "", errConflict
+	}
+	if deleted, _ := doc["_deleted"].(bool); deleted {
+		if currentRev == "" {
+			return "", errMissing
+		}
+		return "0-0", local.Delete([]byte(docID))
+	}
+	var gen int64
+	if currentRev != "" {
+		gen, _ = strconv.ParseInt(strings.TrimPrefix(currentRev, "0-"), 10, 64)
+	}
+	rev := fmt.Sprintf("0-%d", gen+1)
+	stored := map[string]interface{}{"_id": docID, "_rev": rev}
+	for key, value := range doc {
+		if !strings.HasPrefix(key, "_") {
+			stored[key] = value
+		}
+	}
+	data, err := json.Marshal(stored)
+	if err != nil {
+		return "", err
+	}
+	return rev, local.Put([]byte(docID), data)
+}
+
+// selectRev returns the revision requested by the rev option, or the winning
+// revision.
+func selectRev(rec *docRecord, rev string) (*revNode, error) {
+	if rec == nil {
+		return nil, errMissing
+	}
+	if rev == "" {
+		winner := rec.winner()
+		if winner.Deleted {
+			return nil, errDeleted
+		}
+		return winner, nil
+	}
+	node := rec.find(rev)
+	if node == nil || node.Body == nil {
+		return nil, errMissing
+	}
+	return node, nil
+}
+
+// renderDoc returns the JSON document for revision node, with any special
+// fields requested by opts.
+func renderDoc(b *bbolt.Bucket, docID string, rec *docRecord, node *revNode, opts map[string]interface{}) ([]byte, error) {
+	doc := decodeBody(node.Body)
+	doc["_id"] = docID
+	doc["_rev"] = node.Rev
+	if node.Deleted {
+		doc["_deleted"] = true
+	}
+	if docquery.BoolOpt(opts, "revs") {
+		gen, _, _ := parseRev(node.Rev)
+		doc["_revisions"] = map[string]interface{}{
+			"start": gen,
+			"ids":   rec.ancestry(node.Rev),
+		}
+	}
+	if docquery.BoolOpt(opts, "conflicts") {
+		if conflicts := rec.conflicts(false); len(conflicts) > 0 {
+			doc["_conflicts"] = conflicts
+		}
+	}
+	if docquery.BoolOpt(opts, "deleted_conflicts") {
+		if conflicts := rec.conflicts(true); len(conflicts) > 0 {
+			doc["_deleted_conflicts"] = conflicts
+		}
+	}
+	if docquery.BoolOpt(opts, "attachments") {
+		if atts, ok := doc["_attachments"].(map[string]interface{}); ok {
+			for filename, a := range atts {
+				att, _ := a.(map[string]interface{})
+				sum, _ := att["digest"].(string)
+				delete(att, "stub")
+				att["data"] = base64.StdEncoding.EncodeToString(b.Bucket(bucketAtts).Get([]byte(sum)))
+				atts[filename] = att
+			}
+		}
+	}
+	return json.Marshal(doc)
+}
+
+func (d *db) Get(_ context.Context, docID string, opts map[string]interface{}) (*driver.Document, error) {
+	rev, _ := opts["rev"].(string)
+	var body []byte
+	err := d.view(func(b *bbolt.Bucket) error {
+		if isLocal(docID) {
+			data := b.Bucket(bucketLocal).Get([]byte(docID))
+			if data == nil {
+				return errMissing
+			}
+			body = append([]byte(nil), data...)
+			return nil
+		}
+		rec, err := readRecord(b, docID)
+		if err != nil {
+			return err
+		}
+		node, err := selectRev(rec, rev)
+		if err != nil {
+			return err
+		}
+		rev = node.Rev
+		body, err = renderDoc(b, docID, rec, node, opts)
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+	if isLocal(docID) {
+		rev, _ = decodeBody(body)["_rev"].(string)
+	}
+	return &driver.Document{
+		ContentLength: int64(len(body)),
+		Rev:           rev,
+		Body:          ioutil.NopCloser(bytes.NewReader(body)),
+	}, nil
+}
+
+func (d *db) CreateDoc(ctx context.Context, doc interface{}, opts map[string]interface{}) (docID, rev string, err error) {
+	stored, err := normalize(doc)
+	if err != nil {
+		return "", "", err
+	}
+	docID, _ = stored["_id"].(string)
+	if docID == "" {
+		if docID, err = kivik.UUIDv4(); err != nil {
+			return "", "", err
+		}
+	}
+	rev, err = d.Put(ctx, docID, stored, opts)
+	return docID, rev, err
+}
+
+func (d *db) Put(_ context.Context, docID string, doc interface{}, opts map[string]interface{}) (string, error) {
+	if err := validateID(docID); err != nil {
+		return "", err
+	}
+	newDoc, err := normalize(doc)
+	if err != nil {
+		return "", err
+	}
+	if rev, _ := opts["rev"].(string); rev != "" {
+		newDoc["_rev"] = rev
+	}
+	newEdits, ok := opts["new_edits"]
+	replicated := ok && (newEdits == false || newEdits == "false")
+	var rev string
+	err = d.update(func(b *bbolt.Bucket) error {
+		var err error
+		switch {
+		case isLocal(docID):
+			rev, err = putLocal(b, docID, newDoc)
+		case replicated:
+			rev, err = replicatedEdit(b, docID, newDoc)
+		default:
+			rev, err = newEdit(b, docID, newDoc)
+		}
+		return err
+	})
+	return rev, err
+}
+
+func (d *db) Delete(_ context.Context, docID, rev string, _ map[string]interface{}) (string, error) {
+	tombstone := map[string]interface{}{"_rev": rev, "_deleted": true}
+	var newRev string
+	err := d.update(func(b *bbolt.Bucket) error {
+		if isLocal(docID) {
+			var err error
+			newRev, err = putLocal(b, docID, tombstone)
+			return err
+		}
+		rec, err := readRecord(b, docID)
+		if err != nil {
+			return err
+		}
+		if rec == nil {
+			return errMissing
+		}
+		if rec.winner().Deleted {
+			return errDeleted
+		}
+		newRev, err = newEdit(b, docID, tombstone)
+		return err
+	})
+	return newRev, err
+}
+
+func (d *db) Stats(_ context.Context) (*driver.DBStats, error) {
+	stats := &driver.DBStats{Name: d.name}
+	err := d.view(func(b *bbolt.Bucket) error {
+		stats.DocCount = readCount(b, keyDocCount)
+		stats.DeletedCount = readCount(b, keyDelCount)
+		stats.UpdateSeq = driver.SequenceID(strconv.FormatUint(b.Bucket(bucketSeqs).Sequence(), 10))
+		bs := b.Stats()
+		stats.DiskSize = int64(bs.BranchAlloc + bs.LeafAlloc)
+		stats.ActiveSize = int64(bs.BranchInuse + bs.LeafInuse)
+		return b.Bucket(bucketDocs).ForEach(func(k, v []byte) error {
+			rec := &docRecord{}
+			if err := json.Unmarshal(v, rec); err != nil {
+				return errors.Wrapf(err, "bolt: corrupt document %s", k)
+			}
+			if winner := rec.winner(); !winner.Deleted {
+				stats.ExternalSize += int64(len(winner.Body))
+			}
+			return nil
+		})
+	})
+	if err != nil {
+		return nil, err
+	}
+	return stats, nil
+}
+
+// Compact removes the content of attachments no longer referenced by any
+// stored revision. Space freed within the file is reused, but the file does
+// not shrink.
+func (d *db) Compact(_ context.Context) error {
+	return d.update(func(b *bbolt.Bucket) error {
+		used := make(map[string]bool)
+		err := b.Bucket(bucketDocs).ForEach(func(k, v []byte) error {
+			rec := &docRecord{}
+			if err := json.Unmarshal(v, rec); err != nil {
+				return errors.Wrapf(err, "bolt: corrupt document %s", k)
+			}
+			for _, node := range rec.Revs {
+				for _, a := range attachmentsOf(node.Body) {
+					att, _ := a.(map[string]interface{})
+					sum, _ := att["digest"].(string)
+					used[sum] = true
+				}
+			}
+			return nil
+		})
+		if err != nil {
+			return err
+		}
+		var unused [][]byte
+		atts := b.Bucket(bucketAtts)
+		_ = atts.ForEach(func(k, _ []byte) error {
+			if !used[string(k)] {
+				unused = append(unused, append([]byte(nil), k...))
+			}
+			return nil
+		})
+		for _, k := range unused {
+			if err := atts.Delete(k); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+}
+
+// CompactView does nothing, as views are not supported.
+func (d *db) CompactView(_ context.Context, _ string) error {
+	return nil
+}
+
+// ViewCleanup does nothing, as views are not supported.
+func (d *db) ViewCleanup(_ context.Context) error {
+	return nil
+}
+
+func (d *db) Security(_ context.Context) (*driver.Security, error) {
+	sec := &driver.Security{}
+	err := d.view(func(b *bbolt.Bucket) error {
+		data := b.Bucket(bucketMeta).Get(keySecurity)
+		if data == nil {
+			return nil
+		}
+		return json.Unmarshal(data, sec)
+	})
+	if err != nil {
+		return nil, err
+	}
+	return sec, nil
+}
+
+func (d *db) SetSecurity(_ context.Context, security *driver.Security) error {
+	data, err := json.Marshal(security)
+	if err != nil {
+		return err
+	}
+	return d.update(func(b *bbolt.Bucket) error {
+		return b.Bucket(bucketMeta).Put(keySecurity, data)
+	})
+}
+
+func (d *db) Query(_ context.Context, _, _ string, _ map[string]interface{}) (driver.Rows, error) {
+	return nil, errors.Status(kivik.StatusNotImplemented, "bolt: views are not supported")
+}
+
+func (d *db) RevsDiff(_ context.Context, revMap interface{}) (driver.Rows, error) {
+	data, err := json.Marshal(revMap)
+	if err != nil {
+		return nil, errors.WrapStatus(kivik.StatusBadRequest, err)
+	}
+	var revs map[string][]string
+	if err := json.Unmarshal(data, &revs); err != nil {
+		return nil, errors.WrapStatus(kivik.StatusBadRequest, err)
+	}
+	ids := make([]string, 0, len(revs))
+	for id := range revs {
+		ids = append(ids, id)
+	}
+	sort.Strings(ids)
+	var rows []*driver.Row
+	err = d.view(func(b *bbolt.Bucket) error {
+		for _, id := range ids {
+			rec, err := readRecord(b, id)
+			if err != nil {
+				return err
+			}
+			if rec == nil {
+				rec = &docRecord{}
+			}
+			var missing []string
+			var maxGen int64
+			for _, rev := range revs[id] {
+				if rec.find(rev) == nil {
+					missing = append(missing, rev)
+					if gen, _, _ := parseRev(rev); gen > maxGen {
+						maxGen = gen
+					}
+				}
+			}
+			if len(missing) == 0 {
+				continue
+			}
+			diff := map[string][]string{"missing": missing}
+			for _, leaf := range rec.leaves() {
+				if gen, _, _ := parseRev(leaf.Rev); gen < maxGen {
+					diff["possible_ancestors"] = append(diff["possible_ancestors"], leaf.Rev)
+				}
+			}
+			value, _ := json.Marshal(diff)
+			rows = append(rows, &driver.Row{ID: id, Value: value})
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return &rowsIter{rows: rows}, nil
+}
+
+// OpenRevs returns the requested leaf revisions, or all leaf revisions if
+// revs is empty.
+func (d *db) OpenRevs(_ context.Context, docID string, revs []string, opts map[string]interface{}) (driver.Rows, error) {
+	var rows []*driver.Row
+	err := d.view(func(b *bbolt.Bucket) error {
+		rec, err := readRecord(b, docID)
+		if err != nil {
+			return err
+		}
+		if rec == nil {
+			return errMissing
+		}
+		if len(revs) == 0 {
+			for _, leaf := range rec.leaves() {
+				revs = append(revs, leaf.Rev)
+			}
+			sort.Strings(revs)
+		}
+		for _, rev := range revs {
+			node, err := selectRev(rec, rev)
+			if err != nil {
+				rows = append(rows, &driver.Row{ID: docID, Error: err})
+				continue
+			}
+			doc, err := renderDoc(b, docID, rec, node, opts)
+			if err != nil {
+				return err
+			}
+			rows = append(rows, &driver.Row{ID: docID, Doc: doc})
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return &rowsIter{rows: rows}, nil
+}
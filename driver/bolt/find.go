@@ -0,0 +1,158 @@
+package bolt
+
+import (
+	"context"
+	"crypto/md5"
+	"encoding/json"
+	"fmt"
+	"strings"
+
+	bbolt "go.etcd.io/bbolt"
+
+	"github.com/go-kivik/kivik"
+	"github.com/go-kivik/kivik/driver"
+	"github.com/go-kivik/kivik/driver/internal/docquery"
+	"github.com/go-kivik/kivik/errors"
+)
+
+var _ driver.Finder = &db{}
+
+// Find evaluates the query's selector against every document in the
+// database, other than design documents.
+func (d *db) Find(_ context.Context, query interface{}) (driver.Rows, error) {
+	q, err := docquery.ParseFind(query)
+	if err != nil {
+		return nil, err
+	}
+	var matches []map[string]interface{}
+	err = d.view(func(b *bbolt.Bucket) error {
+		return b.Bucket(bucketDocs).ForEach(func(k, v []byte) error {
+			id := string(k)
+			if strings.HasPrefix(id, "_design/") {
+				return nil
+			}
+			rec := &docRecord{}
+			if err := json.Unmarshal(v, rec); err != nil {
+				return errors.Wrapf(err, "bolt: corrupt document %s", id)
+			}
+			winner := rec.winner()
+			if winner.Deleted {
+				return nil
+			}
+			doc := decodeBody(winner.Body)
+			doc["_id"], doc["_rev"] = id, winner.Rev
+			if q.Match(doc) {
+				matches = append(matches, doc)
+			}
+			return nil
+		})
+	})
+	if err != nil {
+		return nil, err
+	}
+	rows, bookmark, err := q.Rows(matches)
+	if err != nil {
+		return nil, err
+	}
+	return &rowsIter{
+		rows:     rows,
+		warning:  docquery.NoIndexWarning,
+		bookmark: bookmark,
+	}, nil
+}
+
+// readIndexes returns the stored index definitions.
+func readIndexes(b *bbolt.Bucket) ([]driver.Index, error) {
+	data := b.Bucket(bucketMeta).Get(keyIndexes)
+	if data == nil {
+		return nil, nil
+	}
+	var indexes []driver.Index
+	return indexes, json.Unmarshal(data, &indexes)
+}
+
+func writeIndexes(b *bbolt.Bucket, indexes []driver.Index) error {
+	data, err := json.Marshal(indexes)
+	if err != nil {
+		return err
+	}
+	return b.Bucket(bucketMeta).Put(keyIndexes, data)
+}
+
+// CreateIndex records an index definition. Indexes are reported by
+// GetIndexes, but are not used by Find.
+func (d *db) CreateIndex(_ context.Context, ddoc, name string, index interface{}) error {
+	data, err := docquery.ToJSON(index)
+	if err != nil {
+		return err
+	}
+	var def map[string]interface{}
+	if err := json.Unmarshal(data, &def); err != nil {
+		return errors.WrapStatus(kivik.StatusBadRequest, err)
+	}
+	if _, ok := def["fields"].([]interface{}); !ok {
+		return errors.Status(kivik.StatusBadRequest, "bolt: index must include fields")
+	}
+	hash := fmt.Sprintf("%x", md5.Sum(data))
+	if name == "" {
+		name = hash
+	}
+	if ddoc == "" {
+		ddoc = hash
+	}
+	if !strings.HasPrefix(ddoc, "_design/") {
+		ddoc = "_design/" + ddoc
+	}
+	return d.update(func(b *bbolt.Bucket) error {
+		indexes, err := readIndexes(b)
+		if err != nil {
+			return err
+		}
+		for _, idx := range indexes {
+			if idx.DesignDoc == ddoc && idx.Name == name {
+				return nil
+			}
+		}
+		return writeIndexes(b, append(indexes, driver.Index{DesignDoc: ddoc, Name: name, Type: "json", Definition: def}))
+	})
+}
+
+func (d *db) GetIndexes(_ context.Context) ([]driver.Index, error) {
+	var indexes []driver.Index
+	err := d.view(func(b *bbolt.Bucket) error {
+		var err error
+		indexes, err = readIndexes(b)
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+	return append([]driver.Index{docquery.AllDocsIndex}, indexes...), nil
+}
+
+func (d *db) DeleteIndex(_ context.Context, ddoc, name string) error {
+	if !strings.HasPrefix(ddoc, "_design/") {
+		ddoc = "_design/" + ddoc
+	}
+	return d.update(func(b *bbolt.Bucket) error {
+		indexes, err := readIndexes(b)
+		if err != nil {
+			return err
+		}
+		for i, idx := range indexes {
+			if idx.DesignDoc == ddoc && idx.Name == name {
+				return writeIndexes(b, append(indexes[:i], indexes[i+1:]...))
+			}
+		}
+		return errors.Status(kivik.StatusNotFound, "Index not found")
+	})
+}
+
+// Explain reports that every query is answered by scanning all documents.
+func (d *db) Explain(_ context.Context, query interface{}) (*driver.QueryPlan, error) {
+	q, err := docquery.ParseFind(query)
+	if err != nil {
+		return nil, err
+	}
+	return q.Plan(d.name), nil
+}
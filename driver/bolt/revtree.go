@@ -0,0 +1,200 @@
+package bolt
+
+import (
+	"crypto/md5"
+	"encoding/json"
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/go-kivik/kivik"
+	"github.com/go-kivik/kivik/errors"
+)
+
+// revNode is a single revision in a document's revision tree. Only leaf
+// revisions retain their bodies; a body is discarded when the revision is
+// superseded.
+type revNode struct {
+	Rev     string          `json:"rev"`
+	Parent  string          `json:"parent,omitempty"`
+	Deleted bool            `json:"deleted,omitempty"`
+	Body    json.RawMessage `json:"body,omitempty"`
+}
+
+// docRecord is the stored form of a document: its revision tree, and the
+// sequence of its most recent update.
+type docRecord struct {
+	Seq  uint64     `json:"seq"`
+	Revs []*revNode `json:"revs"`
+}
+
+// parseRev splits a revision into its generation and hash.
+func parseRev(rev string) (int64, string, error) {
+	parts := strings.SplitN(rev, "-", 2)
+	if len(parts) != 2 || parts[1] == "" {
+		return 0, "", errors.Status(kivik.StatusBadRequest, "Invalid rev format")
+	}
+	gen, err := strconv.ParseInt(parts[0], 10, 64)
+	if err != nil || gen < 1 {
+		return 0, "", errors.Status(kivik.StatusBadRequest, "Invalid rev format")
+	}
+	return gen, parts[1], nil
+}
+
+// revWins reports whether rev a beats rev b, by CouchDB's deterministic
+// choice of winning revision, disregarding deletion.
+func revWins(a, b string) bool {
+	genA, hashA, _ := parseRev(a)
+	genB, hashB, _ := parseRev(b)
+	if genA != genB {
+		return genA > genB
+	}
+	return hashA > hashB
+}
+
+// newRev returns the revision which follows parent, for the given body.
+func newRev(parent string, deleted bool, body []byte) string {
+	var gen int64
+	if parent != "" {
+		gen, _, _ = parseRev(parent)
+	}
+	h := md5.New()
+	_, _ = h.Write([]byte(parent))
+	_, _ = h.Write([]byte(strconv.FormatBool(deleted)))
+	_, _ = h.Write(body)
+	return fmt.Sprintf("%d-%x", gen+1, h.Sum(nil))
+}
+
+func (r *docRecord) find(rev string) *revNode {
+	for _, node := range r.Revs {
+		if node.Rev == rev {
+			return node
+		}
+	}
+	return nil
+}
+
+// leaves returns the revisions which have no children.
+func (r *docRecord) leaves() []*revNode {
+	parents := make(map[string]bool, len(r.Revs))
+	for _, node := range r.Revs {
+		parents[node.Parent] = true
+	}
+	leaves := make([]*revNode, 0, 1)
+	for _, node := range r.Revs {
+		if !parents[node.Rev] {
+			leaves = append(leaves, node)
+		}
+	}
+	return leaves
+}
+
+func (r *docRecord) isLeaf(rev string) bool {
+	for _, node := range r.leaves() {
+		if node.Rev == rev {
+			return true
+		}
+	}
+	return false
+}
+
+// winner returns the winning revision: the highest non-deleted leaf, or the
+// highest deleted leaf if all leaves are deleted.
+func (r *docRecord) winner() *revNode {
+	var winner *revNode
+	for _, node := range r.leaves() {
+		switch {
+		case winner == nil,
+			winner.Deleted && !node.Deleted,
+			winner.Deleted == node.Deleted && revWins(node.Rev, winner.Rev):
+			winner = node
+		}
+	}
+	return winner
+}
+
+// conflicts returns the non-winning leaves, either deleted or not.
+func (r *docRecord) conflicts(deleted bool) []string {
+	winner := r.winner()
+	var revs []string
+	for _, node := range r.leaves() {
+		if node != winner && node.Deleted == deleted {
+			revs = append(revs, node.Rev)
+		}
+	}
+	return revs
+}
+
+// ancestry returns the hashes of rev and its known ancestors, newest first.
+func (r *docRecord) ancestry(rev string) []string {
+	var hashes []string
+	for node := r.find(rev); node != nil; node = r.find(node.Parent) {
+		_, hash, _ := parseRev(node.Rev)
+		hashes = append(hashes, hash)
+	}
+	return hashes
+}
+
+// add appends node as a child of its parent, discarding the parent's body.
+func (r *docRecord) add(node *revNode) {
+	if parent := r.find(node.Parent); parent != nil {
+		parent.Body = nil
+	}
+	r.Revs = append(r.Revs, node)
+}
+
+// merge adds the revisions in path, newest first, as replicated with
+// new_edits=false. Any revisions already present are left unchanged, and the
+// oldest revision of path is added as a root if it is not known. It returns
+// false if the newest revision was already present.
+func (r *docRecord) merge(path []string, deleted bool, body []byte) bool {
+	if r.find(path[0]) != nil {
+		return false
+	}
+	for i := len(path) - 1; i >= 0; i-- {
+		if r.find(path[i]) != nil {
+			continue
+		}
+		node := &revNode{Rev: path[i]}
+		if i < len(path)-1 {
+			node.Parent = path[i+1]
+		}
+		if i == 0 {
+			node.Deleted = deleted
+			node.Body = body
+		}
+		r.add(node)
+	}
+	return true
+}
+
+// revPath returns the revisions described by a _revisions object, newest
+// first, or just rev if revisions is nil.
+func revPath(rev string, revisions interface{}) ([]string, error) {
+	gen, _, err := parseRev(rev)
+	if err != nil {
+		return nil, err
+	}
+	if revisions == nil {
+		return []string{rev}, nil
+	}
+	data, _ := json.Marshal(revisions)
+	var revs struct {
+		Start int64    `json:"start"`
+		IDs   []string `json:"ids"`
+	}
+	if err := json.Unmarshal(data, &revs); err != nil || revs.Start != gen || len(revs.IDs) == 0 {
+		return nil, errors.Status(kivik.StatusBadRequest, "Invalid _revisions")
+	}
+	path := make([]string, 0, len(revs.IDs))
+	for i, id := range revs.IDs {
+		if revs.Start-int64(i) < 1 {
+			break
+		}
+		path = append(path, fmt.Sprintf("%d-%s", revs.Start-int64(i), id))
+	}
+	if path[0] != rev {
+		return nil, errors.Status(kivik.StatusBadRequest, "Invalid _revisions")
+	}
+	return path, nil
+}
@@ -0,0 +1,114 @@
+package bolt
+
+import (
+	"testing"
+
+	"github.com/flimzy/diff"
+)
+
+func TestWinner(t *testing.T) {
+	tests := []struct {
+		name     string
+		revs     []*revNode
+		expected string
+	}{
+		{
+			name:     "single",
+			revs:     []*revNode{{Rev: "1-a"}},
+			expected: "1-a",
+		},
+		{
+			name:     "highest generation",
+			revs:     []*revNode{{Rev: "1-a"}, {Rev: "2-a", Parent: "1-a"}, {Rev: "2-b", Parent: "1-a"}, {Rev: "3-a", Parent: "2-a"}},
+			expected: "3-a",
+		},
+		{
+			name:     "highest hash",
+			revs:     []*revNode{{Rev: "1-a"}, {Rev: "2-a", Parent: "1-a"}, {Rev: "2-b", Parent: "1-a"}},
+			expected: "2-b",
+		},
+		{
+			name:     "live beats deleted",
+			revs:     []*revNode{{Rev: "1-a"}, {Rev: "2-a", Parent: "1-a"}, {Rev: "2-b", Parent: "1-a"}, {Rev: "3-a", Parent: "2-a", Deleted: true}},
+			expected: "2-b",
+		},
+		{
+			name:     "all deleted",
+			revs:     []*revNode{{Rev: "1-a", Deleted: true}, {Rev: "1-b", Deleted: true}},
+			expected: "1-b",
+		},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			rec := &docRecord{Revs: test.revs}
+			if winner := rec.winner().Rev; winner != test.expected {
+				t.Errorf("Unexpected winner: %s", winner)
+			}
+		})
+	}
+}
+
+func TestMerge(t *testing.T) {
+	rec := &docRecord{}
+	rec.add(&revNode{Rev: "1-a", Body: []byte(`{}`)})
+	if !rec.merge([]string{"3-c", "2-b", "1-a"}, false, []byte(`{"x":1}`)) {
+		t.Fatal("Expected merge to add revisions")
+	}
+	if rec.merge([]string{"3-c", "2-b", "1-a"}, false, nil) {
+		t.Error("Expected repeated merge to do nothing")
+	}
+	if d := diff.Interface([]string{"c", "b", "a"}, rec.ancestry("3-c")); d != nil {
+		t.Error(d)
+	}
+	if rec.find("1-a").Body != nil {
+		t.Error("Expected superseded body to be discarded")
+	}
+}
+
+func TestRevPath(t *testing.T) {
+	tests := []struct {
+		name      string
+		rev       string
+		revisions interface{}
+		expected  []string
+		err       string
+	}{
+		{
+			name:     "no history",
+			rev:      "2-b",
+			expected: []string{"2-b"},
+		},
+		{
+			name:      "history",
+			rev:       "2-b",
+			revisions: map[string]interface{}{"start": 2, "ids": []string{"b", "a"}},
+			expected:  []string{"2-b", "1-a"},
+		},
+		{
+			name:      "mismatch",
+			rev:       "2-b",
+			revisions: map[string]interface{}{"start": 2, "ids": []string{"c", "a"}},
+			err:       "Invalid _revisions",
+		},
+		{
+			name: "invalid rev",
+			rev:  "foo",
+			err:  "Invalid rev format",
+		},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			path, err := revPath(test.rev, test.revisions)
+			var msg string
+			if err != nil {
+				msg = err.Error()
+			}
+			if msg != test.err {
+				t.Errorf("Unexpected error: %s", msg)
+			}
+			if d := diff.Interface(test.expected, path); d != nil {
+				t.Error(d)
+			}
+		})
+	}
+}
@@ -0,0 +1,219 @@
+// Package cache provides a kivik driver which caches the results of reads
+// from another kivik Client, in a pluggable Store, so that every code path
+// which reads through the client benefits:
+//
+//	client, err := cache.New(backend, cache.NewLRU(10000))
+//
+// Documents read with Get, without options, are cached by ID, along with
+// their revision. The results of AllDocs, Query and Find are cached by their
+// options. Entries are invalidated by following the changes feed of each
+// database used, from the first call to DB, and by writes made through the
+// client. Until the feed of a database is established, and whenever it must
+// be re-established, its reads bypass the cache, and once re-established,
+// entries stored before the interruption are no longer used, as changes may
+// have been missed.
+//
+// Any change to a database invalidates its cached AllDocs, Query and Find
+// results, so the cache is most effective for databases which are read much
+// more often than written.
+//
+// Errors from the Store are not returned; the read is passed to the backend
+// instead.
+package cache
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/go-kivik/kivik"
+	"github.com/go-kivik/kivik/driver"
+	"github.com/go-kivik/kivik/driver/proxy"
+	"github.com/go-kivik/kivik/errors"
+	"github.com/go-kivik/kivik/internal/registry"
+)
+
+// DriverName is the name under which the caching driver is registered.
+const DriverName = "cache"
+
+// RetryInterval is the time to wait before re-establishing a database's
+// changes feed, after it fails.
+var RetryInterval = time.Second
+
+type config struct {
+	backend *kivik.Client
+	store   Store
+}
+
+var configs = registry.New(DriverName, func(v interface{}) (driver.Client, error) {
+	cfg := v.(*config)
+	return NewClient(proxy.NewClient(cfg.backend), cfg.store)
+})
+
+func init() {
+	kivik.Register(DriverName, configs.Driver())
+}
+
+// New returns a kivik client which caches reads from backend in store.
+func New(backend *kivik.Client, store Store) (*kivik.Client, error) {
+	if backend == nil {
+		return nil, errors.Status(kivik.StatusBadRequest, "cache: backend required")
+	}
+	if store == nil {
+		return nil, errors.Status(kivik.StatusBadRequest, "cache: store required")
+	}
+	return configs.NewClient(context.Background(), &config{backend: backend, store: store})
+}
+
+// NewClient wraps c, a driver client such as returned by proxy.NewClient,
+// caching reads in store. Closing the returned client stops following the
+// changes feeds, and closes c, if it supports it.
+func NewClient(c driver.Client, store Store) (driver.Client, error) {
+	id, err := kivik.UUIDv4()
+	if err != nil {
+		return nil, err
+	}
+	ctx, cancel := context.WithCancel(context.Background())
+	return &client{
+		Client:   c,
+		store:    store,
+		prefix:   id,
+		ctx:      ctx,
+		cancel:   cancel,
+		watchers: make(map[string]*watcher),
+	}, nil
+}
+
+type client struct {
+	driver.Client
+	store Store
+	// prefix is unique to the client, and prefixes every key, so that no
+	// other client sees the entries it stores.
+	prefix string
+
+	ctx      context.Context
+	cancel   context.CancelFunc
+	mu       sync.Mutex
+	watchers map[string]*watcher
+}
+
+var _ driver.ClientCloser = &client{}
+
+func (c *client) DB(ctx context.Context, dbName string, opts map[string]interface{}) (driver.DB, error) {
+	inner, err := c.Client.DB(ctx, dbName, opts)
+	if err != nil {
+		return nil, err
+	}
+	return &db{DB: inner, client: c, name: dbName, watcher: c.watch(dbName, inner)}, nil
+}
+
+// watch returns the watcher of dbName, starting it, with db, if necessary.
+func (c *client) watch(dbName string, db driver.DB) *watcher {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if w, ok := c.watchers[dbName]; ok {
+		return w
+	}
+	w := &watcher{}
+	c.watchers[dbName] = w
+	go w.run(c.ctx, db, func(docID string) {
+		_, epoch, _ := w.state()
+		_ = c.store.Delete(context.Background(), c.docKey(dbName, epoch, docID))
+	})
+	return w
+}
+
+func (c *client) docKey(dbName string, epoch uint64, docID string) string {
+	return fmt.Sprintf("%s\x00doc\x00%s\x00%d\x00%s", c.prefix, dbName, epoch, docID)
+}
+
+func (c *client) rowsKey(dbName string, gen uint64, query string) string {
+	return fmt.Sprintf("%s\x00rows\x00%s\x00%d\x00%s", c.prefix, dbName, gen, query)
+}
+
+func (c *client) Close(ctx context.Context) error {
+	c.cancel()
+	if closer, ok := c.Client.(driver.ClientCloser); ok {
+		return closer.Close(ctx)
+	}
+	return nil
+}
+
+// watcher follows the changes feed of a database, and tracks whether the
+// cache may be used for it.
+type watcher struct {
+	mu sync.Mutex
+	// gen is incremented with every change, and keys cached result sets.
+	gen uint64
+	// epoch is incremented each time the feed is established, and keys
+	// cached documents.
+	epoch uint64
+	live  bool
+}
+
+// state returns the current generation and epoch, and whether the feed is
+// established.
+func (w *watcher) state() (gen, epoch uint64, live bool) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	return w.gen, w.epoch, w.live
+}
+
+// current reports whether gen is still the current generation, so that a
+// result read in that generation may be stored.
+func (w *watcher) current(gen uint64) bool {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	return w.live && w.gen == gen
+}
+
+func (w *watcher) bump() {
+	w.mu.Lock()
+	w.gen++
+	w.mu.Unlock()
+}
+
+func (w *watcher) setLive(live bool) {
+	w.mu.Lock()
+	w.live = live
+	w.gen++
+	if live {
+		w.epoch++
+	}
+	w.mu.Unlock()
+}
+
+// run follows the changes feed of db, calling invalidate with the ID of each
+// changed document, until ctx is cancelled.
+func (w *watcher) run(ctx context.Context, db driver.DB, invalidate func(docID string)) {
+	for {
+		feed, err := db.Changes(ctx, map[string]interface{}{
+			"feed":  "continuous",
+			"since": "now",
+		})
+		if err == nil {
+			w.setLive(true)
+			for {
+				change := new(driver.Change)
+				if err := feed.Next(change); err != nil {
+					break
+				}
+				// The generation is incremented first, so that a read which
+				// stores the document concurrently sees the change, and
+				// removes it again. See db.set.
+				w.bump()
+				invalidate(change.ID)
+			}
+			_ = feed.Close()
+			w.setLive(false)
+		}
+		timer := time.NewTimer(RetryInterval)
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+			return
+		case <-timer.C:
+		}
+	}
+}
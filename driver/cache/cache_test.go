@@ -0,0 +1,253 @@
+package cache
+
+import (
+	"context"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/flimzy/diff"
+	"github.com/go-kivik/kivik"
+	"github.com/go-kivik/kivik/driver"
+	_ "github.com/go-kivik/kivik/driver/fs"
+	"github.com/go-kivik/kivik/driver/proxy"
+)
+
+// countingHook counts the operations which reach the backend.
+type countingHook struct {
+	mu  sync.Mutex
+	ops map[string]int
+}
+
+func (h *countingHook) Before(ctx context.Context, op *kivik.Operation) context.Context {
+	h.mu.Lock()
+	h.ops[op.Name]++
+	h.mu.Unlock()
+	return ctx
+}
+
+func (h *countingHook) After(context.Context, *kivik.Operation, time.Duration, error) {}
+
+func (h *countingHook) count(name string) int {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	return h.ops[name]
+}
+
+type fixture struct {
+	backend *kivik.DB
+	hook    *countingHook
+	client  driver.Client
+	db      *db
+	cleanup func()
+}
+
+// newFixture returns a caching driver client of an fs database, seeded with
+// a document, once the database's changes feed is established.
+func newFixture(t *testing.T) *fixture {
+	dir, err := ioutil.TempDir("", "kivik-cache-")
+	if err != nil {
+		t.Fatal(err)
+	}
+	f := &fixture{
+		hook:    &countingHook{ops: make(map[string]int)},
+		cleanup: func() { _ = os.RemoveAll(dir) },
+	}
+	ctx := context.Background()
+	backend, err := kivik.New(ctx, "fs", dir)
+	if err != nil {
+		f.cleanup()
+		t.Fatal(err)
+	}
+	if f.backend, err = backend.CreateDB(ctx, "test"); err != nil {
+		f.cleanup()
+		t.Fatal(err)
+	}
+	if _, err := f.backend.Put(ctx, "foo", map[string]int{"n": 1}); err != nil {
+		f.cleanup()
+		t.Fatal(err)
+	}
+	backend.AddHook(f.hook)
+	if f.client, err = NewClient(proxy.NewClient(backend), NewLRU(100)); err != nil {
+		f.cleanup()
+		t.Fatal(err)
+	}
+	cleanup := f.cleanup
+	f.cleanup = func() {
+		_ = f.client.(driver.ClientCloser).Close(ctx)
+		cleanup()
+	}
+	dbi, err := f.client.DB(ctx, "test", nil)
+	if err != nil {
+		f.cleanup()
+		t.Fatal(err)
+	}
+	f.db = dbi.(*db)
+	eventually(t, "changes feed established", func() bool {
+		_, _, live := f.db.watcher.state()
+		return live
+	})
+	return f
+}
+
+// eventually fails the test if cond is not true within a few seconds.
+func eventually(t *testing.T, what string, cond func() bool) {
+	for deadline := time.Now().Add(5 * time.Second); time.Now().Before(deadline); {
+		if cond() {
+			return
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+	t.Fatalf("Timed out waiting for %s", what)
+}
+
+func (f *fixture) get(t *testing.T, docID string) string {
+	doc, err := f.db.Get(context.Background(), docID, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer doc.Body.Close() // nolint: errcheck
+	body, err := ioutil.ReadAll(doc.Body)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return fmt.Sprintf("%s %s", doc.Rev[:1], body)
+}
+
+func TestNew(t *testing.T) {
+	if _, err := New(nil, NewLRU(0)); kivik.StatusCode(err) != kivik.StatusBadRequest {
+		t.Errorf("Unexpected error: %v", err)
+	}
+	backend, err := kivik.New(context.Background(), "fs", os.TempDir())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := New(backend, nil); kivik.StatusCode(err) != kivik.StatusBadRequest {
+		t.Errorf("Unexpected error: %v", err)
+	}
+}
+
+func TestGet(t *testing.T) {
+	f := newFixture(t)
+	defer f.cleanup()
+	first := f.get(t, "foo")
+	if second := f.get(t, "foo"); second != first {
+		t.Errorf("Unexpected cached document: %s", second)
+	}
+	if n := f.hook.count("Get"); n != 1 {
+		t.Errorf("Expected 1 backend Get, got %d", n)
+	}
+	// A write through the client is seen immediately.
+	rev, err := f.backend.Rev(context.Background(), "foo")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := f.db.Put(context.Background(), "foo", map[string]interface{}{"_rev": rev, "n": 2}, nil); err != nil {
+		t.Fatal(err)
+	}
+	if doc := f.get(t, "foo"); doc == first {
+		t.Errorf("Stale document after write: %s", doc)
+	}
+	// A write made directly to the backend is seen via the changes feed.
+	rev, err = f.backend.Rev(context.Background(), "foo")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := f.backend.Put(context.Background(), "foo", map[string]interface{}{"_rev": rev, "n": 3}); err != nil {
+		t.Fatal(err)
+	}
+	eventually(t, "invalidation", func() bool {
+		return strings.HasPrefix(f.get(t, "foo"), "3 ")
+	})
+}
+
+func TestRows(t *testing.T) {
+	f := newFixture(t)
+	defer f.cleanup()
+	ctx := context.Background()
+	readIDs := func() []string {
+		rows, err := f.db.AllDocs(ctx, map[string]interface{}{"include_docs": true})
+		if err != nil {
+			t.Fatal(err)
+		}
+		var ids []string
+		row := new(driver.Row)
+		for rows.Next(row) == nil {
+			ids = append(ids, row.ID)
+		}
+		return ids
+	}
+	if d := diff.Interface([]string{"foo"}, readIDs()); d != nil {
+		t.Error(d)
+	}
+	if d := diff.Interface([]string{"foo"}, readIDs()); d != nil {
+		t.Error(d)
+	}
+	if n := f.hook.count("AllDocs"); n != 1 {
+		t.Errorf("Expected 1 backend AllDocs, got %d", n)
+	}
+	if _, err := f.backend.Put(ctx, "bar", map[string]int{}); err != nil {
+		t.Fatal(err)
+	}
+	eventually(t, "invalidation", func() bool {
+		return len(readIDs()) == 2
+	})
+}
+
+func TestLRU(t *testing.T) {
+	ctx := context.Background()
+	s := NewLRU(2)
+	_ = s.Set(ctx, "a", []byte("1"))
+	_ = s.Set(ctx, "b", []byte("2"))
+	if _, ok, _ := s.Get(ctx, "a"); !ok {
+		t.Fatal("Expected a to be stored")
+	}
+	_ = s.Set(ctx, "c", []byte("3"))
+	if _, ok, _ := s.Get(ctx, "b"); ok {
+		t.Error("Expected b, the least-recently used entry, to be evicted")
+	}
+	if value, ok, _ := s.Get(ctx, "a"); !ok || string(value) != "1" {
+		t.Errorf("Unexpected value for a: %s", value)
+	}
+	_ = s.Delete(ctx, "a")
+	if s.Len() != 1 {
+		t.Errorf("Unexpected length: %d", s.Len())
+	}
+}
+
+// racingStore calls onSet after each Set, to simulate a change observed
+// while an entry is being stored.
+type racingStore struct {
+	Store
+	onSet func()
+}
+
+func (s *racingStore) Set(ctx context.Context, key string, value []byte) error {
+	err := s.Store.Set(ctx, key, value)
+	s.onSet()
+	return err
+}
+
+func TestSetRace(t *testing.T) {
+	ctx := context.Background()
+	w := &watcher{live: true}
+	store := &racingStore{Store: NewLRU(0), onSet: w.bump}
+	d := &db{client: &client{store: store}, name: "test", watcher: w}
+	d.set(ctx, 0, "foo", "stale")
+	if _, ok, _ := store.Get(ctx, "foo"); ok {
+		t.Error("Expected entry invalidated during Set to be deleted")
+	}
+	store.onSet = func() {}
+	d.set(ctx, 0, "foo", "stale")
+	if _, ok, _ := store.Get(ctx, "foo"); ok {
+		t.Error("Expected entry from an old generation not to be stored")
+	}
+	d.set(ctx, 1, "foo", "current")
+	if _, ok, _ := store.Get(ctx, "foo"); !ok {
+		t.Error("Expected current entry to be stored")
+	}
+}
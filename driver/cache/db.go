@@ -0,0 +1,313 @@
+package cache
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"io"
+	"io/ioutil"
+
+	"github.com/go-kivik/kivik"
+	"github.com/go-kivik/kivik/driver"
+	"github.com/go-kivik/kivik/errors"
+)
+
+// db caches reads from the embedded DB.
+type db struct {
+	driver.DB
+	client  *client
+	name    string
+	watcher *watcher
+}
+
+var (
+	_ driver.DB         = &db{}
+	_ driver.DBCloser   = &db{}
+	_ driver.Finder     = &db{}
+	_ driver.RevsDiffer = &db{}
+	_ driver.OpenRever  = &db{}
+)
+
+func notSupported(method string) error {
+	return errors.Statusf(kivik.StatusNotImplemented, "cache: backend does not support %s", method)
+}
+
+// cachedDoc is the stored form of a document.
+type cachedDoc struct {
+	Rev  string `json:"rev"`
+	Body []byte `json:"body"`
+}
+
+// Get returns the cached copy of the document, if any, when no options are
+// given.
+func (d *db) Get(ctx context.Context, docID string, opts map[string]interface{}) (*driver.Document, error) {
+	gen, epoch, live := d.watcher.state()
+	if len(opts) > 0 || !live {
+		return d.DB.Get(ctx, docID, opts)
+	}
+	key := d.client.docKey(d.name, epoch, docID)
+	if data, ok, err := d.client.store.Get(ctx, key); err == nil && ok {
+		var cached cachedDoc
+		if json.Unmarshal(data, &cached) == nil {
+			return cached.document(), nil
+		}
+	}
+	doc, err := d.DB.Get(ctx, docID, opts)
+	if err != nil || doc.Attachments != nil {
+		return doc, err
+	}
+	defer doc.Body.Close() // nolint: errcheck
+	body, err := ioutil.ReadAll(doc.Body)
+	if err != nil {
+		return nil, errors.WrapStatus(kivik.StatusNetworkError, err)
+	}
+	cached := &cachedDoc{Rev: doc.Rev, Body: body}
+	if doc.Rev != "" {
+		d.set(ctx, gen, key, cached)
+	}
+	return cached.document(), nil
+}
+
+// set stores v, read in generation gen, under key, unless the generation has
+// since changed. Invalidations increment the generation before deleting
+// entries, so if it changes while v is being stored, v may be stale, and is
+// deleted again.
+func (d *db) set(ctx context.Context, gen uint64, key string, v interface{}) {
+	if !d.watcher.current(gen) {
+		return
+	}
+	data, err := json.Marshal(v)
+	if err != nil {
+		return
+	}
+	_ = d.client.store.Set(ctx, key, data)
+	if !d.watcher.current(gen) {
+		_ = d.client.store.Delete(ctx, key)
+	}
+}
+
+func (c *cachedDoc) document() *driver.Document {
+	return &driver.Document{
+		ContentLength: int64(len(c.Body)),
+		Rev:           c.Rev,
+		Body:          ioutil.NopCloser(bytes.NewReader(c.Body)),
+	}
+}
+
+// invalidate removes the cached copy of docID, and any cached result sets,
+// after a write through the client, without waiting for the changes feed.
+func (d *db) invalidate(ctx context.Context, docID string) {
+	d.watcher.bump()
+	_, epoch, _ := d.watcher.state()
+	_ = d.client.store.Delete(ctx, d.client.docKey(d.name, epoch, docID))
+}
+
+func (d *db) Put(ctx context.Context, docID string, doc interface{}, opts map[string]interface{}) (string, error) {
+	defer d.invalidate(ctx, docID)
+	return d.DB.Put(ctx, docID, doc, opts)
+}
+
+func (d *db) CreateDoc(ctx context.Context, doc interface{}, opts map[string]interface{}) (string, string, error) {
+	docID, rev, err := d.DB.CreateDoc(ctx, doc, opts)
+	if err == nil {
+		d.invalidate(ctx, docID)
+	}
+	return docID, rev, err
+}
+
+func (d *db) Delete(ctx context.Context, docID, rev string, opts map[string]interface{}) (string, error) {
+	defer d.invalidate(ctx, docID)
+	return d.DB.Delete(ctx, docID, rev, opts)
+}
+
+func (d *db) PutAttachment(ctx context.Context, docID, rev string, att *driver.Attachment, opts map[string]interface{}) (string, error) {
+	defer d.invalidate(ctx, docID)
+	return d.DB.PutAttachment(ctx, docID, rev, att, opts)
+}
+
+func (d *db) DeleteAttachment(ctx context.Context, docID, rev, filename string, opts map[string]interface{}) (string, error) {
+	defer d.invalidate(ctx, docID)
+	return d.DB.DeleteAttachment(ctx, docID, rev, filename, opts)
+}
+
+// cachedRows is the stored form of a result set.
+type cachedRows struct {
+	Rows      []cachedRow `json:"rows"`
+	Offset    int64       `json:"offset"`
+	TotalRows int64       `json:"total_rows"`
+	UpdateSeq string      `json:"update_seq,omitempty"`
+	Warning   string      `json:"warning,omitempty"`
+	Bookmark  string      `json:"bookmark,omitempty"`
+}
+
+type cachedRow struct {
+	ID    string          `json:"id"`
+	Key   json.RawMessage `json:"key,omitempty"`
+	Value json.RawMessage `json:"value,omitempty"`
+	Doc   json.RawMessage `json:"doc,omitempty"`
+	// err is the row's error, such as for a missing document. Result sets
+	// with row errors are not stored.
+	err error
+}
+
+// rows returns the cached result set for query, if any, or else the result
+// of fetch, which is read fully, and stored unless any row failed.
+func (d *db) rows(ctx context.Context, query string, fetch func() (driver.Rows, error)) (driver.Rows, error) {
+	gen, _, live := d.watcher.state()
+	if !live {
+		return fetch()
+	}
+	key := d.client.rowsKey(d.name, gen, query)
+	if data, ok, err := d.client.store.Get(ctx, key); err == nil && ok {
+		result := new(cachedRows)
+		if json.Unmarshal(data, result) == nil {
+			return &rowsIter{cachedRows: result}, nil
+		}
+	}
+	rows, err := fetch()
+	if err != nil {
+		return nil, err
+	}
+	result := &cachedRows{}
+	var rowErr bool
+	for {
+		row := new(driver.Row)
+		if err := rows.Next(row); err != nil {
+			if err == io.EOF {
+				break
+			}
+			_ = rows.Close()
+			return nil, err
+		}
+		rowErr = rowErr || row.Error != nil
+		result.Rows = append(result.Rows, cachedRow{ID: row.ID, Key: row.Key, Value: row.Value, Doc: row.Doc, err: row.Error})
+	}
+	_ = rows.Close()
+	result.Offset, result.TotalRows, result.UpdateSeq = rows.Offset(), rows.TotalRows(), rows.UpdateSeq()
+	if w, ok := rows.(driver.RowsWarner); ok {
+		result.Warning = w.Warning()
+	}
+	if b, ok := rows.(driver.Bookmarker); ok {
+		result.Bookmark = b.Bookmark()
+	}
+	if !rowErr {
+		d.set(ctx, gen, key, result)
+	}
+	return &rowsIter{cachedRows: result}, nil
+}
+
+func queryKey(kind string, args ...interface{}) string {
+	data, _ := json.Marshal(args)
+	return kind + "\x00" + string(data)
+}
+
+func (d *db) AllDocs(ctx context.Context, opts map[string]interface{}) (driver.Rows, error) {
+	return d.rows(ctx, queryKey("all_docs", opts), func() (driver.Rows, error) {
+		return d.DB.AllDocs(ctx, opts)
+	})
+}
+
+func (d *db) Query(ctx context.Context, ddoc, view string, opts map[string]interface{}) (driver.Rows, error) {
+	return d.rows(ctx, queryKey("view", ddoc, view, opts), func() (driver.Rows, error) {
+		return d.DB.Query(ctx, ddoc, view, opts)
+	})
+}
+
+func (d *db) Find(ctx context.Context, query interface{}) (driver.Rows, error) {
+	finder, ok := d.DB.(driver.Finder)
+	if !ok {
+		return nil, notSupported("Find")
+	}
+	var q interface{} = query
+	switch t := query.(type) {
+	case string:
+		q = json.RawMessage(t)
+	case []byte:
+		q = json.RawMessage(t)
+	}
+	return d.rows(ctx, queryKey("find", q), func() (driver.Rows, error) {
+		return finder.Find(ctx, query)
+	})
+}
+
+func (d *db) CreateIndex(ctx context.Context, ddoc, name string, index interface{}) error {
+	if finder, ok := d.DB.(driver.Finder); ok {
+		return finder.CreateIndex(ctx, ddoc, name, index)
+	}
+	return notSupported("CreateIndex")
+}
+
+func (d *db) GetIndexes(ctx context.Context) ([]driver.Index, error) {
+	if finder, ok := d.DB.(driver.Finder); ok {
+		return finder.GetIndexes(ctx)
+	}
+	return nil, notSupported("GetIndexes")
+}
+
+func (d *db) DeleteIndex(ctx context.Context, ddoc, name string) error {
+	if finder, ok := d.DB.(driver.Finder); ok {
+		return finder.DeleteIndex(ctx, ddoc, name)
+	}
+	return notSupported("DeleteIndex")
+}
+
+func (d *db) Explain(ctx context.Context, query interface{}) (*driver.QueryPlan, error) {
+	if finder, ok := d.DB.(driver.Finder); ok {
+		return finder.Explain(ctx, query)
+	}
+	return nil, notSupported("Explain")
+}
+
+func (d *db) RevsDiff(ctx context.Context, revMap interface{}) (driver.Rows, error) {
+	if rd, ok := d.DB.(driver.RevsDiffer); ok {
+		return rd.RevsDiff(ctx, revMap)
+	}
+	return nil, notSupported("RevsDiff")
+}
+
+func (d *db) OpenRevs(ctx context.Context, docID string, revs []string, opts map[string]interface{}) (driver.Rows, error) {
+	if openRever, ok := d.DB.(driver.OpenRever); ok {
+		return openRever.OpenRevs(ctx, docID, revs, opts)
+	}
+	return nil, notSupported("OpenRevs")
+}
+
+func (d *db) Close(ctx context.Context) error {
+	if closer, ok := d.DB.(driver.DBCloser); ok {
+		return closer.Close(ctx)
+	}
+	return nil
+}
+
+// rowsIter is a driver iterator over a cached result set.
+type rowsIter struct {
+	*cachedRows
+	i int
+}
+
+var (
+	_ driver.Rows       = &rowsIter{}
+	_ driver.RowsWarner = &rowsIter{}
+	_ driver.Bookmarker = &rowsIter{}
+)
+
+func (r *rowsIter) Next(row *driver.Row) error {
+	if r.i >= len(r.Rows) {
+		return io.EOF
+	}
+	cached := r.Rows[r.i]
+	r.i++
+	*row = driver.Row{ID: cached.ID, Key: cached.Key, Value: cached.Value, Doc: cached.Doc, Error: cached.err}
+	return nil
+}
+
+func (r *rowsIter) Close() error {
+	r.i = len(r.Rows)
+	return nil
+}
+
+func (r *rowsIter) Offset() int64     { return r.cachedRows.Offset }
+func (r *rowsIter) TotalRows() int64  { return r.cachedRows.TotalRows }
+func (r *rowsIter) UpdateSeq() string { return r.cachedRows.UpdateSeq }
+func (r *rowsIter) Warning() string   { return r.cachedRows.Warning }
+func (r *rowsIter) Bookmark() string  { return r.cachedRows.Bookmark }
@@ -0,0 +1,98 @@
+package cache
+
+import (
+	"container/list"
+	"context"
+	"sync"
+)
+
+// Store is a key/value store for cached results, such as LRU, or an
+// adapter for an external store such as Redis. Implementations must be safe
+// for concurrent use. Keys are unique to the client which stored them, as
+// only that client's changes feed invalidates them, so a Store may be
+// shared between clients, or processes, without one serving another's
+// stale results.
+type Store interface {
+	// Get returns the value stored for key, and whether it was found.
+	Get(ctx context.Context, key string) (value []byte, ok bool, err error)
+	// Set stores value for key. A Store may evict entries at any time.
+	Set(ctx context.Context, key string, value []byte) error
+	// Delete removes key from the store, if present.
+	Delete(ctx context.Context, key string) error
+}
+
+// LRU is an in-memory Store which evicts the least-recently used entry when
+// full.
+type LRU struct {
+	maxEntries int
+
+	mu      sync.Mutex
+	order   *list.List
+	entries map[string]*list.Element
+}
+
+type lruEntry struct {
+	key   string
+	value []byte
+}
+
+var _ Store = &LRU{}
+
+// NewLRU returns an empty LRU store, which holds at most maxEntries
+// entries. Zero means no limit.
+func NewLRU(maxEntries int) *LRU {
+	return &LRU{
+		maxEntries: maxEntries,
+		order:      list.New(),
+		entries:    make(map[string]*list.Element),
+	}
+}
+
+// Get returns the value stored for key, and marks it as recently used.
+func (s *LRU) Get(_ context.Context, key string) ([]byte, bool, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	elem, ok := s.entries[key]
+	if !ok {
+		return nil, false, nil
+	}
+	s.order.MoveToFront(elem)
+	return elem.Value.(*lruEntry).value, true, nil
+}
+
+// Set stores value for key, evicting the least-recently used entry if the
+// store is full.
+func (s *LRU) Set(_ context.Context, key string, value []byte) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if elem, ok := s.entries[key]; ok {
+		elem.Value.(*lruEntry).value = value
+		s.order.MoveToFront(elem)
+		return nil
+	}
+	s.entries[key] = s.order.PushFront(&lruEntry{key: key, value: value})
+	if s.maxEntries > 0 && s.order.Len() > s.maxEntries {
+		oldest := s.order.Back()
+		s.order.Remove(oldest)
+		delete(s.entries, oldest.Value.(*lruEntry).key)
+	}
+	return nil
+}
+
+// Delete removes key from the store.
+func (s *LRU) Delete(_ context.Context, key string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if elem, ok := s.entries[key]; ok {
+		s.order.Remove(elem)
+		delete(s.entries, key)
+	}
+	return nil
+}
+
+// Len returns the number of entries in the store.
+func (s *LRU) Len() int {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.order.Len()
+}
@@ -11,6 +11,13 @@ type Changes interface {
 	Next(*Change) error
 	// Close closes the rows iterator.
 	Close() error
+	// LastSeq returns the last update sequence id present in the change set,
+	// if returned by the server. It need only be valid after Next has
+	// returned io.EOF.
+	LastSeq() string
+	// Pending returns the count of remaining items in the change feed. It
+	// need only be valid after Next has returned io.EOF.
+	Pending() int64
 }
 
 // Change represents the changes to a single document.
@@ -0,0 +1,25 @@
+package driver
+
+import "context"
+
+// ClusterMembership contains the list of known nodes, and cluster nodes, as
+// returned by the /_membership endpoint.
+// See http://docs.couchdb.org/en/2.2.0/api/server/common.html#get--_membership
+type ClusterMembership struct {
+	AllNodes     []string `json:"all_nodes"`
+	ClusterNodes []string `json:"cluster_nodes"`
+}
+
+// Cluster is an optional interface that may be implemented by a Client for
+// cluster management, as supported by CouchDB 2.x and later.
+type Cluster interface {
+	// ClusterStatus returns the current cluster status, such as
+	// "cluster_finished" or "single_node_disabled".
+	ClusterStatus(ctx context.Context, options map[string]interface{}) (string, error)
+	// ClusterSetup performs the action specified by action, which should be
+	// marshaled to JSON and sent as the body of a /_cluster_setup request.
+	ClusterSetup(ctx context.Context, action interface{}) error
+	// Membership returns a list of all known nodes, and all nodes configured
+	// as part of the cluster.
+	Membership(ctx context.Context) (*ClusterMembership, error)
+}
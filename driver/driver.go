@@ -14,6 +14,15 @@ type Driver interface {
 	NewClient(ctx context.Context, name string) (Client, error)
 }
 
+// OptionsDriver is an optional interface that may be implemented by a Driver
+// which accepts client configuration beyond what can be expressed in a data
+// source name, such as a custom *http.Client.
+type OptionsDriver interface {
+	// NewClientWithOptions returns a connection handle to the database,
+	// configured with options. Unrecognized options should be rejected.
+	NewClientWithOptions(ctx context.Context, name string, options map[string]interface{}) (Client, error)
+}
+
 // Version represents a server version response.
 type Version struct {
 	// Version is the version number reported by the server or backend.
@@ -93,16 +102,83 @@ type Authenticator interface {
 	Authenticate(ctx context.Context, authenticator interface{}) error
 }
 
+// ClientCloser is an optional interface that may be implemented by a Client
+// to clean up resources, such as connection pools or open file handles, when
+// a Client is no longer needed.
+type ClientCloser interface {
+	Close(ctx context.Context) error
+}
+
+// Pinger is an optional interface that may be implemented by a Client. When
+// not implemented, Kivik will call Version instead, to emulate the
+// functionality.
+type Pinger interface {
+	// Ping returns true if the database is online and available for requests.
+	// CouchDB 2.0 and later provide the /_up endpoint for this purpose. Older
+	// servers may be checked with a HEAD request to the server root.
+	Ping(ctx context.Context) (bool, error)
+}
+
 // DBStats contains database statistics..
 type DBStats struct {
-	Name           string `json:"db_name"`
-	CompactRunning bool   `json:"compact_running"`
-	DocCount       int64  `json:"doc_count"`
-	DeletedCount   int64  `json:"doc_del_count"`
-	UpdateSeq      string `json:"update_seq"`
-	DiskSize       int64  `json:"disk_size"`
-	ActiveSize     int64  `json:"data_size"`
-	ExternalSize   int64  `json:"-"`
+	Name           string         `json:"db_name"`
+	CompactRunning bool           `json:"compact_running"`
+	DocCount       int64          `json:"doc_count"`
+	DeletedCount   int64          `json:"doc_del_count"`
+	UpdateSeq      SequenceID     `json:"update_seq"`
+	PurgeSeq       SequenceID     `json:"purge_seq"`
+	DiskSize       int64          `json:"disk_size"`
+	ActiveSize     int64          `json:"data_size"`
+	ExternalSize   int64          `json:"-"`
+	Cluster        *ClusterConfig `json:"cluster,omitempty"`
+	Partitioned    bool           `json:"-"`
+}
+
+// ClusterConfig contains the sharding and quorum configuration of a
+// clustered database.
+type ClusterConfig struct {
+	Replicas    int `json:"n"`
+	Shards      int `json:"q"`
+	ReadQuorum  int `json:"r"`
+	WriteQuorum int `json:"w"`
+}
+
+// UnmarshalJSON satisfies the json.Unmarshaler interface. It reads both the
+// CouchDB 1.x disk_size and data_size fields, and the sizes and props objects
+// reported by CouchDB 2.x and later, which take precedence.
+func (s *DBStats) UnmarshalJSON(data []byte) error {
+	type dbStats DBStats
+	var stats struct {
+		dbStats
+		Sizes *struct {
+			File     int64 `json:"file"`
+			External int64 `json:"external"`
+			Active   int64 `json:"active"`
+		} `json:"sizes"`
+		Props struct {
+			Partitioned bool `json:"partitioned"`
+		} `json:"props"`
+	}
+	if err := json.Unmarshal(data, &stats); err != nil {
+		return err
+	}
+	*s = DBStats(stats.dbStats)
+	if sizes := stats.Sizes; sizes != nil {
+		s.DiskSize = sizes.File
+		s.ActiveSize = sizes.Active
+		s.ExternalSize = sizes.External
+	}
+	s.Partitioned = stats.Props.Partitioned
+	return nil
+}
+
+// DBsStatser is an optional interface that may be implemented by a Client to
+// return the statistics of many databases in a single request, as with the
+// CouchDB 2.2+ /_dbs_info endpoint.
+type DBsStatser interface {
+	// DBsStats returns the statistics of each named database, in the order
+	// requested, with a nil entry for any database which does not exist.
+	DBsStats(ctx context.Context, dbnames []string) ([]*DBStats, error)
 }
 
 // Members represents the members of a database security document.
@@ -192,6 +268,13 @@ type Attachments interface {
 	Close() error
 }
 
+// DBCloser is an optional interface that may be implemented by a DB to clean
+// up resources, such as open file handles or goroutines serving continuous
+// feeds, when a DB is no longer needed.
+type DBCloser interface {
+	Close(ctx context.Context) error
+}
+
 // BulkDocer is an optional interface which may be implemented by a DB to
 // support bulk insert/update operations. For any driver that does not support
 // the BulkDocer interface, the Put or CreateDoc methods will be called for each
@@ -203,6 +286,38 @@ type BulkDocer interface {
 	BulkDocs(ctx context.Context, docs []interface{}, options map[string]interface{}) (BulkResults, error)
 }
 
+// DesignDocer is an optional interface that may be implemented by a DB.
+type DesignDocer interface {
+	// DesignDocs returns all of the design documents in the database, subject
+	// to the options provided.
+	DesignDocs(ctx context.Context, options map[string]interface{}) (Rows, error)
+}
+
+// LocalDocer is an optional interface that may be implemented by a DB.
+type LocalDocer interface {
+	// LocalDocs returns all of the local documents in the database, subject
+	// to the options provided.
+	LocalDocs(ctx context.Context, options map[string]interface{}) (Rows, error)
+}
+
+// BulkGetReference is a reference to a document given in a BulkGet query.
+type BulkGetReference struct {
+	ID        string   `json:"id"`
+	Rev       string   `json:"rev,omitempty"`
+	AttsSince []string `json:"atts_since,omitempty"`
+}
+
+// BulkGetter is an optional interface which may be implemented by a DB to
+// support fetching multiple documents in a single request. For any driver
+// that does not support the BulkGetter interface, the Get method will be
+// called for each document to emulate the same functionality.
+type BulkGetter interface {
+	// BulkGet uses the _bulk_get interface to fetch multiple documents in a
+	// single request. The returned Rows should have ID and Doc populated for
+	// each document fetched, or ID and Error for each failure.
+	BulkGet(ctx context.Context, docs []BulkGetReference, options map[string]interface{}) (Rows, error)
+}
+
 // Finder is an optional interface which may be implemented by a DB. The Finder
 // interface provides access to the new (in CouchDB 2.0) MongoDB-style query
 // interface.
@@ -239,6 +354,10 @@ type QueryPlan struct {
 	// an empty list if all fields are to be returned.
 	Fields []interface{}          `json:"fields"`
 	Range  map[string]interface{} `json:"range"`
+
+	// MRArgs are the map/reduce arguments used to query the selected index,
+	// as returned by CouchDB 2.1 and later.
+	MRArgs map[string]interface{} `json:"mrargs"`
 }
 
 // Index is a MonboDB-style index definition.
@@ -262,6 +381,17 @@ type Attachment struct {
 	Digest          string        `json:"digest"`
 }
 
+// MultipartPutter is an optional interface which may be satisfied by a DB. If
+// satisfied, it is used to store a document and its attachments in a single
+// multipart/related request. If not satisfied, the attachments are inlined
+// in the document, base64-encoded, and Put is used instead.
+type MultipartPutter interface {
+	// PutWithAttachments stores doc, along with the provided attachments,
+	// and returns the new revision. Any attachment stubs already present in
+	// doc should be preserved.
+	PutWithAttachments(ctx context.Context, docID string, doc interface{}, atts []*Attachment) (rev string, err error)
+}
+
 // AttachmentMetaGetter is an optional interface which may be satisfied by a
 // DB. If satisfied, it may be used to fetch meta data about an attachment. If
 // not satisfied, GetAttachment will be used instead.
@@ -297,6 +427,28 @@ type MetaGetter interface {
 	GetMeta(ctx context.Context, docID string, options map[string]interface{}) (size int64, rev string, err error)
 }
 
+// DocumentMetaGetter is an optional interface that may be implemented by a DB,
+// to report the deleted status of a document, as well as its size and
+// revision. If implemented, it is used in preference to MetaGetter.
+type DocumentMetaGetter interface {
+	// GetDocumentMeta returns the size, revision and deleted status of the
+	// requested document. It should accept the same options as the Get
+	// method.
+	GetDocumentMeta(ctx context.Context, docID string, options map[string]interface{}) (*DocumentMeta, error)
+}
+
+// DocumentMeta is the metadata of a document, as returned by
+// GetDocumentMeta.
+type DocumentMeta struct {
+	// Size is the size of the document, in bytes.
+	Size int64
+	// Rev is the revision of the document.
+	Rev string
+	// Deleted is true if the revision is a deletion, which may be the case
+	// when a specific revision is requested with the rev option.
+	Deleted bool
+}
+
 // Flusher is an optional interface that may be implemented by a DB that can
 // force a flush of the database backend file(s) to disk or other permanent
 // storage.
@@ -316,3 +468,39 @@ type Flusher interface {
 type Copier interface {
 	Copy(ctx context.Context, targetID, sourceID string, options map[string]interface{}) (targetRev string, err error)
 }
+
+// RevsDiffer is an optional interface that may be implemented by a DB.
+type RevsDiffer interface {
+	// RevsDiff returns a Rows iterator, which should populate the ID and Value
+	// fields, and nothing else. The Value field should contain a JSON object
+	// with the "missing" and, optionally, "possible_ancestors" keys, as
+	// returned by the CouchDB _revs_diff endpoint. revMap should be a map of
+	// document IDs to lists of revisions, or any value which marshals to
+	// such a JSON object.
+	RevsDiff(ctx context.Context, revMap interface{}) (Rows, error)
+}
+
+// OpenRever is an optional interface that may be implemented by a DB, to
+// fetch multiple leaf revisions of a single document.
+type OpenRever interface {
+	// OpenRevs returns a Rows iterator, with one row per requested leaf
+	// revision. Each row should have the ID and Doc fields populated, or, for
+	// a missing revision, the ID and Error fields. If revs is empty, all leaf
+	// revisions should be returned, as with open_revs=all, including
+	// conflicts and deleted leaves.
+	OpenRevs(ctx context.Context, docID string, revs []string, options map[string]interface{}) (Rows, error)
+}
+
+// PurgeResult is the result of a purge request.
+type PurgeResult struct {
+	Seq    int64               `json:"purge_seq"`
+	Purged map[string][]string `json:"purged"`
+}
+
+// Purger is an optional interface which may be implemented by a DB to support
+// document purging.
+type Purger interface {
+	// Purge permanently removes the references to deleted documents from the
+	// database.
+	Purge(ctx context.Context, docRevMap map[string][]string) (*PurgeResult, error)
+}
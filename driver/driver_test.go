@@ -0,0 +1,77 @@
+package driver
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/flimzy/diff"
+	"github.com/flimzy/testy"
+)
+
+func TestDBStatsUnmarshal(t *testing.T) {
+	tests := []struct {
+		name  string
+		input string
+
+		expected *DBStats
+		err      string
+	}{
+		{
+			name:  "Couch 1.6",
+			input: `{"db_name":"foo","doc_count":1,"update_seq":12,"purge_seq":0,"disk_size":100,"data_size":50}`,
+			expected: &DBStats{
+				Name:       "foo",
+				DocCount:   1,
+				UpdateSeq:  "12",
+				PurgeSeq:   "0",
+				DiskSize:   100,
+				ActiveSize: 50,
+			},
+		},
+		{
+			name: "Couch 3.x",
+			input: `{"db_name":"foo","update_seq":"12-g1AAAA","purge_seq":"0-g1AAAA",
+				"sizes":{"file":200,"external":80,"active":120},"disk_size":100,"data_size":50,
+				"cluster":{"q":2,"n":3,"w":2,"r":2},"props":{"partitioned":true}}`,
+			expected: &DBStats{
+				Name:         "foo",
+				UpdateSeq:    "12-g1AAAA",
+				PurgeSeq:     "0-g1AAAA",
+				DiskSize:     200,
+				ActiveSize:   120,
+				ExternalSize: 80,
+				Cluster:      &ClusterConfig{Replicas: 3, Shards: 2, ReadQuorum: 2, WriteQuorum: 2},
+				Partitioned:  true,
+			},
+		},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			stats := &DBStats{}
+			err := json.Unmarshal([]byte(test.input), stats)
+			testy.Error(t, test.err, err)
+			if d := diff.Interface(test.expected, stats); d != nil {
+				t.Error(d)
+			}
+		})
+	}
+}
+
+func TestPartitionStatsUnmarshal(t *testing.T) {
+	input := `{"db_name":"foo","doc_count":3,"doc_del_count":1,"partition":"bar","sizes":{"active":120,"external":80}}`
+	stats := &PartitionStats{}
+	if err := json.Unmarshal([]byte(input), stats); err != nil {
+		t.Fatal(err)
+	}
+	expected := &PartitionStats{
+		DBName:          "foo",
+		DocCount:        3,
+		DeletedDocCount: 1,
+		Partition:       "bar",
+		ActiveSize:      120,
+		ExternalSize:    80,
+	}
+	if d := diff.Interface(expected, stats); d != nil {
+		t.Error(d)
+	}
+}
@@ -0,0 +1,391 @@
+package encrypt
+
+import (
+	"bytes"
+	"context"
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/rand"
+	"encoding/binary"
+	"encoding/json"
+	"io"
+	"strings"
+
+	"github.com/go-kivik/kivik"
+	"github.com/go-kivik/kivik/errors"
+)
+
+const (
+	// kidField, fieldField and ciphertextField are the fields of an
+	// envelope, the JSON object which replaces an encrypted value.
+	kidField        = "$kid"
+	fieldField      = "$field"
+	ciphertextField = "$ciphertext"
+	// bodyField holds the envelope of the fields of a document encrypted
+	// together.
+	bodyField = "$encrypted"
+)
+
+// attachmentMagic begins encrypted attachment content, which continues with
+// the length of the key ID, the key ID, the nonce and the sealed content.
+var attachmentMagic = []byte("KVKE1")
+
+// Kinds of sealed value.
+const (
+	fieldKind      = "field"
+	attachmentKind = "attachment"
+)
+
+// binding identifies where a sealed value belongs: the document, and the
+// field or attachment within it. It is authenticated along with the value,
+// so that a value moved to another field, attachment or document fails to
+// decrypt.
+type binding struct {
+	kind  string
+	docID string
+	name  string
+}
+
+// aad returns the additional authenticated data for a value sealed with the
+// key identified by kid, at b.
+func (b binding) aad(kid string) []byte {
+	var buf []byte
+	for _, s := range []string{kid, b.kind, b.docID, b.name} {
+		var n [binary.MaxVarintLen64]byte
+		buf = append(buf, n[:binary.PutUvarint(n[:], uint64(len(s)))]...)
+		buf = append(buf, s...)
+	}
+	return buf
+}
+
+// crypter encrypts and decrypts values with keys from a KeyProvider. The ID
+// of the key, and the binding of the value, are authenticated along with
+// each value.
+type crypter struct {
+	keys        KeyProvider
+	fields      map[string]bool
+	attachments bool
+}
+
+func aead(key []byte) (cipher.AEAD, error) {
+	block, err := aes.NewCipher(key)
+	if err != nil {
+		return nil, errors.WrapStatus(kivik.StatusInternalServerError, err)
+	}
+	gcm, err := cipher.NewGCM(block)
+	if err != nil {
+		return nil, errors.WrapStatus(kivik.StatusInternalServerError, err)
+	}
+	return gcm, nil
+}
+
+// seal encrypts plaintext, bound to b, with the current key, returning the
+// key ID, and the nonce followed by the ciphertext.
+func (c *crypter) seal(ctx context.Context, b binding, plaintext []byte) (string, []byte, error) {
+	kid, key, err := c.keys.CurrentKey(ctx)
+	if err != nil {
+		return "", nil, err
+	}
+	if len(kid) > 255 {
+		return "", nil, errors.Statusf(kivik.StatusInternalServerError, "encrypt: key ID %q too long", kid)
+	}
+	gcm, err := aead(key)
+	if err != nil {
+		return "", nil, err
+	}
+	nonce := make([]byte, gcm.NonceSize())
+	if _, err := io.ReadFull(rand.Reader, nonce); err != nil {
+		return "", nil, errors.WrapStatus(kivik.StatusInternalServerError, err)
+	}
+	return kid, gcm.Seal(nonce, nonce, plaintext, b.aad(kid)), nil
+}
+
+// open decrypts sealed, the nonce followed by the ciphertext, bound to b,
+// with the key identified by kid.
+func (c *crypter) open(ctx context.Context, kid string, b binding, sealed []byte) ([]byte, error) {
+	key, err := c.keys.Key(ctx, kid)
+	if err != nil {
+		return nil, err
+	}
+	gcm, err := aead(key)
+	if err != nil {
+		return nil, err
+	}
+	if len(sealed) < gcm.NonceSize() {
+		return nil, errors.Status(kivik.StatusBadResponse, "encrypt: ciphertext too short")
+	}
+	plaintext, err := gcm.Open(nil, sealed[:gcm.NonceSize()], sealed[gcm.NonceSize():], b.aad(kid))
+	if err != nil {
+		return nil, errors.WrapStatus(kivik.StatusBadResponse, errors.Wrap(err, "encrypt: decryption failed"))
+	}
+	return plaintext, nil
+}
+
+// envelope replaces an encrypted value. Field records the name of the field
+// to which the value belongs, for values found outside their document, such
+// as those emitted by views.
+type envelope struct {
+	KID        string `json:"$kid"`
+	Field      string `json:"$field"`
+	Ciphertext []byte `json:"$ciphertext"`
+}
+
+// sealValue returns the envelope of value, the JSON value of field name of
+// document docID.
+func (c *crypter) sealValue(ctx context.Context, docID, name string, value []byte) (json.RawMessage, error) {
+	kid, sealed, err := c.seal(ctx, binding{kind: fieldKind, docID: docID, name: name}, value)
+	if err != nil {
+		return nil, err
+	}
+	return json.Marshal(envelope{KID: kid, Field: name, Ciphertext: sealed})
+}
+
+// encryptDoc returns the JSON encoding of doc, with the configured fields,
+// and inline attachment data, encrypted. Design documents are returned
+// unencrypted, as the backend must be able to read them.
+func (c *crypter) encryptDoc(ctx context.Context, docID string, doc interface{}) (json.RawMessage, error) {
+	fields, err := docFields(doc)
+	if err != nil {
+		return nil, err
+	}
+	if docID == "" {
+		_ = json.Unmarshal(fields["_id"], &docID)
+	}
+	if strings.HasPrefix(docID, "_design/") {
+		return json.Marshal(fields)
+	}
+	if len(c.fields) > 0 {
+		for name := range c.fields {
+			value, ok := fields[name]
+			if !ok {
+				continue
+			}
+			if fields[name], err = c.sealValue(ctx, docID, name, value); err != nil {
+				return nil, err
+			}
+		}
+	} else {
+		body := make(map[string]json.RawMessage)
+		for name, value := range fields {
+			if name[0] != '_' {
+				body[name] = value
+				delete(fields, name)
+			}
+		}
+		if len(body) > 0 {
+			data, _ := json.Marshal(body)
+			if fields[bodyField], err = c.sealValue(ctx, docID, bodyField, data); err != nil {
+				return nil, err
+			}
+		}
+	}
+	if c.attachments {
+		if err := c.mapAttachments(fields, func(filename string, content []byte) ([]byte, error) {
+			return c.encryptAttachment(ctx, docID, filename, content)
+		}); err != nil {
+			return nil, err
+		}
+	}
+	return json.Marshal(fields)
+}
+
+// docFields returns the top-level fields of doc, which must encode as a
+// JSON object.
+func docFields(doc interface{}) (map[string]json.RawMessage, error) {
+	var data []byte
+	switch t := doc.(type) {
+	case json.RawMessage:
+		data = t
+	case []byte:
+		data = t
+	case string:
+		data = []byte(t)
+	default:
+		var err error
+		if data, err = json.Marshal(doc); err != nil {
+			return nil, errors.WrapStatus(kivik.StatusBadRequest, err)
+		}
+	}
+	var fields map[string]json.RawMessage
+	if err := json.Unmarshal(data, &fields); err != nil || fields == nil {
+		return nil, errors.Status(kivik.StatusBadRequest, "encrypt: document must be a JSON object")
+	}
+	return fields, nil
+}
+
+// mapAttachments replaces the inline data of each attachment in the
+// _attachments field with the result of fn, called with the attachment's
+// filename and data.
+func (c *crypter) mapAttachments(fields map[string]json.RawMessage, fn func(string, []byte) ([]byte, error)) error {
+	raw, ok := fields["_attachments"]
+	if !ok {
+		return nil
+	}
+	var atts map[string]map[string]json.RawMessage
+	if err := json.Unmarshal(raw, &atts); err != nil {
+		return nil
+	}
+	for filename, att := range atts {
+		var data []byte
+		if json.Unmarshal(att["data"], &data) != nil || data == nil {
+			continue
+		}
+		content, err := fn(filename, data)
+		if err != nil {
+			return err
+		}
+		att["data"], _ = json.Marshal(content)
+		att["length"], _ = json.Marshal(len(content))
+		delete(att, "digest")
+	}
+	fields["_attachments"], _ = json.Marshal(atts)
+	return nil
+}
+
+// decryptDoc returns doc, a JSON document, with its encrypted fields, and
+// inline attachment data, decrypted. docID is the ID of the document, if it
+// has no _id field, such as when a query projected it away.
+func (c *crypter) decryptDoc(ctx context.Context, docID string, doc json.RawMessage) (json.RawMessage, error) {
+	if !bytes.Contains(doc, []byte(`"`+kidField+`"`)) && (!c.attachments || !bytes.Contains(doc, []byte(`"_attachments"`))) {
+		return doc, nil
+	}
+	var fields map[string]json.RawMessage
+	if err := json.Unmarshal(doc, &fields); err != nil {
+		return nil, errors.WrapStatus(kivik.StatusBadResponse, err)
+	}
+	if id, ok := fields["_id"]; ok {
+		_ = json.Unmarshal(id, &docID)
+	}
+	for name, field := range fields {
+		if !isEnvelope(field) {
+			continue
+		}
+		decrypted, err := c.openValue(ctx, binding{kind: fieldKind, docID: docID, name: name}, field)
+		if err != nil {
+			return nil, err
+		}
+		fields[name] = decrypted
+	}
+	if body, ok := fields[bodyField]; ok {
+		delete(fields, bodyField)
+		var bodyFields map[string]json.RawMessage
+		if err := json.Unmarshal(body, &bodyFields); err != nil {
+			return nil, errors.WrapStatus(kivik.StatusBadResponse, err)
+		}
+		for name, field := range bodyFields {
+			fields[name] = field
+		}
+	}
+	if c.attachments {
+		if err := c.mapAttachments(fields, func(filename string, content []byte) ([]byte, error) {
+			return c.decryptAttachment(ctx, docID, filename, content)
+		}); err != nil {
+			return nil, err
+		}
+	}
+	return json.Marshal(fields)
+}
+
+// isEnvelope returns true if value is the JSON object of an envelope.
+func isEnvelope(value json.RawMessage) bool {
+	if firstByte(value) != '{' || !bytes.Contains(value, []byte(`"`+kidField+`"`)) {
+		return false
+	}
+	var fields map[string]json.RawMessage
+	if json.Unmarshal(value, &fields) != nil {
+		return false
+	}
+	return len(fields) == 3 && fields[kidField] != nil && fields[fieldField] != nil && fields[ciphertextField] != nil
+}
+
+// decrypt returns value, a JSON value emitted by a view of document docID,
+// with any envelopes within it decrypted. As the value may have been moved
+// from its field, the field to which each envelope is bound is taken from
+// the envelope. Values which were not encrypted are returned unchanged.
+func (c *crypter) decrypt(ctx context.Context, docID string, value json.RawMessage) (json.RawMessage, error) {
+	if !bytes.Contains(value, []byte(`"`+kidField+`"`)) {
+		return value, nil
+	}
+	if isEnvelope(value) {
+		return c.openValue(ctx, binding{kind: fieldKind, docID: docID}, value)
+	}
+	switch firstByte(value) {
+	case '{':
+		var fields map[string]json.RawMessage
+		if err := json.Unmarshal(value, &fields); err != nil {
+			return nil, errors.WrapStatus(kivik.StatusBadResponse, err)
+		}
+		for name, field := range fields {
+			decrypted, err := c.decrypt(ctx, docID, field)
+			if err != nil {
+				return nil, err
+			}
+			fields[name] = decrypted
+		}
+		return json.Marshal(fields)
+	case '[':
+		var values []json.RawMessage
+		if err := json.Unmarshal(value, &values); err != nil {
+			return nil, errors.WrapStatus(kivik.StatusBadResponse, err)
+		}
+		for i, v := range values {
+			decrypted, err := c.decrypt(ctx, docID, v)
+			if err != nil {
+				return nil, err
+			}
+			values[i] = decrypted
+		}
+		return json.Marshal(values)
+	}
+	return value, nil
+}
+
+func firstByte(value []byte) byte {
+	value = bytes.TrimSpace(value)
+	if len(value) == 0 {
+		return 0
+	}
+	return value[0]
+}
+
+// openValue decrypts value, an envelope, bound to b. If b has no name, the
+// field named in the envelope is used.
+func (c *crypter) openValue(ctx context.Context, b binding, value json.RawMessage) (json.RawMessage, error) {
+	var env envelope
+	if err := json.Unmarshal(value, &env); err != nil {
+		return nil, errors.WrapStatus(kivik.StatusBadResponse, err)
+	}
+	if b.name == "" {
+		b.name = env.Field
+	}
+	return c.open(ctx, env.KID, b, env.Ciphertext)
+}
+
+// encryptAttachment returns the encrypted form of the content of attachment
+// filename of document docID.
+func (c *crypter) encryptAttachment(ctx context.Context, docID, filename string, content []byte) ([]byte, error) {
+	kid, sealed, err := c.seal(ctx, binding{kind: attachmentKind, docID: docID, name: filename}, content)
+	if err != nil {
+		return nil, err
+	}
+	buf := make([]byte, 0, len(attachmentMagic)+1+len(kid)+len(sealed))
+	buf = append(buf, attachmentMagic...)
+	buf = append(buf, byte(len(kid)))
+	buf = append(buf, kid...)
+	return append(buf, sealed...), nil
+}
+
+// decryptAttachment returns the plaintext of the content of attachment
+// filename of document docID. Content which was not encrypted is returned
+// unchanged.
+func (c *crypter) decryptAttachment(ctx context.Context, docID, filename string, content []byte) ([]byte, error) {
+	if !bytes.HasPrefix(content, attachmentMagic) {
+		return content, nil
+	}
+	rest := content[len(attachmentMagic):]
+	if len(rest) == 0 || len(rest) < 1+int(rest[0]) {
+		return nil, errors.Status(kivik.StatusBadResponse, "encrypt: malformed attachment")
+	}
+	kid := string(rest[1 : 1+rest[0]])
+	return c.open(ctx, kid, binding{kind: attachmentKind, docID: docID, name: filename}, rest[1+rest[0]:])
+}
@@ -0,0 +1,325 @@
+package encrypt
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"io/ioutil"
+
+	"github.com/go-kivik/kivik"
+	"github.com/go-kivik/kivik/driver"
+	"github.com/go-kivik/kivik/errors"
+)
+
+// db encrypts documents written to the embedded DB, and decrypts those read
+// from it.
+type db struct {
+	driver.DB
+	crypter *crypter
+}
+
+var (
+	_ driver.DB          = &db{}
+	_ driver.DBCloser    = &db{}
+	_ driver.Finder      = &db{}
+	_ driver.DesignDocer = &db{}
+	_ driver.LocalDocer  = &db{}
+	_ driver.RevsDiffer  = &db{}
+	_ driver.OpenRever   = &db{}
+	_ driver.Flusher     = &db{}
+	_ driver.Purger      = &db{}
+)
+
+func notSupported(method string) error {
+	return errors.Statusf(kivik.StatusNotImplemented, "encrypt: backend does not support %s", method)
+}
+
+func (d *db) Put(ctx context.Context, docID string, doc interface{}, opts map[string]interface{}) (string, error) {
+	body, err := d.crypter.encryptDoc(ctx, docID, doc)
+	if err != nil {
+		return "", err
+	}
+	return d.DB.Put(ctx, docID, body, opts)
+}
+
+// CreateDoc generates the document ID, if doc has none, as encrypted values
+// are bound to it.
+func (d *db) CreateDoc(ctx context.Context, doc interface{}, opts map[string]interface{}) (string, string, error) {
+	fields, err := docFields(doc)
+	if err != nil {
+		return "", "", err
+	}
+	var docID string
+	_ = json.Unmarshal(fields["_id"], &docID)
+	if docID == "" {
+		if docID, err = kivik.UUIDv4(); err != nil {
+			return "", "", err
+		}
+	}
+	rev, err := d.Put(ctx, docID, fields, opts)
+	return docID, rev, err
+}
+
+func (d *db) Get(ctx context.Context, docID string, opts map[string]interface{}) (*driver.Document, error) {
+	doc, err := d.DB.Get(ctx, docID, opts)
+	if err != nil {
+		return nil, err
+	}
+	defer doc.Body.Close() // nolint: errcheck
+	body, err := ioutil.ReadAll(doc.Body)
+	if err != nil {
+		return nil, errors.WrapStatus(kivik.StatusNetworkError, err)
+	}
+	if body, err = d.crypter.decryptDoc(ctx, docID, body); err != nil {
+		return nil, err
+	}
+	result := &driver.Document{
+		ContentLength: int64(len(body)),
+		Rev:           doc.Rev,
+		Body:          ioutil.NopCloser(bytes.NewReader(body)),
+	}
+	if doc.Attachments != nil {
+		result.Attachments = &attachments{Attachments: doc.Attachments, ctx: ctx, docID: docID, crypter: d.crypter}
+	}
+	return result, nil
+}
+
+func (d *db) PutAttachment(ctx context.Context, docID, rev string, att *driver.Attachment, opts map[string]interface{}) (string, error) {
+	if !d.crypter.attachments {
+		return d.DB.PutAttachment(ctx, docID, rev, att, opts)
+	}
+	content, err := ioutil.ReadAll(att.Content)
+	if err != nil {
+		return "", errors.WrapStatus(kivik.StatusBadRequest, err)
+	}
+	if content, err = d.crypter.encryptAttachment(ctx, docID, att.Filename, content); err != nil {
+		return "", err
+	}
+	encrypted := *att
+	encrypted.Content = ioutil.NopCloser(bytes.NewReader(content))
+	encrypted.Size = int64(len(content))
+	encrypted.Digest = ""
+	return d.DB.PutAttachment(ctx, docID, rev, &encrypted, opts)
+}
+
+func (d *db) GetAttachment(ctx context.Context, docID, rev, filename string, opts map[string]interface{}) (*driver.Attachment, error) {
+	att, err := d.DB.GetAttachment(ctx, docID, rev, filename, opts)
+	if err != nil {
+		return nil, err
+	}
+	if err := d.crypter.decryptContent(ctx, docID, filename, att); err != nil {
+		return nil, err
+	}
+	return att, nil
+}
+
+// decryptContent replaces the content of att, attachment filename of
+// document docID, with its plaintext, if attachments are encrypted.
+func (c *crypter) decryptContent(ctx context.Context, docID, filename string, att *driver.Attachment) error {
+	if !c.attachments || att.Content == nil {
+		return nil
+	}
+	defer att.Content.Close() // nolint: errcheck
+	content, err := ioutil.ReadAll(att.Content)
+	if err != nil {
+		return errors.WrapStatus(kivik.StatusNetworkError, err)
+	}
+	if content, err = c.decryptAttachment(ctx, docID, filename, content); err != nil {
+		return err
+	}
+	att.Content = ioutil.NopCloser(bytes.NewReader(content))
+	att.Size = int64(len(content))
+	att.Digest = ""
+	return nil
+}
+
+func (d *db) rows(ctx context.Context, rows driver.Rows, err error) (driver.Rows, error) {
+	if err != nil {
+		return nil, err
+	}
+	return &rowsIter{Rows: rows, ctx: ctx, crypter: d.crypter}, nil
+}
+
+func (d *db) AllDocs(ctx context.Context, opts map[string]interface{}) (driver.Rows, error) {
+	rows, err := d.DB.AllDocs(ctx, opts)
+	return d.rows(ctx, rows, err)
+}
+
+func (d *db) Query(ctx context.Context, ddoc, view string, opts map[string]interface{}) (driver.Rows, error) {
+	rows, err := d.DB.Query(ctx, ddoc, view, opts)
+	return d.rows(ctx, rows, err)
+}
+
+func (d *db) Changes(ctx context.Context, opts map[string]interface{}) (driver.Changes, error) {
+	changes, err := d.DB.Changes(ctx, opts)
+	if err != nil {
+		return nil, err
+	}
+	return &changesIter{Changes: changes, ctx: ctx, crypter: d.crypter}, nil
+}
+
+func (d *db) Find(ctx context.Context, query interface{}) (driver.Rows, error) {
+	finder, ok := d.DB.(driver.Finder)
+	if !ok {
+		return nil, notSupported("Find")
+	}
+	rows, err := finder.Find(ctx, query)
+	return d.rows(ctx, rows, err)
+}
+
+func (d *db) CreateIndex(ctx context.Context, ddoc, name string, index interface{}) error {
+	if finder, ok := d.DB.(driver.Finder); ok {
+		return finder.CreateIndex(ctx, ddoc, name, index)
+	}
+	return notSupported("CreateIndex")
+}
+
+func (d *db) GetIndexes(ctx context.Context) ([]driver.Index, error) {
+	if finder, ok := d.DB.(driver.Finder); ok {
+		return finder.GetIndexes(ctx)
+	}
+	return nil, notSupported("GetIndexes")
+}
+
+func (d *db) DeleteIndex(ctx context.Context, ddoc, name string) error {
+	if finder, ok := d.DB.(driver.Finder); ok {
+		return finder.DeleteIndex(ctx, ddoc, name)
+	}
+	return notSupported("DeleteIndex")
+}
+
+func (d *db) Explain(ctx context.Context, query interface{}) (*driver.QueryPlan, error) {
+	if finder, ok := d.DB.(driver.Finder); ok {
+		return finder.Explain(ctx, query)
+	}
+	return nil, notSupported("Explain")
+}
+
+func (d *db) DesignDocs(ctx context.Context, opts map[string]interface{}) (driver.Rows, error) {
+	if designDocer, ok := d.DB.(driver.DesignDocer); ok {
+		return designDocer.DesignDocs(ctx, opts)
+	}
+	return nil, notSupported("DesignDocs")
+}
+
+func (d *db) LocalDocs(ctx context.Context, opts map[string]interface{}) (driver.Rows, error) {
+	localDocer, ok := d.DB.(driver.LocalDocer)
+	if !ok {
+		return nil, notSupported("LocalDocs")
+	}
+	rows, err := localDocer.LocalDocs(ctx, opts)
+	return d.rows(ctx, rows, err)
+}
+
+func (d *db) RevsDiff(ctx context.Context, revMap interface{}) (driver.Rows, error) {
+	if rd, ok := d.DB.(driver.RevsDiffer); ok {
+		return rd.RevsDiff(ctx, revMap)
+	}
+	return nil, notSupported("RevsDiff")
+}
+
+func (d *db) OpenRevs(ctx context.Context, docID string, revs []string, opts map[string]interface{}) (driver.Rows, error) {
+	openRever, ok := d.DB.(driver.OpenRever)
+	if !ok {
+		return nil, notSupported("OpenRevs")
+	}
+	rows, err := openRever.OpenRevs(ctx, docID, revs, opts)
+	return d.rows(ctx, rows, err)
+}
+
+func (d *db) Flush(ctx context.Context) error {
+	if flusher, ok := d.DB.(driver.Flusher); ok {
+		return flusher.Flush(ctx)
+	}
+	return notSupported("Flush")
+}
+
+func (d *db) Purge(ctx context.Context, docMap map[string][]string) (*driver.PurgeResult, error) {
+	if purger, ok := d.DB.(driver.Purger); ok {
+		return purger.Purge(ctx, docMap)
+	}
+	return nil, notSupported("Purge")
+}
+
+func (d *db) Close(ctx context.Context) error {
+	if closer, ok := d.DB.(driver.DBCloser); ok {
+		return closer.Close(ctx)
+	}
+	return nil
+}
+
+// rowsIter decrypts the values and documents of the embedded Rows.
+type rowsIter struct {
+	driver.Rows
+	ctx     context.Context
+	crypter *crypter
+}
+
+var (
+	_ driver.Rows       = &rowsIter{}
+	_ driver.RowsWarner = &rowsIter{}
+	_ driver.Bookmarker = &rowsIter{}
+)
+
+func (r *rowsIter) Next(row *driver.Row) error {
+	if err := r.Rows.Next(row); err != nil {
+		return err
+	}
+	var err error
+	if row.Value, err = r.crypter.decrypt(r.ctx, row.ID, row.Value); err != nil {
+		return err
+	}
+	if row.Doc != nil {
+		row.Doc, err = r.crypter.decryptDoc(r.ctx, row.ID, row.Doc)
+	}
+	return err
+}
+
+func (r *rowsIter) Warning() string {
+	if w, ok := r.Rows.(driver.RowsWarner); ok {
+		return w.Warning()
+	}
+	return ""
+}
+
+func (r *rowsIter) Bookmark() string {
+	if b, ok := r.Rows.(driver.Bookmarker); ok {
+		return b.Bookmark()
+	}
+	return ""
+}
+
+// changesIter decrypts the documents of the embedded Changes.
+type changesIter struct {
+	driver.Changes
+	ctx     context.Context
+	crypter *crypter
+}
+
+func (c *changesIter) Next(change *driver.Change) error {
+	if err := c.Changes.Next(change); err != nil {
+		return err
+	}
+	if change.Doc == nil {
+		return nil
+	}
+	var err error
+	change.Doc, err = c.crypter.decryptDoc(c.ctx, change.ID, change.Doc)
+	return err
+}
+
+// attachments decrypts the content of the embedded Attachments, of document
+// docID.
+type attachments struct {
+	driver.Attachments
+	ctx     context.Context
+	docID   string
+	crypter *crypter
+}
+
+func (a *attachments) Next(att *driver.Attachment) error {
+	if err := a.Attachments.Next(att); err != nil {
+		return err
+	}
+	return a.crypter.decryptContent(a.ctx, a.docID, att.Filename, att)
+}
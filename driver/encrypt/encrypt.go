@@ -0,0 +1,159 @@
+// Package encrypt provides a kivik driver which encrypts documents and
+// attachments before they reach another kivik Client, and decrypts them as
+// they are read, so that the backend never sees the plaintext:
+//
+//	client, err := encrypt.New(backend, &encrypt.Config{
+//		Keys:   &encrypt.KeyRing{Current: "2024", Keys: keys},
+//		Fields: []string{"ssn", "notes"},
+//	})
+//
+// Either the configured top-level fields of each document are encrypted, or,
+// if none are configured, all fields together, except those which begin
+// with an underscore, such as _id and _rev, which the backend requires.
+// Values are encrypted with AES-GCM, and tagged with the ID of the key used,
+// so that keys may be rotated: new writes use the current key, while reads
+// use whichever key a value was encrypted with. A document is re-encrypted
+// with the current key when it is next written. Each value is bound to its
+// document ID, and to the name of its field or attachment, so that a value
+// moved elsewhere in the backend fails to decrypt.
+//
+// Encrypted values are decrypted wherever they appear in results, including
+// documents returned by AllDocs, Query, Find and Changes, and values emitted
+// by views. Values written before encryption was enabled are returned as
+// they are. Selectors, view keys and indexes cannot refer to the content of
+// encrypted fields.
+package encrypt
+
+import (
+	"context"
+
+	"github.com/go-kivik/kivik"
+	"github.com/go-kivik/kivik/driver"
+	"github.com/go-kivik/kivik/driver/proxy"
+	"github.com/go-kivik/kivik/errors"
+	"github.com/go-kivik/kivik/internal/registry"
+)
+
+// DriverName is the name under which the encrypting driver is registered.
+const DriverName = "encrypt"
+
+// KeyProvider provides the AES keys, of 16, 24 or 32 bytes, with which
+// values are encrypted. It must be safe for concurrent use.
+type KeyProvider interface {
+	// CurrentKey returns the ID and value of the key with which to encrypt
+	// new values.
+	CurrentKey(ctx context.Context) (id string, key []byte, err error)
+	// Key returns the value of the key with the given ID, with which
+	// existing values were encrypted.
+	Key(ctx context.Context, id string) ([]byte, error)
+}
+
+// KeyRing is a KeyProvider holding a fixed set of keys, which must not be
+// modified while in use. To rotate keys, create a client with a KeyRing in
+// which the new key is current, keeping the old keys for as long as any
+// value remains encrypted with them.
+type KeyRing struct {
+	// Current is the ID of the key with which to encrypt new values.
+	Current string
+	// Keys maps key IDs to key values.
+	Keys map[string][]byte
+}
+
+var _ KeyProvider = &KeyRing{}
+
+// CurrentKey returns the current key.
+func (r *KeyRing) CurrentKey(ctx context.Context) (string, []byte, error) {
+	key, err := r.Key(ctx, r.Current)
+	return r.Current, key, err
+}
+
+// Key returns the key with the given ID.
+func (r *KeyRing) Key(_ context.Context, id string) ([]byte, error) {
+	key, ok := r.Keys[id]
+	if !ok {
+		return nil, errors.Statusf(kivik.StatusInternalServerError, "encrypt: unknown key %q", id)
+	}
+	return key, nil
+}
+
+// Config configures the encrypting driver.
+type Config struct {
+	// Keys provides the encryption keys. It is required.
+	Keys KeyProvider
+	// Fields lists the top-level fields to encrypt. If empty, all fields
+	// are encrypted together, except those which begin with an underscore.
+	Fields []string
+	// Attachments, if true, causes attachment content to be encrypted.
+	Attachments bool
+}
+
+func (c *Config) validate() error {
+	if c == nil || c.Keys == nil {
+		return errors.Status(kivik.StatusBadRequest, "encrypt: key provider required")
+	}
+	for _, field := range c.Fields {
+		if field == "" || field[0] == '_' {
+			return errors.Statusf(kivik.StatusBadRequest, "encrypt: field %q may not be encrypted", field)
+		}
+	}
+	return nil
+}
+
+type config struct {
+	backend *kivik.Client
+	config  *Config
+}
+
+var configs = registry.New(DriverName, func(v interface{}) (driver.Client, error) {
+	cfg := v.(*config)
+	return NewClient(proxy.NewClient(cfg.backend), cfg.config)
+})
+
+func init() {
+	kivik.Register(DriverName, configs.Driver())
+}
+
+// New returns a kivik client which encrypts data written to backend, and
+// decrypts data read from it, according to cfg.
+func New(backend *kivik.Client, cfg *Config) (*kivik.Client, error) {
+	if backend == nil {
+		return nil, errors.Status(kivik.StatusBadRequest, "encrypt: backend required")
+	}
+	if err := cfg.validate(); err != nil {
+		return nil, err
+	}
+	return configs.NewClient(context.Background(), &config{backend: backend, config: cfg})
+}
+
+// NewClient wraps c, a driver client such as returned by proxy.NewClient,
+// encrypting and decrypting according to cfg.
+func NewClient(c driver.Client, cfg *Config) (driver.Client, error) {
+	if err := cfg.validate(); err != nil {
+		return nil, err
+	}
+	fields := make(map[string]bool, len(cfg.Fields))
+	for _, field := range cfg.Fields {
+		fields[field] = true
+	}
+	return &client{
+		Client: c,
+		crypter: &crypter{
+			keys:        cfg.Keys,
+			fields:      fields,
+			attachments: cfg.Attachments,
+		},
+	}, nil
+}
+
+type client struct {
+	driver.Client
+	crypter *crypter
+}
+
+func (c *client) DB(ctx context.Context, dbName string, opts map[string]interface{}) (driver.DB, error) {
+	inner, err := c.Client.DB(ctx, dbName, opts)
+	if err != nil {
+		return nil, err
+	}
+	return &db{DB: inner, crypter: c.crypter}, nil
+}
@@ -0,0 +1,286 @@
+package encrypt
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"io/ioutil"
+	"os"
+	"strings"
+	"testing"
+
+	"github.com/flimzy/diff"
+	"github.com/go-kivik/kivik"
+	_ "github.com/go-kivik/kivik/driver/fs"
+)
+
+type fixture struct {
+	backend *kivik.DB
+	keys    *KeyRing
+	cleanup func()
+}
+
+// newFixture returns an empty fs database, and a key ring whose current key
+// is "old".
+func newFixture(t *testing.T) *fixture {
+	dir, err := ioutil.TempDir("", "kivik-encrypt-")
+	if err != nil {
+		t.Fatal(err)
+	}
+	f := &fixture{
+		keys: &KeyRing{Current: "old", Keys: map[string][]byte{
+			"old": bytes.Repeat([]byte{1}, 32),
+			"new": bytes.Repeat([]byte{2}, 16),
+		}},
+		cleanup: func() { _ = os.RemoveAll(dir) },
+	}
+	ctx := context.Background()
+	backend, err := kivik.New(ctx, "fs", dir)
+	if err != nil {
+		f.cleanup()
+		t.Fatal(err)
+	}
+	if f.backend, err = backend.CreateDB(ctx, "test"); err != nil {
+		f.cleanup()
+		t.Fatal(err)
+	}
+	return f
+}
+
+func (f *fixture) db(t *testing.T, cfg *Config) *kivik.DB {
+	cfg.Keys = f.keys
+	client, err := New(f.backend.Client(), cfg)
+	if err != nil {
+		t.Fatal(err)
+	}
+	db, err := client.DB(context.Background(), "test")
+	if err != nil {
+		t.Fatal(err)
+	}
+	return db
+}
+
+func raw(t *testing.T, db *kivik.DB, docID string) map[string]interface{} {
+	var doc map[string]interface{}
+	if err := db.Get(context.Background(), docID).ScanDoc(&doc); err != nil {
+		t.Fatal(err)
+	}
+	delete(doc, "_rev")
+	return doc
+}
+
+func TestNew(t *testing.T) {
+	backend, err := kivik.New(context.Background(), "fs", os.TempDir())
+	if err != nil {
+		t.Fatal(err)
+	}
+	tests := []struct {
+		name    string
+		backend *kivik.Client
+		cfg     *Config
+	}{
+		{name: "no backend", cfg: &Config{Keys: &KeyRing{}}},
+		{name: "no config", backend: backend},
+		{name: "no keys", backend: backend, cfg: &Config{}},
+		{name: "underscore field", backend: backend, cfg: &Config{Keys: &KeyRing{}, Fields: []string{"_id"}}},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			if _, err := New(test.backend, test.cfg); kivik.StatusCode(err) != kivik.StatusBadRequest {
+				t.Errorf("Unexpected error: %v", err)
+			}
+		})
+	}
+}
+
+func TestFields(t *testing.T) {
+	f := newFixture(t)
+	defer f.cleanup()
+	ctx := context.Background()
+	db := f.db(t, &Config{Fields: []string{"ssn"}})
+	if _, err := db.Put(ctx, "foo", map[string]interface{}{"name": "Bob", "ssn": "123-45-6789"}); err != nil {
+		t.Fatal(err)
+	}
+	stored := raw(t, f.backend, "foo")
+	if stored["name"] != "Bob" {
+		t.Errorf("Unencrypted field not stored as is: %v", stored)
+	}
+	if ssn, _ := json.Marshal(stored["ssn"]); !strings.Contains(string(ssn), `"$kid":"old"`) {
+		t.Errorf("Field not encrypted: %s", ssn)
+	}
+	expected := map[string]interface{}{"_id": "foo", "name": "Bob", "ssn": "123-45-6789"}
+	if d := diff.Interface(expected, raw(t, db, "foo")); d != nil {
+		t.Error(d)
+	}
+	// Documents are decrypted in result sets too.
+	rows, err := db.AllDocs(ctx, kivik.Options{"include_docs": true})
+	if err != nil {
+		t.Fatal(err)
+	}
+	var docs []map[string]interface{}
+	for rows.Next() {
+		var doc map[string]interface{}
+		if err := rows.ScanDoc(&doc); err != nil {
+			t.Fatal(err)
+		}
+		delete(doc, "_rev")
+		docs = append(docs, doc)
+	}
+	if d := diff.Interface([]map[string]interface{}{expected}, docs); d != nil {
+		t.Error(d)
+	}
+}
+
+func TestBody(t *testing.T) {
+	f := newFixture(t)
+	defer f.cleanup()
+	ctx := context.Background()
+	db := f.db(t, &Config{})
+	docID, _, err := db.CreateDoc(ctx, map[string]interface{}{"name": "Bob", "age": 42})
+	if err != nil {
+		t.Fatal(err)
+	}
+	stored := raw(t, f.backend, docID)
+	if _, ok := stored[bodyField]; !ok || len(stored) != 2 {
+		t.Errorf("Body not encrypted: %v", stored)
+	}
+	expected := map[string]interface{}{"_id": docID, "name": "Bob", "age": float64(42)}
+	if d := diff.Interface(expected, raw(t, db, docID)); d != nil {
+		t.Error(d)
+	}
+	// Design documents are stored unencrypted.
+	if _, err := db.Put(ctx, "_design/foo", map[string]interface{}{"language": "javascript"}); err != nil {
+		t.Fatal(err)
+	}
+	if stored := raw(t, f.backend, "_design/foo"); stored["language"] != "javascript" {
+		t.Errorf("Design document encrypted: %v", stored)
+	}
+}
+
+func TestRotation(t *testing.T) {
+	f := newFixture(t)
+	defer f.cleanup()
+	ctx := context.Background()
+	db := f.db(t, &Config{})
+	if _, err := f.backend.Put(ctx, "plain", map[string]string{"name": "Alice"}); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := db.Put(ctx, "foo", map[string]string{"name": "Bob"}); err != nil {
+		t.Fatal(err)
+	}
+	f.keys.Current = "new"
+	if _, err := db.Put(ctx, "bar", map[string]string{"name": "Carol"}); err != nil {
+		t.Fatal(err)
+	}
+	if stored, _ := json.Marshal(raw(t, f.backend, "bar")); !strings.Contains(string(stored), `"$kid":"new"`) {
+		t.Errorf("Not encrypted with the current key: %s", stored)
+	}
+	for docID, name := range map[string]string{"plain": "Alice", "foo": "Bob", "bar": "Carol"} {
+		if doc := raw(t, db, docID); doc["name"] != name {
+			t.Errorf("Unexpected %s: %v", docID, doc)
+		}
+	}
+	delete(f.keys.Keys, "old")
+	if err := db.Get(ctx, "foo").Err; kivik.StatusCode(err) != kivik.StatusInternalServerError {
+		t.Errorf("Unexpected error with a missing key: %v", err)
+	}
+}
+
+func TestAttachments(t *testing.T) {
+	f := newFixture(t)
+	defer f.cleanup()
+	ctx := context.Background()
+	db := f.db(t, &Config{Fields: []string{"secret"}, Attachments: true})
+	rev, err := db.Put(ctx, "foo", map[string]string{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	att := &kivik.Attachment{
+		Filename:    "foo.txt",
+		ContentType: "text/plain",
+		Content:     ioutil.NopCloser(strings.NewReader("attached")),
+	}
+	if _, err := db.PutAttachment(ctx, "foo", rev, att); err != nil {
+		t.Fatal(err)
+	}
+	read := func(db *kivik.DB) string {
+		att, err := db.GetAttachment(ctx, "foo", "", "foo.txt")
+		if err != nil {
+			t.Fatal(err)
+		}
+		defer att.Content.Close() // nolint: errcheck
+		content, err := ioutil.ReadAll(att.Content)
+		if err != nil {
+			t.Fatal(err)
+		}
+		return string(content)
+	}
+	if stored := read(f.backend); !strings.HasPrefix(stored, string(attachmentMagic)) {
+		t.Errorf("Attachment not encrypted: %q", stored)
+	}
+	if content := read(db); content != "attached" {
+		t.Errorf("Unexpected content: %q", content)
+	}
+	// Content moved to another attachment fails to decrypt.
+	stored := read(f.backend)
+	rev, err = f.backend.Rev(ctx, "foo")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := f.backend.PutAttachment(ctx, "foo", rev, &kivik.Attachment{
+		Filename:    "bar.txt",
+		ContentType: "text/plain",
+		Content:     ioutil.NopCloser(strings.NewReader(stored)),
+	}); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := db.GetAttachment(ctx, "foo", "", "bar.txt"); kivik.StatusCode(err) != kivik.StatusBadResponse {
+		t.Errorf("Unexpected error: %v", err)
+	}
+}
+
+func TestSwappedEnvelope(t *testing.T) {
+	f := newFixture(t)
+	defer f.cleanup()
+	ctx := context.Background()
+	db := f.db(t, &Config{Fields: []string{"ssn", "salary"}})
+	for _, docID := range []string{"foo", "bar"} {
+		if _, err := db.Put(ctx, docID, map[string]interface{}{"ssn": "123-45-6789", "salary": 1000}); err != nil {
+			t.Fatal(err)
+		}
+	}
+	tests := []struct {
+		name string
+		swap func(foo, bar map[string]interface{})
+	}{
+		{
+			name: "between fields",
+			swap: func(foo, _ map[string]interface{}) {
+				foo["ssn"], foo["salary"] = foo["salary"], foo["ssn"]
+			},
+		},
+		{
+			name: "between documents",
+			swap: func(foo, bar map[string]interface{}) {
+				foo["ssn"] = bar["ssn"]
+			},
+		},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			foo, bar := raw(t, f.backend, "foo"), raw(t, f.backend, "bar")
+			test.swap(foo, bar)
+			rev, err := f.backend.Rev(ctx, "foo")
+			if err != nil {
+				t.Fatal(err)
+			}
+			foo["_rev"] = rev
+			if _, err := f.backend.Put(ctx, "foo", foo); err != nil {
+				t.Fatal(err)
+			}
+			if err := db.Get(ctx, "foo").Err; kivik.StatusCode(err) != kivik.StatusBadResponse {
+				t.Errorf("Unexpected error: %v", err)
+			}
+		})
+	}
+}
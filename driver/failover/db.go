@@ -0,0 +1,336 @@
+package failover
+
+import (
+	"context"
+	"sync"
+
+	"github.com/go-kivik/kivik/driver"
+)
+
+// db is a database on every node. Its requests are sent to nodes in the same
+// way as the client's. Each node's database is a proxy, so implements every
+// optional interface declared here.
+type db struct {
+	client *client
+	name   string
+	opts   map[string]interface{}
+
+	mu  sync.Mutex
+	dbs []driver.DB
+}
+
+var (
+	_ driver.DB                   = &db{}
+	_ driver.DBCloser             = &db{}
+	_ driver.Finder               = &db{}
+	_ driver.DesignDocer          = &db{}
+	_ driver.LocalDocer           = &db{}
+	_ driver.RevsDiffer           = &db{}
+	_ driver.OpenRever            = &db{}
+	_ driver.Copier               = &db{}
+	_ driver.DocumentMetaGetter   = &db{}
+	_ driver.AttachmentMetaGetter = &db{}
+	_ driver.Flusher              = &db{}
+	_ driver.Purger               = &db{}
+)
+
+// node returns the database on node idx, opening it if necessary.
+func (d *db) node(ctx context.Context, idx int) (driver.DB, error) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	if d.dbs[idx] == nil {
+		db, err := d.client.nodes[idx].DB(ctx, d.name, d.opts)
+		if err != nil {
+			return nil, err
+		}
+		d.dbs[idx] = db
+	}
+	return d.dbs[idx], nil
+}
+
+// do calls fn with the database on each node in turn, as client.do.
+func (d *db) do(ctx context.Context, read bool, fn func(driver.DB) error) error {
+	return d.client.do(ctx, read, func(idx int) error {
+		db, err := d.node(ctx, idx)
+		if err != nil {
+			return err
+		}
+		return fn(db)
+	})
+}
+
+// rows calls fn, a read returning rows, as do.
+func (d *db) rows(ctx context.Context, fn func(driver.DB) (driver.Rows, error)) (driver.Rows, error) {
+	var rows driver.Rows
+	err := d.do(ctx, true, func(db driver.DB) error {
+		var e error
+		rows, e = fn(db)
+		return e
+	})
+	return rows, err
+}
+
+// Close closes the database on each node on which it has been opened.
+func (d *db) Close(ctx context.Context) error {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	var err error
+	for _, db := range d.dbs {
+		if db == nil {
+			continue
+		}
+		if e := db.(driver.DBCloser).Close(ctx); e != nil && err == nil {
+			err = e
+		}
+	}
+	return err
+}
+
+func (d *db) AllDocs(ctx context.Context, opts map[string]interface{}) (driver.Rows, error) {
+	return d.rows(ctx, func(db driver.DB) (driver.Rows, error) {
+		return db.AllDocs(ctx, opts)
+	})
+}
+
+func (d *db) DesignDocs(ctx context.Context, opts map[string]interface{}) (driver.Rows, error) {
+	return d.rows(ctx, func(db driver.DB) (driver.Rows, error) {
+		return db.(driver.DesignDocer).DesignDocs(ctx, opts)
+	})
+}
+
+func (d *db) LocalDocs(ctx context.Context, opts map[string]interface{}) (driver.Rows, error) {
+	return d.rows(ctx, func(db driver.DB) (driver.Rows, error) {
+		return db.(driver.LocalDocer).LocalDocs(ctx, opts)
+	})
+}
+
+func (d *db) Query(ctx context.Context, ddoc, view string, opts map[string]interface{}) (driver.Rows, error) {
+	return d.rows(ctx, func(db driver.DB) (driver.Rows, error) {
+		return db.Query(ctx, ddoc, view, opts)
+	})
+}
+
+func (d *db) Find(ctx context.Context, query interface{}) (driver.Rows, error) {
+	return d.rows(ctx, func(db driver.DB) (driver.Rows, error) {
+		return db.(driver.Finder).Find(ctx, query)
+	})
+}
+
+func (d *db) RevsDiff(ctx context.Context, revMap interface{}) (driver.Rows, error) {
+	return d.rows(ctx, func(db driver.DB) (driver.Rows, error) {
+		return db.(driver.RevsDiffer).RevsDiff(ctx, revMap)
+	})
+}
+
+func (d *db) OpenRevs(ctx context.Context, docID string, revs []string, opts map[string]interface{}) (driver.Rows, error) {
+	return d.rows(ctx, func(db driver.DB) (driver.Rows, error) {
+		return db.(driver.OpenRever).OpenRevs(ctx, docID, revs, opts)
+	})
+}
+
+func (d *db) Get(ctx context.Context, docID string, opts map[string]interface{}) (*driver.Document, error) {
+	var doc *driver.Document
+	err := d.do(ctx, true, func(db driver.DB) error {
+		var e error
+		doc, e = db.Get(ctx, docID, opts)
+		return e
+	})
+	return doc, err
+}
+
+func (d *db) GetDocumentMeta(ctx context.Context, docID string, opts map[string]interface{}) (*driver.DocumentMeta, error) {
+	var meta *driver.DocumentMeta
+	err := d.do(ctx, true, func(db driver.DB) error {
+		var e error
+		meta, e = db.(driver.DocumentMetaGetter).GetDocumentMeta(ctx, docID, opts)
+		return e
+	})
+	return meta, err
+}
+
+func (d *db) CreateDoc(ctx context.Context, doc interface{}, opts map[string]interface{}) (string, string, error) {
+	var docID, rev string
+	err := d.do(ctx, false, func(db driver.DB) error {
+		var e error
+		docID, rev, e = db.CreateDoc(ctx, doc, opts)
+		return e
+	})
+	return docID, rev, err
+}
+
+func (d *db) Put(ctx context.Context, docID string, doc interface{}, opts map[string]interface{}) (string, error) {
+	var rev string
+	err := d.do(ctx, false, func(db driver.DB) error {
+		var e error
+		rev, e = db.Put(ctx, docID, doc, opts)
+		return e
+	})
+	return rev, err
+}
+
+func (d *db) Delete(ctx context.Context, docID, rev string, opts map[string]interface{}) (string, error) {
+	var newRev string
+	err := d.do(ctx, false, func(db driver.DB) error {
+		var e error
+		newRev, e = db.Delete(ctx, docID, rev, opts)
+		return e
+	})
+	return newRev, err
+}
+
+func (d *db) Copy(ctx context.Context, targetID, sourceID string, opts map[string]interface{}) (string, error) {
+	var rev string
+	err := d.do(ctx, false, func(db driver.DB) error {
+		var e error
+		rev, e = db.(driver.Copier).Copy(ctx, targetID, sourceID, opts)
+		return e
+	})
+	return rev, err
+}
+
+func (d *db) Stats(ctx context.Context) (*driver.DBStats, error) {
+	var stats *driver.DBStats
+	err := d.do(ctx, true, func(db driver.DB) error {
+		var e error
+		stats, e = db.Stats(ctx)
+		return e
+	})
+	return stats, err
+}
+
+func (d *db) Compact(ctx context.Context) error {
+	return d.do(ctx, false, func(db driver.DB) error {
+		return db.Compact(ctx)
+	})
+}
+
+func (d *db) CompactView(ctx context.Context, ddocID string) error {
+	return d.do(ctx, false, func(db driver.DB) error {
+		return db.CompactView(ctx, ddocID)
+	})
+}
+
+func (d *db) ViewCleanup(ctx context.Context) error {
+	return d.do(ctx, false, func(db driver.DB) error {
+		return db.ViewCleanup(ctx)
+	})
+}
+
+func (d *db) Flush(ctx context.Context) error {
+	return d.do(ctx, false, func(db driver.DB) error {
+		return db.(driver.Flusher).Flush(ctx)
+	})
+}
+
+func (d *db) Purge(ctx context.Context, docRevMap map[string][]string) (*driver.PurgeResult, error) {
+	var result *driver.PurgeResult
+	err := d.do(ctx, false, func(db driver.DB) error {
+		var e error
+		result, e = db.(driver.Purger).Purge(ctx, docRevMap)
+		return e
+	})
+	return result, err
+}
+
+func (d *db) Security(ctx context.Context) (*driver.Security, error) {
+	var sec *driver.Security
+	err := d.do(ctx, true, func(db driver.DB) error {
+		var e error
+		sec, e = db.Security(ctx)
+		return e
+	})
+	return sec, err
+}
+
+func (d *db) SetSecurity(ctx context.Context, security *driver.Security) error {
+	return d.do(ctx, false, func(db driver.DB) error {
+		return db.SetSecurity(ctx, security)
+	})
+}
+
+func (d *db) Changes(ctx context.Context, opts map[string]interface{}) (driver.Changes, error) {
+	var changes driver.Changes
+	err := d.do(ctx, true, func(db driver.DB) error {
+		var e error
+		changes, e = db.Changes(ctx, opts)
+		return e
+	})
+	return changes, err
+}
+
+// PutAttachment is sent to the primary node only, as the attachment's
+// content cannot be read again for another node.
+func (d *db) PutAttachment(ctx context.Context, docID, rev string, att *driver.Attachment, opts map[string]interface{}) (string, error) {
+	var newRev string
+	err := d.client.doOnce(ctx, func(idx int) error {
+		db, e := d.node(ctx, idx)
+		if e != nil {
+			return e
+		}
+		newRev, e = db.PutAttachment(ctx, docID, rev, att, opts)
+		return e
+	})
+	return newRev, err
+}
+
+func (d *db) GetAttachment(ctx context.Context, docID, rev, filename string, opts map[string]interface{}) (*driver.Attachment, error) {
+	var att *driver.Attachment
+	err := d.do(ctx, true, func(db driver.DB) error {
+		var e error
+		att, e = db.GetAttachment(ctx, docID, rev, filename, opts)
+		return e
+	})
+	return att, err
+}
+
+func (d *db) GetAttachmentMeta(ctx context.Context, docID, rev, filename string, opts map[string]interface{}) (*driver.Attachment, error) {
+	var att *driver.Attachment
+	err := d.do(ctx, true, func(db driver.DB) error {
+		var e error
+		att, e = db.(driver.AttachmentMetaGetter).GetAttachmentMeta(ctx, docID, rev, filename, opts)
+		return e
+	})
+	return att, err
+}
+
+func (d *db) DeleteAttachment(ctx context.Context, docID, rev, filename string, opts map[string]interface{}) (string, error) {
+	var newRev string
+	err := d.do(ctx, false, func(db driver.DB) error {
+		var e error
+		newRev, e = db.DeleteAttachment(ctx, docID, rev, filename, opts)
+		return e
+	})
+	return newRev, err
+}
+
+func (d *db) CreateIndex(ctx context.Context, ddoc, name string, index interface{}) error {
+	return d.do(ctx, false, func(db driver.DB) error {
+		return db.(driver.Finder).CreateIndex(ctx, ddoc, name, index)
+	})
+}
+
+func (d *db) GetIndexes(ctx context.Context) ([]driver.Index, error) {
+	var indexes []driver.Index
+	err := d.do(ctx, true, func(db driver.DB) error {
+		var e error
+		indexes, e = db.(driver.Finder).GetIndexes(ctx)
+		return e
+	})
+	return indexes, err
+}
+
+func (d *db) DeleteIndex(ctx context.Context, ddoc, name string) error {
+	return d.do(ctx, false, func(db driver.DB) error {
+		return db.(driver.Finder).DeleteIndex(ctx, ddoc, name)
+	})
+}
+
+func (d *db) Explain(ctx context.Context, query interface{}) (*driver.QueryPlan, error) {
+	var plan *driver.QueryPlan
+	err := d.do(ctx, true, func(db driver.DB) error {
+		var e error
+		plan, e = db.(driver.Finder).Explain(ctx, query)
+		return e
+	})
+	return plan, err
+}
@@ -0,0 +1,358 @@
+// Package failover provides a kivik driver which connects to several
+// servers, typically the nodes of a CouchDB cluster with no load balancer in
+// front of it, and fails over from one to the next when a node becomes
+// unreachable, transparently to the caller:
+//
+//	client, err := failover.New(ctx, failover.Primary, "couch",
+//		"http://node1:5984/", "http://node2:5984/", "http://node3:5984/")
+//
+// Every request is sent to a single primary node, which remains in use until
+// it fails, so that session state is preserved. In BalanceReads mode,
+// read-only requests are instead distributed across all healthy nodes in
+// turn.
+//
+// A node is considered failed when a request returns StatusNetworkError or
+// StatusServiceUnavailable, and the request is then repeated on the next
+// node, until one succeeds or all have been tried. Requests should therefore
+// be safe to repeat. PutAttachment, whose content can be read only once, is
+// not repeated. Failed nodes are tried last, until they are found to be
+// healthy again, either by a successful request, or by Ping, which checks
+// every node, and may be called periodically to monitor them. Failover
+// happens only when a request is made; a result set or feed which fails part
+// way through is not resumed on another node.
+//
+// Credentials passed to Authenticate are kept, and used to authenticate with
+// each node before it is first used, or first used after failing, so that a
+// cookie authentication session follows the client from node to node.
+//
+// The failover client owns the clients it creates for each node, and closes
+// them when it is closed.
+package failover
+
+import (
+	"context"
+	"sync"
+
+	"github.com/go-kivik/kivik"
+	"github.com/go-kivik/kivik/driver"
+	"github.com/go-kivik/kivik/driver/proxy"
+	"github.com/go-kivik/kivik/errors"
+	"github.com/go-kivik/kivik/internal/registry"
+)
+
+// DriverName is the name under which the failover driver is registered.
+const DriverName = "failover"
+
+// Mode determines which node handles read-only requests.
+type Mode int
+
+const (
+	// Primary sends every request to the primary node.
+	Primary Mode = iota
+	// BalanceReads distributes read-only requests across all healthy nodes,
+	// and sends writes to the primary node.
+	BalanceReads
+)
+
+type config struct {
+	mode     Mode
+	backends []*kivik.Client
+}
+
+var configs = registry.New(DriverName, func(v interface{}) (driver.Client, error) {
+	cfg := v.(*config)
+	c := &client{
+		mode:     cfg.mode,
+		backends: cfg.backends,
+		nodes:    make([]driver.Client, len(cfg.backends)),
+		healthy:  make([]bool, len(cfg.backends)),
+		authed:   make([]int, len(cfg.backends)),
+	}
+	for i, backend := range cfg.backends {
+		c.nodes[i] = proxy.NewClient(backend)
+		c.healthy[i] = true
+	}
+	return c, nil
+})
+
+func init() {
+	kivik.Register(DriverName, configs.Driver())
+}
+
+// New returns a kivik client which connects with driverName to each of
+// urls, in order of preference, failing over from one to the next according
+// to mode. All nodes are initially assumed to be healthy.
+func New(ctx context.Context, mode Mode, driverName string, urls ...string) (*kivik.Client, error) {
+	if len(urls) == 0 {
+		return nil, errors.Status(kivik.StatusBadRequest, "failover: at least one URL required")
+	}
+	backends := make([]*kivik.Client, 0, len(urls))
+	for _, url := range urls {
+		backend, err := kivik.New(ctx, driverName, url)
+		if err != nil {
+			_ = closeAll(ctx, backends)
+			return nil, err
+		}
+		backends = append(backends, backend)
+	}
+	return configs.NewClient(ctx, &config{mode: mode, backends: backends})
+}
+
+// closeAll closes each of clients, returning the first error.
+func closeAll(ctx context.Context, clients []*kivik.Client) error {
+	var err error
+	for _, c := range clients {
+		if e := c.Close(ctx); e != nil && err == nil {
+			err = e
+		}
+	}
+	return err
+}
+
+type client struct {
+	mode     Mode
+	backends []*kivik.Client
+	nodes    []driver.Client
+
+	mu      sync.Mutex
+	healthy []bool
+	primary int
+	next    int
+	// auth holds the credentials last passed to Authenticate, and authGen
+	// counts the calls to Authenticate. authed[i] is equal to authGen once
+	// node i has been authenticated with auth.
+	auth    interface{}
+	authGen int
+	authed  []int
+}
+
+var (
+	_ driver.Client        = &client{}
+	_ driver.ClientCloser  = &client{}
+	_ driver.Pinger        = &client{}
+	_ driver.Authenticator = &client{}
+	_ driver.DBsStatser    = &client{}
+)
+
+// primaryIndex returns the index of the primary node, choosing a new one if
+// the current primary is unhealthy. c.mu must be held.
+func (c *client) primaryIndex() int {
+	for i := 0; i < len(c.nodes); i++ {
+		candidate := (c.primary + i) % len(c.nodes)
+		if c.healthy[candidate] {
+			c.primary = candidate
+			break
+		}
+	}
+	return c.primary
+}
+
+// order returns the indexes of the nodes, in the order they should be tried.
+// If balance is true, healthy nodes are used in turn. Otherwise, the primary
+// is tried first.
+func (c *client) order(balance bool) []int {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	start := c.primaryIndex()
+	if balance {
+		start = c.next % len(c.nodes)
+		c.next++
+	}
+	var healthy, unhealthy []int
+	for i := 0; i < len(c.nodes); i++ {
+		idx := (start + i) % len(c.nodes)
+		if c.healthy[idx] {
+			healthy = append(healthy, idx)
+		} else {
+			unhealthy = append(unhealthy, idx)
+		}
+	}
+	// Unhealthy nodes are tried last, in case they have recovered.
+	return append(healthy, unhealthy...)
+}
+
+// setHealthy records the health of a node. A node which has failed must be
+// authenticated again before it is next used, as it may have lost its
+// sessions.
+func (c *client) setHealthy(idx int, healthy bool) {
+	c.mu.Lock()
+	c.healthy[idx] = healthy
+	if !healthy {
+		c.authed[idx] = -1
+	}
+	c.mu.Unlock()
+}
+
+// authenticate authenticates with node idx, if Authenticate has been called
+// and the node has not yet been authenticated with the current credentials.
+func (c *client) authenticate(ctx context.Context, idx int) error {
+	c.mu.Lock()
+	auth, gen := c.auth, c.authGen
+	done := auth == nil || c.authed[idx] == gen
+	c.mu.Unlock()
+	if done {
+		return nil
+	}
+	if err := c.nodes[idx].(driver.Authenticator).Authenticate(ctx, auth); err != nil {
+		return err
+	}
+	c.mu.Lock()
+	if c.authGen == gen {
+		c.authed[idx] = gen
+	}
+	c.mu.Unlock()
+	return nil
+}
+
+// failoverError returns true if err indicates that the node is unavailable.
+func failoverError(err error) bool {
+	switch kivik.StatusCode(err) {
+	case kivik.StatusNetworkError, kivik.StatusServiceUnavailable:
+		return true
+	}
+	return false
+}
+
+// try calls fn with node idx, once it is authenticated, and records the
+// node's health. It returns true if the node is unavailable.
+func (c *client) try(ctx context.Context, idx int, fn func(int) error) (bool, error) {
+	err := c.authenticate(ctx, idx)
+	if err == nil {
+		err = fn(idx)
+	}
+	failed := failoverError(err)
+	c.setHealthy(idx, !failed)
+	return failed, err
+}
+
+// do calls fn with the index of the primary node, or, if read is true and
+// reads are balanced, the next healthy node. If the node is unavailable, fn
+// is called again with the next node, until it succeeds or all nodes have
+// been tried.
+func (c *client) do(ctx context.Context, read bool, fn func(int) error) error {
+	var err error
+	for _, idx := range c.order(read && c.mode == BalanceReads) {
+		if ctx.Err() != nil {
+			return ctx.Err()
+		}
+		var failed bool
+		if failed, err = c.try(ctx, idx, fn); !failed {
+			return err
+		}
+	}
+	return err
+}
+
+// doOnce calls fn with the index of the primary node only, for requests
+// which cannot be repeated.
+func (c *client) doOnce(ctx context.Context, fn func(int) error) error {
+	_, err := c.try(ctx, c.order(false)[0], fn)
+	return err
+}
+
+func (c *client) AllDBs(ctx context.Context, opts map[string]interface{}) ([]string, error) {
+	var dbs []string
+	err := c.do(ctx, true, func(idx int) error {
+		var e error
+		dbs, e = c.nodes[idx].AllDBs(ctx, opts)
+		return e
+	})
+	return dbs, err
+}
+
+func (c *client) DBExists(ctx context.Context, dbName string, opts map[string]interface{}) (bool, error) {
+	var exists bool
+	err := c.do(ctx, true, func(idx int) error {
+		var e error
+		exists, e = c.nodes[idx].DBExists(ctx, dbName, opts)
+		return e
+	})
+	return exists, err
+}
+
+func (c *client) CreateDB(ctx context.Context, dbName string, opts map[string]interface{}) error {
+	return c.do(ctx, false, func(idx int) error {
+		return c.nodes[idx].CreateDB(ctx, dbName, opts)
+	})
+}
+
+func (c *client) DestroyDB(ctx context.Context, dbName string, opts map[string]interface{}) error {
+	return c.do(ctx, false, func(idx int) error {
+		return c.nodes[idx].DestroyDB(ctx, dbName, opts)
+	})
+}
+
+func (c *client) Version(ctx context.Context) (*driver.Version, error) {
+	var ver *driver.Version
+	err := c.do(ctx, true, func(idx int) error {
+		var e error
+		ver, e = c.nodes[idx].Version(ctx)
+		return e
+	})
+	return ver, err
+}
+
+// DB returns a database which is opened on each node when first used there.
+func (c *client) DB(_ context.Context, dbName string, opts map[string]interface{}) (driver.DB, error) {
+	return &db{
+		client: c,
+		name:   dbName,
+		opts:   opts,
+		dbs:    make([]driver.DB, len(c.nodes)),
+	}, nil
+}
+
+func (c *client) DBsStats(ctx context.Context, dbnames []string) ([]*driver.DBStats, error) {
+	var stats []*driver.DBStats
+	err := c.do(ctx, true, func(idx int) error {
+		var e error
+		stats, e = c.nodes[idx].(driver.DBsStatser).DBsStats(ctx, dbnames)
+		return e
+	})
+	return stats, err
+}
+
+// Ping pings every node, updating its health status, and reports whether
+// any node is up.
+func (c *client) Ping(ctx context.Context) (bool, error) {
+	results := make([]bool, len(c.nodes))
+	var wg sync.WaitGroup
+	for i, node := range c.nodes {
+		wg.Add(1)
+		go func(i int, node driver.Client) {
+			defer wg.Done()
+			results[i], _ = node.(driver.Pinger).Ping(ctx)
+		}(i, node)
+	}
+	wg.Wait()
+	var up bool
+	for i, ok := range results {
+		c.setHealthy(i, ok)
+		up = up || ok
+	}
+	return up, nil
+}
+
+// Authenticate authenticates with the primary node, and keeps a, to
+// authenticate with other nodes as they are used. If authentication fails,
+// a is discarded.
+func (c *client) Authenticate(ctx context.Context, a interface{}) error {
+	c.mu.Lock()
+	c.auth = a
+	c.authGen++
+	gen := c.authGen
+	c.mu.Unlock()
+	err := c.do(ctx, false, func(int) error { return nil })
+	if err != nil {
+		c.mu.Lock()
+		if c.authGen == gen {
+			c.auth = nil
+		}
+		c.mu.Unlock()
+	}
+	return err
+}
+
+func (c *client) Close(ctx context.Context) error {
+	return closeAll(ctx, c.backends)
+}
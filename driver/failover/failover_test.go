@@ -0,0 +1,223 @@
+package failover
+
+import (
+	"context"
+	"io/ioutil"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/flimzy/diff"
+	"github.com/go-kivik/kivik"
+	"github.com/go-kivik/kivik/driver"
+	"github.com/go-kivik/kivik/errors"
+	"github.com/go-kivik/kivik/mock"
+)
+
+// testNodes is a driver whose clients, named by their DSN, record each call,
+// and fail with a network error while down.
+type testNodes struct {
+	mu    sync.Mutex
+	down  map[string]bool
+	calls []string
+}
+
+var nodes = &testNodes{down: map[string]bool{}}
+
+func init() {
+	kivik.Register("failovertest", nodes)
+}
+
+func (n *testNodes) reset() {
+	n.mu.Lock()
+	n.down = map[string]bool{}
+	n.calls = nil
+	n.mu.Unlock()
+}
+
+func (n *testNodes) setDown(dsn string, down bool) {
+	n.mu.Lock()
+	n.down[dsn] = down
+	n.mu.Unlock()
+}
+
+// takeCalls returns the calls recorded since the last call to takeCalls.
+func (n *testNodes) takeCalls() []string {
+	n.mu.Lock()
+	defer n.mu.Unlock()
+	calls := n.calls
+	n.calls = nil
+	return calls
+}
+
+func (n *testNodes) call(dsn, what string) error {
+	n.mu.Lock()
+	defer n.mu.Unlock()
+	if n.down[dsn] {
+		return errors.Status(kivik.StatusNetworkError, dsn+" unreachable")
+	}
+	n.calls = append(n.calls, dsn+": "+what)
+	return nil
+}
+
+func (n *testNodes) NewClient(_ context.Context, dsn string) (driver.Client, error) {
+	return &mock.Authenticator{
+		Client: &mock.Client{
+			VersionFunc: func(_ context.Context) (*driver.Version, error) {
+				if err := n.call(dsn, "version"); err != nil {
+					return nil, err
+				}
+				return &driver.Version{Version: dsn}, nil
+			},
+			DBFunc: func(_ context.Context, _ string, _ map[string]interface{}) (driver.DB, error) {
+				return &mock.DB{
+					GetFunc: func(_ context.Context, docID string, _ map[string]interface{}) (*driver.Document, error) {
+						if err := n.call(dsn, "get "+docID); err != nil {
+							return nil, err
+						}
+						return &driver.Document{
+							Rev:  "1-xxx",
+							Body: ioutil.NopCloser(strings.NewReader(`{}`)),
+						}, nil
+					},
+					PutFunc: func(_ context.Context, docID string, _ interface{}, _ map[string]interface{}) (string, error) {
+						if err := n.call(dsn, "put "+docID); err != nil {
+							return "", err
+						}
+						return "1-xxx", nil
+					},
+				}, nil
+			},
+		},
+		AuthenticateFunc: func(_ context.Context, a interface{}) error {
+			return n.call(dsn, "auth "+a.(string))
+		},
+	}, nil
+}
+
+func newTestClient(t *testing.T, mode Mode) (*kivik.Client, *kivik.DB) {
+	nodes.reset()
+	client, err := New(context.Background(), mode, "failovertest", "a", "b", "c")
+	if err != nil {
+		t.Fatal(err)
+	}
+	db, err := client.DB(context.Background(), "test")
+	if err != nil {
+		t.Fatal(err)
+	}
+	return client, db
+}
+
+func get(t *testing.T, db *kivik.DB, docID string) {
+	row := db.Get(context.Background(), docID)
+	if row.Err != nil {
+		t.Fatal(row.Err)
+	}
+	_ = row.Body.Close()
+}
+
+func put(t *testing.T, db *kivik.DB, docID string) {
+	if _, err := db.Put(context.Background(), docID, map[string]string{}); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func checkCalls(t *testing.T, expected ...string) {
+	if d := diff.Interface(expected, nodes.takeCalls()); d != nil {
+		t.Error(d)
+	}
+}
+
+func TestNew(t *testing.T) {
+	if _, err := New(context.Background(), Primary, "failovertest"); kivik.StatusCode(err) != kivik.StatusBadRequest {
+		t.Errorf("Unexpected error: %v", err)
+	}
+	if _, err := New(context.Background(), Primary, "unknown", "a"); err == nil {
+		t.Error("Expected an error for an unknown driver")
+	}
+}
+
+func TestFailover(t *testing.T) {
+	_, db := newTestClient(t, Primary)
+
+	get(t, db, "foo")
+	checkCalls(t, "a: get foo")
+
+	nodes.setDown("a", true)
+	put(t, db, "foo")
+	checkCalls(t, "b: put foo")
+
+	// The primary remains b, even once a recovers, until b fails.
+	nodes.setDown("a", false)
+	get(t, db, "foo")
+	checkCalls(t, "b: get foo")
+
+	nodes.setDown("b", true)
+	get(t, db, "foo")
+	checkCalls(t, "c: get foo")
+
+	nodes.setDown("c", true)
+	nodes.setDown("a", true)
+	row := db.Get(context.Background(), "foo")
+	if kivik.StatusCode(row.Err) != kivik.StatusNetworkError {
+		t.Errorf("Unexpected error: %v", row.Err)
+	}
+}
+
+func TestBalanceReads(t *testing.T) {
+	_, db := newTestClient(t, BalanceReads)
+
+	get(t, db, "foo")
+	get(t, db, "foo")
+	put(t, db, "foo")
+	get(t, db, "foo")
+	checkCalls(t, "a: get foo", "b: get foo", "a: put foo", "c: get foo")
+
+	nodes.setDown("a", true)
+	get(t, db, "foo")
+	put(t, db, "foo")
+	checkCalls(t, "b: get foo", "b: put foo")
+}
+
+func TestAuthenticate(t *testing.T) {
+	client, db := newTestClient(t, Primary)
+	ctx := context.Background()
+
+	if err := client.Authenticate(ctx, "alice"); err != nil {
+		t.Fatal(err)
+	}
+	get(t, db, "foo")
+	checkCalls(t, "a: auth alice", "a: get foo")
+
+	// The session follows the client to the next node.
+	nodes.setDown("a", true)
+	get(t, db, "foo")
+	checkCalls(t, "b: auth alice", "b: get foo")
+
+	// A node which has failed is authenticated again when next used.
+	nodes.setDown("a", false)
+	nodes.setDown("b", true)
+	nodes.setDown("c", true)
+	get(t, db, "foo")
+	checkCalls(t, "a: auth alice", "a: get foo")
+}
+
+func TestPing(t *testing.T) {
+	client, db := newTestClient(t, Primary)
+	ctx := context.Background()
+
+	nodes.setDown("a", true)
+	if up, err := client.Ping(ctx); !up || err != nil {
+		t.Errorf("Unexpected result: %t, %v", up, err)
+	}
+	nodes.takeCalls()
+	// a is known to be down, so is not tried first.
+	get(t, db, "foo")
+	checkCalls(t, "b: get foo")
+
+	nodes.setDown("b", true)
+	nodes.setDown("c", true)
+	if up, err := client.Ping(ctx); up || err != nil {
+		t.Errorf("Unexpected result: %t, %v", up, err)
+	}
+}
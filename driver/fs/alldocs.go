@@ -0,0 +1,200 @@
+package fs
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"io/ioutil"
+	"net/url"
+	"os"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/go-kivik/kivik"
+	"github.com/go-kivik/kivik/driver"
+	"github.com/go-kivik/kivik/driver/internal/docquery"
+	"github.com/go-kivik/kivik/errors"
+)
+
+type rowsIter struct {
+	rows      []*driver.Row
+	offset    int64
+	totalRows int64
+	updateSeq string
+	warning   string
+	bookmark  string
+}
+
+var (
+	_ driver.Rows       = &rowsIter{}
+	_ driver.RowsWarner = &rowsIter{}
+	_ driver.Bookmarker = &rowsIter{}
+)
+
+func (r *rowsIter) Next(row *driver.Row) error {
+	if len(r.rows) == 0 {
+		return io.EOF
+	}
+	*row, r.rows = *r.rows[0], r.rows[1:]
+	return nil
+}
+
+func (r *rowsIter) Close() error {
+	r.rows = nil
+	return nil
+}
+
+func (r *rowsIter) UpdateSeq() string { return r.updateSeq }
+func (r *rowsIter) Offset() int64     { return r.offset }
+func (r *rowsIter) TotalRows() int64  { return r.totalRows }
+func (r *rowsIter) Warning() string   { return r.warning }
+func (r *rowsIter) Bookmark() string  { return r.bookmark }
+
+// docs reads every document in the database, other than local documents.
+// The caller must hold d.mu.
+func (d *db) docs() (map[string]document, error) {
+	files, err := ioutil.ReadDir(d.dir)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil, errDBNotFound
+		}
+		return nil, err
+	}
+	docs := make(map[string]document, len(files))
+	for _, file := range files {
+		name := file.Name()
+		if file.IsDir() || !strings.HasSuffix(name, docExt) {
+			continue
+		}
+		docID, err := url.PathUnescape(strings.TrimSuffix(name, docExt))
+		if err != nil || isLocal(docID) {
+			continue
+		}
+		doc, err := d.readDoc(docID)
+		if err != nil {
+			return nil, err
+		}
+		docs[docID] = doc
+	}
+	return docs, nil
+}
+
+// AllDocs returns the documents in the database, ordered by ID.
+func (d *db) AllDocs(_ context.Context, opts map[string]interface{}) (driver.Rows, error) {
+	q, err := docquery.ParseAllDocs(opts)
+	if err != nil {
+		return nil, err
+	}
+	d.mu.RLock()
+	defer d.mu.RUnlock()
+	docs, err := d.docs()
+	if err != nil {
+		return nil, err
+	}
+	ids := make([]string, 0, len(docs))
+	for id, doc := range docs {
+		if !doc.deleted() {
+			ids = append(ids, id)
+		}
+	}
+	sort.Strings(ids)
+	result := &rowsIter{totalRows: int64(len(ids))}
+	if q.UpdateSeq {
+		seq, err := d.updateSeq()
+		if err != nil {
+			return nil, err
+		}
+		result.updateSeq = strconv.FormatInt(seq, 10)
+	}
+	var rows []*driver.Row
+	if q.HasKeys {
+		rows, err = keyRows(q, docs)
+	} else {
+		rows, result.offset, err = rangeRows(q, ids, docs)
+	}
+	if err != nil {
+		return nil, err
+	}
+	if q.Skip > 0 {
+		if q.Skip > int64(len(rows)) {
+			q.Skip = int64(len(rows))
+		}
+		rows = rows[q.Skip:]
+		result.offset += q.Skip
+	}
+	if q.Limit > 0 && q.Limit < int64(len(rows)) {
+		rows = rows[:q.Limit]
+	}
+	result.rows = rows
+	return result, nil
+}
+
+func rangeRows(q *docquery.AllDocs, ids []string, docs map[string]document) ([]*driver.Row, int64, error) {
+	if q.Descending {
+		for i, j := 0, len(ids)-1; i < j; i, j = i+1, j-1 {
+			ids[i], ids[j] = ids[j], ids[i]
+		}
+	}
+	var rows []*driver.Row
+	var offset int64
+	for _, id := range ids {
+		if !q.InRange(id) {
+			if len(rows) == 0 {
+				offset++
+			}
+			continue
+		}
+		row, err := docRow(id, docs[id], q.IncludeDocs)
+		if err != nil {
+			return nil, 0, err
+		}
+		rows = append(rows, row)
+	}
+	if len(rows) == 0 {
+		offset = int64(len(ids))
+	}
+	return rows, offset, nil
+}
+
+func keyRows(q *docquery.AllDocs, docs map[string]document) ([]*driver.Row, error) {
+	rows := make([]*driver.Row, 0, len(q.Keys))
+	for _, id := range q.Keys {
+		doc, ok := docs[id]
+		if !ok {
+			key, _ := json.Marshal(id)
+			rows = append(rows, &driver.Row{
+				Key:   key,
+				Error: errors.Status(kivik.StatusNotFound, "not_found"),
+			})
+			continue
+		}
+		row, err := docRow(id, doc, q.IncludeDocs && !doc.deleted())
+		if err != nil {
+			return nil, err
+		}
+		rows = append(rows, row)
+	}
+	return rows, nil
+}
+
+func docRow(id string, doc document, includeDoc bool) (*driver.Row, error) {
+	key, err := json.Marshal(id)
+	if err != nil {
+		return nil, err
+	}
+	value := map[string]interface{}{"rev": doc.rev()}
+	if doc.deleted() {
+		value["deleted"] = true
+	}
+	row := &driver.Row{ID: id, Key: key}
+	if row.Value, err = json.Marshal(value); err != nil {
+		return nil, err
+	}
+	if includeDoc {
+		if row.Doc, err = json.Marshal(doc); err != nil {
+			return nil, err
+		}
+	}
+	return row, nil
+}
@@ -0,0 +1,167 @@
+package fs
+
+import (
+	"context"
+	"encoding/base64"
+	"io/ioutil"
+	"os"
+
+	"github.com/go-kivik/kivik"
+	"github.com/go-kivik/kivik/driver"
+	"github.com/go-kivik/kivik/errors"
+)
+
+func (d *db) PutAttachment(_ context.Context, docID, rev string, att *driver.Attachment, _ map[string]interface{}) (string, error) {
+	if err := validateID(docID); err != nil {
+		return "", err
+	}
+	content, err := ioutil.ReadAll(att.Content)
+	if err != nil {
+		return "", err
+	}
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	if err := d.checkDB(); err != nil {
+		return "", err
+	}
+	doc, err := d.readDoc(docID)
+	switch {
+	case kivik.StatusCode(err) == kivik.StatusNotFound:
+		doc = document{}
+	case err != nil:
+		return "", err
+	case doc.deleted():
+		doc = document{}
+	}
+	if rev != doc.rev() {
+		return "", errConflict
+	}
+	atts := stubs(doc.attachments())
+	atts[att.Filename] = map[string]interface{}{
+		"content_type": att.ContentType,
+		"data":         base64.StdEncoding.EncodeToString(content),
+	}
+	doc["_attachments"] = atts
+	return d.update(docID, doc)
+}
+
+func (d *db) DeleteAttachment(_ context.Context, docID, rev, filename string, _ map[string]interface{}) (string, error) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	if err := d.checkDB(); err != nil {
+		return "", err
+	}
+	doc, err := d.readDoc(docID)
+	if err != nil {
+		return "", err
+	}
+	if doc.deleted() {
+		return "", errDeleted
+	}
+	if rev != doc.rev() {
+		return "", errConflict
+	}
+	atts := stubs(doc.attachments())
+	if _, ok := atts[filename]; !ok {
+		return "", errMissing
+	}
+	delete(atts, filename)
+	doc["_attachments"] = atts
+	return d.update(docID, doc)
+}
+
+// stubs returns a copy of the stored attachments, marked as stubs.
+func stubs(atts map[string]interface{}) map[string]interface{} {
+	result := make(map[string]interface{}, len(atts))
+	for filename := range atts {
+		result[filename] = map[string]interface{}{"stub": true}
+	}
+	return result
+}
+
+func (d *db) GetAttachment(_ context.Context, docID, rev, filename string, _ map[string]interface{}) (*driver.Attachment, error) {
+	d.mu.RLock()
+	defer d.mu.RUnlock()
+	att, err := d.attachmentMeta(docID, rev, filename)
+	if err != nil {
+		return nil, err
+	}
+	path, err := d.attPath(docID, filename)
+	if err != nil {
+		return nil, err
+	}
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	att.Content = f
+	return att, nil
+}
+
+func (d *db) GetAttachmentMeta(_ context.Context, docID, rev, filename string, _ map[string]interface{}) (*driver.Attachment, error) {
+	d.mu.RLock()
+	defer d.mu.RUnlock()
+	return d.attachmentMeta(docID, rev, filename)
+}
+
+// attachmentMeta returns the stored metadata of an attachment. The caller
+// must hold d.mu.
+func (d *db) attachmentMeta(docID, rev, filename string) (*driver.Attachment, error) {
+	if err := d.checkDB(); err != nil {
+		return nil, err
+	}
+	doc, err := d.readDoc(docID)
+	if err != nil {
+		return nil, err
+	}
+	if rev != "" && rev != doc.rev() {
+		return nil, errMissing
+	}
+	if doc.deleted() {
+		return nil, errDeleted
+	}
+	meta, ok := doc.attachments()[filename].(map[string]interface{})
+	if !ok {
+		return nil, errMissing
+	}
+	att := &driver.Attachment{Filename: filename}
+	att.ContentType, _ = meta["content_type"].(string)
+	att.Digest, _ = meta["digest"].(string)
+	if length, ok := meta["length"].(float64); ok {
+		att.Size = int64(length)
+	}
+	if revPos, ok := meta["revpos"].(float64); ok {
+		att.RevPos = int64(revPos)
+	}
+	return att, nil
+}
+
+// inlineAttachments replaces the attachment stubs in doc with their
+// base64-encoded content. The caller must hold d.mu.
+func (d *db) inlineAttachments(docID string, doc document) error {
+	atts := doc.attachments()
+	inlined := make(map[string]interface{}, len(atts))
+	for filename, a := range atts {
+		meta, _ := a.(map[string]interface{})
+		path, err := d.attPath(docID, filename)
+		if err != nil {
+			return err
+		}
+		content, err := ioutil.ReadFile(path)
+		if err != nil {
+			return errors.Wrapf(err, "fs: read attachment %s", filename)
+		}
+		att := make(map[string]interface{}, len(meta)+1)
+		for k, v := range meta {
+			if k != "stub" {
+				att[k] = v
+			}
+		}
+		att["data"] = base64.StdEncoding.EncodeToString(content)
+		inlined[filename] = att
+	}
+	if len(inlined) > 0 {
+		doc["_attachments"] = inlined
+	}
+	return nil
+}
@@ -0,0 +1,290 @@
+package fs
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/go-kivik/kivik"
+	"github.com/go-kivik/kivik/driver"
+	"github.com/go-kivik/kivik/driver/internal/docquery"
+	"github.com/go-kivik/kivik/errors"
+)
+
+const changesLog = "_changes.log"
+
+// PollInterval is how often the changes log is checked for new entries by
+// longpoll and continuous changes feeds.
+var PollInterval = 100 * time.Millisecond
+
+// logEntry is a single line of the changes log.
+type logEntry struct {
+	Seq     int64  `json:"seq"`
+	ID      string `json:"id"`
+	Rev     string `json:"rev"`
+	Deleted bool   `json:"deleted,omitempty"`
+}
+
+func (d *db) logPath() string {
+	return filepath.Join(d.dir, changesLog)
+}
+
+// readLog returns the log entries after since. The caller must hold d.mu.
+func (d *db) readLog(since int64) ([]logEntry, error) {
+	f, err := os.Open(d.logPath())
+	if os.IsNotExist(err) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close() // nolint: errcheck
+	var entries []logEntry
+	scanner := bufio.NewScanner(f)
+	for scanner.Scan() {
+		var entry logEntry
+		if err := json.Unmarshal(scanner.Bytes(), &entry); err != nil {
+			return nil, errors.Wrap(err, "fs: corrupt changes log")
+		}
+		if entry.Seq > since {
+			entries = append(entries, entry)
+		}
+	}
+	return entries, scanner.Err()
+}
+
+// updateSeq returns the sequence of the last logged update. The caller must
+// hold d.mu.
+func (d *db) updateSeq() (int64, error) {
+	entries, err := d.readLog(0)
+	if err != nil || len(entries) == 0 {
+		return 0, err
+	}
+	return entries[len(entries)-1].Seq, nil
+}
+
+// appendLog records an update. The caller must hold d.mu for writing.
+func (d *db) appendLog(docID, rev string, deleted bool) error {
+	seq, err := d.updateSeq()
+	if err != nil {
+		return err
+	}
+	line, err := json.Marshal(logEntry{Seq: seq + 1, ID: docID, Rev: rev, Deleted: deleted})
+	if err != nil {
+		return err
+	}
+	f, err := os.OpenFile(d.logPath(), os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0666)
+	if err != nil {
+		return err
+	}
+	if _, err := f.Write(append(line, '\n')); err != nil {
+		_ = f.Close()
+		return err
+	}
+	return f.Close()
+}
+
+// latest returns only the last entry for each document, in sequence order.
+func latest(entries []logEntry) []logEntry {
+	last := make(map[string]int, len(entries))
+	for i, entry := range entries {
+		last[entry.ID] = i
+	}
+	result := make([]logEntry, 0, len(last))
+	for i, entry := range entries {
+		if last[entry.ID] == i {
+			result = append(result, entry)
+		}
+	}
+	return result
+}
+
+func parseSince(since interface{}, updateSeq int64) (int64, error) {
+	switch t := since.(type) {
+	case nil:
+		return 0, nil
+	case int:
+		return int64(t), nil
+	case int64:
+		return t, nil
+	case float64:
+		return int64(t), nil
+	case string:
+		switch t {
+		case "":
+			return 0, nil
+		case "now":
+			return updateSeq, nil
+		}
+		seq, err := strconv.ParseInt(t, 10, 64)
+		if err != nil {
+			return 0, errors.Statusf(kivik.StatusBadRequest, "fs: invalid since value: %s", t)
+		}
+		return seq, nil
+	}
+	return parseSince(fmt.Sprint(since), updateSeq)
+}
+
+// Changes returns the changes feed. The feed option may be "normal",
+// "longpoll" or "continuous"; the latter two poll the changes log every
+// PollInterval for new entries.
+func (d *db) Changes(ctx context.Context, opts map[string]interface{}) (driver.Changes, error) {
+	limit, err := docquery.IntOpt(opts, "limit")
+	if err != nil {
+		return nil, err
+	}
+	d.mu.RLock()
+	defer d.mu.RUnlock()
+	if err := d.checkDB(); err != nil {
+		return nil, err
+	}
+	updateSeq, err := d.updateSeq()
+	if err != nil {
+		return nil, err
+	}
+	since, err := parseSince(opts["since"], updateSeq)
+	if err != nil {
+		return nil, err
+	}
+	feed, _ := opts["feed"].(string)
+	c := &changes{
+		ctx:         ctx,
+		db:          d,
+		feed:        feed,
+		since:       since,
+		limit:       limit,
+		includeDocs: docquery.BoolOpt(opts, "include_docs"),
+		descending:  docquery.BoolOpt(opts, "descending") && (feed == "" || feed == "normal"),
+		lastSeq:     since,
+		closed:      make(chan struct{}),
+	}
+	if err := c.load(); err != nil {
+		return nil, err
+	}
+	return c, nil
+}
+
+type changes struct {
+	ctx         context.Context
+	db          *db
+	feed        string
+	since       int64
+	limit       int64
+	includeDocs bool
+	descending  bool
+
+	pending []logEntry
+	sent    int64
+	// lastSeq is the sequence of the last change returned.
+	lastSeq int64
+	remain  int64
+
+	closeOnce sync.Once
+	closed    chan struct{}
+}
+
+var _ driver.Changes = &changes{}
+
+func (c *changes) polling() bool {
+	return c.feed == "longpoll" || c.feed == "continuous"
+}
+
+// load reads the entries after c.since. The caller must hold c.db.mu.
+func (c *changes) load() error {
+	entries, err := c.db.readLog(c.since)
+	if err != nil {
+		return err
+	}
+	entries = latest(entries)
+	if c.descending {
+		sort.Slice(entries, func(i, j int) bool { return entries[i].Seq > entries[j].Seq })
+	}
+	c.pending = entries
+	for _, entry := range entries {
+		if entry.Seq > c.since {
+			c.since = entry.Seq
+		}
+	}
+	return nil
+}
+
+func (c *changes) Next(change *driver.Change) error {
+	if c.limit > 0 && c.sent >= c.limit {
+		c.remain = int64(len(c.pending))
+		c.pending = nil
+		return io.EOF
+	}
+	for len(c.pending) == 0 {
+		if !c.polling() || (c.feed == "longpoll" && c.sent > 0) {
+			return io.EOF
+		}
+		timer := time.NewTimer(PollInterval)
+		select {
+		case <-c.ctx.Done():
+			timer.Stop()
+			return c.ctx.Err()
+		case <-c.closed:
+			timer.Stop()
+			return io.EOF
+		case <-timer.C:
+		}
+		if err := c.reload(); err != nil {
+			return err
+		}
+	}
+	entry := c.pending[0]
+	c.pending = c.pending[1:]
+	c.sent++
+	c.lastSeq = entry.Seq
+	*change = driver.Change{
+		ID:      entry.ID,
+		Seq:     driver.SequenceID(strconv.FormatInt(entry.Seq, 10)),
+		Deleted: entry.Deleted,
+		Changes: driver.ChangedRevs{entry.Rev},
+	}
+	if c.includeDocs {
+		c.db.mu.RLock()
+		doc, err := c.db.readDoc(entry.ID)
+		c.db.mu.RUnlock()
+		if err != nil && kivik.StatusCode(err) != kivik.StatusNotFound {
+			return err
+		}
+		if doc != nil {
+			if change.Doc, err = json.Marshal(doc); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+// reload checks for new entries, for polling feeds.
+func (c *changes) reload() error {
+	c.db.mu.RLock()
+	defer c.db.mu.RUnlock()
+	if err := c.db.checkDB(); err != nil {
+		return err
+	}
+	return c.load()
+}
+
+func (c *changes) Close() error {
+	c.closeOnce.Do(func() { close(c.closed) })
+	return nil
+}
+
+func (c *changes) LastSeq() string {
+	return strconv.FormatInt(c.lastSeq, 10)
+}
+
+func (c *changes) Pending() int64 {
+	return c.remain
+}
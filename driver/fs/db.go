@@ -0,0 +1,600 @@
+package fs
+
+import (
+	"bytes"
+	"context"
+	"crypto/md5"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"net/url"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+
+	"github.com/go-kivik/kivik"
+	"github.com/go-kivik/kivik/driver"
+	"github.com/go-kivik/kivik/driver/internal/docquery"
+	"github.com/go-kivik/kivik/errors"
+)
+
+var (
+	errDBNotFound = errors.Status(kivik.StatusNotFound, "Database does not exist.")
+	errMissing    = errors.Status(kivik.StatusNotFound, "missing")
+	errDeleted    = errors.Status(kivik.StatusNotFound, "deleted")
+	errConflict   = errors.Status(kivik.StatusConflict, "Document update conflict.")
+)
+
+const (
+	docExt      = ".json"
+	attDirExt   = ".att"
+	securityDoc = "_security"
+	indexesDoc  = "_indexes"
+)
+
+type db struct {
+	name string
+	dir  string
+	mu   *sync.RWMutex
+}
+
+var (
+	_ driver.DB                   = &db{}
+	_ driver.RevsDiffer           = &db{}
+	_ driver.AttachmentMetaGetter = &db{}
+)
+
+// document is a stored document, decoded.
+type document map[string]interface{}
+
+func (d document) rev() string {
+	rev, _ := d["_rev"].(string)
+	return rev
+}
+
+func (d document) deleted() bool {
+	deleted, _ := d["_deleted"].(bool)
+	return deleted
+}
+
+func (d document) attachments() map[string]interface{} {
+	atts, _ := d["_attachments"].(map[string]interface{})
+	return atts
+}
+
+// checkDB returns an error if the database does not exist. The caller must
+// hold d.mu.
+func (d *db) checkDB() error {
+	if _, err := os.Stat(d.dir); err != nil {
+		if os.IsNotExist(err) {
+			return errDBNotFound
+		}
+		return err
+	}
+	return nil
+}
+
+func (d *db) docPath(docID string) string {
+	return filepath.Join(d.dir, url.PathEscape(docID)+docExt)
+}
+
+// attPath returns the path of an attachment file. The filenames "." and "..",
+// which url.PathEscape leaves as is, are rejected, as they would refer to the
+// attachment directory itself, or to the database directory.
+func (d *db) attPath(docID, filename string) (string, error) {
+	if filename == "" || filename == "." || filename == ".." {
+		return "", errors.Statusf(kivik.StatusBadRequest, "fs: invalid attachment name: %q", filename)
+	}
+	return filepath.Join(d.dir, url.PathEscape(docID)+attDirExt, url.PathEscape(filename)), nil
+}
+
+// readDoc reads the stored document, or returns errMissing.
+func (d *db) readDoc(docID string) (document, error) {
+	data, err := ioutil.ReadFile(d.docPath(docID))
+	if os.IsNotExist(err) {
+		return nil, errMissing
+	}
+	if err != nil {
+		return nil, err
+	}
+	var doc document
+	if err := json.Unmarshal(data, &doc); err != nil {
+		return nil, errors.Wrapf(err, "fs: corrupt document %s", docID)
+	}
+	return doc, nil
+}
+
+// writeFile writes data to path atomically, by way of a temporary file.
+func writeFile(path string, data []byte) error {
+	if err := os.MkdirAll(filepath.Dir(path), 0777); err != nil {
+		return err
+	}
+	tmp, err := ioutil.TempFile(filepath.Dir(path), ".tmp-")
+	if err != nil {
+		return err
+	}
+	if _, err := tmp.Write(data); err != nil {
+		_ = tmp.Close()
+		_ = os.Remove(tmp.Name())
+		return err
+	}
+	if err := tmp.Close(); err != nil {
+		_ = os.Remove(tmp.Name())
+		return err
+	}
+	return os.Rename(tmp.Name(), path)
+}
+
+func isLocal(docID string) bool {
+	return strings.HasPrefix(docID, "_local/")
+}
+
+func validateID(docID string) error {
+	if docID == "" {
+		return errors.Status(kivik.StatusBadRequest, "Document id must not be empty")
+	}
+	if strings.HasPrefix(docID, "_") && !strings.HasPrefix(docID, "_design/") && !isLocal(docID) {
+		return errors.Status(kivik.StatusBadRequest, "Only reserved document ids may start with underscore.")
+	}
+	return nil
+}
+
+// normalize converts doc to a document by way of JSON.
+func normalize(doc interface{}) (document, error) {
+	var data []byte
+	switch t := doc.(type) {
+	case json.RawMessage:
+		data = t
+	case []byte:
+		data = t
+	default:
+		var err error
+		if data, err = json.Marshal(doc); err != nil {
+			return nil, errors.WrapStatus(kivik.StatusBadRequest, err)
+		}
+	}
+	var result document
+	if err := json.Unmarshal(data, &result); err != nil {
+		return nil, errors.WrapStatus(kivik.StatusBadRequest, err)
+	}
+	if result == nil {
+		return nil, errors.Status(kivik.StatusBadRequest, "Document must be a JSON object")
+	}
+	return result, nil
+}
+
+// parseRev splits a revision into its generation and hash.
+func parseRev(rev string) (int64, string, error) {
+	parts := strings.SplitN(rev, "-", 2)
+	if len(parts) != 2 {
+		return 0, "", errors.Statusf(kivik.StatusBadRequest, "Invalid rev format")
+	}
+	gen, err := strconv.ParseInt(parts[0], 10, 64)
+	if err != nil {
+		return 0, "", errors.Statusf(kivik.StatusBadRequest, "Invalid rev format")
+	}
+	return gen, parts[1], nil
+}
+
+// revWins reports whether rev a beats rev b, by CouchDB's deterministic
+// choice of winning revision.
+func revWins(a, b string) bool {
+	genA, hashA, _ := parseRev(a)
+	genB, hashB, _ := parseRev(b)
+	if genA != genB {
+		return genA > genB
+	}
+	return hashA > hashB
+}
+
+func (d *db) Get(_ context.Context, docID string, opts map[string]interface{}) (*driver.Document, error) {
+	d.mu.RLock()
+	defer d.mu.RUnlock()
+	if err := d.checkDB(); err != nil {
+		return nil, err
+	}
+	doc, err := d.readDoc(docID)
+	if err != nil {
+		return nil, err
+	}
+	if rev, _ := opts["rev"].(string); rev != "" {
+		if rev != doc.rev() {
+			return nil, errMissing
+		}
+	} else if doc.deleted() {
+		return nil, errDeleted
+	}
+	if docquery.BoolOpt(opts, "revs") && !isLocal(docID) {
+		gen, hash, err := parseRev(doc.rev())
+		if err != nil {
+			return nil, err
+		}
+		doc["_revisions"] = map[string]interface{}{
+			"start": gen,
+			"ids":   []string{hash},
+		}
+	}
+	if docquery.BoolOpt(opts, "attachments") {
+		if err := d.inlineAttachments(docID, doc); err != nil {
+			return nil, err
+		}
+	}
+	body, err := json.Marshal(doc)
+	if err != nil {
+		return nil, err
+	}
+	return &driver.Document{
+		ContentLength: int64(len(body)),
+		Rev:           doc.rev(),
+		Body:          ioutil.NopCloser(bytes.NewReader(body)),
+	}, nil
+}
+
+func (d *db) CreateDoc(ctx context.Context, doc interface{}, opts map[string]interface{}) (docID, rev string, err error) {
+	stored, err := normalize(doc)
+	if err != nil {
+		return "", "", err
+	}
+	docID, _ = stored["_id"].(string)
+	if docID == "" {
+		if docID, err = kivik.UUIDv4(); err != nil {
+			return "", "", err
+		}
+	}
+	rev, err = d.Put(ctx, docID, stored, opts)
+	return docID, rev, err
+}
+
+func (d *db) Put(_ context.Context, docID string, doc interface{}, opts map[string]interface{}) (string, error) {
+	if err := validateID(docID); err != nil {
+		return "", err
+	}
+	newDoc, err := normalize(doc)
+	if err != nil {
+		return "", err
+	}
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	if err := d.checkDB(); err != nil {
+		return "", err
+	}
+	if newEdits, ok := opts["new_edits"]; ok && (newEdits == false || newEdits == "false") {
+		return d.replicate(docID, newDoc)
+	}
+	if rev, _ := opts["rev"].(string); rev != "" {
+		newDoc["_rev"] = rev
+	}
+	return d.update(docID, newDoc)
+}
+
+func (d *db) Delete(_ context.Context, docID, rev string, _ map[string]interface{}) (string, error) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	if err := d.checkDB(); err != nil {
+		return "", err
+	}
+	current, err := d.readDoc(docID)
+	if err != nil {
+		return "", err
+	}
+	if current.deleted() {
+		return "", errDeleted
+	}
+	return d.update(docID, document{"_rev": rev, "_deleted": true})
+}
+
+// update stores doc as a new revision of the document. The caller must hold
+// d.mu for writing.
+func (d *db) update(docID string, doc document) (string, error) {
+	current, err := d.readDoc(docID)
+	if err != nil && kivik.StatusCode(err) != kivik.StatusNotFound {
+		return "", err
+	}
+	rev := doc.rev()
+	var gen int64
+	switch {
+	case current == nil:
+		if rev != "" && !isLocal(docID) {
+			return "", errConflict
+		}
+	case current.deleted() && rev == "":
+		gen, _, _ = parseRev(current.rev())
+	case rev != current.rev():
+		return "", errConflict
+	default:
+		gen, _, _ = parseRev(current.rev())
+	}
+	gen++
+	var newRev string
+	if isLocal(docID) {
+		newRev = fmt.Sprintf("0-%d", gen)
+	} else {
+		newRev = fmt.Sprintf("%d-%s", gen, revHash(current.rev(), doc))
+	}
+	return newRev, d.store(docID, newRev, doc, current, gen)
+}
+
+// replicate stores doc with its existing revision, as for new_edits=false.
+// If the stored revision wins, doc is silently discarded. The caller must
+// hold d.mu for writing.
+func (d *db) replicate(docID string, doc document) (string, error) {
+	rev := doc.rev()
+	gen, _, err := parseRev(rev)
+	if err != nil {
+		return "", err
+	}
+	current, err := d.readDoc(docID)
+	if err != nil && kivik.StatusCode(err) != kivik.StatusNotFound {
+		return "", err
+	}
+	if current != nil && !revWins(rev, current.rev()) {
+		return rev, nil
+	}
+	return rev, d.store(docID, rev, doc, current, gen)
+}
+
+func revHash(prevRev string, doc document) string {
+	body, _ := json.Marshal(doc)
+	sum := md5.Sum(append([]byte(prevRev), body...))
+	return fmt.Sprintf("%x", sum)
+}
+
+// store writes doc as revision rev, replacing current, which may be nil.
+// Attachment data is written to the sidecar directory, and replaced in doc
+// with stubs. revPos is the generation of rev, recorded for new
+// attachments. The caller must hold d.mu for writing.
+func (d *db) store(docID, rev string, doc, current document, revPos int64) error {
+	stored := document{"_id": docID, "_rev": rev}
+	if doc.deleted() {
+		stored["_deleted"] = true
+	} else {
+		for key, value := range doc {
+			if !strings.HasPrefix(key, "_") {
+				stored[key] = value
+			}
+		}
+		atts, err := d.storeAttachments(docID, doc.attachments(), current.attachments(), revPos)
+		if err != nil {
+			return err
+		}
+		if len(atts) > 0 {
+			stored["_attachments"] = atts
+		}
+	}
+	data, err := json.Marshal(stored)
+	if err != nil {
+		return err
+	}
+	if err := writeFile(d.docPath(docID), data); err != nil {
+		return err
+	}
+	if err := d.removeStaleAttachments(docID, stored.attachments()); err != nil {
+		return err
+	}
+	if isLocal(docID) {
+		return nil
+	}
+	return d.appendLog(docID, rev, stored.deleted())
+}
+
+// storeAttachments writes the content of any attachments with inline data,
+// and returns the attachment stubs to store with the document.
+func (d *db) storeAttachments(docID string, atts, currentAtts map[string]interface{}, revPos int64) (map[string]interface{}, error) {
+	stubs := make(map[string]interface{}, len(atts))
+	for filename, a := range atts {
+		att, _ := a.(map[string]interface{})
+		if stub, _ := att["stub"].(bool); stub {
+			existing, ok := currentAtts[filename]
+			if !ok {
+				return nil, errors.Statusf(kivik.StatusPreconditionFailed, "Missing attachment stub: %s", filename)
+			}
+			stubs[filename] = existing
+			continue
+		}
+		encoded, _ := att["data"].(string)
+		content, err := base64.StdEncoding.DecodeString(encoded)
+		if err != nil {
+			return nil, errors.Statusf(kivik.StatusBadRequest, "Invalid attachment data for %s", filename)
+		}
+		path, err := d.attPath(docID, filename)
+		if err != nil {
+			return nil, err
+		}
+		if err := writeFile(path, content); err != nil {
+			return nil, err
+		}
+		contentType, _ := att["content_type"].(string)
+		if contentType == "" {
+			contentType = "application/octet-stream"
+		}
+		stubs[filename] = map[string]interface{}{
+			"content_type": contentType,
+			"digest":       digest(content),
+			"length":       len(content),
+			"revpos":       revPos,
+			"stub":         true,
+		}
+	}
+	return stubs, nil
+}
+
+func digest(content []byte) string {
+	sum := md5.Sum(content)
+	return "md5-" + base64.StdEncoding.EncodeToString(sum[:])
+}
+
+// removeStaleAttachments removes attachment files no longer referenced by
+// the document.
+func (d *db) removeStaleAttachments(docID string, atts map[string]interface{}) error {
+	dir := filepath.Join(d.dir, url.PathEscape(docID)+attDirExt)
+	files, err := ioutil.ReadDir(dir)
+	if os.IsNotExist(err) {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+	for _, file := range files {
+		filename, err := url.PathUnescape(file.Name())
+		if err != nil {
+			continue
+		}
+		if _, ok := atts[filename]; !ok {
+			if err := os.Remove(filepath.Join(dir, file.Name())); err != nil {
+				return err
+			}
+		}
+	}
+	if len(atts) == 0 {
+		return os.Remove(dir)
+	}
+	return nil
+}
+
+func (d *db) Stats(_ context.Context) (*driver.DBStats, error) {
+	d.mu.RLock()
+	defer d.mu.RUnlock()
+	if err := d.checkDB(); err != nil {
+		return nil, err
+	}
+	stats := &driver.DBStats{Name: d.name}
+	err := filepath.Walk(d.dir, func(path string, info os.FileInfo, err error) error {
+		if err != nil || info.IsDir() {
+			return err
+		}
+		stats.DiskSize += info.Size()
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	docs, err := d.docs()
+	if err != nil {
+		return nil, err
+	}
+	for _, doc := range docs {
+		if doc.deleted() {
+			stats.DeletedCount++
+			continue
+		}
+		stats.DocCount++
+		body, _ := json.Marshal(doc)
+		stats.ExternalSize += int64(len(body))
+	}
+	seq, err := d.updateSeq()
+	if err != nil {
+		return nil, err
+	}
+	stats.UpdateSeq = driver.SequenceID(strconv.FormatInt(seq, 10))
+	stats.ActiveSize = stats.DiskSize
+	return stats, nil
+}
+
+// Compact removes superseded entries from the changes log.
+func (d *db) Compact(_ context.Context) error {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	if err := d.checkDB(); err != nil {
+		return err
+	}
+	entries, err := d.readLog(0)
+	if err != nil {
+		return err
+	}
+	var buf bytes.Buffer
+	enc := json.NewEncoder(&buf)
+	for _, entry := range latest(entries) {
+		if err := enc.Encode(entry); err != nil {
+			return err
+		}
+	}
+	return writeFile(d.logPath(), buf.Bytes())
+}
+
+// CompactView does nothing, as views are not supported.
+func (d *db) CompactView(_ context.Context, _ string) error {
+	return nil
+}
+
+// ViewCleanup does nothing, as views are not supported.
+func (d *db) ViewCleanup(_ context.Context) error {
+	return nil
+}
+
+func (d *db) Security(_ context.Context) (*driver.Security, error) {
+	d.mu.RLock()
+	defer d.mu.RUnlock()
+	if err := d.checkDB(); err != nil {
+		return nil, err
+	}
+	sec := &driver.Security{}
+	data, err := ioutil.ReadFile(filepath.Join(d.dir, securityDoc))
+	if os.IsNotExist(err) {
+		return sec, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	return sec, json.Unmarshal(data, sec)
+}
+
+func (d *db) SetSecurity(_ context.Context, security *driver.Security) error {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	if err := d.checkDB(); err != nil {
+		return err
+	}
+	data, err := json.Marshal(security)
+	if err != nil {
+		return err
+	}
+	return writeFile(filepath.Join(d.dir, securityDoc), data)
+}
+
+func (d *db) Query(_ context.Context, _, _ string, _ map[string]interface{}) (driver.Rows, error) {
+	return nil, errors.Status(kivik.StatusNotImplemented, "fs: views are not supported")
+}
+
+func (d *db) RevsDiff(_ context.Context, revMap interface{}) (driver.Rows, error) {
+	data, err := json.Marshal(revMap)
+	if err != nil {
+		return nil, errors.WrapStatus(kivik.StatusBadRequest, err)
+	}
+	var revs map[string][]string
+	if err := json.Unmarshal(data, &revs); err != nil {
+		return nil, errors.WrapStatus(kivik.StatusBadRequest, err)
+	}
+	d.mu.RLock()
+	defer d.mu.RUnlock()
+	if err := d.checkDB(); err != nil {
+		return nil, err
+	}
+	ids := make([]string, 0, len(revs))
+	for id := range revs {
+		ids = append(ids, id)
+	}
+	sort.Strings(ids)
+	var rows []*driver.Row
+	for _, id := range ids {
+		var current string
+		if doc, err := d.readDoc(id); err == nil {
+			current = doc.rev()
+		}
+		var missing []string
+		for _, rev := range revs[id] {
+			if rev != current {
+				missing = append(missing, rev)
+			}
+		}
+		if len(missing) == 0 {
+			continue
+		}
+		value, _ := json.Marshal(map[string][]string{"missing": missing})
+		rows = append(rows, &driver.Row{ID: id, Value: value})
+	}
+	return &rowsIter{rows: rows}, nil
+}
@@ -0,0 +1,162 @@
+package fs
+
+import (
+	"context"
+	"crypto/md5"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/go-kivik/kivik"
+	"github.com/go-kivik/kivik/driver"
+	"github.com/go-kivik/kivik/driver/internal/docquery"
+	"github.com/go-kivik/kivik/errors"
+)
+
+var _ driver.Finder = &db{}
+
+// Find evaluates the query's selector against every document in the
+// database, other than design documents.
+func (d *db) Find(_ context.Context, query interface{}) (driver.Rows, error) {
+	q, err := docquery.ParseFind(query)
+	if err != nil {
+		return nil, err
+	}
+	d.mu.RLock()
+	docs, err := d.docs()
+	d.mu.RUnlock()
+	if err != nil {
+		return nil, err
+	}
+	matches := make([]map[string]interface{}, 0, len(docs))
+	for id, doc := range docs {
+		if doc.deleted() || strings.HasPrefix(id, "_design/") {
+			continue
+		}
+		if q.Match(doc) {
+			matches = append(matches, doc)
+		}
+	}
+	rows, bookmark, err := q.Rows(matches)
+	if err != nil {
+		return nil, err
+	}
+	return &rowsIter{
+		rows:     rows,
+		warning:  docquery.NoIndexWarning,
+		bookmark: bookmark,
+	}, nil
+}
+
+func (d *db) indexesPath() string {
+	return filepath.Join(d.dir, indexesDoc)
+}
+
+// readIndexes returns the stored index definitions. The caller must hold
+// d.mu.
+func (d *db) readIndexes() ([]driver.Index, error) {
+	data, err := ioutil.ReadFile(d.indexesPath())
+	if os.IsNotExist(err) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	var indexes []driver.Index
+	return indexes, json.Unmarshal(data, &indexes)
+}
+
+// CreateIndex records an index definition. Indexes are reported by
+// GetIndexes, but are not used by Find.
+func (d *db) CreateIndex(_ context.Context, ddoc, name string, index interface{}) error {
+	data, err := docquery.ToJSON(index)
+	if err != nil {
+		return err
+	}
+	var def map[string]interface{}
+	if err := json.Unmarshal(data, &def); err != nil {
+		return errors.WrapStatus(kivik.StatusBadRequest, err)
+	}
+	if _, ok := def["fields"].([]interface{}); !ok {
+		return errors.Status(kivik.StatusBadRequest, "fs: index must include fields")
+	}
+	hash := fmt.Sprintf("%x", md5.Sum(data))
+	if name == "" {
+		name = hash
+	}
+	if ddoc == "" {
+		ddoc = hash
+	}
+	if !strings.HasPrefix(ddoc, "_design/") {
+		ddoc = "_design/" + ddoc
+	}
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	if err := d.checkDB(); err != nil {
+		return err
+	}
+	indexes, err := d.readIndexes()
+	if err != nil {
+		return err
+	}
+	for _, idx := range indexes {
+		if idx.DesignDoc == ddoc && idx.Name == name {
+			return nil
+		}
+	}
+	indexes = append(indexes, driver.Index{DesignDoc: ddoc, Name: name, Type: "json", Definition: def})
+	if data, err = json.Marshal(indexes); err != nil {
+		return err
+	}
+	return writeFile(d.indexesPath(), data)
+}
+
+func (d *db) GetIndexes(_ context.Context) ([]driver.Index, error) {
+	d.mu.RLock()
+	defer d.mu.RUnlock()
+	if err := d.checkDB(); err != nil {
+		return nil, err
+	}
+	indexes, err := d.readIndexes()
+	if err != nil {
+		return nil, err
+	}
+	return append([]driver.Index{docquery.AllDocsIndex}, indexes...), nil
+}
+
+func (d *db) DeleteIndex(_ context.Context, ddoc, name string) error {
+	if !strings.HasPrefix(ddoc, "_design/") {
+		ddoc = "_design/" + ddoc
+	}
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	if err := d.checkDB(); err != nil {
+		return err
+	}
+	indexes, err := d.readIndexes()
+	if err != nil {
+		return err
+	}
+	for i, idx := range indexes {
+		if idx.DesignDoc == ddoc && idx.Name == name {
+			data, err := json.Marshal(append(indexes[:i], indexes[i+1:]...))
+			if err != nil {
+				return err
+			}
+			return writeFile(d.indexesPath(), data)
+		}
+	}
+	return errors.Status(kivik.StatusNotFound, "Index not found")
+}
+
+// Explain reports that every query is answered by scanning all documents.
+func (d *db) Explain(_ context.Context, query interface{}) (*driver.QueryPlan, error) {
+	q, err := docquery.ParseFind(query)
+	if err != nil {
+		return nil, err
+	}
+	return q.Plan(d.name), nil
+}
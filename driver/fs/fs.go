@@ -0,0 +1,186 @@
+// Package fs provides a kivik driver which stores each database as a
+// directory, and each document as a JSON file within it. Fixture databases
+// may thus be kept under version control, and simple embedded applications
+// may use kivik without a server.
+//
+// The data source name is the path of an existing root directory:
+//
+//	client, err := kivik.New(ctx, "fs", "/path/to/root")
+//
+// Each database is a subdirectory of the root. Each document is stored as
+// <docid>.json, and its attachments as files in a sidecar directory,
+// <docid>.att. Database names must be valid CouchDB database names. Database
+// names, document IDs and attachment filenames are escaped to form valid file
+// names. Every update is appended to a changes
+// log, _changes.log, from which the update sequence and changes feed are
+// derived.
+//
+// Only the winning revision of each document is stored, so there is no
+// revision history, and no conflicts are kept. Find evaluates selectors
+// against every document, with the mango package; indexes may be created,
+// but are not used. Views are not supported.
+//
+// A Client is safe for concurrent use, but a database must not be written
+// by more than one process at a time.
+package fs
+
+import (
+	"context"
+	"io/ioutil"
+	"net/url"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"sync"
+
+	"github.com/go-kivik/kivik"
+	"github.com/go-kivik/kivik/driver"
+	"github.com/go-kivik/kivik/errors"
+)
+
+// Version is the version reported by the driver.
+const Version = "0.0.1"
+
+// Vendor is the vendor string reported by the driver.
+const Vendor = "Kivik File System"
+
+func init() {
+	kivik.Register("fs", &fsDriver{})
+}
+
+type fsDriver struct{}
+
+var _ driver.Driver = &fsDriver{}
+
+func (d *fsDriver) NewClient(_ context.Context, root string) (driver.Client, error) {
+	info, err := os.Stat(root)
+	if err != nil {
+		return nil, errors.WrapStatus(kivik.StatusBadRequest, err)
+	}
+	if !info.IsDir() {
+		return nil, errors.Statusf(kivik.StatusBadRequest, "fs: %s is not a directory", root)
+	}
+	return &client{
+		root:  root,
+		locks: make(map[string]*sync.RWMutex),
+	}, nil
+}
+
+type client struct {
+	root string
+
+	mu    sync.Mutex
+	locks map[string]*sync.RWMutex
+}
+
+var _ driver.Client = &client{}
+
+// lock returns the lock which guards the named database.
+func (c *client) lock(dbName string) *sync.RWMutex {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	l, ok := c.locks[dbName]
+	if !ok {
+		l = &sync.RWMutex{}
+		c.locks[dbName] = l
+	}
+	return l
+}
+
+// validDBName matches the database names permitted by CouchDB. Besides
+// compatibility, this ensures that a name cannot refer to the root directory,
+// or its parent, such as "." or "..".
+var validDBName = regexp.MustCompile(`^[a-z][a-z0-9_$()+/-]*$`)
+
+// dbDir returns the directory of the named database, or an error if the name
+// is invalid.
+func (c *client) dbDir(dbName string) (string, error) {
+	if !validDBName.MatchString(dbName) {
+		return "", errors.Statusf(kivik.StatusBadRequest, "fs: illegal database name: %s", dbName)
+	}
+	return filepath.Join(c.root, url.PathEscape(dbName)), nil
+}
+
+func (c *client) Version(_ context.Context) (*driver.Version, error) {
+	return &driver.Version{
+		Version: Version,
+		Vendor:  Vendor,
+	}, nil
+}
+
+func (c *client) AllDBs(_ context.Context, _ map[string]interface{}) ([]string, error) {
+	files, err := ioutil.ReadDir(c.root)
+	if err != nil {
+		return nil, err
+	}
+	dbs := make([]string, 0, len(files))
+	for _, file := range files {
+		if !file.IsDir() {
+			continue
+		}
+		name, err := url.PathUnescape(file.Name())
+		if err != nil {
+			continue
+		}
+		dbs = append(dbs, name)
+	}
+	sort.Strings(dbs)
+	return dbs, nil
+}
+
+func (c *client) DBExists(_ context.Context, dbName string, _ map[string]interface{}) (bool, error) {
+	dir, err := c.dbDir(dbName)
+	if err != nil {
+		return false, err
+	}
+	info, err := os.Stat(dir)
+	if os.IsNotExist(err) {
+		return false, nil
+	}
+	if err != nil {
+		return false, err
+	}
+	return info.IsDir(), nil
+}
+
+func (c *client) CreateDB(_ context.Context, dbName string, _ map[string]interface{}) error {
+	dir, err := c.dbDir(dbName)
+	if err != nil {
+		return err
+	}
+	l := c.lock(dbName)
+	l.Lock()
+	defer l.Unlock()
+	err = os.Mkdir(dir, 0777)
+	if os.IsExist(err) {
+		return errors.Status(kivik.StatusPreconditionFailed, "The database could not be created, the file already exists.")
+	}
+	return err
+}
+
+func (c *client) DestroyDB(_ context.Context, dbName string, _ map[string]interface{}) error {
+	dir, err := c.dbDir(dbName)
+	if err != nil {
+		return err
+	}
+	l := c.lock(dbName)
+	l.Lock()
+	defer l.Unlock()
+	if _, err := os.Stat(dir); os.IsNotExist(err) {
+		return errDBNotFound
+	}
+	return os.RemoveAll(dir)
+}
+
+func (c *client) DB(_ context.Context, dbName string, _ map[string]interface{}) (driver.DB, error) {
+	dir, err := c.dbDir(dbName)
+	if err != nil {
+		return nil, err
+	}
+	return &db{
+		name: dbName,
+		dir:  dir,
+		mu:   c.lock(dbName),
+	}, nil
+}
@@ -0,0 +1,396 @@
+package fs
+
+import (
+	"context"
+	"io/ioutil"
+	"os"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/flimzy/diff"
+	"github.com/go-kivik/kivik"
+)
+
+// newTestDB returns a client and database in a new temporary directory, and
+// a function to remove the directory.
+func newTestDB(t *testing.T) (*kivik.Client, *kivik.DB, func()) {
+	dir, err := ioutil.TempDir("", "kivik-fs-")
+	if err != nil {
+		t.Fatal(err)
+	}
+	cleanup := func() { _ = os.RemoveAll(dir) }
+	client, err := kivik.New(context.Background(), "fs", dir)
+	if err != nil {
+		cleanup()
+		t.Fatal(err)
+	}
+	db, err := client.CreateDB(context.Background(), "test/db")
+	if err != nil {
+		cleanup()
+		t.Fatal(err)
+	}
+	return client, db, cleanup
+}
+
+func TestNewClient(t *testing.T) {
+	_, err := kivik.New(context.Background(), "fs", "/does/not/exist")
+	if status := kivik.StatusCode(err); status != kivik.StatusBadRequest {
+		t.Errorf("Unexpected status: %d (%s)", status, err)
+	}
+}
+
+func TestDatabases(t *testing.T) {
+	client, _, cleanup := newTestDB(t)
+	defer cleanup()
+	ctx := context.Background()
+	if _, err := client.CreateDB(ctx, "test/db"); kivik.StatusCode(err) != kivik.StatusPreconditionFailed {
+		t.Errorf("Unexpected error creating duplicate database: %v", err)
+	}
+	if _, err := client.CreateDB(ctx, "another"); err != nil {
+		t.Fatal(err)
+	}
+	dbs, err := client.AllDBs(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if d := diff.Interface([]string{"another", "test/db"}, dbs); d != nil {
+		t.Error(d)
+	}
+	if err := client.DestroyDB(ctx, "another"); err != nil {
+		t.Fatal(err)
+	}
+	if err := client.DestroyDB(ctx, "another"); kivik.StatusCode(err) != kivik.StatusNotFound {
+		t.Errorf("Unexpected error destroying missing database: %v", err)
+	}
+	if exists, _ := client.DBExists(ctx, "another"); exists {
+		t.Error("Destroyed database still exists")
+	}
+}
+
+func TestInvalidNames(t *testing.T) {
+	client, db, cleanup := newTestDB(t)
+	defer cleanup()
+	ctx := context.Background()
+	for _, name := range []string{".", "..", "Upper", "_users", ""} {
+		if _, err := client.CreateDB(ctx, name); kivik.StatusCode(err) != kivik.StatusBadRequest {
+			t.Errorf("Unexpected error creating %q: %v", name, err)
+		}
+		if err := client.DestroyDB(ctx, name); kivik.StatusCode(err) != kivik.StatusBadRequest {
+			t.Errorf("Unexpected error destroying %q: %v", name, err)
+		}
+		if _, err := client.DBExists(ctx, name); kivik.StatusCode(err) != kivik.StatusBadRequest {
+			t.Errorf("Unexpected error checking %q: %v", name, err)
+		}
+		if _, err := client.DB(ctx, name); kivik.StatusCode(err) != kivik.StatusBadRequest {
+			t.Errorf("Unexpected error opening %q: %v", name, err)
+		}
+	}
+	for _, filename := range []string{".", ".."} {
+		_, err := db.PutAttachment(ctx, "foo", "", &kivik.Attachment{
+			Filename:    filename,
+			ContentType: "text/plain",
+			Content:     ioutil.NopCloser(strings.NewReader("Hello")),
+		})
+		if kivik.StatusCode(err) != kivik.StatusBadRequest {
+			t.Errorf("Unexpected error storing attachment %q: %v", filename, err)
+		}
+	}
+	if exists, err := client.DBExists(ctx, "test/db"); err != nil || !exists {
+		t.Errorf("Database damaged: %v", err)
+	}
+}
+
+func TestCRUD(t *testing.T) {
+	_, db, cleanup := newTestDB(t)
+	defer cleanup()
+	ctx := context.Background()
+	rev, err := db.Put(ctx, "foo", map[string]interface{}{"value": 1})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !strings.HasPrefix(rev, "1-") {
+		t.Errorf("Unexpected rev: %s", rev)
+	}
+	if _, err := db.Put(ctx, "foo", map[string]interface{}{"value": 2}); kivik.StatusCode(err) != kivik.StatusConflict {
+		t.Errorf("Expected conflict, got %v", err)
+	}
+	rev2, err := db.Put(ctx, "foo", map[string]interface{}{"_rev": rev, "value": 2})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !strings.HasPrefix(rev2, "2-") {
+		t.Errorf("Unexpected rev: %s", rev2)
+	}
+	var doc map[string]interface{}
+	if err := db.Get(ctx, "foo").ScanDoc(&doc); err != nil {
+		t.Fatal(err)
+	}
+	expected := map[string]interface{}{"_id": "foo", "_rev": rev2, "value": 2.0}
+	if d := diff.Interface(expected, doc); d != nil {
+		t.Error(d)
+	}
+	if _, err := db.Delete(ctx, "foo", rev2); err != nil {
+		t.Fatal(err)
+	}
+	if err := db.Get(ctx, "foo").Err; kivik.StatusCode(err) != kivik.StatusNotFound {
+		t.Errorf("Expected deleted document, got %v", err)
+	}
+	docID, _, err := db.CreateDoc(ctx, map[string]interface{}{"value": 3})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if docID == "" {
+		t.Error("Expected generated document ID")
+	}
+}
+
+func TestAllDocs(t *testing.T) {
+	_, db, cleanup := newTestDB(t)
+	defer cleanup()
+	ctx := context.Background()
+	for _, id := range []string{"c", "a", "d", "b"} {
+		if _, err := db.Put(ctx, id, map[string]interface{}{}); err != nil {
+			t.Fatal(err)
+		}
+	}
+	tests := []struct {
+		name     string
+		options  kivik.Options
+		expected []string
+		offset   int64
+	}{
+		{
+			name:     "all",
+			expected: []string{"a", "b", "c", "d"},
+		},
+		{
+			name:     "descending",
+			options:  kivik.Options{"descending": true},
+			expected: []string{"d", "c", "b", "a"},
+		},
+		{
+			name:     "range",
+			options:  kivik.Options{"startkey": `"b"`, "endkey": `"c"`},
+			expected: []string{"b", "c"},
+			offset:   1,
+		},
+		{
+			name:     "exclusive end",
+			options:  kivik.Options{"startkey": `"b"`, "endkey": `"d"`, "inclusive_end": false},
+			expected: []string{"b", "c"},
+			offset:   1,
+		},
+		{
+			name:     "skip and limit",
+			options:  kivik.Options{"skip": 1, "limit": 2},
+			expected: []string{"b", "c"},
+			offset:   1,
+		},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			rows, err := db.AllDocs(ctx, test.options)
+			if err != nil {
+				t.Fatal(err)
+			}
+			var ids []string
+			for rows.Next() {
+				ids = append(ids, rows.ID())
+			}
+			if err := rows.Err(); err != nil {
+				t.Fatal(err)
+			}
+			if d := diff.Interface(test.expected, ids); d != nil {
+				t.Error(d)
+			}
+			if rows.Offset() != test.offset {
+				t.Errorf("Unexpected offset: %d", rows.Offset())
+			}
+			if rows.TotalRows() != 4 {
+				t.Errorf("Unexpected total rows: %d", rows.TotalRows())
+			}
+		})
+	}
+}
+
+func TestChanges(t *testing.T) {
+	_, db, cleanup := newTestDB(t)
+	defer cleanup()
+	ctx := context.Background()
+	rev, err := db.Put(ctx, "a", map[string]interface{}{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := db.Put(ctx, "b", map[string]interface{}{}); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := db.Delete(ctx, "a", rev); err != nil {
+		t.Fatal(err)
+	}
+	changes, err := db.Changes(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var ids []string
+	for changes.Next() {
+		ids = append(ids, changes.ID())
+		if changes.ID() == "a" && !changes.Deleted() {
+			t.Error("Expected a to be deleted")
+		}
+	}
+	if err := changes.Err(); err != nil {
+		t.Fatal(err)
+	}
+	if d := diff.Interface([]string{"b", "a"}, ids); d != nil {
+		t.Error(d)
+	}
+	if changes.LastSeq() != "3" {
+		t.Errorf("Unexpected last seq: %s", changes.LastSeq())
+	}
+
+	t.Run("limit", func(t *testing.T) {
+		changes, err := db.Changes(ctx, kivik.Options{"since": "1", "limit": 1})
+		if err != nil {
+			t.Fatal(err)
+		}
+		var ids []string
+		for changes.Next() {
+			ids = append(ids, changes.ID())
+		}
+		if d := diff.Interface([]string{"b"}, ids); d != nil {
+			t.Error(d)
+		}
+		if changes.LastSeq() != "2" {
+			t.Errorf("Unexpected last seq: %s", changes.LastSeq())
+		}
+	})
+
+	t.Run("longpoll", func(t *testing.T) {
+		changes, err := db.Changes(ctx, kivik.Options{"feed": "longpoll", "since": "now"})
+		if err != nil {
+			t.Fatal(err)
+		}
+		go func() {
+			time.Sleep(50 * time.Millisecond)
+			_, _ = db.Put(ctx, "c", map[string]interface{}{})
+		}()
+		var ids []string
+		for changes.Next() {
+			ids = append(ids, changes.ID())
+		}
+		if err := changes.Err(); err != nil {
+			t.Fatal(err)
+		}
+		if d := diff.Interface([]string{"c"}, ids); d != nil {
+			t.Error(d)
+		}
+	})
+}
+
+func TestAttachments(t *testing.T) {
+	_, db, cleanup := newTestDB(t)
+	defer cleanup()
+	ctx := context.Background()
+	rev, err := db.PutAttachment(ctx, "foo", "", &kivik.Attachment{
+		Filename:    "foo.txt",
+		ContentType: "text/plain",
+		Content:     ioutil.NopCloser(strings.NewReader("Hello")),
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	rev, err = db.Put(ctx, "foo", map[string]interface{}{
+		"_rev":         rev,
+		"value":        1,
+		"_attachments": map[string]interface{}{"foo.txt": map[string]interface{}{"stub": true}},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	att, err := db.GetAttachment(ctx, "foo", rev, "foo.txt")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer att.Content.Close() // nolint: errcheck
+	content, err := ioutil.ReadAll(att.Content)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(content) != "Hello" {
+		t.Errorf("Unexpected content: %s", content)
+	}
+	if att.ContentType != "text/plain" {
+		t.Errorf("Unexpected content type: %s", att.ContentType)
+	}
+	if _, err := db.DeleteAttachment(ctx, "foo", rev, "foo.txt"); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := db.GetAttachment(ctx, "foo", "", "foo.txt"); kivik.StatusCode(err) != kivik.StatusNotFound {
+		t.Errorf("Expected missing attachment, got %v", err)
+	}
+}
+
+func TestFind(t *testing.T) {
+	_, db, cleanup := newTestDB(t)
+	defer cleanup()
+	ctx := context.Background()
+	for id, age := range map[string]int{"alice": 30, "bob": 25, "carol": 35, "dave": 20} {
+		if _, err := db.Put(ctx, id, map[string]interface{}{"age": age, "name": id}); err != nil {
+			t.Fatal(err)
+		}
+	}
+	tests := []struct {
+		name     string
+		query    string
+		expected []string
+		status   int
+	}{
+		{
+			name:     "selector",
+			query:    `{"selector":{"age":{"$gt":22}}}`,
+			expected: []string{"alice", "bob", "carol"},
+		},
+		{
+			name:     "sort",
+			query:    `{"selector":{"age":{"$gt":22}},"sort":[{"age":"desc"}]}`,
+			expected: []string{"carol", "alice", "bob"},
+		},
+		{
+			name:     "skip and limit",
+			query:    `{"selector":{},"sort":["age"],"skip":1,"limit":2}`,
+			expected: []string{"bob", "alice"},
+		},
+		{
+			name:   "missing selector",
+			query:  `{}`,
+			status: kivik.StatusBadRequest,
+		},
+		{
+			name:   "invalid selector",
+			query:  `{"selector":{"age":{"$foo":1}}}`,
+			status: kivik.StatusBadRequest,
+		},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			rows, err := db.Find(ctx, test.query)
+			if status := kivik.StatusCode(err); status != test.status && (err != nil || test.status != 0) {
+				t.Fatalf("Unexpected status: %d (%v)", status, err)
+			}
+			if err != nil {
+				return
+			}
+			var ids []string
+			for rows.Next() {
+				ids = append(ids, rows.ID())
+			}
+			if err := rows.Err(); err != nil {
+				t.Fatal(err)
+			}
+			if d := diff.Interface(test.expected, ids); d != nil {
+				t.Error(d)
+			}
+		})
+	}
+}
@@ -0,0 +1,16 @@
+package driver
+
+import "context"
+
+// GeoQuerier is an optional interface which may be implemented by a DB, to
+// support geospatial index queries, as provided by Cloudant Geo.
+type GeoQuerier interface {
+	// Geo queries the geospatial index named index, in design document ddoc.
+	// The query shape is given in options, as the bbox, lat/lon/radius, or g
+	// query parameters. The ID of each row should be set to the document ID,
+	// and the Value to the raw JSON result row, including its "geometry" key.
+	// The Doc should be set if include_docs was requested.
+	//
+	// The returned Rows should implement Bookmarker.
+	Geo(ctx context.Context, ddoc, index string, options map[string]interface{}) (Rows, error)
+}
@@ -0,0 +1,85 @@
+package docquery
+
+// AllDocs holds the parsed options of an AllDocs request.
+type AllDocs struct {
+	Limit, Skip      int64
+	Descending       bool
+	IncludeDocs      bool
+	Conflicts        bool
+	InclusiveEnd     bool
+	UpdateSeq        bool
+	StartKey, EndKey string
+	HasStart, HasEnd bool
+	Keys             []string
+	HasKeys          bool
+}
+
+// ParseAllDocs parses the options of an AllDocs request.
+func ParseAllDocs(opts map[string]interface{}) (*AllDocs, error) {
+	q := &AllDocs{
+		Descending:   BoolOpt(opts, "descending"),
+		IncludeDocs:  BoolOpt(opts, "include_docs"),
+		Conflicts:    BoolOpt(opts, "conflicts"),
+		InclusiveEnd: true,
+		UpdateSeq:    BoolOpt(opts, "update_seq"),
+	}
+	if _, ok := opts["inclusive_end"]; ok {
+		q.InclusiveEnd = BoolOpt(opts, "inclusive_end")
+	}
+	var err error
+	if q.Limit, err = IntOpt(opts, "limit"); err != nil {
+		return nil, err
+	}
+	if q.Skip, err = IntOpt(opts, "skip"); err != nil {
+		return nil, err
+	}
+	if q.Keys, q.HasKeys, err = KeysOpt(opts); err != nil {
+		return nil, err
+	}
+	key, hasKey, err := KeyOpt(opts, "key")
+	if err != nil {
+		return nil, err
+	}
+	if hasKey {
+		q.Keys, q.HasKeys = []string{key}, true
+	}
+	if q.StartKey, q.HasStart, err = KeyOpt(opts, "startkey", "start_key"); err != nil {
+		return nil, err
+	}
+	if q.EndKey, q.HasEnd, err = KeyOpt(opts, "endkey", "end_key"); err != nil {
+		return nil, err
+	}
+	return q, nil
+}
+
+// BeforeStart reports whether id precedes the start key, in the direction of
+// iteration.
+func (q *AllDocs) BeforeStart(id string) bool {
+	if !q.HasStart {
+		return false
+	}
+	if q.Descending {
+		return id > q.StartKey
+	}
+	return id < q.StartKey
+}
+
+// PastEnd reports whether id follows the end key, in the direction of
+// iteration.
+func (q *AllDocs) PastEnd(id string) bool {
+	if !q.HasEnd {
+		return false
+	}
+	if id == q.EndKey {
+		return !q.InclusiveEnd
+	}
+	if q.Descending {
+		return id < q.EndKey
+	}
+	return id > q.EndKey
+}
+
+// InRange reports whether id falls within the start and end keys.
+func (q *AllDocs) InRange(id string) bool {
+	return !q.BeforeStart(id) && !q.PastEnd(id)
+}
@@ -0,0 +1,92 @@
+package docquery
+
+import (
+	"testing"
+
+	"github.com/flimzy/diff"
+	"github.com/go-kivik/kivik"
+)
+
+func TestAllDocsInRange(t *testing.T) {
+	ids := []string{"a", "b", "c", "d"}
+	tests := []struct {
+		name     string
+		opts     map[string]interface{}
+		expected []string
+	}{
+		{
+			name:     "no range",
+			expected: []string{"a", "b", "c", "d"},
+		},
+		{
+			name:     "start and end",
+			opts:     map[string]interface{}{"startkey": `"b"`, "endkey": "c"},
+			expected: []string{"b", "c"},
+		},
+		{
+			name:     "exclusive end",
+			opts:     map[string]interface{}{"start_key": "b", "end_key": "c", "inclusive_end": false},
+			expected: []string{"b"},
+		},
+		{
+			name:     "descending",
+			opts:     map[string]interface{}{"startkey": "c", "endkey": "b", "descending": true, "inclusive_end": "false"},
+			expected: []string{"c"},
+		},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			q, err := ParseAllDocs(test.opts)
+			if err != nil {
+				t.Fatal(err)
+			}
+			var result []string
+			for _, id := range ids {
+				if q.InRange(id) {
+					result = append(result, id)
+				}
+			}
+			if d := diff.Interface(test.expected, result); d != nil {
+				t.Error(d)
+			}
+		})
+	}
+}
+
+func TestFindRows(t *testing.T) {
+	docs := func() []map[string]interface{} {
+		return []map[string]interface{}{
+			{"_id": "alice", "age": 30.0},
+			{"_id": "bob", "age": 25.0},
+			{"_id": "carol", "age": 35.0},
+		}
+	}
+	q, err := ParseFind(`{"selector":{},"sort":[{"age":"desc"}],"limit":2,"fields":["_id"]}`)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var ids []string
+	rows, bookmark, err := q.Rows(docs())
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, row := range rows {
+		ids = append(ids, row.ID)
+	}
+	q, err = ParseFind(map[string]interface{}{"selector": map[string]interface{}{}, "sort": []interface{}{map[string]interface{}{"age": "desc"}}, "bookmark": bookmark})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if rows, _, err = q.Rows(docs()); err != nil {
+		t.Fatal(err)
+	}
+	for _, row := range rows {
+		ids = append(ids, row.ID)
+	}
+	if d := diff.Interface([]string{"carol", "alice", "bob"}, ids); d != nil {
+		t.Error(d)
+	}
+	if _, err := ParseFind(`{"selector":{},"bookmark":"invalid"}`); kivik.StatusCode(err) != kivik.StatusBadRequest {
+		t.Errorf("Unexpected error: %v", err)
+	}
+}
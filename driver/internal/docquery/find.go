@@ -0,0 +1,226 @@
+package docquery
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/go-kivik/kivik"
+	"github.com/go-kivik/kivik/driver"
+	"github.com/go-kivik/kivik/errors"
+	"github.com/go-kivik/kivik/mango"
+)
+
+// DefaultFindLimit is the number of results returned by Find, if no limit is
+// given, as for CouchDB.
+const DefaultFindLimit = 25
+
+// NoIndexWarning is the warning returned with the results of every Find, as
+// queries are answered by scanning all documents.
+const NoIndexWarning = "No matching index found, create an index to optimize query time."
+
+// AllDocsIndex is the special index reported by GetIndexes, and used by every
+// query.
+var AllDocsIndex = driver.Index{
+	Name: "_all_docs",
+	Type: "special",
+	Definition: map[string]interface{}{
+		"fields": []interface{}{map[string]interface{}{"_id": "asc"}},
+	},
+}
+
+// Find is a parsed Find query.
+type Find struct {
+	Selector map[string]interface{} `json:"selector"`
+	Fields   []string               `json:"fields"`
+	Sort     []interface{}          `json:"sort"`
+	Limit    *int64                 `json:"limit"`
+	Skip     int64                  `json:"skip"`
+	Bookmark string                 `json:"bookmark"`
+
+	matcher    *mango.Matcher
+	sortFields []sortField
+	start      int64
+}
+
+type sortField struct {
+	path []string
+	desc bool
+}
+
+// ParseFind parses and validates a Find query.
+func ParseFind(query interface{}) (*Find, error) {
+	data, err := ToJSON(query)
+	if err != nil {
+		return nil, err
+	}
+	q := &Find{}
+	if err := json.Unmarshal(data, q); err != nil {
+		return nil, errors.WrapStatus(kivik.StatusBadRequest, err)
+	}
+	if q.Selector == nil {
+		return nil, errors.Status(kivik.StatusBadRequest, "kivik: query must include a selector")
+	}
+	if q.matcher, err = mango.Compile(q.Selector); err != nil {
+		return nil, errors.WrapStatus(kivik.StatusBadRequest, err)
+	}
+	q.sortFields = make([]sortField, 0, len(q.Sort))
+	for _, s := range q.Sort {
+		switch t := s.(type) {
+		case string:
+			q.sortFields = append(q.sortFields, sortField{path: splitField(t)})
+		case map[string]interface{}:
+			for field, dir := range t {
+				q.sortFields = append(q.sortFields, sortField{path: splitField(field), desc: dir == "desc"})
+			}
+		default:
+			return nil, errors.Status(kivik.StatusBadRequest, "kivik: invalid sort field")
+		}
+	}
+	if q.start, err = decodeBookmark(q.Bookmark); err != nil {
+		return nil, err
+	}
+	return q, nil
+}
+
+// Match reports whether doc matches the selector, and has every sort field,
+// as CouchDB omits documents without them.
+func (q *Find) Match(doc map[string]interface{}) bool {
+	for _, field := range q.sortFields {
+		if _, ok := lookupField(doc, field.path); !ok {
+			return false
+		}
+	}
+	return q.matcher.Match(doc)
+}
+
+// Rows sorts the matching documents, applies the bookmark, skip and limit,
+// and returns the resulting rows, along with the bookmark for the next page.
+// Each document must include its _id.
+func (q *Find) Rows(matches []map[string]interface{}) ([]*driver.Row, string, error) {
+	sort.Slice(matches, func(i, j int) bool {
+		for _, field := range q.sortFields {
+			a, _ := lookupField(matches[i], field.path)
+			b, _ := lookupField(matches[j], field.path)
+			if c := mango.Compare(a, b); c != 0 {
+				return (c < 0) != field.desc
+			}
+		}
+		return matches[i]["_id"].(string) < matches[j]["_id"].(string)
+	})
+	start := q.start + q.Skip
+	if start > int64(len(matches)) {
+		start = int64(len(matches))
+	}
+	matches = matches[start:]
+	if limit := q.limit(); limit < int64(len(matches)) {
+		matches = matches[:limit]
+	}
+	rows := make([]*driver.Row, len(matches))
+	for i, doc := range matches {
+		id := doc["_id"].(string)
+		if len(q.Fields) > 0 {
+			doc = project(doc, q.Fields)
+		}
+		body, err := json.Marshal(doc)
+		if err != nil {
+			return nil, "", err
+		}
+		rows[i] = &driver.Row{ID: id, Doc: body}
+	}
+	return rows, encodeBookmark(start + int64(len(rows))), nil
+}
+
+func (q *Find) limit() int64 {
+	if q.Limit != nil {
+		return *q.Limit
+	}
+	return DefaultFindLimit
+}
+
+// Plan returns the query plan for q, which always scans AllDocsIndex.
+func (q *Find) Plan(dbName string) *driver.QueryPlan {
+	fields := make([]interface{}, len(q.Fields))
+	for i, field := range q.Fields {
+		fields[i] = field
+	}
+	return &driver.QueryPlan{
+		DBName: dbName,
+		Index: map[string]interface{}{
+			"ddoc": nil,
+			"name": AllDocsIndex.Name,
+			"type": AllDocsIndex.Type,
+			"def":  AllDocsIndex.Definition,
+		},
+		Selector: q.Selector,
+		Options: map[string]interface{}{
+			"bookmark": q.Bookmark,
+			"sort":     q.Sort,
+		},
+		Limit:  q.limit(),
+		Skip:   q.Skip,
+		Fields: fields,
+	}
+}
+
+func splitField(field string) []string {
+	return strings.Split(field, ".")
+}
+
+// lookupField returns the value at path within doc.
+func lookupField(doc map[string]interface{}, path []string) (interface{}, bool) {
+	var value interface{} = doc
+	for _, name := range path {
+		obj, ok := value.(map[string]interface{})
+		if !ok {
+			return nil, false
+		}
+		if value, ok = obj[name]; !ok {
+			return nil, false
+		}
+	}
+	return value, true
+}
+
+// project returns a copy of doc containing only the named fields.
+func project(doc map[string]interface{}, fields []string) map[string]interface{} {
+	result := make(map[string]interface{}, len(fields))
+	for _, field := range fields {
+		path := splitField(field)
+		value, ok := lookupField(doc, path)
+		if !ok {
+			continue
+		}
+		target := result
+		for _, name := range path[:len(path)-1] {
+			next, ok := target[name].(map[string]interface{})
+			if !ok {
+				next = make(map[string]interface{})
+				target[name] = next
+			}
+			target = next
+		}
+		target[path[len(path)-1]] = value
+	}
+	return result
+}
+
+func encodeBookmark(offset int64) string {
+	return base64.RawURLEncoding.EncodeToString([]byte(strconv.FormatInt(offset, 10)))
+}
+
+func decodeBookmark(bookmark string) (int64, error) {
+	if bookmark == "" || bookmark == "nil" {
+		return 0, nil
+	}
+	data, err := base64.RawURLEncoding.DecodeString(bookmark)
+	if err == nil {
+		var offset int64
+		if offset, err = strconv.ParseInt(string(data), 10, 64); err == nil {
+			return offset, nil
+		}
+	}
+	return 0, errors.Status(kivik.StatusBadRequest, "kivik: invalid bookmark")
+}
@@ -0,0 +1,105 @@
+// Package docquery implements the option parsing, and the Find and AllDocs
+// query logic, shared by the fs and bolt drivers.
+package docquery
+
+import (
+	"encoding/json"
+	"strconv"
+
+	"github.com/go-kivik/kivik"
+	"github.com/go-kivik/kivik/errors"
+)
+
+// BoolOpt returns the named option as a bool. Strings are parsed with
+// strconv.ParseBool; any other value is false.
+func BoolOpt(opts map[string]interface{}, key string) bool {
+	switch t := opts[key].(type) {
+	case bool:
+		return t
+	case string:
+		b, _ := strconv.ParseBool(t)
+		return b
+	}
+	return false
+}
+
+// IntOpt returns the named option as an integer, or 0 if it is not set.
+func IntOpt(opts map[string]interface{}, key string) (int64, error) {
+	switch t := opts[key].(type) {
+	case nil:
+		return 0, nil
+	case int:
+		return int64(t), nil
+	case int64:
+		return t, nil
+	case float64:
+		return int64(t), nil
+	case string:
+		n, err := strconv.ParseInt(t, 10, 64)
+		if err != nil {
+			return 0, errors.Statusf(kivik.StatusBadRequest, "kivik: invalid value for %s: %s", key, t)
+		}
+		return n, nil
+	}
+	return 0, errors.Statusf(kivik.StatusBadRequest, "kivik: invalid value for %s: %v", key, opts[key])
+}
+
+// KeyOpt returns the value of the first of the named options which is set.
+// Keys may be passed JSON-encoded, as by kivik.StartKey, or as plain strings.
+func KeyOpt(opts map[string]interface{}, names ...string) (string, bool, error) {
+	for _, name := range names {
+		value, ok := opts[name]
+		if !ok {
+			continue
+		}
+		if s, ok := value.(string); ok {
+			var key string
+			if json.Unmarshal([]byte(s), &key) == nil {
+				return key, true, nil
+			}
+			return s, true, nil
+		}
+		var key string
+		data, _ := json.Marshal(value)
+		if err := json.Unmarshal(data, &key); err != nil {
+			return "", false, errors.Statusf(kivik.StatusBadRequest, "kivik: invalid value for %s", name)
+		}
+		return key, true, nil
+	}
+	return "", false, nil
+}
+
+// KeysOpt returns the keys option, which may be passed JSON-encoded, or as a
+// slice.
+func KeysOpt(opts map[string]interface{}) ([]string, bool, error) {
+	value, ok := opts["keys"]
+	if !ok {
+		return nil, false, nil
+	}
+	var data []byte
+	if s, ok := value.(string); ok {
+		data = []byte(s)
+	} else {
+		data, _ = json.Marshal(value)
+	}
+	var keys []string
+	if err := json.Unmarshal(data, &keys); err != nil {
+		return nil, false, errors.Status(kivik.StatusBadRequest, "kivik: keys must be an array of strings")
+	}
+	return keys, true, nil
+}
+
+// ToJSON returns i as JSON. Strings, byte slices and json.RawMessages are
+// assumed to be JSON already.
+func ToJSON(i interface{}) ([]byte, error) {
+	switch t := i.(type) {
+	case string:
+		return []byte(t), nil
+	case []byte:
+		return t, nil
+	case json.RawMessage:
+		return t, nil
+	}
+	data, err := json.Marshal(i)
+	return data, errors.WrapStatus(kivik.StatusBadRequest, err)
+}
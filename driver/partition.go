@@ -0,0 +1,52 @@
+package driver
+
+import (
+	"context"
+	"encoding/json"
+)
+
+// PartitionStats contains partition statistics, as returned by the
+// /{db}/_partition/{partition} endpoint.
+type PartitionStats struct {
+	DBName          string `json:"db_name"`
+	DocCount        int64  `json:"doc_count"`
+	DeletedDocCount int64  `json:"doc_del_count"`
+	Partition       string `json:"partition"`
+	ActiveSize      int64  `json:"-"`
+	ExternalSize    int64  `json:"-"`
+}
+
+// UnmarshalJSON satisfies the json.Unmarshaler interface. It reads the active
+// and external sizes from the sizes object.
+func (s *PartitionStats) UnmarshalJSON(data []byte) error {
+	type partitionStats PartitionStats
+	var stats struct {
+		partitionStats
+		Sizes struct {
+			Active   int64 `json:"active"`
+			External int64 `json:"external"`
+		} `json:"sizes"`
+	}
+	if err := json.Unmarshal(data, &stats); err != nil {
+		return err
+	}
+	*s = PartitionStats(stats.partitionStats)
+	s.ActiveSize = stats.Sizes.Active
+	s.ExternalSize = stats.Sizes.External
+	return nil
+}
+
+// Partitioner is an optional interface which may be implemented by a DB, to
+// support partitioned databases, as introduced in CouchDB 3.0.
+type Partitioner interface {
+	// PartitionStats returns statistics for the named partition.
+	PartitionStats(ctx context.Context, name string) (*PartitionStats, error)
+	// PartitionAllDocs returns all documents in the named partition.
+	PartitionAllDocs(ctx context.Context, partition string, options map[string]interface{}) (Rows, error)
+	// PartitionQuery queries a partitioned view, limited to the named
+	// partition.
+	PartitionQuery(ctx context.Context, partition, ddoc, view string, options map[string]interface{}) (Rows, error)
+	// PartitionFind executes a Mango query, limited to the named partition.
+	// query should be treated as described for Finder.Find.
+	PartitionFind(ctx context.Context, partition string, query interface{}) (Rows, error)
+}
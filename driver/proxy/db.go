@@ -0,0 +1,238 @@
+package proxy
+
+import (
+	"context"
+
+	"github.com/go-kivik/kivik"
+	"github.com/go-kivik/kivik/driver"
+)
+
+// db forwards every call to a backend kivik.DB. Optional interfaces are
+// always implemented, as the backend emulates them where its own driver
+// does not, or else returns the same error it would return directly.
+type db struct {
+	db *kivik.DB
+}
+
+var (
+	_ driver.DB                   = &db{}
+	_ driver.DBCloser             = &db{}
+	_ driver.Finder               = &db{}
+	_ driver.DesignDocer          = &db{}
+	_ driver.LocalDocer           = &db{}
+	_ driver.RevsDiffer           = &db{}
+	_ driver.OpenRever            = &db{}
+	_ driver.Copier               = &db{}
+	_ driver.DocumentMetaGetter   = &db{}
+	_ driver.AttachmentMetaGetter = &db{}
+	_ driver.Flusher              = &db{}
+	_ driver.Purger               = &db{}
+)
+
+func (d *db) Close(ctx context.Context) error {
+	return d.db.Close(ctx)
+}
+
+func (d *db) AllDocs(ctx context.Context, opts map[string]interface{}) (driver.Rows, error) {
+	return newRows(d.db.AllDocs(ctx, opts))
+}
+
+func (d *db) DesignDocs(ctx context.Context, opts map[string]interface{}) (driver.Rows, error) {
+	return newRows(d.db.DesignDocs(ctx, opts))
+}
+
+func (d *db) LocalDocs(ctx context.Context, opts map[string]interface{}) (driver.Rows, error) {
+	return newRows(d.db.LocalDocs(ctx, opts))
+}
+
+func (d *db) Query(ctx context.Context, ddoc, view string, opts map[string]interface{}) (driver.Rows, error) {
+	return newRows(d.db.Query(ctx, ddoc, view, opts))
+}
+
+func (d *db) Find(ctx context.Context, query interface{}) (driver.Rows, error) {
+	return newRows(d.db.Find(ctx, query))
+}
+
+func (d *db) RevsDiff(ctx context.Context, revMap interface{}) (driver.Rows, error) {
+	return newRows(d.db.RevsDiff(ctx, revMap))
+}
+
+func (d *db) OpenRevs(ctx context.Context, docID string, revs []string, opts map[string]interface{}) (driver.Rows, error) {
+	return newRows(d.db.GetOpenRevs(ctx, docID, revs, opts))
+}
+
+func (d *db) Get(ctx context.Context, docID string, opts map[string]interface{}) (*driver.Document, error) {
+	row := d.db.Get(ctx, docID, opts)
+	if row.Err != nil {
+		return nil, row.Err
+	}
+	doc := &driver.Document{
+		ContentLength: row.ContentLength,
+		Rev:           row.Rev,
+		Body:          row.Body,
+	}
+	if row.Attachments != nil {
+		doc.Attachments = &attachments{atts: row.Attachments}
+	}
+	return doc, nil
+}
+
+func (d *db) GetDocumentMeta(ctx context.Context, docID string, opts map[string]interface{}) (*driver.DocumentMeta, error) {
+	meta, err := d.db.GetMeta(ctx, docID, opts)
+	if err != nil {
+		return nil, err
+	}
+	return (*driver.DocumentMeta)(meta), nil
+}
+
+func (d *db) CreateDoc(ctx context.Context, doc interface{}, opts map[string]interface{}) (string, string, error) {
+	return d.db.CreateDoc(ctx, doc, opts)
+}
+
+func (d *db) Put(ctx context.Context, docID string, doc interface{}, opts map[string]interface{}) (string, error) {
+	return d.db.Put(ctx, docID, doc, opts)
+}
+
+func (d *db) Delete(ctx context.Context, docID, rev string, opts map[string]interface{}) (string, error) {
+	return d.db.Delete(ctx, docID, rev, opts)
+}
+
+func (d *db) Copy(ctx context.Context, targetID, sourceID string, opts map[string]interface{}) (string, error) {
+	return d.db.Copy(ctx, targetID, sourceID, opts)
+}
+
+func (d *db) Stats(ctx context.Context) (*driver.DBStats, error) {
+	stats, err := d.db.Stats(ctx)
+	if err != nil {
+		return nil, err
+	}
+	return driverStats(stats), nil
+}
+
+func (d *db) Compact(ctx context.Context) error {
+	return d.db.Compact(ctx)
+}
+
+func (d *db) CompactView(ctx context.Context, ddocID string) error {
+	return d.db.CompactView(ctx, ddocID)
+}
+
+func (d *db) ViewCleanup(ctx context.Context) error {
+	return d.db.ViewCleanup(ctx)
+}
+
+func (d *db) Flush(ctx context.Context) error {
+	return d.db.Flush(ctx)
+}
+
+func (d *db) Purge(ctx context.Context, docRevMap map[string][]string) (*driver.PurgeResult, error) {
+	result, err := d.db.Purge(ctx, docRevMap)
+	if err != nil {
+		return nil, err
+	}
+	r := driver.PurgeResult(*result)
+	return &r, nil
+}
+
+func (d *db) Security(ctx context.Context) (*driver.Security, error) {
+	sec, err := d.db.Security(ctx)
+	if err != nil {
+		return nil, err
+	}
+	return &driver.Security{
+		Admins:  driver.Members(sec.Admins),
+		Members: driver.Members(sec.Members),
+	}, nil
+}
+
+func (d *db) SetSecurity(ctx context.Context, security *driver.Security) error {
+	return d.db.SetSecurity(ctx, &kivik.Security{
+		Admins:  kivik.Members(security.Admins),
+		Members: kivik.Members(security.Members),
+	})
+}
+
+func (d *db) Changes(ctx context.Context, opts map[string]interface{}) (driver.Changes, error) {
+	changes, err := d.db.Changes(ctx, opts)
+	if err != nil {
+		return nil, err
+	}
+	return &changesIter{changes: changes}, nil
+}
+
+func (d *db) PutAttachment(ctx context.Context, docID, rev string, att *driver.Attachment, opts map[string]interface{}) (string, error) {
+	a := kivik.Attachment(*att)
+	return d.db.PutAttachment(ctx, docID, rev, &a, opts)
+}
+
+func (d *db) GetAttachment(ctx context.Context, docID, rev, filename string, opts map[string]interface{}) (*driver.Attachment, error) {
+	att, err := d.db.GetAttachment(ctx, docID, rev, filename, opts)
+	if err != nil {
+		return nil, err
+	}
+	a := driver.Attachment(*att)
+	return &a, nil
+}
+
+func (d *db) GetAttachmentMeta(ctx context.Context, docID, rev, filename string, opts map[string]interface{}) (*driver.Attachment, error) {
+	att, err := d.db.GetAttachmentMeta(ctx, docID, rev, filename, opts)
+	if err != nil {
+		return nil, err
+	}
+	a := driver.Attachment(*att)
+	return &a, nil
+}
+
+func (d *db) DeleteAttachment(ctx context.Context, docID, rev, filename string, opts map[string]interface{}) (string, error) {
+	return d.db.DeleteAttachment(ctx, docID, rev, filename, opts)
+}
+
+func (d *db) CreateIndex(ctx context.Context, ddoc, name string, index interface{}) error {
+	return d.db.CreateIndex(ctx, ddoc, name, index)
+}
+
+func (d *db) GetIndexes(ctx context.Context) ([]driver.Index, error) {
+	indexes, err := d.db.GetIndexes(ctx)
+	if err != nil {
+		return nil, err
+	}
+	result := make([]driver.Index, len(indexes))
+	for i, index := range indexes {
+		result[i] = driver.Index(index)
+	}
+	return result, nil
+}
+
+func (d *db) DeleteIndex(ctx context.Context, ddoc, name string) error {
+	return d.db.DeleteIndex(ctx, ddoc, name)
+}
+
+func (d *db) Explain(ctx context.Context, query interface{}) (*driver.QueryPlan, error) {
+	plan, err := d.db.Explain(ctx, query)
+	if err != nil {
+		return nil, err
+	}
+	p := driver.QueryPlan(*plan)
+	return &p, nil
+}
+
+func driverStats(s *kivik.DBStats) *driver.DBStats {
+	var cluster *driver.ClusterConfig
+	if s.Cluster != nil {
+		c := driver.ClusterConfig(*s.Cluster)
+		cluster = &c
+	}
+	return &driver.DBStats{
+		Name:           s.Name,
+		CompactRunning: s.CompactRunning,
+		DocCount:       s.DocCount,
+		DeletedCount:   s.DeletedCount,
+		UpdateSeq:      driver.SequenceID(s.UpdateSeq),
+		PurgeSeq:       driver.SequenceID(s.PurgeSeq),
+		DiskSize:       s.DiskSize,
+		ActiveSize:     s.ActiveSize,
+		ExternalSize:   s.ExternalSize,
+		Cluster:        cluster,
+		Partitioned:    s.Partitioned,
+	}
+}
@@ -0,0 +1,107 @@
+package proxy
+
+import (
+	"encoding/json"
+	"io"
+
+	"github.com/go-kivik/kivik"
+	"github.com/go-kivik/kivik/driver"
+)
+
+// rowsIter adapts a backend result set to a driver iterator.
+type rowsIter struct {
+	rows *kivik.Rows
+}
+
+var (
+	_ driver.Rows       = &rowsIter{}
+	_ driver.RowsWarner = &rowsIter{}
+	_ driver.Bookmarker = &rowsIter{}
+)
+
+func newRows(rows *kivik.Rows, err error) (driver.Rows, error) {
+	if err != nil {
+		return nil, err
+	}
+	return &rowsIter{rows: rows}, nil
+}
+
+func (r *rowsIter) Next(row *driver.Row) error {
+	if !r.rows.Next() {
+		if err := r.rows.Err(); err != nil {
+			return err
+		}
+		return io.EOF
+	}
+	*row = driver.Row{ID: r.rows.ID()}
+	if err := r.rows.ScanKey(&row.Key); err != nil {
+		return err
+	}
+	if err := r.rows.ScanValue(&row.Value); err != nil {
+		return err
+	}
+	// ScanDoc fails with StatusBadRequest when the row has no document;
+	// any other error is the row's own, such as for a missing document in
+	// a BulkGet result.
+	var doc json.RawMessage
+	if err := r.rows.ScanDoc(&doc); err != nil {
+		if kivik.StatusCode(err) != kivik.StatusBadRequest {
+			row.Error = err
+		}
+		return nil
+	}
+	row.Doc = doc
+	return nil
+}
+
+func (r *rowsIter) Close() error      { return r.rows.Close() }
+func (r *rowsIter) Offset() int64     { return r.rows.Offset() }
+func (r *rowsIter) TotalRows() int64  { return r.rows.TotalRows() }
+func (r *rowsIter) UpdateSeq() string { return string(r.rows.UpdateSeq()) }
+func (r *rowsIter) Warning() string   { return r.rows.Warning() }
+func (r *rowsIter) Bookmark() string  { return r.rows.Bookmark() }
+
+// changesIter adapts a backend changes feed to a driver iterator.
+type changesIter struct {
+	changes *kivik.Changes
+}
+
+var _ driver.Changes = &changesIter{}
+
+func (c *changesIter) Next(change *driver.Change) error {
+	if !c.changes.Next() {
+		if err := c.changes.Err(); err != nil {
+			return err
+		}
+		return io.EOF
+	}
+	*change = driver.Change{
+		ID:      c.changes.ID(),
+		Seq:     driver.SequenceID(c.changes.Seq()),
+		Deleted: c.changes.Deleted(),
+		Changes: driver.ChangedRevs(c.changes.Changes()),
+	}
+	return c.changes.ScanDoc(&change.Doc)
+}
+
+func (c *changesIter) Close() error    { return c.changes.Close() }
+func (c *changesIter) LastSeq() string { return string(c.changes.LastSeq()) }
+func (c *changesIter) Pending() int64  { return c.changes.Pending() }
+
+// attachments adapts a backend attachments iterator to a driver iterator.
+type attachments struct {
+	atts *kivik.AttachmentsIterator
+}
+
+var _ driver.Attachments = &attachments{}
+
+func (a *attachments) Next(att *driver.Attachment) error {
+	next, err := a.atts.Next()
+	if err != nil {
+		return err
+	}
+	*att = driver.Attachment(*next)
+	return nil
+}
+
+func (a *attachments) Close() error { return nil }
@@ -0,0 +1,122 @@
+// Package proxy provides a kivik driver whose backend is another kivik
+// Client. Every call is forwarded to the backend, through its hooks and
+// emulations, so proxies may be chained, and wrappers such as caching,
+// metrics or read-only access may be composed uniformly, whatever the
+// driver at the end of the chain:
+//
+//	backend, err := kivik.New(ctx, "fs", "/path/to/root")
+//	if err != nil {
+//		return err
+//	}
+//	client, err := proxy.New(backend)
+//
+// Wrappers implemented as drivers may embed the driver.Client returned by
+// NewClient, and override only the methods they need.
+//
+// Closing a proxy client does not close its backend, which remains the
+// responsibility of the caller.
+package proxy
+
+import (
+	"context"
+
+	"github.com/go-kivik/kivik"
+	"github.com/go-kivik/kivik/driver"
+	"github.com/go-kivik/kivik/errors"
+	"github.com/go-kivik/kivik/internal/registry"
+)
+
+// DriverName is the name under which the proxy driver is registered.
+const DriverName = "proxy"
+
+var backends = registry.New(DriverName, func(backend interface{}) (driver.Client, error) {
+	return NewClient(backend.(*kivik.Client)), nil
+})
+
+func init() {
+	kivik.Register(DriverName, backends.Driver())
+}
+
+// New returns a kivik client which forwards every call to backend.
+func New(backend *kivik.Client) (*kivik.Client, error) {
+	if backend == nil {
+		return nil, errors.Status(kivik.StatusBadRequest, "proxy: backend required")
+	}
+	return backends.NewClient(context.Background(), backend)
+}
+
+// NewClient returns a driver client which forwards every call to backend.
+func NewClient(backend *kivik.Client) driver.Client {
+	return &client{backend: backend}
+}
+
+type client struct {
+	backend *kivik.Client
+}
+
+var (
+	_ driver.Client        = &client{}
+	_ driver.Pinger        = &client{}
+	_ driver.Authenticator = &client{}
+	_ driver.DBsStatser    = &client{}
+)
+
+func (c *client) AllDBs(ctx context.Context, opts map[string]interface{}) ([]string, error) {
+	return c.backend.AllDBs(ctx, opts)
+}
+
+func (c *client) DBExists(ctx context.Context, dbName string, opts map[string]interface{}) (bool, error) {
+	return c.backend.DBExists(ctx, dbName, opts)
+}
+
+func (c *client) CreateDB(ctx context.Context, dbName string, opts map[string]interface{}) error {
+	_, err := c.backend.CreateDB(ctx, dbName, opts)
+	return err
+}
+
+func (c *client) DestroyDB(ctx context.Context, dbName string, opts map[string]interface{}) error {
+	return c.backend.DestroyDB(ctx, dbName, opts)
+}
+
+func (c *client) Version(ctx context.Context) (*driver.Version, error) {
+	ver, err := c.backend.Version(ctx)
+	if err != nil {
+		return nil, err
+	}
+	return &driver.Version{
+		Version:     ver.Version,
+		Vendor:      ver.Vendor,
+		Features:    ver.Features,
+		RawResponse: ver.RawResponse,
+	}, nil
+}
+
+func (c *client) DB(ctx context.Context, dbName string, opts map[string]interface{}) (driver.DB, error) {
+	backend, err := c.backend.DB(ctx, dbName, opts)
+	if err != nil {
+		return nil, err
+	}
+	return &db{db: backend}, nil
+}
+
+func (c *client) Ping(ctx context.Context) (bool, error) {
+	return c.backend.Ping(ctx)
+}
+
+func (c *client) Authenticate(ctx context.Context, a interface{}) error {
+	return c.backend.Authenticate(ctx, a)
+}
+
+func (c *client) DBsStats(ctx context.Context, dbnames []string) ([]*driver.DBStats, error) {
+	stats, err := c.backend.DBsStats(ctx, dbnames)
+	if err != nil {
+		return nil, err
+	}
+	result := make([]*driver.DBStats, len(stats))
+	for i, s := range stats {
+		if s != nil {
+			result[i] = driverStats(s)
+		}
+	}
+	return result, nil
+}
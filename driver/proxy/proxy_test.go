@@ -0,0 +1,266 @@
+package proxy
+
+import (
+	"context"
+	"io/ioutil"
+	"os"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/flimzy/diff"
+	"github.com/go-kivik/kivik"
+	_ "github.com/go-kivik/kivik/driver/fs"
+)
+
+// newTestClient returns a proxy of a client of the fs driver, in a new
+// temporary directory, the backend client, and a function to remove the
+// directory.
+func newTestClient(t *testing.T) (*kivik.Client, *kivik.Client, func()) {
+	dir, err := ioutil.TempDir("", "kivik-proxy-")
+	if err != nil {
+		t.Fatal(err)
+	}
+	cleanup := func() { _ = os.RemoveAll(dir) }
+	backend, err := kivik.New(context.Background(), "fs", dir)
+	if err != nil {
+		cleanup()
+		t.Fatal(err)
+	}
+	client, err := New(backend)
+	if err != nil {
+		cleanup()
+		t.Fatal(err)
+	}
+	return client, backend, cleanup
+}
+
+type recordingHook struct {
+	mu  sync.Mutex
+	ops []string
+}
+
+func (h *recordingHook) Before(ctx context.Context, op *kivik.Operation) context.Context {
+	h.mu.Lock()
+	h.ops = append(h.ops, op.Name)
+	h.mu.Unlock()
+	return ctx
+}
+
+func (h *recordingHook) After(context.Context, *kivik.Operation, time.Duration, error) {}
+
+func TestNewClient(t *testing.T) {
+	if _, err := New(nil); kivik.StatusCode(err) != kivik.StatusBadRequest {
+		t.Errorf("Unexpected error: %v", err)
+	}
+	_, err := kivik.New(context.Background(), DriverName, "unknown")
+	if status := kivik.StatusCode(err); status != kivik.StatusBadRequest {
+		t.Errorf("Unexpected status: %d (%s)", status, err)
+	}
+}
+
+func TestClient(t *testing.T) {
+	client, backend, cleanup := newTestClient(t)
+	defer cleanup()
+	ctx := context.Background()
+	hook := &recordingHook{}
+	backend.AddHook(hook)
+	if _, err := client.CreateDB(ctx, "foo"); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := client.CreateDB(ctx, "foo"); kivik.StatusCode(err) != kivik.StatusPreconditionFailed {
+		t.Errorf("Unexpected error creating duplicate database: %v", err)
+	}
+	dbs, err := client.AllDBs(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if d := diff.Interface([]string{"foo"}, dbs); d != nil {
+		t.Error(d)
+	}
+	ver, err := client.Version(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if ver.Vendor != "Kivik File System" {
+		t.Errorf("Unexpected vendor: %s", ver.Vendor)
+	}
+	if err := client.DestroyDB(ctx, "foo"); err != nil {
+		t.Fatal(err)
+	}
+	if exists, err := client.DBExists(ctx, "foo"); err != nil || exists {
+		t.Errorf("Unexpected result: %t, %v", exists, err)
+	}
+	expected := []string{"CreateDB", "CreateDB", "AllDBs", "Version", "DestroyDB", "DBExists"}
+	if d := diff.Interface(expected, hook.ops); d != nil {
+		t.Error(d)
+	}
+}
+
+func TestDB(t *testing.T) {
+	client, _, cleanup := newTestClient(t)
+	defer cleanup()
+	ctx := context.Background()
+	db, err := client.CreateDB(ctx, "test")
+	if err != nil {
+		t.Fatal(err)
+	}
+	rev, err := db.Put(ctx, "foo", map[string]string{"name": "Foo"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	var doc struct {
+		Name string `json:"name"`
+	}
+	row := db.Get(ctx, "foo")
+	if err := row.ScanDoc(&doc); err != nil {
+		t.Fatal(err)
+	}
+	if doc.Name != "Foo" || row.Rev != rev {
+		t.Errorf("Unexpected result: %+v, rev %s", doc, row.Rev)
+	}
+	if _, err := db.Put(ctx, "foo", map[string]string{"name": "Bar"}); kivik.StatusCode(err) != kivik.StatusConflict {
+		t.Errorf("Unexpected error: %v", err)
+	}
+	if _, err := db.Put(ctx, "bar", map[string]string{"name": "Bar"}); err != nil {
+		t.Fatal(err)
+	}
+	rows, err := db.AllDocs(ctx, kivik.Options{"include_docs": true})
+	if err != nil {
+		t.Fatal(err)
+	}
+	var names []string
+	for rows.Next() {
+		if err := rows.ScanDoc(&doc); err != nil {
+			t.Fatal(err)
+		}
+		names = append(names, rows.ID()+":"+doc.Name)
+	}
+	if err := rows.Err(); err != nil {
+		t.Fatal(err)
+	}
+	if d := diff.Interface([]string{"bar:Bar", "foo:Foo"}, names); d != nil {
+		t.Error(d)
+	}
+	if rows.TotalRows() != 2 {
+		t.Errorf("Unexpected total rows: %d", rows.TotalRows())
+	}
+	if _, err := db.Delete(ctx, "foo", rev); err != nil {
+		t.Fatal(err)
+	}
+	if err := db.Get(ctx, "foo").Err; kivik.StatusCode(err) != kivik.StatusNotFound {
+		t.Errorf("Unexpected error: %v", err)
+	}
+	stats, err := db.Stats(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if stats.DocCount != 1 {
+		t.Errorf("Unexpected doc count: %d", stats.DocCount)
+	}
+}
+
+func TestChanges(t *testing.T) {
+	client, _, cleanup := newTestClient(t)
+	defer cleanup()
+	ctx := context.Background()
+	db, err := client.CreateDB(ctx, "test")
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, id := range []string{"a", "b"} {
+		if _, err := db.Put(ctx, id, map[string]string{}); err != nil {
+			t.Fatal(err)
+		}
+	}
+	changes, err := db.Changes(ctx, kivik.Options{"include_docs": true})
+	if err != nil {
+		t.Fatal(err)
+	}
+	var ids []string
+	for changes.Next() {
+		var doc struct {
+			ID string `json:"_id"`
+		}
+		if err := changes.ScanDoc(&doc); err != nil {
+			t.Fatal(err)
+		}
+		ids = append(ids, changes.ID()+":"+doc.ID)
+	}
+	if err := changes.Err(); err != nil {
+		t.Fatal(err)
+	}
+	if d := diff.Interface([]string{"a:a", "b:b"}, ids); d != nil {
+		t.Error(d)
+	}
+	if changes.LastSeq() == "" {
+		t.Error("Expected a last seq")
+	}
+}
+
+func TestAttachments(t *testing.T) {
+	client, _, cleanup := newTestClient(t)
+	defer cleanup()
+	ctx := context.Background()
+	db, err := client.CreateDB(ctx, "test")
+	if err != nil {
+		t.Fatal(err)
+	}
+	rev, err := db.PutAttachment(ctx, "foo", "", &kivik.Attachment{
+		Filename:    "foo.txt",
+		ContentType: "text/plain",
+		Content:     ioutil.NopCloser(strings.NewReader("hello")),
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	att, err := db.GetAttachment(ctx, "foo", rev, "foo.txt")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer att.Content.Close() // nolint: errcheck
+	content, err := ioutil.ReadAll(att.Content)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(content) != "hello" || att.ContentType != "text/plain" {
+		t.Errorf("Unexpected attachment: %s, %s", att.ContentType, content)
+	}
+}
+
+func TestChained(t *testing.T) {
+	proxied, _, cleanup := newTestClient(t)
+	defer cleanup()
+	ctx := context.Background()
+	client, err := New(proxied)
+	if err != nil {
+		t.Fatal(err)
+	}
+	db, err := client.CreateDB(ctx, "test")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := db.Put(ctx, "foo", map[string]interface{}{"n": 1}); err != nil {
+		t.Fatal(err)
+	}
+	rows, err := db.Find(ctx, map[string]interface{}{
+		"selector": map[string]interface{}{"n": 1},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	var ids []string
+	for rows.Next() {
+		ids = append(ids, rows.ID())
+	}
+	if err := rows.Err(); err != nil {
+		t.Fatal(err)
+	}
+	if d := diff.Interface([]string{"foo"}, ids); d != nil {
+		t.Error(d)
+	}
+	if rows.Warning() == "" {
+		t.Error("Expected the backend's warning")
+	}
+}
@@ -0,0 +1,243 @@
+package readonly
+
+import (
+	"context"
+
+	"github.com/go-kivik/kivik"
+	"github.com/go-kivik/kivik/driver"
+	"github.com/go-kivik/kivik/errors"
+)
+
+// readonlyDB passes reads through to the embedded DB, and rejects writes.
+type readonlyDB struct {
+	driver.DB
+}
+
+var (
+	_ driver.DB                   = &readonlyDB{}
+	_ driver.DBCloser             = &readonlyDB{}
+	_ driver.Finder               = &readonlyDB{}
+	_ driver.DesignDocer          = &readonlyDB{}
+	_ driver.LocalDocer           = &readonlyDB{}
+	_ driver.RevsDiffer           = &readonlyDB{}
+	_ driver.OpenRever            = &readonlyDB{}
+	_ driver.AttachmentMetaGetter = &readonlyDB{}
+	_ driver.Partitioner          = &readonlyDB{}
+	_ driver.Searcher             = &readonlyDB{}
+	_ driver.GeoQuerier           = &readonlyDB{}
+	_ driver.BulkDocer            = &readonlyDB{}
+	_ driver.Purger               = &readonlyDB{}
+)
+
+func notSupported(method string) error {
+	return errors.Statusf(kivik.StatusNotImplemented, "readonly: backend does not support %s", method)
+}
+
+func (d *readonlyDB) Put(_ context.Context, _ string, _ interface{}, _ map[string]interface{}) (string, error) {
+	return "", errReadOnly
+}
+
+func (d *readonlyDB) CreateDoc(_ context.Context, _ interface{}, _ map[string]interface{}) (string, string, error) {
+	return "", "", errReadOnly
+}
+
+func (d *readonlyDB) Delete(_ context.Context, _, _ string, _ map[string]interface{}) (string, error) {
+	return "", errReadOnly
+}
+
+func (d *readonlyDB) BulkDocs(_ context.Context, _ []interface{}, _ map[string]interface{}) (driver.BulkResults, error) {
+	return nil, errReadOnly
+}
+
+func (d *readonlyDB) Purge(_ context.Context, _ map[string][]string) (*driver.PurgeResult, error) {
+	return nil, errReadOnly
+}
+
+func (d *readonlyDB) Compact(_ context.Context) error {
+	return errReadOnly
+}
+
+func (d *readonlyDB) CompactView(_ context.Context, _ string) error {
+	return errReadOnly
+}
+
+func (d *readonlyDB) ViewCleanup(_ context.Context) error {
+	return errReadOnly
+}
+
+func (d *readonlyDB) SetSecurity(_ context.Context, _ *driver.Security) error {
+	return errReadOnly
+}
+
+func (d *readonlyDB) PutAttachment(_ context.Context, _, _ string, _ *driver.Attachment, _ map[string]interface{}) (string, error) {
+	return "", errReadOnly
+}
+
+func (d *readonlyDB) DeleteAttachment(_ context.Context, _, _, _ string, _ map[string]interface{}) (string, error) {
+	return "", errReadOnly
+}
+
+func (d *readonlyDB) CreateIndex(_ context.Context, _, _ string, _ interface{}) error {
+	return errReadOnly
+}
+
+func (d *readonlyDB) DeleteIndex(_ context.Context, _, _ string) error {
+	return errReadOnly
+}
+
+func (d *readonlyDB) Close(ctx context.Context) error {
+	if closer, ok := d.DB.(driver.DBCloser); ok {
+		return closer.Close(ctx)
+	}
+	return nil
+}
+
+func (d *readonlyDB) Find(ctx context.Context, query interface{}) (driver.Rows, error) {
+	if finder, ok := d.DB.(driver.Finder); ok {
+		return finder.Find(ctx, query)
+	}
+	return nil, notSupported("Find")
+}
+
+func (d *readonlyDB) GetIndexes(ctx context.Context) ([]driver.Index, error) {
+	if finder, ok := d.DB.(driver.Finder); ok {
+		return finder.GetIndexes(ctx)
+	}
+	return nil, notSupported("GetIndexes")
+}
+
+func (d *readonlyDB) Explain(ctx context.Context, query interface{}) (*driver.QueryPlan, error) {
+	if finder, ok := d.DB.(driver.Finder); ok {
+		return finder.Explain(ctx, query)
+	}
+	return nil, notSupported("Explain")
+}
+
+func (d *readonlyDB) DesignDocs(ctx context.Context, opts map[string]interface{}) (driver.Rows, error) {
+	if ddocer, ok := d.DB.(driver.DesignDocer); ok {
+		return ddocer.DesignDocs(ctx, opts)
+	}
+	return nil, notSupported("DesignDocs")
+}
+
+func (d *readonlyDB) LocalDocs(ctx context.Context, opts map[string]interface{}) (driver.Rows, error) {
+	if ldocer, ok := d.DB.(driver.LocalDocer); ok {
+		return ldocer.LocalDocs(ctx, opts)
+	}
+	return nil, notSupported("LocalDocs")
+}
+
+func (d *readonlyDB) RevsDiff(ctx context.Context, revMap interface{}) (driver.Rows, error) {
+	if rd, ok := d.DB.(driver.RevsDiffer); ok {
+		return rd.RevsDiff(ctx, revMap)
+	}
+	return nil, notSupported("RevsDiff")
+}
+
+func (d *readonlyDB) OpenRevs(ctx context.Context, docID string, revs []string, opts map[string]interface{}) (driver.Rows, error) {
+	if openRever, ok := d.DB.(driver.OpenRever); ok {
+		return openRever.OpenRevs(ctx, docID, revs, opts)
+	}
+	return nil, notSupported("OpenRevs")
+}
+
+// documentMeta adapts a driver.MetaGetter to driver.DocumentMetaGetter.
+type documentMeta struct {
+	driver.MetaGetter
+}
+
+func (m documentMeta) GetDocumentMeta(ctx context.Context, docID string, opts map[string]interface{}) (*driver.DocumentMeta, error) {
+	size, rev, err := m.GetMeta(ctx, docID, opts)
+	if err != nil {
+		return nil, err
+	}
+	return &driver.DocumentMeta{Size: size, Rev: rev}, nil
+}
+
+// newReadonlyDB wraps db. The meta and BulkGetter interfaces, which kivik
+// emulates for drivers which lack them, are declared only if db implements
+// them, so that kivik's emulation is used otherwise.
+func newReadonlyDB(db driver.DB) driver.DB {
+	d := &readonlyDB{DB: db}
+	var meta driver.DocumentMetaGetter
+	switch t := db.(type) {
+	case driver.DocumentMetaGetter:
+		meta = t
+	case driver.MetaGetter:
+		meta = documentMeta{t}
+	}
+	bulkGetter, _ := db.(driver.BulkGetter)
+	switch {
+	case meta != nil && bulkGetter != nil:
+		return &struct {
+			*readonlyDB
+			driver.DocumentMetaGetter
+			driver.BulkGetter
+		}{d, meta, bulkGetter}
+	case meta != nil:
+		return &struct {
+			*readonlyDB
+			driver.DocumentMetaGetter
+		}{d, meta}
+	case bulkGetter != nil:
+		return &struct {
+			*readonlyDB
+			driver.BulkGetter
+		}{d, bulkGetter}
+	}
+	return d
+}
+
+func (d *readonlyDB) GetAttachmentMeta(ctx context.Context, docID, rev, filename string, opts map[string]interface{}) (*driver.Attachment, error) {
+	if metaer, ok := d.DB.(driver.AttachmentMetaGetter); ok {
+		return metaer.GetAttachmentMeta(ctx, docID, rev, filename, opts)
+	}
+	att, err := d.DB.GetAttachment(ctx, docID, rev, filename, opts)
+	if err != nil {
+		return nil, err
+	}
+	_ = att.Content.Close()
+	return att, nil
+}
+
+func (d *readonlyDB) PartitionStats(ctx context.Context, name string) (*driver.PartitionStats, error) {
+	if p, ok := d.DB.(driver.Partitioner); ok {
+		return p.PartitionStats(ctx, name)
+	}
+	return nil, notSupported("PartitionStats")
+}
+
+func (d *readonlyDB) PartitionAllDocs(ctx context.Context, partition string, opts map[string]interface{}) (driver.Rows, error) {
+	if p, ok := d.DB.(driver.Partitioner); ok {
+		return p.PartitionAllDocs(ctx, partition, opts)
+	}
+	return nil, notSupported("PartitionAllDocs")
+}
+
+func (d *readonlyDB) PartitionQuery(ctx context.Context, partition, ddoc, view string, opts map[string]interface{}) (driver.Rows, error) {
+	if p, ok := d.DB.(driver.Partitioner); ok {
+		return p.PartitionQuery(ctx, partition, ddoc, view, opts)
+	}
+	return nil, notSupported("PartitionQuery")
+}
+
+func (d *readonlyDB) PartitionFind(ctx context.Context, partition string, query interface{}) (driver.Rows, error) {
+	if p, ok := d.DB.(driver.Partitioner); ok {
+		return p.PartitionFind(ctx, partition, query)
+	}
+	return nil, notSupported("PartitionFind")
+}
+
+func (d *readonlyDB) Search(ctx context.Context, ddoc, index, query string, opts map[string]interface{}) (driver.Rows, error) {
+	if searcher, ok := d.DB.(driver.Searcher); ok {
+		return searcher.Search(ctx, ddoc, index, query, opts)
+	}
+	return nil, notSupported("Search")
+}
+
+func (d *readonlyDB) Geo(ctx context.Context, ddoc, index string, opts map[string]interface{}) (driver.Rows, error) {
+	if geo, ok := d.DB.(driver.GeoQuerier); ok {
+		return geo.Geo(ctx, ddoc, index, opts)
+	}
+	return nil, notSupported("Geo")
+}
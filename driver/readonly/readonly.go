@@ -0,0 +1,96 @@
+// Package readonly provides a kivik driver which exposes another kivik
+// Client as read-only. Reads are passed through to the backend, while every
+// write, such as Put, Delete, CreateDB, BulkDocs or SetSecurity, fails with
+// StatusForbidden, without reaching the backend. This is useful for exposing
+// production replicas to analytics jobs, and for tests which must not mutate
+// their fixtures:
+//
+//	client, err := readonly.New(backend)
+//
+// Writes which kivik emulates, such as Copy, are rejected as well, as they
+// are emulated with the underlying write methods.
+package readonly
+
+import (
+	"context"
+
+	"github.com/go-kivik/kivik"
+	"github.com/go-kivik/kivik/driver"
+	"github.com/go-kivik/kivik/driver/proxy"
+	"github.com/go-kivik/kivik/errors"
+	"github.com/go-kivik/kivik/internal/registry"
+)
+
+// DriverName is the name under which the read-only driver is registered.
+const DriverName = "readonly"
+
+var backends = registry.New(DriverName, func(backend interface{}) (driver.Client, error) {
+	return NewClient(proxy.NewClient(backend.(*kivik.Client))), nil
+})
+
+func init() {
+	kivik.Register(DriverName, backends.Driver())
+}
+
+// New returns a kivik client which passes reads through to backend, and
+// rejects all writes.
+func New(backend *kivik.Client) (*kivik.Client, error) {
+	if backend == nil {
+		return nil, errors.Status(kivik.StatusBadRequest, "readonly: backend required")
+	}
+	return backends.NewClient(context.Background(), backend)
+}
+
+// NewClient wraps c, a driver client such as returned by proxy.NewClient,
+// rejecting all writes. Reads are passed through to c. Optional read
+// interfaces which kivik emulates, such as BulkGetter and MetaGetter, are
+// declared only where c's databases implement them. The others, such as
+// Finder or Partitioner, are always declared, and fail with
+// StatusNotImplemented where c's databases do not implement them, as kivik
+// itself would.
+func NewClient(c driver.Client) driver.Client {
+	return &client{Client: c}
+}
+
+// errReadOnly is returned for every write.
+var errReadOnly = errors.Status(kivik.StatusForbidden, "readonly: client is read-only")
+
+type client struct {
+	driver.Client
+}
+
+var (
+	_ driver.Pinger        = &client{}
+	_ driver.Authenticator = &client{}
+)
+
+func (c *client) CreateDB(_ context.Context, _ string, _ map[string]interface{}) error {
+	return errReadOnly
+}
+
+func (c *client) DestroyDB(_ context.Context, _ string, _ map[string]interface{}) error {
+	return errReadOnly
+}
+
+func (c *client) DB(ctx context.Context, dbName string, opts map[string]interface{}) (driver.DB, error) {
+	db, err := c.Client.DB(ctx, dbName, opts)
+	if err != nil {
+		return nil, err
+	}
+	return newReadonlyDB(db), nil
+}
+
+func (c *client) Ping(ctx context.Context) (bool, error) {
+	if pinger, ok := c.Client.(driver.Pinger); ok {
+		return pinger.Ping(ctx)
+	}
+	_, err := c.Client.Version(ctx)
+	return err == nil, err
+}
+
+func (c *client) Authenticate(ctx context.Context, a interface{}) error {
+	if auth, ok := c.Client.(driver.Authenticator); ok {
+		return auth.Authenticate(ctx, a)
+	}
+	return errors.Status(kivik.StatusNotImplemented, "readonly: backend does not support authentication")
+}
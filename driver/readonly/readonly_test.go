@@ -0,0 +1,241 @@
+package readonly
+
+import (
+	"context"
+	"io/ioutil"
+	"os"
+	"strings"
+	"testing"
+
+	"github.com/flimzy/diff"
+	"github.com/go-kivik/kivik"
+	"github.com/go-kivik/kivik/driver"
+	_ "github.com/go-kivik/kivik/driver/fs"
+	"github.com/go-kivik/kivik/mock"
+)
+
+// newTestDB returns a read-only handle to a database of the fs driver, in a
+// new temporary directory, seeded with a single document, the backend
+// database, and a function to remove the directory.
+func newTestDB(t *testing.T) (*kivik.DB, *kivik.DB, func()) {
+	dir, err := ioutil.TempDir("", "kivik-readonly-")
+	if err != nil {
+		t.Fatal(err)
+	}
+	cleanup := func() { _ = os.RemoveAll(dir) }
+	ctx := context.Background()
+	backend, err := kivik.New(ctx, "fs", dir)
+	if err != nil {
+		cleanup()
+		t.Fatal(err)
+	}
+	backendDB, err := backend.CreateDB(ctx, "test")
+	if err != nil {
+		cleanup()
+		t.Fatal(err)
+	}
+	if _, err := backendDB.Put(ctx, "foo", map[string]interface{}{"n": 1}); err != nil {
+		cleanup()
+		t.Fatal(err)
+	}
+	client, err := New(backend)
+	if err != nil {
+		cleanup()
+		t.Fatal(err)
+	}
+	db, err := client.DB(ctx, "test")
+	if err != nil {
+		cleanup()
+		t.Fatal(err)
+	}
+	return db, backendDB, cleanup
+}
+
+func TestReads(t *testing.T) {
+	db, _, cleanup := newTestDB(t)
+	defer cleanup()
+	ctx := context.Background()
+	var doc struct {
+		N int `json:"n"`
+	}
+	if err := db.Get(ctx, "foo").ScanDoc(&doc); err != nil {
+		t.Fatal(err)
+	}
+	if doc.N != 1 {
+		t.Errorf("Unexpected document: %+v", doc)
+	}
+	rows, err := db.Find(ctx, map[string]interface{}{
+		"selector": map[string]interface{}{"n": 1},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	var ids []string
+	for rows.Next() {
+		ids = append(ids, rows.ID())
+	}
+	if err := rows.Err(); err != nil {
+		t.Fatal(err)
+	}
+	if d := diff.Interface([]string{"foo"}, ids); d != nil {
+		t.Error(d)
+	}
+	if _, err := db.GetMeta(ctx, "foo"); err != nil {
+		t.Error(err)
+	}
+	if _, err := db.Client().AllDBs(ctx); err != nil {
+		t.Error(err)
+	}
+}
+
+func TestWrites(t *testing.T) {
+	tests := []struct {
+		name string
+		call func(*kivik.DB) error
+	}{
+		{
+			name: "Put",
+			call: func(db *kivik.DB) error {
+				_, err := db.Put(context.Background(), "bar", map[string]interface{}{})
+				return err
+			},
+		},
+		{
+			name: "CreateDoc",
+			call: func(db *kivik.DB) error {
+				_, _, err := db.CreateDoc(context.Background(), map[string]interface{}{})
+				return err
+			},
+		},
+		{
+			name: "Delete",
+			call: func(db *kivik.DB) error {
+				rev, err := db.Rev(context.Background(), "foo")
+				if err != nil {
+					return err
+				}
+				_, err = db.Delete(context.Background(), "foo", rev)
+				return err
+			},
+		},
+		{
+			name: "Copy",
+			call: func(db *kivik.DB) error {
+				_, err := db.Copy(context.Background(), "bar", "foo")
+				return err
+			},
+		},
+		{
+			name: "BulkDocs",
+			call: func(db *kivik.DB) error {
+				_, err := db.BulkDocs(context.Background(), []interface{}{map[string]interface{}{"_id": "bar"}})
+				return err
+			},
+		},
+		{
+			name: "SetSecurity",
+			call: func(db *kivik.DB) error {
+				return db.SetSecurity(context.Background(), &kivik.Security{})
+			},
+		},
+		{
+			name: "PutAttachment",
+			call: func(db *kivik.DB) error {
+				_, err := db.PutAttachment(context.Background(), "foo", "", &kivik.Attachment{
+					Filename: "foo.txt",
+					Content:  ioutil.NopCloser(strings.NewReader("foo")),
+				})
+				return err
+			},
+		},
+		{
+			name: "CreateIndex",
+			call: func(db *kivik.DB) error {
+				return db.CreateIndex(context.Background(), "", "", map[string]interface{}{"fields": []string{"n"}})
+			},
+		},
+		{
+			name: "CreateDB",
+			call: func(db *kivik.DB) error {
+				_, err := db.Client().CreateDB(context.Background(), "other")
+				return err
+			},
+		},
+		{
+			name: "DestroyDB",
+			call: func(db *kivik.DB) error {
+				return db.Client().DestroyDB(context.Background(), "test")
+			},
+		},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			db, backendDB, cleanup := newTestDB(t)
+			defer cleanup()
+			err := test.call(db)
+			if status := kivik.StatusCode(err); status != kivik.StatusForbidden {
+				t.Errorf("Unexpected status: %d (%v)", status, err)
+			}
+			stats, err := backendDB.Stats(context.Background())
+			if err != nil {
+				t.Fatal(err)
+			}
+			if stats.DocCount != 1 || stats.UpdateSeq != "1" {
+				t.Errorf("Backend was modified: %d documents, update seq %s", stats.DocCount, stats.UpdateSeq)
+			}
+		})
+	}
+}
+
+// bulkGetter is a backend database which implements driver.BulkGetter.
+type bulkGetter struct {
+	*mock.DB
+}
+
+func (b *bulkGetter) BulkGet(_ context.Context, _ []driver.BulkGetReference, _ map[string]interface{}) (driver.Rows, error) {
+	return &mock.Rows{}, nil
+}
+
+func TestOptionalInterfaces(t *testing.T) {
+	tests := []struct {
+		name       string
+		backend    driver.DB
+		meta       bool
+		bulkGetter bool
+	}{
+		{
+			name:    "none",
+			backend: &mock.DB{},
+		},
+		{
+			name:    "meta getter",
+			backend: &mock.MetaGetter{DB: &mock.DB{}},
+			meta:    true,
+		},
+		{
+			name:    "document meta getter",
+			backend: &mock.DocumentMetaGetter{DB: &mock.DB{}},
+			meta:    true,
+		},
+		{
+			name:       "bulk getter",
+			backend:    &bulkGetter{DB: &mock.DB{}},
+			bulkGetter: true,
+		},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			db := newReadonlyDB(test.backend)
+			if _, ok := db.(driver.DocumentMetaGetter); ok != test.meta {
+				t.Errorf("DocumentMetaGetter declared: %t", ok)
+			}
+			if _, ok := db.(driver.BulkGetter); ok != test.bulkGetter {
+				t.Errorf("BulkGetter declared: %t", ok)
+			}
+			_, err := db.(driver.Partitioner).PartitionStats(context.Background(), "foo")
+			if status := kivik.StatusCode(err); status != kivik.StatusNotImplemented {
+				t.Errorf("Unexpected status: %d (%v)", status, err)
+			}
+		})
+	}
+}
@@ -18,6 +18,9 @@ type Row struct {
 	// Doc is the raw, un-decoded JSON document. This is only populated by views
 	// which return docs, such as /_all_docs?include_docs=true.
 	Doc json.RawMessage `json:"doc"`
+	// Error represents the error for any row not fetched, such as by a
+	// BulkGet request for a non-existent document.
+	Error error `json:"-"`
 }
 
 // SequenceID is a CouchDB update sequence ID. This is just a string, but has
@@ -33,19 +36,31 @@ func (id *SequenceID) UnmarshalJSON(data []byte) error {
 }
 
 // Rows is an iterator over a view's results.
+//
+// Drivers are encouraged to decode results incrementally, as they are read
+// from the network, rather than buffering the entire response, so that memory
+// use does not grow with the size of the result set.
 type Rows interface {
 	// Next is called to populate row with the next row in the result set.
 	//
+	// Next may block while waiting for the row to be read from the network.
+	// It should return promptly, with an error, if the context of the
+	// originating request is cancelled, or if Close is called.
+	//
 	// Next should return io.EOF when there are no more rows.
 	Next(row *Row) error
-	// Close closes the rows iterator.
+	// Close closes the rows iterator, releasing any underlying connection.
+	// It may be called before all rows have been read.
 	Close() error
 	// UpdateSeq is the update sequence of the database, if requested in the
-	// result set.
+	// result set. A streaming driver need only report it once Next has
+	// returned io.EOF.
 	UpdateSeq() string
-	// Offset is the offset where the result set starts.
+	// Offset is the offset where the result set starts. A streaming driver
+	// need only report it once Next has returned io.EOF.
 	Offset() int64
-	// TotalRows is the number of documents in the database/view.
+	// TotalRows is the number of documents in the database/view. A streaming
+	// driver need only report it once Next has returned io.EOF.
 	TotalRows() int64
 }
 
@@ -0,0 +1,75 @@
+package driver
+
+import (
+	"context"
+	"time"
+)
+
+// SchedulerJobEvent is an event in a replication job's history.
+type SchedulerJobEvent struct {
+	Type      string    `json:"type"`
+	Reason    string    `json:"reason"`
+	Timestamp time.Time `json:"timestamp"`
+}
+
+// SchedulerJob represents a replication job, as returned by the
+// /_scheduler/jobs endpoint.
+type SchedulerJob struct {
+	Database  string              `json:"database"`
+	JobID     string              `json:"id"`
+	PID       string              `json:"pid"`
+	Source    string              `json:"source"`
+	Target    string              `json:"target"`
+	User      string              `json:"user"`
+	DocID     string              `json:"doc_id"`
+	Node      string              `json:"node"`
+	StartTime time.Time           `json:"start_time"`
+	History   []SchedulerJobEvent `json:"history"`
+}
+
+// SchedulerDoc represents the state of a replication document, as returned
+// by the /_scheduler/docs endpoint.
+type SchedulerDoc struct {
+	Database      string                 `json:"database"`
+	DocID         string                 `json:"doc_id"`
+	ReplicationID string                 `json:"id"`
+	Node          string                 `json:"node"`
+	Source        string                 `json:"source"`
+	Target        string                 `json:"target"`
+	State         string                 `json:"state"`
+	ErrorCount    int                    `json:"error_count"`
+	StartTime     time.Time              `json:"start_time"`
+	LastUpdated   time.Time              `json:"last_updated"`
+	Info          map[string]interface{} `json:"info"`
+}
+
+// SchedulerJobs is an iterator over replication jobs.
+type SchedulerJobs interface {
+	// Next is called to populate job with the next job in the result set.
+	//
+	// Next should return io.EOF when there are no more jobs.
+	Next(job *SchedulerJob) error
+	// Close closes the iterator.
+	Close() error
+}
+
+// SchedulerDocs is an iterator over replication documents.
+type SchedulerDocs interface {
+	// Next is called to populate doc with the next document in the result
+	// set.
+	//
+	// Next should return io.EOF when there are no more documents.
+	Next(doc *SchedulerDoc) error
+	// Close closes the iterator.
+	Close() error
+}
+
+// Scheduler is an optional interface that may be implemented by a Client to
+// provide access to the replication scheduler, introduced in CouchDB 2.1.
+type Scheduler interface {
+	// SchedulerJobs returns an iterator over running replication jobs.
+	SchedulerJobs(ctx context.Context, options map[string]interface{}) (SchedulerJobs, error)
+	// SchedulerDocs returns an iterator over the replication documents in
+	// the named replicator database.
+	SchedulerDocs(ctx context.Context, replicatorDB string, options map[string]interface{}) (SchedulerDocs, error)
+}
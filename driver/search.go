@@ -0,0 +1,31 @@
+package driver
+
+import (
+	"context"
+	"encoding/json"
+)
+
+// Searcher is an optional interface which may be implemented by a DB, to
+// support full text search, as provided by Cloudant Search, and the _search
+// endpoint of CouchDB 3.x.
+type Searcher interface {
+	// Search queries the search index named index, in design document ddoc,
+	// with the Lucene query syntax. The ID of each row should be set to the
+	// document ID, and the Value to the raw JSON search result, including the
+	// "order", "fields" and "highlights" keys, as returned by the server.
+	// The Doc should be set if include_docs was requested.
+	//
+	// The returned Rows should implement Bookmarker, and SearchFacets if
+	// counts or ranges were requested.
+	Search(ctx context.Context, ddoc, index, query string, options map[string]interface{}) (Rows, error)
+}
+
+// SearchFacets is an optional interface that may be implemented by the Rows
+// returned by Searcher, to report faceted results. Both methods need only be
+// valid after Next has returned io.EOF.
+type SearchFacets interface {
+	// Counts returns the raw "counts" object of the search response, or nil.
+	Counts() json.RawMessage
+	// Ranges returns the raw "ranges" object of the search response, or nil.
+	Ranges() json.RawMessage
+}
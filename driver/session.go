@@ -31,3 +31,11 @@ type Sessioner interface {
 	// Session returns information about the authenticated user.
 	Session(ctx context.Context) (*Session, error)
 }
+
+// SessionDeleter is an optional interface that a Client may satisfy to allow
+// terminating the authenticated session.
+type SessionDeleter interface {
+	// DeleteSession ends the current authenticated session, such as by
+	// expiring a session cookie.
+	DeleteSession(ctx context.Context) error
+}
@@ -0,0 +1,253 @@
+package shard
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"sort"
+	"strconv"
+
+	"github.com/go-kivik/kivik"
+	"github.com/go-kivik/kivik/driver"
+	"github.com/go-kivik/kivik/errors"
+)
+
+// rowsIter is a driver iterator over rows merged from every shard.
+type rowsIter struct {
+	rows      []*driver.Row
+	offset    int64
+	totalRows int64
+	updateSeq string
+}
+
+var _ driver.Rows = &rowsIter{}
+
+func (r *rowsIter) Next(row *driver.Row) error {
+	if len(r.rows) == 0 {
+		return io.EOF
+	}
+	*row, r.rows = *r.rows[0], r.rows[1:]
+	return nil
+}
+
+func (r *rowsIter) Close() error {
+	r.rows = nil
+	return nil
+}
+
+func (r *rowsIter) UpdateSeq() string { return r.updateSeq }
+func (r *rowsIter) Offset() int64     { return r.offset }
+func (r *rowsIter) TotalRows() int64  { return r.totalRows }
+
+// readRows calls fn with each row of rows, then closes it.
+func readRows(rows driver.Rows, fn func(*driver.Row)) error {
+	defer rows.Close() // nolint: errcheck
+	for {
+		row := new(driver.Row)
+		if err := rows.Next(row); err != nil {
+			if err == io.EOF {
+				return nil
+			}
+			return err
+		}
+		fn(row)
+	}
+}
+
+func boolOpt(opts map[string]interface{}, key string) bool {
+	switch t := opts[key].(type) {
+	case bool:
+		return t
+	case string:
+		b, _ := strconv.ParseBool(t)
+		return b
+	}
+	return false
+}
+
+func intOpt(opts map[string]interface{}, key string) (int64, error) {
+	switch t := opts[key].(type) {
+	case nil:
+		return 0, nil
+	case int:
+		return int64(t), nil
+	case int64:
+		return t, nil
+	case float64:
+		return int64(t), nil
+	case string:
+		n, err := strconv.ParseInt(t, 10, 64)
+		if err != nil {
+			return 0, errors.Statusf(kivik.StatusBadRequest, "shard: invalid value for %s: %s", key, t)
+		}
+		return n, nil
+	}
+	return 0, errors.Statusf(kivik.StatusBadRequest, "shard: invalid value for %s: %v", key, opts[key])
+}
+
+// keysOpt returns the document IDs requested by the keys or key option, or
+// nil if neither is set. Keys may be passed JSON-encoded, or as plain
+// strings.
+func keysOpt(opts map[string]interface{}) ([]string, error) {
+	if value, ok := opts["keys"]; ok {
+		var data []byte
+		if s, ok := value.(string); ok {
+			data = []byte(s)
+		} else {
+			data, _ = json.Marshal(value)
+		}
+		var keys []string
+		if err := json.Unmarshal(data, &keys); err != nil {
+			return nil, errors.Status(kivik.StatusBadRequest, "shard: keys must be an array of strings")
+		}
+		return keys, nil
+	}
+	value, ok := opts["key"]
+	if !ok {
+		return nil, nil
+	}
+	if s, ok := value.(string); ok {
+		var key string
+		if json.Unmarshal([]byte(s), &key) == nil {
+			return []string{key}, nil
+		}
+		return []string{s}, nil
+	}
+	var key string
+	data, _ := json.Marshal(value)
+	if err := json.Unmarshal(data, &key); err != nil {
+		return nil, errors.Status(kivik.StatusBadRequest, "shard: invalid value for key")
+	}
+	return []string{key}, nil
+}
+
+// AllDocs queries every shard, and merges the results. The skip option is
+// applied after merging, so each shard is asked for up to skip+limit rows.
+// Results are read fully into memory before being returned.
+func (d *db) AllDocs(ctx context.Context, opts map[string]interface{}) (driver.Rows, error) {
+	skip, err := intOpt(opts, "skip")
+	if err != nil {
+		return nil, err
+	}
+	limit, err := intOpt(opts, "limit")
+	if err != nil {
+		return nil, err
+	}
+	_, hasLimit := opts["limit"]
+	keys, err := keysOpt(opts)
+	if err != nil {
+		return nil, err
+	}
+	shardOpts := make(map[string]interface{}, len(opts))
+	for k, v := range opts {
+		shardOpts[k] = v
+	}
+	delete(shardOpts, "skip")
+	if hasLimit {
+		shardOpts["limit"] = skip + limit
+	}
+	var result *rowsIter
+	if keys != nil {
+		delete(shardOpts, "key")
+		result, err = d.allDocsByKeys(ctx, keys, shardOpts)
+	} else {
+		result, err = d.allDocsMerged(ctx, shardOpts, boolOpt(opts, "descending"))
+	}
+	if err != nil {
+		return nil, err
+	}
+	if skip > int64(len(result.rows)) {
+		skip = int64(len(result.rows))
+	}
+	result.rows = result.rows[skip:]
+	result.offset += skip
+	if hasLimit && limit < int64(len(result.rows)) {
+		result.rows = result.rows[:limit]
+	}
+	return result, nil
+}
+
+// allDocsMerged queries every shard, and merge-sorts the rows by document ID.
+func (d *db) allDocsMerged(ctx context.Context, opts map[string]interface{}, descending bool) (*rowsIter, error) {
+	result := &rowsIter{}
+	seqs := make([]string, len(d.shards))
+	for i, shard := range d.shards {
+		rows, err := shard.AllDocs(ctx, opts)
+		if err != nil {
+			return nil, err
+		}
+		if err := readRows(rows, func(row *driver.Row) {
+			result.rows = append(result.rows, row)
+		}); err != nil {
+			return nil, err
+		}
+		result.offset += rows.Offset()
+		result.totalRows += rows.TotalRows()
+		seqs[i] = rows.UpdateSeq()
+	}
+	sort.SliceStable(result.rows, func(i, j int) bool {
+		if descending {
+			return result.rows[i].ID > result.rows[j].ID
+		}
+		return result.rows[i].ID < result.rows[j].ID
+	})
+	result.updateSeq = encodeUpdateSeq(seqs)
+	return result, nil
+}
+
+// allDocsByKeys asks each shard for the keys it owns, and returns the rows
+// in the order of keys.
+func (d *db) allDocsByKeys(ctx context.Context, keys []string, opts map[string]interface{}) (*rowsIter, error) {
+	// Every shard is queried, even with no keys, for its total rows.
+	parts := make([][]string, len(d.shards))
+	for i := range parts {
+		parts[i] = []string{}
+	}
+	for _, key := range keys {
+		i := d.ring.locate(key)
+		parts[i] = append(parts[i], key)
+	}
+	queues := make([][]*driver.Row, len(d.shards))
+	result := &rowsIter{}
+	seqs := make([]string, len(d.shards))
+	for i, shard := range d.shards {
+		shardOpts := make(map[string]interface{}, len(opts))
+		for k, v := range opts {
+			shardOpts[k] = v
+		}
+		shardOpts["keys"] = parts[i]
+		delete(shardOpts, "limit")
+		rows, err := shard.AllDocs(ctx, shardOpts)
+		if err != nil {
+			return nil, err
+		}
+		if err := readRows(rows, func(row *driver.Row) {
+			queues[i] = append(queues[i], row)
+		}); err != nil {
+			return nil, err
+		}
+		result.totalRows += rows.TotalRows()
+		seqs[i] = rows.UpdateSeq()
+	}
+	for _, key := range keys {
+		i := d.ring.locate(key)
+		if len(queues[i]) == 0 {
+			break
+		}
+		result.rows = append(result.rows, queues[i][0])
+		queues[i] = queues[i][1:]
+	}
+	result.updateSeq = encodeUpdateSeq(seqs)
+	return result, nil
+}
+
+// encodeUpdateSeq returns the composite of seqs, or an empty string if no
+// shard reported its update sequence.
+func encodeUpdateSeq(seqs []string) string {
+	for _, seq := range seqs {
+		if seq != "" {
+			return encodeSeq(seqs)
+		}
+	}
+	return ""
+}
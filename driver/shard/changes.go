@@ -0,0 +1,225 @@
+package shard
+
+import (
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"io"
+	"sync"
+
+	"github.com/go-kivik/kivik"
+	"github.com/go-kivik/kivik/driver"
+	"github.com/go-kivik/kivik/errors"
+)
+
+// encodeSeq returns an opaque composite of the sequence ID of each shard.
+func encodeSeq(seqs []string) string {
+	data, _ := json.Marshal(seqs)
+	return base64.RawURLEncoding.EncodeToString(data)
+}
+
+// decodeSeq returns the sequence ID of each of n shards, from a composite
+// returned by encodeSeq. The special values 0 and now, and an empty string,
+// apply to every shard.
+func decodeSeq(since interface{}, n int) ([]string, error) {
+	var s string
+	switch t := since.(type) {
+	case nil:
+	case string:
+		s = t
+	default:
+		s = fmt.Sprint(t)
+	}
+	seqs := make([]string, n)
+	switch s {
+	case "", "0", "now":
+		for i := range seqs {
+			seqs[i] = s
+		}
+		return seqs, nil
+	}
+	data, err := base64.RawURLEncoding.DecodeString(s)
+	if err == nil {
+		err = json.Unmarshal(data, &seqs)
+	}
+	if err != nil || len(seqs) != n {
+		return nil, errors.Statusf(kivik.StatusBadRequest, "shard: invalid sequence ID %q", s)
+	}
+	return seqs, nil
+}
+
+// currentSeq returns the current sequence ID of shard, read from its changes
+// feed since now, or now if the shard reports none.
+func currentSeq(ctx context.Context, shard driver.DB) (string, error) {
+	feed, err := shard.Changes(ctx, map[string]interface{}{"since": "now"})
+	if err != nil {
+		return "", err
+	}
+	defer feed.Close() // nolint: errcheck
+	for {
+		if err := feed.Next(new(driver.Change)); err != nil {
+			if err != io.EOF {
+				return "", err
+			}
+			break
+		}
+	}
+	if seq := feed.LastSeq(); seq != "" {
+		return seq, nil
+	}
+	return "now", nil
+}
+
+// Changes opens the changes feed of every shard, and merges them in the
+// order in which changes arrive. Each change's sequence ID is the composite
+// of the last sequence ID read from each shard, so that it may be passed
+// back as since, to resume the merged feed. A since of now is resolved to
+// each shard's current sequence ID first, so that the composite does not
+// refer to now for shards with no changes. A longpoll feed ends as soon as
+// the feed of any shard ends.
+func (d *db) Changes(ctx context.Context, opts map[string]interface{}) (driver.Changes, error) {
+	seqs, err := decodeSeq(opts["since"], len(d.shards))
+	if err != nil {
+		return nil, err
+	}
+	limit, err := intOpt(opts, "limit")
+	if err != nil {
+		return nil, err
+	}
+	_, hasLimit := opts["limit"]
+	feed, _ := opts["feed"].(string)
+	// The shard feeds are cancelled on Close, as a feed blocked waiting for
+	// changes cannot otherwise be closed.
+	ctx, cancel := context.WithCancel(ctx)
+	c := &changesIter{
+		cancel:   cancel,
+		feeds:    make([]driver.Changes, 0, len(d.shards)),
+		results:  make(chan changeResult),
+		done:     make(chan struct{}),
+		seqs:     seqs,
+		limit:    limit,
+		hasLimit: hasLimit,
+		longpoll: feed == "longpoll",
+	}
+	for i, shard := range d.shards {
+		shardOpts := make(map[string]interface{}, len(opts))
+		for k, v := range opts {
+			shardOpts[k] = v
+		}
+		if seqs[i] == "now" {
+			if seqs[i], err = currentSeq(ctx, shard); err != nil {
+				_ = c.Close()
+				return nil, err
+			}
+		}
+		if seqs[i] == "" {
+			delete(shardOpts, "since")
+		} else {
+			shardOpts["since"] = seqs[i]
+		}
+		feed, err := shard.Changes(ctx, shardOpts)
+		if err != nil {
+			_ = c.Close()
+			return nil, err
+		}
+		c.feeds = append(c.feeds, feed)
+	}
+	c.active = len(c.feeds)
+	for i, feed := range c.feeds {
+		go c.read(i, feed)
+	}
+	return c, nil
+}
+
+type changeResult struct {
+	shard  int
+	change *driver.Change
+	err    error
+}
+
+type changesIter struct {
+	feeds     []driver.Changes
+	results   chan changeResult
+	done      chan struct{}
+	cancel    context.CancelFunc
+	closeOnce sync.Once
+
+	active   int
+	seqs     []string
+	pending  int64
+	limit    int64
+	hasLimit bool
+	count    int64
+	longpoll bool
+}
+
+var _ driver.Changes = &changesIter{}
+
+// read sends each change from the feed of shard i to the merged feed, until
+// the feed ends, or the merged feed is closed.
+func (c *changesIter) read(i int, feed driver.Changes) {
+	for {
+		change := new(driver.Change)
+		err := feed.Next(change)
+		select {
+		case c.results <- changeResult{shard: i, change: change, err: err}:
+		case <-c.done:
+			return
+		}
+		if err != nil {
+			return
+		}
+	}
+}
+
+func (c *changesIter) Next(change *driver.Change) error {
+	for {
+		if c.active == 0 || (c.hasLimit && c.count >= c.limit) {
+			return io.EOF
+		}
+		var result changeResult
+		select {
+		case result = <-c.results:
+		case <-c.done:
+			return io.EOF
+		}
+		if result.err != nil {
+			if result.err != io.EOF {
+				return result.err
+			}
+			feed := c.feeds[result.shard]
+			if seq := feed.LastSeq(); seq != "" {
+				c.seqs[result.shard] = seq
+			}
+			c.pending += feed.Pending()
+			c.active--
+			if c.longpoll {
+				c.active = 0
+			}
+			continue
+		}
+		c.count++
+		c.seqs[result.shard] = string(result.change.Seq)
+		*change = *result.change
+		change.Seq = driver.SequenceID(encodeSeq(c.seqs))
+		return nil
+	}
+}
+
+func (c *changesIter) Close() error {
+	var err error
+	c.closeOnce.Do(func() {
+		close(c.done)
+		c.cancel()
+		for _, feed := range c.feeds {
+			if e := feed.Close(); e != nil && e != context.Canceled && err == nil {
+				err = e
+			}
+		}
+	})
+	return err
+}
+
+func (c *changesIter) LastSeq() string { return encodeSeq(c.seqs) }
+func (c *changesIter) Pending() int64  { return c.pending }
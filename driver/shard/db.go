@@ -0,0 +1,273 @@
+package shard
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"io/ioutil"
+	"sort"
+
+	"github.com/go-kivik/kivik"
+	"github.com/go-kivik/kivik/driver"
+	"github.com/go-kivik/kivik/errors"
+)
+
+// db is a database sharded by document ID, with one part on each backend.
+type db struct {
+	name   string
+	shards []driver.DB
+	ring   *ring
+}
+
+var (
+	_ driver.DB         = &db{}
+	_ driver.DBCloser   = &db{}
+	_ driver.OpenRever  = &db{}
+	_ driver.RevsDiffer = &db{}
+)
+
+// shard returns the part of the database which holds docID.
+func (d *db) shard(docID string) driver.DB {
+	return d.shards[d.ring.locate(docID)]
+}
+
+// each calls fn for each part of the database in turn, stopping at the
+// first error.
+func (d *db) each(fn func(driver.DB) error) error {
+	for _, shard := range d.shards {
+		if err := fn(shard); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (d *db) Close(ctx context.Context) error {
+	return d.each(func(shard driver.DB) error {
+		if closer, ok := shard.(driver.DBCloser); ok {
+			return closer.Close(ctx)
+		}
+		return nil
+	})
+}
+
+func (d *db) Get(ctx context.Context, docID string, opts map[string]interface{}) (*driver.Document, error) {
+	return d.shard(docID).Get(ctx, docID, opts)
+}
+
+// metaGetterDB is a db whose shards all implement driver.MetaGetter.
+type metaGetterDB struct {
+	*db
+}
+
+var _ driver.MetaGetter = &metaGetterDB{}
+
+func (d *metaGetterDB) GetMeta(ctx context.Context, docID string, opts map[string]interface{}) (int64, string, error) {
+	return d.shard(docID).(driver.MetaGetter).GetMeta(ctx, docID, opts)
+}
+
+// documentMetaGetterDB is a db whose shards all implement
+// driver.DocumentMetaGetter.
+type documentMetaGetterDB struct {
+	*db
+}
+
+var _ driver.DocumentMetaGetter = &documentMetaGetterDB{}
+
+func (d *documentMetaGetterDB) GetDocumentMeta(ctx context.Context, docID string, opts map[string]interface{}) (*driver.DocumentMeta, error) {
+	return d.shard(docID).(driver.DocumentMetaGetter).GetDocumentMeta(ctx, docID, opts)
+}
+
+// withMeta returns d, wrapped to declare the meta interface which all of its
+// shards implement. Where they do not, d is returned as is, and kivik falls
+// back to fetching the document.
+func (d *db) withMeta() driver.DB {
+	docMeta, meta := true, true
+	for _, shard := range d.shards {
+		_, ok := shard.(driver.DocumentMetaGetter)
+		docMeta = docMeta && ok
+		_, ok = shard.(driver.MetaGetter)
+		meta = meta && ok
+	}
+	switch {
+	case docMeta:
+		return &documentMetaGetterDB{d}
+	case meta:
+		return &metaGetterDB{d}
+	}
+	return d
+}
+
+func (d *db) OpenRevs(ctx context.Context, docID string, revs []string, opts map[string]interface{}) (driver.Rows, error) {
+	if openRever, ok := d.shard(docID).(driver.OpenRever); ok {
+		return openRever.OpenRevs(ctx, docID, revs, opts)
+	}
+	return nil, errors.Status(kivik.StatusNotImplemented, "shard: open revs not supported by backend")
+}
+
+// CreateDoc generates the document ID, if doc has none, so that the
+// document's shard is known before it is stored.
+func (d *db) CreateDoc(ctx context.Context, doc interface{}, opts map[string]interface{}) (string, string, error) {
+	data, err := marshalDoc(doc)
+	if err != nil {
+		return "", "", err
+	}
+	var meta struct {
+		ID string `json:"_id"`
+	}
+	if err := json.Unmarshal(data, &meta); err != nil {
+		return "", "", errors.WrapStatus(kivik.StatusBadRequest, err)
+	}
+	docID := meta.ID
+	if docID == "" {
+		if docID, err = kivik.UUIDv4(); err != nil {
+			return "", "", err
+		}
+	}
+	rev, err := d.shard(docID).Put(ctx, docID, json.RawMessage(data), opts)
+	return docID, rev, err
+}
+
+// marshalDoc returns doc as JSON, from any of the types accepted by Put.
+func marshalDoc(doc interface{}) ([]byte, error) {
+	switch t := doc.(type) {
+	case json.RawMessage:
+		return t, nil
+	case []byte:
+		return t, nil
+	case io.Reader:
+		data, err := ioutil.ReadAll(t)
+		if err != nil {
+			return nil, errors.WrapStatus(kivik.StatusUnknownError, err)
+		}
+		return data, nil
+	}
+	data, err := json.Marshal(doc)
+	if err != nil {
+		return nil, errors.WrapStatus(kivik.StatusBadRequest, err)
+	}
+	return data, nil
+}
+
+func (d *db) Put(ctx context.Context, docID string, doc interface{}, opts map[string]interface{}) (string, error) {
+	return d.shard(docID).Put(ctx, docID, doc, opts)
+}
+
+func (d *db) Delete(ctx context.Context, docID, rev string, opts map[string]interface{}) (string, error) {
+	return d.shard(docID).Delete(ctx, docID, rev, opts)
+}
+
+func (d *db) PutAttachment(ctx context.Context, docID, rev string, att *driver.Attachment, opts map[string]interface{}) (string, error) {
+	return d.shard(docID).PutAttachment(ctx, docID, rev, att, opts)
+}
+
+func (d *db) GetAttachment(ctx context.Context, docID, rev, filename string, opts map[string]interface{}) (*driver.Attachment, error) {
+	return d.shard(docID).GetAttachment(ctx, docID, rev, filename, opts)
+}
+
+func (d *db) DeleteAttachment(ctx context.Context, docID, rev, filename string, opts map[string]interface{}) (string, error) {
+	return d.shard(docID).DeleteAttachment(ctx, docID, rev, filename, opts)
+}
+
+func (d *db) Query(_ context.Context, _, _ string, _ map[string]interface{}) (driver.Rows, error) {
+	return nil, errors.Status(kivik.StatusNotImplemented, "shard: views are not supported")
+}
+
+// Stats sums the statistics of each part of the database. The update and
+// purge sequences are composites, as for Changes.
+func (d *db) Stats(ctx context.Context) (*driver.DBStats, error) {
+	result := &driver.DBStats{Name: d.name}
+	updateSeqs := make([]string, len(d.shards))
+	purgeSeqs := make([]string, len(d.shards))
+	for i, shard := range d.shards {
+		stats, err := shard.Stats(ctx)
+		if err != nil {
+			return nil, err
+		}
+		result.CompactRunning = result.CompactRunning || stats.CompactRunning
+		result.DocCount += stats.DocCount
+		result.DeletedCount += stats.DeletedCount
+		result.DiskSize += stats.DiskSize
+		result.ActiveSize += stats.ActiveSize
+		result.ExternalSize += stats.ExternalSize
+		updateSeqs[i] = string(stats.UpdateSeq)
+		purgeSeqs[i] = string(stats.PurgeSeq)
+	}
+	result.UpdateSeq = driver.SequenceID(encodeSeq(updateSeqs))
+	result.PurgeSeq = driver.SequenceID(encodeSeq(purgeSeqs))
+	return result, nil
+}
+
+func (d *db) Compact(ctx context.Context) error {
+	return d.each(func(shard driver.DB) error {
+		return shard.Compact(ctx)
+	})
+}
+
+func (d *db) CompactView(ctx context.Context, ddocID string) error {
+	return d.each(func(shard driver.DB) error {
+		return shard.CompactView(ctx, ddocID)
+	})
+}
+
+func (d *db) ViewCleanup(ctx context.Context) error {
+	return d.each(func(shard driver.DB) error {
+		return shard.ViewCleanup(ctx)
+	})
+}
+
+// Security returns the security document of the first part of the
+// database, as SetSecurity stores the same document on every part.
+func (d *db) Security(ctx context.Context) (*driver.Security, error) {
+	return d.shards[0].Security(ctx)
+}
+
+func (d *db) SetSecurity(ctx context.Context, security *driver.Security) error {
+	return d.each(func(shard driver.DB) error {
+		return shard.SetSecurity(ctx, security)
+	})
+}
+
+// RevsDiff splits revMap by shard, and merges the results, sorted by
+// document ID.
+func (d *db) RevsDiff(ctx context.Context, revMap interface{}) (driver.Rows, error) {
+	data, err := marshalDoc(revMap)
+	if err != nil {
+		return nil, err
+	}
+	var revs map[string]interface{}
+	if err := json.Unmarshal(data, &revs); err != nil {
+		return nil, errors.WrapStatus(kivik.StatusBadRequest, err)
+	}
+	parts := make([]map[string]interface{}, len(d.shards))
+	for docID, docRevs := range revs {
+		i := d.ring.locate(docID)
+		if parts[i] == nil {
+			parts[i] = make(map[string]interface{})
+		}
+		parts[i][docID] = docRevs
+	}
+	result := &rowsIter{}
+	for i, part := range parts {
+		if part == nil {
+			continue
+		}
+		rd, ok := d.shards[i].(driver.RevsDiffer)
+		if !ok {
+			return nil, errors.Status(kivik.StatusNotImplemented, "shard: _revs_diff not supported by backend")
+		}
+		rows, err := rd.RevsDiff(ctx, part)
+		if err != nil {
+			return nil, err
+		}
+		if err := readRows(rows, func(row *driver.Row) {
+			result.rows = append(result.rows, row)
+		}); err != nil {
+			return nil, err
+		}
+	}
+	sort.Slice(result.rows, func(i, j int) bool {
+		return result.rows[i].ID < result.rows[j].ID
+	})
+	return result, nil
+}
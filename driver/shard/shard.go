@@ -0,0 +1,241 @@
+// Package shard provides a kivik driver which spreads data across several
+// backend kivik Clients, to scale beyond a single server at the client
+// layer:
+//
+//	client, err := shard.New(shard.ByDocument, node1, node2, node3)
+//
+// Placement is by consistent hashing, so that adding a backend moves only
+// a fraction of the data. Each backend is identified on the hash ring by its
+// driver name and data source name, which must therefore be distinct, and
+// must not change between runs, or data will be sought on the wrong
+// backend.
+//
+// In ByDatabase mode, each database is stored whole on one backend, and
+// every call is forwarded to that backend. AllDBs lists the databases of
+// every backend.
+//
+// In ByDocument mode, each database is created on every backend, and each
+// document is stored on the backend to which its ID hashes. Document and
+// attachment calls are forwarded to that backend. AllDocs and Changes fan out
+// to every backend: AllDocs results are merge-sorted by document ID, and
+// Changes sequence IDs are composites of each backend's sequence, so may be
+// passed back as the since option. Views are not supported in this mode, as
+// a view's rows cannot be merged without its reduce function, and neither is
+// Find. Database-level writes, such as CreateDB or SetSecurity, are applied
+// to each backend in turn, and are not rolled back if one fails.
+package shard
+
+import (
+	"context"
+	"fmt"
+	"hash/crc32"
+	"sort"
+
+	"github.com/go-kivik/kivik"
+	"github.com/go-kivik/kivik/driver"
+	"github.com/go-kivik/kivik/driver/proxy"
+	"github.com/go-kivik/kivik/errors"
+	"github.com/go-kivik/kivik/internal/registry"
+)
+
+// DriverName is the name under which the shard driver is registered.
+const DriverName = "shard"
+
+// Version is the version reported by the driver.
+const Version = "0.0.1"
+
+// Vendor is the vendor string reported by the driver.
+const Vendor = "Kivik Shard"
+
+// Mode determines the unit of data placed on a single backend.
+type Mode int
+
+const (
+	// ByDocument places each document on the backend to which its ID
+	// hashes.
+	ByDocument Mode = iota
+	// ByDatabase places each database on the backend to which its name
+	// hashes.
+	ByDatabase
+)
+
+type config struct {
+	mode     Mode
+	backends []*kivik.Client
+}
+
+var configs = registry.New(DriverName, func(v interface{}) (driver.Client, error) {
+	cfg := v.(*config)
+	c := &client{
+		mode:     cfg.mode,
+		backends: make([]driver.Client, len(cfg.backends)),
+		ring:     newRing(cfg.backends),
+	}
+	for i, backend := range cfg.backends {
+		c.backends[i] = proxy.NewClient(backend)
+	}
+	return c, nil
+})
+
+func init() {
+	kivik.Register(DriverName, configs.Driver())
+}
+
+// New returns a kivik client which spreads data across backends, according
+// to mode.
+func New(mode Mode, backends ...*kivik.Client) (*kivik.Client, error) {
+	if len(backends) == 0 {
+		return nil, errors.Status(kivik.StatusBadRequest, "shard: at least one backend required")
+	}
+	seen := make(map[string]bool, len(backends))
+	for _, backend := range backends {
+		if backend == nil {
+			return nil, errors.Status(kivik.StatusBadRequest, "shard: backend must not be nil")
+		}
+		name := backendName(backend)
+		if seen[name] {
+			return nil, errors.Statusf(kivik.StatusBadRequest, "shard: duplicate backend %s", name)
+		}
+		seen[name] = true
+	}
+	return configs.NewClient(context.Background(), &config{mode: mode, backends: backends})
+}
+
+func backendName(c *kivik.Client) string {
+	return c.Driver() + ":" + c.DSN()
+}
+
+// pointsPerBackend is the number of points each backend is given on the
+// hash ring, to spread keys evenly.
+const pointsPerBackend = 64
+
+// ring is a consistent hash ring.
+type ring struct {
+	points []uint32
+	owners []int
+}
+
+func newRing(backends []*kivik.Client) *ring {
+	type point struct {
+		hash  uint32
+		owner int
+	}
+	points := make([]point, 0, len(backends)*pointsPerBackend)
+	for i, backend := range backends {
+		name := backendName(backend)
+		for j := 0; j < pointsPerBackend; j++ {
+			points = append(points, point{
+				hash:  crc32.ChecksumIEEE([]byte(fmt.Sprintf("%s#%d", name, j))),
+				owner: i,
+			})
+		}
+	}
+	sort.Slice(points, func(i, j int) bool { return points[i].hash < points[j].hash })
+	r := &ring{
+		points: make([]uint32, len(points)),
+		owners: make([]int, len(points)),
+	}
+	for i, p := range points {
+		r.points[i], r.owners[i] = p.hash, p.owner
+	}
+	return r
+}
+
+// locate returns the index of the backend which owns key.
+func (r *ring) locate(key string) int {
+	hash := crc32.ChecksumIEEE([]byte(key))
+	i := sort.Search(len(r.points), func(i int) bool { return r.points[i] >= hash })
+	if i == len(r.points) {
+		i = 0
+	}
+	return r.owners[i]
+}
+
+type client struct {
+	mode     Mode
+	backends []driver.Client
+	ring     *ring
+}
+
+var _ driver.Client = &client{}
+
+func (c *client) Version(_ context.Context) (*driver.Version, error) {
+	return &driver.Version{
+		Version: Version,
+		Vendor:  Vendor,
+	}, nil
+}
+
+func (c *client) AllDBs(ctx context.Context, opts map[string]interface{}) ([]string, error) {
+	seen := make(map[string]bool)
+	var dbs []string
+	for _, backend := range c.backends {
+		names, err := backend.AllDBs(ctx, opts)
+		if err != nil {
+			return nil, err
+		}
+		for _, name := range names {
+			if !seen[name] {
+				seen[name] = true
+				dbs = append(dbs, name)
+			}
+		}
+	}
+	sort.Strings(dbs)
+	return dbs, nil
+}
+
+func (c *client) DBExists(ctx context.Context, dbName string, opts map[string]interface{}) (bool, error) {
+	if c.mode == ByDatabase {
+		return c.backends[c.ring.locate(dbName)].DBExists(ctx, dbName, opts)
+	}
+	for _, backend := range c.backends {
+		exists, err := backend.DBExists(ctx, dbName, opts)
+		if err != nil || !exists {
+			return false, err
+		}
+	}
+	return true, nil
+}
+
+func (c *client) CreateDB(ctx context.Context, dbName string, opts map[string]interface{}) error {
+	if c.mode == ByDatabase {
+		return c.backends[c.ring.locate(dbName)].CreateDB(ctx, dbName, opts)
+	}
+	for _, backend := range c.backends {
+		if err := backend.CreateDB(ctx, dbName, opts); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (c *client) DestroyDB(ctx context.Context, dbName string, opts map[string]interface{}) error {
+	if c.mode == ByDatabase {
+		return c.backends[c.ring.locate(dbName)].DestroyDB(ctx, dbName, opts)
+	}
+	for _, backend := range c.backends {
+		if err := backend.DestroyDB(ctx, dbName, opts); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (c *client) DB(ctx context.Context, dbName string, opts map[string]interface{}) (driver.DB, error) {
+	if c.mode == ByDatabase {
+		return c.backends[c.ring.locate(dbName)].DB(ctx, dbName, opts)
+	}
+	d := &db{
+		name:   dbName,
+		shards: make([]driver.DB, len(c.backends)),
+		ring:   c.ring,
+	}
+	for i, backend := range c.backends {
+		var err error
+		if d.shards[i], err = backend.DB(ctx, dbName, opts); err != nil {
+			return nil, err
+		}
+	}
+	return d.withMeta(), nil
+}
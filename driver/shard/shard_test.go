@@ -0,0 +1,328 @@
+package shard
+
+import (
+	"context"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"sort"
+	"testing"
+
+	"github.com/flimzy/diff"
+	"github.com/go-kivik/kivik"
+	_ "github.com/go-kivik/kivik/driver/fs"
+)
+
+// newBackends returns n clients of the fs driver, each in a new temporary
+// directory, and a function to remove the directories.
+func newBackends(t *testing.T, n int) ([]*kivik.Client, func()) {
+	var dirs []string
+	cleanup := func() {
+		for _, dir := range dirs {
+			_ = os.RemoveAll(dir)
+		}
+	}
+	backends := make([]*kivik.Client, n)
+	for i := range backends {
+		dir, err := ioutil.TempDir("", "kivik-shard-")
+		if err != nil {
+			cleanup()
+			t.Fatal(err)
+		}
+		dirs = append(dirs, dir)
+		if backends[i], err = kivik.New(context.Background(), "fs", dir); err != nil {
+			cleanup()
+			t.Fatal(err)
+		}
+	}
+	return backends, cleanup
+}
+
+// newTestDB returns a database sharded by document across three backends,
+// with documents doc00 to doc(n-1), the backends, and a function to remove
+// them.
+func newTestDB(t *testing.T, n int) (*kivik.DB, []*kivik.Client, func()) {
+	backends, cleanup := newBackends(t, 3)
+	client, err := New(ByDocument, backends...)
+	if err != nil {
+		cleanup()
+		t.Fatal(err)
+	}
+	ctx := context.Background()
+	db, err := client.CreateDB(ctx, "test")
+	if err != nil {
+		cleanup()
+		t.Fatal(err)
+	}
+	for i := 0; i < n; i++ {
+		if _, err := db.Put(ctx, fmt.Sprintf("doc%02d", i), map[string]int{"n": i}); err != nil {
+			cleanup()
+			t.Fatal(err)
+		}
+	}
+	return db, backends, cleanup
+}
+
+func TestNew(t *testing.T) {
+	if _, err := New(ByDocument); kivik.StatusCode(err) != kivik.StatusBadRequest {
+		t.Errorf("Unexpected error: %v", err)
+	}
+	backends, cleanup := newBackends(t, 1)
+	defer cleanup()
+	if _, err := New(ByDocument, backends[0], backends[0]); kivik.StatusCode(err) != kivik.StatusBadRequest {
+		t.Errorf("Unexpected error: %v", err)
+	}
+}
+
+func TestRing(t *testing.T) {
+	backends, cleanup := newBackends(t, 4)
+	defer cleanup()
+	three := newRing(backends[:3])
+	four := newRing(backends)
+	counts := make([]int, 4)
+	var moved int
+	for i := 0; i < 1000; i++ {
+		key := fmt.Sprintf("key%d", i)
+		owner := four.locate(key)
+		counts[owner]++
+		if old := three.locate(key); old != owner {
+			if owner != 3 {
+				t.Errorf("Key %s moved between existing backends, from %d to %d", key, old, owner)
+			}
+			moved++
+		}
+	}
+	for i, count := range counts {
+		if count == 0 {
+			t.Errorf("Backend %d owns no keys", i)
+		}
+	}
+	if moved == 0 || moved > 500 {
+		t.Errorf("Unexpected number of keys moved: %d", moved)
+	}
+}
+
+func TestDocuments(t *testing.T) {
+	db, backends, cleanup := newTestDB(t, 30)
+	defer cleanup()
+	ctx := context.Background()
+	var total int64
+	for i, backend := range backends {
+		backendDB, err := backend.DB(ctx, "test")
+		if err != nil {
+			t.Fatal(err)
+		}
+		stats, err := backendDB.Stats(ctx)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if stats.DocCount == 0 {
+			t.Errorf("Backend %d holds no documents", i)
+		}
+		total += stats.DocCount
+	}
+	if total != 30 {
+		t.Errorf("Unexpected total documents: %d", total)
+	}
+	stats, err := db.Stats(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if stats.DocCount != 30 {
+		t.Errorf("Unexpected doc count: %d", stats.DocCount)
+	}
+	var doc struct {
+		N int `json:"n"`
+	}
+	row := db.Get(ctx, "doc07")
+	if err := row.ScanDoc(&doc); err != nil {
+		t.Fatal(err)
+	}
+	if doc.N != 7 {
+		t.Errorf("Unexpected document: %+v", doc)
+	}
+	if _, err := db.Delete(ctx, "doc07", row.Rev); err != nil {
+		t.Fatal(err)
+	}
+	docID, _, err := db.CreateDoc(ctx, map[string]int{"n": 100})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := db.Get(ctx, docID).ScanDoc(&doc); err != nil {
+		t.Fatal(err)
+	}
+	if doc.N != 100 {
+		t.Errorf("Unexpected document: %+v", doc)
+	}
+}
+
+func TestAllDocs(t *testing.T) {
+	tests := []struct {
+		name     string
+		options  kivik.Options
+		expected []string
+		offset   int64
+	}{
+		{
+			name:     "all",
+			expected: []string{"doc00", "doc01", "doc02", "doc03", "doc04", "doc05"},
+		},
+		{
+			name:     "skip and limit",
+			options:  kivik.Options{"skip": 2, "limit": 3},
+			expected: []string{"doc02", "doc03", "doc04"},
+			offset:   2,
+		},
+		{
+			name:     "descending",
+			options:  kivik.Options{"descending": true, "limit": 2},
+			expected: []string{"doc05", "doc04"},
+		},
+		{
+			name:     "start key",
+			options:  kivik.Options{"startkey": "doc04"},
+			expected: []string{"doc04", "doc05"},
+			offset:   4,
+		},
+		{
+			name:     "keys",
+			options:  kivik.Options{"keys": []string{"doc05", "doc01", "doc03"}},
+			expected: []string{"doc05", "doc01", "doc03"},
+		},
+	}
+	db, _, cleanup := newTestDB(t, 6)
+	defer cleanup()
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			rows, err := db.AllDocs(context.Background(), test.options)
+			if err != nil {
+				t.Fatal(err)
+			}
+			var ids []string
+			for rows.Next() {
+				ids = append(ids, rows.ID())
+			}
+			if err := rows.Err(); err != nil {
+				t.Fatal(err)
+			}
+			if d := diff.Interface(test.expected, ids); d != nil {
+				t.Error(d)
+			}
+			if rows.TotalRows() != 6 {
+				t.Errorf("Unexpected total rows: %d", rows.TotalRows())
+			}
+			if test.offset != 0 && rows.Offset() != test.offset {
+				t.Errorf("Unexpected offset: %d", rows.Offset())
+			}
+		})
+	}
+}
+
+func readChanges(t *testing.T, db *kivik.DB, options kivik.Options) ([]string, kivik.SequenceID) {
+	changes, err := db.Changes(context.Background(), options)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var ids []string
+	for changes.Next() {
+		ids = append(ids, changes.ID())
+	}
+	if err := changes.Err(); err != nil {
+		t.Fatal(err)
+	}
+	return ids, changes.LastSeq()
+}
+
+func TestChanges(t *testing.T) {
+	db, _, cleanup := newTestDB(t, 6)
+	defer cleanup()
+	ctx := context.Background()
+	ids, lastSeq := readChanges(t, db, nil)
+	if len(ids) != 6 {
+		t.Errorf("Unexpected changes: %v", ids)
+	}
+	if _, err := db.Put(ctx, "new", map[string]int{}); err != nil {
+		t.Fatal(err)
+	}
+	ids, _ = readChanges(t, db, kivik.Options{"since": lastSeq})
+	if d := diff.Interface([]string{"new"}, ids); d != nil {
+		t.Error(d)
+	}
+	ids, _ = readChanges(t, db, kivik.Options{"limit": 2})
+	if len(ids) != 2 {
+		t.Errorf("Unexpected changes: %v", ids)
+	}
+	ids, _ = readChanges(t, db, kivik.Options{"feed": "longpoll", "since": lastSeq})
+	if d := diff.Interface([]string{"new"}, ids); d != nil {
+		t.Error(d)
+	}
+	// A composite from since=now refers to each shard's actual sequence ID,
+	// even for shards with no changes, so resuming from it misses nothing.
+	_, nowSeq := readChanges(t, db, kivik.Options{"since": "now"})
+	seqs, err := decodeSeq(nowSeq, 3)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, seq := range seqs {
+		if seq == "now" {
+			t.Errorf("Unresolved sequence ID in %v", seqs)
+		}
+	}
+	for _, id := range []string{"x", "y", "z"} {
+		if _, err := db.Put(ctx, id, map[string]int{}); err != nil {
+			t.Fatal(err)
+		}
+	}
+	ids, _ = readChanges(t, db, kivik.Options{"since": nowSeq})
+	sort.Strings(ids)
+	if d := diff.Interface([]string{"x", "y", "z"}, ids); d != nil {
+		t.Error(d)
+	}
+	if _, err := db.Changes(ctx, kivik.Options{"since": "bogus"}); kivik.StatusCode(err) != kivik.StatusBadRequest {
+		t.Errorf("Unexpected error: %v", err)
+	}
+}
+
+func TestByDatabase(t *testing.T) {
+	backends, cleanup := newBackends(t, 3)
+	defer cleanup()
+	client, err := New(ByDatabase, backends...)
+	if err != nil {
+		t.Fatal(err)
+	}
+	ctx := context.Background()
+	names := []string{"a", "b", "c", "d", "e", "f"}
+	for _, name := range names {
+		if _, err := client.CreateDB(ctx, name); err != nil {
+			t.Fatal(err)
+		}
+	}
+	var total int
+	for _, backend := range backends {
+		dbs, err := backend.AllDBs(ctx)
+		if err != nil {
+			t.Fatal(err)
+		}
+		total += len(dbs)
+	}
+	if total != len(names) {
+		t.Errorf("Databases were not placed once each: %d", total)
+	}
+	dbs, err := client.AllDBs(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if d := diff.Interface(names, dbs); d != nil {
+		t.Error(d)
+	}
+	db, err := client.DB(ctx, "c")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := db.Put(ctx, "foo", map[string]int{}); err != nil {
+		t.Fatal(err)
+	}
+	if err := db.Get(ctx, "foo").Err; err != nil {
+		t.Error(err)
+	}
+}
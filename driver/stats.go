@@ -0,0 +1,47 @@
+package driver
+
+import (
+	"context"
+	"encoding/json"
+)
+
+// Stat is a single server statistic, as returned by the
+// /_node/{node-name}/_stats endpoint.
+type Stat struct {
+	// Type is the stat type, such as "counter", "gauge" or "histogram".
+	Type string `json:"type"`
+	// Desc is a human-readable description of the stat.
+	Desc string `json:"desc"`
+	// Value is the raw JSON value of the stat.
+	Value json.RawMessage `json:"value"`
+}
+
+// SystemStats contains Erlang VM statistics, as returned by the
+// /_node/{node-name}/_system endpoint.
+type SystemStats struct {
+	Uptime                  int64            `json:"uptime"`
+	Memory                  map[string]int64 `json:"memory"`
+	RunQueue                int64            `json:"run_queue"`
+	ETSTableCount           int64            `json:"ets_table_count"`
+	ProcessCount            int64            `json:"process_count"`
+	ProcessLimit            int64            `json:"process_limit"`
+	OSProcCount             int64            `json:"os_proc_count"`
+	InternalReplicationJobs int64            `json:"internal_replication_jobs"`
+}
+
+// NodeStats contains the statistics for a single server node.
+type NodeStats struct {
+	// Stats maps the dot-separated stat name, such as
+	// "couchdb.request_time", to its value.
+	Stats map[string]Stat
+	// System contains the system statistics, if available.
+	System *SystemStats
+}
+
+// Stater is an optional interface that may be implemented by a Client to
+// return server node statistics.
+type Stater interface {
+	// Stats returns the statistics for the node named by the "node" option,
+	// or for the local node if the option is not set.
+	Stats(ctx context.Context, options map[string]interface{}) (*NodeStats, error)
+}
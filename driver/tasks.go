@@ -0,0 +1,38 @@
+package driver
+
+import "context"
+
+// ActiveTask describes a running task, as returned by the /_active_tasks
+// endpoint.
+type ActiveTask struct {
+	Type      string `json:"type"`
+	PID       string `json:"pid"`
+	Node      string `json:"node"`
+	StartedOn int64  `json:"started_on"`
+	UpdatedOn int64  `json:"updated_on"`
+	Progress  int64  `json:"progress"`
+
+	Database     string `json:"database"`
+	DesignDoc    string `json:"design_document"`
+	Phase        string `json:"phase"`
+	ChangesDone  int64  `json:"changes_done"`
+	TotalChanges int64  `json:"total_changes"`
+
+	ReplicationID         string `json:"replication_id"`
+	DocID                 string `json:"doc_id"`
+	Source                string `json:"source"`
+	Target                string `json:"target"`
+	Continuous            bool   `json:"continuous"`
+	DocsRead              int64  `json:"docs_read"`
+	DocsWritten           int64  `json:"docs_written"`
+	DocWriteFailures      int64  `json:"doc_write_failures"`
+	MissingRevisionsFound int64  `json:"missing_revisions_found"`
+	RevisionsChecked      int64  `json:"revisions_checked"`
+}
+
+// ActiveTasker is an optional interface that may be implemented by a Client
+// to list running tasks.
+type ActiveTasker interface {
+	// ActiveTasks returns a list of running tasks.
+	ActiveTasks(ctx context.Context) ([]ActiveTask, error)
+}
@@ -1,5 +1,7 @@
 package driver
 
+import "context"
+
 // DBUpdate represents a database update event.
 type DBUpdate struct {
 	DBName string `json:"db_name"`
@@ -25,3 +27,15 @@ type DBUpdater interface {
 	// and a function to close the connection.
 	DBUpdates() (DBUpdates, error)
 }
+
+// ContextDBUpdater is an optional interface that may be implemented by a
+// Client to provide access to the DB Updates feed, with a context and feed
+// options. If implemented, it is used in preference to DBUpdater.
+type ContextDBUpdater interface {
+	// DBUpdates must return a DBUpdates iterator. The iterator should remain
+	// open until closed, or until ctx is cancelled. The options map may
+	// contain the standard CouchDB _db_updates query parameters, such as
+	// "feed" ("normal", "longpoll" or "continuous"), "timeout", "heartbeat"
+	// and "since".
+	DBUpdates(ctx context.Context, options map[string]interface{}) (DBUpdates, error)
+}
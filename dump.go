@@ -0,0 +1,294 @@
+package kivik
+
+import (
+	"archive/tar"
+	"bytes"
+	"context"
+	"encoding/json"
+	"io"
+	"io/ioutil"
+	"net/url"
+	"path"
+	"strings"
+	"time"
+
+	"github.com/go-kivik/kivik/errors"
+)
+
+// Dump archive layout. Each database is stored in a directory named for the
+// (path-escaped) database name. Within that directory, the security document
+// is stored as _security.json, and each document as <docID>.json. Any
+// attachments are stored in a directory named for the document, and precede
+// the document itself in the archive.
+const (
+	dumpSecurityFile = "_security.json"
+	dumpDocExt       = ".json"
+)
+
+// dumpDatabases returns the list of databases to be included in a dump or
+// restore, as provided by the "databases" option, or nil if all databases
+// should be included.
+func dumpDatabases(opts Options) ([]string, error) {
+	dbs, ok := opts["databases"]
+	if !ok {
+		return nil, nil
+	}
+	names, ok := dbs.([]string)
+	if !ok {
+		return nil, errors.Statusf(StatusBadRequest, "kivik: invalid type %T for 'databases' option", dbs)
+	}
+	return names, nil
+}
+
+// Dump writes a snapshot of one or more databases to w, as a tar archive. The
+// snapshot includes each database's security document, and every
+// non-deleted document, including design documents and attachments. The
+// resulting archive may be passed to Restore to recreate the databases, with
+// document revisions preserved.
+//
+// The "databases" option, a []string, may be used to limit the snapshot to
+// the named databases. If omitted, all databases returned by AllDBs are
+// included. All other options are ignored.
+func (c *Client) Dump(ctx context.Context, w io.Writer, options ...Options) error {
+	opts, err := mergeOptions(options...)
+	if err != nil {
+		return err
+	}
+	dbNames, err := dumpDatabases(opts)
+	if err != nil {
+		return err
+	}
+	if dbNames == nil {
+		if dbNames, err = c.AllDBs(ctx); err != nil {
+			return err
+		}
+	}
+	tw := tar.NewWriter(w)
+	for _, dbName := range dbNames {
+		db, err := c.DB(ctx, dbName)
+		if err != nil {
+			return err
+		}
+		if err := dumpDB(ctx, tw, db); err != nil {
+			return err
+		}
+	}
+	return errors.WrapStatus(StatusUnknownError, tw.Close())
+}
+
+func dumpFile(tw *tar.Writer, name string, content []byte) error {
+	hdr := &tar.Header{
+		Name:    name,
+		Mode:    0644,
+		Size:    int64(len(content)),
+		ModTime: time.Now(),
+	}
+	if err := tw.WriteHeader(hdr); err != nil {
+		return errors.WrapStatus(StatusUnknownError, err)
+	}
+	_, err := tw.Write(content)
+	return errors.WrapStatus(StatusUnknownError, err)
+}
+
+func dumpDB(ctx context.Context, tw *tar.Writer, db *DB) error {
+	dir := url.PathEscape(db.Name())
+	sec, err := db.Security(ctx)
+	if err != nil {
+		return err
+	}
+	secJSON, err := json.Marshal(sec)
+	if err != nil {
+		return errors.WrapStatus(StatusUnknownError, err)
+	}
+	if e := dumpFile(tw, path.Join(dir, dumpSecurityFile), secJSON); e != nil {
+		return e
+	}
+	rows, err := db.AllDocs(ctx)
+	if err != nil {
+		return err
+	}
+	defer rows.Close() // nolint: errcheck
+	for rows.Next() {
+		if err := dumpDoc(ctx, tw, db, dir, rows.ID()); err != nil {
+			return err
+		}
+	}
+	return rows.Err()
+}
+
+func dumpDoc(ctx context.Context, tw *tar.Writer, db *DB, dir, docID string) error {
+	var doc struct {
+		Rev         string                     `json:"_rev"`
+		Attachments map[string]json.RawMessage `json:"_attachments"`
+	}
+	row := db.Get(ctx, docID)
+	if row.Err != nil {
+		return row.Err
+	}
+	defer row.Body.Close() // nolint: errcheck
+	body, err := ioutil.ReadAll(row.Body)
+	if err != nil {
+		return errors.WrapStatus(StatusNetworkError, err)
+	}
+	if e := json.Unmarshal(body, &doc); e != nil {
+		return errors.WrapStatus(StatusBadResponse, e)
+	}
+	docName := url.PathEscape(docID)
+	for filename := range doc.Attachments {
+		att, err := db.GetAttachment(ctx, docID, doc.Rev, filename)
+		if err != nil {
+			return err
+		}
+		content, err := ioutil.ReadAll(att.Content)
+		_ = att.Content.Close()
+		if err != nil {
+			return errors.WrapStatus(StatusNetworkError, err)
+		}
+		if e := dumpFile(tw, path.Join(dir, docName, url.PathEscape(filename)), content); e != nil {
+			return e
+		}
+	}
+	return dumpFile(tw, path.Join(dir, docName+dumpDocExt), body)
+}
+
+// Restore reads a snapshot created by Dump from r, and recreates the
+// databases it contains. Databases which do not already exist are created.
+// Documents are written with the "new_edits" option set to false, so that
+// the revisions recorded in the snapshot are preserved. This requires driver
+// support for the "new_edits" option.
+//
+// Restore accepts the same "databases" option as Dump, to restore only a
+// subset of the databases in the snapshot.
+func (c *Client) Restore(ctx context.Context, r io.Reader, options ...Options) error {
+	opts, err := mergeOptions(options...)
+	if err != nil {
+		return err
+	}
+	dbNames, err := dumpDatabases(opts)
+	if err != nil {
+		return err
+	}
+	rs := &restorer{
+		client: c,
+		atts:   make(Attachments),
+	}
+	if dbNames != nil {
+		rs.include = make(map[string]bool, len(dbNames))
+		for _, name := range dbNames {
+			rs.include[name] = true
+		}
+	}
+	tr := tar.NewReader(r)
+	for {
+		hdr, err := tr.Next()
+		if err == io.EOF {
+			return nil
+		}
+		if err != nil {
+			return errors.WrapStatus(StatusBadRequest, err)
+		}
+		if hdr.Typeflag == tar.TypeDir {
+			continue
+		}
+		if err := rs.restoreFile(ctx, hdr.Name, tr); err != nil {
+			return err
+		}
+	}
+}
+
+// restorer holds the state of a Restore operation.
+type restorer struct {
+	client  *Client
+	include map[string]bool
+	db      *DB
+	// atts holds attachments read for the next document in the archive.
+	atts Attachments
+}
+
+func splitDumpPath(name string) (parts []string, err error) {
+	parts = strings.Split(strings.TrimPrefix(path.Clean(name), "/"), "/")
+	for i, part := range parts {
+		if parts[i], err = url.PathUnescape(part); err != nil {
+			return nil, errors.WrapStatus(StatusBadRequest, err)
+		}
+	}
+	return parts, nil
+}
+
+func (rs *restorer) restoreFile(ctx context.Context, name string, r io.Reader) error {
+	parts, err := splitDumpPath(name)
+	if err != nil {
+		return err
+	}
+	if len(parts) < 2 || len(parts) > 3 {
+		return errors.Statusf(StatusBadRequest, "kivik: unexpected file '%s' in dump", name)
+	}
+	if rs.include != nil && !rs.include[parts[0]] {
+		return nil
+	}
+	if err := rs.useDB(ctx, parts[0]); err != nil {
+		return err
+	}
+	content, err := ioutil.ReadAll(r)
+	if err != nil {
+		return errors.WrapStatus(StatusBadRequest, err)
+	}
+	if len(parts) == 3 {
+		rs.atts[parts[2]] = &Attachment{
+			Filename: parts[2],
+			Content:  ioutil.NopCloser(bytes.NewReader(content)),
+		}
+		return nil
+	}
+	if parts[1] == dumpSecurityFile {
+		sec := new(Security)
+		if e := json.Unmarshal(content, sec); e != nil {
+			return errors.WrapStatus(StatusBadRequest, e)
+		}
+		return rs.db.SetSecurity(ctx, sec)
+	}
+	if !strings.HasSuffix(parts[1], dumpDocExt) {
+		return errors.Statusf(StatusBadRequest, "kivik: unexpected file '%s' in dump", name)
+	}
+	return rs.restoreDoc(ctx, strings.TrimSuffix(parts[1], dumpDocExt), content)
+}
+
+// useDB sets the current database, creating it if necessary.
+func (rs *restorer) useDB(ctx context.Context, dbName string) error {
+	if rs.db != nil && rs.db.Name() == dbName {
+		return nil
+	}
+	rs.atts = make(Attachments)
+	exists, err := rs.client.DBExists(ctx, dbName)
+	if err != nil {
+		return err
+	}
+	if exists {
+		rs.db, err = rs.client.DB(ctx, dbName)
+	} else {
+		rs.db, err = rs.client.CreateDB(ctx, dbName)
+	}
+	return err
+}
+
+func (rs *restorer) restoreDoc(ctx context.Context, docID string, content []byte) error {
+	var doc map[string]interface{}
+	if err := json.Unmarshal(content, &doc); err != nil {
+		return errors.WrapStatus(StatusBadRequest, err)
+	}
+	if stubs, ok := doc["_attachments"].(map[string]interface{}); ok {
+		for filename, stub := range stubs {
+			att, ok := rs.atts[filename]
+			if !ok {
+				return errors.Statusf(StatusBadRequest, "kivik: attachment '%s' for document '%s' missing from dump", filename, docID)
+			}
+			if s, ok := stub.(map[string]interface{}); ok {
+				att.ContentType, _ = s["content_type"].(string)
+			}
+			stubs[filename] = att
+		}
+	}
+	rs.atts = make(Attachments)
+	_, err := rs.db.Put(ctx, docID, doc, Options{"new_edits": false})
+	return err
+}
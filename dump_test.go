@@ -0,0 +1,236 @@
+package kivik
+
+import (
+	"archive/tar"
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"testing"
+
+	"github.com/flimzy/diff"
+	"github.com/flimzy/testy"
+	"github.com/go-kivik/kivik/driver"
+	"github.com/go-kivik/kivik/mock"
+)
+
+func dumpTestDB() *mock.DB {
+	ids := []string{"_design/foo", "bar"}
+	return &mock.DB{
+		SecurityFunc: func(_ context.Context) (*driver.Security, error) {
+			return &driver.Security{Admins: driver.Members{Names: []string{"bob"}}}, nil
+		},
+		AllDocsFunc: func(_ context.Context, _ map[string]interface{}) (driver.Rows, error) {
+			i := 0
+			return &mock.Rows{
+				NextFunc: func(row *driver.Row) error {
+					if i == len(ids) {
+						return io.EOF
+					}
+					row.ID = ids[i]
+					i++
+					return nil
+				},
+				CloseFunc: func() error { return nil },
+			}, nil
+		},
+		GetFunc: func(_ context.Context, docID string, _ map[string]interface{}) (*driver.Document, error) {
+			switch docID {
+			case "_design/foo":
+				return &driver.Document{Body: body(`{"_id":"_design/foo","_rev":"1-xxx"}`)}, nil
+			case "bar":
+				return &driver.Document{Body: body(`{"_id":"bar","_rev":"2-yyy","_attachments":{"a.txt":{"content_type":"text/plain","stub":true}}}`)}, nil
+			}
+			return nil, fmt.Errorf("Unexpected docID: %s", docID)
+		},
+		GetAttachmentFunc: func(_ context.Context, docID, rev, filename string, _ map[string]interface{}) (*driver.Attachment, error) {
+			if docID != "bar" || rev != "2-yyy" || filename != "a.txt" {
+				return nil, fmt.Errorf("Unexpected attachment: %s/%s/%s", docID, rev, filename)
+			}
+			return &driver.Attachment{Filename: filename, ContentType: "text/plain", Content: body("test content")}, nil
+		},
+	}
+}
+
+func readDump(t *testing.T, data []byte) map[string]string {
+	files := make(map[string]string)
+	tr := tar.NewReader(bytes.NewReader(data))
+	for {
+		hdr, err := tr.Next()
+		if err == io.EOF {
+			return files
+		}
+		if err != nil {
+			t.Fatal(err)
+		}
+		content, err := ioutil.ReadAll(tr)
+		if err != nil {
+			t.Fatal(err)
+		}
+		files[hdr.Name] = string(content)
+	}
+}
+
+func TestDump(t *testing.T) {
+	tests := []struct {
+		name     string
+		client   *Client
+		options  Options
+		expected map[string]string
+		status   int
+		err      string
+	}{
+		{
+			name: "invalid databases option",
+			client: &Client{
+				driverClient: &mock.Client{},
+			},
+			options: Options{"databases": "foo"},
+			status:  StatusBadRequest,
+			err:     "kivik: invalid type string for 'databases' option",
+		},
+		{
+			name: "AllDBs error",
+			client: &Client{
+				driverClient: &mock.Client{
+					AllDBsFunc: func(_ context.Context, _ map[string]interface{}) ([]string, error) {
+						return nil, errors.New("alldbs error")
+					},
+				},
+			},
+			status: StatusInternalServerError,
+			err:    "alldbs error",
+		},
+		{
+			name: "security error",
+			client: &Client{
+				driverClient: &mock.Client{
+					DBFunc: func(_ context.Context, _ string, _ map[string]interface{}) (driver.DB, error) {
+						return &mock.DB{
+							SecurityFunc: func(_ context.Context) (*driver.Security, error) {
+								return nil, errors.New("security error")
+							},
+						}, nil
+					},
+				},
+			},
+			options: Options{"databases": []string{"foo"}},
+			status:  StatusInternalServerError,
+			err:     "security error",
+		},
+		{
+			name: "success",
+			client: &Client{
+				driverClient: &mock.Client{
+					AllDBsFunc: func(_ context.Context, _ map[string]interface{}) ([]string, error) {
+						return []string{"foo/bar"}, nil
+					},
+					DBFunc: func(_ context.Context, _ string, _ map[string]interface{}) (driver.DB, error) {
+						return dumpTestDB(), nil
+					},
+				},
+			},
+			expected: map[string]string{
+				"foo%2Fbar/_security.json":     `{"admins":{"names":["bob"]},"members":{}}`,
+				"foo%2Fbar/_design%2Ffoo.json": `{"_id":"_design/foo","_rev":"1-xxx"}`,
+				"foo%2Fbar/bar/a.txt":          "test content",
+				"foo%2Fbar/bar.json":           `{"_id":"bar","_rev":"2-yyy","_attachments":{"a.txt":{"content_type":"text/plain","stub":true}}}`,
+			},
+		},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			buf := &bytes.Buffer{}
+			err := test.client.Dump(context.Background(), buf, test.options)
+			testy.StatusError(t, test.err, test.status, err)
+			if d := diff.Interface(test.expected, readDump(t, buf.Bytes())); d != nil {
+				t.Error(d)
+			}
+		})
+	}
+}
+
+func TestRestore(t *testing.T) {
+	dump := &bytes.Buffer{}
+	src := &Client{
+		driverClient: &mock.Client{
+			DBFunc: func(_ context.Context, _ string, _ map[string]interface{}) (driver.DB, error) {
+				return dumpTestDB(), nil
+			},
+		},
+	}
+	if err := src.Dump(context.Background(), dump, Options{"databases": []string{"foo"}}); err != nil {
+		t.Fatal(err)
+	}
+	var created []string
+	var security *driver.Security
+	docs := make(map[string]string)
+	target := &mock.DB{
+		SetSecurityFunc: func(_ context.Context, sec *driver.Security) error {
+			security = sec
+			return nil
+		},
+		PutFunc: func(_ context.Context, docID string, doc interface{}, opts map[string]interface{}) (string, error) {
+			if d := diff.Interface(map[string]interface{}{"new_edits": false}, opts); d != nil {
+				return "", fmt.Errorf("Unexpected options:\n%s", d)
+			}
+			body, err := json.Marshal(doc)
+			if err != nil {
+				return "", err
+			}
+			docs[docID] = string(body)
+			return "", nil
+		},
+	}
+	client := &Client{
+		driverClient: &mock.Client{
+			DBExistsFunc: func(_ context.Context, _ string, _ map[string]interface{}) (bool, error) {
+				return false, nil
+			},
+			CreateDBFunc: func(_ context.Context, dbName string, _ map[string]interface{}) error {
+				created = append(created, dbName)
+				return nil
+			},
+			DBFunc: func(_ context.Context, _ string, _ map[string]interface{}) (driver.DB, error) {
+				return target, nil
+			},
+		},
+	}
+	t.Run("filtered", func(t *testing.T) {
+		err := client.Restore(context.Background(), bytes.NewReader(dump.Bytes()), Options{"databases": []string{"bar"}})
+		testy.Error(t, "", err)
+		if len(created) != 0 {
+			t.Errorf("Unexpected databases created: %v", created)
+		}
+	})
+	t.Run("success", func(t *testing.T) {
+		err := client.Restore(context.Background(), bytes.NewReader(dump.Bytes()))
+		testy.Error(t, "", err)
+		if d := diff.Interface([]string{"foo"}, created); d != nil {
+			t.Error(d)
+		}
+		if d := diff.Interface(&driver.Security{Admins: driver.Members{Names: []string{"bob"}}}, security); d != nil {
+			t.Error(d)
+		}
+		expected := map[string]string{
+			"_design/foo": `{"_id":"_design/foo","_rev":"1-xxx"}`,
+			"bar":         `{"_attachments":{"a.txt":{"content_type":"text/plain","data":"dGVzdCBjb250ZW50"}},"_id":"bar","_rev":"2-yyy"}`,
+		}
+		if d := diff.Interface(expected, docs); d != nil {
+			t.Error(d)
+		}
+	})
+	t.Run("missing attachment", func(t *testing.T) {
+		buf := &bytes.Buffer{}
+		tw := tar.NewWriter(buf)
+		if err := dumpFile(tw, "foo/bar.json", []byte(`{"_id":"bar","_attachments":{"a.txt":{"stub":true}}}`)); err != nil {
+			t.Fatal(err)
+		}
+		_ = tw.Close()
+		err := client.Restore(context.Background(), buf)
+		testy.StatusError(t, "kivik: attachment 'a.txt' for document 'bar' missing from dump", StatusBadRequest, err)
+	})
+}
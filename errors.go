@@ -1,9 +1,42 @@
 package kivik
 
+import "github.com/go-kivik/kivik/errors"
+
+// Sentinel errors, which match any error returned by Kivik, or by a driver,
+// with the corresponding status code, when compared with errors.Is in Go 1.13
+// and later:
+//
+//  if errors.Is(err, kivik.ErrNotFound) {
+//      return
+//  }
+//
+// The underlying driver error remains accessible with errors.As.
+var (
+	ErrBadRequest         = errors.Sentinel(StatusBadRequest, "kivik: bad request")
+	ErrUnauthorized       = errors.Sentinel(StatusUnauthorized, "kivik: unauthorized")
+	ErrForbidden          = errors.Sentinel(StatusForbidden, "kivik: forbidden")
+	ErrNotFound           = errors.Sentinel(StatusNotFound, "kivik: not found")
+	ErrConflict           = errors.Sentinel(StatusConflict, "kivik: conflict")
+	ErrPreconditionFailed = errors.Sentinel(StatusPreconditionFailed, "kivik: precondition failed")
+	ErrNotImplemented     = errors.Sentinel(StatusNotImplemented, "kivik: not implemented")
+	ErrNetworkError       = errors.Sentinel(StatusNetworkError, "kivik: network error")
+)
+
 type statusCoder interface {
 	StatusCode() int
 }
 
+// unwrapper is satisfied by errors wrapped with fmt.Errorf's %w verb, in Go
+// 1.13 and later.
+type unwrapper interface {
+	Unwrap() error
+}
+
+// causer is satisfied by errors wrapped with github.com/pkg/errors.
+type causer interface {
+	Cause() error
+}
+
 // StatusCode returns the HTTP status code embedded in the error, or 500
 // (internal server error), if there was no specified status code.  If err is
 // nil, StatusCode returns 0. This provides a convenient way to determine the
@@ -22,7 +55,7 @@ type statusCoder interface {
 // This method uses the statusCoder interface, which is not exported by this
 // package, but is considered part of the stable public API.  Driver
 // implementations are expected to return errors which conform to this
-// interface.
+// interface. Wrapped errors are unwrapped, until one which conforms is found.
 //
 //  type statusCoder interface {
 //      StatusCode() int
@@ -31,12 +64,53 @@ func StatusCode(err error) int {
 	if err == nil {
 		return 0
 	}
-	if coder, ok := err.(statusCoder); ok {
-		return coder.StatusCode()
+	for err != nil {
+		if coder, ok := err.(statusCoder); ok {
+			return coder.StatusCode()
+		}
+		switch e := err.(type) {
+		case unwrapper:
+			err = e.Unwrap()
+		case causer:
+			err = e.Cause()
+		default:
+			err = nil
+		}
 	}
 	return StatusInternalServerError
 }
 
+// iser is satisfied by errors which provide their own comparison for errors.Is,
+// in Go 1.13 and later, including those created by the
+// github.com/go-kivik/kivik/errors package.
+type iser interface {
+	Is(error) bool
+}
+
+// wrapDriverError wraps err, if it reports a status code, but does not
+// provide its own Is method, so that it matches the corresponding sentinel
+// error with errors.Is. The original error remains accessible with
+// errors.As, and its message and status code are unchanged.
+func wrapDriverError(err error) error {
+	for e := err; e != nil; {
+		if _, ok := e.(statusCoder); ok {
+			if _, ok := e.(iser); ok {
+				return err
+			}
+			return errors.WrapStatus(StatusCode(err), err)
+		}
+		switch t := e.(type) {
+		case unwrapper:
+			e = t.Unwrap()
+		case causer:
+			e = t.Cause()
+		default:
+			e = nil
+		}
+	}
+	return err
+}
+
 type reasoner interface {
 	Reason() string
 }
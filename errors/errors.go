@@ -14,6 +14,7 @@ import (
 type statusError struct {
 	statusCode int
 	message    string
+	sentinel   bool
 }
 
 // MarshalJSON satisifies the json.Marshaler interface for the statusError
@@ -39,6 +40,18 @@ func (se *statusError) Reason() string {
 	return se.message
 }
 
+// Is returns true if target is a sentinel error with the same status code as
+// se. This allows errors.Is to be used to check the status of an error in Go
+// 1.13 and later.
+func (se *statusError) Is(target error) bool {
+	return isSentinel(target, se.statusCode)
+}
+
+func isSentinel(target error, status int) bool {
+	s, ok := target.(*statusError)
+	return ok && s.sentinel && s.statusCode == status
+}
+
 // New is a wrapper around the standard errors.New, to avoid the need for
 // multiple imports.
 func New(msg string) error {
@@ -53,6 +66,17 @@ func Status(status int, msg string) error {
 	}
 }
 
+// Sentinel returns a new error with the designated HTTP status, which any
+// error created by this package with the same status matches, when compared
+// with errors.Is.
+func Sentinel(status int, msg string) error {
+	return &statusError{
+		statusCode: status,
+		message:    msg,
+		sentinel:   true,
+	}
+}
+
 // Statusf returns a new error with the designated HTTP status.
 func Statusf(status int, format string, args ...interface{}) error {
 	return &statusError{
@@ -78,6 +102,18 @@ func (e *wrappedError) Cause() error {
 	return e.err
 }
 
+// Unwrap returns the underlying error, for use by errors.Unwrap and errors.As
+// in Go 1.13 and later.
+func (e *wrappedError) Unwrap() error {
+	return e.err
+}
+
+// Is returns true if target is a sentinel error with the same status code as
+// e.
+func (e *wrappedError) Is(target error) bool {
+	return isSentinel(target, e.statusCode)
+}
+
 // WrapStatus bundles an existing error with a status code.
 func WrapStatus(status int, err error) error {
 	if err == nil {
@@ -197,3 +197,60 @@ func TestErrorf(t *testing.T) {
 		t.Errorf("Unexpected Error: %s", e)
 	}
 }
+
+func TestSentinel(t *testing.T) {
+	type iser interface {
+		Is(error) bool
+	}
+	notFound := Sentinel(404, "not found")
+	tests := []struct {
+		name     string
+		err      error
+		target   error
+		expected bool
+	}{
+		{
+			name:     "status error match",
+			err:      Status(404, "missing"),
+			target:   notFound,
+			expected: true,
+		},
+		{
+			name:   "status error mismatch",
+			err:    Status(409, "conflict"),
+			target: notFound,
+		},
+		{
+			name:   "non-sentinel target",
+			err:    Status(404, "missing"),
+			target: Status(404, "missing"),
+		},
+		{
+			name:     "wrapped error match",
+			err:      WrapStatus(404, errors.New("missing")),
+			target:   notFound,
+			expected: true,
+		},
+		{
+			name:   "wrapped error mismatch",
+			err:    WrapStatus(500, errors.New("missing")),
+			target: notFound,
+		},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			result := test.err.(iser).Is(test.target)
+			if result != test.expected {
+				t.Errorf("Expected %t, got %t", test.expected, result)
+			}
+		})
+	}
+}
+
+func TestWrappedErrorUnwrap(t *testing.T) {
+	cause := errors.New("foo")
+	err := WrapStatus(400, cause).(*wrappedError)
+	if err.Unwrap() != cause {
+		t.Errorf("Unexpected Unwrap result: %v", err.Unwrap())
+	}
+}
@@ -1,10 +1,14 @@
 package kivik
 
 import (
+	"context"
 	"errors"
 	"testing"
 
+	"github.com/flimzy/testy"
+	"github.com/go-kivik/kivik/driver"
 	kerrors "github.com/go-kivik/kivik/errors"
+	"github.com/go-kivik/kivik/mock"
 )
 
 func TestStatusCoder(t *testing.T) {
@@ -28,6 +32,21 @@ func TestStatusCoder(t *testing.T) {
 			Err:      kerrors.Status(400, "bad request"),
 			Expected: 400,
 		},
+		{
+			Name:     "pkg/errors wrapped",
+			Err:      kerrors.Wrap(kerrors.Status(404, "not found"), "foo"),
+			Expected: 404,
+		},
+		{
+			Name:     "Unwrap",
+			Err:      testUnwrapper{kerrors.Status(409, "conflict")},
+			Expected: 409,
+		},
+		{
+			Name:     "Unwrap standard error",
+			Err:      testUnwrapper{errors.New("foo")},
+			Expected: 500,
+		},
 	}
 	for _, test := range tests {
 		func(test scTest) {
@@ -41,6 +60,55 @@ func TestStatusCoder(t *testing.T) {
 	}
 }
 
+type testUnwrapper struct{ err error }
+
+func (e testUnwrapper) Error() string { return "wrapped: " + e.err.Error() }
+func (e testUnwrapper) Unwrap() error { return e.err }
+
+func TestSentinelErrors(t *testing.T) {
+	type iser interface {
+		Is(error) bool
+	}
+	err := kerrors.Status(StatusNotFound, "missing")
+	if !err.(iser).Is(ErrNotFound) {
+		t.Error("Expected error to match ErrNotFound")
+	}
+	if err.(iser).Is(ErrConflict) {
+		t.Error("Expected error not to match ErrConflict")
+	}
+	if code := StatusCode(ErrConflict); code != StatusConflict {
+		t.Errorf("Unexpected status code for ErrConflict: %d", code)
+	}
+}
+
+// plainStatusError reports a status code, but has no Is method, as for errors
+// returned by drivers which do not use the kivik errors package.
+type plainStatusError int
+
+func (e plainStatusError) Error() string   { return "plain" }
+func (e plainStatusError) StatusCode() int { return int(e) }
+
+func TestDriverErrorSentinel(t *testing.T) {
+	db := &DB{
+		driverDB: &mock.DB{
+			GetFunc: func(context.Context, string, map[string]interface{}) (*driver.Document, error) {
+				return nil, plainStatusError(StatusNotFound)
+			},
+		},
+	}
+	err := db.Get(context.Background(), "foo").Err
+	testy.StatusError(t, "plain", StatusNotFound, err)
+	if !err.(iser).Is(ErrNotFound) {
+		t.Error("Expected error to match ErrNotFound")
+	}
+	if err.(iser).Is(ErrConflict) {
+		t.Error("Expected error not to match ErrConflict")
+	}
+	if cause := err.(unwrapper).Unwrap(); cause != plainStatusError(StatusNotFound) {
+		t.Errorf("Unexpected underlying error: %v", cause)
+	}
+}
+
 type testReasoner int
 
 func (tr testReasoner) Reason() string { return "reason" }
@@ -0,0 +1,117 @@
+package kivik
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"github.com/go-kivik/kivik/errors"
+)
+
+// FaultInjector is a Hook which makes selected operations fail, or adds
+// latency to them, so that retry logic, timeouts and error handling may be
+// tested deterministically, with any driver. Register it with Client.AddHook.
+//
+// Faults are matched against the Operation name, such as "Get" or "Put", or
+// against any operation if the name is empty. Each fault applies to a given
+// number of matching calls, after which it is discarded. Retries count as
+// separate calls.
+type FaultInjector struct {
+	mu     sync.Mutex
+	faults []*fault
+}
+
+var _ Interceptor = &FaultInjector{}
+
+type fault struct {
+	op        string
+	remaining int
+	delay     time.Duration
+	err       error
+}
+
+// FailNext makes the next call to op fail with the given HTTP status.
+func (f *FaultInjector) FailNext(op string, status int) {
+	f.Fail(op, 1, errors.Status(status, "kivik: injected fault"))
+}
+
+// Fail makes the next n calls to op fail with err. A negative n applies to
+// every call, until Reset is called.
+func (f *FaultInjector) Fail(op string, n int, err error) {
+	f.add(&fault{op: op, remaining: n, err: err})
+}
+
+// Delay delays the next n calls to op by d, or until the request's context is
+// cancelled. A negative n applies to every call, until Reset is called.
+func (f *FaultInjector) Delay(op string, n int, d time.Duration) {
+	f.add(&fault{op: op, remaining: n, delay: d})
+}
+
+// Reset discards all pending faults.
+func (f *FaultInjector) Reset() {
+	f.mu.Lock()
+	f.faults = nil
+	f.mu.Unlock()
+}
+
+func (f *FaultInjector) add(flt *fault) {
+	if flt.remaining == 0 {
+		return
+	}
+	f.mu.Lock()
+	f.faults = append(f.faults, flt)
+	f.mu.Unlock()
+}
+
+// next returns the faults which apply to a call to op, consuming them.
+func (f *FaultInjector) next(op string) []*fault {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	var matched []*fault
+	pending := f.faults[:0]
+	for _, flt := range f.faults {
+		if flt.op != "" && flt.op != op {
+			pending = append(pending, flt)
+			continue
+		}
+		matched = append(matched, flt)
+		if flt.remaining > 0 {
+			flt.remaining--
+		}
+		if flt.remaining != 0 {
+			pending = append(pending, flt)
+		}
+	}
+	f.faults = pending
+	return matched
+}
+
+// Before satisfies the Hook interface.
+func (f *FaultInjector) Before(ctx context.Context, _ *Operation) context.Context {
+	return ctx
+}
+
+// After satisfies the Hook interface.
+func (f *FaultInjector) After(_ context.Context, _ *Operation, _ time.Duration, _ error) {}
+
+// Intercept satisfies the Interceptor interface. It applies any delays, then
+// returns the first injected error, if any, for op.
+func (f *FaultInjector) Intercept(ctx context.Context, op *Operation) error {
+	faults := f.next(op.Name)
+	var err error
+	for _, flt := range faults {
+		if flt.delay > 0 {
+			timer := time.NewTimer(flt.delay)
+			select {
+			case <-timer.C:
+			case <-ctx.Done():
+				timer.Stop()
+				return ctx.Err()
+			}
+		}
+		if err == nil {
+			err = flt.err
+		}
+	}
+	return err
+}
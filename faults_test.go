@@ -0,0 +1,81 @@
+package kivik
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/flimzy/testy"
+	"github.com/go-kivik/kivik/driver"
+	"github.com/go-kivik/kivik/errors"
+	"github.com/go-kivik/kivik/mock"
+)
+
+func TestFaultInjector(t *testing.T) {
+	faults := &FaultInjector{}
+	client := &Client{}
+	client.AddHook(faults)
+	var puts int
+	db := &DB{
+		client: client,
+		name:   "db",
+		driverDB: &mock.DB{
+			PutFunc: func(_ context.Context, _ string, _ interface{}, _ map[string]interface{}) (string, error) {
+				puts++
+				return "1-xxx", nil
+			},
+			GetFunc: func(_ context.Context, _ string, _ map[string]interface{}) (*driver.Document, error) {
+				return &driver.Document{Rev: "1-xxx", Body: body(`{}`)}, nil
+			},
+		},
+	}
+	ctx := context.Background()
+	put := func() error {
+		_, err := db.Put(ctx, "foo", map[string]string{})
+		return err
+	}
+
+	t.Run("FailNext", func(t *testing.T) {
+		puts = 0
+		faults.FailNext("Put", StatusServiceUnavailable)
+		testy.StatusError(t, "kivik: injected fault", StatusServiceUnavailable, put())
+		if err := db.Get(ctx, "foo").Err; err != nil {
+			t.Errorf("Get should not fail: %s", err)
+		}
+		testy.Error(t, "", put())
+		if puts != 1 {
+			t.Errorf("Expected 1 call to the driver, got %d", puts)
+		}
+	})
+	t.Run("retried", func(t *testing.T) {
+		puts = 0
+		client.SetRetryPolicy(&RetryPolicy{MaxRetries: 2, MinBackoff: time.Millisecond, RetryWrites: true})
+		defer client.SetRetryPolicy(nil)
+		faults.Fail("Put", 2, errors.Status(StatusTooManyRequests, "slow down"))
+		testy.Error(t, "", put())
+		if puts != 1 {
+			t.Errorf("Expected 1 call to the driver, got %d", puts)
+		}
+	})
+	t.Run("any operation", func(t *testing.T) {
+		faults.Fail("", -1, errors.Status(StatusServiceUnavailable, "down"))
+		defer faults.Reset()
+		testy.StatusError(t, "down", StatusServiceUnavailable, put())
+		testy.StatusError(t, "down", StatusServiceUnavailable, db.Get(ctx, "foo").Err)
+	})
+	t.Run("Delay", func(t *testing.T) {
+		faults.Delay("Put", 1, 20*time.Millisecond)
+		start := time.Now()
+		testy.Error(t, "", put())
+		if elapsed := time.Since(start); elapsed < 20*time.Millisecond {
+			t.Errorf("Expected delay, took %s", elapsed)
+		}
+	})
+	t.Run("Delay cancelled", func(t *testing.T) {
+		faults.Delay("Put", 1, time.Minute)
+		ctx, cancel := context.WithTimeout(ctx, time.Millisecond)
+		defer cancel()
+		_, err := db.Put(ctx, "foo", map[string]string{})
+		testy.Error(t, "context deadline exceeded", err)
+	})
+}
@@ -14,11 +14,9 @@ var findNotImplemented = errors.Status(StatusNotImplemented, "kivik: driver does
 // See http://docs.couchdb.org/en/2.0.0/api/database/find.html#db-find
 func (db *DB) Find(ctx context.Context, query interface{}) (*Rows, error) {
 	if finder, ok := db.driverDB.(driver.Finder); ok {
-		rowsi, err := finder.Find(ctx, query)
-		if err != nil {
-			return nil, err
-		}
-		return newRows(ctx, rowsi), nil
+		return db.doRows(ctx, &Operation{Name: "Find"}, func(ctx context.Context) (driver.Rows, error) {
+			return finder.Find(ctx, query)
+		})
 	}
 	return nil, findNotImplemented
 }
@@ -29,7 +27,9 @@ func (db *DB) Find(ctx context.Context, query interface{}) (*Rows, error) {
 // http://docs.couchdb.org/en/2.0.0/api/database/find.html#find-sort
 func (db *DB) CreateIndex(ctx context.Context, ddoc, name string, index interface{}) error {
 	if finder, ok := db.driverDB.(driver.Finder); ok {
-		return finder.CreateIndex(ctx, ddoc, name, index)
+		return db.do(ctx, &Operation{Name: "CreateIndex"}, true, func(ctx context.Context) error {
+			return finder.CreateIndex(ctx, ddoc, name, index)
+		})
 	}
 	return findNotImplemented
 }
@@ -37,7 +37,9 @@ func (db *DB) CreateIndex(ctx context.Context, ddoc, name string, index interfac
 // DeleteIndex deletes the requested index.
 func (db *DB) DeleteIndex(ctx context.Context, ddoc, name string) error {
 	if finder, ok := db.driverDB.(driver.Finder); ok {
-		return finder.DeleteIndex(ctx, ddoc, name)
+		return db.do(ctx, &Operation{Name: "DeleteIndex"}, true, func(ctx context.Context) error {
+			return finder.DeleteIndex(ctx, ddoc, name)
+		})
 	}
 	return findNotImplemented
 }
@@ -53,7 +55,12 @@ type Index struct {
 // GetIndexes returns the indexes defined on the current database.
 func (db *DB) GetIndexes(ctx context.Context) ([]Index, error) {
 	if finder, ok := db.driverDB.(driver.Finder); ok {
-		dIndexes, err := finder.GetIndexes(ctx)
+		var dIndexes []driver.Index
+		err := db.do(ctx, &Operation{Name: "GetIndexes"}, false, func(ctx context.Context) error {
+			var e error
+			dIndexes, e = finder.GetIndexes(ctx)
+			return e
+		})
 		indexes := make([]Index, len(dIndexes))
 		for i, index := range dIndexes {
 			indexes[i] = Index(index)
@@ -77,13 +84,22 @@ type QueryPlan struct {
 	// an empty list if all fields are to be returned.
 	Fields []interface{}          `json:"fields"`
 	Range  map[string]interface{} `json:"range"`
+
+	// MRArgs are the map/reduce arguments used to query the selected index,
+	// as returned by CouchDB 2.1 and later.
+	MRArgs map[string]interface{} `json:"mrargs"`
 }
 
 // Explain returns the query plan for a given query. Explain takes the same
 // arguments as Find.
 func (db *DB) Explain(ctx context.Context, query interface{}) (*QueryPlan, error) {
 	if explainer, ok := db.driverDB.(driver.Finder); ok {
-		plan, err := explainer.Explain(ctx, query)
+		var plan *driver.QueryPlan
+		err := db.do(ctx, &Operation{Name: "Explain"}, false, func(ctx context.Context) error {
+			var e error
+			plan, e = explainer.Explain(ctx, query)
+			return e
+		})
 		if err != nil {
 			return nil, err
 		}
@@ -292,11 +292,17 @@ func TestExplain(t *testing.T) {
 					if d := diff.Interface(expectedQuery, query); d != nil {
 						return nil, fmt.Errorf("Unexpected query:\n%s", d)
 					}
-					return &driver.QueryPlan{DBName: "foo"}, nil
+					return &driver.QueryPlan{
+						DBName: "foo",
+						MRArgs: map[string]interface{}{"include_docs": true},
+					}, nil
 				},
 			},
-			query:    int(3),
-			expected: &QueryPlan{DBName: "foo"},
+			query: int(3),
+			expected: &QueryPlan{
+				DBName: "foo",
+				MRArgs: map[string]interface{}{"include_docs": true},
+			},
 		},
 	}
 	for _, test := range tests {
@@ -0,0 +1,129 @@
+package kivik
+
+import (
+	"context"
+	"encoding/json"
+	"strconv"
+	"strings"
+
+	"github.com/go-kivik/kivik/driver"
+	"github.com/go-kivik/kivik/errors"
+)
+
+// GeoRows is an iterator over the results of a geospatial query.
+type GeoRows struct {
+	*Rows
+}
+
+// Geometry is a GeoJSON geometry object.
+type Geometry struct {
+	// Type is the geometry type, such as "Point" or "Polygon".
+	Type string `json:"type"`
+	// Coordinates are the raw JSON coordinates, whose structure depends on
+	// Type.
+	Coordinates json.RawMessage `json:"coordinates"`
+}
+
+// Point is a position, as a longitude and latitude pair, in decimal degrees.
+type Point struct {
+	Lon, Lat float64
+}
+
+// Relations between the query shape and indexed geometries, for use with
+// GeoPolygon.
+const (
+	GeoIntersects = "intersects"
+	GeoContains   = "contains"
+	GeoWithin     = "within"
+	GeoOverlaps   = "overlaps"
+	GeoTouches    = "touches"
+	GeoDisjoint   = "disjoint"
+)
+
+func formatFloats(values ...float64) string {
+	parts := make([]string, len(values))
+	for i, v := range values {
+		parts[i] = strconv.FormatFloat(v, 'f', -1, 64)
+	}
+	return strings.Join(parts, ",")
+}
+
+// GeoBBox returns options for a geospatial query, matching geometries which
+// intersect the bounding box with corners min and max.
+func GeoBBox(min, max Point) Options {
+	return Options{"bbox": formatFloats(min.Lon, min.Lat, max.Lon, max.Lat)}
+}
+
+// GeoRadius returns options for a geospatial query, matching geometries
+// within meters of center.
+func GeoRadius(center Point, meters float64) Options {
+	return Options{"lat": center.Lat, "lon": center.Lon, "radius": meters}
+}
+
+// GeoPolygon returns options for a geospatial query, matching geometries
+// which have relation, such as GeoWithin, to the polygon with the given
+// vertices. The polygon is closed automatically, if necessary.
+func GeoPolygon(relation string, vertices ...Point) Options {
+	if len(vertices) > 0 && vertices[0] != vertices[len(vertices)-1] {
+		vertices = append(vertices[:len(vertices):len(vertices)], vertices[0])
+	}
+	points := make([]string, len(vertices))
+	for i, v := range vertices {
+		points[i] = strconv.FormatFloat(v.Lon, 'f', -1, 64) + " " + strconv.FormatFloat(v.Lat, 'f', -1, 64)
+	}
+	return Options{
+		"g":        "POLYGON((" + strings.Join(points, ",") + "))",
+		"relation": relation,
+	}
+}
+
+// Geo queries the geospatial index named index, in design document ddoc.
+// Exactly one query shape must be given, with GeoBBox, GeoRadius or
+// GeoPolygon. Other options, such as limit, bookmark and include_docs, are
+// passed to the driver. Use Geometry to read the geometry of each result.
+func (db *DB) Geo(ctx context.Context, ddoc, index string, options ...Options) (*GeoRows, error) {
+	geo, ok := db.driverDB.(driver.GeoQuerier)
+	if !ok {
+		return nil, errors.Status(StatusNotImplemented, "kivik: driver does not support geospatial queries")
+	}
+	if ddoc == "" {
+		return nil, missingArg("ddoc")
+	}
+	if index == "" {
+		return nil, missingArg("index")
+	}
+	opts, err := mergeOptions(options...)
+	if err != nil {
+		return nil, err
+	}
+	var shapes int
+	for _, key := range []string{"bbox", "radius", "g"} {
+		if _, ok := opts[key]; ok {
+			shapes++
+		}
+	}
+	if shapes != 1 {
+		return nil, errors.Status(StatusBadRequest, "kivik: exactly one of bbox, radius or g required")
+	}
+	rows, err := db.doRows(ctx, &Operation{Name: "Geo", Options: opts}, func(ctx context.Context) (driver.Rows, error) {
+		return geo.Geo(ctx, ddoc, index, opts)
+	})
+	if err != nil {
+		return nil, err
+	}
+	return &GeoRows{Rows: rows}, nil
+}
+
+// Geometry returns the geometry of the current result.
+func (r *GeoRows) Geometry() (*Geometry, error) {
+	var value struct {
+		Geometry *Geometry `json:"geometry"`
+	}
+	if err := r.ScanValue(&value); err != nil {
+		return nil, err
+	}
+	if value.Geometry == nil {
+		return nil, errors.Status(StatusBadResponse, "kivik: result has no geometry")
+	}
+	return value.Geometry, nil
+}
@@ -0,0 +1,165 @@
+package kivik
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"testing"
+
+	"github.com/flimzy/diff"
+	"github.com/flimzy/testy"
+	"github.com/go-kivik/kivik/driver"
+	"github.com/go-kivik/kivik/mock"
+)
+
+func TestGeoOptions(t *testing.T) {
+	tests := []struct {
+		name     string
+		options  Options
+		expected Options
+	}{
+		{
+			name:     "bbox",
+			options:  GeoBBox(Point{Lon: -11.05, Lat: 12.5}, Point{Lon: 1, Lat: 14}),
+			expected: Options{"bbox": "-11.05,12.5,1,14"},
+		},
+		{
+			name:     "radius",
+			options:  GeoRadius(Point{Lon: -71.06, Lat: 42.35}, 100),
+			expected: Options{"lat": 42.35, "lon": -71.06, "radius": 100.0},
+		},
+		{
+			name:     "polygon",
+			options:  GeoPolygon(GeoWithin, Point{0, 0}, Point{1, 0}, Point{1, 1}),
+			expected: Options{"g": "POLYGON((0 0,1 0,1 1,0 0))", "relation": "within"},
+		},
+		{
+			name:     "closed polygon",
+			options:  GeoPolygon(GeoIntersects, Point{0, 0}, Point{1, 0}, Point{1, 1}, Point{0, 0}),
+			expected: Options{"g": "POLYGON((0 0,1 0,1 1,0 0))", "relation": "intersects"},
+		},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			if d := diff.Interface(test.expected, test.options); d != nil {
+				t.Error(d)
+			}
+		})
+	}
+}
+
+func TestGeo(t *testing.T) {
+	tests := []struct {
+		name    string
+		db      *DB
+		ddoc    string
+		index   string
+		options []Options
+		status  int
+		err     string
+	}{
+		{
+			name:    "not implemented",
+			db:      &DB{driverDB: &mock.DB{}},
+			ddoc:    "foo",
+			index:   "bar",
+			options: []Options{GeoRadius(Point{}, 1)},
+			status:  StatusNotImplemented,
+			err:     "kivik: driver does not support geospatial queries",
+		},
+		{
+			name:   "missing ddoc",
+			db:     &DB{driverDB: &mock.GeoQuerier{}},
+			index:  "bar",
+			status: StatusBadRequest,
+			err:    "kivik: ddoc required",
+		},
+		{
+			name:   "missing index",
+			db:     &DB{driverDB: &mock.GeoQuerier{}},
+			ddoc:   "foo",
+			status: StatusBadRequest,
+			err:    "kivik: index required",
+		},
+		{
+			name:   "no shape",
+			db:     &DB{driverDB: &mock.GeoQuerier{}},
+			ddoc:   "foo",
+			index:  "bar",
+			status: StatusBadRequest,
+			err:    "kivik: exactly one of bbox, radius or g required",
+		},
+		{
+			name:    "two shapes",
+			db:      &DB{driverDB: &mock.GeoQuerier{}},
+			ddoc:    "foo",
+			index:   "bar",
+			options: []Options{GeoRadius(Point{}, 1), GeoBBox(Point{}, Point{1, 1})},
+			status:  StatusBadRequest,
+			err:     "kivik: exactly one of bbox, radius or g required",
+		},
+		{
+			name: "db error",
+			db: &DB{driverDB: &mock.GeoQuerier{
+				GeoFunc: func(_ context.Context, _, _ string, _ map[string]interface{}) (driver.Rows, error) {
+					return nil, fmt.Errorf("geo failed")
+				},
+			}},
+			ddoc:    "foo",
+			index:   "bar",
+			options: []Options{GeoRadius(Point{}, 1)},
+			status:  StatusInternalServerError,
+			err:     "geo failed",
+		},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			_, err := test.db.Geo(context.Background(), test.ddoc, test.index, test.options...)
+			testy.StatusError(t, test.err, test.status, err)
+		})
+	}
+}
+
+func TestGeoRows(t *testing.T) {
+	db := &DB{driverDB: &mock.GeoQuerier{
+		GeoFunc: func(_ context.Context, ddoc, index string, opts map[string]interface{}) (driver.Rows, error) {
+			if ddoc != "geodd" || index != "geoidx" || opts["bbox"] != "0,0,1,1" {
+				return nil, fmt.Errorf("Unexpected request: %s %s %v", ddoc, index, opts)
+			}
+			values := []string{
+				`{"id":"a","geometry":{"type":"Point","coordinates":[0.5,0.5]}}`,
+				`{"id":"b"}`,
+			}
+			return &mock.Rows{
+				NextFunc: func(row *driver.Row) error {
+					if len(values) == 0 {
+						return io.EOF
+					}
+					row.Value, values = json.RawMessage(values[0]), values[1:]
+					return nil
+				},
+				CloseFunc: func() error { return nil },
+			}, nil
+		},
+	}}
+	rows, err := db.Geo(context.Background(), "geodd", "geoidx", GeoBBox(Point{0, 0}, Point{1, 1}))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !rows.Next() {
+		t.Fatal(rows.Err())
+	}
+	geom, err := rows.Geometry()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if d := diff.Interface(&Geometry{Type: "Point", Coordinates: json.RawMessage(`[0.5,0.5]`)}, geom); d != nil {
+		t.Error(d)
+	}
+	if !rows.Next() {
+		t.Fatal(rows.Err())
+	}
+	_, err = rows.Geometry()
+	testy.StatusError(t, "kivik: result has no geometry", StatusBadResponse, err)
+}
@@ -0,0 +1,210 @@
+package kivik
+
+import (
+	"context"
+	"io"
+	"sync"
+	"time"
+
+	"github.com/go-kivik/kivik/driver"
+)
+
+// Operation describes a single request to the driver, as passed to a Hook.
+type Operation struct {
+	// Name is the name of the operation, which is the name of the driver
+	// method called, such as "Get", "Put" or "AllDocs".
+	Name string
+	// DB is the name of the database, or empty for client-level operations.
+	DB string
+	// DocID is the ID of the document, for document-level operations.
+	DocID string
+	// Options are the options passed to the driver, after merging. Hooks
+	// must not modify them.
+	Options Options
+	// Rows is true if the operation returns a Rows iterator. See RowsHook.
+	Rows bool
+}
+
+// Hook is called around each request made to the driver by the Client and
+// DB methods, such as Get, Put, AllDocs and Query, which permits logging,
+// metrics and tracing to be added without wrapping the driver. When a
+// RetryPolicy is in effect, the hook is called once per attempt.
+type Hook interface {
+	// Before is called before the request is made. The returned context is
+	// passed to the driver, and to After, so may be used to carry state,
+	// such as a trace span, between the two calls.
+	Before(ctx context.Context, op *Operation) context.Context
+	// After is called after the request completes, with the time taken and
+	// the error returned by the driver, if any.
+	After(ctx context.Context, op *Operation, duration time.Duration, err error)
+}
+
+// RowsHook may be implemented by a Hook which also wishes to be notified when
+// iteration over the Rows returned by an operation ends, such as to record the
+// number of rows read. When the operation succeeds, RowsDone is called once,
+// with the context returned by Before, when the Rows are closed, explicitly or
+// by reading the final row.
+type RowsHook interface {
+	Hook
+	RowsDone(ctx context.Context, op *Operation, rows int64, err error)
+}
+
+// Interceptor may be implemented by a Hook which may fail a request before it
+// reaches the driver, such as to inject faults in tests. Intercept is called
+// after every hook's Before method. If it returns an error, the driver is not
+// called, and the error is passed to After, and returned, as if returned by
+// the driver.
+type Interceptor interface {
+	Hook
+	Intercept(ctx context.Context, op *Operation) error
+}
+
+// AddHook registers hook on c. It applies also to any DB handles obtained
+// from c. Hooks are called in the order registered for Before, and in the
+// reverse order for After. AddHook should be called before the client is
+// used.
+func (c *Client) AddHook(hook Hook) {
+	c.hooks = append(c.hooks, hook)
+}
+
+// callHooks calls fn, surrounded by the Before and After methods of hooks.
+// The error returned by fn is wrapped with wrapDriverError.
+func callHooks(ctx context.Context, hooks []Hook, op *Operation, fn func(context.Context) error) error {
+	if len(hooks) == 0 {
+		return wrapDriverError(fn(ctx))
+	}
+	for _, hook := range hooks {
+		ctx = hook.Before(ctx, op)
+	}
+	start := time.Now()
+	err := intercept(ctx, hooks, op)
+	if err == nil {
+		err = wrapDriverError(fn(ctx))
+	}
+	duration := time.Since(start)
+	for i := len(hooks) - 1; i >= 0; i-- {
+		hooks[i].After(ctx, op, duration, err)
+	}
+	return err
+}
+
+func intercept(ctx context.Context, hooks []Hook, op *Operation) error {
+	for _, hook := range hooks {
+		if i, ok := hook.(Interceptor); ok {
+			if err := i.Intercept(ctx, op); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+// do calls fn for op, with hooks and retries as configured for c. write
+// indicates that the operation is not idempotent.
+func (c *Client) do(ctx context.Context, op *Operation, write bool, fn func(context.Context) error) error {
+	return retry(ctx, c.retryPolicy, write, logRetry(c.log(), op), func() error {
+		return callHooks(ctx, c.hooks, op, fn)
+	})
+}
+
+// hooks returns the hooks registered on the DB's client.
+func (db *DB) hooks() []Hook {
+	if db.client == nil {
+		return nil
+	}
+	return db.client.hooks
+}
+
+// do calls fn for op, with hooks and retries as configured for the DB's
+// client. write indicates that the operation is not idempotent.
+func (db *DB) do(ctx context.Context, op *Operation, write bool, fn func(context.Context) error) error {
+	var policy *RetryPolicy
+	if db.client != nil {
+		policy = db.client.retryPolicy
+	}
+	op.DB = db.name
+	return retry(ctx, policy, write, logRetry(db.client.log(), op), func() error {
+		return callHooks(ctx, db.hooks(), op, fn)
+	})
+}
+
+// doRows calls fn for op, which returns a Rows iterator, as for do, and
+// returns the iterator, wrapped with newRows.
+func (db *DB) doRows(ctx context.Context, op *Operation, fn func(context.Context) (driver.Rows, error)) (*Rows, error) {
+	op.Rows = true
+	var rowsi driver.Rows
+	var hookCtx context.Context
+	err := db.do(ctx, op, false, func(ctx context.Context) error {
+		hookCtx = ctx
+		var e error
+		rowsi, e = fn(ctx)
+		return e
+	})
+	if err != nil {
+		return nil, err
+	}
+	return db.newRows(ctx, hookCtx, op, rowsi), nil
+}
+
+// newRows returns a Rows iterator for rowsi, the result of op. hookCtx is the
+// context returned by the hooks for op.
+func (db *DB) newRows(ctx, hookCtx context.Context, op *Operation, rowsi driver.Rows) *Rows {
+	var hooks []RowsHook
+	for _, hook := range db.hooks() {
+		if rh, ok := hook.(RowsHook); ok {
+			hooks = append(hooks, rh)
+		}
+	}
+	if len(hooks) == 0 {
+		return newRows(ctx, db.client.codec(), rowsi)
+	}
+	feed := &countingIterator{
+		iterator: &rowsIterator{rowsi},
+		done: func(count int64, err error) {
+			for i := len(hooks) - 1; i >= 0; i-- {
+				hooks[i].RowsDone(hookCtx, op, count, err)
+			}
+		},
+	}
+	return &Rows{
+		iter:  newIterator(ctx, feed, &driver.Row{}),
+		rowsi: rowsi,
+		codec: db.client.codec(),
+	}
+}
+
+// countingIterator counts the values read from an iterator, and calls done
+// once, when iteration ends.
+type countingIterator struct {
+	iterator
+	count int64
+	once  sync.Once
+	done  func(count int64, err error)
+}
+
+var _ iterator = &countingIterator{}
+
+func (c *countingIterator) Next(i interface{}) error {
+	err := c.iterator.Next(i)
+	if err != nil {
+		c.finish(err)
+		return err
+	}
+	c.count++
+	return nil
+}
+
+func (c *countingIterator) Close() error {
+	err := c.iterator.Close()
+	c.finish(nil)
+	return err
+}
+
+func (c *countingIterator) finish(err error) {
+	if err == io.EOF {
+		err = nil
+	}
+	c.once.Do(func() {
+		c.done(c.count, err)
+	})
+}
@@ -0,0 +1,245 @@
+package kivik
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/flimzy/diff"
+	"github.com/flimzy/testy"
+	"github.com/go-kivik/kivik/driver"
+	"github.com/go-kivik/kivik/errors"
+	"github.com/go-kivik/kivik/mock"
+)
+
+type hookKey struct{}
+
+type recordingHook struct {
+	name  string
+	calls *[]string
+}
+
+var _ Hook = &recordingHook{}
+
+func (h *recordingHook) Before(ctx context.Context, op *Operation) context.Context {
+	*h.calls = append(*h.calls, fmt.Sprintf("%s before %s %s/%s %v", h.name, op.Name, op.DB, op.DocID, op.Options))
+	return context.WithValue(ctx, hookKey{}, h.name)
+}
+
+func (h *recordingHook) After(ctx context.Context, op *Operation, _ time.Duration, err error) {
+	*h.calls = append(*h.calls, fmt.Sprintf("%s after %s ctx=%v err=%v", h.name, op.Name, ctx.Value(hookKey{}), err))
+}
+
+func TestHooks(t *testing.T) {
+	var calls []string
+	client := &Client{}
+	client.AddHook(&recordingHook{name: "a", calls: &calls})
+	client.AddHook(&recordingHook{name: "b", calls: &calls})
+	client.SetRetryPolicy(&RetryPolicy{MaxRetries: 1, MinBackoff: time.Millisecond})
+	var attempts int
+	db := &DB{
+		client: client,
+		name:   "db",
+		driverDB: &mock.DB{
+			GetFunc: func(ctx context.Context, _ string, _ map[string]interface{}) (*driver.Document, error) {
+				calls = append(calls, fmt.Sprintf("driver ctx=%v", ctx.Value(hookKey{})))
+				attempts++
+				if attempts == 1 {
+					return nil, errors.Status(StatusServiceUnavailable, "unavailable")
+				}
+				return &driver.Document{Rev: "1-xxx", Body: body(`{}`)}, nil
+			},
+		},
+	}
+	row := db.Get(context.Background(), "foo", Options{"revs": true})
+	if row.Err != nil {
+		t.Fatal(row.Err)
+	}
+	expected := []string{
+		"a before Get db/foo map[revs:true]",
+		"b before Get db/foo map[revs:true]",
+		"driver ctx=b",
+		"b after Get ctx=b err=unavailable",
+		"a after Get ctx=b err=unavailable",
+		"a before Get db/foo map[revs:true]",
+		"b before Get db/foo map[revs:true]",
+		"driver ctx=b",
+		"b after Get ctx=b err=<nil>",
+		"a after Get ctx=b err=<nil>",
+	}
+	if d := diff.Interface(expected, calls); d != nil {
+		t.Error(d)
+	}
+}
+
+func TestHooksClient(t *testing.T) {
+	var calls []string
+	client := &Client{
+		driverClient: &mock.Client{
+			DestroyDBFunc: func(_ context.Context, _ string, _ map[string]interface{}) error {
+				return errors.Status(StatusNotFound, "not found")
+			},
+		},
+	}
+	client.AddHook(&recordingHook{name: "a", calls: &calls})
+	err := client.DestroyDB(context.Background(), "foo")
+	testy.StatusError(t, "not found", StatusNotFound, err)
+	expected := []string{
+		"a before DestroyDB foo/ map[]",
+		"a after DestroyDB ctx=a err=not found",
+	}
+	if d := diff.Interface(expected, calls); d != nil {
+		t.Error(d)
+	}
+}
+
+func TestHooksOptional(t *testing.T) {
+	errFail := errors.Status(StatusNotFound, "not found")
+	ctx := context.Background()
+	tests := []struct {
+		name   string
+		client driver.Client
+		db     driver.DB
+		call   func(*Client, *DB) error
+	}{
+		{
+			name: "Find",
+			db: &mock.Finder{FindFunc: func(context.Context, interface{}) (driver.Rows, error) {
+				return nil, errFail
+			}},
+			call: func(_ *Client, db *DB) error {
+				_, err := db.Find(ctx, nil)
+				return err
+			},
+		},
+		{
+			name: "BulkDocs",
+			db: &mock.BulkDocer{BulkDocsFunc: func(context.Context, []interface{}, map[string]interface{}) (driver.BulkResults, error) {
+				return nil, errFail
+			}},
+			call: func(_ *Client, db *DB) error {
+				_, err := db.BulkDocs(ctx, []interface{}{map[string]string{}})
+				return err
+			},
+		},
+		{
+			name: "BulkGet",
+			db: &mock.BulkGetter{BulkGetFunc: func(context.Context, []driver.BulkGetReference, map[string]interface{}) (driver.Rows, error) {
+				return nil, errFail
+			}},
+			call: func(_ *Client, db *DB) error {
+				_, err := db.BulkGet(ctx, []BulkGetReference{{ID: "foo"}})
+				return err
+			},
+		},
+		{
+			name: "Purge",
+			db: &mock.Purger{PurgeFunc: func(context.Context, map[string][]string) (*driver.PurgeResult, error) {
+				return nil, errFail
+			}},
+			call: func(_ *Client, db *DB) error {
+				_, err := db.Purge(ctx, map[string][]string{"foo": {"1-xxx"}})
+				return err
+			},
+		},
+		{
+			name: "PartitionAllDocs",
+			db: &mock.Partitioner{PartitionAllDocsFunc: func(context.Context, string, map[string]interface{}) (driver.Rows, error) {
+				return nil, errFail
+			}},
+			call: func(_ *Client, db *DB) error {
+				_, err := db.PartitionAllDocs(ctx, "foo")
+				return err
+			},
+		},
+		{
+			name: "Ping",
+			client: &mock.Pinger{PingFunc: func(context.Context) (bool, error) {
+				return false, errFail
+			}},
+			call: func(c *Client, _ *DB) error {
+				_, err := c.Ping(ctx)
+				return err
+			},
+		},
+		{
+			name: "ActiveTasks",
+			client: &mock.ActiveTasker{ActiveTasksFunc: func(context.Context) ([]driver.ActiveTask, error) {
+				return nil, errFail
+			}},
+			call: func(c *Client, _ *DB) error {
+				_, err := c.ActiveTasks(ctx)
+				return err
+			},
+		},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			var calls []string
+			client := &Client{driverClient: test.client}
+			client.AddHook(&recordingHook{name: "a", calls: &calls})
+			db := &DB{client: client, name: "db", driverDB: test.db}
+			err := test.call(client, db)
+			testy.StatusError(t, "not found", StatusNotFound, err)
+			if len(calls) != 2 || !strings.HasPrefix(calls[0], "a before "+test.name+" ") {
+				t.Errorf("Unexpected hook calls: %v", calls)
+			}
+		})
+	}
+}
+
+type rowsRecordingHook struct {
+	recordingHook
+}
+
+var _ RowsHook = &rowsRecordingHook{}
+
+func (h *rowsRecordingHook) RowsDone(ctx context.Context, op *Operation, rows int64, err error) {
+	*h.calls = append(*h.calls, fmt.Sprintf("%s rows %s ctx=%v rows=%d err=%v", h.name, op.Name, ctx.Value(hookKey{}), rows, err))
+}
+
+func TestRowsHook(t *testing.T) {
+	var calls []string
+	client := &Client{}
+	client.AddHook(&rowsRecordingHook{recordingHook{name: "a", calls: &calls}})
+	ids := []string{"a", "b", "c"}
+	db := &DB{
+		client: client,
+		name:   "db",
+		driverDB: &mock.DB{
+			AllDocsFunc: func(_ context.Context, _ map[string]interface{}) (driver.Rows, error) {
+				return &mock.Rows{
+					NextFunc: func(row *driver.Row) error {
+						if len(ids) == 0 {
+							return io.EOF
+						}
+						row.ID, ids = ids[0], ids[1:]
+						return nil
+					},
+					CloseFunc: func() error { return nil },
+				}, nil
+			},
+		},
+	}
+	rows, err := db.AllDocs(context.Background())
+	if err != nil {
+		t.Fatal(err)
+	}
+	for rows.Next() {
+	}
+	if err := rows.Err(); err != nil {
+		t.Fatal(err)
+	}
+	_ = rows.Close()
+	expected := []string{
+		"a before AllDocs db/ map[]",
+		"a after AllDocs ctx=a err=<nil>",
+		"a rows AllDocs ctx=a rows=3 err=<nil>",
+	}
+	if d := diff.Interface(expected, calls); d != nil {
+		t.Error(d)
+	}
+}
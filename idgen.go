@@ -0,0 +1,92 @@
+package kivik
+
+import (
+	"crypto/rand"
+	"encoding/binary"
+	"encoding/hex"
+	"sync"
+	"time"
+)
+
+// IDGenerator generates document IDs for CreateDoc. It must be safe for
+// concurrent use.
+type IDGenerator func() (string, error)
+
+// SetIDGenerator sets the generator of IDs for documents created with
+// CreateDoc. When a generator is set, CreateDoc generates the ID on the client
+// and stores the document with Put, so that a retried CreateDoc cannot create
+// a duplicate document. A nil generator restores the default, which leaves ID
+// generation to the driver.
+//
+// SetIDGenerator should be called before the client is used.
+func (c *Client) SetIDGenerator(gen IDGenerator) {
+	c.idGenerator = gen
+}
+
+// idGen returns the client's IDGenerator, or nil if none is set. It is safe to
+// call on a nil client.
+func (c *Client) idGen() IDGenerator {
+	if c == nil {
+		return nil
+	}
+	return c.idGenerator
+}
+
+// UUIDv4 is an IDGenerator which returns random (version 4) UUIDs, formatted
+// as 32 hexadecimal digits, as are CouchDB's own UUIDs.
+func UUIDv4() (string, error) {
+	var uuid [16]byte
+	if _, err := rand.Read(uuid[:]); err != nil {
+		return "", err
+	}
+	uuid[6] = uuid[6]&0x0f | 0x40
+	uuid[8] = uuid[8]&0x3f | 0x80
+	return hex.EncodeToString(uuid[:]), nil
+}
+
+// UUIDv7 is an IDGenerator which returns time-ordered (version 7) UUIDs,
+// formatted as 32 hexadecimal digits. IDs generated in different milliseconds
+// sort in the order they were generated, which improves the locality of
+// inserts into the database's b-tree, compared to random IDs.
+func UUIDv7() (string, error) {
+	var uuid [16]byte
+	if _, err := rand.Read(uuid[6:]); err != nil {
+		return "", err
+	}
+	var ms [8]byte
+	binary.BigEndian.PutUint64(ms[:], uint64(time.Now().UnixNano()/int64(time.Millisecond)))
+	copy(uuid[:6], ms[2:])
+	uuid[6] = uuid[6]&0x0f | 0x70
+	uuid[8] = uuid[8]&0x3f | 0x80
+	return hex.EncodeToString(uuid[:]), nil
+}
+
+// SequentialIDs returns an IDGenerator which implements CouchDB's
+// "sequential" algorithm: each ID is a random 26-digit hexadecimal prefix,
+// followed by a 6-digit suffix which increases by a random amount with each
+// ID. When the suffix overflows, a new prefix is chosen.
+func SequentialIDs() IDGenerator {
+	var mu sync.Mutex
+	var prefix string
+	var suffix uint32
+	return func() (string, error) {
+		mu.Lock()
+		defer mu.Unlock()
+		var inc [1]byte
+		if _, err := rand.Read(inc[:]); err != nil {
+			return "", err
+		}
+		suffix += uint32(inc[0]) + 1
+		if prefix == "" || suffix > 0xffffff {
+			var p [13]byte
+			if _, err := rand.Read(p[:]); err != nil {
+				return "", err
+			}
+			prefix = hex.EncodeToString(p[:])
+			suffix = uint32(inc[0]) + 1
+		}
+		var s [4]byte
+		binary.BigEndian.PutUint32(s[:], suffix)
+		return prefix + hex.EncodeToString(s[1:]), nil
+	}
+}
@@ -0,0 +1,92 @@
+package kivik
+
+import (
+	"context"
+	"regexp"
+	"testing"
+
+	"github.com/flimzy/testy"
+	"github.com/go-kivik/kivik/errors"
+	"github.com/go-kivik/kivik/mock"
+)
+
+func TestIDGenerators(t *testing.T) {
+	tests := []struct {
+		name    string
+		gen     IDGenerator
+		pattern string
+	}{
+		{"UUIDv4", UUIDv4, "^[0-9a-f]{12}4[0-9a-f]{3}[89ab][0-9a-f]{15}$"},
+		{"UUIDv7", UUIDv7, "^[0-9a-f]{12}7[0-9a-f]{3}[89ab][0-9a-f]{15}$"},
+		{"sequential", SequentialIDs(), "^[0-9a-f]{32}$"},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			re := regexp.MustCompile(test.pattern)
+			seen := make(map[string]bool)
+			for i := 0; i < 100; i++ {
+				id, err := test.gen()
+				if err != nil {
+					t.Fatal(err)
+				}
+				if !re.MatchString(id) {
+					t.Fatalf("Unexpected ID format: %s", id)
+				}
+				if seen[id] {
+					t.Fatalf("Duplicate ID: %s", id)
+				}
+				seen[id] = true
+			}
+		})
+	}
+}
+
+func TestSequentialIDsOrder(t *testing.T) {
+	gen := SequentialIDs()
+	prev, _ := gen()
+	for i := 0; i < 1000; i++ {
+		id, _ := gen()
+		if id[:26] == prev[:26] && id <= prev {
+			t.Fatalf("%s is not after %s", id, prev)
+		}
+		prev = id
+	}
+}
+
+func TestCreateDocIDGenerator(t *testing.T) {
+	var putID string
+	var puts int
+	db := &DB{
+		client: &Client{},
+		driverDB: &mock.DB{
+			PutFunc: func(_ context.Context, docID string, _ interface{}, _ map[string]interface{}) (string, error) {
+				puts++
+				putID = docID
+				if puts == 1 {
+					return "", errors.Status(StatusServiceUnavailable, "try again")
+				}
+				return "1-xxx", nil
+			},
+		},
+	}
+	db.client.SetRetryPolicy(&RetryPolicy{MaxRetries: 1, MinBackoff: 1, RetryWrites: true})
+	db.client.SetIDGenerator(func() (string, error) {
+		return "generated", nil
+	})
+	doc := map[string]interface{}{"foo": "bar"}
+	docID, rev, err := db.CreateDoc(context.Background(), doc)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if docID != "generated" || rev != "1-xxx" || putID != "generated" || puts != 2 {
+		t.Errorf("Unexpected result: %s / %s / %s / %d", docID, rev, putID, puts)
+	}
+
+	t.Run("generator error", func(t *testing.T) {
+		db.client.SetIDGenerator(func() (string, error) {
+			return "", errors.New("no entropy")
+		})
+		_, _, err := db.CreateDoc(context.Background(), doc)
+		testy.Error(t, "kivik: generate document ID: no entropy", err)
+	})
+}
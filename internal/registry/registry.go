@@ -0,0 +1,73 @@
+// Package registry implements the driver.Driver of the wrapper drivers, such
+// as proxy and shard, whose clients are built from Go values, such as backend
+// clients, rather than from a data source name.
+//
+// kivik.New passes a driver only a data source name, so each value is
+// stored under a generated name while its client is created, and removed
+// once kivik.New returns, so that the registry retains nothing.
+package registry
+
+import (
+	"context"
+	"fmt"
+	"sync"
+
+	"github.com/go-kivik/kivik"
+	"github.com/go-kivik/kivik/driver"
+	"github.com/go-kivik/kivik/errors"
+)
+
+// Registry is a driver.Driver which creates its clients from stored values.
+type Registry struct {
+	name      string
+	newClient func(interface{}) (driver.Client, error)
+
+	mu      sync.Mutex
+	counter int
+	values  map[string]interface{}
+}
+
+// New returns a Registry for the driver registered as name, which creates
+// its clients by calling newClient with the value passed to NewClient.
+func New(name string, newClient func(interface{}) (driver.Client, error)) *Registry {
+	return &Registry{
+		name:      name,
+		newClient: newClient,
+		values:    make(map[string]interface{}),
+	}
+}
+
+// NewClient returns a kivik client of r's driver, created from v.
+func (r *Registry) NewClient(ctx context.Context, v interface{}) (*kivik.Client, error) {
+	r.mu.Lock()
+	r.counter++
+	dsn := fmt.Sprintf("%s%d", r.name, r.counter)
+	r.values[dsn] = v
+	r.mu.Unlock()
+	defer func() {
+		r.mu.Lock()
+		delete(r.values, dsn)
+		r.mu.Unlock()
+	}()
+	return kivik.New(ctx, r.name, dsn)
+}
+
+// Driver returns r as a driver.Driver. Its NewClient method, called by
+// kivik.New, accepts only the data source names generated by r.NewClient.
+func (r *Registry) Driver() driver.Driver {
+	return (*registryDriver)(r)
+}
+
+type registryDriver Registry
+
+var _ driver.Driver = &registryDriver{}
+
+func (d *registryDriver) NewClient(_ context.Context, dsn string) (driver.Client, error) {
+	d.mu.Lock()
+	v, ok := d.values[dsn]
+	d.mu.Unlock()
+	if !ok {
+		return nil, errors.Statusf(kivik.StatusBadRequest, "%s: unknown data source name %q; use %s.New", d.name, dsn, d.name)
+	}
+	return d.newClient(v)
+}
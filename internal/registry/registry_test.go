@@ -0,0 +1,34 @@
+package registry
+
+import (
+	"context"
+	"testing"
+
+	"github.com/go-kivik/kivik"
+	"github.com/go-kivik/kivik/driver"
+	"github.com/go-kivik/kivik/mock"
+)
+
+var testRegistry = New("registrytest", func(v interface{}) (driver.Client, error) {
+	return &mock.Client{ID: v.(string)}, nil
+})
+
+func init() {
+	kivik.Register("registrytest", testRegistry.Driver())
+}
+
+func TestNewClient(t *testing.T) {
+	ctx := context.Background()
+	client, err := testRegistry.NewClient(ctx, "foo")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(testRegistry.values) != 0 {
+		t.Errorf("Values retained after NewClient: %v", testRegistry.values)
+	}
+	// The data source name is no longer valid once the client is created.
+	_, err = kivik.New(ctx, "registrytest", client.DSN())
+	if kivik.StatusCode(err) != kivik.StatusBadRequest {
+		t.Errorf("Unexpected error: %v", err)
+	}
+}
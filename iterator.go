@@ -128,7 +128,7 @@ func (i *iter) Err() error {
 	return i.lasterr
 }
 
-func scan(dest interface{}, val json.RawMessage) error {
+func scan(codec JSONCodec, dest interface{}, val json.RawMessage) error {
 	if reflect.TypeOf(dest).Kind() != reflect.Ptr {
 		return errNonPtr
 	}
@@ -148,5 +148,8 @@ func scan(dest interface{}, val json.RawMessage) error {
 		*d = val
 		return nil
 	}
-	return errors.WrapStatus(StatusBadResponse, json.Unmarshal(val, dest))
+	if codec == nil {
+		codec = stdJSON{}
+	}
+	return errors.WrapStatus(StatusBadResponse, codec.Unmarshal(val, dest))
 }
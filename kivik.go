@@ -15,9 +15,16 @@ type Client struct {
 	dsn          string
 	driverName   string
 	driverClient driver.Client
+	retryPolicy  *RetryPolicy
+	hooks        []Hook
+	logger       Logger
+	jsonCodec    JSONCodec
+	idGenerator  IDGenerator
 }
 
-// Options is a collection of options. The keys and values are backend specific.
+// Options is a collection of options. The keys and values are backend
+// specific. Options for common query parameters may be constructed with
+// helpers such as IncludeDocs and StartKey, and combined with Param.
 type Options map[string]interface{}
 
 func mergeOptions(otherOpts ...Options) (Options, error) {
@@ -27,19 +34,41 @@ func mergeOptions(otherOpts ...Options) (Options, error) {
 			return nil, err
 		}
 	}
+	if err := validateOptions(options); err != nil {
+		return nil, err
+	}
 	return options, nil
 }
 
 // New creates a new client object specified by its database driver name
 // and a driver-specific data source name.
 func New(ctx context.Context, driverName, dataSourceName string) (*Client, error) {
+	return NewWithOptions(ctx, driverName, dataSourceName)
+}
+
+// NewWithOptions works like New, but passes driver-specific options to the
+// driver, such as HTTPClient. If options are provided, and the driver does
+// not support them, an error with status StatusNotImplemented is returned.
+func NewWithOptions(ctx context.Context, driverName, dataSourceName string, options ...Options) (*Client, error) {
 	driversMu.RLock()
 	driveri, ok := drivers[driverName]
 	driversMu.RUnlock()
 	if !ok {
 		return nil, errors.Statusf(StatusBadRequest, "kivik: unknown driver %q (forgotten import?)", driverName)
 	}
-	client, err := driveri.NewClient(ctx, dataSourceName)
+	opts, err := mergeOptions(options...)
+	if err != nil {
+		return nil, err
+	}
+	var client driver.Client
+	if od, ok := driveri.(driver.OptionsDriver); ok {
+		client, err = od.NewClientWithOptions(ctx, dataSourceName, opts)
+	} else {
+		if len(opts) > 0 {
+			return nil, errors.Statusf(StatusNotImplemented, "kivik: driver %q does not support client options", driverName)
+		}
+		client, err = driveri.NewClient(ctx, dataSourceName)
+	}
 	if err != nil {
 		return nil, err
 	}
@@ -66,6 +95,9 @@ type Version struct {
 	Version string
 	// Vendor is the vendor string reported by the server or backend.
 	Vendor string
+	// Features is a list of enabled, optional features. This was added in
+	// CouchDB 2.1.0, and can be expected to be empty for older versions.
+	Features []string
 	// RawResponse is the raw response body returned by the server, useful if
 	// you need additional backend-specific information.
 	//
@@ -76,13 +108,19 @@ type Version struct {
 
 // Version returns version and vendor info about the backend.
 func (c *Client) Version(ctx context.Context) (*Version, error) {
-	ver, err := c.driverClient.Version(ctx)
+	var ver *driver.Version
+	err := c.do(ctx, &Operation{Name: "Version"}, false, func(ctx context.Context) error {
+		var e error
+		ver, e = c.driverClient.Version(ctx)
+		return e
+	})
 	if err != nil {
 		return nil, err
 	}
 	return &Version{
 		Version:     ver.Version,
 		Vendor:      ver.Vendor,
+		Features:    ver.Features,
 		RawResponse: ver.RawResponse,
 	}, nil
 }
@@ -108,7 +146,61 @@ func (c *Client) AllDBs(ctx context.Context, options ...Options) ([]string, erro
 	if err != nil {
 		return nil, err
 	}
-	return c.driverClient.AllDBs(ctx, opts)
+	var dbs []string
+	err = c.do(ctx, &Operation{Name: "AllDBs", Options: opts}, false, func(ctx context.Context) error {
+		var e error
+		dbs, e = c.driverClient.AllDBs(ctx, opts)
+		return e
+	})
+	return dbs, err
+}
+
+// DBsStats returns the statistics of each named database, in the order
+// requested, with a nil entry for any database which does not exist. Drivers
+// which support it, such as for CouchDB 2.2 and later, fetch the statistics
+// in a single request. Otherwise, Stats is called for each database in turn.
+//
+// See http://docs.couchdb.org/en/2.2.0/api/server/common.html#dbs-info
+func (c *Client) DBsStats(ctx context.Context, dbnames []string) ([]*DBStats, error) {
+	statser, ok := c.driverClient.(driver.DBsStatser)
+	if !ok {
+		return c.emulateDBsStats(ctx, dbnames)
+	}
+	var stats []*driver.DBStats
+	err := c.do(ctx, &Operation{Name: "DBsStats"}, false, func(ctx context.Context) error {
+		var e error
+		stats, e = statser.DBsStats(ctx, dbnames)
+		return e
+	})
+	if err != nil {
+		return nil, err
+	}
+	result := make([]*DBStats, len(stats))
+	for i, s := range stats {
+		if s != nil {
+			result[i] = newDBStats(s)
+		}
+	}
+	return result, nil
+}
+
+func (c *Client) emulateDBsStats(ctx context.Context, dbnames []string) ([]*DBStats, error) {
+	result := make([]*DBStats, len(dbnames))
+	for i, dbname := range dbnames {
+		db, err := c.DB(ctx, dbname)
+		if err != nil {
+			return nil, err
+		}
+		stats, err := db.Stats(ctx)
+		switch {
+		case StatusCode(err) == StatusNotFound:
+			continue
+		case err != nil:
+			return nil, err
+		}
+		result[i] = stats
+	}
+	return result, nil
 }
 
 // DBExists returns true if the specified database exists.
@@ -117,18 +209,32 @@ func (c *Client) DBExists(ctx context.Context, dbName string, options ...Options
 	if err != nil {
 		return false, err
 	}
-	return c.driverClient.DBExists(ctx, dbName, opts)
+	var exists bool
+	err = c.do(ctx, &Operation{Name: "DBExists", DB: dbName, Options: opts}, false, func(ctx context.Context) error {
+		var e error
+		exists, e = c.driverClient.DBExists(ctx, dbName, opts)
+		return e
+	})
+	return exists, err
 }
 
-// CreateDB creates a DB of the requested name.
+// CreateDB creates a DB of the requested name. The "partitioned" option, if
+// provided, must be a bool, and requests a partitioned database from backends
+// which support them.
 func (c *Client) CreateDB(ctx context.Context, dbName string, options ...Options) (*DB, error) {
 	opts, err := mergeOptions(options...)
 	if err != nil {
 		return nil, err
 	}
-	if e := c.driverClient.CreateDB(ctx, dbName, opts); e != nil {
+	if e := validatePartitionedOption(opts); e != nil {
 		return nil, e
 	}
+	err = c.do(ctx, &Operation{Name: "CreateDB", DB: dbName, Options: opts}, true, func(ctx context.Context) error {
+		return c.driverClient.CreateDB(ctx, dbName, opts)
+	})
+	if err != nil {
+		return nil, err
+	}
 	return c.DB(ctx, dbName, nil)
 }
 
@@ -138,7 +244,9 @@ func (c *Client) DestroyDB(ctx context.Context, dbName string, options ...Option
 	if err != nil {
 		return err
 	}
-	return c.driverClient.DestroyDB(ctx, dbName, opts)
+	return c.do(ctx, &Operation{Name: "DestroyDB", DB: dbName, Options: opts}, true, func(ctx context.Context) error {
+		return c.driverClient.DestroyDB(ctx, dbName, opts)
+	})
 }
 
 // Authenticate authenticates the client with the passed authenticator, which
@@ -146,11 +254,48 @@ func (c *Client) DestroyDB(ctx context.Context, dbName string, options ...Option
 // error will be returned.
 func (c *Client) Authenticate(ctx context.Context, a interface{}) error {
 	if auth, ok := c.driverClient.(driver.Authenticator); ok {
-		return auth.Authenticate(ctx, a)
+		return c.do(ctx, &Operation{Name: "Authenticate"}, true, func(ctx context.Context) error {
+			return auth.Authenticate(ctx, a)
+		})
 	}
 	return errors.Status(StatusNotImplemented, "kivik: driver does not support authentication")
 }
 
+// Ping returns true if the database is online and available for requests,
+// such as by querying the /_up endpoint. This is intended as a cheap health
+// check, so is never retried. If the underlying driver supports the Pinger
+// interface, it will be used. Otherwise, a fallback is made to calling
+// Version, and any failure is reported as the server being down, rather than
+// as an error.
+func (c *Client) Ping(ctx context.Context) (bool, error) {
+	pinger, ok := c.driverClient.(driver.Pinger)
+	var up bool
+	err := callHooks(ctx, c.hooks, &Operation{Name: "Ping"}, func(ctx context.Context) error {
+		if ok {
+			var e error
+			up, e = pinger.Ping(ctx)
+			return e
+		}
+		_, e := c.driverClient.Version(ctx)
+		up = e == nil
+		return e
+	})
+	if !ok {
+		return up, nil
+	}
+	return up, err
+}
+
+// Close cleans up any resources used by the client, if supported by the
+// driver. The client should not be used after Close is called. For drivers
+// which hold no such resources, Close is a no-op.
+func (c *Client) Close(ctx context.Context) error {
+	if closer, ok := c.driverClient.(driver.ClientCloser); ok {
+		return closer.Close(ctx)
+	}
+	return nil
+}
+
 func missingArg(arg string) error {
 	return errors.Statusf(StatusBadRequest, "kivik: %s required", arg)
 }
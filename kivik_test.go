@@ -4,11 +4,14 @@ import (
 	"context"
 	"errors"
 	"fmt"
+	"net/http"
 	"testing"
+	"time"
 
 	"github.com/flimzy/diff"
 	"github.com/flimzy/testy"
 	"github.com/go-kivik/kivik/driver"
+	kerrors "github.com/go-kivik/kivik/errors"
 	"github.com/go-kivik/kivik/mock"
 )
 
@@ -78,6 +81,86 @@ func TestNew(t *testing.T) {
 	}
 }
 
+func TestNewWithOptions(t *testing.T) {
+	registryMU.Lock()
+	defer registryMU.Unlock()
+	httpClient := &http.Client{}
+	tests := []struct {
+		name     string
+		driver   driver.Driver
+		options  []Options
+		expected *Client
+		status   int
+		err      string
+	}{
+		{
+			name: "options not supported",
+			driver: &mock.Driver{
+				NewClientFunc: func(_ context.Context, _ string) (driver.Client, error) {
+					return &mock.Client{ID: "foo"}, nil
+				},
+			},
+			options: []Options{HTTPClient(httpClient)},
+			status:  StatusNotImplemented,
+			err:     `kivik: driver "foo" does not support client options`,
+		},
+		{
+			name: "no options, unsupported",
+			driver: &mock.Driver{
+				NewClientFunc: func(_ context.Context, _ string) (driver.Client, error) {
+					return &mock.Client{ID: "foo"}, nil
+				},
+			},
+			expected: &Client{
+				dsn:          "oink",
+				driverName:   "foo",
+				driverClient: &mock.Client{ID: "foo"},
+			},
+		},
+		{
+			name: "options passed",
+			driver: &mock.OptionsDriver{
+				NewClientWithOptionsFunc: func(_ context.Context, dsn string, opts map[string]interface{}) (driver.Client, error) {
+					if opts[OptionHTTPClient] != httpClient {
+						return nil, fmt.Errorf("Unexpected options: %v", opts)
+					}
+					return &mock.Client{ID: dsn}, nil
+				},
+			},
+			options: []Options{HTTPClient(httpClient)},
+			expected: &Client{
+				dsn:          "oink",
+				driverName:   "foo",
+				driverClient: &mock.Client{ID: "oink"},
+			},
+		},
+		{
+			name: "driver error",
+			driver: &mock.OptionsDriver{
+				NewClientWithOptionsFunc: func(_ context.Context, _ string, _ map[string]interface{}) (driver.Client, error) {
+					return nil, errors.New("unknown option")
+				},
+			},
+			options: []Options{{"foo": "bar"}},
+			status:  StatusInternalServerError,
+			err:     "unknown option",
+		},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			defer func() {
+				drivers = make(map[string]driver.Driver)
+			}()
+			Register("foo", test.driver)
+			result, err := NewWithOptions(context.Background(), "foo", "oink", test.options...)
+			testy.StatusError(t, test.err, test.status, err)
+			if d := diff.Interface(test.expected, result); d != nil {
+				t.Error(d)
+			}
+		})
+	}
+}
+
 func TestClientGetters(t *testing.T) {
 	driverName := "foo"
 	dsn := "bar"
@@ -333,6 +416,16 @@ func TestCreateDB(t *testing.T) {
 			status: StatusInternalServerError,
 			err:    "db error",
 		},
+		{
+			name: "invalid partitioned option",
+			client: &Client{
+				driverClient: &mock.Client{},
+			},
+			dbName: "foo",
+			opts:   map[string]interface{}{"partitioned": "true"},
+			status: StatusBadRequest,
+			err:    "kivik: invalid type string for 'partitioned' option",
+		},
 		{
 			name: "success",
 			client: &Client{
@@ -474,3 +567,135 @@ func TestAuthenticate(t *testing.T) {
 		})
 	}
 }
+
+func TestPing(t *testing.T) {
+	tests := []struct {
+		name     string
+		client   *Client
+		expected bool
+		status   int
+		err      string
+	}{
+		{
+			name: "non-pinger error",
+			client: &Client{
+				driverClient: &mock.Client{
+					VersionFunc: func(_ context.Context) (*driver.Version, error) {
+						return nil, errors.New("version error")
+					},
+				},
+			},
+			expected: false,
+		},
+		{
+			name: "non-pinger success",
+			client: &Client{
+				driverClient: &mock.Client{
+					VersionFunc: func(_ context.Context) (*driver.Version, error) {
+						return &driver.Version{}, nil
+					},
+				},
+			},
+			expected: true,
+		},
+		{
+			name: "pinger error",
+			client: &Client{
+				driverClient: &mock.Pinger{
+					PingFunc: func(_ context.Context) (bool, error) {
+						return false, errors.New("ping error")
+					},
+				},
+			},
+			status: StatusInternalServerError,
+			err:    "ping error",
+		},
+		{
+			name: "pinger unavailable",
+			client: &Client{
+				driverClient: &mock.Pinger{
+					PingFunc: func(_ context.Context) (bool, error) {
+						return false, nil
+					},
+				},
+			},
+			expected: false,
+		},
+		{
+			name: "pinger success",
+			client: &Client{
+				driverClient: &mock.Pinger{
+					PingFunc: func(_ context.Context) (bool, error) {
+						return true, nil
+					},
+				},
+			},
+			expected: true,
+		},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			result, err := test.client.Ping(context.Background())
+			testy.StatusError(t, test.err, test.status, err)
+			if result != test.expected {
+				t.Errorf("Unexpected result: %t", result)
+			}
+		})
+	}
+	t.Run("no retry", func(t *testing.T) {
+		var pings int
+		client := &Client{
+			driverClient: &mock.Pinger{
+				PingFunc: func(_ context.Context) (bool, error) {
+					pings++
+					return false, kerrors.Status(StatusServiceUnavailable, "unavailable")
+				},
+			},
+		}
+		client.SetRetryPolicy(&RetryPolicy{MaxRetries: 3, MinBackoff: time.Millisecond})
+		_, err := client.Ping(context.Background())
+		testy.StatusError(t, "unavailable", StatusServiceUnavailable, err)
+		if pings != 1 {
+			t.Errorf("Expected 1 ping, got %d", pings)
+		}
+	})
+}
+
+func TestClientClose(t *testing.T) {
+	tests := []struct {
+		name   string
+		client driver.Client
+		status int
+		err    string
+	}{
+		{
+			name:   "non-closer",
+			client: &mock.Client{},
+		},
+		{
+			name: "close error",
+			client: &mock.ClientCloser{
+				CloseFunc: func(_ context.Context) error {
+					return errors.New("close error")
+				},
+			},
+			status: StatusInternalServerError,
+			err:    "close error",
+		},
+		{
+			name: "success",
+			client: &mock.ClientCloser{
+				CloseFunc: func(_ context.Context) error {
+					return nil
+				},
+			},
+		},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			c := &Client{driverClient: test.client}
+			err := c.Close(context.Background())
+			testy.StatusError(t, test.err, test.status, err)
+		})
+	}
+}
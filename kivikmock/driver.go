@@ -0,0 +1,287 @@
+package kivikmock
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"io/ioutil"
+
+	"github.com/go-kivik/kivik"
+	"github.com/go-kivik/kivik/driver"
+	"github.com/go-kivik/kivik/errors"
+)
+
+// client implements driver.Client, answering each call from the mock's
+// expectations.
+type client struct {
+	mock *Client
+}
+
+var _ driver.Client = &client{}
+
+func (c *client) AllDBs(ctx context.Context, opts map[string]interface{}) ([]string, error) {
+	e, err := c.mock.next("AllDBs", func(e expectation) error {
+		return e.(*ExpectedAllDBs).matchOptions(opts)
+	})
+	if err != nil {
+		return nil, err
+	}
+	exp := e.(*ExpectedAllDBs)
+	if err := exp.result(ctx); err != nil {
+		return nil, err
+	}
+	return exp.dbs, nil
+}
+
+func (c *client) CreateDB(ctx context.Context, dbName string, opts map[string]interface{}) error {
+	e, err := c.mock.next("CreateDB", func(e expectation) error {
+		exp := e.(*ExpectedCreateDB)
+		if err := matchString("database", exp.name, dbName); err != nil {
+			return err
+		}
+		return exp.matchOptions(opts)
+	})
+	if err != nil {
+		return err
+	}
+	return e.(*ExpectedCreateDB).result(ctx)
+}
+
+func (c *client) DestroyDB(ctx context.Context, dbName string, opts map[string]interface{}) error {
+	e, err := c.mock.next("DestroyDB", func(e expectation) error {
+		exp := e.(*ExpectedDestroyDB)
+		if err := matchString("database", exp.name, dbName); err != nil {
+			return err
+		}
+		return exp.matchOptions(opts)
+	})
+	if err != nil {
+		return err
+	}
+	return e.(*ExpectedDestroyDB).result(ctx)
+}
+
+func (c *client) DBExists(ctx context.Context, dbName string, opts map[string]interface{}) (bool, error) {
+	e, err := c.mock.next("DBExists", func(e expectation) error {
+		exp := e.(*ExpectedDBExists)
+		if err := matchString("database", exp.name, dbName); err != nil {
+			return err
+		}
+		return exp.matchOptions(opts)
+	})
+	if err != nil {
+		return false, err
+	}
+	exp := e.(*ExpectedDBExists)
+	if err := exp.result(ctx); err != nil {
+		return false, err
+	}
+	return exp.exists, nil
+}
+
+func (c *client) Version(ctx context.Context) (*driver.Version, error) {
+	e, err := c.mock.next("Version", func(expectation) error { return nil })
+	if err != nil {
+		return nil, err
+	}
+	exp := e.(*ExpectedVersion)
+	if err := exp.result(ctx); err != nil {
+		return nil, err
+	}
+	return exp.version, nil
+}
+
+// DB returns a handle to the named database. It is not itself an expected
+// call, as kivik calls it implicitly.
+func (c *client) DB(_ context.Context, dbName string, _ map[string]interface{}) (driver.DB, error) {
+	return &db{mock: c.mock, name: dbName}, nil
+}
+
+// db implements driver.DB. Methods without a corresponding expectation
+// always fail, as unexpected calls.
+type db struct {
+	mock *Client
+	name string
+}
+
+var (
+	_ driver.DB     = &db{}
+	_ driver.Finder = &db{}
+)
+
+// call returns the expectation matching a call to method, after any
+// expected delay, or the expected error.
+func (d *db) call(ctx context.Context, method, docID string, opts map[string]interface{}, match func(expectation) error) (expectation, error) {
+	e, err := d.mock.next(method, func(e expectation) error {
+		if err := e.(dbExpecter).base().match(d.name, docID, opts); err != nil {
+			return err
+		}
+		if match != nil {
+			return match(e)
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return e, e.(dbExpecter).base().result(ctx)
+}
+
+type dbExpecter interface {
+	base() *dbExpectation
+}
+
+func (e *dbExpectation) base() *dbExpectation { return e }
+
+func (d *db) Get(ctx context.Context, docID string, opts map[string]interface{}) (*driver.Document, error) {
+	e, err := d.call(ctx, "Get", docID, opts, nil)
+	if err != nil {
+		return nil, err
+	}
+	body, err := json.Marshal(e.(*ExpectedGet).doc)
+	if err != nil {
+		return nil, err
+	}
+	var meta struct {
+		Rev string `json:"_rev"`
+	}
+	_ = json.Unmarshal(body, &meta)
+	return &driver.Document{
+		ContentLength: int64(len(body)),
+		Rev:           meta.Rev,
+		Body:          ioutil.NopCloser(bytes.NewReader(body)),
+	}, nil
+}
+
+func (d *db) Put(ctx context.Context, docID string, doc interface{}, opts map[string]interface{}) (string, error) {
+	e, err := d.call(ctx, "Put", docID, opts, func(e expectation) error {
+		return matchJSON("document", e.(*ExpectedPut).doc, doc)
+	})
+	if err != nil {
+		return "", err
+	}
+	return e.(*ExpectedPut).rev, nil
+}
+
+func (d *db) CreateDoc(ctx context.Context, doc interface{}, opts map[string]interface{}) (string, string, error) {
+	e, err := d.call(ctx, "CreateDoc", "", opts, func(e expectation) error {
+		return matchJSON("document", e.(*ExpectedCreateDoc).doc, doc)
+	})
+	if err != nil {
+		return "", "", err
+	}
+	exp := e.(*ExpectedCreateDoc)
+	return exp.newID, exp.rev, nil
+}
+
+func (d *db) Delete(ctx context.Context, docID, rev string, opts map[string]interface{}) (string, error) {
+	e, err := d.call(ctx, "Delete", docID, opts, func(e expectation) error {
+		return matchString("rev", e.(*ExpectedDelete).rev, rev)
+	})
+	if err != nil {
+		return "", err
+	}
+	return e.(*ExpectedDelete).newRev, nil
+}
+
+func (d *db) AllDocs(ctx context.Context, opts map[string]interface{}) (driver.Rows, error) {
+	e, err := d.call(ctx, "AllDocs", "", opts, nil)
+	if err != nil {
+		return nil, err
+	}
+	return e.(*ExpectedAllDocs).rows.iter(), nil
+}
+
+func (d *db) Query(ctx context.Context, ddoc, view string, opts map[string]interface{}) (driver.Rows, error) {
+	e, err := d.call(ctx, "Query", "", opts, func(e expectation) error {
+		exp := e.(*ExpectedQuery)
+		if err := matchString("design document", exp.ddoc, ddoc); err != nil {
+			return err
+		}
+		return matchString("view", exp.view, view)
+	})
+	if err != nil {
+		return nil, err
+	}
+	return e.(*ExpectedQuery).rows.iter(), nil
+}
+
+func (d *db) Find(ctx context.Context, query interface{}) (driver.Rows, error) {
+	e, err := d.call(ctx, "Find", "", nil, func(e expectation) error {
+		return matchJSON("query", e.(*ExpectedFind).query, query)
+	})
+	if err != nil {
+		return nil, err
+	}
+	return e.(*ExpectedFind).rows.iter(), nil
+}
+
+func (d *db) Changes(ctx context.Context, opts map[string]interface{}) (driver.Changes, error) {
+	e, err := d.call(ctx, "Changes", "", opts, nil)
+	if err != nil {
+		return nil, err
+	}
+	return e.(*ExpectedChanges).changes.iter(), nil
+}
+
+func (d *db) Stats(ctx context.Context) (*driver.DBStats, error) {
+	e, err := d.call(ctx, "Stats", "", nil, nil)
+	if err != nil {
+		return nil, err
+	}
+	return e.(*ExpectedStats).stats, nil
+}
+
+// unexpected returns the error for a call to a method which may not be
+// expected.
+func (d *db) unexpected(method string) error {
+	return errors.Statusf(kivik.StatusNotImplemented, "kivikmock: call to %s was not expected; it is not supported by kivikmock", method)
+}
+
+func (d *db) CreateIndex(_ context.Context, _, _ string, _ interface{}) error {
+	return d.unexpected("CreateIndex")
+}
+
+func (d *db) GetIndexes(_ context.Context) ([]driver.Index, error) {
+	return nil, d.unexpected("GetIndexes")
+}
+
+func (d *db) DeleteIndex(_ context.Context, _, _ string) error {
+	return d.unexpected("DeleteIndex")
+}
+
+func (d *db) Explain(_ context.Context, _ interface{}) (*driver.QueryPlan, error) {
+	return nil, d.unexpected("Explain")
+}
+
+func (d *db) Compact(_ context.Context) error {
+	return d.unexpected("Compact")
+}
+
+func (d *db) CompactView(_ context.Context, _ string) error {
+	return d.unexpected("CompactView")
+}
+
+func (d *db) ViewCleanup(_ context.Context) error {
+	return d.unexpected("ViewCleanup")
+}
+
+func (d *db) Security(_ context.Context) (*driver.Security, error) {
+	return nil, d.unexpected("Security")
+}
+
+func (d *db) SetSecurity(_ context.Context, _ *driver.Security) error {
+	return d.unexpected("SetSecurity")
+}
+
+func (d *db) PutAttachment(_ context.Context, _, _ string, _ *driver.Attachment, _ map[string]interface{}) (string, error) {
+	return "", d.unexpected("PutAttachment")
+}
+
+func (d *db) GetAttachment(_ context.Context, _, _, _ string, _ map[string]interface{}) (*driver.Attachment, error) {
+	return nil, d.unexpected("GetAttachment")
+}
+
+func (d *db) DeleteAttachment(_ context.Context, _, _, _ string, _ map[string]interface{}) (string, error) {
+	return "", d.unexpected("DeleteAttachment")
+}
@@ -0,0 +1,677 @@
+package kivikmock
+
+import (
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"time"
+
+	"github.com/go-kivik/kivik/driver"
+)
+
+// ExpectedAllDBs is an expected call to AllDBs.
+type ExpectedAllDBs struct {
+	expectationBase
+	dbs []string
+}
+
+// ExpectAllDBs expects a call to AllDBs.
+func (c *Client) ExpectAllDBs() *ExpectedAllDBs {
+	e := &ExpectedAllDBs{}
+	c.expect(e)
+	return e
+}
+
+func (e *ExpectedAllDBs) method() string { return "AllDBs" }
+func (e *ExpectedAllDBs) String() string { return "AllDBs()" }
+
+// WithOptions sets the options the call must pass.
+func (e *ExpectedAllDBs) WithOptions(opts map[string]interface{}) *ExpectedAllDBs {
+	e.options = opts
+	return e
+}
+
+// WillReturn sets the database names to return.
+func (e *ExpectedAllDBs) WillReturn(dbs []string) *ExpectedAllDBs {
+	e.dbs = dbs
+	return e
+}
+
+// WillReturnError sets the error to return.
+func (e *ExpectedAllDBs) WillReturnError(err error) *ExpectedAllDBs {
+	e.err = err
+	return e
+}
+
+// WillDelay delays the return of the call.
+func (e *ExpectedAllDBs) WillDelay(d time.Duration) *ExpectedAllDBs {
+	e.delay = d
+	return e
+}
+
+// ExpectedCreateDB is an expected call to CreateDB.
+type ExpectedCreateDB struct {
+	expectationBase
+	name string
+}
+
+// ExpectCreateDB expects a call to CreateDB, for the named database. An
+// empty name matches any database.
+func (c *Client) ExpectCreateDB(dbName string) *ExpectedCreateDB {
+	e := &ExpectedCreateDB{name: dbName}
+	c.expect(e)
+	return e
+}
+
+func (e *ExpectedCreateDB) method() string { return "CreateDB" }
+func (e *ExpectedCreateDB) String() string { return fmt.Sprintf("CreateDB(%q)", e.name) }
+
+// WithOptions sets the options the call must pass.
+func (e *ExpectedCreateDB) WithOptions(opts map[string]interface{}) *ExpectedCreateDB {
+	e.options = opts
+	return e
+}
+
+// WillReturnError sets the error to return.
+func (e *ExpectedCreateDB) WillReturnError(err error) *ExpectedCreateDB {
+	e.err = err
+	return e
+}
+
+// WillDelay delays the return of the call.
+func (e *ExpectedCreateDB) WillDelay(d time.Duration) *ExpectedCreateDB {
+	e.delay = d
+	return e
+}
+
+// ExpectedDestroyDB is an expected call to DestroyDB.
+type ExpectedDestroyDB struct {
+	expectationBase
+	name string
+}
+
+// ExpectDestroyDB expects a call to DestroyDB, for the named database. An
+// empty name matches any database.
+func (c *Client) ExpectDestroyDB(dbName string) *ExpectedDestroyDB {
+	e := &ExpectedDestroyDB{name: dbName}
+	c.expect(e)
+	return e
+}
+
+func (e *ExpectedDestroyDB) method() string { return "DestroyDB" }
+func (e *ExpectedDestroyDB) String() string { return fmt.Sprintf("DestroyDB(%q)", e.name) }
+
+// WithOptions sets the options the call must pass.
+func (e *ExpectedDestroyDB) WithOptions(opts map[string]interface{}) *ExpectedDestroyDB {
+	e.options = opts
+	return e
+}
+
+// WillReturnError sets the error to return.
+func (e *ExpectedDestroyDB) WillReturnError(err error) *ExpectedDestroyDB {
+	e.err = err
+	return e
+}
+
+// WillDelay delays the return of the call.
+func (e *ExpectedDestroyDB) WillDelay(d time.Duration) *ExpectedDestroyDB {
+	e.delay = d
+	return e
+}
+
+// ExpectedDBExists is an expected call to DBExists.
+type ExpectedDBExists struct {
+	expectationBase
+	name   string
+	exists bool
+}
+
+// ExpectDBExists expects a call to DBExists, for the named database. An
+// empty name matches any database.
+func (c *Client) ExpectDBExists(dbName string) *ExpectedDBExists {
+	e := &ExpectedDBExists{name: dbName}
+	c.expect(e)
+	return e
+}
+
+func (e *ExpectedDBExists) method() string { return "DBExists" }
+func (e *ExpectedDBExists) String() string { return fmt.Sprintf("DBExists(%q)", e.name) }
+
+// WithOptions sets the options the call must pass.
+func (e *ExpectedDBExists) WithOptions(opts map[string]interface{}) *ExpectedDBExists {
+	e.options = opts
+	return e
+}
+
+// WillReturn sets whether the database exists.
+func (e *ExpectedDBExists) WillReturn(exists bool) *ExpectedDBExists {
+	e.exists = exists
+	return e
+}
+
+// WillReturnError sets the error to return.
+func (e *ExpectedDBExists) WillReturnError(err error) *ExpectedDBExists {
+	e.err = err
+	return e
+}
+
+// WillDelay delays the return of the call.
+func (e *ExpectedDBExists) WillDelay(d time.Duration) *ExpectedDBExists {
+	e.delay = d
+	return e
+}
+
+// ExpectedVersion is an expected call to Version.
+type ExpectedVersion struct {
+	expectationBase
+	version *driver.Version
+}
+
+// ExpectVersion expects a call to Version.
+func (c *Client) ExpectVersion() *ExpectedVersion {
+	e := &ExpectedVersion{version: &driver.Version{}}
+	c.expect(e)
+	return e
+}
+
+func (e *ExpectedVersion) method() string { return "Version" }
+func (e *ExpectedVersion) String() string { return "Version()" }
+
+// WillReturn sets the version to return.
+func (e *ExpectedVersion) WillReturn(version *driver.Version) *ExpectedVersion {
+	e.version = version
+	return e
+}
+
+// WillReturnError sets the error to return.
+func (e *ExpectedVersion) WillReturnError(err error) *ExpectedVersion {
+	e.err = err
+	return e
+}
+
+// WillDelay delays the return of the call.
+func (e *ExpectedVersion) WillDelay(d time.Duration) *ExpectedVersion {
+	e.delay = d
+	return e
+}
+
+// dbExpectation holds the state common to expectations of database methods.
+type dbExpectation struct {
+	expectationBase
+	db    string
+	docID string
+}
+
+func (e *dbExpectation) match(dbName, docID string, opts map[string]interface{}) error {
+	if err := matchString("database", e.db, dbName); err != nil {
+		return err
+	}
+	if err := matchString("document ID", e.docID, docID); err != nil {
+		return err
+	}
+	return e.matchOptions(opts)
+}
+
+func (e *dbExpectation) describe(method string) string {
+	if e.docID == "" {
+		return fmt.Sprintf("%s(db=%q)", method, e.db)
+	}
+	return fmt.Sprintf("%s(db=%q, docID=%q)", method, e.db, e.docID)
+}
+
+// ExpectedGet is an expected call to DB.Get.
+type ExpectedGet struct {
+	dbExpectation
+	doc interface{}
+}
+
+// ExpectGet expects a call to Get, on the named database. An empty name
+// matches any database.
+func (c *Client) ExpectGet(dbName string) *ExpectedGet {
+	e := &ExpectedGet{dbExpectation: dbExpectation{db: dbName}}
+	c.expect(e)
+	return e
+}
+
+func (e *ExpectedGet) method() string { return "Get" }
+func (e *ExpectedGet) String() string { return e.describe("Get") }
+
+// WithDocID sets the document ID the call must request.
+func (e *ExpectedGet) WithDocID(docID string) *ExpectedGet {
+	e.docID = docID
+	return e
+}
+
+// WithOptions sets the options the call must pass.
+func (e *ExpectedGet) WithOptions(opts map[string]interface{}) *ExpectedGet {
+	e.options = opts
+	return e
+}
+
+// WillReturn sets the document to return. It is marshaled to JSON, and its
+// revision is read from its _rev field.
+func (e *ExpectedGet) WillReturn(doc interface{}) *ExpectedGet {
+	e.doc = doc
+	return e
+}
+
+// WillReturnError sets the error to return.
+func (e *ExpectedGet) WillReturnError(err error) *ExpectedGet {
+	e.err = err
+	return e
+}
+
+// WillDelay delays the return of the call.
+func (e *ExpectedGet) WillDelay(d time.Duration) *ExpectedGet {
+	e.delay = d
+	return e
+}
+
+// ExpectedPut is an expected call to DB.Put.
+type ExpectedPut struct {
+	dbExpectation
+	doc interface{}
+	rev string
+}
+
+// ExpectPut expects a call to Put, on the named database. An empty name
+// matches any database.
+func (c *Client) ExpectPut(dbName string) *ExpectedPut {
+	e := &ExpectedPut{dbExpectation: dbExpectation{db: dbName}}
+	c.expect(e)
+	return e
+}
+
+func (e *ExpectedPut) method() string { return "Put" }
+func (e *ExpectedPut) String() string { return e.describe("Put") }
+
+// WithDocID sets the document ID the call must write.
+func (e *ExpectedPut) WithDocID(docID string) *ExpectedPut {
+	e.docID = docID
+	return e
+}
+
+// WithDoc sets the document the call must write. Documents are compared by
+// their JSON representations.
+func (e *ExpectedPut) WithDoc(doc interface{}) *ExpectedPut {
+	e.doc = doc
+	return e
+}
+
+// WithOptions sets the options the call must pass.
+func (e *ExpectedPut) WithOptions(opts map[string]interface{}) *ExpectedPut {
+	e.options = opts
+	return e
+}
+
+// WillReturn sets the revision to return.
+func (e *ExpectedPut) WillReturn(rev string) *ExpectedPut {
+	e.rev = rev
+	return e
+}
+
+// WillReturnError sets the error to return.
+func (e *ExpectedPut) WillReturnError(err error) *ExpectedPut {
+	e.err = err
+	return e
+}
+
+// WillDelay delays the return of the call.
+func (e *ExpectedPut) WillDelay(d time.Duration) *ExpectedPut {
+	e.delay = d
+	return e
+}
+
+// ExpectedCreateDoc is an expected call to DB.CreateDoc.
+type ExpectedCreateDoc struct {
+	dbExpectation
+	doc        interface{}
+	newID, rev string
+}
+
+// ExpectCreateDoc expects a call to CreateDoc, on the named database. An
+// empty name matches any database.
+func (c *Client) ExpectCreateDoc(dbName string) *ExpectedCreateDoc {
+	e := &ExpectedCreateDoc{dbExpectation: dbExpectation{db: dbName}}
+	c.expect(e)
+	return e
+}
+
+func (e *ExpectedCreateDoc) method() string { return "CreateDoc" }
+func (e *ExpectedCreateDoc) String() string { return e.describe("CreateDoc") }
+
+// WithDoc sets the document the call must write. Documents are compared by
+// their JSON representations.
+func (e *ExpectedCreateDoc) WithDoc(doc interface{}) *ExpectedCreateDoc {
+	e.doc = doc
+	return e
+}
+
+// WithOptions sets the options the call must pass.
+func (e *ExpectedCreateDoc) WithOptions(opts map[string]interface{}) *ExpectedCreateDoc {
+	e.options = opts
+	return e
+}
+
+// WillReturn sets the document ID and revision to return.
+func (e *ExpectedCreateDoc) WillReturn(docID, rev string) *ExpectedCreateDoc {
+	e.newID, e.rev = docID, rev
+	return e
+}
+
+// WillReturnError sets the error to return.
+func (e *ExpectedCreateDoc) WillReturnError(err error) *ExpectedCreateDoc {
+	e.err = err
+	return e
+}
+
+// WillDelay delays the return of the call.
+func (e *ExpectedCreateDoc) WillDelay(d time.Duration) *ExpectedCreateDoc {
+	e.delay = d
+	return e
+}
+
+// ExpectedDelete is an expected call to DB.Delete.
+type ExpectedDelete struct {
+	dbExpectation
+	rev, newRev string
+}
+
+// ExpectDelete expects a call to Delete, on the named database. An empty
+// name matches any database.
+func (c *Client) ExpectDelete(dbName string) *ExpectedDelete {
+	e := &ExpectedDelete{dbExpectation: dbExpectation{db: dbName}}
+	c.expect(e)
+	return e
+}
+
+func (e *ExpectedDelete) method() string { return "Delete" }
+func (e *ExpectedDelete) String() string { return e.describe("Delete") }
+
+// WithDocID sets the document ID the call must delete.
+func (e *ExpectedDelete) WithDocID(docID string) *ExpectedDelete {
+	e.docID = docID
+	return e
+}
+
+// WithRev sets the revision the call must delete.
+func (e *ExpectedDelete) WithRev(rev string) *ExpectedDelete {
+	e.rev = rev
+	return e
+}
+
+// WithOptions sets the options the call must pass.
+func (e *ExpectedDelete) WithOptions(opts map[string]interface{}) *ExpectedDelete {
+	e.options = opts
+	return e
+}
+
+// WillReturn sets the revision of the deletion to return.
+func (e *ExpectedDelete) WillReturn(rev string) *ExpectedDelete {
+	e.newRev = rev
+	return e
+}
+
+// WillReturnError sets the error to return.
+func (e *ExpectedDelete) WillReturnError(err error) *ExpectedDelete {
+	e.err = err
+	return e
+}
+
+// WillDelay delays the return of the call.
+func (e *ExpectedDelete) WillDelay(d time.Duration) *ExpectedDelete {
+	e.delay = d
+	return e
+}
+
+// ExpectedAllDocs is an expected call to DB.AllDocs.
+type ExpectedAllDocs struct {
+	dbExpectation
+	rows *Rows
+}
+
+// ExpectAllDocs expects a call to AllDocs, on the named database. An empty
+// name matches any database.
+func (c *Client) ExpectAllDocs(dbName string) *ExpectedAllDocs {
+	e := &ExpectedAllDocs{dbExpectation: dbExpectation{db: dbName}}
+	c.expect(e)
+	return e
+}
+
+func (e *ExpectedAllDocs) method() string { return "AllDocs" }
+func (e *ExpectedAllDocs) String() string { return e.describe("AllDocs") }
+
+// WithOptions sets the options the call must pass.
+func (e *ExpectedAllDocs) WithOptions(opts map[string]interface{}) *ExpectedAllDocs {
+	e.options = opts
+	return e
+}
+
+// WillReturn sets the rows to return.
+func (e *ExpectedAllDocs) WillReturn(rows *Rows) *ExpectedAllDocs {
+	e.rows = rows
+	return e
+}
+
+// WillReturnError sets the error to return.
+func (e *ExpectedAllDocs) WillReturnError(err error) *ExpectedAllDocs {
+	e.err = err
+	return e
+}
+
+// WillDelay delays the return of the call.
+func (e *ExpectedAllDocs) WillDelay(d time.Duration) *ExpectedAllDocs {
+	e.delay = d
+	return e
+}
+
+// ExpectedQuery is an expected call to DB.Query.
+type ExpectedQuery struct {
+	dbExpectation
+	ddoc, view string
+	rows       *Rows
+}
+
+// ExpectQuery expects a call to Query, on the named database. An empty name
+// matches any database.
+func (c *Client) ExpectQuery(dbName string) *ExpectedQuery {
+	e := &ExpectedQuery{dbExpectation: dbExpectation{db: dbName}}
+	c.expect(e)
+	return e
+}
+
+func (e *ExpectedQuery) method() string { return "Query" }
+
+func (e *ExpectedQuery) String() string {
+	return fmt.Sprintf("Query(db=%q, ddoc=%q, view=%q)", e.db, e.ddoc, e.view)
+}
+
+// WithView sets the design document and view the call must query. Either
+// may be empty, to match any.
+func (e *ExpectedQuery) WithView(ddoc, view string) *ExpectedQuery {
+	e.ddoc, e.view = ddoc, view
+	return e
+}
+
+// WithOptions sets the options the call must pass.
+func (e *ExpectedQuery) WithOptions(opts map[string]interface{}) *ExpectedQuery {
+	e.options = opts
+	return e
+}
+
+// WillReturn sets the rows to return.
+func (e *ExpectedQuery) WillReturn(rows *Rows) *ExpectedQuery {
+	e.rows = rows
+	return e
+}
+
+// WillReturnError sets the error to return.
+func (e *ExpectedQuery) WillReturnError(err error) *ExpectedQuery {
+	e.err = err
+	return e
+}
+
+// WillDelay delays the return of the call.
+func (e *ExpectedQuery) WillDelay(d time.Duration) *ExpectedQuery {
+	e.delay = d
+	return e
+}
+
+// ExpectedFind is an expected call to DB.Find.
+type ExpectedFind struct {
+	dbExpectation
+	query interface{}
+	rows  *Rows
+}
+
+// ExpectFind expects a call to Find, on the named database. An empty name
+// matches any database.
+func (c *Client) ExpectFind(dbName string) *ExpectedFind {
+	e := &ExpectedFind{dbExpectation: dbExpectation{db: dbName}}
+	c.expect(e)
+	return e
+}
+
+func (e *ExpectedFind) method() string { return "Find" }
+func (e *ExpectedFind) String() string { return e.describe("Find") }
+
+// WithQuery sets the query the call must pass. Queries are compared by their
+// JSON representations.
+func (e *ExpectedFind) WithQuery(query interface{}) *ExpectedFind {
+	e.query = query
+	return e
+}
+
+// WillReturn sets the rows to return.
+func (e *ExpectedFind) WillReturn(rows *Rows) *ExpectedFind {
+	e.rows = rows
+	return e
+}
+
+// WillReturnError sets the error to return.
+func (e *ExpectedFind) WillReturnError(err error) *ExpectedFind {
+	e.err = err
+	return e
+}
+
+// WillDelay delays the return of the call.
+func (e *ExpectedFind) WillDelay(d time.Duration) *ExpectedFind {
+	e.delay = d
+	return e
+}
+
+// ExpectedChanges is an expected call to DB.Changes.
+type ExpectedChanges struct {
+	dbExpectation
+	changes *Changes
+}
+
+// ExpectChanges expects a call to Changes, on the named database. An empty
+// name matches any database.
+func (c *Client) ExpectChanges(dbName string) *ExpectedChanges {
+	e := &ExpectedChanges{dbExpectation: dbExpectation{db: dbName}}
+	c.expect(e)
+	return e
+}
+
+func (e *ExpectedChanges) method() string { return "Changes" }
+func (e *ExpectedChanges) String() string { return e.describe("Changes") }
+
+// WithOptions sets the options the call must pass.
+func (e *ExpectedChanges) WithOptions(opts map[string]interface{}) *ExpectedChanges {
+	e.options = opts
+	return e
+}
+
+// WillReturn sets the changes to return.
+func (e *ExpectedChanges) WillReturn(changes *Changes) *ExpectedChanges {
+	e.changes = changes
+	return e
+}
+
+// WillReturnError sets the error to return.
+func (e *ExpectedChanges) WillReturnError(err error) *ExpectedChanges {
+	e.err = err
+	return e
+}
+
+// WillDelay delays the return of the call.
+func (e *ExpectedChanges) WillDelay(d time.Duration) *ExpectedChanges {
+	e.delay = d
+	return e
+}
+
+// ExpectedStats is an expected call to DB.Stats.
+type ExpectedStats struct {
+	dbExpectation
+	stats *driver.DBStats
+}
+
+// ExpectStats expects a call to Stats, on the named database. An empty name
+// matches any database.
+func (c *Client) ExpectStats(dbName string) *ExpectedStats {
+	e := &ExpectedStats{dbExpectation: dbExpectation{db: dbName}, stats: &driver.DBStats{}}
+	c.expect(e)
+	return e
+}
+
+func (e *ExpectedStats) method() string { return "Stats" }
+func (e *ExpectedStats) String() string { return e.describe("Stats") }
+
+// WillReturn sets the statistics to return.
+func (e *ExpectedStats) WillReturn(stats *driver.DBStats) *ExpectedStats {
+	e.stats = stats
+	return e
+}
+
+// WillReturnError sets the error to return.
+func (e *ExpectedStats) WillReturnError(err error) *ExpectedStats {
+	e.err = err
+	return e
+}
+
+// WillDelay delays the return of the call.
+func (e *ExpectedStats) WillDelay(d time.Duration) *ExpectedStats {
+	e.delay = d
+	return e
+}
+
+// matchJSON reports whether actual matches expected, when both are converted
+// to JSON. A nil expected value matches anything.
+func matchJSON(name string, expected, actual interface{}) error {
+	if expected == nil {
+		return nil
+	}
+	e, err := normalizeJSON(expected)
+	if err != nil {
+		return err
+	}
+	a, err := normalizeJSON(actual)
+	if err != nil {
+		return err
+	}
+	if !reflect.DeepEqual(e, a) {
+		return fmt.Errorf("%s %v does not match %v", name, a, e)
+	}
+	return nil
+}
+
+func normalizeJSON(i interface{}) (interface{}, error) {
+	var data []byte
+	switch t := i.(type) {
+	case string:
+		data = []byte(t)
+	case []byte:
+		data = t
+	case json.RawMessage:
+		data = t
+	default:
+		var err error
+		if data, err = json.Marshal(i); err != nil {
+			return nil, err
+		}
+	}
+	var result interface{}
+	err := json.Unmarshal(data, &result)
+	return result, err
+}
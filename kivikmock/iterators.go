@@ -0,0 +1,203 @@
+package kivikmock
+
+import (
+	"encoding/json"
+	"io"
+
+	"github.com/go-kivik/kivik/driver"
+)
+
+// Rows is a canned result set, to be returned by an expected AllDocs, Query
+// or Find call.
+type Rows struct {
+	rows      []*driver.Row
+	offset    int64
+	totalRows int64
+	updateSeq string
+	warning   string
+	bookmark  string
+	err       error
+}
+
+// NewRows returns an empty result set.
+func NewRows() *Rows {
+	return &Rows{}
+}
+
+// AddRow appends a row.
+func (r *Rows) AddRow(row *driver.Row) *Rows {
+	r.rows = append(r.rows, row)
+	return r
+}
+
+// AddDoc appends a row for document docID, with doc, marshaled to JSON, as
+// the row's document. The row's key is docID, and its value the document's
+// revision, as for AllDocs with include_docs.
+func (r *Rows) AddDoc(docID string, doc interface{}) *Rows {
+	row := &driver.Row{ID: docID}
+	row.Key, _ = json.Marshal(docID)
+	var err error
+	if row.Doc, err = json.Marshal(doc); err != nil {
+		row.Error = err
+		return r.AddRow(row)
+	}
+	var meta struct {
+		Rev string `json:"_rev"`
+	}
+	_ = json.Unmarshal(row.Doc, &meta)
+	row.Value, _ = json.Marshal(map[string]string{"rev": meta.Rev})
+	return r.AddRow(row)
+}
+
+// Offset sets the offset reported by the result set.
+func (r *Rows) Offset(offset int64) *Rows {
+	r.offset = offset
+	return r
+}
+
+// TotalRows sets the total rows reported by the result set.
+func (r *Rows) TotalRows(totalRows int64) *Rows {
+	r.totalRows = totalRows
+	return r
+}
+
+// UpdateSeq sets the update sequence reported by the result set.
+func (r *Rows) UpdateSeq(seq string) *Rows {
+	r.updateSeq = seq
+	return r
+}
+
+// Warning sets the warning reported by the result set.
+func (r *Rows) Warning(warning string) *Rows {
+	r.warning = warning
+	return r
+}
+
+// Bookmark sets the bookmark reported by the result set.
+func (r *Rows) Bookmark(bookmark string) *Rows {
+	r.bookmark = bookmark
+	return r
+}
+
+// FinalError sets an error to be returned after the last row, in place of
+// the normal end of the result set.
+func (r *Rows) FinalError(err error) *Rows {
+	r.err = err
+	return r
+}
+
+// iter returns a driver iterator over a copy of the rows.
+func (r *Rows) iter() *rowsIter {
+	if r == nil {
+		r = NewRows()
+	}
+	it := &rowsIter{Rows: *r}
+	it.rows = append([]*driver.Row(nil), r.rows...)
+	return it
+}
+
+type rowsIter struct {
+	Rows
+}
+
+var (
+	_ driver.Rows       = &rowsIter{}
+	_ driver.RowsWarner = &rowsIter{}
+	_ driver.Bookmarker = &rowsIter{}
+)
+
+func (r *rowsIter) Next(row *driver.Row) error {
+	if len(r.rows) == 0 {
+		if r.err != nil {
+			return r.err
+		}
+		return io.EOF
+	}
+	*row, r.rows = *r.rows[0], r.rows[1:]
+	return nil
+}
+
+func (r *rowsIter) Close() error {
+	r.rows = nil
+	return nil
+}
+
+func (r *rowsIter) Offset() int64     { return r.offset }
+func (r *rowsIter) TotalRows() int64  { return r.totalRows }
+func (r *rowsIter) UpdateSeq() string { return r.updateSeq }
+func (r *rowsIter) Warning() string   { return r.warning }
+func (r *rowsIter) Bookmark() string  { return r.bookmark }
+
+// Changes is a canned changes feed, to be returned by an expected Changes
+// call.
+type Changes struct {
+	changes []*driver.Change
+	lastSeq string
+	pending int64
+	err     error
+}
+
+// NewChanges returns an empty changes feed.
+func NewChanges() *Changes {
+	return &Changes{}
+}
+
+// AddChange appends a change.
+func (c *Changes) AddChange(change *driver.Change) *Changes {
+	c.changes = append(c.changes, change)
+	return c
+}
+
+// LastSeq sets the last sequence reported by the feed.
+func (c *Changes) LastSeq(seq string) *Changes {
+	c.lastSeq = seq
+	return c
+}
+
+// Pending sets the pending count reported by the feed.
+func (c *Changes) Pending(pending int64) *Changes {
+	c.pending = pending
+	return c
+}
+
+// FinalError sets an error to be returned after the last change, in place of
+// the normal end of the feed.
+func (c *Changes) FinalError(err error) *Changes {
+	c.err = err
+	return c
+}
+
+// iter returns a driver iterator over a copy of the changes.
+func (c *Changes) iter() *changesIter {
+	if c == nil {
+		c = NewChanges()
+	}
+	it := &changesIter{Changes: *c}
+	it.changes = append([]*driver.Change(nil), c.changes...)
+	return it
+}
+
+type changesIter struct {
+	Changes
+}
+
+var _ driver.Changes = &changesIter{}
+
+func (c *changesIter) Next(change *driver.Change) error {
+	if len(c.changes) == 0 {
+		if c.err != nil {
+			return c.err
+		}
+		return io.EOF
+	}
+	*change, c.changes = *c.changes[0], c.changes[1:]
+	return nil
+}
+
+func (c *changesIter) Close() error {
+	c.changes = nil
+	return nil
+}
+
+func (c *changesIter) LastSeq() string { return c.lastSeq }
+func (c *changesIter) Pending() int64  { return c.pending }
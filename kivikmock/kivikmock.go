@@ -0,0 +1,177 @@
+// Package kivikmock provides a mock kivik driver, for unit testing code which
+// uses kivik, without a running server. It is modelled on sqlmock.
+//
+// Tests declare the calls they expect, and the results to return, then run
+// the code under test, and finally check that every expectation was met:
+//
+//	client, mock, err := kivikmock.New()
+//	if err != nil {
+//		t.Fatal(err)
+//	}
+//	mock.ExpectGet("users").WithDocID("bob").WillReturn(map[string]interface{}{
+//		"_id":  "bob",
+//		"_rev": "1-xxx",
+//		"name": "Bob",
+//	})
+//	// ... exercise code which calls client.DB(ctx, "users").Get(ctx, "bob")
+//	if err := mock.ExpectationsWereMet(); err != nil {
+//		t.Error(err)
+//	}
+//
+// By default, calls must arrive in the order in which they were expected.
+// Any call which does not match the next expectation fails with an error.
+// Call MatchExpectationsInOrder(false) to match calls to expectations in any
+// order.
+//
+// Only the methods with a corresponding Expect function may be expected.
+// Calls to any other driver method fail, as unexpected calls.
+package kivikmock
+
+import (
+	"context"
+	"fmt"
+	"reflect"
+	"sync"
+	"time"
+
+	"github.com/go-kivik/kivik"
+	"github.com/go-kivik/kivik/driver"
+	"github.com/go-kivik/kivik/internal/registry"
+)
+
+// DriverName is the name under which the mock driver is registered.
+const DriverName = "kivikmock"
+
+var clients = registry.New(DriverName, func(mock interface{}) (driver.Client, error) {
+	return &client{mock: mock.(*Client)}, nil
+})
+
+func init() {
+	kivik.Register(DriverName, clients.Driver())
+}
+
+// New returns a kivik client, backed by the mock driver, and the Client
+// with which to set expectations of it.
+func New() (*kivik.Client, *Client, error) {
+	mock := &Client{ordered: true}
+	client, err := clients.NewClient(context.Background(), mock)
+	if err != nil {
+		return nil, nil, err
+	}
+	return client, mock, nil
+}
+
+// Client records the expected calls to a mock client, and the results to
+// return for each.
+type Client struct {
+	mu       sync.Mutex
+	ordered  bool
+	expected []expectation
+}
+
+// MatchExpectationsInOrder sets whether calls must arrive in the order in
+// which they were expected. The default is true.
+func (c *Client) MatchExpectationsInOrder(ordered bool) {
+	c.mu.Lock()
+	c.ordered = ordered
+	c.mu.Unlock()
+}
+
+// ExpectationsWereMet returns an error if any expected call has not been
+// made.
+func (c *Client) ExpectationsWereMet() error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	for _, e := range c.expected {
+		if !e.fulfilled() {
+			return fmt.Errorf("kivikmock: there is a remaining expectation which was not matched: %s", e)
+		}
+	}
+	return nil
+}
+
+func (c *Client) expect(e expectation) {
+	c.mu.Lock()
+	c.expected = append(c.expected, e)
+	c.mu.Unlock()
+}
+
+// next returns the expectation which matches a call to method, marking it
+// fulfilled. match reports why an expectation of method does not match the
+// call's arguments.
+func (c *Client) next(method string, match func(expectation) error) (expectation, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	for _, e := range c.expected {
+		if e.fulfilled() {
+			continue
+		}
+		if e.method() != method {
+			if c.ordered {
+				return nil, fmt.Errorf("kivikmock: call to %s was not expected, next expectation is: %s", method, e)
+			}
+			continue
+		}
+		if err := match(e); err != nil {
+			if c.ordered {
+				return nil, fmt.Errorf("kivikmock: call to %s does not match expectation %s: %s", method, e, err)
+			}
+			continue
+		}
+		e.fulfill()
+		return e, nil
+	}
+	return nil, fmt.Errorf("kivikmock: call to %s was not expected", method)
+}
+
+type expectation interface {
+	fmt.Stringer
+	method() string
+	fulfilled() bool
+	fulfill()
+}
+
+// expectationBase holds the state common to all expectations.
+type expectationBase struct {
+	triggered bool
+	err       error
+	delay     time.Duration
+	options   map[string]interface{}
+}
+
+func (e *expectationBase) fulfilled() bool { return e.triggered }
+func (e *expectationBase) fulfill()        { e.triggered = true }
+
+// result waits for the expected delay, then returns the expected error.
+func (e *expectationBase) result(ctx context.Context) error {
+	if e.delay > 0 {
+		timer := time.NewTimer(e.delay)
+		defer timer.Stop()
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-timer.C:
+		}
+	}
+	return e.err
+}
+
+func (e *expectationBase) matchOptions(opts map[string]interface{}) error {
+	if e.options == nil {
+		return nil
+	}
+	if len(e.options) == 0 && len(opts) == 0 {
+		return nil
+	}
+	if !reflect.DeepEqual(e.options, opts) {
+		return fmt.Errorf("options %v do not match %v", opts, e.options)
+	}
+	return nil
+}
+
+func matchString(name, expected, actual string) error {
+	if expected != "" && expected != actual {
+		return fmt.Errorf("%s %q does not match %q", name, actual, expected)
+	}
+	return nil
+}
@@ -0,0 +1,252 @@
+package kivikmock
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/flimzy/diff"
+	"github.com/go-kivik/kivik"
+	"github.com/go-kivik/kivik/driver"
+)
+
+func newMock(t *testing.T) (*kivik.Client, *Client) {
+	client, mock, err := New()
+	if err != nil {
+		t.Fatal(err)
+	}
+	return client, mock
+}
+
+func TestGet(t *testing.T) {
+	client, mock := newMock(t)
+	ctx := context.Background()
+	mock.ExpectGet("users").WithDocID("bob").WillReturn(map[string]interface{}{
+		"_id":  "bob",
+		"_rev": "1-xxx",
+		"name": "Bob",
+	})
+	db, err := client.DB(ctx, "users")
+	if err != nil {
+		t.Fatal(err)
+	}
+	var doc struct {
+		ID   string `json:"_id"`
+		Name string `json:"name"`
+	}
+	row := db.Get(ctx, "bob")
+	if err := row.ScanDoc(&doc); err != nil {
+		t.Fatal(err)
+	}
+	if doc.Name != "Bob" || row.Rev != "1-xxx" {
+		t.Errorf("Unexpected result: %+v, rev %s", doc, row.Rev)
+	}
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Error(err)
+	}
+}
+
+func TestUnexpected(t *testing.T) {
+	tests := []struct {
+		name    string
+		setup   func(*Client)
+		call    func(*kivik.DB) error
+		err     string
+		metErr  string
+		ordered bool
+	}{
+		{
+			name: "no expectations",
+			call: func(db *kivik.DB) error {
+				return db.Get(context.Background(), "bob").Err
+			},
+			err: "kivikmock: call to Get was not expected",
+		},
+		{
+			name: "wrong method",
+			setup: func(c *Client) {
+				c.ExpectPut("users")
+			},
+			call: func(db *kivik.DB) error {
+				return db.Get(context.Background(), "bob").Err
+			},
+			err:    `kivikmock: call to Get was not expected, next expectation is: Put(db="users")`,
+			metErr: `kivikmock: there is a remaining expectation which was not matched: Put(db="users")`,
+		},
+		{
+			name: "wrong document",
+			setup: func(c *Client) {
+				c.ExpectGet("users").WithDocID("alice")
+			},
+			call: func(db *kivik.DB) error {
+				return db.Get(context.Background(), "bob").Err
+			},
+			err:    `kivikmock: call to Get does not match expectation Get(db="users", docID="alice"): document ID "bob" does not match "alice"`,
+			metErr: `kivikmock: there is a remaining expectation which was not matched: Get(db="users", docID="alice")`,
+		},
+		{
+			name: "wrong database",
+			setup: func(c *Client) {
+				c.ExpectGet("other")
+			},
+			call: func(db *kivik.DB) error {
+				return db.Get(context.Background(), "bob").Err
+			},
+			err:    `kivikmock: call to Get does not match expectation Get(db="other"): database "users" does not match "other"`,
+			metErr: `kivikmock: there is a remaining expectation which was not matched: Get(db="other")`,
+		},
+		{
+			name: "wrong document body",
+			setup: func(c *Client) {
+				c.ExpectPut("users").WithDoc(map[string]string{"name": "Alice"})
+			},
+			call: func(db *kivik.DB) error {
+				_, err := db.Put(context.Background(), "bob", map[string]string{"name": "Bob"})
+				return err
+			},
+			err:    `kivikmock: call to Put does not match expectation Put(db="users"): document map[name:Bob] does not match map[name:Alice]`,
+			metErr: `kivikmock: there is a remaining expectation which was not matched: Put(db="users")`,
+		},
+		{
+			name: "unsupported method",
+			call: func(db *kivik.DB) error {
+				return db.Compact(context.Background())
+			},
+			err: "kivikmock: call to Compact was not expected; it is not supported by kivikmock",
+		},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			client, mock := newMock(t)
+			if test.setup != nil {
+				test.setup(mock)
+			}
+			db, err := client.DB(context.Background(), "users")
+			if err != nil {
+				t.Fatal(err)
+			}
+			err = test.call(db)
+			if err == nil || err.Error() != test.err {
+				t.Errorf("Unexpected error: %v", err)
+			}
+			var metErr string
+			if err := mock.ExpectationsWereMet(); err != nil {
+				metErr = err.Error()
+			}
+			if metErr != test.metErr {
+				t.Errorf("Unexpected ExpectationsWereMet error: %s", metErr)
+			}
+		})
+	}
+}
+
+func TestUnordered(t *testing.T) {
+	client, mock := newMock(t)
+	ctx := context.Background()
+	mock.MatchExpectationsInOrder(false)
+	mock.ExpectDelete("users").WithDocID("bob").WithRev("1-xxx").WillReturn("2-yyy")
+	mock.ExpectCreateDB("users")
+	if _, err := client.CreateDB(ctx, "users"); err != nil {
+		t.Fatal(err)
+	}
+	db, err := client.DB(ctx, "users")
+	if err != nil {
+		t.Fatal(err)
+	}
+	rev, err := db.Delete(ctx, "bob", "1-xxx")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if rev != "2-yyy" {
+		t.Errorf("Unexpected rev: %s", rev)
+	}
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Error(err)
+	}
+}
+
+func TestWillReturnError(t *testing.T) {
+	client, mock := newMock(t)
+	ctx := context.Background()
+	expected := errors.New("boom")
+	mock.ExpectAllDBs().WillReturnError(expected)
+	if _, err := client.AllDBs(ctx); err == nil || !strings.Contains(err.Error(), "boom") {
+		t.Errorf("Unexpected error: %v", err)
+	}
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Error(err)
+	}
+}
+
+func TestWillDelay(t *testing.T) {
+	client, mock := newMock(t)
+	mock.ExpectDBExists("users").WillReturn(true).WillDelay(time.Second)
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+	defer cancel()
+	if _, err := client.DBExists(ctx, "users"); err == nil || !strings.Contains(err.Error(), context.DeadlineExceeded.Error()) {
+		t.Errorf("Unexpected error: %v", err)
+	}
+}
+
+func TestRows(t *testing.T) {
+	client, mock := newMock(t)
+	ctx := context.Background()
+	mock.ExpectAllDocs("users").
+		WithOptions(map[string]interface{}{"include_docs": true}).
+		WillReturn(NewRows().
+			AddDoc("alice", map[string]string{"_id": "alice", "_rev": "1-a"}).
+			AddDoc("bob", map[string]string{"_id": "bob", "_rev": "1-b"}).
+			TotalRows(2))
+	db, err := client.DB(ctx, "users")
+	if err != nil {
+		t.Fatal(err)
+	}
+	rows, err := db.AllDocs(ctx, kivik.Options{"include_docs": true})
+	if err != nil {
+		t.Fatal(err)
+	}
+	var ids []string
+	for rows.Next() {
+		ids = append(ids, rows.ID())
+	}
+	if err := rows.Err(); err != nil {
+		t.Fatal(err)
+	}
+	if d := diff.Interface([]string{"alice", "bob"}, ids); d != nil {
+		t.Error(d)
+	}
+	if rows.TotalRows() != 2 {
+		t.Errorf("Unexpected total rows: %d", rows.TotalRows())
+	}
+}
+
+func TestChanges(t *testing.T) {
+	client, mock := newMock(t)
+	ctx := context.Background()
+	mock.ExpectChanges("users").WillReturn(NewChanges().
+		AddChange(&driver.Change{ID: "alice", Seq: "1", Changes: driver.ChangedRevs{"1-a"}}).
+		LastSeq("1"))
+	db, err := client.DB(ctx, "users")
+	if err != nil {
+		t.Fatal(err)
+	}
+	changes, err := db.Changes(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var ids []string
+	for changes.Next() {
+		ids = append(ids, changes.ID())
+	}
+	if err := changes.Err(); err != nil {
+		t.Fatal(err)
+	}
+	if d := diff.Interface([]string{"alice"}, ids); d != nil {
+		t.Error(d)
+	}
+	if changes.LastSeq() != "1" {
+		t.Errorf("Unexpected last seq: %s", changes.LastSeq())
+	}
+}
@@ -0,0 +1,38 @@
+package kivik
+
+// Logger is the interface through which Kivik reports diagnostics, such as
+// retried requests and reconnections of the changes feed. Each method takes
+// a message, and alternating keys and values, in the style of log/slog.
+//
+// Adapters for log/slog and logrus are provided by the slogkivik and
+// logruskivik packages.
+type Logger interface {
+	Debug(msg string, keyvals ...interface{})
+	Info(msg string, keyvals ...interface{})
+	Warn(msg string, keyvals ...interface{})
+	Error(msg string, keyvals ...interface{})
+}
+
+type nopLogger struct{}
+
+var _ Logger = nopLogger{}
+
+func (nopLogger) Debug(string, ...interface{}) {}
+func (nopLogger) Info(string, ...interface{})  {}
+func (nopLogger) Warn(string, ...interface{})  {}
+func (nopLogger) Error(string, ...interface{}) {}
+
+// SetLogger sets the logger used by c, and by any DB handles obtained from
+// it. By default, nothing is logged. SetLogger should be called before the
+// client is used.
+func (c *Client) SetLogger(logger Logger) {
+	c.logger = logger
+}
+
+// log returns the client's logger, which is never nil.
+func (c *Client) log() Logger {
+	if c == nil || c.logger == nil {
+		return nopLogger{}
+	}
+	return c.logger
+}
@@ -0,0 +1,60 @@
+package kivik
+
+import (
+	"context"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/flimzy/diff"
+	"github.com/go-kivik/kivik/driver"
+	"github.com/go-kivik/kivik/errors"
+	"github.com/go-kivik/kivik/mock"
+)
+
+type recordingLogger struct {
+	entries []string
+}
+
+var _ Logger = &recordingLogger{}
+
+func (l *recordingLogger) log(level, msg string, keyvals []interface{}) {
+	l.entries = append(l.entries, fmt.Sprintf("%s %s %v", level, msg, keyvals))
+}
+
+func (l *recordingLogger) Debug(msg string, keyvals ...interface{}) { l.log("DEBUG", msg, keyvals) }
+func (l *recordingLogger) Info(msg string, keyvals ...interface{})  { l.log("INFO", msg, keyvals) }
+func (l *recordingLogger) Warn(msg string, keyvals ...interface{})  { l.log("WARN", msg, keyvals) }
+func (l *recordingLogger) Error(msg string, keyvals ...interface{}) { l.log("ERROR", msg, keyvals) }
+
+func TestLoggerRetry(t *testing.T) {
+	logger := &recordingLogger{}
+	client := &Client{}
+	client.SetLogger(logger)
+	client.SetRetryPolicy(&RetryPolicy{MaxRetries: 1, MinBackoff: time.Millisecond})
+	db := &DB{
+		client: client,
+		name:   "db",
+		driverDB: &mock.DB{
+			DeleteFunc: func(_ context.Context, _, _ string, _ map[string]interface{}) (string, error) {
+				return "", errors.Status(StatusServiceUnavailable, "unavailable")
+			},
+			GetFunc: func(_ context.Context, _ string, _ map[string]interface{}) (*driver.Document, error) {
+				return nil, errors.Status(StatusServiceUnavailable, "unavailable")
+			},
+		},
+	}
+	_, _ = db.Delete(context.Background(), "foo", "1-xxx")
+	_ = db.Get(context.Background(), "foo")
+	expected := []string{
+		"WARN kivik: retrying operation [operation Get db db retry 1 delay 1ms error unavailable]",
+	}
+	if d := diff.Interface(expected, logger.entries); d != nil {
+		t.Error(d)
+	}
+}
+
+func TestNilClientLogger(t *testing.T) {
+	var c *Client
+	c.log().Warn("no panic")
+}
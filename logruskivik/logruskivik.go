@@ -0,0 +1,55 @@
+// Package logruskivik adapts a logrus logger for use as a kivik.Logger.
+package logruskivik
+
+import (
+	"fmt"
+
+	"github.com/sirupsen/logrus"
+
+	"github.com/go-kivik/kivik"
+)
+
+type logger struct {
+	logrus.FieldLogger
+}
+
+var _ kivik.Logger = &logger{}
+
+// New returns a kivik.Logger which logs to l, which may be a *logrus.Logger
+// or *logrus.Entry. If l is nil, logrus.StandardLogger() is used.
+func New(l logrus.FieldLogger) kivik.Logger {
+	if l == nil {
+		l = logrus.StandardLogger()
+	}
+	return &logger{l}
+}
+
+// fields converts alternating keys and values to logrus.Fields. A trailing
+// key without a value is logged under the key "!BADKEY", as log/slog does.
+func fields(keyvals []interface{}) logrus.Fields {
+	f := make(logrus.Fields, len(keyvals)/2)
+	for i := 0; i < len(keyvals); i += 2 {
+		if i+1 == len(keyvals) {
+			f["!BADKEY"] = keyvals[i]
+			break
+		}
+		f[fmt.Sprint(keyvals[i])] = keyvals[i+1]
+	}
+	return f
+}
+
+func (l *logger) Debug(msg string, keyvals ...interface{}) {
+	l.WithFields(fields(keyvals)).Debug(msg)
+}
+
+func (l *logger) Info(msg string, keyvals ...interface{}) {
+	l.WithFields(fields(keyvals)).Info(msg)
+}
+
+func (l *logger) Warn(msg string, keyvals ...interface{}) {
+	l.WithFields(fields(keyvals)).Warn(msg)
+}
+
+func (l *logger) Error(msg string, keyvals ...interface{}) {
+	l.WithFields(fields(keyvals)).Error(msg)
+}
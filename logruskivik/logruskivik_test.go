@@ -0,0 +1,29 @@
+package logruskivik
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/sirupsen/logrus"
+)
+
+func TestLogger(t *testing.T) {
+	buf := &bytes.Buffer{}
+	base := logrus.New()
+	base.Out = buf
+	base.Level = logrus.DebugLevel
+	base.Formatter = &logrus.TextFormatter{DisableTimestamp: true, DisableColors: true}
+	l := New(base)
+	l.Debug("debug", "a", 1)
+	l.Info("info")
+	l.Warn("warn", "db", "foo", "retry", 2)
+	l.Error("error", "odd")
+	expected := `level=debug msg=debug a=1
+level=info msg=info
+level=warning msg=warn db=foo retry=2
+level=error msg=error !BADKEY=odd
+`
+	if buf.String() != expected {
+		t.Errorf("Unexpected output:\n%s", buf.String())
+	}
+}
@@ -0,0 +1,116 @@
+package mango
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/flimzy/diff"
+	"github.com/flimzy/testy"
+)
+
+func TestSelectors(t *testing.T) {
+	tests := []struct {
+		name     string
+		sel      Selector
+		expected string
+	}{
+		{"eq", Eq("a", 1), `{"a":{"$eq":1}}`},
+		{"ne", Ne("a", "x"), `{"a":{"$ne":"x"}}`},
+		{"gt", Gt("a", 1), `{"a":{"$gt":1}}`},
+		{"gte", Gte("a", 1), `{"a":{"$gte":1}}`},
+		{"lt", Lt("a", 1), `{"a":{"$lt":1}}`},
+		{"lte", Lte("a.b", 1), `{"a.b":{"$lte":1}}`},
+		{"in", In("a", 1, "two"), `{"a":{"$in":[1,"two"]}}`},
+		{"in empty", In("a"), `{"a":{"$in":[]}}`},
+		{"nin", Nin("a", 1), `{"a":{"$nin":[1]}}`},
+		{"exists", Exists("a", false), `{"a":{"$exists":false}}`},
+		{"type", Type("a", TypeString), `{"a":{"$type":"string"}}`},
+		{"size", Size("a", 3), `{"a":{"$size":3}}`},
+		{"mod", Mod("a", 4, 1), `{"a":{"$mod":[4,1]}}`},
+		{"regex", Regex("a", "^x"), `{"a":{"$regex":"^x"}}`},
+		{"all", All("a", 1, 2), `{"a":{"$all":[1,2]}}`},
+		{"elemMatch value", ElemMatch("tags", Eq("", "red")), `{"tags":{"$elemMatch":{"$eq":"red"}}}`},
+		{"elemMatch fields", ElemMatch("items", And(Eq("x", 1), Eq("y", 2))), `{"items":{"$elemMatch":{"$and":[{"x":{"$eq":1}},{"y":{"$eq":2}}]}}}`},
+		{"allMatch", AllMatch("a", Gt("", 0)), `{"a":{"$allMatch":{"$gt":0}}}`},
+		{"and", And(Eq("a", 1), Eq("b", 2)), `{"$and":[{"a":{"$eq":1}},{"b":{"$eq":2}}]}`},
+		{"or empty", Or(), `{"$or":[]}`},
+		{"nor", Nor(Eq("a", 1)), `{"$nor":[{"a":{"$eq":1}}]}`},
+		{"not", Not(Eq("a", 1)), `{"$not":{"a":{"$eq":1}}}`},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			result, err := json.Marshal(test.sel)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if d := diff.JSON([]byte(test.expected), result); d != nil {
+				t.Error(d)
+			}
+		})
+	}
+}
+
+func TestQuery(t *testing.T) {
+	tests := []struct {
+		name     string
+		query    *Query
+		expected string
+		err      string
+	}{
+		{
+			name:     "empty",
+			query:    NewQuery(nil),
+			expected: `{"selector":{}}`,
+		},
+		{
+			name: "full",
+			query: NewQuery(Eq("type", "widget")).
+				Sort(Desc("price"), Desc("name")).
+				Fields("_id", "price").
+				Limit(10).
+				Skip(5).
+				Bookmark("xyz").
+				UseIndex("ddoc", "idx"),
+			expected: `{"selector":{"type":{"$eq":"widget"}},"sort":[{"price":"desc"},{"name":"desc"}],"fields":["_id","price"],"limit":10,"skip":5,"bookmark":"xyz","use_index":["ddoc","idx"]}`,
+		},
+		{
+			name:     "use index ddoc only",
+			query:    NewQuery(nil).UseIndex("ddoc"),
+			expected: `{"selector":{},"use_index":"ddoc"}`,
+		},
+		{
+			name:  "mixed sort",
+			query: NewQuery(nil).Sort(Asc("a"), Desc("b")),
+			err:   "json: error calling MarshalJSON for type *mango.Query: mango: all sort fields must use the same direction",
+		},
+		{
+			name:  "empty sort field",
+			query: NewQuery(nil).Sort(Asc("")),
+			err:   "json: error calling MarshalJSON for type *mango.Query: mango: sort field required",
+		},
+		{
+			name:  "negative limit",
+			query: NewQuery(nil).Limit(-1),
+			err:   "json: error calling MarshalJSON for type *mango.Query: mango: limit must not be negative",
+		},
+		{
+			name:  "negative skip",
+			query: NewQuery(nil).Skip(-1),
+			err:   "json: error calling MarshalJSON for type *mango.Query: mango: skip must not be negative",
+		},
+		{
+			name:  "too many index names",
+			query: NewQuery(nil).UseIndex("a", "b", "c"),
+			err:   "json: error calling MarshalJSON for type *mango.Query: mango: too many index names",
+		},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			result, err := json.Marshal(test.query)
+			testy.Error(t, test.err, err)
+			if d := diff.JSON([]byte(test.expected), result); d != nil {
+				t.Error(d)
+			}
+		})
+	}
+}
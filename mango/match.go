@@ -0,0 +1,644 @@
+package mango
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// Matcher evaluates a compiled Mango selector against documents. A Matcher is
+// safe for concurrent use.
+//
+// Matching follows CouchDB's semantics, with two exceptions: strings are
+// compared by code point, rather than with ICU collation, and $regex patterns
+// use Go's regexp syntax, rather than PCRE.
+//
+// A Matcher may be used to filter changes on the client:
+//
+//	m, err := mango.Compile(mango.Eq("type", "widget"))
+//	if err != nil {
+//		return err
+//	}
+//	filter := &kivik.ChangesFilter{
+//		Match: func(change kivik.ChangeEvent) bool {
+//			ok, _ := m.MatchJSON(change.Doc)
+//			return ok
+//		},
+//	}
+type Matcher struct {
+	root matcher
+}
+
+// matcher matches a value. exists is false if the value is absent, as for a
+// missing field.
+type matcher interface {
+	match(value interface{}, exists bool) bool
+}
+
+// Compile parses selector, which may be a Selector, any other value which
+// marshals to a JSON object, or a raw JSON object as a []byte,
+// json.RawMessage or string.
+func Compile(selector interface{}) (*Matcher, error) {
+	var data []byte
+	switch t := selector.(type) {
+	case []byte:
+		data = t
+	case json.RawMessage:
+		data = t
+	case string:
+		data = []byte(t)
+	default:
+		var err error
+		if data, err = json.Marshal(selector); err != nil {
+			return nil, err
+		}
+	}
+	var sel map[string]interface{}
+	if err := json.Unmarshal(data, &sel); err != nil {
+		return nil, fmt.Errorf("mango: invalid selector: %s", err)
+	}
+	if sel == nil {
+		return nil, errors.New("mango: selector must be an object")
+	}
+	root, err := parseSelector(sel)
+	if err != nil {
+		return nil, err
+	}
+	return &Matcher{root: root}, nil
+}
+
+// Match reports whether doc matches the selector. doc should be a decoded
+// JSON value, such as a map[string]interface{}. Other types are converted by
+// way of JSON, and never match if that fails.
+func (m *Matcher) Match(doc interface{}) bool {
+	if _, ok := doc.(map[string]interface{}); !ok {
+		data, err := json.Marshal(doc)
+		if err != nil {
+			return false
+		}
+		doc = nil
+		if err := json.Unmarshal(data, &doc); err != nil {
+			return false
+		}
+	}
+	return m.root.match(doc, true)
+}
+
+// MatchJSON reports whether the JSON document doc matches the selector.
+func (m *Matcher) MatchJSON(doc []byte) (bool, error) {
+	var value interface{}
+	if err := json.Unmarshal(doc, &value); err != nil {
+		return false, err
+	}
+	return m.root.match(value, true), nil
+}
+
+// parseSelector parses a selector object. Keys beginning with $ are
+// operators applied to the current value; others are fields of it.
+func parseSelector(sel map[string]interface{}) (matcher, error) {
+	keys := make([]string, 0, len(sel))
+	for key := range sel {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	and := make(andMatcher, 0, len(keys))
+	for _, key := range keys {
+		var m matcher
+		var err error
+		if strings.HasPrefix(key, "$") {
+			m, err = parseOperator(key, sel[key])
+		} else {
+			m, err = parseField(key, sel[key])
+		}
+		if err != nil {
+			return nil, err
+		}
+		and = append(and, m)
+	}
+	if len(and) == 1 {
+		return and[0], nil
+	}
+	return and, nil
+}
+
+// parseField parses the condition on a field. An object containing operators
+// or sub-fields is parsed as a selector; any other value is an implicit $eq.
+func parseField(field string, arg interface{}) (matcher, error) {
+	var cond matcher
+	if obj, ok := arg.(map[string]interface{}); ok && len(obj) > 0 {
+		var err error
+		if cond, err = parseSelector(obj); err != nil {
+			return nil, err
+		}
+	} else {
+		cond = eqMatcher{arg}
+	}
+	return fieldMatcher{path: splitPath(field), cond: cond}, nil
+}
+
+// splitPath splits a field name on unescaped periods.
+func splitPath(field string) []string {
+	var path []string
+	var buf bytes.Buffer
+	for i := 0; i < len(field); i++ {
+		switch {
+		case field[i] == '\\' && i+1 < len(field) && field[i+1] == '.':
+			buf.WriteByte('.')
+			i++
+		case field[i] == '.':
+			path = append(path, buf.String())
+			buf.Reset()
+		default:
+			buf.WriteByte(field[i])
+		}
+	}
+	return append(path, buf.String())
+}
+
+func parseSelectors(op string, arg interface{}) ([]matcher, error) {
+	list, ok := arg.([]interface{})
+	if !ok {
+		return nil, fmt.Errorf("mango: %s requires an array", op)
+	}
+	matchers := make([]matcher, len(list))
+	for i, item := range list {
+		sel, ok := item.(map[string]interface{})
+		if !ok {
+			return nil, fmt.Errorf("mango: %s requires an array of objects", op)
+		}
+		m, err := parseSelector(sel)
+		if err != nil {
+			return nil, err
+		}
+		matchers[i] = m
+	}
+	return matchers, nil
+}
+
+func parseSubSelector(op string, arg interface{}) (matcher, error) {
+	sel, ok := arg.(map[string]interface{})
+	if !ok {
+		return nil, fmt.Errorf("mango: %s requires an object", op)
+	}
+	return parseSelector(sel)
+}
+
+func parseOperator(op string, arg interface{}) (matcher, error) {
+	switch op {
+	case "$and":
+		m, err := parseSelectors(op, arg)
+		return andMatcher(m), err
+	case "$or":
+		m, err := parseSelectors(op, arg)
+		return orMatcher(m), err
+	case "$nor":
+		m, err := parseSelectors(op, arg)
+		return notMatcher{orMatcher(m)}, err
+	case "$not":
+		m, err := parseSubSelector(op, arg)
+		return notMatcher{m}, err
+	case "$eq":
+		return eqMatcher{arg}, nil
+	case "$ne":
+		return existsAnd(notMatcher{eqMatcher{arg}}), nil
+	case "$lt", "$lte", "$gt", "$gte":
+		return cmpMatcher{op: op, arg: arg}, nil
+	case "$exists":
+		exists, ok := arg.(bool)
+		if !ok {
+			return nil, errors.New("mango: $exists requires a boolean")
+		}
+		return existsMatcher(exists), nil
+	case "$type":
+		t, ok := arg.(string)
+		if !ok || typeRank(t) < 0 {
+			return nil, fmt.Errorf("mango: invalid $type: %v", arg)
+		}
+		return typeMatcher(t), nil
+	case "$in", "$nin", "$all":
+		list, ok := arg.([]interface{})
+		if !ok {
+			return nil, fmt.Errorf("mango: %s requires an array", op)
+		}
+		switch op {
+		case "$in":
+			return inMatcher(list), nil
+		case "$nin":
+			return existsAnd(notMatcher{inMatcher(list)}), nil
+		}
+		return allMatcher(list), nil
+	case "$size":
+		n, ok := integer(arg)
+		if !ok || n < 0 {
+			return nil, errors.New("mango: $size requires a non-negative integer")
+		}
+		return sizeMatcher(n), nil
+	case "$mod":
+		list, _ := arg.([]interface{})
+		if len(list) != 2 {
+			return nil, errors.New("mango: $mod requires [divisor, remainder]")
+		}
+		divisor, ok1 := integer(list[0])
+		remainder, ok2 := integer(list[1])
+		if !ok1 || !ok2 || divisor == 0 {
+			return nil, errors.New("mango: $mod requires a non-zero integer divisor and integer remainder")
+		}
+		return modMatcher{divisor, remainder}, nil
+	case "$regex":
+		pattern, ok := arg.(string)
+		if !ok {
+			return nil, errors.New("mango: $regex requires a string")
+		}
+		re, err := regexp.Compile(pattern)
+		if err != nil {
+			return nil, fmt.Errorf("mango: invalid $regex: %s", err)
+		}
+		return regexMatcher{re}, nil
+	case "$beginsWith":
+		prefix, ok := arg.(string)
+		if !ok {
+			return nil, errors.New("mango: $beginsWith requires a string")
+		}
+		return prefixMatcher(prefix), nil
+	case "$elemMatch", "$allMatch", "$keyMapMatch":
+		m, err := parseSubSelector(op, arg)
+		if err != nil {
+			return nil, err
+		}
+		switch op {
+		case "$elemMatch":
+			return elemMatcher{m}, nil
+		case "$allMatch":
+			return allElemMatcher{m}, nil
+		}
+		return keyMapMatcher{m}, nil
+	}
+	return nil, fmt.Errorf("mango: unknown operator %s", op)
+}
+
+type fieldMatcher struct {
+	path []string
+	cond matcher
+}
+
+func (m fieldMatcher) match(value interface{}, exists bool) bool {
+	for _, name := range m.path {
+		if !exists {
+			break
+		}
+		value, exists = lookup(value, name)
+	}
+	return m.cond.match(value, exists)
+}
+
+// lookup returns the named field of an object, or the indexed element of an
+// array.
+func lookup(value interface{}, name string) (interface{}, bool) {
+	switch t := value.(type) {
+	case map[string]interface{}:
+		v, ok := t[name]
+		return v, ok
+	case []interface{}:
+		i, err := strconv.Atoi(name)
+		if err != nil || i < 0 || i >= len(t) {
+			return nil, false
+		}
+		return t[i], true
+	}
+	return nil, false
+}
+
+type andMatcher []matcher
+
+func (m andMatcher) match(value interface{}, exists bool) bool {
+	for _, sub := range m {
+		if !sub.match(value, exists) {
+			return false
+		}
+	}
+	return true
+}
+
+type orMatcher []matcher
+
+func (m orMatcher) match(value interface{}, exists bool) bool {
+	for _, sub := range m {
+		if sub.match(value, exists) {
+			return true
+		}
+	}
+	return false
+}
+
+type notMatcher struct {
+	matcher
+}
+
+func (m notMatcher) match(value interface{}, exists bool) bool {
+	return !m.matcher.match(value, exists)
+}
+
+// existsAnd returns a matcher which only matches present values which also
+// match m.
+func existsAnd(m matcher) matcher {
+	return andMatcher{existsMatcher(true), m}
+}
+
+type existsMatcher bool
+
+func (m existsMatcher) match(_ interface{}, exists bool) bool {
+	return exists == bool(m)
+}
+
+type eqMatcher struct {
+	arg interface{}
+}
+
+func (m eqMatcher) match(value interface{}, exists bool) bool {
+	return exists && compare(value, m.arg) == 0
+}
+
+type cmpMatcher struct {
+	op  string
+	arg interface{}
+}
+
+func (m cmpMatcher) match(value interface{}, exists bool) bool {
+	if !exists {
+		return false
+	}
+	c := compare(value, m.arg)
+	switch m.op {
+	case "$lt":
+		return c < 0
+	case "$lte":
+		return c <= 0
+	case "$gt":
+		return c > 0
+	}
+	return c >= 0
+}
+
+type typeMatcher string
+
+func (m typeMatcher) match(value interface{}, exists bool) bool {
+	return exists && typeOf(value) == string(m)
+}
+
+// inMatcher matches a value equal to any element of the list, or an array
+// containing such a value.
+type inMatcher []interface{}
+
+func (m inMatcher) match(value interface{}, exists bool) bool {
+	if !exists {
+		return false
+	}
+	for _, arg := range m {
+		if compare(value, arg) == 0 {
+			return true
+		}
+	}
+	if array, ok := value.([]interface{}); ok {
+		for _, elem := range array {
+			for _, arg := range m {
+				if compare(elem, arg) == 0 {
+					return true
+				}
+			}
+		}
+	}
+	return false
+}
+
+// allMatcher matches an array containing every element of the list.
+type allMatcher []interface{}
+
+func (m allMatcher) match(value interface{}, _ bool) bool {
+	array, ok := value.([]interface{})
+	if !ok {
+		return false
+	}
+	for _, arg := range m {
+		if !inMatcher(array).match(arg, true) {
+			return false
+		}
+	}
+	return true
+}
+
+type sizeMatcher int64
+
+func (m sizeMatcher) match(value interface{}, _ bool) bool {
+	array, ok := value.([]interface{})
+	return ok && int64(len(array)) == int64(m)
+}
+
+type modMatcher struct {
+	divisor, remainder int64
+}
+
+func (m modMatcher) match(value interface{}, _ bool) bool {
+	n, ok := integer(value)
+	return ok && n%m.divisor == m.remainder
+}
+
+type regexMatcher struct {
+	re *regexp.Regexp
+}
+
+func (m regexMatcher) match(value interface{}, _ bool) bool {
+	s, ok := value.(string)
+	return ok && m.re.MatchString(s)
+}
+
+type prefixMatcher string
+
+func (m prefixMatcher) match(value interface{}, _ bool) bool {
+	s, ok := value.(string)
+	return ok && strings.HasPrefix(s, string(m))
+}
+
+type elemMatcher struct {
+	matcher
+}
+
+func (m elemMatcher) match(value interface{}, _ bool) bool {
+	array, _ := value.([]interface{})
+	for _, elem := range array {
+		if m.matcher.match(elem, true) {
+			return true
+		}
+	}
+	return false
+}
+
+type allElemMatcher struct {
+	matcher
+}
+
+func (m allElemMatcher) match(value interface{}, _ bool) bool {
+	array, _ := value.([]interface{})
+	for _, elem := range array {
+		if !m.matcher.match(elem, true) {
+			return false
+		}
+	}
+	return len(array) > 0
+}
+
+type keyMapMatcher struct {
+	matcher
+}
+
+func (m keyMapMatcher) match(value interface{}, _ bool) bool {
+	obj, _ := value.(map[string]interface{})
+	for key := range obj {
+		if m.matcher.match(key, true) {
+			return true
+		}
+	}
+	return false
+}
+
+// number returns value as a float64, if it is a number.
+func number(value interface{}) (float64, bool) {
+	switch t := value.(type) {
+	case float64:
+		return t, true
+	case int:
+		return float64(t), true
+	case int64:
+		return float64(t), true
+	case json.Number:
+		f, err := t.Float64()
+		return f, err == nil
+	}
+	return 0, false
+}
+
+// integer returns value as an int64, if it is an integral number.
+func integer(value interface{}) (int64, bool) {
+	f, ok := number(value)
+	if !ok || f != math.Trunc(f) {
+		return 0, false
+	}
+	return int64(f), true
+}
+
+func typeOf(value interface{}) string {
+	switch value.(type) {
+	case nil:
+		return TypeNull
+	case bool:
+		return TypeBoolean
+	case string:
+		return TypeString
+	case []interface{}:
+		return TypeArray
+	case map[string]interface{}:
+		return TypeObject
+	}
+	if _, ok := number(value); ok {
+		return TypeNumber
+	}
+	return ""
+}
+
+// typeRank returns the collation rank of a JSON type, or -1 if the type is
+// unknown.
+func typeRank(t string) int {
+	switch t {
+	case TypeNull:
+		return 0
+	case TypeBoolean:
+		return 1
+	case TypeNumber:
+		return 2
+	case TypeString:
+		return 3
+	case TypeArray:
+		return 4
+	case TypeObject:
+		return 5
+	}
+	return -1
+}
+
+// Compare compares two decoded JSON values, in the collation order used by
+// Matcher: null, booleans, numbers, strings, arrays, then objects. The result
+// is negative if a sorts before b, positive if after, and zero if they are
+// equal. It may be used to sort query results.
+func Compare(a, b interface{}) int {
+	return compare(a, b)
+}
+
+// compare compares two JSON values, according to CouchDB's collation order:
+// null, booleans, numbers, strings, arrays, then objects.
+func compare(a, b interface{}) int {
+	ta, tb := typeOf(a), typeOf(b)
+	if ra, rb := typeRank(ta), typeRank(tb); ra != rb {
+		return ra - rb
+	}
+	switch ta {
+	case TypeBoolean:
+		ba, bb := a.(bool), b.(bool)
+		switch {
+		case ba == bb:
+			return 0
+		case bb:
+			return -1
+		}
+		return 1
+	case TypeNumber:
+		na, _ := number(a)
+		nb, _ := number(b)
+		switch {
+		case na < nb:
+			return -1
+		case na > nb:
+			return 1
+		}
+		return 0
+	case TypeString:
+		return strings.Compare(a.(string), b.(string))
+	case TypeArray:
+		aa, ab := a.([]interface{}), b.([]interface{})
+		for i := 0; i < len(aa) && i < len(ab); i++ {
+			if c := compare(aa[i], ab[i]); c != 0 {
+				return c
+			}
+		}
+		return len(aa) - len(ab)
+	case TypeObject:
+		return compareObjects(a.(map[string]interface{}), b.(map[string]interface{}))
+	}
+	return 0
+}
+
+func compareObjects(a, b map[string]interface{}) int {
+	ka, kb := sortedKeys(a), sortedKeys(b)
+	for i := 0; i < len(ka) && i < len(kb); i++ {
+		if c := strings.Compare(ka[i], kb[i]); c != 0 {
+			return c
+		}
+		if c := compare(a[ka[i]], b[kb[i]]); c != 0 {
+			return c
+		}
+	}
+	return len(ka) - len(kb)
+}
+
+func sortedKeys(m map[string]interface{}) []string {
+	keys := make([]string, 0, len(m))
+	for k := range m {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	return keys
+}
@@ -0,0 +1,150 @@
+package mango
+
+import (
+	"testing"
+
+	"github.com/flimzy/testy"
+)
+
+func TestCompile(t *testing.T) {
+	tests := []struct {
+		name     string
+		selector interface{}
+		err      string
+	}{
+		{"selector", Eq("a", 1), ""},
+		{"raw", `{"a":1}`, ""},
+		{"bytes", []byte(`{"a":{"$gt":1}}`), ""},
+		{"invalid JSON", `{`, "mango: invalid selector: unexpected end of JSON input"},
+		{"null", `null`, "mango: selector must be an object"},
+		{"not object", `[]`, "mango: invalid selector: json: cannot unmarshal array into Go value of type map[string]interface {}"},
+		{"unknown operator", `{"a":{"$foo":1}}`, "mango: unknown operator $foo"},
+		{"and not array", `{"$and":{}}`, "mango: $and requires an array"},
+		{"or of non-objects", `{"$or":[1]}`, "mango: $or requires an array of objects"},
+		{"not not object", `{"$not":1}`, "mango: $not requires an object"},
+		{"exists not bool", `{"a":{"$exists":1}}`, "mango: $exists requires a boolean"},
+		{"invalid type", `{"a":{"$type":"int"}}`, "mango: invalid $type: int"},
+		{"in not array", `{"a":{"$in":1}}`, "mango: $in requires an array"},
+		{"negative size", `{"a":{"$size":-1}}`, "mango: $size requires a non-negative integer"},
+		{"mod zero", `{"a":{"$mod":[0,1]}}`, "mango: $mod requires a non-zero integer divisor and integer remainder"},
+		{"mod short", `{"a":{"$mod":[2]}}`, "mango: $mod requires [divisor, remainder]"},
+		{"invalid regex", `{"a":{"$regex":"("}}`, "mango: invalid $regex: error parsing regexp: missing closing ): `(`"},
+		{"elemMatch not object", `{"a":{"$elemMatch":1}}`, "mango: $elemMatch requires an object"},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			_, err := Compile(test.selector)
+			testy.Error(t, test.err, err)
+		})
+	}
+}
+
+func TestMatch(t *testing.T) {
+	doc := `{
+		"_id": "x",
+		"name": "Bob",
+		"age": 42,
+		"active": true,
+		"nothing": null,
+		"address": {"city": "Paris", "zip.code": "75001"},
+		"tags": ["red", "blue"],
+		"scores": [3, 5, 8],
+		"items": [{"x": 1, "y": 2}, {"x": 3, "y": 4}],
+		"counts": {"a": 1, "b": 2}
+	}`
+	tests := []struct {
+		name     string
+		selector interface{}
+		expected bool
+	}{
+		{"empty", `{}`, true},
+		{"implicit eq", `{"name":"Bob"}`, true},
+		{"implicit eq mismatch", `{"name":"Alice"}`, false},
+		{"implicit and", `{"name":"Bob","age":41}`, false},
+		{"eq", Eq("age", 42), true},
+		{"eq missing", Eq("missing", nil), false},
+		{"eq null", Eq("nothing", nil), true},
+		{"eq array", `{"tags":["red","blue"]}`, true},
+		{"eq object", `{"address":{"$eq":{"city":"Paris","zip.code":"75001"}}}`, true},
+		{"ne", Ne("age", 41), true},
+		{"ne missing", Ne("missing", 1), false},
+		{"gt", Gt("age", 40), true},
+		{"gte", Gte("age", 42), true},
+		{"lt", Lt("age", 42), false},
+		{"lte", Lte("age", 42), true},
+		{"collation", Gt("name", 100), true},
+		{"collation null", Lt("nothing", false), true},
+		{"nested field", Eq("address.city", "Paris"), true},
+		{"nested object", `{"address":{"city":"Paris"}}`, true},
+		{"escaped period", `{"address.zip\\.code":"75001"}`, true},
+		{"array index", Eq("items.1.x", 3), true},
+		{"array index out of range", Eq("items.2.x", 3), false},
+		{"in", In("age", 1, 42), true},
+		{"in array", In("tags", "blue", "green"), true},
+		{"in mismatch", In("age", 1, 2), false},
+		{"nin", Nin("age", 1, 2), true},
+		{"nin missing", Nin("missing", 1), false},
+		{"exists", Exists("nothing", true), true},
+		{"not exists", Exists("missing", false), true},
+		{"type", Type("address", TypeObject), true},
+		{"type null", Type("nothing", TypeNull), true},
+		{"type mismatch", Type("age", TypeString), false},
+		{"size", Size("tags", 2), true},
+		{"size mismatch", Size("tags", 3), false},
+		{"mod", Mod("age", 5, 2), true},
+		{"mod mismatch", Mod("age", 5, 1), false},
+		{"regex", Regex("name", "^B"), true},
+		{"regex non-string", Regex("age", "4"), false},
+		{"beginsWith", `{"name":{"$beginsWith":"Bo"}}`, true},
+		{"all", All("scores", 8, 3), true},
+		{"all mismatch", All("scores", 8, 4), false},
+		{"elemMatch value", ElemMatch("tags", Eq("", "blue")), true},
+		{"elemMatch fields", ElemMatch("items", And(Eq("x", 3), Eq("y", 4))), true},
+		{"elemMatch fields mismatch", ElemMatch("items", And(Eq("x", 1), Eq("y", 4))), false},
+		{"allMatch", AllMatch("scores", Gt("", 2)), true},
+		{"allMatch mismatch", AllMatch("scores", Gt("", 3)), false},
+		{"keyMapMatch", `{"counts":{"$keyMapMatch":{"$eq":"b"}}}`, true},
+		{"and", And(Eq("name", "Bob"), Gt("age", 40)), true},
+		{"or", Or(Eq("name", "Alice"), Gt("age", 40)), true},
+		{"or mismatch", Or(Eq("name", "Alice"), Gt("age", 50)), false},
+		{"nor", Nor(Eq("name", "Alice"), Gt("age", 50)), true},
+		{"not", Not(Eq("name", "Alice")), true},
+		{"not missing", `{"missing":{"$not":{"$eq":1}}}`, true},
+		{"combined conditions", `{"age":{"$gt":40,"$lt":50}}`, true},
+		{"combined conditions mismatch", `{"age":{"$gt":40,"$lt":42}}`, false},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			m, err := Compile(test.selector)
+			if err != nil {
+				t.Fatal(err)
+			}
+			result, err := m.MatchJSON([]byte(doc))
+			if err != nil {
+				t.Fatal(err)
+			}
+			if result != test.expected {
+				t.Errorf("Expected %t, got %t", test.expected, result)
+			}
+		})
+	}
+}
+
+func TestMatcherMatch(t *testing.T) {
+	m, err := Compile(Gt("n", 1))
+	if err != nil {
+		t.Fatal(err)
+	}
+	type doc struct {
+		N int `json:"n"`
+	}
+	if !m.Match(doc{N: 2}) {
+		t.Error("Expected struct to match")
+	}
+	if !m.Match(map[string]interface{}{"n": 2}) {
+		t.Error("Expected map to match")
+	}
+	if m.Match(make(chan int)) {
+		t.Error("Expected unmarshalable value not to match")
+	}
+}
@@ -0,0 +1,146 @@
+package mango
+
+import (
+	"encoding/json"
+	"errors"
+)
+
+// SortField is a field to sort by, and a direction. See Asc and Desc.
+type SortField struct {
+	Field      string
+	Descending bool
+}
+
+// Asc sorts by field in ascending order.
+func Asc(field string) SortField {
+	return SortField{Field: field}
+}
+
+// Desc sorts by field in descending order.
+func Desc(field string) SortField {
+	return SortField{Field: field, Descending: true}
+}
+
+// Query is a complete Mango query, which may be passed to DB.Find or
+// DB.Explain. Its methods modify and return the receiver, so that calls may be
+// chained.
+type Query struct {
+	selector Selector
+	sort     []SortField
+	fields   []string
+	limit    int
+	skip     int
+	bookmark string
+	useIndex []string
+}
+
+// NewQuery returns a new query for documents matching sel. A nil selector
+// matches all documents.
+func NewQuery(sel Selector) *Query {
+	if sel == nil {
+		sel = Selector{}
+	}
+	return &Query{selector: sel}
+}
+
+// Sort sets the sort order of the results. CouchDB requires that all fields
+// are sorted in the same direction.
+func (q *Query) Sort(fields ...SortField) *Query {
+	q.sort = fields
+	return q
+}
+
+// Fields limits the fields returned for each document.
+func (q *Query) Fields(fields ...string) *Query {
+	q.fields = fields
+	return q
+}
+
+// Limit sets the maximum number of results returned.
+func (q *Query) Limit(limit int) *Query {
+	q.limit = limit
+	return q
+}
+
+// Skip sets the number of results to skip.
+func (q *Query) Skip(skip int) *Query {
+	q.skip = skip
+	return q
+}
+
+// Bookmark sets the bookmark, as returned by Rows.Bookmark, from which to
+// continue a previous query.
+func (q *Query) Bookmark(bookmark string) *Query {
+	q.bookmark = bookmark
+	return q
+}
+
+// UseIndex requests the use of a specific index, identified by its design
+// document, and optionally its name.
+func (q *Query) UseIndex(ddoc string, name ...string) *Query {
+	q.useIndex = append([]string{ddoc}, name...)
+	return q
+}
+
+// Validate returns an error if the query is invalid.
+func (q *Query) Validate() error {
+	if q.limit < 0 {
+		return errors.New("mango: limit must not be negative")
+	}
+	if q.skip < 0 {
+		return errors.New("mango: skip must not be negative")
+	}
+	if len(q.useIndex) > 2 {
+		return errors.New("mango: too many index names")
+	}
+	for _, f := range q.sort {
+		if f.Field == "" {
+			return errors.New("mango: sort field required")
+		}
+		if f.Descending != q.sort[0].Descending {
+			return errors.New("mango: all sort fields must use the same direction")
+		}
+	}
+	return nil
+}
+
+// MarshalJSON satisfies the json.Marshaler interface. It returns an error if
+// the query is invalid.
+func (q *Query) MarshalJSON() ([]byte, error) {
+	if err := q.Validate(); err != nil {
+		return nil, err
+	}
+	query := map[string]interface{}{
+		"selector": q.selector,
+	}
+	if len(q.sort) > 0 {
+		sort := make([]map[string]string, len(q.sort))
+		for i, f := range q.sort {
+			dir := "asc"
+			if f.Descending {
+				dir = "desc"
+			}
+			sort[i] = map[string]string{f.Field: dir}
+		}
+		query["sort"] = sort
+	}
+	if len(q.fields) > 0 {
+		query["fields"] = q.fields
+	}
+	if q.limit > 0 {
+		query["limit"] = q.limit
+	}
+	if q.skip > 0 {
+		query["skip"] = q.skip
+	}
+	if q.bookmark != "" {
+		query["bookmark"] = q.bookmark
+	}
+	switch len(q.useIndex) {
+	case 1:
+		query["use_index"] = q.useIndex[0]
+	case 2:
+		query["use_index"] = q.useIndex
+	}
+	return json.Marshal(query)
+}
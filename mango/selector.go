@@ -0,0 +1,160 @@
+// Package mango provides a builder for Mango queries, as used by DB.Find and
+// the CouchDB /_find endpoint.
+//
+// Selectors are built with the condition and combination functions, and
+// wrapped in a Query to add sorting, projection and pagination:
+//
+//	query := mango.NewQuery(mango.And(
+//		mango.Eq("type", "widget"),
+//		mango.Gt("price", 100),
+//	)).Sort(mango.Asc("price")).Fields("_id", "price").Limit(10)
+//	rows, err := db.Find(ctx, query)
+//
+// See http://docs.couchdb.org/en/2.0.0/api/database/find.html#selector-syntax
+package mango
+
+// Selector is a Mango selector, which marshals to the JSON form expected by
+// CouchDB. Field names may use dot notation to refer to nested fields.
+type Selector map[string]interface{}
+
+func condition(field, operator string, arg interface{}) Selector {
+	return Selector{field: map[string]interface{}{operator: arg}}
+}
+
+// Eq matches documents where field equals value.
+func Eq(field string, value interface{}) Selector {
+	return condition(field, "$eq", value)
+}
+
+// Ne matches documents where field does not equal value.
+func Ne(field string, value interface{}) Selector {
+	return condition(field, "$ne", value)
+}
+
+// Gt matches documents where field is greater than value.
+func Gt(field string, value interface{}) Selector {
+	return condition(field, "$gt", value)
+}
+
+// Gte matches documents where field is greater than or equal to value.
+func Gte(field string, value interface{}) Selector {
+	return condition(field, "$gte", value)
+}
+
+// Lt matches documents where field is less than value.
+func Lt(field string, value interface{}) Selector {
+	return condition(field, "$lt", value)
+}
+
+// Lte matches documents where field is less than or equal to value.
+func Lte(field string, value interface{}) Selector {
+	return condition(field, "$lte", value)
+}
+
+// In matches documents where field equals one of values.
+func In(field string, values ...interface{}) Selector {
+	return condition(field, "$in", array(values))
+}
+
+// Nin matches documents where field equals none of values.
+func Nin(field string, values ...interface{}) Selector {
+	return condition(field, "$nin", array(values))
+}
+
+// Exists matches documents where field exists, or does not exist, according
+// to exists.
+func Exists(field string, exists bool) Selector {
+	return condition(field, "$exists", exists)
+}
+
+// JSON types, for use with Type.
+const (
+	TypeNull    = "null"
+	TypeBoolean = "boolean"
+	TypeNumber  = "number"
+	TypeString  = "string"
+	TypeArray   = "array"
+	TypeObject  = "object"
+)
+
+// Type matches documents where field has the specified JSON type.
+func Type(field, jsonType string) Selector {
+	return condition(field, "$type", jsonType)
+}
+
+// Size matches documents where field is an array of the specified length.
+func Size(field string, length int) Selector {
+	return condition(field, "$size", length)
+}
+
+// Mod matches documents where field is an integer, whose remainder when
+// divided by divisor is remainder.
+func Mod(field string, divisor, remainder int) Selector {
+	return condition(field, "$mod", []int{divisor, remainder})
+}
+
+// Regex matches documents where field is a string matching pattern.
+func Regex(field, pattern string) Selector {
+	return condition(field, "$regex", pattern)
+}
+
+// All matches documents where field is an array containing all of values.
+func All(field string, values ...interface{}) Selector {
+	return condition(field, "$all", array(values))
+}
+
+// ElemMatch matches documents where field is an array, at least one element of
+// which matches sel. Conditions on the element itself, rather than its
+// fields, may be expressed with an empty field name, as in Eq("", value).
+func ElemMatch(field string, sel Selector) Selector {
+	return condition(field, "$elemMatch", sel.value())
+}
+
+// AllMatch matches documents where field is an array, all elements of which
+// match sel.
+func AllMatch(field string, sel Selector) Selector {
+	return condition(field, "$allMatch", sel.value())
+}
+
+// And matches documents which match all of sels.
+func And(sels ...Selector) Selector {
+	return Selector{"$and": selectors(sels)}
+}
+
+// Or matches documents which match any of sels.
+func Or(sels ...Selector) Selector {
+	return Selector{"$or": selectors(sels)}
+}
+
+// Nor matches documents which match none of sels.
+func Nor(sels ...Selector) Selector {
+	return Selector{"$nor": selectors(sels)}
+}
+
+// Not matches documents which do not match sel.
+func Not(sel Selector) Selector {
+	return Selector{"$not": sel}
+}
+
+// value returns the selector's value, for use as an operator argument. A
+// condition on the empty field name applies to the value itself.
+func (s Selector) value() interface{} {
+	if cond, ok := s[""]; ok && len(s) == 1 {
+		return cond
+	}
+	return s
+}
+
+func selectors(sels []Selector) []Selector {
+	if sels == nil {
+		return []Selector{}
+	}
+	return sels
+}
+
+func array(values []interface{}) []interface{} {
+	if values == nil {
+		return []interface{}{}
+	}
+	return values
+}
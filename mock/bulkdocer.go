@@ -18,3 +18,16 @@ var _ driver.BulkDocer = &BulkDocer{}
 func (db *BulkDocer) BulkDocs(ctx context.Context, docs []interface{}, options map[string]interface{}) (driver.BulkResults, error) {
 	return db.BulkDocsFunc(ctx, docs, options)
 }
+
+// BulkGetter mocks a driver.DB and driver.BulkGetter
+type BulkGetter struct {
+	*DB
+	BulkGetFunc func(context.Context, []driver.BulkGetReference, map[string]interface{}) (driver.Rows, error)
+}
+
+var _ driver.BulkGetter = &BulkGetter{}
+
+// BulkGet calls db.BulkGetFunc
+func (db *BulkGetter) BulkGet(ctx context.Context, docs []driver.BulkGetReference, options map[string]interface{}) (driver.Rows, error) {
+	return db.BulkGetFunc(ctx, docs, options)
+}
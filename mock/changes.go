@@ -4,8 +4,10 @@ import "github.com/go-kivik/kivik/driver"
 
 // Changes mocks driver.Changes
 type Changes struct {
-	NextFunc  func(*driver.Change) error
-	CloseFunc func() error
+	NextFunc    func(*driver.Change) error
+	CloseFunc   func() error
+	LastSeqFunc func() string
+	PendingFunc func() int64
 }
 
 var _ driver.Changes = &Changes{}
@@ -19,3 +21,13 @@ func (c *Changes) Next(change *driver.Change) error {
 func (c *Changes) Close() error {
 	return c.CloseFunc()
 }
+
+// LastSeq calls c.LastSeqFunc
+func (c *Changes) LastSeq() string {
+	return c.LastSeqFunc()
+}
+
+// Pending calls c.PendingFunc
+func (c *Changes) Pending() int64 {
+	return c.PendingFunc()
+}
@@ -82,6 +82,19 @@ func (c *Authenticator) Authenticate(ctx context.Context, a interface{}) error {
 	return c.AuthenticateFunc(ctx, a)
 }
 
+// Pinger mocks driver.Client and driver.Pinger
+type Pinger struct {
+	*Client
+	PingFunc func(context.Context) (bool, error)
+}
+
+var _ driver.Pinger = &Pinger{}
+
+// Ping calls c.PingFunc
+func (c *Pinger) Ping(ctx context.Context) (bool, error) {
+	return c.PingFunc(ctx)
+}
+
 // DBUpdater mocks driver.Client and driver.DBUpdater
 type DBUpdater struct {
 	*Client
@@ -94,3 +107,68 @@ var _ driver.DBUpdater = &DBUpdater{}
 func (c *DBUpdater) DBUpdates() (driver.DBUpdates, error) {
 	return c.DBUpdatesFunc()
 }
+
+// ContextDBUpdater mocks driver.Client and driver.ContextDBUpdater
+type ContextDBUpdater struct {
+	*Client
+	DBUpdatesFunc func(context.Context, map[string]interface{}) (driver.DBUpdates, error)
+}
+
+var _ driver.ContextDBUpdater = &ContextDBUpdater{}
+
+// DBUpdates calls c.DBUpdatesFunc
+func (c *ContextDBUpdater) DBUpdates(ctx context.Context, opts map[string]interface{}) (driver.DBUpdates, error) {
+	return c.DBUpdatesFunc(ctx, opts)
+}
+
+// Stater mocks driver.Client and driver.Stater
+type Stater struct {
+	*Client
+	StatsFunc func(context.Context, map[string]interface{}) (*driver.NodeStats, error)
+}
+
+var _ driver.Stater = &Stater{}
+
+// Stats calls c.StatsFunc
+func (c *Stater) Stats(ctx context.Context, opts map[string]interface{}) (*driver.NodeStats, error) {
+	return c.StatsFunc(ctx, opts)
+}
+
+// DBsStatser mocks driver.Client and driver.DBsStatser
+type DBsStatser struct {
+	*Client
+	DBsStatsFunc func(context.Context, []string) ([]*driver.DBStats, error)
+}
+
+var _ driver.DBsStatser = &DBsStatser{}
+
+// DBsStats calls c.DBsStatsFunc
+func (c *DBsStatser) DBsStats(ctx context.Context, dbnames []string) ([]*driver.DBStats, error) {
+	return c.DBsStatsFunc(ctx, dbnames)
+}
+
+// ActiveTasker mocks driver.Client and driver.ActiveTasker
+type ActiveTasker struct {
+	*Client
+	ActiveTasksFunc func(context.Context) ([]driver.ActiveTask, error)
+}
+
+var _ driver.ActiveTasker = &ActiveTasker{}
+
+// ActiveTasks calls c.ActiveTasksFunc
+func (c *ActiveTasker) ActiveTasks(ctx context.Context) ([]driver.ActiveTask, error) {
+	return c.ActiveTasksFunc(ctx)
+}
+
+// ClientCloser mocks driver.Client and driver.ClientCloser
+type ClientCloser struct {
+	*Client
+	CloseFunc func(context.Context) error
+}
+
+var _ driver.ClientCloser = &ClientCloser{}
+
+// Close calls c.CloseFunc
+func (c *ClientCloser) Close(ctx context.Context) error {
+	return c.CloseFunc(ctx)
+}
@@ -0,0 +1,32 @@
+package mock
+
+import (
+	"context"
+
+	"github.com/go-kivik/kivik/driver"
+)
+
+// Cluster mocks driver.Client and driver.Cluster
+type Cluster struct {
+	*Client
+	ClusterStatusFunc func(context.Context, map[string]interface{}) (string, error)
+	ClusterSetupFunc  func(context.Context, interface{}) error
+	MembershipFunc    func(context.Context) (*driver.ClusterMembership, error)
+}
+
+var _ driver.Cluster = &Cluster{}
+
+// ClusterStatus calls c.ClusterStatusFunc
+func (c *Cluster) ClusterStatus(ctx context.Context, opts map[string]interface{}) (string, error) {
+	return c.ClusterStatusFunc(ctx, opts)
+}
+
+// ClusterSetup calls c.ClusterSetupFunc
+func (c *Cluster) ClusterSetup(ctx context.Context, action interface{}) error {
+	return c.ClusterSetupFunc(ctx, action)
+}
+
+// Membership calls c.MembershipFunc
+func (c *Cluster) Membership(ctx context.Context) (*driver.ClusterMembership, error) {
+	return c.MembershipFunc(ctx)
+}
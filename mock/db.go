@@ -110,6 +110,32 @@ func (db *DB) Query(ctx context.Context, ddoc, view string, opts map[string]inte
 	return db.QueryFunc(ctx, ddoc, view, opts)
 }
 
+// DesignDocer mocks a driver.DB and driver.DesignDocer
+type DesignDocer struct {
+	*DB
+	DesignDocsFunc func(context.Context, map[string]interface{}) (driver.Rows, error)
+}
+
+var _ driver.DesignDocer = &DesignDocer{}
+
+// DesignDocs calls db.DesignDocsFunc
+func (db *DesignDocer) DesignDocs(ctx context.Context, options map[string]interface{}) (driver.Rows, error) {
+	return db.DesignDocsFunc(ctx, options)
+}
+
+// LocalDocer mocks a driver.DB and driver.LocalDocer
+type LocalDocer struct {
+	*DB
+	LocalDocsFunc func(context.Context, map[string]interface{}) (driver.Rows, error)
+}
+
+var _ driver.LocalDocer = &LocalDocer{}
+
+// LocalDocs calls db.LocalDocsFunc
+func (db *LocalDocer) LocalDocs(ctx context.Context, options map[string]interface{}) (driver.Rows, error) {
+	return db.LocalDocsFunc(ctx, options)
+}
+
 // Finder mocks a driver.DB and driver.Finder
 type Finder struct {
 	*DB
@@ -173,6 +199,19 @@ func (db *MetaGetter) GetMeta(ctx context.Context, docID string, opts map[string
 	return db.GetMetaFunc(ctx, docID, opts)
 }
 
+// DocumentMetaGetter mocks a driver.DB and driver.DocumentMetaGetter
+type DocumentMetaGetter struct {
+	*DB
+	GetDocumentMetaFunc func(context.Context, string, map[string]interface{}) (*driver.DocumentMeta, error)
+}
+
+var _ driver.DocumentMetaGetter = &DocumentMetaGetter{}
+
+// GetDocumentMeta calls db.GetDocumentMetaFunc
+func (db *DocumentMetaGetter) GetDocumentMeta(ctx context.Context, docID string, opts map[string]interface{}) (*driver.DocumentMeta, error) {
+	return db.GetDocumentMetaFunc(ctx, docID, opts)
+}
+
 // Copier mocks a driver.DB and driver.Copier.
 type Copier struct {
 	*DB
@@ -198,3 +237,68 @@ var _ driver.AttachmentMetaGetter = &AttachmentMetaGetter{}
 func (db *AttachmentMetaGetter) GetAttachmentMeta(ctx context.Context, docID, rev, filename string, options map[string]interface{}) (*driver.Attachment, error) {
 	return db.GetAttachmentMetaFunc(ctx, docID, rev, filename, options)
 }
+
+// RevsDiffer mocks a driver.DB and driver.RevsDiffer
+type RevsDiffer struct {
+	*DB
+	RevsDiffFunc func(context.Context, interface{}) (driver.Rows, error)
+}
+
+var _ driver.RevsDiffer = &RevsDiffer{}
+
+// RevsDiff calls db.RevsDiffFunc
+func (db *RevsDiffer) RevsDiff(ctx context.Context, revMap interface{}) (driver.Rows, error) {
+	return db.RevsDiffFunc(ctx, revMap)
+}
+
+// OpenRever mocks a driver.DB and driver.OpenRever
+type OpenRever struct {
+	*DB
+	OpenRevsFunc func(context.Context, string, []string, map[string]interface{}) (driver.Rows, error)
+}
+
+var _ driver.OpenRever = &OpenRever{}
+
+// OpenRevs calls db.OpenRevsFunc
+func (db *OpenRever) OpenRevs(ctx context.Context, docID string, revs []string, options map[string]interface{}) (driver.Rows, error) {
+	return db.OpenRevsFunc(ctx, docID, revs, options)
+}
+
+// MultipartPutter mocks a driver.DB and driver.MultipartPutter
+type MultipartPutter struct {
+	*DB
+	PutWithAttachmentsFunc func(context.Context, string, interface{}, []*driver.Attachment) (string, error)
+}
+
+var _ driver.MultipartPutter = &MultipartPutter{}
+
+// PutWithAttachments calls db.PutWithAttachmentsFunc
+func (db *MultipartPutter) PutWithAttachments(ctx context.Context, docID string, doc interface{}, atts []*driver.Attachment) (string, error) {
+	return db.PutWithAttachmentsFunc(ctx, docID, doc, atts)
+}
+
+// Purger mocks a driver.DB and driver.Purger
+type Purger struct {
+	*DB
+	PurgeFunc func(context.Context, map[string][]string) (*driver.PurgeResult, error)
+}
+
+var _ driver.Purger = &Purger{}
+
+// Purge calls db.PurgeFunc
+func (db *Purger) Purge(ctx context.Context, docMap map[string][]string) (*driver.PurgeResult, error) {
+	return db.PurgeFunc(ctx, docMap)
+}
+
+// DBCloser mocks a driver.DB and driver.DBCloser
+type DBCloser struct {
+	*DB
+	CloseFunc func(context.Context) error
+}
+
+var _ driver.DBCloser = &DBCloser{}
+
+// Close calls db.CloseFunc
+func (db *DBCloser) Close(ctx context.Context) error {
+	return db.CloseFunc(ctx)
+}
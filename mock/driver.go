@@ -17,3 +17,16 @@ var _ driver.Driver = &Driver{}
 func (d *Driver) NewClient(ctx context.Context, name string) (driver.Client, error) {
 	return d.NewClientFunc(ctx, name)
 }
+
+// OptionsDriver mocks a driver.Driver and driver.OptionsDriver
+type OptionsDriver struct {
+	*Driver
+	NewClientWithOptionsFunc func(context.Context, string, map[string]interface{}) (driver.Client, error)
+}
+
+var _ driver.OptionsDriver = &OptionsDriver{}
+
+// NewClientWithOptions calls d.NewClientWithOptionsFunc
+func (d *OptionsDriver) NewClientWithOptions(ctx context.Context, name string, options map[string]interface{}) (driver.Client, error) {
+	return d.NewClientWithOptionsFunc(ctx, name, options)
+}
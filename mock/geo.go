@@ -0,0 +1,20 @@
+package mock
+
+import (
+	"context"
+
+	"github.com/go-kivik/kivik/driver"
+)
+
+// GeoQuerier mocks a driver.DB and driver.GeoQuerier
+type GeoQuerier struct {
+	*DB
+	GeoFunc func(context.Context, string, string, map[string]interface{}) (driver.Rows, error)
+}
+
+var _ driver.GeoQuerier = &GeoQuerier{}
+
+// Geo calls db.GeoFunc
+func (db *GeoQuerier) Geo(ctx context.Context, ddoc, index string, options map[string]interface{}) (driver.Rows, error) {
+	return db.GeoFunc(ctx, ddoc, index, options)
+}
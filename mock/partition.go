@@ -0,0 +1,38 @@
+package mock
+
+import (
+	"context"
+
+	"github.com/go-kivik/kivik/driver"
+)
+
+// Partitioner mocks a driver.DB and driver.Partitioner
+type Partitioner struct {
+	*DB
+	PartitionStatsFunc   func(context.Context, string) (*driver.PartitionStats, error)
+	PartitionAllDocsFunc func(context.Context, string, map[string]interface{}) (driver.Rows, error)
+	PartitionQueryFunc   func(context.Context, string, string, string, map[string]interface{}) (driver.Rows, error)
+	PartitionFindFunc    func(context.Context, string, interface{}) (driver.Rows, error)
+}
+
+var _ driver.Partitioner = &Partitioner{}
+
+// PartitionStats calls db.PartitionStatsFunc
+func (db *Partitioner) PartitionStats(ctx context.Context, name string) (*driver.PartitionStats, error) {
+	return db.PartitionStatsFunc(ctx, name)
+}
+
+// PartitionAllDocs calls db.PartitionAllDocsFunc
+func (db *Partitioner) PartitionAllDocs(ctx context.Context, partition string, options map[string]interface{}) (driver.Rows, error) {
+	return db.PartitionAllDocsFunc(ctx, partition, options)
+}
+
+// PartitionQuery calls db.PartitionQueryFunc
+func (db *Partitioner) PartitionQuery(ctx context.Context, partition, ddoc, view string, options map[string]interface{}) (driver.Rows, error) {
+	return db.PartitionQueryFunc(ctx, partition, ddoc, view, options)
+}
+
+// PartitionFind calls db.PartitionFindFunc
+func (db *Partitioner) PartitionFind(ctx context.Context, partition string, query interface{}) (driver.Rows, error) {
+	return db.PartitionFindFunc(ctx, partition, query)
+}
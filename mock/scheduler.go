@@ -0,0 +1,66 @@
+package mock
+
+import (
+	"context"
+
+	"github.com/go-kivik/kivik/driver"
+)
+
+// Scheduler mocks driver.Client and driver.Scheduler
+type Scheduler struct {
+	*Client
+	SchedulerJobsFunc func(context.Context, map[string]interface{}) (driver.SchedulerJobs, error)
+	SchedulerDocsFunc func(context.Context, string, map[string]interface{}) (driver.SchedulerDocs, error)
+}
+
+var _ driver.Scheduler = &Scheduler{}
+
+// SchedulerJobs calls c.SchedulerJobsFunc
+func (c *Scheduler) SchedulerJobs(ctx context.Context, opts map[string]interface{}) (driver.SchedulerJobs, error) {
+	return c.SchedulerJobsFunc(ctx, opts)
+}
+
+// SchedulerDocs calls c.SchedulerDocsFunc
+func (c *Scheduler) SchedulerDocs(ctx context.Context, replicatorDB string, opts map[string]interface{}) (driver.SchedulerDocs, error) {
+	return c.SchedulerDocsFunc(ctx, replicatorDB, opts)
+}
+
+// SchedulerJobs mocks driver.SchedulerJobs
+type SchedulerJobs struct {
+	// ID identifies a specific SchedulerJobs instance.
+	ID        string
+	NextFunc  func(*driver.SchedulerJob) error
+	CloseFunc func() error
+}
+
+var _ driver.SchedulerJobs = &SchedulerJobs{}
+
+// Next calls j.NextFunc
+func (j *SchedulerJobs) Next(job *driver.SchedulerJob) error {
+	return j.NextFunc(job)
+}
+
+// Close calls j.CloseFunc
+func (j *SchedulerJobs) Close() error {
+	return j.CloseFunc()
+}
+
+// SchedulerDocs mocks driver.SchedulerDocs
+type SchedulerDocs struct {
+	// ID identifies a specific SchedulerDocs instance.
+	ID        string
+	NextFunc  func(*driver.SchedulerDoc) error
+	CloseFunc func() error
+}
+
+var _ driver.SchedulerDocs = &SchedulerDocs{}
+
+// Next calls d.NextFunc
+func (d *SchedulerDocs) Next(doc *driver.SchedulerDoc) error {
+	return d.NextFunc(doc)
+}
+
+// Close calls d.CloseFunc
+func (d *SchedulerDocs) Close() error {
+	return d.CloseFunc()
+}
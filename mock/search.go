@@ -0,0 +1,40 @@
+package mock
+
+import (
+	"context"
+	"encoding/json"
+
+	"github.com/go-kivik/kivik/driver"
+)
+
+// Searcher mocks a driver.DB and driver.Searcher
+type Searcher struct {
+	*DB
+	SearchFunc func(context.Context, string, string, string, map[string]interface{}) (driver.Rows, error)
+}
+
+var _ driver.Searcher = &Searcher{}
+
+// Search calls db.SearchFunc
+func (db *Searcher) Search(ctx context.Context, ddoc, index, query string, options map[string]interface{}) (driver.Rows, error) {
+	return db.SearchFunc(ctx, ddoc, index, query, options)
+}
+
+// SearchFacets wraps driver.SearchFacets
+type SearchFacets struct {
+	*Rows
+	CountsFunc func() json.RawMessage
+	RangesFunc func() json.RawMessage
+}
+
+var _ driver.SearchFacets = &SearchFacets{}
+
+// Counts calls r.CountsFunc
+func (r *SearchFacets) Counts() json.RawMessage {
+	return r.CountsFunc()
+}
+
+// Ranges calls r.RangesFunc
+func (r *SearchFacets) Ranges() json.RawMessage {
+	return r.RangesFunc()
+}
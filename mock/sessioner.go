@@ -18,3 +18,16 @@ var _ driver.Sessioner = &Sessioner{}
 func (s *Sessioner) Session(ctx context.Context) (*driver.Session, error) {
 	return s.SessionFunc(ctx)
 }
+
+// SessionDeleter mocks driver.Client and driver.SessionDeleter
+type SessionDeleter struct {
+	*Client
+	DeleteSessionFunc func(context.Context) error
+}
+
+var _ driver.SessionDeleter = &SessionDeleter{}
+
+// DeleteSession calls s.DeleteSessionFunc
+func (s *SessionDeleter) DeleteSession(ctx context.Context) error {
+	return s.DeleteSessionFunc(ctx)
+}
@@ -0,0 +1,35 @@
+package kivik
+
+import (
+	"context"
+
+	"github.com/go-kivik/kivik/driver"
+	"github.com/go-kivik/kivik/errors"
+)
+
+// GetOpenRevs fetches the requested leaf revisions of a document, as a Rows
+// iterator. If revs is empty, all leaf revisions are returned, including
+// conflicting and deleted leaves, as with the open_revs=all query parameter.
+// This is primarily useful for conflict resolution, and for serving as a
+// replication source.
+//
+// Use ScanDoc to read each revision. If a requested revision could not be
+// found, ScanDoc will return an error with status StatusNotFound.
+//
+// See http://docs.couchdb.org/en/2.2.0/api/document/common.html#get--db-docid
+func (db *DB) GetOpenRevs(ctx context.Context, docID string, revs []string, options ...Options) (*Rows, error) {
+	openRever, ok := db.driverDB.(driver.OpenRever)
+	if !ok {
+		return nil, errors.Status(StatusNotImplemented, "kivik: open revs not supported by driver")
+	}
+	if docID == "" {
+		return nil, missingArg("docID")
+	}
+	opts, err := mergeOptions(options...)
+	if err != nil {
+		return nil, err
+	}
+	return db.doRows(ctx, &Operation{Name: "OpenRevs", DocID: docID, Options: opts}, func(ctx context.Context) (driver.Rows, error) {
+		return openRever.OpenRevs(ctx, docID, revs, opts)
+	})
+}
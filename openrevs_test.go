@@ -0,0 +1,94 @@
+package kivik
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"testing"
+
+	"github.com/flimzy/diff"
+	"github.com/flimzy/testy"
+	"github.com/go-kivik/kivik/driver"
+	"github.com/go-kivik/kivik/mock"
+)
+
+func TestGetOpenRevs(t *testing.T) {
+	tests := []struct {
+		name     string
+		db       *DB
+		docID    string
+		revs     []string
+		options  Options
+		expected *Rows
+		status   int
+		err      string
+	}{
+		{
+			name:   "non-OpenRever",
+			db:     &DB{driverDB: &mock.DB{}},
+			docID:  "foo",
+			status: StatusNotImplemented,
+			err:    "kivik: open revs not supported by driver",
+		},
+		{
+			name:   "missing docID",
+			db:     &DB{driverDB: &mock.OpenRever{}},
+			status: StatusBadRequest,
+			err:    "kivik: docID required",
+		},
+		{
+			name: "db error",
+			db: &DB{
+				driverDB: &mock.OpenRever{
+					OpenRevsFunc: func(_ context.Context, _ string, _ []string, _ map[string]interface{}) (driver.Rows, error) {
+						return nil, errors.New("db error")
+					},
+				},
+			},
+			docID:  "foo",
+			status: StatusInternalServerError,
+			err:    "db error",
+		},
+		{
+			name: "success",
+			db: &DB{
+				driverDB: &mock.OpenRever{
+					OpenRevsFunc: func(_ context.Context, docID string, revs []string, opts map[string]interface{}) (driver.Rows, error) {
+						if docID != "foo" {
+							return nil, fmt.Errorf("Unexpected docID: %s", docID)
+						}
+						if d := diff.Interface([]string{"1-xxx", "2-yyy"}, revs); d != nil {
+							return nil, fmt.Errorf("Unexpected revs:\n%s", d)
+						}
+						if d := diff.Interface(testOptions, opts); d != nil {
+							return nil, fmt.Errorf("Unexpected options:\n%s", d)
+						}
+						return &mock.Rows{ID: "a"}, nil
+					},
+				},
+			},
+			docID:   "foo",
+			revs:    []string{"1-xxx", "2-yyy"},
+			options: testOptions,
+			expected: &Rows{
+				iter: &iter{
+					feed: &rowsIterator{
+						Rows: &mock.Rows{ID: "a"},
+					},
+					curVal: &driver.Row{},
+				},
+				rowsi: &mock.Rows{ID: "a"},
+			},
+		},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			result, err := test.db.GetOpenRevs(context.Background(), test.docID, test.revs, test.options)
+			testy.StatusError(t, test.err, test.status, err)
+			result.cancel = nil // Determinism
+			if d := diff.Interface(test.expected, result); d != nil {
+				t.Error(d)
+			}
+		})
+	}
+}
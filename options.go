@@ -0,0 +1,111 @@
+package kivik
+
+import (
+	"encoding/json"
+	"net/http"
+	"strconv"
+
+	"github.com/go-kivik/kivik/errors"
+)
+
+// The functions below construct Options for common query parameters. As
+// every method which accepts options merges any number of Options values,
+// they may be freely combined, with each other or with a literal Options map:
+//
+//  rows, err := db.AllDocs(ctx, kivik.IncludeDocs(), kivik.StartKey("foo"), kivik.Limit(10))
+
+// Param returns an Options value containing the single key/value pair. It
+// may be used for any option not covered by a dedicated helper.
+func Param(key string, value interface{}) Options {
+	return Options{key: value}
+}
+
+// IncludeDocs requests that the full document be included with each row of a
+// view or changes feed result.
+func IncludeDocs() Options {
+	return Param("include_docs", true)
+}
+
+// Descending requests that results be returned in descending key order.
+func Descending() Options {
+	return Param("descending", true)
+}
+
+// Limit limits the number of rows returned.
+func Limit(n int) Options {
+	return Param("limit", n)
+}
+
+// Skip skips the first n rows of a result set.
+func Skip(n int) Options {
+	return Param("skip", n)
+}
+
+// Key limits view results to those matching key. key is JSON-encoded, as
+// required by the key query parameter.
+func Key(key interface{}) Options {
+	return jsonParam("key", key)
+}
+
+// StartKey limits view results to those with keys at or after key. key is
+// JSON-encoded, as required by the startkey query parameter.
+func StartKey(key interface{}) Options {
+	return jsonParam("startkey", key)
+}
+
+// EndKey limits view results to those with keys at or before key. key is
+// JSON-encoded, as required by the endkey query parameter.
+func EndKey(key interface{}) Options {
+	return jsonParam("endkey", key)
+}
+
+// Since starts a changes feed after seq. Pass SequenceNow to receive only
+// future changes.
+func Since(seq SequenceID) Options {
+	return Param("since", string(seq))
+}
+
+// HTTPClient returns an option for NewWithOptions, which requests that an
+// HTTP-based driver use client for all requests. This allows control of TLS
+// configuration, proxies, timeouts and connection pooling, and the use of
+// transport middleware.
+func HTTPClient(client *http.Client) Options {
+	return Param(OptionHTTPClient, client)
+}
+
+// invalidOption is stored in place of an option value which could not be
+// encoded, so that the error may be reported by mergeOptions.
+type invalidOption struct {
+	err error
+}
+
+func jsonParam(key string, value interface{}) Options {
+	v, err := json.Marshal(value)
+	if err != nil {
+		return Param(key, invalidOption{err: err})
+	}
+	return Param(key, string(v))
+}
+
+// validateOptions returns an error if any option value could not be encoded.
+func validateOptions(opts Options) error {
+	for key, value := range opts {
+		if invalid, ok := value.(invalidOption); ok {
+			return errors.Statusf(StatusBadRequest, "kivik: invalid value for '%s' option: %s", key, invalid.err)
+		}
+	}
+	return nil
+}
+
+// boolOption returns the named option as a bool. Strings, such as "true", are
+// parsed with strconv.ParseBool; any other value is false.
+func boolOption(opts map[string]interface{}, key string) bool {
+	switch t := opts[key].(type) {
+	case bool:
+		return t
+	case string:
+		b, _ := strconv.ParseBool(t)
+		return b
+	}
+	return false
+}
@@ -0,0 +1,54 @@
+package kivik
+
+import (
+	"testing"
+
+	"github.com/flimzy/diff"
+	"github.com/flimzy/testy"
+)
+
+func TestOptionHelpers(t *testing.T) {
+	tests := []struct {
+		name     string
+		options  []Options
+		expected Options
+		status   int
+		err      string
+	}{
+		{
+			name:     "param",
+			options:  []Options{Param("conflicts", true)},
+			expected: Options{"conflicts": true},
+		},
+		{
+			name:     "view options",
+			options:  []Options{IncludeDocs(), Descending(), Limit(10), Skip(5)},
+			expected: Options{"include_docs": true, "descending": true, "limit": 10, "skip": 5},
+		},
+		{
+			name:     "json-encoded keys",
+			options:  []Options{StartKey("foo"), EndKey([]interface{}{"foo", map[string]interface{}{}}), Key(3)},
+			expected: Options{"startkey": `"foo"`, "endkey": `["foo",{}]`, "key": "3"},
+		},
+		{
+			name:     "combined with map",
+			options:  []Options{{"limit": 3}, Limit(10)},
+			expected: Options{"limit": 10},
+		},
+		{
+			name:    "unencodable key",
+			options: []Options{StartKey(make(chan int))},
+			status:  StatusBadRequest,
+			err:     "kivik: invalid value for 'startkey' option: json: unsupported type: chan int",
+		},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			result, err := mergeOptions(test.options...)
+			testy.StatusError(t, test.err, test.status, err)
+			if d := diff.Interface(test.expected, result); d != nil {
+				t.Error(d)
+			}
+		})
+	}
+}
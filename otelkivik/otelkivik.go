@@ -0,0 +1,118 @@
+// Package otelkivik provides OpenTelemetry tracing for Kivik clients.
+//
+// Each request made through an instrumented client is recorded as a client
+// span, named after the operation, such as "kivik.Get" or "kivik.Query", with
+// the database name, document ID and, on failure, the Kivik status code as
+// attributes. For operations which return Rows, such as AllDocs and Query, the
+// span ends when the Rows are closed, and records the number of rows read.
+//
+// As the OpenTelemetry API requires a recent version of Go, this package is
+// not subject to the Go version requirements of Kivik itself.
+package otelkivik
+
+import (
+	"context"
+	"time"
+
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/trace"
+
+	"github.com/go-kivik/kivik"
+)
+
+const instrumentationName = "github.com/go-kivik/kivik/otelkivik"
+
+// Attribute keys set on spans, in addition to the standard db.system,
+// db.name and db.operation attributes.
+const (
+	DocIDKey      = attribute.Key("kivik.doc_id")
+	StatusCodeKey = attribute.Key("kivik.status_code")
+	RowsKey       = attribute.Key("kivik.rows")
+)
+
+type config struct {
+	provider trace.TracerProvider
+}
+
+// Option configures the tracing hook.
+type Option func(*config)
+
+// WithTracerProvider sets the TracerProvider used to create spans. By
+// default, the global TracerProvider is used.
+func WithTracerProvider(provider trace.TracerProvider) Option {
+	return func(c *config) {
+		c.provider = provider
+	}
+}
+
+// Instrument registers a tracing hook on client. It should be called before
+// the client is used.
+func Instrument(client *kivik.Client, opts ...Option) {
+	client.AddHook(NewHook(opts...))
+}
+
+// NewHook returns a kivik.Hook which records a span for each operation.
+func NewHook(opts ...Option) kivik.Hook {
+	cfg := &config{}
+	for _, opt := range opts {
+		opt(cfg)
+	}
+	if cfg.provider == nil {
+		cfg.provider = otel.GetTracerProvider()
+	}
+	return &hook{
+		tracer: cfg.provider.Tracer(instrumentationName),
+	}
+}
+
+type hook struct {
+	tracer trace.Tracer
+}
+
+var _ kivik.RowsHook = &hook{}
+
+func (h *hook) Before(ctx context.Context, op *kivik.Operation) context.Context {
+	attrs := []attribute.KeyValue{
+		attribute.String("db.system", "couchdb"),
+		attribute.String("db.operation", op.Name),
+	}
+	if op.DB != "" {
+		attrs = append(attrs, attribute.String("db.name", op.DB))
+	}
+	if op.DocID != "" {
+		attrs = append(attrs, DocIDKey.String(op.DocID))
+	}
+	ctx, _ = h.tracer.Start(ctx, "kivik."+op.Name,
+		trace.WithSpanKind(trace.SpanKindClient),
+		trace.WithAttributes(attrs...),
+	)
+	return ctx
+}
+
+func (h *hook) After(ctx context.Context, op *kivik.Operation, _ time.Duration, err error) {
+	span := trace.SpanFromContext(ctx)
+	if err == nil && op.Rows {
+		// The span is ended by RowsDone.
+		return
+	}
+	recordError(span, err)
+	span.End()
+}
+
+func (h *hook) RowsDone(ctx context.Context, _ *kivik.Operation, rows int64, err error) {
+	span := trace.SpanFromContext(ctx)
+	span.SetAttributes(RowsKey.Int64(rows))
+	recordError(span, err)
+	span.End()
+}
+
+func recordError(span trace.Span, err error) {
+	if err == nil {
+		return
+	}
+	span.SetAttributes(StatusCodeKey.Int(kivik.StatusCode(err)))
+	span.RecordError(err)
+	span.SetStatus(codes.Error, err.Error())
+}
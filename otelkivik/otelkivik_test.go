@@ -0,0 +1,173 @@
+package otelkivik
+
+import (
+	"context"
+	"io"
+	"testing"
+
+	"github.com/flimzy/diff"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+
+	"github.com/go-kivik/kivik"
+	"github.com/go-kivik/kivik/driver"
+	"github.com/go-kivik/kivik/errors"
+	"github.com/go-kivik/kivik/mock"
+)
+
+func init() {
+	kivik.Register("otelkivik-mock", &mock.Driver{
+		NewClientFunc: func(_ context.Context, _ string) (driver.Client, error) {
+			return &mock.Client{
+				DBFunc: func(_ context.Context, _ string, _ map[string]interface{}) (driver.DB, error) {
+					return newTestDB(), nil
+				},
+			}, nil
+		},
+	})
+}
+
+// testDB is a mock database which also supports Find and BulkDocs.
+type testDB struct {
+	*mock.Finder
+}
+
+var _ driver.BulkDocer = &testDB{}
+
+func newTestDB() *testDB {
+	return &testDB{Finder: &mock.Finder{
+		DB: &mock.DB{
+			GetFunc: func(_ context.Context, _ string, _ map[string]interface{}) (*driver.Document, error) {
+				return nil, errors.Status(kivik.StatusNotFound, "missing")
+			},
+			AllDocsFunc: func(_ context.Context, _ map[string]interface{}) (driver.Rows, error) {
+				ids := []string{"a", "b"}
+				return &mock.Rows{
+					NextFunc: func(row *driver.Row) error {
+						if len(ids) == 0 {
+							return io.EOF
+						}
+						row.ID, ids = ids[0], ids[1:]
+						return nil
+					},
+					CloseFunc: func() error { return nil },
+				}, nil
+			},
+		},
+		FindFunc: func(_ context.Context, _ interface{}) (driver.Rows, error) {
+			return &mock.Rows{
+				NextFunc:  func(*driver.Row) error { return io.EOF },
+				CloseFunc: func() error { return nil },
+			}, nil
+		},
+	}}
+}
+
+func (db *testDB) BulkDocs(_ context.Context, _ []interface{}, _ map[string]interface{}) (driver.BulkResults, error) {
+	return nil, errors.Status(kivik.StatusBadRequest, "bad request")
+}
+
+type span struct {
+	Name       string
+	Status     codes.Code
+	Attributes map[attribute.Key]interface{}
+}
+
+func TestInstrument(t *testing.T) {
+	recorder := tracetest.NewSpanRecorder()
+	provider := sdktrace.NewTracerProvider(sdktrace.WithSpanProcessor(recorder))
+	ctx := context.Background()
+	client, err := kivik.New(ctx, "otelkivik-mock", "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	Instrument(client, WithTracerProvider(provider))
+	db, err := client.DB(ctx, "foo")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := db.Get(ctx, "bar").Err; kivik.StatusCode(err) != kivik.StatusNotFound {
+		t.Fatalf("Unexpected error: %s", err)
+	}
+	rows, err := db.AllDocs(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for rows.Next() {
+	}
+	if err := rows.Err(); err != nil {
+		t.Fatal(err)
+	}
+
+	var spans []span
+	for _, s := range recorder.Ended() {
+		attrs := map[attribute.Key]interface{}{}
+		for _, kv := range s.Attributes() {
+			attrs[kv.Key] = kv.Value.AsInterface()
+		}
+		spans = append(spans, span{
+			Name:       s.Name(),
+			Status:     s.Status().Code,
+			Attributes: attrs,
+		})
+	}
+	expected := []span{
+		{
+			Name:   "kivik.Get",
+			Status: codes.Error,
+			Attributes: map[attribute.Key]interface{}{
+				"db.system":    "couchdb",
+				"db.operation": "Get",
+				"db.name":      "foo",
+				DocIDKey:       "bar",
+				StatusCodeKey:  int64(kivik.StatusNotFound),
+			},
+		},
+		{
+			Name:   "kivik.AllDocs",
+			Status: codes.Unset,
+			Attributes: map[attribute.Key]interface{}{
+				"db.system":    "couchdb",
+				"db.operation": "AllDocs",
+				"db.name":      "foo",
+				RowsKey:        int64(2),
+			},
+		},
+	}
+	if d := diff.Interface(expected, spans); d != nil {
+		t.Error(d)
+	}
+}
+
+func TestInstrumentOptional(t *testing.T) {
+	recorder := tracetest.NewSpanRecorder()
+	provider := sdktrace.NewTracerProvider(sdktrace.WithSpanProcessor(recorder))
+	ctx := context.Background()
+	client, err := kivik.New(ctx, "otelkivik-mock", "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	Instrument(client, WithTracerProvider(provider))
+	db, err := client.DB(ctx, "foo")
+	if err != nil {
+		t.Fatal(err)
+	}
+	rows, err := db.Find(ctx, map[string]interface{}{"selector": map[string]interface{}{}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	_ = rows.Close()
+	if _, err := db.BulkDocs(ctx, []interface{}{map[string]string{}}); kivik.StatusCode(err) != kivik.StatusBadRequest {
+		t.Fatalf("Unexpected error: %s", err)
+	}
+
+	var names []string
+	for _, s := range recorder.Ended() {
+		names = append(names, s.Name())
+	}
+	if d := diff.Interface([]string{"kivik.Find", "kivik.BulkDocs"}, names); d != nil {
+		t.Error(d)
+	}
+}
@@ -0,0 +1,283 @@
+package kivik
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"strings"
+
+	"github.com/go-kivik/kivik/driver"
+	"github.com/go-kivik/kivik/errors"
+)
+
+// AllDocsPager works like AllDocs, but fetches the results in pages of
+// pageSize rows, issuing successive requests as the returned Rows is
+// iterated. This avoids loading a large result set at once.
+//
+// Pages are requested with the startkey and startkey_docid options, as
+// recommended by the CouchDB documentation, so the "limit", "skip",
+// "startkey_docid", "start_key_doc_id" and "start_key" options may not be
+// used, and nor may "keys".
+//
+// See http://docs.couchdb.org/en/2.2.0/ddocs/views/pagination.html
+func (db *DB) AllDocsPager(ctx context.Context, pageSize int, options ...Options) (*Rows, error) {
+	opts, err := mergeOptions(options...)
+	if err != nil {
+		return nil, err
+	}
+	return newPager(ctx, db.client.codec(), &pager{
+		pageSize: pageSize,
+		options:  opts,
+		fetch:    db.fetchPage("AllDocs", db.driverDB.AllDocs),
+	})
+}
+
+// QueryPager works like Query, but fetches the results in pages of pageSize
+// rows, as described for AllDocsPager. Reduced views are not supported, as
+// their rows have no document IDs to page by; reading a reduced row fails
+// with StatusBadRequest.
+func (db *DB) QueryPager(ctx context.Context, ddoc, view string, pageSize int, options ...Options) (*Rows, error) {
+	ddoc = strings.TrimPrefix(ddoc, "_design/")
+	view = strings.TrimPrefix(view, "_view/")
+	opts, err := mergeOptions(options...)
+	if err != nil {
+		return nil, err
+	}
+	return newPager(ctx, db.client.codec(), &pager{
+		pageSize: pageSize,
+		options:  opts,
+		fetch: db.fetchPage("Query", func(ctx context.Context, opts map[string]interface{}) (driver.Rows, error) {
+			return db.driverDB.Query(ctx, ddoc, view, opts)
+		}),
+	})
+}
+
+// FindPager works like Find, but fetches the results in pages of pageSize
+// rows, as described for AllDocsPager. Pages are requested with the bookmark
+// returned with the previous page, so the query may not contain the "limit",
+// "skip" or "bookmark" fields. The driver's Find results must support
+// bookmarks.
+//
+// See http://docs.couchdb.org/en/2.2.0/api/database/find.html#pagination
+func (db *DB) FindPager(ctx context.Context, query interface{}, pageSize int) (*Rows, error) {
+	finder, ok := db.driverDB.(driver.Finder)
+	if !ok {
+		return nil, findNotImplemented
+	}
+	opts, err := findQuery(db.client.codec(), query)
+	if err != nil {
+		return nil, err
+	}
+	return newPager(ctx, db.client.codec(), &pager{
+		pageSize:  pageSize,
+		options:   opts,
+		bookmarks: true,
+		fetch: db.fetchPage("Find", func(ctx context.Context, query map[string]interface{}) (driver.Rows, error) {
+			return finder.Find(ctx, query)
+		}),
+	})
+}
+
+// findQuery returns query, a Find query, as a map of its fields.
+func findQuery(codec JSONCodec, query interface{}) (map[string]interface{}, error) {
+	if codec == nil {
+		codec = stdJSON{}
+	}
+	var data []byte
+	switch t := query.(type) {
+	case json.RawMessage:
+		data = t
+	case []byte:
+		data = t
+	case string:
+		data = []byte(t)
+	default:
+		var err error
+		if data, err = codec.Marshal(query); err != nil {
+			return nil, errors.WrapStatus(StatusBadRequest, err)
+		}
+	}
+	var fields map[string]interface{}
+	if err := codec.Unmarshal(data, &fields); err != nil || fields == nil {
+		return nil, errors.Status(StatusBadRequest, "kivik: query must be a JSON object")
+	}
+	return fields, nil
+}
+
+// fetchPage returns a function which fetches a page of results with fetch,
+// as the operation name, with hooks and retries.
+func (db *DB) fetchPage(name string, fetch func(context.Context, map[string]interface{}) (driver.Rows, error)) func(context.Context, map[string]interface{}) (driver.Rows, error) {
+	return func(ctx context.Context, opts map[string]interface{}) (driver.Rows, error) {
+		var rowsi driver.Rows
+		err := db.do(ctx, &Operation{Name: name, Options: opts}, false, func(ctx context.Context) error {
+			var e error
+			rowsi, e = fetch(ctx, opts)
+			return e
+		})
+		return rowsi, err
+	}
+}
+
+// errReducedPage is returned when a paged view returns a reduced row, which
+// has no document ID to page by.
+var errReducedPage = errors.Status(StatusBadRequest, "kivik: reduced views cannot be paged")
+
+// errNoBookmark is returned when a page of Find results has no bookmark with
+// which to request the next.
+var errNoBookmark = errors.Status(StatusNotImplemented, "kivik: driver does not support bookmarks")
+
+var (
+	pagerReservedOptions    = []string{"limit", "skip", "startkey_docid", "start_key_doc_id", "start_key", "keys"}
+	bookmarkReservedOptions = []string{"limit", "skip", "bookmark"}
+)
+
+// newPager returns the rows of p, once it has fetched its first page.
+func newPager(ctx context.Context, codec JSONCodec, p *pager) (*Rows, error) {
+	if p.pageSize <= 0 {
+		return nil, errors.Status(StatusBadRequest, "kivik: page size must be positive")
+	}
+	reserved := pagerReservedOptions
+	if p.bookmarks {
+		reserved = bookmarkReservedOptions
+	}
+	for _, name := range reserved {
+		if _, ok := p.options[name]; ok {
+			return nil, errors.Statusf(StatusBadRequest, "kivik: '%s' option may not be used when paging", name)
+		}
+	}
+	p.ctx = ctx
+	if err := p.nextPage(); err != nil {
+		return nil, err
+	}
+	return newRows(ctx, codec, p), nil
+}
+
+// pager is a driver.Rows which transparently fetches successive pages of
+// results.
+type pager struct {
+	ctx      context.Context
+	pageSize int
+	options  Options
+	fetch    func(context.Context, map[string]interface{}) (driver.Rows, error)
+	// bookmarks, if true, causes pages to be requested by the bookmark of
+	// the previous page, rather than by its last key and document ID.
+	bookmarks bool
+
+	// page is the current page. It is nil between pages.
+	page driver.Rows
+	// count is the number of rows read from the current page.
+	count    int
+	lastKey  string
+	lastID   string
+	bookmark string
+	done     bool
+
+	// offset is the offset of the first page, and last is the most recently
+	// exhausted page, from which the remaining metadata is read.
+	offset int64
+	last   driver.Rows
+}
+
+var _ driver.Rows = &pager{}
+
+func (p *pager) nextPage() error {
+	opts := make(map[string]interface{}, len(p.options)+3)
+	for k, v := range p.options {
+		opts[k] = v
+	}
+	opts["limit"] = p.pageSize
+	switch {
+	case p.last == nil:
+	case p.bookmarks:
+		opts["bookmark"] = p.bookmark
+	default:
+		opts["startkey"] = p.lastKey
+		opts["startkey_docid"] = p.lastID
+		opts["skip"] = 1
+	}
+	page, err := p.fetch(p.ctx, opts)
+	if err != nil {
+		return err
+	}
+	p.page = page
+	p.count = 0
+	return nil
+}
+
+func (p *pager) Next(row *driver.Row) error {
+	for {
+		if p.page == nil {
+			if p.done {
+				return io.EOF
+			}
+			if err := p.nextPage(); err != nil {
+				return err
+			}
+		}
+		err := p.page.Next(row)
+		if err == nil {
+			if row.ID == "" && !p.bookmarks {
+				return errReducedPage
+			}
+			p.count++
+			p.lastKey = string(row.Key)
+			p.lastID = row.ID
+			return nil
+		}
+		if err != io.EOF {
+			return err
+		}
+		if p.last == nil {
+			p.offset = p.page.Offset()
+		}
+		p.done = p.count < p.pageSize
+		if !p.done && p.bookmarks {
+			p.bookmark = bookmark(p.page)
+		}
+		p.last = p.page
+		p.page = nil
+		if e := p.last.Close(); e != nil {
+			return e
+		}
+		if !p.done && p.bookmarks && p.bookmark == "" {
+			p.done = true
+			return errNoBookmark
+		}
+	}
+}
+
+func (p *pager) Close() error {
+	p.done = true
+	if p.page == nil {
+		return nil
+	}
+	page := p.page
+	p.page = nil
+	return page.Close()
+}
+
+func (p *pager) Offset() int64 {
+	return p.offset
+}
+
+func (p *pager) TotalRows() int64 {
+	if p.last == nil {
+		return 0
+	}
+	return p.last.TotalRows()
+}
+
+func (p *pager) UpdateSeq() string {
+	if p.last == nil {
+		return ""
+	}
+	return p.last.UpdateSeq()
+}
+
+// bookmark returns the bookmark of rows, or "" if it has none.
+func bookmark(rows driver.Rows) string {
+	if b, ok := rows.(driver.Bookmarker); ok {
+		return b.Bookmark()
+	}
+	return ""
+}
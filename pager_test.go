@@ -0,0 +1,338 @@
+package kivik
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"testing"
+
+	"github.com/flimzy/diff"
+	"github.com/flimzy/testy"
+	"github.com/go-kivik/kivik/driver"
+	"github.com/go-kivik/kivik/mock"
+)
+
+// pagedRows returns a mock view result, paging through ids as requested by
+// the limit, skip and startkey_docid options. requests records the options
+// of each request.
+func pagedRows(ids []string, requests *[]map[string]interface{}) func(context.Context, map[string]interface{}) (driver.Rows, error) {
+	return func(_ context.Context, opts map[string]interface{}) (driver.Rows, error) {
+		*requests = append(*requests, opts)
+		start := 0
+		if startID, ok := opts["startkey_docid"].(string); ok {
+			for start < len(ids) && ids[start] != startID {
+				start++
+			}
+		}
+		if skip, ok := opts["skip"].(int); ok {
+			start += skip
+		}
+		end := start + opts["limit"].(int)
+		if end > len(ids) {
+			end = len(ids)
+		}
+		page := ids[start:end]
+		return &mock.Rows{
+			NextFunc: func(row *driver.Row) error {
+				if len(page) == 0 {
+					return io.EOF
+				}
+				*row = driver.Row{ID: page[0], Key: json.RawMessage(`"` + page[0] + `"`)}
+				page = page[1:]
+				return nil
+			},
+			CloseFunc:     func() error { return nil },
+			OffsetFunc:    func() int64 { return int64(start) },
+			TotalRowsFunc: func() int64 { return int64(len(ids)) },
+			UpdateSeqFunc: func() string { return "" },
+		}, nil
+	}
+}
+
+func TestAllDocsPager(t *testing.T) {
+	tests := []struct {
+		name     string
+		ids      []string
+		pageSize int
+		options  Options
+		expected []string
+		requests []map[string]interface{}
+		status   int
+		err      string
+	}{
+		{
+			name:   "invalid page size",
+			status: StatusBadRequest,
+			err:    "kivik: page size must be positive",
+		},
+		{
+			name:     "reserved option",
+			pageSize: 2,
+			options:  Options{"skip": 3},
+			status:   StatusBadRequest,
+			err:      "kivik: 'skip' option may not be used when paging",
+		},
+		{
+			name:     "start_key",
+			pageSize: 2,
+			options:  Options{"start_key": "a"},
+			status:   StatusBadRequest,
+			err:      "kivik: 'start_key' option may not be used when paging",
+		},
+		{
+			name:     "keys",
+			pageSize: 2,
+			options:  Options{"keys": []string{"a", "b"}},
+			status:   StatusBadRequest,
+			err:      "kivik: 'keys' option may not be used when paging",
+		},
+		{
+			name:     "partial last page",
+			ids:      []string{"a", "b", "c", "d", "e"},
+			pageSize: 2,
+			options:  Options{"include_docs": true},
+			expected: []string{"a", "b", "c", "d", "e"},
+			requests: []map[string]interface{}{
+				{"include_docs": true, "limit": 2},
+				{"include_docs": true, "limit": 2, "startkey": `"b"`, "startkey_docid": "b", "skip": 1},
+				{"include_docs": true, "limit": 2, "startkey": `"d"`, "startkey_docid": "d", "skip": 1},
+			},
+		},
+		{
+			name:     "full last page",
+			ids:      []string{"a", "b", "c", "d"},
+			pageSize: 2,
+			expected: []string{"a", "b", "c", "d"},
+			requests: []map[string]interface{}{
+				{"limit": 2},
+				{"limit": 2, "startkey": `"b"`, "startkey_docid": "b", "skip": 1},
+				{"limit": 2, "startkey": `"d"`, "startkey_docid": "d", "skip": 1},
+			},
+		},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			requests := []map[string]interface{}{}
+			db := &DB{
+				driverDB: &mock.DB{
+					AllDocsFunc: pagedRows(test.ids, &requests),
+				},
+			}
+			rows, err := db.AllDocsPager(context.Background(), test.pageSize, test.options)
+			testy.StatusError(t, test.err, test.status, err)
+			var result []string
+			for rows.Next() {
+				result = append(result, rows.ID())
+			}
+			if err := rows.Err(); err != nil {
+				t.Fatal(err)
+			}
+			if d := diff.Interface(test.expected, result); d != nil {
+				t.Error(d)
+			}
+			if d := diff.Interface(test.requests, requests); d != nil {
+				t.Error(d)
+			}
+			if total := rows.TotalRows(); total != int64(len(test.ids)) {
+				t.Errorf("Unexpected total rows: %d", total)
+			}
+		})
+	}
+}
+
+func TestQueryPager(t *testing.T) {
+	t.Run("query error", func(t *testing.T) {
+		db := &DB{
+			driverDB: &mock.DB{
+				QueryFunc: func(_ context.Context, _, _ string, _ map[string]interface{}) (driver.Rows, error) {
+					return nil, errors.New("query error")
+				},
+			},
+		}
+		_, err := db.QueryPager(context.Background(), "foo", "bar", 10)
+		testy.StatusError(t, "query error", StatusInternalServerError, err)
+	})
+	t.Run("success", func(t *testing.T) {
+		requests := []map[string]interface{}{}
+		fetch := pagedRows([]string{"a", "b", "c"}, &requests)
+		db := &DB{
+			driverDB: &mock.DB{
+				QueryFunc: func(ctx context.Context, ddoc, view string, opts map[string]interface{}) (driver.Rows, error) {
+					if ddoc != "foo" || view != "bar" {
+						return nil, fmt.Errorf("Unexpected view: %s/%s", ddoc, view)
+					}
+					return fetch(ctx, opts)
+				},
+			},
+		}
+		rows, err := db.QueryPager(context.Background(), "_design/foo", "_view/bar", 2)
+		if err != nil {
+			t.Fatal(err)
+		}
+		var result []string
+		for rows.Next() {
+			result = append(result, rows.ID())
+		}
+		if err := rows.Err(); err != nil {
+			t.Fatal(err)
+		}
+		if d := diff.Interface([]string{"a", "b", "c"}, result); d != nil {
+			t.Error(d)
+		}
+		if len(requests) != 2 {
+			t.Errorf("Unexpected number of requests: %d", len(requests))
+		}
+	})
+	t.Run("reduced", func(t *testing.T) {
+		db := &DB{
+			driverDB: &mock.DB{
+				QueryFunc: func(_ context.Context, _, _ string, _ map[string]interface{}) (driver.Rows, error) {
+					return &mock.Rows{
+						NextFunc: func(row *driver.Row) error {
+							*row = driver.Row{Key: json.RawMessage(`null`), Value: json.RawMessage(`3`)}
+							return nil
+						},
+						CloseFunc:  func() error { return nil },
+						OffsetFunc: func() int64 { return 0 },
+					}, nil
+				},
+			},
+		}
+		rows, err := db.QueryPager(context.Background(), "foo", "bar", 2)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if rows.Next() {
+			t.Error("Expected no rows")
+		}
+		testy.StatusError(t, "kivik: reduced views cannot be paged", StatusBadRequest, rows.Err())
+	})
+}
+
+// bookmarkedRows returns a mock Find result, paging through ids as requested
+// by the limit and bookmark fields. If bookmarks is false, no bookmark is
+// returned.
+func bookmarkedRows(ids []string, bookmarks bool, requests *[]map[string]interface{}) func(context.Context, interface{}) (driver.Rows, error) {
+	return func(_ context.Context, query interface{}) (driver.Rows, error) {
+		opts := query.(map[string]interface{})
+		*requests = append(*requests, opts)
+		start := 0
+		if bookmark, ok := opts["bookmark"].(string); ok {
+			for start < len(ids) && ids[start] != bookmark {
+				start++
+			}
+			start++
+		}
+		end := start + opts["limit"].(int)
+		if end > len(ids) {
+			end = len(ids)
+		}
+		page := ids[start:end]
+		rows := &mock.Rows{
+			NextFunc: func(row *driver.Row) error {
+				if len(page) == 0 {
+					return io.EOF
+				}
+				*row = driver.Row{ID: page[0]}
+				page = page[1:]
+				return nil
+			},
+			CloseFunc:     func() error { return nil },
+			OffsetFunc:    func() int64 { return 0 },
+			TotalRowsFunc: func() int64 { return 0 },
+		}
+		if !bookmarks {
+			return rows, nil
+		}
+		return &mock.Bookmarker{
+			Rows: rows,
+			BookmarkFunc: func() string {
+				if end == 0 {
+					return "nil"
+				}
+				return ids[end-1]
+			},
+		}, nil
+	}
+}
+
+func TestFindPager(t *testing.T) {
+	tests := []struct {
+		name      string
+		ids       []string
+		bookmarks bool
+		query     interface{}
+		expected  []string
+		requests  []map[string]interface{}
+		status    int
+		err       string
+	}{
+		{
+			name:   "invalid query",
+			query:  `[]`,
+			status: StatusBadRequest,
+			err:    "kivik: query must be a JSON object",
+		},
+		{
+			name:   "reserved field",
+			query:  map[string]interface{}{"selector": map[string]interface{}{}, "bookmark": "foo"},
+			status: StatusBadRequest,
+			err:    "kivik: 'bookmark' option may not be used when paging",
+		},
+		{
+			name:      "success",
+			ids:       []string{"a", "b", "c"},
+			bookmarks: true,
+			query:     `{"selector":{}}`,
+			expected:  []string{"a", "b", "c"},
+			requests: []map[string]interface{}{
+				{"selector": map[string]interface{}{}, "limit": 2},
+				{"selector": map[string]interface{}{}, "limit": 2, "bookmark": "b"},
+			},
+		},
+		{
+			name:     "no bookmarks",
+			ids:      []string{"a", "b", "c"},
+			query:    json.RawMessage(`{"selector":{}}`),
+			expected: []string{"a", "b"},
+			requests: []map[string]interface{}{
+				{"selector": map[string]interface{}{}, "limit": 2},
+			},
+			status: StatusNotImplemented,
+			err:    "kivik: driver does not support bookmarks",
+		},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			requests := []map[string]interface{}{}
+			db := &DB{
+				driverDB: &mock.Finder{
+					FindFunc: bookmarkedRows(test.ids, test.bookmarks, &requests),
+				},
+			}
+			rows, err := db.FindPager(context.Background(), test.query, 2)
+			if err != nil {
+				testy.StatusError(t, test.err, test.status, err)
+				return
+			}
+			var result []string
+			for rows.Next() {
+				result = append(result, rows.ID())
+			}
+			testy.StatusError(t, test.err, test.status, rows.Err())
+			if d := diff.Interface(test.expected, result); d != nil {
+				t.Error(d)
+			}
+			if d := diff.Interface(test.requests, requests); d != nil {
+				t.Error(d)
+			}
+		})
+	}
+	t.Run("not implemented", func(t *testing.T) {
+		db := &DB{driverDB: &mock.DB{}}
+		_, err := db.FindPager(context.Background(), `{}`, 2)
+		testy.StatusError(t, "kivik: driver does not support Find interface", StatusNotImplemented, err)
+	})
+}
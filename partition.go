@@ -0,0 +1,134 @@
+package kivik
+
+import (
+	"context"
+	"encoding/json"
+	"strings"
+
+	"github.com/go-kivik/kivik/driver"
+	"github.com/go-kivik/kivik/errors"
+)
+
+var partitionNotImplemented = errors.Status(StatusNotImplemented, "kivik: driver does not support partitioned databases")
+
+// PartitionStats contains partition statistics.
+type PartitionStats struct {
+	// DBName is the name of the database.
+	DBName string `json:"db_name"`
+	// DocCount is the number of documents in the partition.
+	DocCount int64 `json:"doc_count"`
+	// DeletedDocCount is the number of deleted documents in the partition.
+	DeletedDocCount int64 `json:"doc_del_count"`
+	// Partition is the name of the partition.
+	Partition string `json:"partition"`
+	// ActiveSize is the number of bytes used on-disk to store active
+	// documents in the partition.
+	ActiveSize int64 `json:"-"`
+	// ExternalSize is the size of the documents in the partition, as
+	// represented as JSON, before compression.
+	ExternalSize int64 `json:"-"`
+}
+
+// UnmarshalJSON satisfies the json.Unmarshaler interface. It reads the active
+// and external sizes from the sizes object, as returned by CouchDB.
+func (s *PartitionStats) UnmarshalJSON(data []byte) error {
+	var stats driver.PartitionStats
+	if err := json.Unmarshal(data, &stats); err != nil {
+		return err
+	}
+	*s = PartitionStats(stats)
+	return nil
+}
+
+// validatePartitionedOption ensures that the "partitioned" option, which may
+// be passed to CreateDB, is a bool, if set.
+func validatePartitionedOption(opts Options) error {
+	if p, ok := opts["partitioned"]; ok {
+		if _, ok := p.(bool); !ok {
+			return errors.Statusf(StatusBadRequest, "kivik: invalid type %T for 'partitioned' option", p)
+		}
+	}
+	return nil
+}
+
+func (db *DB) partitioner(partition string) (driver.Partitioner, error) {
+	p, ok := db.driverDB.(driver.Partitioner)
+	if !ok {
+		return nil, partitionNotImplemented
+	}
+	if partition == "" {
+		return nil, missingArg("partition")
+	}
+	if strings.HasPrefix(partition, "_") {
+		return nil, errors.Statusf(StatusBadRequest, "kivik: invalid partition name '%s'", partition)
+	}
+	return p, nil
+}
+
+// PartitionStats returns statistics about the named partition.
+//
+// See https://docs.couchdb.org/en/stable/api/partitioned-dbs.html#db-partition-partition
+func (db *DB) PartitionStats(ctx context.Context, name string) (*PartitionStats, error) {
+	p, err := db.partitioner(name)
+	if err != nil {
+		return nil, err
+	}
+	var stats *driver.PartitionStats
+	err = db.do(ctx, &Operation{Name: "PartitionStats"}, false, func(ctx context.Context) error {
+		var e error
+		stats, e = p.PartitionStats(ctx, name)
+		return e
+	})
+	if err != nil {
+		return nil, err
+	}
+	s := PartitionStats(*stats)
+	return &s, nil
+}
+
+// PartitionAllDocs returns a list of all documents in the named partition.
+// It accepts the same options as AllDocs.
+func (db *DB) PartitionAllDocs(ctx context.Context, partition string, options ...Options) (*Rows, error) {
+	p, err := db.partitioner(partition)
+	if err != nil {
+		return nil, err
+	}
+	opts, err := mergeOptions(options...)
+	if err != nil {
+		return nil, err
+	}
+	return db.doRows(ctx, &Operation{Name: "PartitionAllDocs", Options: opts}, func(ctx context.Context) (driver.Rows, error) {
+		return p.PartitionAllDocs(ctx, partition, opts)
+	})
+}
+
+// PartitionQuery executes the specified view function from the specified
+// design document, limited to the named partition. It is otherwise identical
+// to Query.
+func (db *DB) PartitionQuery(ctx context.Context, partition, ddoc, view string, options ...Options) (*Rows, error) {
+	p, err := db.partitioner(partition)
+	if err != nil {
+		return nil, err
+	}
+	opts, err := mergeOptions(options...)
+	if err != nil {
+		return nil, err
+	}
+	ddoc = strings.TrimPrefix(ddoc, "_design/")
+	view = strings.TrimPrefix(view, "_view/")
+	return db.doRows(ctx, &Operation{Name: "PartitionQuery", Options: opts}, func(ctx context.Context) (driver.Rows, error) {
+		return p.PartitionQuery(ctx, partition, ddoc, view, opts)
+	})
+}
+
+// PartitionFind executes a query using the /_find interface, limited to the
+// named partition. It is otherwise identical to Find.
+func (db *DB) PartitionFind(ctx context.Context, partition string, query interface{}) (*Rows, error) {
+	p, err := db.partitioner(partition)
+	if err != nil {
+		return nil, err
+	}
+	return db.doRows(ctx, &Operation{Name: "PartitionFind"}, func(ctx context.Context) (driver.Rows, error) {
+		return p.PartitionFind(ctx, partition, query)
+	})
+}
@@ -0,0 +1,299 @@
+package kivik
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"testing"
+
+	"github.com/flimzy/diff"
+	"github.com/flimzy/testy"
+	"github.com/go-kivik/kivik/driver"
+	"github.com/go-kivik/kivik/mock"
+)
+
+func TestPartitionStats(t *testing.T) {
+	tests := []struct {
+		name      string
+		db        *DB
+		partition string
+		expected  *PartitionStats
+		status    int
+		err       string
+	}{
+		{
+			name:      "non-partitioner",
+			db:        &DB{driverDB: &mock.DB{}},
+			partition: "foo",
+			status:    StatusNotImplemented,
+			err:       "kivik: driver does not support partitioned databases",
+		},
+		{
+			name:   "missing partition",
+			db:     &DB{driverDB: &mock.Partitioner{}},
+			status: StatusBadRequest,
+			err:    "kivik: partition required",
+		},
+		{
+			name:      "invalid partition",
+			db:        &DB{driverDB: &mock.Partitioner{}},
+			partition: "_foo",
+			status:    StatusBadRequest,
+			err:       "kivik: invalid partition name '_foo'",
+		},
+		{
+			name: "db error",
+			db: &DB{
+				driverDB: &mock.Partitioner{
+					PartitionStatsFunc: func(_ context.Context, _ string) (*driver.PartitionStats, error) {
+						return nil, errors.New("db error")
+					},
+				},
+			},
+			partition: "foo",
+			status:    StatusInternalServerError,
+			err:       "db error",
+		},
+		{
+			name: "success",
+			db: &DB{
+				driverDB: &mock.Partitioner{
+					PartitionStatsFunc: func(_ context.Context, name string) (*driver.PartitionStats, error) {
+						if name != "foo" {
+							return nil, fmt.Errorf("Unexpected partition: %s", name)
+						}
+						return &driver.PartitionStats{DBName: "db", Partition: "foo", DocCount: 3}, nil
+					},
+				},
+			},
+			partition: "foo",
+			expected:  &PartitionStats{DBName: "db", Partition: "foo", DocCount: 3},
+		},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			result, err := test.db.PartitionStats(context.Background(), test.partition)
+			testy.StatusError(t, test.err, test.status, err)
+			if d := diff.Interface(test.expected, result); d != nil {
+				t.Error(d)
+			}
+		})
+	}
+}
+
+func TestPartitionStatsUnmarshal(t *testing.T) {
+	var stats PartitionStats
+	if err := json.Unmarshal([]byte(`{"db_name":"foo","partition":"bar","sizes":{"active":120,"external":80}}`), &stats); err != nil {
+		t.Fatal(err)
+	}
+	expected := PartitionStats{DBName: "foo", Partition: "bar", ActiveSize: 120, ExternalSize: 80}
+	if d := diff.Interface(expected, stats); d != nil {
+		t.Error(d)
+	}
+}
+
+func TestPartitionAllDocs(t *testing.T) {
+	tests := []struct {
+		name     string
+		db       *DB
+		options  Options
+		expected *Rows
+		status   int
+		err      string
+	}{
+		{
+			name:   "non-partitioner",
+			db:     &DB{driverDB: &mock.DB{}},
+			status: StatusNotImplemented,
+			err:    "kivik: driver does not support partitioned databases",
+		},
+		{
+			name: "db error",
+			db: &DB{
+				driverDB: &mock.Partitioner{
+					PartitionAllDocsFunc: func(_ context.Context, _ string, _ map[string]interface{}) (driver.Rows, error) {
+						return nil, errors.New("db error")
+					},
+				},
+			},
+			status: StatusInternalServerError,
+			err:    "db error",
+		},
+		{
+			name: "success",
+			db: &DB{
+				driverDB: &mock.Partitioner{
+					PartitionAllDocsFunc: func(_ context.Context, partition string, opts map[string]interface{}) (driver.Rows, error) {
+						if partition != "foo" {
+							return nil, fmt.Errorf("Unexpected partition: %s", partition)
+						}
+						if d := diff.Interface(testOptions, opts); d != nil {
+							return nil, fmt.Errorf("Unexpected options:\n%s", d)
+						}
+						return &mock.Rows{ID: "a"}, nil
+					},
+				},
+			},
+			options: testOptions,
+			expected: &Rows{
+				iter: &iter{
+					feed: &rowsIterator{
+						Rows: &mock.Rows{ID: "a"},
+					},
+					curVal: &driver.Row{},
+				},
+				rowsi: &mock.Rows{ID: "a"},
+			},
+		},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			result, err := test.db.PartitionAllDocs(context.Background(), "foo", test.options)
+			testy.StatusError(t, test.err, test.status, err)
+			result.cancel = nil // Determinism
+			if d := diff.Interface(test.expected, result); d != nil {
+				t.Error(d)
+			}
+		})
+	}
+}
+
+func TestPartitionQuery(t *testing.T) {
+	tests := []struct {
+		name     string
+		db       *DB
+		ddoc     string
+		view     string
+		options  Options
+		expected *Rows
+		status   int
+		err      string
+	}{
+		{
+			name:   "non-partitioner",
+			db:     &DB{driverDB: &mock.DB{}},
+			status: StatusNotImplemented,
+			err:    "kivik: driver does not support partitioned databases",
+		},
+		{
+			name: "db error",
+			db: &DB{
+				driverDB: &mock.Partitioner{
+					PartitionQueryFunc: func(_ context.Context, _, _, _ string, _ map[string]interface{}) (driver.Rows, error) {
+						return nil, errors.New("db error")
+					},
+				},
+			},
+			status: StatusInternalServerError,
+			err:    "db error",
+		},
+		{
+			name: "success",
+			db: &DB{
+				driverDB: &mock.Partitioner{
+					PartitionQueryFunc: func(_ context.Context, partition, ddoc, view string, opts map[string]interface{}) (driver.Rows, error) {
+						if partition != "foo" {
+							return nil, fmt.Errorf("Unexpected partition: %s", partition)
+						}
+						if ddoc != "ddoc" || view != "view" {
+							return nil, fmt.Errorf("Unexpected view: %s/%s", ddoc, view)
+						}
+						if d := diff.Interface(testOptions, opts); d != nil {
+							return nil, fmt.Errorf("Unexpected options:\n%s", d)
+						}
+						return &mock.Rows{ID: "a"}, nil
+					},
+				},
+			},
+			ddoc:    "_design/ddoc",
+			view:    "_view/view",
+			options: testOptions,
+			expected: &Rows{
+				iter: &iter{
+					feed: &rowsIterator{
+						Rows: &mock.Rows{ID: "a"},
+					},
+					curVal: &driver.Row{},
+				},
+				rowsi: &mock.Rows{ID: "a"},
+			},
+		},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			result, err := test.db.PartitionQuery(context.Background(), "foo", test.ddoc, test.view, test.options)
+			testy.StatusError(t, test.err, test.status, err)
+			result.cancel = nil // Determinism
+			if d := diff.Interface(test.expected, result); d != nil {
+				t.Error(d)
+			}
+		})
+	}
+}
+
+func TestPartitionFind(t *testing.T) {
+	tests := []struct {
+		name     string
+		db       *DB
+		query    interface{}
+		expected *Rows
+		status   int
+		err      string
+	}{
+		{
+			name:   "non-partitioner",
+			db:     &DB{driverDB: &mock.DB{}},
+			status: StatusNotImplemented,
+			err:    "kivik: driver does not support partitioned databases",
+		},
+		{
+			name: "db error",
+			db: &DB{
+				driverDB: &mock.Partitioner{
+					PartitionFindFunc: func(_ context.Context, _ string, _ interface{}) (driver.Rows, error) {
+						return nil, errors.New("db error")
+					},
+				},
+			},
+			status: StatusInternalServerError,
+			err:    "db error",
+		},
+		{
+			name: "success",
+			db: &DB{
+				driverDB: &mock.Partitioner{
+					PartitionFindFunc: func(_ context.Context, partition string, query interface{}) (driver.Rows, error) {
+						if partition != "foo" {
+							return nil, fmt.Errorf("Unexpected partition: %s", partition)
+						}
+						if d := diff.Interface(int(3), query); d != nil {
+							return nil, fmt.Errorf("Unexpected query:\n%s", d)
+						}
+						return &mock.Rows{ID: "a"}, nil
+					},
+				},
+			},
+			query: int(3),
+			expected: &Rows{
+				iter: &iter{
+					feed: &rowsIterator{
+						Rows: &mock.Rows{ID: "a"},
+					},
+					curVal: &driver.Row{},
+				},
+				rowsi: &mock.Rows{ID: "a"},
+			},
+		},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			result, err := test.db.PartitionFind(context.Background(), "foo", test.query)
+			testy.StatusError(t, test.err, test.status, err)
+			result.cancel = nil // Determinism
+			if d := diff.Interface(test.expected, result); d != nil {
+				t.Error(d)
+			}
+		})
+	}
+}
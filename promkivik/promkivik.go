@@ -0,0 +1,102 @@
+// Package promkivik provides Prometheus metrics for Kivik clients.
+//
+// A Collector is registered as a hook on one or more Kivik clients, and with
+// a Prometheus registry, to record the number of operations, errors by status
+// code, and operation latency, labeled by operation and database name.
+//
+// As the Prometheus client requires a recent version of Go, this package is
+// not subject to the Go version requirements of Kivik itself.
+package promkivik
+
+import (
+	"context"
+	"strconv"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+
+	"github.com/go-kivik/kivik"
+)
+
+// Collector records metrics for Kivik operations. It implements both
+// kivik.Hook and prometheus.Collector.
+type Collector struct {
+	operations *prometheus.CounterVec
+	errors     *prometheus.CounterVec
+	duration   *prometheus.HistogramVec
+}
+
+var (
+	_ kivik.Hook           = &Collector{}
+	_ prometheus.Collector = &Collector{}
+)
+
+// NewCollector returns a new Collector. namespace, if not empty, is prefixed
+// to the metric names, which are:
+//
+//	kivik_operations_total{operation, db}
+//	kivik_operation_errors_total{operation, db, status}
+//	kivik_operation_duration_seconds{operation, db}
+//
+// buckets are the latency histogram buckets, in seconds. If none are given,
+// prometheus.DefBuckets is used.
+func NewCollector(namespace string, buckets ...float64) *Collector {
+	if len(buckets) == 0 {
+		buckets = prometheus.DefBuckets
+	}
+	return &Collector{
+		operations: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Namespace: namespace,
+			Subsystem: "kivik",
+			Name:      "operations_total",
+			Help:      "Total number of Kivik operations.",
+		}, []string{"operation", "db"}),
+		errors: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Namespace: namespace,
+			Subsystem: "kivik",
+			Name:      "operation_errors_total",
+			Help:      "Total number of failed Kivik operations, by status code.",
+		}, []string{"operation", "db", "status"}),
+		duration: prometheus.NewHistogramVec(prometheus.HistogramOpts{
+			Namespace: namespace,
+			Subsystem: "kivik",
+			Name:      "operation_duration_seconds",
+			Help:      "Latency of Kivik operations.",
+			Buckets:   buckets,
+		}, []string{"operation", "db"}),
+	}
+}
+
+// Instrument registers c as a hook on client. It should be called before the
+// client is used.
+func (c *Collector) Instrument(client *kivik.Client) {
+	client.AddHook(c)
+}
+
+// Before satisfies the kivik.Hook interface.
+func (c *Collector) Before(ctx context.Context, _ *kivik.Operation) context.Context {
+	return ctx
+}
+
+// After satisfies the kivik.Hook interface.
+func (c *Collector) After(_ context.Context, op *kivik.Operation, duration time.Duration, err error) {
+	c.operations.WithLabelValues(op.Name, op.DB).Inc()
+	c.duration.WithLabelValues(op.Name, op.DB).Observe(duration.Seconds())
+	if err != nil {
+		c.errors.WithLabelValues(op.Name, op.DB, strconv.Itoa(kivik.StatusCode(err))).Inc()
+	}
+}
+
+// Describe satisfies the prometheus.Collector interface.
+func (c *Collector) Describe(ch chan<- *prometheus.Desc) {
+	c.operations.Describe(ch)
+	c.errors.Describe(ch)
+	c.duration.Describe(ch)
+}
+
+// Collect satisfies the prometheus.Collector interface.
+func (c *Collector) Collect(ch chan<- prometheus.Metric) {
+	c.operations.Collect(ch)
+	c.errors.Collect(ch)
+	c.duration.Collect(ch)
+}
@@ -0,0 +1,132 @@
+package promkivik
+
+import (
+	"context"
+	"io"
+	"strings"
+	"testing"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+
+	"github.com/go-kivik/kivik"
+	"github.com/go-kivik/kivik/driver"
+	"github.com/go-kivik/kivik/errors"
+	"github.com/go-kivik/kivik/mock"
+)
+
+func init() {
+	kivik.Register("promkivik-mock", &mock.Driver{
+		NewClientFunc: func(_ context.Context, _ string) (driver.Client, error) {
+			return &mock.Client{
+				DBFunc: func(_ context.Context, _ string, _ map[string]interface{}) (driver.DB, error) {
+					return newTestDB(), nil
+				},
+			}, nil
+		},
+	})
+}
+
+// testDB is a mock database which also supports Find and BulkDocs.
+type testDB struct {
+	*mock.Finder
+}
+
+var _ driver.BulkDocer = &testDB{}
+
+func newTestDB() *testDB {
+	return &testDB{Finder: &mock.Finder{
+		DB: &mock.DB{
+			PutFunc: func(_ context.Context, docID string, _ interface{}, _ map[string]interface{}) (string, error) {
+				if docID == "conflict" {
+					return "", errors.Status(kivik.StatusConflict, "conflict")
+				}
+				return "1-xxx", nil
+			},
+		},
+		FindFunc: func(_ context.Context, _ interface{}) (driver.Rows, error) {
+			return &mock.Rows{
+				NextFunc:  func(*driver.Row) error { return io.EOF },
+				CloseFunc: func() error { return nil },
+			}, nil
+		},
+	}}
+}
+
+func (db *testDB) BulkDocs(_ context.Context, _ []interface{}, _ map[string]interface{}) (driver.BulkResults, error) {
+	return nil, errors.Status(kivik.StatusBadRequest, "bad request")
+}
+
+func TestCollector(t *testing.T) {
+	ctx := context.Background()
+	client, err := kivik.New(ctx, "promkivik-mock", "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	collector := NewCollector("test")
+	collector.Instrument(client)
+	registry := prometheus.NewPedanticRegistry()
+	if err := registry.Register(collector); err != nil {
+		t.Fatal(err)
+	}
+	db, err := client.DB(ctx, "foo")
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, id := range []string{"a", "b", "conflict"} {
+		_, _ = db.Put(ctx, id, map[string]string{})
+	}
+	expected := `
+# HELP test_kivik_operation_errors_total Total number of failed Kivik operations, by status code.
+# TYPE test_kivik_operation_errors_total counter
+test_kivik_operation_errors_total{db="foo",operation="Put",status="409"} 1
+# HELP test_kivik_operations_total Total number of Kivik operations.
+# TYPE test_kivik_operations_total counter
+test_kivik_operations_total{db="foo",operation="Put"} 3
+`
+	if err := testutil.GatherAndCompare(registry, strings.NewReader(expected), "test_kivik_operations_total", "test_kivik_operation_errors_total"); err != nil {
+		t.Error(err)
+	}
+	if n := testutil.CollectAndCount(collector, "test_kivik_operation_duration_seconds"); n != 1 {
+		t.Errorf("Expected 1 duration series, got %d", n)
+	}
+}
+
+func TestCollectorOptional(t *testing.T) {
+	ctx := context.Background()
+	client, err := kivik.New(ctx, "promkivik-mock", "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	collector := NewCollector("test")
+	collector.Instrument(client)
+	registry := prometheus.NewPedanticRegistry()
+	if err := registry.Register(collector); err != nil {
+		t.Fatal(err)
+	}
+	db, err := client.DB(ctx, "foo")
+	if err != nil {
+		t.Fatal(err)
+	}
+	rows, err := db.Find(ctx, map[string]interface{}{"selector": map[string]interface{}{}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	_ = rows.Close()
+	_, _ = db.BulkDocs(ctx, []interface{}{map[string]string{}})
+	expected := `
+# HELP test_kivik_operation_errors_total Total number of failed Kivik operations, by status code.
+# TYPE test_kivik_operation_errors_total counter
+test_kivik_operation_errors_total{db="foo",operation="BulkDocs",status="400"} 1
+# HELP test_kivik_operations_total Total number of Kivik operations.
+# TYPE test_kivik_operations_total counter
+test_kivik_operations_total{db="foo",operation="BulkDocs"} 1
+test_kivik_operations_total{db="foo",operation="Find"} 1
+`
+	if err := testutil.GatherAndCompare(registry, strings.NewReader(expected), "test_kivik_operations_total", "test_kivik_operation_errors_total"); err != nil {
+		t.Error(err)
+	}
+	if n := testutil.CollectAndCount(collector, "test_kivik_operation_duration_seconds"); n != 2 {
+		t.Errorf("Expected 2 duration series, got %d", n)
+	}
+}
@@ -0,0 +1,355 @@
+// Package replicate implements the CouchDB replication protocol in Go, to
+// replicate documents between any two kivik databases, regardless of driver.
+//
+// Replication proceeds in batches: changes are read from the source, the
+// revisions the target lacks are identified with RevsDiff, fetched from the
+// source with BulkGet, and written to the target with BulkDocs, preserving
+// their revision history. After each batch, a checkpoint is recorded in a
+// _local document on both databases, so that an interrupted replication may
+// resume where it left off.
+//
+// See http://docs.couchdb.org/en/2.1.1/replication/protocol.html
+package replicate
+
+import (
+	"context"
+	"crypto/md5"
+	"crypto/rand"
+	"encoding/hex"
+	"fmt"
+	"time"
+
+	"github.com/go-kivik/kivik"
+	"github.com/go-kivik/kivik/errors"
+)
+
+// DefaultBatchSize is the number of changes processed per batch, if not set
+// in the Config.
+const DefaultBatchSize = 100
+
+// Config configures a replication. A nil *Config uses the defaults.
+type Config struct {
+	// ID identifies the replication, and names the _local checkpoint
+	// documents. It defaults to a hash of the source and target DSNs and
+	// database names.
+	ID string
+	// Continuous causes replication to continue, waiting for new changes on
+	// the source, until the context is cancelled.
+	Continuous bool
+	// BatchSize is the maximum number of changes processed per batch.
+	BatchSize int
+	// ChangesOptions are passed to the source's Changes method, and may be
+	// used, for example, to apply a server-side filter.
+	ChangesOptions kivik.Options
+	// Filter, if set, selects the changes to replicate on the client. The
+	// changes feed is then read with include_docs set, so that Filter may
+	// inspect each document.
+	Filter func(change kivik.ChangeEvent) bool
+}
+
+// Result reports the outcome of a replication.
+type Result struct {
+	StartTime time.Time
+	EndTime   time.Time
+	// LastSeq is the last source sequence replicated.
+	LastSeq string
+	// MissingChecked is the number of revisions checked against the target.
+	MissingChecked int64
+	// MissingFound is the number of revisions found to be missing on the
+	// target.
+	MissingFound int64
+	// DocsRead is the number of document revisions read from the source.
+	DocsRead int64
+	// DocsWritten is the number of document revisions written to the target.
+	DocsWritten int64
+	// DocWriteFailures is the number of document revisions which could not
+	// be read from the source, or written to the target.
+	DocWriteFailures int64
+}
+
+// checkpoint is the content of a _local checkpoint document.
+type checkpoint struct {
+	Rev           string `json:"_rev,omitempty"`
+	SessionID     string `json:"session_id"`
+	SourceLastSeq string `json:"source_last_seq"`
+}
+
+type replicator struct {
+	target, source *kivik.DB
+	id             string
+	sessionID      string
+	continuous     bool
+	batchSize      int
+	options        kivik.Options
+	filter         *kivik.ChangesFilter
+	result         *Result
+
+	targetRev, sourceRev string
+}
+
+// Replicate replicates all changes from source to target. One-shot
+// replication returns once the target is up to date. Continuous replication
+// returns once ctx is cancelled, without error.
+//
+// The source must support Changes, and the target RevsDiff. BulkGet and
+// BulkDocs are emulated if necessary.
+func Replicate(ctx context.Context, target, source *kivik.DB, config *Config) (*Result, error) {
+	r := &replicator{
+		target:    target,
+		source:    source,
+		batchSize: DefaultBatchSize,
+		result:    &Result{StartTime: time.Now()},
+	}
+	if config != nil {
+		r.id = config.ID
+		r.continuous = config.Continuous
+		if config.BatchSize > 0 {
+			r.batchSize = config.BatchSize
+		}
+		r.options = config.ChangesOptions
+		if config.Filter != nil {
+			r.filter = &kivik.ChangesFilter{Match: config.Filter}
+		}
+	}
+	if r.id == "" {
+		r.id = replicationID(target, source)
+	}
+	sessionID, err := newSessionID()
+	if err != nil {
+		return nil, err
+	}
+	r.sessionID = sessionID
+	err = r.run(ctx)
+	r.result.EndTime = time.Now()
+	if r.continuous && ctx.Err() != nil {
+		err = nil
+	}
+	return r.result, err
+}
+
+func (r *replicator) run(ctx context.Context) error {
+	since, err := r.readCheckpoints(ctx)
+	if err != nil {
+		return err
+	}
+	r.result.LastSeq = since
+	feed := "normal"
+	for {
+		lastSeq, err := r.batch(ctx, since, feed)
+		if err != nil {
+			return err
+		}
+		if lastSeq == "" || lastSeq == since {
+			// Caught up with the source.
+			if !r.continuous {
+				return nil
+			}
+			feed = "longpoll"
+			continue
+		}
+		if err := r.writeCheckpoints(ctx, lastSeq); err != nil {
+			return err
+		}
+		since = lastSeq
+		r.result.LastSeq = lastSeq
+	}
+}
+
+// batch replicates up to batchSize changes after since, returning the
+// sequence of the last change read.
+func (r *replicator) batch(ctx context.Context, since, feed string) (string, error) {
+	opts := kivik.Options{
+		"feed":  feed,
+		"style": "all_docs",
+		"limit": r.batchSize,
+	}
+	if since != "" {
+		opts["since"] = since
+	}
+	if r.filter != nil {
+		opts["include_docs"] = true
+	}
+	changes, err := r.source.FilterChanges(ctx, r.filter, r.options, opts)
+	if err != nil {
+		return "", err
+	}
+	defer changes.Close() // nolint: errcheck
+	revMap := make(map[string][]string)
+	var lastSeq string
+	for changes.Next() {
+		revMap[changes.ID()] = append(revMap[changes.ID()], changes.Changes()...)
+		r.result.MissingChecked += int64(len(changes.Changes()))
+		lastSeq = string(changes.Seq())
+	}
+	if err := changes.Err(); err != nil {
+		return "", err
+	}
+	if seq := changes.LastSeq(); seq != "" {
+		lastSeq = string(seq)
+	}
+	if len(revMap) == 0 {
+		return lastSeq, nil
+	}
+	missing, err := r.revsDiff(ctx, revMap)
+	if err != nil {
+		return "", err
+	}
+	if len(missing) == 0 {
+		return lastSeq, nil
+	}
+	docs, err := r.fetch(ctx, missing)
+	if err != nil {
+		return "", err
+	}
+	return lastSeq, r.write(ctx, docs)
+}
+
+// revsDiff returns references to the revisions in revMap missing from the
+// target.
+func (r *replicator) revsDiff(ctx context.Context, revMap map[string][]string) ([]kivik.BulkGetReference, error) {
+	rows, err := r.target.RevsDiff(ctx, revMap)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close() // nolint: errcheck
+	var refs []kivik.BulkGetReference
+	for rows.Next() {
+		var diff kivik.RevDiff
+		if err := rows.ScanValue(&diff); err != nil {
+			return nil, err
+		}
+		for _, rev := range diff.Missing {
+			refs = append(refs, kivik.BulkGetReference{
+				ID:        rows.ID(),
+				Rev:       rev,
+				AttsSince: diff.PossibleAncestors,
+			})
+		}
+	}
+	r.result.MissingFound += int64(len(refs))
+	return refs, rows.Err()
+}
+
+// fetch reads the referenced revisions from the source, with their revision
+// histories and attachments.
+func (r *replicator) fetch(ctx context.Context, refs []kivik.BulkGetReference) ([]interface{}, error) {
+	rows, err := r.source.BulkGet(ctx, refs, kivik.Options{
+		"revs":        true,
+		"attachments": true,
+	})
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close() // nolint: errcheck
+	docs := make([]interface{}, 0, len(refs))
+	for rows.Next() {
+		var doc map[string]interface{}
+		if err := rows.ScanDoc(&doc); err != nil {
+			if kivik.StatusCode(err) == kivik.StatusNotFound {
+				// The revision was removed since the change was read, such
+				// as by compaction.
+				r.result.DocWriteFailures++
+				continue
+			}
+			return nil, err
+		}
+		r.result.DocsRead++
+		docs = append(docs, doc)
+	}
+	return docs, rows.Err()
+}
+
+// write stores docs on the target, preserving their revisions.
+func (r *replicator) write(ctx context.Context, docs []interface{}) error {
+	if len(docs) == 0 {
+		return nil
+	}
+	results, err := r.target.BulkDocs(ctx, docs, kivik.Options{"new_edits": false})
+	if err != nil {
+		return err
+	}
+	defer results.Close() // nolint: errcheck
+	var failures int64
+	for results.Next() {
+		if results.UpdateErr() != nil {
+			failures++
+		}
+	}
+	if err := results.Err(); err != nil {
+		return err
+	}
+	r.result.DocWriteFailures += failures
+	r.result.DocsWritten += int64(len(docs)) - failures
+	return nil
+}
+
+func (r *replicator) checkpointID() string {
+	return "_local/" + r.id
+}
+
+// readCheckpoint reads the checkpoint from db. A missing checkpoint is not
+// an error.
+func (r *replicator) readCheckpoint(ctx context.Context, db *kivik.DB) (*checkpoint, error) {
+	cp := &checkpoint{}
+	err := db.Get(ctx, r.checkpointID()).ScanDoc(cp)
+	if kivik.StatusCode(err) == kivik.StatusNotFound {
+		return cp, nil
+	}
+	return cp, err
+}
+
+// readCheckpoints returns the sequence from which to resume replication. This
+// is only possible if the checkpoints on the source and target agree.
+func (r *replicator) readCheckpoints(ctx context.Context) (string, error) {
+	targetCP, err := r.readCheckpoint(ctx, r.target)
+	if err != nil {
+		return "", err
+	}
+	sourceCP, err := r.readCheckpoint(ctx, r.source)
+	if err != nil {
+		return "", err
+	}
+	r.targetRev, r.sourceRev = targetCP.Rev, sourceCP.Rev
+	if targetCP.SessionID == "" || targetCP.SessionID != sourceCP.SessionID ||
+		targetCP.SourceLastSeq != sourceCP.SourceLastSeq {
+		return "", nil
+	}
+	return targetCP.SourceLastSeq, nil
+}
+
+// writeCheckpoints records seq as the last sequence replicated.
+func (r *replicator) writeCheckpoints(ctx context.Context, seq string) error {
+	var err error
+	r.targetRev, err = r.target.Put(ctx, r.checkpointID(), &checkpoint{
+		Rev:           r.targetRev,
+		SessionID:     r.sessionID,
+		SourceLastSeq: seq,
+	})
+	if err != nil {
+		return errors.Wrap(err, "checkpoint target")
+	}
+	r.sourceRev, err = r.source.Put(ctx, r.checkpointID(), &checkpoint{
+		Rev:           r.sourceRev,
+		SessionID:     r.sessionID,
+		SourceLastSeq: seq,
+	})
+	if err != nil {
+		return errors.Wrap(err, "checkpoint source")
+	}
+	return nil
+}
+
+// replicationID derives a replication ID from the source and target.
+func replicationID(target, source *kivik.DB) string {
+	sum := md5.Sum([]byte(fmt.Sprintf("%s\n%s\n%s\n%s",
+		source.Client().DSN(), source.Name(), target.Client().DSN(), target.Name())))
+	return hex.EncodeToString(sum[:])
+}
+
+func newSessionID() (string, error) {
+	id := make([]byte, 16)
+	if _, err := rand.Read(id); err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(id), nil
+}
@@ -0,0 +1,365 @@
+package replicate
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"strconv"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/flimzy/diff"
+	"github.com/go-kivik/kivik"
+	"github.com/go-kivik/kivik/driver"
+	"github.com/go-kivik/kivik/errors"
+	"github.com/go-kivik/kivik/mock"
+)
+
+// memDB is a minimal in-memory database, storing a single revision of each
+// document.
+type memDB struct {
+	*mock.DB
+	mu      sync.Mutex
+	docs    map[string]map[string]interface{}
+	changes []driver.Change
+	updated chan struct{}
+}
+
+var (
+	_ driver.RevsDiffer = &memDB{}
+	_ driver.BulkDocer  = &memDB{}
+)
+
+func newMemDB() *memDB {
+	db := &memDB{
+		docs:    make(map[string]map[string]interface{}),
+		updated: make(chan struct{}),
+	}
+	db.DB = &mock.DB{
+		GetFunc:     db.get,
+		PutFunc:     db.put,
+		ChangesFunc: db.changesFeed,
+	}
+	return db
+}
+
+func (db *memDB) store(doc map[string]interface{}) {
+	id := doc["_id"].(string)
+	db.docs[id] = doc
+	if strings.HasPrefix(id, "_local/") {
+		return
+	}
+	db.changes = append(db.changes, driver.Change{
+		ID:      id,
+		Seq:     driver.SequenceID(strconv.Itoa(len(db.changes) + 1)),
+		Changes: driver.ChangedRevs{doc["_rev"].(string)},
+	})
+	close(db.updated)
+	db.updated = make(chan struct{})
+}
+
+func (db *memDB) rev(docID string) string {
+	db.mu.Lock()
+	defer db.mu.Unlock()
+	if doc, ok := db.docs[docID]; ok {
+		return doc["_rev"].(string)
+	}
+	return ""
+}
+
+func (db *memDB) get(_ context.Context, docID string, opts map[string]interface{}) (*driver.Document, error) {
+	db.mu.Lock()
+	defer db.mu.Unlock()
+	doc, ok := db.docs[docID]
+	if !ok {
+		return nil, errors.Status(kivik.StatusNotFound, "missing")
+	}
+	if rev, _ := opts["rev"].(string); rev != "" && rev != doc["_rev"] {
+		return nil, errors.Status(kivik.StatusNotFound, "missing")
+	}
+	body, _ := json.Marshal(doc)
+	return &driver.Document{
+		Rev:  doc["_rev"].(string),
+		Body: ioutil.NopCloser(bytes.NewReader(body)),
+	}, nil
+}
+
+func (db *memDB) put(_ context.Context, docID string, doc interface{}, _ map[string]interface{}) (string, error) {
+	body, _ := json.Marshal(doc)
+	var m map[string]interface{}
+	_ = json.Unmarshal(body, &m)
+	db.mu.Lock()
+	defer db.mu.Unlock()
+	var gen int
+	if old, ok := db.docs[docID]; ok {
+		if m["_rev"] != old["_rev"] {
+			return "", errors.Status(kivik.StatusConflict, "conflict")
+		}
+		gen, _ = strconv.Atoi(strings.SplitN(old["_rev"].(string), "-", 2)[0])
+	} else if rev, _ := m["_rev"].(string); rev != "" {
+		return "", errors.Status(kivik.StatusConflict, "conflict")
+	}
+	m["_id"] = docID
+	m["_rev"] = fmt.Sprintf("%d-%s", gen+1, docID)
+	db.store(m)
+	return m["_rev"].(string), nil
+}
+
+func (db *memDB) changesFeed(ctx context.Context, opts map[string]interface{}) (driver.Changes, error) {
+	since, _ := strconv.Atoi(fmt.Sprint(opts["since"]))
+	limit, _ := opts["limit"].(int)
+	db.mu.Lock()
+	if opts["feed"] == "longpoll" && since >= len(db.changes) {
+		updated := db.updated
+		db.mu.Unlock()
+		select {
+		case <-updated:
+		case <-ctx.Done():
+			return nil, ctx.Err()
+		}
+		db.mu.Lock()
+	}
+	var changes []driver.Change
+	if since < len(db.changes) {
+		changes = append(changes, db.changes[since:]...)
+	}
+	db.mu.Unlock()
+	if limit > 0 && len(changes) > limit {
+		changes = changes[:limit]
+	}
+	return &mock.Changes{
+		NextFunc: func(change *driver.Change) error {
+			if len(changes) == 0 {
+				return io.EOF
+			}
+			*change, changes = changes[0], changes[1:]
+			return nil
+		},
+		CloseFunc:   func() error { return nil },
+		LastSeqFunc: func() string { return "" },
+	}, nil
+}
+
+func (db *memDB) RevsDiff(_ context.Context, revMap interface{}) (driver.Rows, error) {
+	db.mu.Lock()
+	var rows []*driver.Row
+	for id, revs := range revMap.(map[string][]string) {
+		var missing []string
+		for _, rev := range revs {
+			if doc, ok := db.docs[id]; !ok || doc["_rev"] != rev {
+				missing = append(missing, rev)
+			}
+		}
+		if len(missing) > 0 {
+			value, _ := json.Marshal(kivik.RevDiff{Missing: missing})
+			rows = append(rows, &driver.Row{ID: id, Value: value})
+		}
+	}
+	db.mu.Unlock()
+	return &mock.Rows{
+		NextFunc: func(row *driver.Row) error {
+			if len(rows) == 0 {
+				return io.EOF
+			}
+			*row, rows = *rows[0], rows[1:]
+			return nil
+		},
+		CloseFunc: func() error { return nil },
+	}, nil
+}
+
+func (db *memDB) BulkDocs(_ context.Context, docs []interface{}, opts map[string]interface{}) (driver.BulkResults, error) {
+	if opts["new_edits"] != false {
+		return nil, errors.Status(kivik.StatusBadRequest, "new_edits must be false")
+	}
+	db.mu.Lock()
+	defer db.mu.Unlock()
+	for _, doc := range docs {
+		db.store(doc.(map[string]interface{}))
+	}
+	return &mock.BulkResults{
+		NextFunc:  func(_ *driver.BulkResult) error { return io.EOF },
+		CloseFunc: func() error { return nil },
+	}, nil
+}
+
+var (
+	memDBs   = make(map[string]*memDB)
+	memDBsMu sync.Mutex
+)
+
+func init() {
+	kivik.Register("replicate-mem", &mock.Driver{
+		NewClientFunc: func(_ context.Context, _ string) (driver.Client, error) {
+			return &mock.Client{
+				DBFunc: func(_ context.Context, name string, _ map[string]interface{}) (driver.DB, error) {
+					memDBsMu.Lock()
+					defer memDBsMu.Unlock()
+					return memDBs[name], nil
+				},
+			}, nil
+		},
+	})
+}
+
+// setup returns new, empty, source and target databases.
+func setup(t *testing.T) (target, source *kivik.DB, targetMem, sourceMem *memDB) {
+	ctx := context.Background()
+	client, err := kivik.New(ctx, "replicate-mem", "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	targetMem, sourceMem = newMemDB(), newMemDB()
+	memDBsMu.Lock()
+	memDBs[t.Name()+"/target"] = targetMem
+	memDBs[t.Name()+"/source"] = sourceMem
+	memDBsMu.Unlock()
+	if target, err = client.DB(ctx, t.Name()+"/target"); err != nil {
+		t.Fatal(err)
+	}
+	if source, err = client.DB(ctx, t.Name()+"/source"); err != nil {
+		t.Fatal(err)
+	}
+	return target, source, targetMem, sourceMem
+}
+
+func putDocs(t *testing.T, db *kivik.DB, ids ...string) {
+	for _, id := range ids {
+		if _, err := db.Put(context.Background(), id, map[string]string{"name": id}); err != nil {
+			t.Fatal(err)
+		}
+	}
+}
+
+func TestReplicate(t *testing.T) {
+	ctx := context.Background()
+	target, source, targetMem, sourceMem := setup(t)
+	putDocs(t, source, "a", "b", "c")
+	result, err := Replicate(ctx, target, source, &Config{BatchSize: 2})
+	if err != nil {
+		t.Fatal(err)
+	}
+	expected := &Result{
+		LastSeq:        "3",
+		MissingChecked: 3,
+		MissingFound:   3,
+		DocsRead:       3,
+		DocsWritten:    3,
+	}
+	result.StartTime, result.EndTime = time.Time{}, time.Time{}
+	if d := diff.Interface(expected, result); d != nil {
+		t.Error(d)
+	}
+	for _, id := range []string{"a", "b", "c"} {
+		if rev := targetMem.rev(id); rev != sourceMem.rev(id) {
+			t.Errorf("%s: expected rev %s, got %s", id, sourceMem.rev(id), rev)
+		}
+	}
+
+	t.Run("resume", func(t *testing.T) {
+		putDocs(t, source, "d")
+		result, err := Replicate(ctx, target, source, nil)
+		if err != nil {
+			t.Fatal(err)
+		}
+		expected := &Result{
+			LastSeq:        "4",
+			MissingChecked: 1,
+			MissingFound:   1,
+			DocsRead:       1,
+			DocsWritten:    1,
+		}
+		result.StartTime, result.EndTime = time.Time{}, time.Time{}
+		if d := diff.Interface(expected, result); d != nil {
+			t.Error(d)
+		}
+	})
+
+	t.Run("up to date", func(t *testing.T) {
+		result, err := Replicate(ctx, target, source, nil)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if result.MissingChecked != 0 || result.LastSeq != "4" {
+			t.Errorf("Unexpected result: %+v", result)
+		}
+	})
+
+	t.Run("new replication ID", func(t *testing.T) {
+		result, err := Replicate(ctx, target, source, &Config{ID: "other"})
+		if err != nil {
+			t.Fatal(err)
+		}
+		if result.MissingChecked != 4 || result.MissingFound != 0 {
+			t.Errorf("Unexpected result: %+v", result)
+		}
+	})
+}
+
+func TestReplicateFilter(t *testing.T) {
+	target, source, targetMem, _ := setup(t)
+	putDocs(t, source, "a", "b", "c")
+	result, err := Replicate(context.Background(), target, source, &Config{
+		BatchSize: 1,
+		Filter: func(change kivik.ChangeEvent) bool {
+			return change.ID == "c"
+		},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if result.DocsWritten != 1 || result.LastSeq != "3" {
+		t.Errorf("Unexpected result: %+v", result)
+	}
+	if targetMem.rev("a") != "" || targetMem.rev("c") == "" {
+		t.Error("Unexpected documents replicated")
+	}
+}
+
+func TestReplicateContinuous(t *testing.T) {
+	target, source, targetMem, _ := setup(t)
+	putDocs(t, source, "a")
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	type reply struct {
+		result *Result
+		err    error
+	}
+	done := make(chan reply)
+	go func() {
+		result, err := Replicate(ctx, target, source, &Config{Continuous: true})
+		done <- reply{result, err}
+	}()
+	putDocs(t, source, "b")
+	deadline := time.Now().Add(5 * time.Second)
+	for targetMem.rev("b") == "" {
+		if time.Now().After(deadline) {
+			t.Fatal("timed out waiting for replication")
+		}
+		time.Sleep(time.Millisecond)
+	}
+	cancel()
+	r := <-done
+	if r.err != nil {
+		t.Fatal(r.err)
+	}
+	if r.result.DocsWritten != 2 {
+		t.Errorf("Expected 2 docs written, got %d", r.result.DocsWritten)
+	}
+}
+
+func TestReplicateError(t *testing.T) {
+	target, source, _, sourceMem := setup(t)
+	sourceMem.ChangesFunc = func(_ context.Context, _ map[string]interface{}) (driver.Changes, error) {
+		return nil, errors.Status(kivik.StatusUnauthorized, "unauthorized")
+	}
+	_, err := Replicate(context.Background(), target, source, nil)
+	if kivik.StatusCode(err) != kivik.StatusUnauthorized {
+		t.Errorf("Unexpected error: %v", err)
+	}
+}
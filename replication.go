@@ -148,7 +148,12 @@ func (c *Client) GetReplications(ctx context.Context, options ...Options) ([]*Re
 		if err != nil {
 			return nil, err
 		}
-		reps, err := replicator.GetReplications(ctx, opts)
+		var reps []driver.Replication
+		err = c.do(ctx, &Operation{Name: "GetReplications", Options: opts}, false, func(ctx context.Context) error {
+			var e error
+			reps, e = replicator.GetReplications(ctx, opts)
+			return e
+		})
 		if err != nil {
 			return nil, err
 		}
@@ -168,7 +173,12 @@ func (c *Client) Replicate(ctx context.Context, targetDSN, sourceDSN string, opt
 		if err != nil {
 			return nil, err
 		}
-		rep, err := replicator.Replicate(ctx, targetDSN, sourceDSN, opts)
+		var rep driver.Replication
+		err = c.do(ctx, &Operation{Name: "Replicate", Options: opts}, true, func(ctx context.Context) error {
+			var e error
+			rep, e = replicator.Replicate(ctx, targetDSN, sourceDSN, opts)
+			return e
+		})
 		if err != nil {
 			return nil, err
 		}
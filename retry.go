@@ -0,0 +1,120 @@
+package kivik
+
+import (
+	"context"
+	"math/rand"
+	"time"
+)
+
+// Default backoff bounds for a RetryPolicy.
+const (
+	DefaultRetryMinBackoff = 100 * time.Millisecond
+	DefaultRetryMaxBackoff = 10 * time.Second
+)
+
+// RetryPolicy controls the automatic retry of requests which fail with a
+// transient error: StatusTooManyRequests, StatusServiceUnavailable, or
+// StatusNetworkError.
+//
+// Read operations, such as Get, AllDocs and Query, are retried according to
+// the policy. Write operations, such as Put and Delete, are retried only if
+// RetryWrites is true.
+type RetryPolicy struct {
+	// MaxRetries is the maximum number of retries after the initial attempt.
+	// Zero disables retries.
+	MaxRetries int
+	// MinBackoff is the delay before the first retry. It is doubled for each
+	// subsequent retry, up to MaxBackoff. Defaults to DefaultRetryMinBackoff.
+	MinBackoff time.Duration
+	// MaxBackoff is the maximum delay between retries. Defaults to
+	// DefaultRetryMaxBackoff.
+	MaxBackoff time.Duration
+	// Jitter is the fraction, between 0 and 1, by which each delay is randomly
+	// reduced, to avoid many clients retrying in lockstep.
+	Jitter float64
+	// RetryWrites enables retries for write operations. Retried writes may
+	// result in a conflict, if the original request succeeded but the response
+	// was lost.
+	RetryWrites bool
+}
+
+// SetRetryPolicy sets the default retry policy for all requests made through
+// c, and through any DB handles obtained from it. A nil policy disables
+// retries. SetRetryPolicy should be called before the client is used.
+func (c *Client) SetRetryPolicy(policy *RetryPolicy) {
+	c.retryPolicy = policy
+}
+
+type retryPolicyKey struct{}
+
+// WithRetryPolicy returns a copy of ctx which carries policy. Requests made
+// with the returned context use policy in place of the client's default. A
+// nil policy disables retries.
+func WithRetryPolicy(ctx context.Context, policy *RetryPolicy) context.Context {
+	return context.WithValue(ctx, retryPolicyKey{}, policy)
+}
+
+func retryableError(err error) bool {
+	switch StatusCode(err) {
+	case StatusTooManyRequests, StatusServiceUnavailable, StatusNetworkError:
+		return true
+	}
+	return false
+}
+
+func (p *RetryPolicy) backoff(retry int) time.Duration {
+	min, max := p.MinBackoff, p.MaxBackoff
+	if min <= 0 {
+		min = DefaultRetryMinBackoff
+	}
+	if max <= 0 {
+		max = DefaultRetryMaxBackoff
+	}
+	delay := min
+	for i := 0; i < retry && delay < max; i++ {
+		delay *= 2
+	}
+	if delay > max {
+		delay = max
+	}
+	if p.Jitter > 0 {
+		delay -= time.Duration(p.Jitter * rand.Float64() * float64(delay))
+	}
+	return delay
+}
+
+// retry calls fn, retrying according to the policy in ctx, or else policy.
+// write indicates that fn is not idempotent. Each retry is logged to log, if
+// it is not nil.
+func retry(ctx context.Context, policy *RetryPolicy, write bool, log func(retry int, delay time.Duration, err error), fn func() error) error {
+	if p, ok := ctx.Value(retryPolicyKey{}).(*RetryPolicy); ok {
+		policy = p
+	}
+	err := fn()
+	if policy == nil || (write && !policy.RetryWrites) {
+		return err
+	}
+	for i := 0; i < policy.MaxRetries && retryableError(err); i++ {
+		delay := policy.backoff(i)
+		if deadline, ok := ctx.Deadline(); ok && time.Until(deadline) < delay {
+			return err
+		}
+		if log != nil {
+			log(i+1, delay, err)
+		}
+		select {
+		case <-time.After(delay):
+		case <-ctx.Done():
+			return err
+		}
+		err = fn()
+	}
+	return err
+}
+
+// logRetry returns a function which logs retries of op to logger.
+func logRetry(logger Logger, op *Operation) func(int, time.Duration, error) {
+	return func(retry int, delay time.Duration, err error) {
+		logger.Warn("kivik: retrying operation", "operation", op.Name, "db", op.DB, "retry", retry, "delay", delay, "error", err)
+	}
+}
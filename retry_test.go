@@ -0,0 +1,227 @@
+package kivik
+
+import (
+	"context"
+	"io"
+	"testing"
+	"time"
+
+	"github.com/flimzy/testy"
+	"github.com/go-kivik/kivik/driver"
+	kerrors "github.com/go-kivik/kivik/errors"
+	"github.com/go-kivik/kivik/mock"
+)
+
+func TestRetry(t *testing.T) {
+	transient := kerrors.Status(StatusServiceUnavailable, "unavailable")
+	tests := []struct {
+		name     string
+		ctx      context.Context
+		timeout  time.Duration
+		policy   *RetryPolicy
+		write    bool
+		errs     []error
+		attempts int
+		status   int
+		err      string
+	}{
+		{
+			name:     "no policy",
+			errs:     []error{transient, nil},
+			attempts: 1,
+			status:   StatusServiceUnavailable,
+			err:      "unavailable",
+		},
+		{
+			name:     "success after retry",
+			policy:   &RetryPolicy{MaxRetries: 3, MinBackoff: time.Millisecond},
+			errs:     []error{transient, kerrors.Status(StatusTooManyRequests, "slow down"), nil},
+			attempts: 3,
+		},
+		{
+			name:     "retries exhausted",
+			policy:   &RetryPolicy{MaxRetries: 2, MinBackoff: time.Millisecond},
+			errs:     []error{transient, transient, kerrors.Status(StatusNetworkError, "net error"), nil},
+			attempts: 3,
+			status:   StatusNetworkError,
+			err:      "net error",
+		},
+		{
+			name:     "permanent error",
+			policy:   &RetryPolicy{MaxRetries: 2, MinBackoff: time.Millisecond},
+			errs:     []error{kerrors.Status(StatusNotFound, "not found"), nil},
+			attempts: 1,
+			status:   StatusNotFound,
+			err:      "not found",
+		},
+		{
+			name:     "write without opt-in",
+			policy:   &RetryPolicy{MaxRetries: 2, MinBackoff: time.Millisecond},
+			write:    true,
+			errs:     []error{transient, nil},
+			attempts: 1,
+			status:   StatusServiceUnavailable,
+			err:      "unavailable",
+		},
+		{
+			name:     "write with opt-in",
+			policy:   &RetryPolicy{MaxRetries: 2, MinBackoff: time.Millisecond, RetryWrites: true},
+			write:    true,
+			errs:     []error{transient, nil},
+			attempts: 2,
+		},
+		{
+			name:     "context override",
+			ctx:      WithRetryPolicy(context.Background(), &RetryPolicy{MaxRetries: 1, MinBackoff: time.Millisecond}),
+			errs:     []error{transient, nil},
+			attempts: 2,
+		},
+		{
+			name:     "context disables",
+			ctx:      WithRetryPolicy(context.Background(), nil),
+			policy:   &RetryPolicy{MaxRetries: 2, MinBackoff: time.Millisecond},
+			errs:     []error{transient, nil},
+			attempts: 1,
+			status:   StatusServiceUnavailable,
+			err:      "unavailable",
+		},
+		{
+			name:     "deadline too short",
+			timeout:  time.Hour,
+			policy:   &RetryPolicy{MaxRetries: 2, MinBackoff: 2 * time.Hour, MaxBackoff: 2 * time.Hour},
+			errs:     []error{transient, nil},
+			attempts: 1,
+			status:   StatusServiceUnavailable,
+			err:      "unavailable",
+		},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			ctx := test.ctx
+			if ctx == nil {
+				ctx = context.Background()
+			}
+			if test.timeout > 0 {
+				var cancel context.CancelFunc
+				ctx, cancel = context.WithTimeout(ctx, test.timeout)
+				defer cancel()
+			}
+			var attempts int
+			err := retry(ctx, test.policy, test.write, nil, func() error {
+				err := test.errs[attempts]
+				attempts++
+				return err
+			})
+			testy.StatusError(t, test.err, test.status, err)
+			if attempts != test.attempts {
+				t.Errorf("Expected %d attempts, got %d", test.attempts, attempts)
+			}
+		})
+	}
+}
+
+func TestRetryPolicyBackoff(t *testing.T) {
+	p := &RetryPolicy{MinBackoff: time.Second, MaxBackoff: 5 * time.Second}
+	expected := []time.Duration{time.Second, 2 * time.Second, 4 * time.Second, 5 * time.Second, 5 * time.Second}
+	for i, exp := range expected {
+		if d := p.backoff(i); d != exp {
+			t.Errorf("Retry %d: expected %v, got %v", i, exp, d)
+		}
+	}
+	p.Jitter = 0.5
+	for i := 0; i < 100; i++ {
+		if d := p.backoff(0); d < 500*time.Millisecond || d > time.Second {
+			t.Errorf("Backoff out of range: %v", d)
+		}
+	}
+}
+
+func TestGetRetry(t *testing.T) {
+	var attempts int
+	client := &Client{}
+	client.SetRetryPolicy(&RetryPolicy{MaxRetries: 1, MinBackoff: time.Millisecond})
+	db := &DB{
+		client: client,
+		driverDB: &mock.DB{
+			GetFunc: func(_ context.Context, _ string, _ map[string]interface{}) (*driver.Document, error) {
+				attempts++
+				if attempts == 1 {
+					return nil, kerrors.Status(StatusNetworkError, "connection reset")
+				}
+				return &driver.Document{Rev: "1-xxx", Body: body(`{}`)}, nil
+			},
+		},
+	}
+	row := db.Get(context.Background(), "foo")
+	if row.Err != nil {
+		t.Fatal(row.Err)
+	}
+	if row.Rev != "1-xxx" {
+		t.Errorf("Unexpected rev: %s", row.Rev)
+	}
+	if attempts != 2 {
+		t.Errorf("Expected 2 attempts, got %d", attempts)
+	}
+}
+
+func TestPagerRetry(t *testing.T) {
+	var attempts int
+	client := &Client{}
+	client.SetRetryPolicy(&RetryPolicy{MaxRetries: 1, MinBackoff: time.Millisecond})
+	db := &DB{
+		client: client,
+		driverDB: &mock.DB{
+			AllDocsFunc: func(_ context.Context, _ map[string]interface{}) (driver.Rows, error) {
+				attempts++
+				if attempts == 1 {
+					return nil, kerrors.Status(StatusTooManyRequests, "slow down")
+				}
+				return &mock.Rows{
+					NextFunc:  func(*driver.Row) error { return io.EOF },
+					CloseFunc: func() error { return nil },
+				}, nil
+			},
+		},
+	}
+	rows, err := db.AllDocsPager(context.Background(), 10)
+	if err != nil {
+		t.Fatal(err)
+	}
+	_ = rows.Close()
+	if attempts != 2 {
+		t.Errorf("Expected 2 attempts, got %d", attempts)
+	}
+}
+
+func TestBulkGetRetry(t *testing.T) {
+	var attempts int
+	client := &Client{}
+	client.SetRetryPolicy(&RetryPolicy{MaxRetries: 1, MinBackoff: time.Millisecond})
+	db := &DB{
+		client: client,
+		driverDB: &mock.DB{
+			GetFunc: func(_ context.Context, _ string, _ map[string]interface{}) (*driver.Document, error) {
+				attempts++
+				if attempts == 1 {
+					return nil, kerrors.Status(StatusServiceUnavailable, "unavailable")
+				}
+				return &driver.Document{Rev: "1-xxx", Body: body(`{}`)}, nil
+			},
+		},
+	}
+	rows, err := db.BulkGet(context.Background(), []BulkGetReference{{ID: "foo"}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer rows.Close() // nolint: errcheck
+	if !rows.Next() {
+		t.Fatal(rows.Err())
+	}
+	var doc map[string]interface{}
+	if err := rows.ScanDoc(&doc); err != nil {
+		t.Fatal(err)
+	}
+	if attempts != 2 {
+		t.Errorf("Expected 2 attempts, got %d", attempts)
+	}
+}
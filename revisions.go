@@ -0,0 +1,176 @@
+package kivik
+
+import (
+	"context"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// Revision statuses reported by RevsInfo.
+const (
+	RevAvailable = "available"
+	RevMissing   = "missing"
+	RevDeleted   = "deleted"
+)
+
+// RevInfo describes a revision in a document's history, as reported with the
+// revs_info option.
+type RevInfo struct {
+	// Rev is the revision ID.
+	Rev string `json:"rev"`
+	// Status is one of RevAvailable, RevMissing or RevDeleted. Missing
+	// revisions have typically been removed by compaction.
+	Status string `json:"status"`
+}
+
+// RevsInfo returns the revision history of the winning revision of docID,
+// newest first, with the availability of each revision.
+func (db *DB) RevsInfo(ctx context.Context, docID string, options ...Options) ([]RevInfo, error) {
+	if docID == "" {
+		return nil, missingArg("docID")
+	}
+	options = append(options[:len(options):len(options)], Param("revs_info", true))
+	var doc struct {
+		RevsInfo []RevInfo `json:"_revs_info"`
+	}
+	if err := db.Get(ctx, docID, options...).ScanDoc(&doc); err != nil {
+		return nil, err
+	}
+	return doc.RevsInfo, nil
+}
+
+// Revisions is the revision history of a single revision, as reported in the
+// _revisions field with the revs option.
+type Revisions struct {
+	// Start is the generation of the newest revision.
+	Start int64 `json:"start"`
+	// IDs are the revision hashes, newest first, without generation prefix.
+	IDs []string `json:"ids"`
+}
+
+// Revs returns the full revision IDs of the history, newest first.
+func (r *Revisions) Revs() []string {
+	revs := make([]string, len(r.IDs))
+	for i, id := range r.IDs {
+		revs[i] = strconv.FormatInt(r.Start-int64(i), 10) + "-" + id
+	}
+	return revs
+}
+
+// Truncated returns true if the history does not extend back to the first
+// revision of the document, as happens when older history has been pruned
+// according to the database's revs_limit.
+func (r *Revisions) Truncated() bool {
+	return r.Start-int64(len(r.IDs)) > 0
+}
+
+// Revisions returns the revision history of the winning revision of docID, or
+// of the revision given by the rev option.
+func (db *DB) Revisions(ctx context.Context, docID string, options ...Options) (*Revisions, error) {
+	if docID == "" {
+		return nil, missingArg("docID")
+	}
+	options = append(options[:len(options):len(options)], Param("revs", true))
+	var doc struct {
+		Revisions *Revisions `json:"_revisions"`
+	}
+	if err := db.Get(ctx, docID, options...).ScanDoc(&doc); err != nil {
+		return nil, err
+	}
+	if doc.Revisions == nil {
+		return &Revisions{}, nil
+	}
+	return doc.Revisions, nil
+}
+
+// RevisionTree is the known revision tree of a document, built from the
+// histories of all its leaf revisions.
+type RevisionTree struct {
+	leaves  []string
+	deleted map[string]bool
+	parents map[string]string
+	nodes   map[string]bool
+}
+
+// RevisionTree returns the revision tree of docID, including conflicting and
+// deleted leaves. It requires that the driver support GetOpenRevs.
+func (db *DB) RevisionTree(ctx context.Context, docID string) (*RevisionTree, error) {
+	rows, err := db.GetOpenRevs(ctx, docID, nil, Param("revs", true))
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close() // nolint: errcheck
+	tree := &RevisionTree{
+		deleted: make(map[string]bool),
+		parents: make(map[string]string),
+		nodes:   make(map[string]bool),
+	}
+	for rows.Next() {
+		var doc struct {
+			Deleted   bool      `json:"_deleted"`
+			Revisions Revisions `json:"_revisions"`
+		}
+		if err := rows.ScanDoc(&doc); err != nil {
+			return nil, err
+		}
+		tree.add(doc.Revisions.Revs(), doc.Deleted)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	sort.Strings(tree.leaves)
+	return tree, nil
+}
+
+// add adds the history of a leaf, newest first, to the tree.
+func (t *RevisionTree) add(revs []string, deleted bool) {
+	if len(revs) == 0 {
+		return
+	}
+	t.leaves = append(t.leaves, revs[0])
+	if deleted {
+		t.deleted[revs[0]] = true
+	}
+	for i, rev := range revs {
+		t.nodes[rev] = true
+		if i < len(revs)-1 {
+			t.parents[rev] = revs[i+1]
+		}
+	}
+}
+
+// Leaves returns the leaf revisions of the tree, sorted by revision ID.
+func (t *RevisionTree) Leaves() []string {
+	return t.leaves
+}
+
+// Deleted returns true if rev is a deleted leaf.
+func (t *RevisionTree) Deleted(rev string) bool {
+	return t.deleted[rev]
+}
+
+// Parent returns the parent of rev, or "" if rev is a root, or unknown.
+func (t *RevisionTree) Parent(rev string) string {
+	return t.parents[rev]
+}
+
+// Ancestors returns the known ancestors of rev, newest first.
+func (t *RevisionTree) Ancestors(rev string) []string {
+	var ancestors []string
+	for parent := t.parents[rev]; parent != ""; parent = t.parents[parent] {
+		ancestors = append(ancestors, parent)
+	}
+	return ancestors
+}
+
+// Truncated returns true if any branch of the tree has been pruned, such that
+// its oldest known revision is not the first generation.
+func (t *RevisionTree) Truncated() bool {
+	for rev := range t.nodes {
+		if t.parents[rev] == "" && !strings.HasPrefix(rev, "1-") {
+			return true
+		}
+	}
+	return false
+}
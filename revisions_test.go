@@ -0,0 +1,154 @@
+package kivik
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"strings"
+	"testing"
+
+	"github.com/flimzy/diff"
+	"github.com/flimzy/testy"
+	"github.com/go-kivik/kivik/driver"
+	"github.com/go-kivik/kivik/mock"
+)
+
+func docDB(option, body string) *DB {
+	return &DB{
+		driverDB: &mock.DB{
+			GetFunc: func(_ context.Context, _ string, opts map[string]interface{}) (*driver.Document, error) {
+				if opts[option] != true {
+					return nil, fmt.Errorf("Unexpected options: %v", opts)
+				}
+				return &driver.Document{Body: ioutil.NopCloser(strings.NewReader(body))}, nil
+			},
+		},
+	}
+}
+
+func TestRevsInfo(t *testing.T) {
+	t.Run("missing docID", func(t *testing.T) {
+		_, err := (&DB{}).RevsInfo(context.Background(), "")
+		testy.StatusError(t, "kivik: docID required", StatusBadRequest, err)
+	})
+	t.Run("success", func(t *testing.T) {
+		db := docDB("revs_info", `{"_id":"foo","_rev":"3-c","_revs_info":[{"rev":"3-c","status":"available"},{"rev":"2-b","status":"deleted"},{"rev":"1-a","status":"missing"}]}`)
+		result, err := db.RevsInfo(context.Background(), "foo")
+		if err != nil {
+			t.Fatal(err)
+		}
+		expected := []RevInfo{
+			{Rev: "3-c", Status: RevAvailable},
+			{Rev: "2-b", Status: RevDeleted},
+			{Rev: "1-a", Status: RevMissing},
+		}
+		if d := diff.Interface(expected, result); d != nil {
+			t.Error(d)
+		}
+	})
+}
+
+func TestRevisions(t *testing.T) {
+	tests := []struct {
+		name      string
+		body      string
+		revs      []string
+		truncated bool
+	}{
+		{
+			name: "none",
+			body: `{"_id":"foo"}`,
+			revs: []string{},
+		},
+		{
+			name: "complete",
+			body: `{"_id":"foo","_revisions":{"start":3,"ids":["c","b","a"]}}`,
+			revs: []string{"3-c", "2-b", "1-a"},
+		},
+		{
+			name:      "truncated",
+			body:      `{"_id":"foo","_revisions":{"start":5,"ids":["e","d"]}}`,
+			revs:      []string{"5-e", "4-d"},
+			truncated: true,
+		},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			result, err := docDB("revs", test.body).Revisions(context.Background(), "foo")
+			if err != nil {
+				t.Fatal(err)
+			}
+			if d := diff.Interface(test.revs, result.Revs()); d != nil {
+				t.Error(d)
+			}
+			if result.Truncated() != test.truncated {
+				t.Errorf("Unexpected Truncated: %v", result.Truncated())
+			}
+		})
+	}
+}
+
+func TestRevisionTree(t *testing.T) {
+	openRevs := func(docs ...string) *DB {
+		return &DB{
+			driverDB: &mock.OpenRever{
+				OpenRevsFunc: func(_ context.Context, _ string, revs []string, opts map[string]interface{}) (driver.Rows, error) {
+					if len(revs) != 0 || opts["revs"] != true {
+						return nil, fmt.Errorf("Unexpected request: %v %v", revs, opts)
+					}
+					return &mock.Rows{
+						NextFunc: func(row *driver.Row) error {
+							if len(docs) == 0 {
+								return io.EOF
+							}
+							row.Doc, docs = json.RawMessage(docs[0]), docs[1:]
+							return nil
+						},
+						CloseFunc: func() error { return nil },
+					}, nil
+				},
+			},
+		}
+	}
+	t.Run("conflicts", func(t *testing.T) {
+		tree, err := openRevs(
+			`{"_id":"foo","_rev":"3-c","_revisions":{"start":3,"ids":["c","b","a"]}}`,
+			`{"_id":"foo","_rev":"3-x","_deleted":true,"_revisions":{"start":3,"ids":["x","y","a"]}}`,
+		).RevisionTree(context.Background(), "foo")
+		if err != nil {
+			t.Fatal(err)
+		}
+		if d := diff.Interface([]string{"3-c", "3-x"}, tree.Leaves()); d != nil {
+			t.Error(d)
+		}
+		if !tree.Deleted("3-x") || tree.Deleted("3-c") {
+			t.Error("Unexpected deleted status")
+		}
+		if p := tree.Parent("2-y"); p != "1-a" {
+			t.Errorf("Unexpected parent: %s", p)
+		}
+		if d := diff.Interface([]string{"2-b", "1-a"}, tree.Ancestors("3-c")); d != nil {
+			t.Error(d)
+		}
+		if tree.Truncated() {
+			t.Error("Unexpected truncation")
+		}
+	})
+	t.Run("truncated", func(t *testing.T) {
+		tree, err := openRevs(
+			`{"_id":"foo","_rev":"9-c","_revisions":{"start":9,"ids":["c","b"]}}`,
+		).RevisionTree(context.Background(), "foo")
+		if err != nil {
+			t.Fatal(err)
+		}
+		if !tree.Truncated() {
+			t.Error("Expected truncation")
+		}
+	})
+	t.Run("not implemented", func(t *testing.T) {
+		_, err := (&DB{driverDB: &mock.DB{}}).RevisionTree(context.Background(), "foo")
+		testy.StatusError(t, "kivik: open revs not supported by driver", StatusNotImplemented, err)
+	})
+}
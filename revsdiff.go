@@ -0,0 +1,40 @@
+package kivik
+
+import (
+	"context"
+
+	"github.com/go-kivik/kivik/driver"
+	"github.com/go-kivik/kivik/errors"
+)
+
+// RevDiff represents a rev diff for a single document, as returned by the
+// RevsDiff method.
+type RevDiff struct {
+	Missing           []string `json:"missing,omitempty"`
+	PossibleAncestors []string `json:"possible_ancestors,omitempty"`
+}
+
+// RevsDiff returns the subset of document/revision IDs that do not correspond to
+// revisions stored in the database. This is used by the replication protocol,
+// and is normally never needed otherwise.  revMap must marshal to the expected
+// format.
+//
+// Use ID() to return the current document ID, and ScanValue to access the full
+// JSON value, which should be of the JSON format:
+//
+//  {
+//      "missing": ["rev1",...],
+//      "possible_ancestors": ["revA",...]
+//  }
+//
+// The ScanValue method may be used to decode the value into a RevDiff.
+//
+// See http://docs.couchdb.org/en/stable/api/database/misc.html#db-revs-diff
+func (db *DB) RevsDiff(ctx context.Context, revMap interface{}) (*Rows, error) {
+	if rd, ok := db.driverDB.(driver.RevsDiffer); ok {
+		return db.doRows(ctx, &Operation{Name: "RevsDiff"}, func(ctx context.Context) (driver.Rows, error) {
+			return rd.RevsDiff(ctx, revMap)
+		})
+	}
+	return nil, errors.Status(StatusNotImplemented, "kivik: _revs_diff not supported by driver")
+}
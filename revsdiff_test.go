@@ -0,0 +1,77 @@
+package kivik
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"testing"
+
+	"github.com/flimzy/diff"
+	"github.com/flimzy/testy"
+	"github.com/go-kivik/kivik/driver"
+	"github.com/go-kivik/kivik/mock"
+)
+
+func TestRevsDiff(t *testing.T) {
+	revMap := map[string][]string{"foo": {"1-xxx", "2-yyy"}}
+	tests := []struct {
+		name     string
+		db       *DB
+		revMap   interface{}
+		expected *Rows
+		status   int
+		err      string
+	}{
+		{
+			name:   "non-RevsDiffer",
+			db:     &DB{driverDB: &mock.DB{}},
+			status: StatusNotImplemented,
+			err:    "kivik: _revs_diff not supported by driver",
+		},
+		{
+			name: "driver error",
+			db: &DB{
+				driverDB: &mock.RevsDiffer{
+					RevsDiffFunc: func(_ context.Context, _ interface{}) (driver.Rows, error) {
+						return nil, errors.New("revs diff error")
+					},
+				},
+			},
+			status: StatusInternalServerError,
+			err:    "revs diff error",
+		},
+		{
+			name: "success",
+			db: &DB{
+				driverDB: &mock.RevsDiffer{
+					RevsDiffFunc: func(_ context.Context, rm interface{}) (driver.Rows, error) {
+						if d := diff.Interface(revMap, rm); d != nil {
+							return nil, fmt.Errorf("Unexpected revMap:\n%s", d)
+						}
+						return &mock.Rows{ID: "a"}, nil
+					},
+				},
+			},
+			revMap: revMap,
+			expected: &Rows{
+				iter: &iter{
+					feed: &rowsIterator{
+						Rows: &mock.Rows{ID: "a"},
+					},
+					curVal: &driver.Row{},
+				},
+				rowsi: &mock.Rows{ID: "a"},
+			},
+		},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			result, err := test.db.RevsDiff(context.Background(), test.revMap)
+			testy.StatusError(t, test.err, test.status, err)
+			result.cancel = nil // Determinism
+			if d := diff.Interface(test.expected, result); d != nil {
+				t.Error(d)
+			}
+		})
+	}
+}
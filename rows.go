@@ -12,11 +12,15 @@ import (
 type Rows struct {
 	*iter
 	rowsi driver.Rows
+	codec JSONCodec
 }
 
 // Next prepares the next result value for reading. It returns true on success
 // or false if there are no more results or an error  occurs while preparing it.
 // Err should be consulted to distinguish between the two.
+//
+// Drivers may read results from the network as they are iterated, so Next may
+// block until the next row is received.
 func (r *Rows) Next() bool {
 	return r.iter.Next()
 }
@@ -42,10 +46,11 @@ var _ iterator = &rowsIterator{}
 
 func (r *rowsIterator) Next(i interface{}) error { return r.Rows.Next(i.(*driver.Row)) }
 
-func newRows(ctx context.Context, rowsi driver.Rows) *Rows {
+func newRows(ctx context.Context, codec JSONCodec, rowsi driver.Rows) *Rows {
 	return &Rows{
 		iter:  newIterator(ctx, &rowsIterator{rowsi}, &driver.Row{}),
 		rowsi: rowsi,
+		codec: codec,
 	}
 }
 
@@ -72,22 +77,27 @@ func (r *Rows) ScanValue(dest interface{}) error {
 		return err
 	}
 	defer runlock()
-	return scan(dest, r.curVal.(*driver.Row).Value)
+	return scan(r.codec, dest, r.curVal.(*driver.Row).Value)
 }
 
 // ScanDoc works the same as ScanValue, but on the doc field of the result. It
-// is only valid for results that include documents.
+// is only valid for results that include documents. If the current row
+// represents a document which could not be fetched, such as in a BulkGet
+// result, the row's error is returned.
 func (r *Rows) ScanDoc(dest interface{}) error {
 	runlock, err := r.rlock()
 	if err != nil {
 		return err
 	}
 	defer runlock()
+	if rowErr := r.curVal.(*driver.Row).Error; rowErr != nil {
+		return rowErr
+	}
 	doc := r.curVal.(*driver.Row).Doc
 	if doc == nil {
 		return errors.Status(StatusBadRequest, "kivik: doc is nil; does the query include docs?")
 	}
-	return scan(dest, doc)
+	return scan(r.codec, dest, doc)
 }
 
 // ScanKey works the same as ScanValue, but on the key field of the result. For
@@ -98,7 +108,7 @@ func (r *Rows) ScanKey(dest interface{}) error {
 		return err
 	}
 	defer runlock()
-	return scan(dest, r.curVal.(*driver.Row).Key)
+	return scan(r.codec, dest, r.curVal.(*driver.Row).Key)
 }
 
 // ID returns the ID of the current result.
@@ -140,9 +150,10 @@ func (r *Rows) TotalRows() int64 {
 }
 
 // UpdateSeq returns the sequence id of the underlying database the view
-// reflects, if requested in the query.
-func (r *Rows) UpdateSeq() string {
-	return r.rowsi.UpdateSeq()
+// reflects, if requested in the query. As with Offset, it is only guaranteed
+// to be set after all result rows have been enumerated.
+func (r *Rows) UpdateSeq() SequenceID {
+	return SequenceID(r.rowsi.UpdateSeq())
 }
 
 // Warning returns a warning generated by the query, if any. This value is only
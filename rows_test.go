@@ -164,6 +164,19 @@ func TestRowsScanDoc(t *testing.T) {
 			status: StatusBadRequest,
 			err:    "kivik: doc is nil; does the query include docs?",
 		},
+		{
+			name: "row error",
+			rows: &Rows{
+				iter: &iter{
+					ready: true,
+					curVal: &driver.Row{
+						Error: errors.New("not found"),
+					},
+				},
+			},
+			status: StatusInternalServerError,
+			err:    "not found",
+		},
 	}
 	for _, test := range tests {
 		t.Run(test.name, func(t *testing.T) {
@@ -271,7 +284,7 @@ func TestRowsGetters(t *testing.T) {
 
 	t.Run("UpdateSeq", func(t *testing.T) {
 		result := r.UpdateSeq()
-		if updateseq != result {
+		if SequenceID(updateseq) != result {
 			t.Errorf("Unexpected result: %v", result)
 		}
 	})
@@ -298,7 +311,7 @@ func TestRowsGetters(t *testing.T) {
 func TestWarning(t *testing.T) {
 	t.Run("Warner", func(t *testing.T) {
 		expected := "test warning"
-		r := newRows(context.Background(), &mock.RowsWarner{
+		r := newRows(context.Background(), nil, &mock.RowsWarner{
 			WarningFunc: func() string { return expected },
 		})
 		if w := r.Warning(); w != expected {
@@ -306,7 +319,7 @@ func TestWarning(t *testing.T) {
 		}
 	})
 	t.Run("NonWarner", func(t *testing.T) {
-		r := newRows(context.Background(), &mock.Rows{})
+		r := newRows(context.Background(), nil, &mock.Rows{})
 		expected := ""
 		if w := r.Warning(); w != expected {
 			t.Errorf("Warning\nExpected: %s\n  Actual: %s", expected, w)
@@ -317,7 +330,7 @@ func TestWarning(t *testing.T) {
 func TestBookmark(t *testing.T) {
 	t.Run("Bookmarker", func(t *testing.T) {
 		expected := "test bookmark"
-		r := newRows(context.Background(), &mock.Bookmarker{
+		r := newRows(context.Background(), nil, &mock.Bookmarker{
 			BookmarkFunc: func() string { return expected },
 		})
 		if w := r.Bookmark(); w != expected {
@@ -325,7 +338,7 @@ func TestBookmark(t *testing.T) {
 		}
 	})
 	t.Run("Non Bookmarker", func(t *testing.T) {
-		r := newRows(context.Background(), &mock.Rows{})
+		r := newRows(context.Background(), nil, &mock.Rows{})
 		expected := ""
 		if w := r.Bookmark(); w != expected {
 			t.Errorf("Warning\nExpected: %s\n  Actual: %s", expected, w)
@@ -0,0 +1,229 @@
+package kivik
+
+import (
+	"context"
+	"time"
+
+	"github.com/go-kivik/kivik/driver"
+	"github.com/go-kivik/kivik/errors"
+)
+
+var schedulerNotImplemented = errors.Status(StatusNotImplemented, "kivik: driver does not support the replication scheduler")
+
+// SchedulerJobEvent is an event in a replication job's history, such as
+// "started" or "crashed".
+type SchedulerJobEvent struct {
+	Type string `json:"type"`
+	// Reason is the reason for a crash, if Type is "crashed".
+	Reason    string    `json:"reason"`
+	Timestamp time.Time `json:"timestamp"`
+}
+
+// SchedulerJob represents a running replication job.
+type SchedulerJob struct {
+	Database  string              `json:"database"`
+	JobID     string              `json:"id"`
+	PID       string              `json:"pid"`
+	Source    string              `json:"source"`
+	Target    string              `json:"target"`
+	User      string              `json:"user"`
+	DocID     string              `json:"doc_id"`
+	Node      string              `json:"node"`
+	StartTime time.Time           `json:"start_time"`
+	History   []SchedulerJobEvent `json:"history"`
+}
+
+// SchedulerDoc represents the state of a replication document.
+type SchedulerDoc struct {
+	Database      string `json:"database"`
+	DocID         string `json:"doc_id"`
+	ReplicationID string `json:"id"`
+	Node          string `json:"node"`
+	Source        string `json:"source"`
+	Target        string `json:"target"`
+	// State is the replication state, such as "running", "crashing" or
+	// "failed".
+	State string `json:"state"`
+	// ErrorCount is the number of consecutive errors encountered by the
+	// replication.
+	ErrorCount  int       `json:"error_count"`
+	StartTime   time.Time `json:"start_time"`
+	LastUpdated time.Time `json:"last_updated"`
+	// Info contains additional state-specific information, such as the
+	// replication statistics, or the last error.
+	Info map[string]interface{} `json:"info"`
+}
+
+// LastError returns the last error reported for the replication, if any.
+func (d *SchedulerDoc) LastError() string {
+	e, _ := d.Info["error"].(string)
+	return e
+}
+
+// SchedulerJobs is an iterator over replication jobs.
+type SchedulerJobs struct {
+	*iter
+	jobsi driver.SchedulerJobs
+}
+
+type schedulerJobsIterator struct{ driver.SchedulerJobs }
+
+var _ iterator = &schedulerJobsIterator{}
+
+func (j *schedulerJobsIterator) Next(i interface{}) error {
+	return j.SchedulerJobs.Next(i.(*driver.SchedulerJob))
+}
+
+func newSchedulerJobs(ctx context.Context, jobsi driver.SchedulerJobs) *SchedulerJobs {
+	return &SchedulerJobs{
+		iter:  newIterator(ctx, &schedulerJobsIterator{jobsi}, &driver.SchedulerJob{}),
+		jobsi: jobsi,
+	}
+}
+
+// Next prepares the next job for reading. It returns true on success, or
+// false if there are no more jobs or an error occurs. Err should be consulted
+// to distinguish between the two.
+func (j *SchedulerJobs) Next() bool {
+	return j.iter.Next()
+}
+
+// Close closes the iterator.
+func (j *SchedulerJobs) Close() error {
+	return j.iter.Close()
+}
+
+// Err returns the error, if any, that was encountered during iteration. Err
+// may be called after an explicit or implicit Close.
+func (j *SchedulerJobs) Err() error {
+	return j.iter.Err()
+}
+
+// Job returns the current job, or nil if the iterator is not ready.
+func (j *SchedulerJobs) Job() *SchedulerJob {
+	runlock, err := j.rlock()
+	if err != nil {
+		return nil
+	}
+	defer runlock()
+	job := j.curVal.(*driver.SchedulerJob)
+	result := &SchedulerJob{
+		Database:  job.Database,
+		JobID:     job.JobID,
+		PID:       job.PID,
+		Source:    job.Source,
+		Target:    job.Target,
+		User:      job.User,
+		DocID:     job.DocID,
+		Node:      job.Node,
+		StartTime: job.StartTime,
+	}
+	if job.History != nil {
+		result.History = make([]SchedulerJobEvent, len(job.History))
+		for i, event := range job.History {
+			result.History[i] = SchedulerJobEvent(event)
+		}
+	}
+	return result
+}
+
+// SchedulerDocs is an iterator over replication documents.
+type SchedulerDocs struct {
+	*iter
+	docsi driver.SchedulerDocs
+}
+
+type schedulerDocsIterator struct{ driver.SchedulerDocs }
+
+var _ iterator = &schedulerDocsIterator{}
+
+func (d *schedulerDocsIterator) Next(i interface{}) error {
+	return d.SchedulerDocs.Next(i.(*driver.SchedulerDoc))
+}
+
+func newSchedulerDocs(ctx context.Context, docsi driver.SchedulerDocs) *SchedulerDocs {
+	return &SchedulerDocs{
+		iter:  newIterator(ctx, &schedulerDocsIterator{docsi}, &driver.SchedulerDoc{}),
+		docsi: docsi,
+	}
+}
+
+// Next prepares the next document for reading. It returns true on success,
+// or false if there are no more documents or an error occurs. Err should be
+// consulted to distinguish between the two.
+func (d *SchedulerDocs) Next() bool {
+	return d.iter.Next()
+}
+
+// Close closes the iterator.
+func (d *SchedulerDocs) Close() error {
+	return d.iter.Close()
+}
+
+// Err returns the error, if any, that was encountered during iteration. Err
+// may be called after an explicit or implicit Close.
+func (d *SchedulerDocs) Err() error {
+	return d.iter.Err()
+}
+
+// Doc returns the current replication document, or nil if the iterator is
+// not ready.
+func (d *SchedulerDocs) Doc() *SchedulerDoc {
+	runlock, err := d.rlock()
+	if err != nil {
+		return nil
+	}
+	defer runlock()
+	doc := SchedulerDoc(*d.curVal.(*driver.SchedulerDoc))
+	return &doc
+}
+
+// SchedulerJobs returns an iterator over the running replication jobs.
+//
+// See http://docs.couchdb.org/en/2.2.0/api/server/common.html#scheduler-jobs
+func (c *Client) SchedulerJobs(ctx context.Context, options ...Options) (*SchedulerJobs, error) {
+	scheduler, ok := c.driverClient.(driver.Scheduler)
+	if !ok {
+		return nil, schedulerNotImplemented
+	}
+	opts, err := mergeOptions(options...)
+	if err != nil {
+		return nil, err
+	}
+	var jobsi driver.SchedulerJobs
+	err = c.do(ctx, &Operation{Name: "SchedulerJobs", Options: opts}, false, func(ctx context.Context) error {
+		var e error
+		jobsi, e = scheduler.SchedulerJobs(ctx, opts)
+		return e
+	})
+	if err != nil {
+		return nil, err
+	}
+	return newSchedulerJobs(ctx, jobsi), nil
+}
+
+// SchedulerDocs returns an iterator over the replication documents in the
+// named replicator database, including their state, error count and last
+// error. If replicatorDB is empty, all replicator databases are included.
+//
+// See http://docs.couchdb.org/en/2.2.0/api/server/common.html#scheduler-docs
+func (c *Client) SchedulerDocs(ctx context.Context, replicatorDB string, options ...Options) (*SchedulerDocs, error) {
+	scheduler, ok := c.driverClient.(driver.Scheduler)
+	if !ok {
+		return nil, schedulerNotImplemented
+	}
+	opts, err := mergeOptions(options...)
+	if err != nil {
+		return nil, err
+	}
+	var docsi driver.SchedulerDocs
+	err = c.do(ctx, &Operation{Name: "SchedulerDocs", DB: replicatorDB, Options: opts}, false, func(ctx context.Context) error {
+		var e error
+		docsi, e = scheduler.SchedulerDocs(ctx, replicatorDB, opts)
+		return e
+	})
+	if err != nil {
+		return nil, err
+	}
+	return newSchedulerDocs(ctx, docsi), nil
+}
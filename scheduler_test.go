@@ -0,0 +1,184 @@
+package kivik
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"testing"
+	"time"
+
+	"github.com/flimzy/diff"
+	"github.com/flimzy/testy"
+	"github.com/go-kivik/kivik/driver"
+	"github.com/go-kivik/kivik/mock"
+)
+
+func TestSchedulerJobs(t *testing.T) {
+	ts := time.Date(2018, 1, 2, 3, 4, 5, 0, time.UTC)
+	tests := []struct {
+		name     string
+		client   driver.Client
+		options  Options
+		expected []*SchedulerJob
+		status   int
+		err      string
+	}{
+		{
+			name:   "non-scheduler",
+			client: &mock.Client{},
+			status: StatusNotImplemented,
+			err:    "kivik: driver does not support the replication scheduler",
+		},
+		{
+			name: "client error",
+			client: &mock.Scheduler{
+				SchedulerJobsFunc: func(_ context.Context, _ map[string]interface{}) (driver.SchedulerJobs, error) {
+					return nil, errors.New("client error")
+				},
+			},
+			status: StatusInternalServerError,
+			err:    "client error",
+		},
+		{
+			name: "success",
+			client: &mock.Scheduler{
+				SchedulerJobsFunc: func(_ context.Context, opts map[string]interface{}) (driver.SchedulerJobs, error) {
+					if d := diff.Interface(testOptions, opts); d != nil {
+						return nil, fmt.Errorf("Unexpected options:\n%s", d)
+					}
+					var done bool
+					return &mock.SchedulerJobs{
+						NextFunc: func(job *driver.SchedulerJob) error {
+							if done {
+								return io.EOF
+							}
+							done = true
+							*job = driver.SchedulerJob{
+								JobID:   "abc",
+								Source:  "foo",
+								History: []driver.SchedulerJobEvent{{Type: "crashed", Reason: "oops", Timestamp: ts}},
+							}
+							return nil
+						},
+						CloseFunc: func() error { return nil },
+					}, nil
+				},
+			},
+			options: testOptions,
+			expected: []*SchedulerJob{
+				{
+					JobID:   "abc",
+					Source:  "foo",
+					History: []SchedulerJobEvent{{Type: "crashed", Reason: "oops", Timestamp: ts}},
+				},
+			},
+		},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			c := &Client{driverClient: test.client}
+			jobs, err := c.SchedulerJobs(context.Background(), test.options)
+			testy.StatusError(t, test.err, test.status, err)
+			var result []*SchedulerJob
+			for jobs.Next() {
+				result = append(result, jobs.Job())
+			}
+			if err := jobs.Err(); err != nil {
+				t.Fatal(err)
+			}
+			if d := diff.Interface(test.expected, result); d != nil {
+				t.Error(d)
+			}
+		})
+	}
+}
+
+func TestSchedulerDocs(t *testing.T) {
+	tests := []struct {
+		name     string
+		client   driver.Client
+		expected []*SchedulerDoc
+		status   int
+		err      string
+	}{
+		{
+			name:   "non-scheduler",
+			client: &mock.Client{},
+			status: StatusNotImplemented,
+			err:    "kivik: driver does not support the replication scheduler",
+		},
+		{
+			name: "client error",
+			client: &mock.Scheduler{
+				SchedulerDocsFunc: func(_ context.Context, _ string, _ map[string]interface{}) (driver.SchedulerDocs, error) {
+					return nil, errors.New("client error")
+				},
+			},
+			status: StatusInternalServerError,
+			err:    "client error",
+		},
+		{
+			name: "success",
+			client: &mock.Scheduler{
+				SchedulerDocsFunc: func(_ context.Context, replicatorDB string, _ map[string]interface{}) (driver.SchedulerDocs, error) {
+					if replicatorDB != "_replicator" {
+						return nil, fmt.Errorf("Unexpected replicator DB: %s", replicatorDB)
+					}
+					var done bool
+					return &mock.SchedulerDocs{
+						NextFunc: func(doc *driver.SchedulerDoc) error {
+							if done {
+								return io.EOF
+							}
+							done = true
+							*doc = driver.SchedulerDoc{
+								DocID:      "foo",
+								State:      "crashing",
+								ErrorCount: 2,
+								Info:       map[string]interface{}{"error": "db_not_found"},
+							}
+							return nil
+						},
+						CloseFunc: func() error { return nil },
+					}, nil
+				},
+			},
+			expected: []*SchedulerDoc{
+				{
+					DocID:      "foo",
+					State:      "crashing",
+					ErrorCount: 2,
+					Info:       map[string]interface{}{"error": "db_not_found"},
+				},
+			},
+		},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			c := &Client{driverClient: test.client}
+			docs, err := c.SchedulerDocs(context.Background(), "_replicator")
+			testy.StatusError(t, test.err, test.status, err)
+			var result []*SchedulerDoc
+			for docs.Next() {
+				result = append(result, docs.Doc())
+			}
+			if err := docs.Err(); err != nil {
+				t.Fatal(err)
+			}
+			if d := diff.Interface(test.expected, result); d != nil {
+				t.Error(d)
+			}
+		})
+	}
+}
+
+func TestSchedulerDocLastError(t *testing.T) {
+	doc := &SchedulerDoc{Info: map[string]interface{}{"error": "db_not_found"}}
+	if e := doc.LastError(); e != "db_not_found" {
+		t.Errorf("Unexpected result: %s", e)
+	}
+	if e := (&SchedulerDoc{}).LastError(); e != "" {
+		t.Errorf("Unexpected result: %s", e)
+	}
+}
@@ -0,0 +1,111 @@
+package kivik
+
+import (
+	"context"
+	"encoding/json"
+
+	"github.com/go-kivik/kivik/driver"
+	"github.com/go-kivik/kivik/errors"
+)
+
+// SearchRows is an iterator over the results of a full text search.
+type SearchRows struct {
+	*Rows
+}
+
+// SearchHit is a single full text search result.
+type SearchHit struct {
+	// ID is the ID of the matching document.
+	ID string `json:"id"`
+	// Order is the sort order of the result. When sorted by relevance, the
+	// default, the first element is the score of the match.
+	Order []interface{} `json:"order"`
+	// Fields contains the raw JSON values of the fields stored in the index.
+	Fields json.RawMessage `json:"fields"`
+	// Highlights contains the highlighted fragments of each field, if
+	// requested with the highlight_fields option.
+	Highlights map[string][]string `json:"highlights"`
+}
+
+// Search queries the search index named index, in design document ddoc, with
+// a Lucene query. Options, such as sort, limit, bookmark, counts and ranges,
+// are passed to the driver. Use Hit to read the ranking, stored fields and
+// highlights of each result, Bookmark to page through results, and Counts and
+// Ranges for faceted results.
+//
+// See https://docs.couchdb.org/en/3.2.0/ddocs/search.html
+func (db *DB) Search(ctx context.Context, ddoc, index, query string, options ...Options) (*SearchRows, error) {
+	searcher, ok := db.driverDB.(driver.Searcher)
+	if !ok {
+		return nil, errors.Status(StatusNotImplemented, "kivik: driver does not support search")
+	}
+	if ddoc == "" {
+		return nil, missingArg("ddoc")
+	}
+	if index == "" {
+		return nil, missingArg("index")
+	}
+	opts, err := mergeOptions(options...)
+	if err != nil {
+		return nil, err
+	}
+	rows, err := db.doRows(ctx, &Operation{Name: "Search", Options: opts}, func(ctx context.Context) (driver.Rows, error) {
+		return searcher.Search(ctx, ddoc, index, query, opts)
+	})
+	if err != nil {
+		return nil, err
+	}
+	return &SearchRows{Rows: rows}, nil
+}
+
+// Hit returns the current search result.
+func (r *SearchRows) Hit() (*SearchHit, error) {
+	hit := &SearchHit{}
+	if err := r.ScanValue(hit); err != nil {
+		return nil, err
+	}
+	hit.ID = r.ID()
+	return hit, nil
+}
+
+// ScanFields unmarshals the stored fields of the current result into dest.
+func (r *SearchRows) ScanFields(dest interface{}) error {
+	var value struct {
+		Fields json.RawMessage `json:"fields"`
+	}
+	if err := r.ScanValue(&value); err != nil {
+		return err
+	}
+	return scan(r.codec, dest, value.Fields)
+}
+
+// Counts returns the facet counts requested with the counts option, by field
+// and value. It is only valid after all results have been read.
+func (r *SearchRows) Counts() (map[string]map[string]int64, error) {
+	facets, ok := r.rowsi.(driver.SearchFacets)
+	if !ok {
+		return nil, nil
+	}
+	return decodeFacets(facets.Counts())
+}
+
+// Ranges returns the facet counts requested with the ranges option, by field
+// and range label. It is only valid after all results have been read.
+func (r *SearchRows) Ranges() (map[string]map[string]int64, error) {
+	facets, ok := r.rowsi.(driver.SearchFacets)
+	if !ok {
+		return nil, nil
+	}
+	return decodeFacets(facets.Ranges())
+}
+
+func decodeFacets(raw json.RawMessage) (map[string]map[string]int64, error) {
+	if raw == nil {
+		return nil, nil
+	}
+	var facets map[string]map[string]int64
+	if err := json.Unmarshal(raw, &facets); err != nil {
+		return nil, errors.WrapStatus(StatusBadResponse, err)
+	}
+	return facets, nil
+}
@@ -0,0 +1,141 @@
+package kivik
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"testing"
+
+	"github.com/flimzy/diff"
+	"github.com/flimzy/testy"
+	"github.com/go-kivik/kivik/driver"
+	"github.com/go-kivik/kivik/mock"
+)
+
+func TestSearch(t *testing.T) {
+	tests := []struct {
+		name   string
+		db     *DB
+		ddoc   string
+		index  string
+		status int
+		err    string
+	}{
+		{
+			name:   "not implemented",
+			db:     &DB{driverDB: &mock.DB{}},
+			ddoc:   "foo",
+			index:  "bar",
+			status: StatusNotImplemented,
+			err:    "kivik: driver does not support search",
+		},
+		{
+			name:   "missing ddoc",
+			db:     &DB{driverDB: &mock.Searcher{}},
+			index:  "bar",
+			status: StatusBadRequest,
+			err:    "kivik: ddoc required",
+		},
+		{
+			name:   "missing index",
+			db:     &DB{driverDB: &mock.Searcher{}},
+			ddoc:   "foo",
+			status: StatusBadRequest,
+			err:    "kivik: index required",
+		},
+		{
+			name: "db error",
+			db: &DB{driverDB: &mock.Searcher{
+				SearchFunc: func(_ context.Context, _, _, _ string, _ map[string]interface{}) (driver.Rows, error) {
+					return nil, fmt.Errorf("search failed")
+				},
+			}},
+			ddoc:   "foo",
+			index:  "bar",
+			status: StatusInternalServerError,
+			err:    "search failed",
+		},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			_, err := test.db.Search(context.Background(), test.ddoc, test.index, "*:*")
+			testy.StatusError(t, test.err, test.status, err)
+		})
+	}
+}
+
+func TestSearchRows(t *testing.T) {
+	db := &DB{driverDB: &mock.Searcher{
+		SearchFunc: func(_ context.Context, ddoc, index, query string, opts map[string]interface{}) (driver.Rows, error) {
+			if ddoc != "_design/foo" || index != "bar" || query != "name:kivik" {
+				return nil, fmt.Errorf("Unexpected request: %s %s %s", ddoc, index, query)
+			}
+			if d := diff.Interface(map[string]interface{}{"counts": `["type"]`}, opts); d != nil {
+				return nil, fmt.Errorf("Unexpected options:\n%s", d)
+			}
+			values := []string{
+				`{"id":"a","order":[1.5,0],"fields":{"name":"kivik"},"highlights":{"name":["<em>kivik</em>"]}}`,
+			}
+			return &mock.SearchFacets{
+				Rows: &mock.Rows{
+					NextFunc: func(row *driver.Row) error {
+						if len(values) == 0 {
+							return io.EOF
+						}
+						row.ID = "a"
+						row.Value, values = json.RawMessage(values[0]), values[1:]
+						return nil
+					},
+					CloseFunc: func() error { return nil },
+				},
+				CountsFunc: func() json.RawMessage { return json.RawMessage(`{"type":{"lib":3,"app":1}}`) },
+				RangesFunc: func() json.RawMessage { return nil },
+			}, nil
+		},
+	}}
+	rows, err := db.Search(context.Background(), "_design/foo", "bar", "name:kivik", Param("counts", `["type"]`))
+	if err != nil {
+		t.Fatal(err)
+	}
+	var hits []*SearchHit
+	for rows.Next() {
+		hit, err := rows.Hit()
+		if err != nil {
+			t.Fatal(err)
+		}
+		hits = append(hits, hit)
+		var fields struct {
+			Name string `json:"name"`
+		}
+		if err := rows.ScanFields(&fields); err != nil {
+			t.Fatal(err)
+		}
+		if fields.Name != "kivik" {
+			t.Errorf("Unexpected fields: %v", fields)
+		}
+	}
+	if err := rows.Err(); err != nil {
+		t.Fatal(err)
+	}
+	expected := []*SearchHit{{
+		ID:         "a",
+		Order:      []interface{}{1.5, 0.0},
+		Fields:     json.RawMessage(`{"name":"kivik"}`),
+		Highlights: map[string][]string{"name": {"<em>kivik</em>"}},
+	}}
+	if d := diff.Interface(expected, hits); d != nil {
+		t.Error(d)
+	}
+	counts, err := rows.Counts()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if d := diff.Interface(map[string]map[string]int64{"type": {"lib": 3, "app": 1}}, counts); d != nil {
+		t.Error(d)
+	}
+	ranges, err := rows.Ranges()
+	if err != nil || ranges != nil {
+		t.Errorf("Unexpected ranges: %v, %v", ranges, err)
+	}
+}
@@ -0,0 +1,95 @@
+package kivik
+
+import (
+	"bytes"
+	"encoding/json"
+	"strconv"
+	"strings"
+)
+
+// SequenceID is a database update sequence ID. CouchDB 1.x uses integer
+// sequences, while CouchDB 2.x and later use opaque strings, prefixed with an
+// approximate count of updates, such as "42-g1AAAAB...". A SequenceID may be
+// unmarshaled from either form, so that code using it is portable across
+// server versions.
+type SequenceID string
+
+// SequenceNow is the special sequence which refers to the current end of the
+// changes feed, as passed in the since option.
+const SequenceNow SequenceID = "now"
+
+var _ json.Unmarshaler = new(SequenceID)
+
+// UnmarshalJSON satisfies the json.Unmarshaler interface. It accepts a JSON
+// string, number or null.
+func (id *SequenceID) UnmarshalJSON(data []byte) error {
+	data = bytes.TrimSpace(data)
+	switch {
+	case bytes.Equal(data, []byte("null")):
+		*id = ""
+		return nil
+	case len(data) > 0 && data[0] == '"':
+		var s string
+		if err := json.Unmarshal(data, &s); err != nil {
+			return err
+		}
+		*id = SequenceID(s)
+		return nil
+	}
+	var n json.Number
+	if err := json.Unmarshal(data, &n); err != nil {
+		return err
+	}
+	*id = SequenceID(n)
+	return nil
+}
+
+// Number returns the integer sequence number of a CouchDB 1.x sequence, or
+// the approximate update count prefix of a CouchDB 2.x sequence. ok is false
+// if id has no numeric part.
+func (id SequenceID) Number() (n int64, ok bool) {
+	s := string(id)
+	if i := strings.IndexByte(s, '-'); i > 0 {
+		s = s[:i]
+	}
+	n, err := strconv.ParseInt(s, 10, 64)
+	return n, err == nil
+}
+
+// Compare compares id to other, in the order the sequences appear in the
+// changes feed. The result is negative if id precedes other, positive if it
+// follows it, and zero if they are equal.
+//
+// The empty sequence precedes all others, and SequenceNow follows all others.
+// Sequences are otherwise ordered by their Number. CouchDB 2.x sequences
+// with equal numbers, but different opaque suffixes, cannot be ordered
+// reliably; they are compared as strings, which is consistent, but
+// arbitrary.
+func (id SequenceID) Compare(other SequenceID) int {
+	if r1, r2 := id.rank(), other.rank(); r1 != r2 {
+		return r1 - r2
+	}
+	n1, _ := id.Number()
+	n2, _ := other.Number()
+	switch {
+	case n1 < n2:
+		return -1
+	case n1 > n2:
+		return 1
+	}
+	return strings.Compare(string(id), string(other))
+}
+
+// rank orders the kinds of sequence: empty, numeric, non-numeric, then now.
+func (id SequenceID) rank() int {
+	switch id {
+	case "":
+		return 0
+	case SequenceNow:
+		return 3
+	}
+	if _, ok := id.Number(); ok {
+		return 1
+	}
+	return 2
+}
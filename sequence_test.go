@@ -0,0 +1,80 @@
+package kivik
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/flimzy/testy"
+)
+
+func TestSequenceIDUnmarshalJSON(t *testing.T) {
+	tests := []struct {
+		name     string
+		input    string
+		expected SequenceID
+		err      string
+	}{
+		{"integer", `42`, "42", ""},
+		{"string", `"42-g1AAAA"`, "42-g1AAAA", ""},
+		{"escaped string", `"a\"b"`, `a"b`, ""},
+		{"null", `null`, "", ""},
+		{"invalid", `[]`, "", "json: cannot unmarshal array into Go value of type json.Number"},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			var result SequenceID
+			err := json.Unmarshal([]byte(test.input), &result)
+			testy.Error(t, test.err, err)
+			if result != test.expected {
+				t.Errorf("Unexpected result: %q", result)
+			}
+		})
+	}
+}
+
+func TestSequenceIDCompare(t *testing.T) {
+	tests := []struct {
+		a, b     SequenceID
+		expected int
+	}{
+		{"1", "1", 0},
+		{"1", "2", -1},
+		{"10", "9", 1},
+		{"", "0", -1},
+		{"now", "99", 1},
+		{"now", "now", 0},
+		{"9-g1AAAA", "10-g1AAAA", -1},
+		{"10-g1AAAA", "10-g1AAAA", 0},
+		{"10-g1AAAA", "9", 1},
+		{"opaque", "99", 1},
+		{"opaque", "now", -1},
+	}
+	for _, test := range tests {
+		t.Run(string(test.a)+"/"+string(test.b), func(t *testing.T) {
+			result := test.a.Compare(test.b)
+			if sign(result) != test.expected {
+				t.Errorf("Expected %d, got %d", test.expected, result)
+			}
+		})
+	}
+}
+
+func sign(n int) int {
+	switch {
+	case n < 0:
+		return -1
+	case n > 0:
+		return 1
+	}
+	return 0
+}
+
+func TestStatsUpdateSeq(t *testing.T) {
+	var stats DBStats
+	if err := json.Unmarshal([]byte(`{"db_name":"foo","update_seq":5}`), &stats); err != nil {
+		t.Fatal(err)
+	}
+	if stats.UpdateSeq != "5" {
+		t.Errorf("Unexpected update seq: %q", stats.UpdateSeq)
+	}
+}
@@ -31,7 +31,12 @@ type Session struct {
 // Session returns information about the currently authenticated user.
 func (c *Client) Session(ctx context.Context) (*Session, error) {
 	if sessioner, ok := c.driverClient.(driver.Sessioner); ok {
-		session, err := sessioner.Session(ctx)
+		var session *driver.Session
+		err := c.do(ctx, &Operation{Name: "Session"}, false, func(ctx context.Context) error {
+			var e error
+			session, e = sessioner.Session(ctx)
+			return e
+		})
 		if err != nil {
 			return nil, err
 		}
@@ -40,3 +45,14 @@ func (c *Client) Session(ctx context.Context) (*Session, error) {
 	}
 	return nil, errors.Status(StatusNotImplemented, "kivik: driver does not support sessions")
 }
+
+// DeleteSession ends the currently authenticated session. Subsequent requests
+// will be made anonymously, unless the client is re-authenticated.
+func (c *Client) DeleteSession(ctx context.Context) error {
+	if deleter, ok := c.driverClient.(driver.SessionDeleter); ok {
+		return c.do(ctx, &Operation{Name: "DeleteSession"}, true, func(ctx context.Context) error {
+			return deleter.DeleteSession(ctx)
+		})
+	}
+	return errors.Status(StatusNotImplemented, "kivik: driver does not support deleting sessions")
+}
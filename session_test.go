@@ -6,6 +6,7 @@ import (
 	"testing"
 
 	"github.com/flimzy/diff"
+	"github.com/flimzy/testy"
 	"github.com/go-kivik/kivik/driver"
 	"github.com/go-kivik/kivik/mock"
 )
@@ -70,3 +71,44 @@ func TestSession(t *testing.T) {
 		})
 	}
 }
+
+func TestDeleteSession(t *testing.T) {
+	tests := []struct {
+		name   string
+		client driver.Client
+		status int
+		err    string
+	}{
+		{
+			name:   "driver doesn't implement SessionDeleter",
+			client: &mock.Client{},
+			status: StatusNotImplemented,
+			err:    "kivik: driver does not support deleting sessions",
+		},
+		{
+			name: "driver returns error",
+			client: &mock.SessionDeleter{
+				DeleteSessionFunc: func(_ context.Context) error {
+					return errors.New("delete error")
+				},
+			},
+			status: StatusInternalServerError,
+			err:    "delete error",
+		},
+		{
+			name: "success",
+			client: &mock.SessionDeleter{
+				DeleteSessionFunc: func(_ context.Context) error {
+					return nil
+				},
+			},
+		},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			client := &Client{driverClient: test.client}
+			err := client.DeleteSession(context.Background())
+			testy.StatusError(t, test.err, test.status, err)
+		})
+	}
+}
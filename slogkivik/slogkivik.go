@@ -0,0 +1,40 @@
+// Package slogkivik adapts a log/slog Logger for use as a kivik.Logger.
+package slogkivik
+
+import (
+	"context"
+	"log/slog"
+
+	"github.com/go-kivik/kivik"
+)
+
+type logger struct {
+	*slog.Logger
+}
+
+var _ kivik.Logger = &logger{}
+
+// New returns a kivik.Logger which logs to l. If l is nil, slog.Default() is
+// used.
+func New(l *slog.Logger) kivik.Logger {
+	if l == nil {
+		l = slog.Default()
+	}
+	return &logger{l}
+}
+
+func (l *logger) Debug(msg string, keyvals ...interface{}) {
+	l.Log(context.Background(), slog.LevelDebug, msg, keyvals...)
+}
+
+func (l *logger) Info(msg string, keyvals ...interface{}) {
+	l.Log(context.Background(), slog.LevelInfo, msg, keyvals...)
+}
+
+func (l *logger) Warn(msg string, keyvals ...interface{}) {
+	l.Log(context.Background(), slog.LevelWarn, msg, keyvals...)
+}
+
+func (l *logger) Error(msg string, keyvals ...interface{}) {
+	l.Log(context.Background(), slog.LevelError, msg, keyvals...)
+}
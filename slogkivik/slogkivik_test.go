@@ -0,0 +1,33 @@
+package slogkivik
+
+import (
+	"bytes"
+	"log/slog"
+	"testing"
+)
+
+func TestLogger(t *testing.T) {
+	buf := &bytes.Buffer{}
+	handler := slog.NewTextHandler(buf, &slog.HandlerOptions{
+		Level: slog.LevelDebug,
+		ReplaceAttr: func(_ []string, a slog.Attr) slog.Attr {
+			if a.Key == slog.TimeKey {
+				return slog.Attr{}
+			}
+			return a
+		},
+	})
+	l := New(slog.New(handler))
+	l.Debug("debug", "a", 1)
+	l.Info("info")
+	l.Warn("warn", "db", "foo", "retry", 2)
+	l.Error("error", "odd")
+	expected := `level=DEBUG msg=debug a=1
+level=INFO msg=info
+level=WARN msg=warn db=foo retry=2
+level=ERROR msg=error !BADKEY=odd
+`
+	if buf.String() != expected {
+		t.Errorf("Unexpected output:\n%s", buf.String())
+	}
+}
@@ -0,0 +1,107 @@
+package kivik
+
+import (
+	"context"
+	"encoding/json"
+
+	"github.com/go-kivik/kivik/driver"
+	"github.com/go-kivik/kivik/errors"
+)
+
+// Stat is a single server statistic.
+type Stat struct {
+	// Type is the stat type, such as "counter", "gauge" or "histogram".
+	Type string `json:"type"`
+	// Desc is a human-readable description of the stat.
+	Desc string `json:"desc"`
+	// Value is the raw JSON value of the stat. Use the Float or Histogram
+	// methods to decode it.
+	Value json.RawMessage `json:"value"`
+}
+
+// Histogram is the value of a histogram stat.
+type Histogram struct {
+	N                 int64        `json:"n"`
+	Min               float64      `json:"min"`
+	Max               float64      `json:"max"`
+	ArithmeticMean    float64      `json:"arithmetic_mean"`
+	Median            float64      `json:"median"`
+	StandardDeviation float64      `json:"standard_deviation"`
+	Percentile        [][2]float64 `json:"percentile"`
+}
+
+// Float returns the value of a counter or gauge stat.
+func (s *Stat) Float() (float64, error) {
+	var f float64
+	err := json.Unmarshal(s.Value, &f)
+	return f, errors.WrapStatus(StatusBadResponse, err)
+}
+
+// Histogram returns the value of a histogram stat.
+func (s *Stat) Histogram() (*Histogram, error) {
+	h := new(Histogram)
+	if err := json.Unmarshal(s.Value, h); err != nil {
+		return nil, errors.WrapStatus(StatusBadResponse, err)
+	}
+	return h, nil
+}
+
+// SystemStats contains Erlang VM statistics for a server node.
+type SystemStats struct {
+	// Uptime is the node uptime, in seconds.
+	Uptime int64 `json:"uptime"`
+	// Memory maps memory categories, such as "processes" and "binary", to
+	// their size in bytes.
+	Memory                  map[string]int64 `json:"memory"`
+	RunQueue                int64            `json:"run_queue"`
+	ETSTableCount           int64            `json:"ets_table_count"`
+	ProcessCount            int64            `json:"process_count"`
+	ProcessLimit            int64            `json:"process_limit"`
+	OSProcCount             int64            `json:"os_proc_count"`
+	InternalReplicationJobs int64            `json:"internal_replication_jobs"`
+}
+
+// NodeStats contains the statistics for a single server node.
+type NodeStats struct {
+	// Stats maps the dot-separated stat name, such as
+	// "couchdb.request_time", to its value.
+	Stats map[string]Stat
+	// System contains the system statistics, if supported by the server.
+	System *SystemStats
+}
+
+// Stats returns server node statistics, such as request counts, latencies
+// and memory usage. The "node" option may be used to select a node by name;
+// by default, the local node is used.
+//
+// See http://docs.couchdb.org/en/2.2.0/api/server/common.html#node-node-name-stats
+func (c *Client) Stats(ctx context.Context, options ...Options) (*NodeStats, error) {
+	stater, ok := c.driverClient.(driver.Stater)
+	if !ok {
+		return nil, errors.Status(StatusNotImplemented, "kivik: driver does not support server stats")
+	}
+	opts, err := mergeOptions(options...)
+	if err != nil {
+		return nil, err
+	}
+	var stats *driver.NodeStats
+	err = c.do(ctx, &Operation{Name: "Stats", Options: opts}, false, func(ctx context.Context) error {
+		var e error
+		stats, e = stater.Stats(ctx, opts)
+		return e
+	})
+	if err != nil {
+		return nil, err
+	}
+	result := &NodeStats{
+		Stats: make(map[string]Stat, len(stats.Stats)),
+	}
+	for name, stat := range stats.Stats {
+		result.Stats[name] = Stat(stat)
+	}
+	if stats.System != nil {
+		sys := SystemStats(*stats.System)
+		result.System = &sys
+	}
+	return result, nil
+}
@@ -0,0 +1,108 @@
+package kivik
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"testing"
+
+	"github.com/flimzy/diff"
+	"github.com/flimzy/testy"
+	"github.com/go-kivik/kivik/driver"
+	"github.com/go-kivik/kivik/mock"
+)
+
+func TestClientStats(t *testing.T) {
+	tests := []struct {
+		name     string
+		client   driver.Client
+		options  Options
+		expected *NodeStats
+		status   int
+		err      string
+	}{
+		{
+			name:   "non-stater",
+			client: &mock.Client{},
+			status: StatusNotImplemented,
+			err:    "kivik: driver does not support server stats",
+		},
+		{
+			name: "client error",
+			client: &mock.Stater{
+				StatsFunc: func(_ context.Context, _ map[string]interface{}) (*driver.NodeStats, error) {
+					return nil, errors.New("client error")
+				},
+			},
+			status: StatusInternalServerError,
+			err:    "client error",
+		},
+		{
+			name: "success",
+			client: &mock.Stater{
+				StatsFunc: func(_ context.Context, opts map[string]interface{}) (*driver.NodeStats, error) {
+					if d := diff.Interface(testOptions, opts); d != nil {
+						return nil, fmt.Errorf("Unexpected options:\n%s", d)
+					}
+					return &driver.NodeStats{
+						Stats: map[string]driver.Stat{
+							"couchdb.open_databases": {Type: "counter", Value: json.RawMessage("3")},
+						},
+						System: &driver.SystemStats{Uptime: 10},
+					}, nil
+				},
+			},
+			options: testOptions,
+			expected: &NodeStats{
+				Stats: map[string]Stat{
+					"couchdb.open_databases": {Type: "counter", Value: json.RawMessage("3")},
+				},
+				System: &SystemStats{Uptime: 10},
+			},
+		},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			c := &Client{driverClient: test.client}
+			result, err := c.Stats(context.Background(), test.options)
+			testy.StatusError(t, test.err, test.status, err)
+			if d := diff.Interface(test.expected, result); d != nil {
+				t.Error(d)
+			}
+		})
+	}
+}
+
+func TestStatFloat(t *testing.T) {
+	t.Run("gauge", func(t *testing.T) {
+		s := &Stat{Type: "gauge", Value: json.RawMessage("1.5")}
+		result, err := s.Float()
+		testy.Error(t, "", err)
+		if result != 1.5 {
+			t.Errorf("Unexpected result: %v", result)
+		}
+	})
+	t.Run("histogram", func(t *testing.T) {
+		s := &Stat{Type: "histogram", Value: json.RawMessage(`{"n":1}`)}
+		_, err := s.Float()
+		testy.StatusError(t, "json: cannot unmarshal object into Go value of type float64", StatusBadResponse, err)
+	})
+}
+
+func TestStatHistogram(t *testing.T) {
+	t.Run("histogram", func(t *testing.T) {
+		s := &Stat{Type: "histogram", Value: json.RawMessage(`{"n":2,"min":1,"max":3,"percentile":[[50,2]]}`)}
+		result, err := s.Histogram()
+		testy.Error(t, "", err)
+		expected := &Histogram{N: 2, Min: 1, Max: 3, Percentile: [][2]float64{{50, 2}}}
+		if d := diff.Interface(expected, result); d != nil {
+			t.Error(d)
+		}
+	})
+	t.Run("counter", func(t *testing.T) {
+		s := &Stat{Type: "counter", Value: json.RawMessage("3")}
+		_, err := s.Histogram()
+		testy.StatusError(t, "json: cannot unmarshal number into Go value of type kivik.Histogram", StatusBadResponse, err)
+	})
+}
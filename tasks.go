@@ -0,0 +1,81 @@
+package kivik
+
+import (
+	"context"
+
+	"github.com/go-kivik/kivik/driver"
+	"github.com/go-kivik/kivik/errors"
+)
+
+// Active task types, as reported in ActiveTask.Type.
+const (
+	TaskTypeIndexer            = "indexer"
+	TaskTypeDatabaseCompaction = "database_compaction"
+	TaskTypeViewCompaction     = "view_compaction"
+	TaskTypeReplication        = "replication"
+)
+
+// ActiveTask describes a running task. Which fields are populated depends on
+// the task Type.
+type ActiveTask struct {
+	// Type is the task type, such as TaskTypeIndexer.
+	Type string `json:"type"`
+	// PID is the process ID of the task.
+	PID string `json:"pid"`
+	// Node is the cluster node running the task.
+	Node string `json:"node"`
+	// StartedOn and UpdatedOn are Unix timestamps of when the task was
+	// started, and last updated.
+	StartedOn int64 `json:"started_on"`
+	UpdatedOn int64 `json:"updated_on"`
+	// Progress is the percentage of completion, for indexer and compaction
+	// tasks.
+	Progress int64 `json:"progress"`
+
+	// The following fields apply to indexer and compaction tasks.
+
+	Database     string `json:"database"`
+	DesignDoc    string `json:"design_document"`
+	Phase        string `json:"phase"`
+	ChangesDone  int64  `json:"changes_done"`
+	TotalChanges int64  `json:"total_changes"`
+
+	// The following fields apply to replication tasks.
+
+	ReplicationID         string `json:"replication_id"`
+	DocID                 string `json:"doc_id"`
+	Source                string `json:"source"`
+	Target                string `json:"target"`
+	Continuous            bool   `json:"continuous"`
+	DocsRead              int64  `json:"docs_read"`
+	DocsWritten           int64  `json:"docs_written"`
+	DocWriteFailures      int64  `json:"doc_write_failures"`
+	MissingRevisionsFound int64  `json:"missing_revisions_found"`
+	RevisionsChecked      int64  `json:"revisions_checked"`
+}
+
+// ActiveTasks returns a list of running tasks, such as view indexers,
+// compactions and replications. This may be polled to wait for a view build
+// to complete, for example.
+//
+// See http://docs.couchdb.org/en/2.2.0/api/server/common.html#active-tasks
+func (c *Client) ActiveTasks(ctx context.Context) ([]ActiveTask, error) {
+	tasker, ok := c.driverClient.(driver.ActiveTasker)
+	if !ok {
+		return nil, errors.Status(StatusNotImplemented, "kivik: driver does not support active tasks")
+	}
+	var tasks []driver.ActiveTask
+	err := c.do(ctx, &Operation{Name: "ActiveTasks"}, false, func(ctx context.Context) error {
+		var e error
+		tasks, e = tasker.ActiveTasks(ctx)
+		return e
+	})
+	if err != nil {
+		return nil, err
+	}
+	result := make([]ActiveTask, len(tasks))
+	for i, task := range tasks {
+		result[i] = ActiveTask(task)
+	}
+	return result, nil
+}
@@ -0,0 +1,64 @@
+package kivik
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/flimzy/diff"
+	"github.com/flimzy/testy"
+	"github.com/go-kivik/kivik/driver"
+	"github.com/go-kivik/kivik/mock"
+)
+
+func TestActiveTasks(t *testing.T) {
+	tests := []struct {
+		name     string
+		client   driver.Client
+		expected []ActiveTask
+		status   int
+		err      string
+	}{
+		{
+			name:   "non-tasker",
+			client: &mock.Client{},
+			status: StatusNotImplemented,
+			err:    "kivik: driver does not support active tasks",
+		},
+		{
+			name: "client error",
+			client: &mock.ActiveTasker{
+				ActiveTasksFunc: func(_ context.Context) ([]driver.ActiveTask, error) {
+					return nil, errors.New("client error")
+				},
+			},
+			status: StatusInternalServerError,
+			err:    "client error",
+		},
+		{
+			name: "success",
+			client: &mock.ActiveTasker{
+				ActiveTasksFunc: func(_ context.Context) ([]driver.ActiveTask, error) {
+					return []driver.ActiveTask{
+						{Type: "indexer", Database: "foo", DesignDoc: "_design/bar", Progress: 50},
+						{Type: "replication", Source: "foo", Target: "bar", DocsRead: 10},
+					}, nil
+				},
+			},
+			expected: []ActiveTask{
+				{Type: TaskTypeIndexer, Database: "foo", DesignDoc: "_design/bar", Progress: 50},
+				{Type: TaskTypeReplication, Source: "foo", Target: "bar", DocsRead: 10},
+			},
+		},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			c := &Client{driverClient: test.client}
+			result, err := c.ActiveTasks(context.Background())
+			testy.StatusError(t, test.err, test.status, err)
+			if d := diff.Interface(test.expected, result); d != nil {
+				t.Error(d)
+			}
+		})
+	}
+}
@@ -0,0 +1,91 @@
+//go:build go1.18
+// +build go1.18
+
+// Package typed provides generic, strongly typed wrappers around the Kivik
+// API, for Go 1.18 and later. Documents and values are unmarshaled directly
+// into the requested type, rather than through an interface{} destination.
+package typed
+
+import (
+	"context"
+
+	"github.com/go-kivik/kivik"
+)
+
+// Get fetches the requested document, and unmarshals it into a value of type
+// T.
+func Get[T any](ctx context.Context, db *kivik.DB, docID string, options ...kivik.Options) (T, error) {
+	var doc T
+	err := db.Get(ctx, docID, options...).ScanDoc(&doc)
+	return doc, err
+}
+
+// Rows is an iterator over query results, which yields each result as a value
+// of type T.
+type Rows[T any] struct {
+	*kivik.Rows
+	scan func(dest interface{}) error
+}
+
+// Docs wraps rows, to yield the document of each result row. The query must
+// include documents, such as by passing the include_docs option to Query, or
+// by calling Find.
+func Docs[T any](rows *kivik.Rows) *Rows[T] {
+	return &Rows[T]{Rows: rows, scan: rows.ScanDoc}
+}
+
+// Values wraps rows, to yield the value of each result row.
+func Values[T any](rows *kivik.Rows) *Rows[T] {
+	return &Rows[T]{Rows: rows, scan: rows.ScanValue}
+}
+
+// Current returns the current result, unmarshaled into a value of type T.
+func (r *Rows[T]) Current() (T, error) {
+	var v T
+	err := r.scan(&v)
+	return v, err
+}
+
+// All reads all remaining results, and closes the iterator.
+func (r *Rows[T]) All() ([]T, error) {
+	defer r.Close() // nolint: errcheck
+	var all []T
+	for r.Next() {
+		v, err := r.Current()
+		if err != nil {
+			return nil, err
+		}
+		all = append(all, v)
+	}
+	return all, r.Err()
+}
+
+// AllDocs returns an iterator over all documents in the database, as values
+// of type T. The include_docs option is set automatically.
+func AllDocs[T any](ctx context.Context, db *kivik.DB, options ...kivik.Options) (*Rows[T], error) {
+	rows, err := db.AllDocs(ctx, append(options[:len(options):len(options)], kivik.IncludeDocs())...)
+	if err != nil {
+		return nil, err
+	}
+	return Docs[T](rows), nil
+}
+
+// Query executes the specified view function, and returns an iterator over
+// the values emitted, as values of type T.
+func Query[T any](ctx context.Context, db *kivik.DB, ddoc, view string, options ...kivik.Options) (*Rows[T], error) {
+	rows, err := db.Query(ctx, ddoc, view, options...)
+	if err != nil {
+		return nil, err
+	}
+	return Values[T](rows), nil
+}
+
+// Find executes a Mango query, and returns an iterator over the matching
+// documents, as values of type T.
+func Find[T any](ctx context.Context, db *kivik.DB, query interface{}) (*Rows[T], error) {
+	rows, err := db.Find(ctx, query)
+	if err != nil {
+		return nil, err
+	}
+	return Docs[T](rows), nil
+}
@@ -0,0 +1,142 @@
+//go:build go1.18
+// +build go1.18
+
+package typed
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"strings"
+	"testing"
+
+	"github.com/flimzy/diff"
+	"github.com/flimzy/testy"
+
+	"github.com/go-kivik/kivik"
+	"github.com/go-kivik/kivik/driver"
+	"github.com/go-kivik/kivik/errors"
+	"github.com/go-kivik/kivik/mock"
+)
+
+type widget struct {
+	ID    string `json:"_id"`
+	Color string `json:"color"`
+}
+
+func mockRows(rows ...driver.Row) *mock.Rows {
+	return &mock.Rows{
+		NextFunc: func(row *driver.Row) error {
+			if len(rows) == 0 {
+				return io.EOF
+			}
+			*row, rows = rows[0], rows[1:]
+			return nil
+		},
+		CloseFunc: func() error { return nil },
+	}
+}
+
+func init() {
+	kivik.Register("typed-mock", &mock.Driver{
+		NewClientFunc: func(_ context.Context, _ string) (driver.Client, error) {
+			return &mock.Client{
+				DBFunc: func(_ context.Context, _ string, _ map[string]interface{}) (driver.DB, error) {
+					return &mock.DB{
+						GetFunc: func(_ context.Context, docID string, _ map[string]interface{}) (*driver.Document, error) {
+							if docID != "foo" {
+								return nil, errors.Status(kivik.StatusNotFound, "missing")
+							}
+							return &driver.Document{
+								Body: ioutil.NopCloser(strings.NewReader(`{"_id":"foo","color":"red"}`)),
+							}, nil
+						},
+						AllDocsFunc: func(_ context.Context, opts map[string]interface{}) (driver.Rows, error) {
+							if opts["include_docs"] != true {
+								return nil, fmt.Errorf("Unexpected options: %v", opts)
+							}
+							return mockRows(
+								driver.Row{ID: "a", Doc: json.RawMessage(`{"_id":"a","color":"red"}`)},
+								driver.Row{ID: "b", Doc: json.RawMessage(`{"_id":"b","color":"blue"}`)},
+							), nil
+						},
+						QueryFunc: func(_ context.Context, _, _ string, _ map[string]interface{}) (driver.Rows, error) {
+							return mockRows(
+								driver.Row{ID: "a", Value: json.RawMessage(`1`)},
+								driver.Row{ID: "b", Value: json.RawMessage(`"two"`)},
+							), nil
+						},
+					}, nil
+				},
+			}, nil
+		},
+	})
+}
+
+func testDB(t *testing.T) *kivik.DB {
+	client, err := kivik.New(context.Background(), "typed-mock", "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	db, err := client.DB(context.Background(), "foo")
+	if err != nil {
+		t.Fatal(err)
+	}
+	return db
+}
+
+func TestGet(t *testing.T) {
+	db := testDB(t)
+	t.Run("success", func(t *testing.T) {
+		doc, err := Get[widget](context.Background(), db, "foo")
+		if err != nil {
+			t.Fatal(err)
+		}
+		if d := diff.Interface(widget{ID: "foo", Color: "red"}, doc); d != nil {
+			t.Error(d)
+		}
+	})
+	t.Run("not found", func(t *testing.T) {
+		_, err := Get[widget](context.Background(), db, "bar")
+		testy.StatusError(t, "missing", kivik.StatusNotFound, err)
+	})
+}
+
+func TestAllDocs(t *testing.T) {
+	rows, err := AllDocs[widget](context.Background(), testDB(t))
+	if err != nil {
+		t.Fatal(err)
+	}
+	docs, err := rows.All()
+	if err != nil {
+		t.Fatal(err)
+	}
+	expected := []widget{{ID: "a", Color: "red"}, {ID: "b", Color: "blue"}}
+	if d := diff.Interface(expected, docs); d != nil {
+		t.Error(d)
+	}
+}
+
+func TestQuery(t *testing.T) {
+	rows, err := Query[int](context.Background(), testDB(t), "ddoc", "view")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !rows.Next() {
+		t.Fatal("Expected a row")
+	}
+	if v, err := rows.Current(); err != nil || v != 1 {
+		t.Errorf("Unexpected result: %v, %v", v, err)
+	}
+	if rows.ID() != "a" {
+		t.Errorf("Unexpected ID: %s", rows.ID())
+	}
+	if !rows.Next() {
+		t.Fatal("Expected a row")
+	}
+	_, err = rows.Current()
+	testy.Error(t, "json: cannot unmarshal string into Go value of type int", err)
+	_ = rows.Close()
+}
@@ -76,15 +76,43 @@ func (f *DBUpdates) Seq() string {
 	return f.curVal.(*driver.DBUpdate).Seq
 }
 
-// DBUpdates begins polling for database updates.
-func (c *Client) DBUpdates() (*DBUpdates, error) {
-	updater, ok := c.driverClient.(driver.DBUpdater)
-	if !ok {
+// DBUpdates begins polling for database updates. By default, the feed
+// returns all updates since the server started, then terminates. To wait for
+// further updates, pass the "feed" option with a value of "longpoll" or
+// "continuous". In continuous mode, the feed remains open until explicitly
+// closed, ctx is cancelled, or an error is encountered.
+//
+// Drivers which implement only driver.DBUpdater accept no options.
+// See http://docs.couchdb.org/en/2.0.0/api/server/common.html#db-updates
+func (c *Client) DBUpdates(ctx context.Context, options ...Options) (*DBUpdates, error) {
+	opts, err := mergeOptions(options...)
+	if err != nil {
+		return nil, err
+	}
+	var updates func(context.Context) (driver.DBUpdates, error)
+	switch updater := c.driverClient.(type) {
+	case driver.ContextDBUpdater:
+		updates = func(ctx context.Context) (driver.DBUpdates, error) {
+			return updater.DBUpdates(ctx, opts)
+		}
+	case driver.DBUpdater:
+		if len(opts) > 0 {
+			return nil, errors.Status(StatusNotImplemented, "kivik: driver does not support DBUpdates options")
+		}
+		updates = func(_ context.Context) (driver.DBUpdates, error) {
+			return updater.DBUpdates()
+		}
+	default:
 		return nil, errors.Status(StatusNotImplemented, "kivik: driver does not implement DBUpdater")
 	}
-	updatesi, err := updater.DBUpdates()
+	var updatesi driver.DBUpdates
+	err = c.do(ctx, &Operation{Name: "DBUpdates", Options: opts}, false, func(ctx context.Context) error {
+		var e error
+		updatesi, e = updates(ctx)
+		return e
+	})
 	if err != nil {
 		return nil, err
 	}
-	return newDBUpdates(context.Background(), updatesi), nil
+	return newDBUpdates(ctx, updatesi), nil
 }
@@ -3,6 +3,7 @@ package kivik
 import (
 	"context"
 	"errors"
+	"fmt"
 	"testing"
 
 	"github.com/flimzy/diff"
@@ -161,6 +162,7 @@ func TestDBUpdates(t *testing.T) {
 	tests := []struct {
 		name     string
 		client   *Client
+		options  Options
 		expected *DBUpdates
 		status   int
 		err      string
@@ -176,8 +178,8 @@ func TestDBUpdates(t *testing.T) {
 		{
 			name: "db error",
 			client: &Client{
-				driverClient: &mock.DBUpdater{
-					DBUpdatesFunc: func() (driver.DBUpdates, error) {
+				driverClient: &mock.ContextDBUpdater{
+					DBUpdatesFunc: func(_ context.Context, _ map[string]interface{}) (driver.DBUpdates, error) {
 						return nil, errors.New("db error")
 					},
 				},
@@ -187,6 +189,29 @@ func TestDBUpdates(t *testing.T) {
 		},
 		{
 			name: "success",
+			client: &Client{
+				driverClient: &mock.ContextDBUpdater{
+					DBUpdatesFunc: func(_ context.Context, opts map[string]interface{}) (driver.DBUpdates, error) {
+						if d := diff.Interface(testOptions, opts); d != nil {
+							return nil, fmt.Errorf("Unexpected options: %s", d)
+						}
+						return &mock.DBUpdates{ID: "a"}, nil
+					},
+				},
+			},
+			options: testOptions,
+			expected: &DBUpdates{
+				iter: &iter{
+					feed: &updatesIterator{
+						DBUpdates: &mock.DBUpdates{ID: "a"},
+					},
+					curVal: &driver.DBUpdate{},
+				},
+				updatesi: &mock.DBUpdates{ID: "a"},
+			},
+		},
+		{
+			name: "legacy DBUpdater",
 			client: &Client{
 				driverClient: &mock.DBUpdater{
 					DBUpdatesFunc: func() (driver.DBUpdates, error) {
@@ -204,10 +229,19 @@ func TestDBUpdates(t *testing.T) {
 				updatesi: &mock.DBUpdates{ID: "a"},
 			},
 		},
+		{
+			name: "legacy DBUpdater with options",
+			client: &Client{
+				driverClient: &mock.DBUpdater{},
+			},
+			options: testOptions,
+			status:  StatusNotImplemented,
+			err:     "kivik: driver does not support DBUpdates options",
+		},
 	}
 	for _, test := range tests {
 		t.Run(test.name, func(t *testing.T) {
-			result, err := test.client.DBUpdates()
+			result, err := test.client.DBUpdates(context.Background(), test.options)
 			testy.StatusError(t, test.err, test.status, err)
 			result.cancel = nil // Determinism
 			if d := diff.Interface(test.expected, result); d != nil {
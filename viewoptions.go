@@ -0,0 +1,197 @@
+package kivik
+
+import (
+	"github.com/go-kivik/kivik/errors"
+)
+
+// Values for ViewOptions.Stale.
+const (
+	StaleOK          = "ok"
+	StaleUpdateAfter = "update_after"
+)
+
+// Values for ViewOptions.Update.
+const (
+	UpdateTrue  = "true"
+	UpdateFalse = "false"
+	UpdateLazy  = "lazy"
+)
+
+// ViewOptions builds the options for a view query, or AllDocs. Keys are
+// JSON-encoded as required, and conflicting options are reported by Options.
+// Its methods modify and return the receiver, so that calls may be chained:
+//
+//	opts, err := kivik.NewViewOptions().
+//		StartKey([]interface{}{"foo"}).
+//		EndKey([]interface{}{"foo", map[string]interface{}{}}).
+//		GroupLevel(2).
+//		Options()
+//	if err != nil {
+//		return err
+//	}
+//	rows, err := db.Query(ctx, "_design/foo", "_view/bar", opts)
+type ViewOptions struct {
+	opts Options
+}
+
+// NewViewOptions returns a new, empty set of view options.
+func NewViewOptions() *ViewOptions {
+	return &ViewOptions{opts: Options{}}
+}
+
+func (o *ViewOptions) set(opts Options) *ViewOptions {
+	for k, v := range opts {
+		o.opts[k] = v
+	}
+	return o
+}
+
+// Key limits results to those matching key.
+func (o *ViewOptions) Key(key interface{}) *ViewOptions {
+	return o.set(Key(key))
+}
+
+// Keys limits results to those matching any of keys, in the order given.
+func (o *ViewOptions) Keys(keys ...interface{}) *ViewOptions {
+	if keys == nil {
+		keys = []interface{}{}
+	}
+	return o.set(jsonParam("keys", keys))
+}
+
+// StartKey limits results to those with keys at or after key.
+func (o *ViewOptions) StartKey(key interface{}) *ViewOptions {
+	return o.set(StartKey(key))
+}
+
+// EndKey limits results to those with keys at or before key.
+func (o *ViewOptions) EndKey(key interface{}) *ViewOptions {
+	return o.set(EndKey(key))
+}
+
+// StartKeyDocID limits results to those at or after the document docID,
+// among rows matching StartKey.
+func (o *ViewOptions) StartKeyDocID(docID string) *ViewOptions {
+	return o.set(Param("startkey_docid", docID))
+}
+
+// EndKeyDocID limits results to those at or before the document docID, among
+// rows matching EndKey.
+func (o *ViewOptions) EndKeyDocID(docID string) *ViewOptions {
+	return o.set(Param("endkey_docid", docID))
+}
+
+// InclusiveEnd sets whether rows matching EndKey are included. The server
+// default is true.
+func (o *ViewOptions) InclusiveEnd(inclusive bool) *ViewOptions {
+	return o.set(Param("inclusive_end", inclusive))
+}
+
+// Descending requests that results be returned in descending key order. Note
+// that StartKey and EndKey are then swapped in meaning.
+func (o *ViewOptions) Descending() *ViewOptions {
+	return o.set(Descending())
+}
+
+// Limit limits the number of rows returned.
+func (o *ViewOptions) Limit(n int) *ViewOptions {
+	return o.set(Limit(n))
+}
+
+// Skip skips the first n rows.
+func (o *ViewOptions) Skip(n int) *ViewOptions {
+	return o.set(Skip(n))
+}
+
+// IncludeDocs requests that the full document be included with each row. It
+// is not valid for reduced results.
+func (o *ViewOptions) IncludeDocs() *ViewOptions {
+	return o.set(IncludeDocs())
+}
+
+// Reduce sets whether the view's reduce function is used. The server default
+// is true, for views which define a reduce function.
+func (o *ViewOptions) Reduce(reduce bool) *ViewOptions {
+	return o.set(Param("reduce", reduce))
+}
+
+// Group requests that reduced results be grouped by key.
+func (o *ViewOptions) Group() *ViewOptions {
+	return o.set(Param("group", true))
+}
+
+// GroupLevel requests that reduced results be grouped by the first level
+// elements of array keys.
+func (o *ViewOptions) GroupLevel(level int) *ViewOptions {
+	return o.set(Param("group_level", level))
+}
+
+// Stale allows stale results to be returned. stale must be StaleOK or
+// StaleUpdateAfter. Stale is deprecated by CouchDB 2.1 in favor of Update and
+// Stable.
+func (o *ViewOptions) Stale(stale string) *ViewOptions {
+	return o.set(Param("stale", stale))
+}
+
+// Update sets whether the view is updated before results are returned.
+// update must be one of UpdateTrue, UpdateFalse or UpdateLazy.
+func (o *ViewOptions) Update(update string) *ViewOptions {
+	return o.set(Param("update", update))
+}
+
+// Stable requests that results come from a stable set of shards.
+func (o *ViewOptions) Stable(stable bool) *ViewOptions {
+	return o.set(Param("stable", stable))
+}
+
+// Options validates the options, and returns them for use with Query or
+// AllDocs.
+func (o *ViewOptions) Options() (Options, error) {
+	if err := validateOptions(o.opts); err != nil {
+		return nil, err
+	}
+	if err := o.validate(); err != nil {
+		return nil, err
+	}
+	opts := make(Options, len(o.opts))
+	for k, v := range o.opts {
+		opts[k] = v
+	}
+	return opts, nil
+}
+
+func (o *ViewOptions) has(key string) bool {
+	_, ok := o.opts[key]
+	return ok
+}
+
+func (o *ViewOptions) validate() error {
+	switch {
+	case o.has("key") && o.has("keys"):
+		return errors.Status(StatusBadRequest, "kivik: key and keys are mutually exclusive")
+	case (o.has("key") || o.has("keys")) && (o.has("startkey") || o.has("endkey")):
+		return errors.Status(StatusBadRequest, "kivik: key and keys may not be combined with startkey or endkey")
+	case o.has("stale") && o.has("update"):
+		return errors.Status(StatusBadRequest, "kivik: stale and update are mutually exclusive")
+	}
+	for _, key := range []string{"limit", "skip", "group_level"} {
+		if n, ok := o.opts[key].(int); ok && n < 0 {
+			return errors.Statusf(StatusBadRequest, "kivik: %s must not be negative", key)
+		}
+	}
+	if reduce, ok := o.opts["reduce"].(bool); ok {
+		if !reduce && (o.has("group") || o.has("group_level")) {
+			return errors.Status(StatusBadRequest, "kivik: group and group_level require reduce")
+		}
+		if reduce && o.has("include_docs") {
+			return errors.Status(StatusBadRequest, "kivik: include_docs is not valid with reduce")
+		}
+	}
+	if stale, ok := o.opts["stale"].(string); ok && stale != StaleOK && stale != StaleUpdateAfter {
+		return errors.Statusf(StatusBadRequest, "kivik: invalid value for stale: %q", stale)
+	}
+	if update, ok := o.opts["update"].(string); ok && update != UpdateTrue && update != UpdateFalse && update != UpdateLazy {
+		return errors.Statusf(StatusBadRequest, "kivik: invalid value for update: %q", update)
+	}
+	return nil
+}
@@ -0,0 +1,140 @@
+package kivik
+
+import (
+	"testing"
+
+	"github.com/flimzy/diff"
+	"github.com/flimzy/testy"
+)
+
+func TestViewOptions(t *testing.T) {
+	tests := []struct {
+		name     string
+		opts     *ViewOptions
+		expected Options
+		status   int
+		err      string
+	}{
+		{
+			name:     "empty",
+			opts:     NewViewOptions(),
+			expected: Options{},
+		},
+		{
+			name: "range",
+			opts: NewViewOptions().
+				StartKey([]interface{}{"foo"}).
+				EndKey([]interface{}{"foo", map[string]interface{}{}}).
+				StartKeyDocID("a").
+				EndKeyDocID("z").
+				InclusiveEnd(false).
+				Descending().
+				Limit(10).
+				Skip(2),
+			expected: Options{
+				"startkey":       `["foo"]`,
+				"endkey":         `["foo",{}]`,
+				"startkey_docid": "a",
+				"endkey_docid":   "z",
+				"inclusive_end":  false,
+				"descending":     true,
+				"limit":          10,
+				"skip":           2,
+			},
+		},
+		{
+			name:     "string key",
+			opts:     NewViewOptions().Key("foo").IncludeDocs(),
+			expected: Options{"key": `"foo"`, "include_docs": true},
+		},
+		{
+			name:     "keys",
+			opts:     NewViewOptions().Keys("a", 1, nil),
+			expected: Options{"keys": `["a",1,null]`},
+		},
+		{
+			name:     "no keys",
+			opts:     NewViewOptions().Keys(),
+			expected: Options{"keys": `[]`},
+		},
+		{
+			name:     "reduce",
+			opts:     NewViewOptions().Reduce(true).GroupLevel(2).Update(UpdateLazy).Stable(true),
+			expected: Options{"reduce": true, "group_level": 2, "update": "lazy", "stable": true},
+		},
+		{
+			name:     "stale",
+			opts:     NewViewOptions().Group().Stale(StaleUpdateAfter),
+			expected: Options{"group": true, "stale": "update_after"},
+		},
+		{
+			name:   "unencodable key",
+			opts:   NewViewOptions().Key(make(chan int)),
+			status: StatusBadRequest,
+			err:    "kivik: invalid value for 'key' option: json: unsupported type: chan int",
+		},
+		{
+			name:   "key and keys",
+			opts:   NewViewOptions().Key("a").Keys("b"),
+			status: StatusBadRequest,
+			err:    "kivik: key and keys are mutually exclusive",
+		},
+		{
+			name:   "key and startkey",
+			opts:   NewViewOptions().Key("a").StartKey("b"),
+			status: StatusBadRequest,
+			err:    "kivik: key and keys may not be combined with startkey or endkey",
+		},
+		{
+			name:   "keys and endkey",
+			opts:   NewViewOptions().Keys("a").EndKey("b"),
+			status: StatusBadRequest,
+			err:    "kivik: key and keys may not be combined with startkey or endkey",
+		},
+		{
+			name:   "stale and update",
+			opts:   NewViewOptions().Stale(StaleOK).Update(UpdateFalse),
+			status: StatusBadRequest,
+			err:    "kivik: stale and update are mutually exclusive",
+		},
+		{
+			name:   "negative limit",
+			opts:   NewViewOptions().Limit(-1),
+			status: StatusBadRequest,
+			err:    "kivik: limit must not be negative",
+		},
+		{
+			name:   "group without reduce",
+			opts:   NewViewOptions().Reduce(false).GroupLevel(1),
+			status: StatusBadRequest,
+			err:    "kivik: group and group_level require reduce",
+		},
+		{
+			name:   "include docs with reduce",
+			opts:   NewViewOptions().Reduce(true).IncludeDocs(),
+			status: StatusBadRequest,
+			err:    "kivik: include_docs is not valid with reduce",
+		},
+		{
+			name:   "invalid stale",
+			opts:   NewViewOptions().Stale("never"),
+			status: StatusBadRequest,
+			err:    `kivik: invalid value for stale: "never"`,
+		},
+		{
+			name:   "invalid update",
+			opts:   NewViewOptions().Update("sometimes"),
+			status: StatusBadRequest,
+			err:    `kivik: invalid value for update: "sometimes"`,
+		},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			result, err := test.opts.Options()
+			testy.StatusError(t, test.err, test.status, err)
+			if d := diff.Interface(test.expected, result); d != nil {
+				t.Error(d)
+			}
+		})
+	}
+}
@@ -0,0 +1,171 @@
+package kivik
+
+import (
+	"context"
+	"encoding/json"
+	"time"
+)
+
+// Default backoff bounds for WatchChanges.
+const (
+	DefaultWatchMinBackoff = 100 * time.Millisecond
+	DefaultWatchMaxBackoff = 30 * time.Second
+)
+
+// WatchConfig configures the reconnection behavior of WatchChanges. The zero
+// value, or a nil *WatchConfig, uses the defaults.
+type WatchConfig struct {
+	// MinBackoff is the delay before the first reconnection attempt. It is
+	// doubled after each consecutive failure, up to MaxBackoff, and reset
+	// once a change is received.
+	MinBackoff time.Duration
+	// MaxBackoff is the maximum delay between reconnection attempts.
+	MaxBackoff time.Duration
+}
+
+// ChangeEvent is a single change delivered by a ChangesWatcher.
+type ChangeEvent struct {
+	// ID is the ID of the changed document.
+	ID string
+	// Seq is the update sequence of the change.
+	Seq SequenceID
+	// Deleted is true if the document was deleted.
+	Deleted bool
+	// Changes is the list of changed leaf revisions.
+	Changes []string
+	// Doc is the raw JSON document, if the include_docs option was set.
+	Doc json.RawMessage
+}
+
+// ChangesWatcher consumes a continuous changes feed, reconnecting as
+// necessary. See WatchChanges.
+type ChangesWatcher struct {
+	db      *DB
+	options Options
+	min     time.Duration
+	max     time.Duration
+	events  chan ChangeEvent
+	err     error
+}
+
+// WatchChanges consumes the changes feed in continuous mode, delivering each
+// change on the channel returned by the Events method. If the feed is
+// closed, or an error occurs, the feed is re-opened from the last sequence
+// received, after an exponential backoff delay.
+//
+// options are passed to Changes, with "feed" set to "continuous". If no
+// "heartbeat" option is provided, a heartbeat of 30 seconds is requested, so
+// that the driver may detect a dropped connection. The "since" option may be
+// used to set the initial sequence.
+//
+// Watching stops when ctx is cancelled, or when an error is received which
+// retrying cannot fix, such as StatusUnauthorized or StatusNotFound. The
+// events channel is then closed, and Err reports the reason.
+func (db *DB) WatchChanges(ctx context.Context, config *WatchConfig, options ...Options) (*ChangesWatcher, error) {
+	opts, err := mergeOptions(options...)
+	if err != nil {
+		return nil, err
+	}
+	if opts == nil {
+		opts = Options{}
+	}
+	opts["feed"] = "continuous"
+	if _, ok := opts["heartbeat"]; !ok {
+		opts["heartbeat"] = 30000
+	}
+	w := &ChangesWatcher{
+		db:      db,
+		options: opts,
+		min:     DefaultWatchMinBackoff,
+		max:     DefaultWatchMaxBackoff,
+		events:  make(chan ChangeEvent),
+	}
+	if config != nil {
+		if config.MinBackoff > 0 {
+			w.min = config.MinBackoff
+		}
+		if config.MaxBackoff > 0 {
+			w.max = config.MaxBackoff
+		}
+	}
+	go w.run(ctx)
+	return w, nil
+}
+
+// Events returns the channel on which changes are delivered. It is closed
+// when watching stops.
+func (w *ChangesWatcher) Events() <-chan ChangeEvent {
+	return w.events
+}
+
+// Err returns the reason watching stopped. It is only valid once the Events
+// channel has been closed. If ctx was cancelled, ctx.Err() is returned.
+func (w *ChangesWatcher) Err() error {
+	return w.err
+}
+
+// permanentWatchError returns true if err is not expected to be resolved by
+// retrying.
+func permanentWatchError(err error) bool {
+	switch StatusCode(err) {
+	case StatusBadRequest, StatusUnauthorized, StatusForbidden, StatusNotFound, StatusNotImplemented:
+		return true
+	}
+	return false
+}
+
+func (w *ChangesWatcher) run(ctx context.Context) {
+	defer close(w.events)
+	backoff := w.min
+	for {
+		err := w.consume(ctx, &backoff)
+		if ctx.Err() != nil {
+			w.err = ctx.Err()
+			return
+		}
+		if permanentWatchError(err) {
+			w.db.client.log().Error("kivik: changes feed failed", "db", w.db.name, "error", err)
+			w.err = err
+			return
+		}
+		w.db.client.log().Warn("kivik: reconnecting to changes feed", "db", w.db.name, "since", w.options["since"], "delay", backoff, "error", err)
+		select {
+		case <-time.After(backoff):
+		case <-ctx.Done():
+			w.err = ctx.Err()
+			return
+		}
+		if backoff *= 2; backoff > w.max {
+			backoff = w.max
+		}
+	}
+}
+
+// consume reads from a single connection to the changes feed, until it is
+// closed, or an error occurs.
+func (w *ChangesWatcher) consume(ctx context.Context, backoff *time.Duration) error {
+	changes, err := w.db.Changes(ctx, w.options)
+	if err != nil {
+		return err
+	}
+	defer changes.Close() // nolint: errcheck
+	for changes.Next() {
+		*backoff = w.min
+		event := ChangeEvent{
+			ID:      changes.ID(),
+			Seq:     changes.Seq(),
+			Deleted: changes.Deleted(),
+			Changes: changes.Changes(),
+		}
+		_ = changes.ScanDoc(&event.Doc)
+		if event.Seq != "" {
+			w.options["since"] = string(event.Seq)
+		}
+		select {
+		case w.events <- event:
+		case <-ctx.Done():
+			return ctx.Err()
+		}
+	}
+	return changes.Err()
+}
@@ -0,0 +1,121 @@
+package kivik
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"testing"
+	"time"
+
+	"github.com/flimzy/diff"
+	"github.com/flimzy/testy"
+	"github.com/go-kivik/kivik/driver"
+	"github.com/go-kivik/kivik/errors"
+	"github.com/go-kivik/kivik/mock"
+)
+
+func changesFeed(changes []driver.Change, err error) *mock.Changes {
+	return &mock.Changes{
+		NextFunc: func(ch *driver.Change) error {
+			if len(changes) == 0 {
+				return err
+			}
+			*ch = changes[0]
+			changes = changes[1:]
+			return nil
+		},
+		CloseFunc: func() error { return nil },
+	}
+}
+
+func TestWatchChanges(t *testing.T) {
+	var requests []map[string]interface{}
+	feeds := []struct {
+		changes []driver.Change
+		err     error
+	}{
+		{
+			changes: []driver.Change{
+				{ID: "a", Seq: "1-x", Changes: []string{"1-a"}},
+				{ID: "b", Seq: "2-x", Changes: []string{"1-b"}, Doc: json.RawMessage(`{"_id":"b"}`)},
+			},
+			err: errors.Status(StatusNetworkError, "connection reset"),
+		},
+		{
+			err: io.EOF,
+		},
+		{
+			changes: []driver.Change{
+				{ID: "a", Seq: "3-x", Deleted: true, Changes: []string{"2-a"}},
+			},
+			err: errors.Status(StatusNotFound, "database deleted"),
+		},
+	}
+	db := &DB{
+		driverDB: &mock.DB{
+			ChangesFunc: func(_ context.Context, opts map[string]interface{}) (driver.Changes, error) {
+				requests = append(requests, copyOptions(opts))
+				if len(feeds) == 0 {
+					return nil, fmt.Errorf("Unexpected request")
+				}
+				feed := feeds[0]
+				feeds = feeds[1:]
+				return changesFeed(feed.changes, feed.err), nil
+			},
+		},
+	}
+	w, err := db.WatchChanges(context.Background(), &WatchConfig{MinBackoff: time.Millisecond}, Options{"include_docs": true})
+	if err != nil {
+		t.Fatal(err)
+	}
+	var events []ChangeEvent
+	for event := range w.Events() {
+		events = append(events, event)
+	}
+	testy.StatusError(t, "database deleted", StatusNotFound, w.Err())
+	expectedEvents := []ChangeEvent{
+		{ID: "a", Seq: "1-x", Changes: []string{"1-a"}},
+		{ID: "b", Seq: "2-x", Changes: []string{"1-b"}, Doc: json.RawMessage(`{"_id":"b"}`)},
+		{ID: "a", Seq: "3-x", Deleted: true, Changes: []string{"2-a"}},
+	}
+	if d := diff.Interface(expectedEvents, events); d != nil {
+		t.Error(d)
+	}
+	expectedRequests := []map[string]interface{}{
+		{"include_docs": true, "feed": "continuous", "heartbeat": 30000},
+		{"include_docs": true, "feed": "continuous", "heartbeat": 30000, "since": "2-x"},
+		{"include_docs": true, "feed": "continuous", "heartbeat": 30000, "since": "2-x"},
+	}
+	if d := diff.Interface(expectedRequests, requests); d != nil {
+		t.Error(d)
+	}
+}
+
+func TestWatchChangesCancel(t *testing.T) {
+	db := &DB{
+		driverDB: &mock.DB{
+			ChangesFunc: func(_ context.Context, _ map[string]interface{}) (driver.Changes, error) {
+				return nil, errors.Status(StatusNetworkError, "connection refused")
+			},
+		},
+	}
+	ctx, cancel := context.WithCancel(context.Background())
+	w, err := db.WatchChanges(ctx, &WatchConfig{MinBackoff: time.Millisecond, MaxBackoff: time.Millisecond})
+	if err != nil {
+		t.Fatal(err)
+	}
+	cancel()
+	for range w.Events() {
+		t.Error("Unexpected event")
+	}
+	testy.Error(t, "context canceled", w.Err())
+}
+
+func copyOptions(opts map[string]interface{}) map[string]interface{} {
+	c := make(map[string]interface{}, len(opts))
+	for k, v := range opts {
+		c[k] = v
+	}
+	return c
+}